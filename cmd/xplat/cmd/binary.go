@@ -7,12 +7,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 
 	"github.com/spf13/cobra"
 
 	"github.com/joeblew999/xplat/internal/config"
 	"github.com/joeblew999/xplat/internal/osutil"
+	"github.com/joeblew999/xplat/internal/platform"
+	"github.com/joeblew999/xplat/internal/registry"
 )
 
 // BinaryCmd is the parent command for binary operations
@@ -54,7 +55,11 @@ Examples:
   xplat binary install sitecheck v0.1.0 joeblew999/ubuntu-website
 
   # Force reinstall
-  xplat binary install analytics v0.1.0 joeblew999/ubuntu-website --force`,
+  xplat binary install analytics v0.1.0 joeblew999/ubuntu-website --force
+
+Before installing, the version is checked against the deprecation notices
+and security advisories in the registry index (see 'xplat binary audit').
+Findings are printed as warnings; --strict refuses to install instead.`,
 	Args: cobra.ExactArgs(3),
 	RunE: runBinaryInstall,
 }
@@ -65,6 +70,8 @@ var (
 	binaryExample     bool
 	binaryDir         string
 	binaryForce       bool
+	binaryStrict      bool
+	binaryNoAdvisory  bool
 )
 
 func init() {
@@ -73,6 +80,8 @@ func init() {
 	BinaryInstallCmd.Flags().BoolVar(&binaryExample, "example", false, "Build as cargo example (--example <name>) instead of binary")
 	BinaryInstallCmd.Flags().StringVar(&binaryDir, "dir", "", "Install directory (default: ~/.local/bin or ~/bin on Windows)")
 	BinaryInstallCmd.Flags().BoolVar(&binaryForce, "force", false, "Force reinstall even if binary exists")
+	BinaryInstallCmd.Flags().BoolVar(&binaryStrict, "strict", false, "Fail instead of warning when the version is deprecated or has known vulnerabilities")
+	BinaryInstallCmd.Flags().BoolVar(&binaryNoAdvisory, "skip-advisories", false, "Skip the registry deprecation and advisory check")
 
 	BinaryCmd.AddCommand(BinaryInstallCmd)
 }
@@ -110,6 +119,12 @@ func runBinaryInstall(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if !binaryNoAdvisory {
+		if err := checkAdvisories(registry.NewClient(), name, repo, version, binaryStrict); err != nil {
+			return err
+		}
+	}
+
 	// Create install directory
 	if err := os.MkdirAll(installDir, config.DefaultDirPerms); err != nil {
 		return fmt.Errorf("failed to create install directory: %w", err)
@@ -202,33 +217,50 @@ func runBinaryInstall(cmd *cobra.Command, args []string) error {
 
 	// Build download URL using the centralized naming function
 	// Format: https://github.com/REPO/releases/download/VERSION/NAME-OS-ARCH[.exe]
-	binName := binaryFilename(name, runtime.GOOS, runtime.GOARCH)
-
-	// Handle "dev" version by using latest release
+	// The native arch is tried first (so an amd64 xplat under Rosetta or
+	// Windows x64 emulation still gets arm64 binaries), then the arch the
+	// OS can emulate.
+	plat := platform.Current()
 	downloadVersion := version
-	var url string
 	if version == "" || version == "dev" {
-		// Use GitHub's special "latest" redirect URL
-		url = fmt.Sprintf("https://github.com/%s/releases/latest/download/%s",
-			repo, binName)
 		downloadVersion = "latest"
-	} else {
-		url = fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
-			repo, version, binName)
 	}
 
-	fmt.Printf("URL: %s\n", url)
+	var resp *http.Response
+	var err error
+	for i, arch := range plat.Archs() {
+		binName := binaryFilename(name, plat.OS, arch)
+
+		// Handle "dev" version by using GitHub's special "latest" redirect URL
+		var url string
+		if downloadVersion == "latest" {
+			url = fmt.Sprintf("https://github.com/%s/releases/latest/download/%s",
+				repo, binName)
+		} else {
+			url = fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
+				repo, version, binName)
+		}
 
-	// Download binary
-	resp, err := http.Get(url)
-	if err != nil {
-		return fmt.Errorf("download failed: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
+		if i > 0 {
+			_, note := platform.Fallback(plat.OS, plat.Arch)
+			fmt.Printf("Note: %s\n", note)
+		}
+		fmt.Printf("URL: %s\n", url)
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed: HTTP %d, release %s may not exist yet, install Go and use --source to build from source", resp.StatusCode, version)
+		// Download binary
+		resp, err = http.Get(url)
+		if err != nil {
+			return fmt.Errorf("download failed: %w", err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			break
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound || i == len(plat.Archs())-1 {
+			return fmt.Errorf("download failed: HTTP %d, release %s may not exist yet, install Go and use --source to build from source", resp.StatusCode, version)
+		}
 	}
+	defer func() { _ = resp.Body.Close() }()
 
 	// Create output file
 	out, err := os.Create(binPath)
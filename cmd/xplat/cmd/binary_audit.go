@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joeblew999/xplat/internal/registry"
+)
+
+// BinaryAuditCmd lists installs affected by registry deprecations and advisories
+var BinaryAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List installed packages that are deprecated or have known vulnerabilities",
+	Long: `Check every xplat-lock.yaml in the workspace against the deprecation
+notices and security advisories in the registry index.
+
+Index entries carry them next to the repo:
+
+  plat-nats:
+    repo: github.com/joeblew999/plat-nats
+    deprecated:
+      message: replaced by the NATS process in plat-telemetry
+      before: v0.3.0          # optional: only older versions are deprecated
+    advisories:
+      - id: CVE-2026-12345
+        summary: Auth bypass on leaf node connections
+        severity: high
+        introduced: v0.2.0    # optional: first affected version
+        fixed: v0.4.1         # optional: first fixed version
+
+Exits 1 when any install is affected, for use in CI.
+
+Examples:
+  xplat binary audit                 # Lockfiles under the current directory
+  xplat binary audit -d ~/workspace  # Every project in a workspace
+  xplat binary audit --json`,
+	RunE: runBinaryAudit,
+}
+
+var (
+	binaryAuditDir  string
+	binaryAuditJSON bool
+)
+
+func init() {
+	BinaryAuditCmd.Flags().StringVarP(&binaryAuditDir, "dir", "d", ".", "Workspace directory to search for xplat-lock.yaml files")
+	BinaryAuditCmd.Flags().BoolVar(&binaryAuditJSON, "json", false, "Output findings as JSON")
+
+	BinaryCmd.AddCommand(BinaryAuditCmd)
+}
+
+func runBinaryAudit(cmd *cobra.Command, args []string) error {
+	index, err := registry.NewClient().FetchIndex()
+	if err != nil {
+		return err
+	}
+	findings, err := index.AuditLockfiles(binaryAuditDir)
+	if err != nil {
+		return err
+	}
+
+	if binaryAuditJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return err
+		}
+	} else if len(findings) == 0 {
+		fmt.Println("No deprecated or vulnerable installs found.")
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "PROJECT\tPACKAGE\tVERSION\tFINDING\tSEVERITY\tFIXED\tDETAILS")
+		_, _ = fmt.Fprintln(w, "-------\t-------\t-------\t-------\t--------\t-----\t-------")
+		for _, f := range findings {
+			id := f.ID
+			if f.Kind == registry.FindingDeprecated {
+				id = "deprecated"
+			}
+			project, err := filepath.Rel(binaryAuditDir, f.Project)
+			if err != nil {
+				project = f.Project
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", project, f.Package, f.Version, id, dash(f.Severity), dash(f.Fixed), f.Message)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%d deprecated or vulnerable install(s)", len(findings))
+	}
+	return nil
+}
+
+// checkAdvisories warns about registry deprecations and advisories affecting
+// version of a package before it is installed. With strict, any finding
+// fails the install, and so does an unreachable index; otherwise that only
+// warns.
+func checkAdvisories(client *registry.Client, name, repo, version string, strict bool) error {
+	findings, err := client.Findings(name, repo, version)
+	if err != nil {
+		if strict {
+			return fmt.Errorf("not installing %s %s: could not check registry advisories (--strict): %w", name, version, err)
+		}
+		fmt.Printf("Warning: could not check registry advisories: %v\n", err)
+		return nil
+	}
+	for _, f := range findings {
+		fmt.Printf("Warning: %s\n", f)
+		if f.URL != "" {
+			fmt.Printf("    %s\n", f.URL)
+		}
+	}
+	if strict && len(findings) > 0 {
+		return fmt.Errorf("not installing %s %s: %d deprecation/advisory finding(s) (--strict)", name, version, len(findings))
+	}
+	return nil
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
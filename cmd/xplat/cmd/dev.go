@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joeblew999/xplat/internal/env"
+	"github.com/joeblew999/xplat/internal/gitops"
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/preview"
+	"github.com/joeblew999/xplat/internal/synccf"
+)
+
+// DevCmd groups local development workflows.
+var DevCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Local development workflows",
+}
+
+var (
+	devPreviewTunnel   string
+	devPreviewInterval time.Duration
+)
+
+var devPreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Run a preview environment for the current git branch",
+	Long: `Run the project's processes as a preview of the current git branch.
+
+Each process with a port in xplat.yaml runs on its port plus a per-branch
+offset (derived from the branch name, so it is stable, and moved to the
+next free slot if another running preview has it), set through the
+<NAME>_PORT variables the generated process-compose config reads. The
+preview gets its own process-compose API port, so several branches can
+run side by side.
+
+If Caddy is running, each process is also routed at
+  https://localhost/preview/<branch>/<process>/
+Processes can read XPLAT_PREVIEW_PATH to set their base URL.
+
+The preview stays in the foreground and is torn down when you switch
+branches or press Ctrl+C. Use 'xplat dev preview down' to clean up a
+preview whose command did not exit cleanly.
+
+Examples:
+  xplat dev preview                  # Preview the current branch
+  xplat dev preview --tunnel web     # Also expose the web process via a quick tunnel
+  xplat dev preview list             # Show recorded previews
+  xplat dev preview down feat/login  # Tear down a preview`,
+	RunE: runDevPreview,
+}
+
+var devPreviewListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded preview environments",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		previews, err := preview.List(".")
+		if err != nil {
+			return err
+		}
+		if len(previews) == 0 {
+			fmt.Println("No previews running")
+			return nil
+		}
+		for _, p := range previews {
+			fmt.Printf("%s (offset +%d, process-compose :%d)\n", p.Branch, p.Offset, p.PCPort)
+			printPreviewProcesses(p)
+		}
+		return nil
+	},
+}
+
+var devPreviewDownCmd = &cobra.Command{
+	Use:   "down [branch]",
+	Short: "Tear down a preview environment (default: current branch)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		branch := ""
+		if len(args) > 0 {
+			branch = args[0]
+		} else {
+			b, err := gitops.GetBranch(".")
+			if err != nil {
+				return err
+			}
+			branch = b
+		}
+		p, err := preview.Load(".", branch)
+		if err != nil {
+			return err
+		}
+		return teardownPreview(p)
+	},
+}
+
+func init() {
+	devPreviewCmd.Flags().StringVar(&devPreviewTunnel, "tunnel", "", "Expose this process via a cloudflared quick tunnel")
+	devPreviewCmd.Flags().DurationVar(&devPreviewInterval, "interval", 2*time.Second, "How often to check for a branch switch")
+
+	devPreviewCmd.AddCommand(devPreviewListCmd)
+	devPreviewCmd.AddCommand(devPreviewDownCmd)
+	DevCmd.AddCommand(devPreviewCmd)
+}
+
+func runDevPreview(cmd *cobra.Command, args []string) error {
+	m, err := manifest.NewLoader().LoadDir(".")
+	if err != nil {
+		return err
+	}
+	branch, err := gitops.GetBranch(".")
+	if err != nil {
+		return err
+	}
+	if _, err := preview.Load(".", branch); err == nil {
+		return fmt.Errorf("a preview of '%s' is already recorded; run 'xplat dev preview down' first", branch)
+	}
+	running, err := preview.List(".")
+	if err != nil {
+		return err
+	}
+	p, err := preview.New(m, branch, running)
+	if err != nil {
+		return err
+	}
+	var tunnelPort int
+	if devPreviewTunnel != "" {
+		for _, proc := range p.Processes {
+			if proc.Name == devPreviewTunnel {
+				tunnelPort = proc.Port
+			}
+		}
+		if tunnelPort == 0 {
+			return fmt.Errorf("process '%s' has no port to tunnel", devPreviewTunnel)
+		}
+	}
+
+	fmt.Printf("Starting preview of '%s' (ports +%d)\n", branch, p.Offset)
+	p.StartedAt = time.Now().UTC()
+	if err := p.Save("."); err != nil {
+		return err
+	}
+	if err := previewProcess(p, "up", "--detached"); err != nil {
+		_ = p.Remove(".")
+		return fmt.Errorf("failed to start processes: %w", err)
+	}
+
+	if env.IsCaddyRunning() {
+		for _, proc := range p.Processes {
+			_, err := env.RegisterService(env.ServiceConfig{
+				Name:        proc.Service,
+				Port:        proc.Port,
+				PathPattern: proc.Path + "/*",
+				Priority:    10, // Ahead of root catch-all services
+			})
+			if err != nil {
+				fmt.Printf("Warning: failed to route %s through Caddy: %v\n", proc.Name, err)
+				continue
+			}
+			p.Caddy = append(p.Caddy, proc.Service)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var tunnel *synccf.Tunnel
+	if tunnelPort != 0 {
+		url, t, err := synccf.RunQuickTunnel(ctx, tunnelPort)
+		if err != nil {
+			fmt.Printf("Warning: tunnel not started: %v\n", err)
+		} else {
+			tunnel = t
+			p.TunnelURL = url
+		}
+	}
+	if err := p.Save("."); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	printPreviewProcesses(p)
+	fmt.Println()
+	fmt.Println("Watching for branch switch (Ctrl+C to stop)...")
+
+	ticker := time.NewTicker(devPreviewInterval)
+	defer ticker.Stop()
+	for watching := true; watching; {
+		select {
+		case <-ctx.Done():
+			watching = false
+		case <-ticker.C:
+			current, err := gitops.GetBranch(".")
+			if err == nil && current == branch {
+				continue
+			}
+			fmt.Printf("\nBranch switched away from '%s'\n", branch)
+			watching = false
+		}
+	}
+
+	if tunnel != nil {
+		tunnel.Stop()
+	}
+	return teardownPreview(p)
+}
+
+// teardownPreview stops a preview's processes, removes its Caddy routes
+// and deletes its state.
+func teardownPreview(p *preview.Preview) error {
+	fmt.Printf("Tearing down preview of '%s'\n", p.Branch)
+	if err := previewProcess(p, "down"); err != nil {
+		fmt.Printf("Warning: failed to stop processes: %v\n", err)
+	}
+	for _, name := range p.Caddy {
+		if err := env.UnregisterService(name); err != nil {
+			fmt.Printf("Warning: failed to remove Caddy route %s: %v\n", name, err)
+		}
+	}
+	return p.Remove(".")
+}
+
+// previewProcess runs 'xplat process <args> -p <pc port>' with the
+// preview's environment. It runs as a child process because the embedded
+// process-compose exits the process it runs in.
+func previewProcess(p *preview.Preview, args ...string) error {
+	xplatBin, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find xplat binary: %w", err)
+	}
+	args = append(append([]string{"process"}, args...), "-p", strconv.Itoa(p.PCPort))
+	c := exec.Command(xplatBin, args...)
+	c.Env = append(os.Environ(), p.Env()...)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
+
+func printPreviewProcesses(p *preview.Preview) {
+	caddy := make(map[string]bool, len(p.Caddy))
+	for _, name := range p.Caddy {
+		caddy[name] = true
+	}
+	for _, proc := range p.Processes {
+		fmt.Printf("  %-16s http://localhost:%d", proc.Name, proc.Port)
+		if caddy[proc.Service] {
+			fmt.Printf("  https://localhost%s/", proc.Path)
+		}
+		fmt.Println()
+	}
+	if p.TunnelURL != "" {
+		fmt.Printf("  tunnel           %s\n", p.TunnelURL)
+	}
+}
@@ -156,7 +156,7 @@ func runDocsReadme(cmd *cobra.Command, args []string) error {
 			categoryMap["Process"] = append(categoryMap["Process"], c)
 		case "sync-gh", "sync-cf":
 			categoryMap["Sync"] = append(categoryMap["Sync"], c)
-		case "docs", "os", "completion":
+		case "docs", "os", "dev", "completion":
 			categoryMap["Development"] = append(categoryMap["Development"], c)
 		default:
 			categoryMap["Other"] = append(categoryMap["Other"], c)
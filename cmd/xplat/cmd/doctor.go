@@ -0,0 +1,130 @@
+// Package cmd provides CLI commands for xplat.
+//
+// doctor.go - Health checks for xplat's background services
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/syncgh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorJSON      bool
+	doctorPollState string
+)
+
+// doctorResult is the outcome of one doctor check.
+type doctorResult struct {
+	Check  string `json:"check"`
+	Status string `json:"status"` // ok, warn, fail, skip
+	Detail string `json:"detail,omitempty"`
+}
+
+// doctorChecks run in order; each returns one or more results.
+var doctorChecks = []func() []doctorResult{
+	doctorPollerFreshness,
+}
+
+// DoctorCmd checks the health of xplat's background services.
+var DoctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the health of xplat services",
+	Long: `Check the health of xplat's background services.
+
+Checks:
+  sync-gh poller   Every polled repo was checked within two intervals
+                   and the last poll succeeded (see 'xplat sync-gh poll-state')
+
+Exits with status 1 if any check fails.
+
+Examples:
+  xplat doctor
+  xplat doctor --json
+  xplat doctor --poll-state nats:xplat-state`,
+	RunE: runDoctor,
+}
+
+func init() {
+	DoctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output results as JSON")
+	DoctorCmd.Flags().StringVar(&doctorPollState, "poll-state", "", "sync-gh poll state backend (see 'sync-gh poll --help')")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	var results []doctorResult
+	for _, check := range doctorChecks {
+		results = append(results, check()...)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Status == "fail" {
+			failed++
+		}
+	}
+
+	if doctorJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range results {
+			fmt.Printf("%-5s %-32s %s\n", r.Status, r.Check, r.Detail)
+		}
+		fmt.Println()
+		if failed > 0 {
+			fmt.Printf("%d of %d checks failed\n", failed, len(results))
+		} else {
+			fmt.Printf("All %d checks passed\n", len(results))
+		}
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// doctorPollerFreshness reports stale or failing repos in the sync-gh poll state.
+func doctorPollerFreshness() []doctorResult {
+	const check = "sync-gh poller"
+
+	store, err := getPollStateStore(doctorPollState)
+	if err != nil {
+		return []doctorResult{{Check: check, Status: "fail", Detail: err.Error()}}
+	}
+	state, err := syncgh.LoadPollStateFrom(store)
+	if err != nil {
+		return []doctorResult{{Check: check, Status: "fail", Detail: err.Error()}}
+	}
+
+	statuses := state.Status(time.Now())
+	if len(statuses) == 0 {
+		return []doctorResult{{Check: check, Status: "skip", Detail: "no repos polled (" + store.String() + ")"}}
+	}
+
+	results := make([]doctorResult, 0, len(statuses))
+	for _, s := range statuses {
+		r := doctorResult{
+			Check:  check + " " + s.Repo + "@" + s.Ref,
+			Status: "ok",
+			Detail: fmt.Sprintf("checked %s ago", time.Since(s.LastChecked).Round(time.Second)),
+		}
+		switch {
+		case s.Stale:
+			r.Status = "fail"
+			r.Detail = fmt.Sprintf("stale: last checked %s ago, poller not running?", time.Since(s.LastChecked).Round(time.Second))
+		case s.LastError != "":
+			r.Status = "warn"
+			r.Detail = "last poll failed: " + s.LastError
+		}
+		results = append(results, r)
+	}
+	return results
+}
@@ -0,0 +1,221 @@
+// Package cmd provides CLI commands for xplat.
+//
+// env.go - Encrypted .env commands (age/sops)
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/joeblew999/xplat/internal/env"
+	"github.com/spf13/cobra"
+	"mvdan.cc/sh/v3/shell"
+)
+
+var (
+	envEncryptKeep   bool
+	envDecryptStdout bool
+)
+
+// EnvFileCmd is the parent command for encrypted .env management.
+// Named EnvFileCmd because EnvCmd is 'xplat os env'.
+var EnvFileCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage encrypted .env files",
+	Long: `Manage .env.enc, an age-encrypted copy of .env that is safe to commit.
+
+When .env is absent and .env.enc exists, xplat decrypts .env.enc in memory
+for 'xplat task', 'xplat process' and 'xplat setup' - plaintext never
+touches disk. Files encrypted with sops (dotenv format, age recipients)
+are also understood.
+
+The age key is found in this order:
+  1. SOPS_AGE_KEY            (key contents)
+  2. SOPS_AGE_KEY_FILE       (path to key file)
+  3. OS keychain, service "xplat-age" (macOS Keychain, Linux secret-tool)
+  4. ~/.config/sops/age/keys.txt
+
+Recipients (public keys that can decrypt) are read from .age-recipients,
+one per line. Without it, the file is encrypted to your own key only.
+
+Requires the age CLI (and sops for sops-encrypted files).
+
+Examples:
+  xplat env encrypt          # .env -> .env.enc, removes .env
+  xplat env encrypt --keep   # .env -> .env.enc, keeps .env
+  xplat env decrypt          # .env.enc -> .env
+  xplat env decrypt --stdout # print decrypted values
+  xplat env edit             # edit .env.enc in $EDITOR`,
+}
+
+var envEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt .env to .env.enc",
+	RunE:  runEnvEncrypt,
+}
+
+var envDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt .env.enc to .env",
+	RunE:  runEnvDecrypt,
+}
+
+var envEditCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit .env.enc in $EDITOR",
+	Long: `Decrypt .env.enc to a private temp file, open it in $EDITOR,
+and re-encrypt it when the editor exits, keeping the sops or age format
+of the existing file. The temp file is always removed.`,
+	RunE: runEnvEdit,
+}
+
+func init() {
+	envEncryptCmd.Flags().BoolVar(&envEncryptKeep, "keep", false, "Keep the plaintext .env after encrypting")
+	envDecryptCmd.Flags().BoolVar(&envDecryptStdout, "stdout", false, "Print to stdout instead of writing .env")
+
+	EnvFileCmd.AddCommand(envEncryptCmd)
+	EnvFileCmd.AddCommand(envDecryptCmd)
+	EnvFileCmd.AddCommand(envEditCmd)
+}
+
+func runEnvEncrypt(cmd *cobra.Command, args []string) error {
+	envPath, err := env.GetEnvPath()
+	if err != nil {
+		return err
+	}
+	plaintext, err := os.ReadFile(envPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	recipients, err := env.LoadRecipients()
+	if err != nil {
+		return err
+	}
+
+	if err := env.EncryptEnvFile(env.GetEncryptedEnvPath(), plaintext, recipients); err != nil {
+		return err
+	}
+	fmt.Printf("Encrypted .env -> %s (%d recipient(s))\n", env.GetEncryptedEnvPath(), len(recipients))
+
+	if !envEncryptKeep {
+		if err := os.Remove(envPath); err != nil {
+			return fmt.Errorf("failed to remove .env: %w", err)
+		}
+		fmt.Println("Removed plaintext .env")
+	}
+	return nil
+}
+
+func runEnvDecrypt(cmd *cobra.Command, args []string) error {
+	plaintext, err := env.DecryptEnvFile(env.GetEncryptedEnvPath())
+	if err != nil {
+		return err
+	}
+
+	if envDecryptStdout {
+		_, err := os.Stdout.Write(plaintext)
+		return err
+	}
+
+	envPath, err := env.GetEnvPath()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(envPath, plaintext, 0600); err != nil {
+		return fmt.Errorf("failed to write .env: %w", err)
+	}
+	fmt.Printf("Decrypted %s -> .env (git-ignored, do not commit)\n", env.GetEncryptedEnvPath())
+	return nil
+}
+
+func runEnvEdit(cmd *cobra.Command, args []string) error {
+	encPath := env.GetEncryptedEnvPath()
+
+	var plaintext []byte
+	if env.EncryptedEnvExists() {
+		var err error
+		plaintext, err = env.DecryptEnvFile(encPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	recipients, err := env.LoadRecipients()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "xplat-env-*.env")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(plaintext); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// $EDITOR may carry arguments ("code --wait"), so split it like a shell would
+	editorArgs, err := shell.Fields(editor, nil)
+	if err != nil {
+		return fmt.Errorf("invalid $EDITOR %q: %w", editor, err)
+	}
+	if len(editorArgs) == 0 {
+		return fmt.Errorf("invalid $EDITOR %q", editor)
+	}
+	editorCmd := exec.Command(editorArgs[0], append(editorArgs[1:], tmpPath)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to read edited file: %w", err)
+	}
+	if bytes.Equal(edited, plaintext) {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	if err := env.EncryptEnvFile(encPath, edited, recipients); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", filepath.Base(encPath))
+	return nil
+}
+
+// applyEncryptedEnv loads .env.enc into the environment when there is no plaintext .env.
+// Failures are reported but not fatal - the command may not need the secrets.
+func applyEncryptedEnv(dir string) {
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".env")); err == nil {
+		return
+	}
+
+	if _, err := env.ApplyEncryptedEnv(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not decrypt .env.enc: %v\n", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/joeblew999/xplat/internal/osutil"
 	"github.com/mholt/archives"
 	"github.com/spf13/cobra"
 )
@@ -100,8 +101,10 @@ func runExtract(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create destination directory
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return fmt.Errorf("cannot create destination: %w", err)
+	if !osDryRun {
+		if err := os.MkdirAll(destDir, 0755); err != nil {
+			return fmt.Errorf("cannot create destination: %w", err)
+		}
 	}
 
 	// Extract
@@ -165,9 +168,19 @@ func extractArchive(ctx context.Context, ex archives.Extractor, reader io.Reader
 
 		// Handle directories
 		if f.IsDir() {
+			if osDryRun {
+				return nil
+			}
 			return os.MkdirAll(destPath, f.Mode())
 		}
 
+		if run, _ := osPlan(func() ([]osutil.Op, error) {
+			return []osutil.Op{{Action: "extract", Path: f.NameInArchive, Target: osutil.Abs(destPath)}}, nil
+		}); !run {
+			extractedCount++
+			return nil
+		}
+
 		// Create parent directory
 		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 			return fmt.Errorf("cannot create directory: %w", err)
@@ -200,6 +213,10 @@ func extractArchive(ctx context.Context, ex archives.Extractor, reader io.Reader
 		return err
 	}
 
+	if osDryRun {
+		fmt.Printf("Would extract %d files to %s\n", extractedCount, osutil.Abs(destDir))
+		return nil
+	}
 	fmt.Printf("Extracted %d files to %s\n", extractedCount, destDir)
 	return nil
 }
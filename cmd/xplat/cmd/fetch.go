@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/joeblew999/xplat/internal/osutil"
 	"github.com/mholt/archives"
 	"github.com/spf13/cobra"
 )
@@ -55,6 +56,19 @@ func init() {
 func runFetch(cmd *cobra.Command, args []string) error {
 	url := args[0]
 
+	// The archive contents are unknown until downloaded, so plan at the
+	// level of the download
+	run, err := osPlan(func() ([]osutil.Op, error) {
+		if fetchExtract {
+			return []osutil.Op{{Action: "download", Path: url, Target: osutil.Abs(fetchOutput), Detail: "extract"}}, nil
+		}
+		dest := filepath.Join(fetchOutput, filepath.Base(strings.TrimSuffix(url, "/")))
+		return []osutil.Op{{Action: "download", Path: url, Target: osutil.Abs(dest)}}, nil
+	})
+	if err != nil || !run {
+		return err
+	}
+
 	// Create output directory
 	if err := os.MkdirAll(fetchOutput, 0755); err != nil {
 		return fmt.Errorf("cannot create output directory: %w", err)
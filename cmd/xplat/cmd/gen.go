@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,7 +22,8 @@ var (
 	genOutput  string
 	genRepoURL string
 	genForce   bool
-	genPages   bool // enable GitHub Pages deployment in CI workflow
+	genPages   bool   // enable GitHub Pages deployment in CI workflow
+	genFormat  string // deps config format: renovate or dependabot
 )
 
 // GenCmd is the parent command for all generation from xplat.yaml.
@@ -43,6 +46,9 @@ Examples:
   xplat gen env          # Generate .env.example
   xplat gen taskfile     # Generate Taskfile with remote includes
   xplat gen process      # Generate process-compose.yaml
+  xplat gen deps         # Generate renovate.json for detected ecosystems
+  xplat gen governance   # Generate CODEOWNERS, issue and PR templates
+  xplat gen community    # Generate LICENSE, SECURITY.md and FUNDING.yml
   xplat gen all          # Generate all of the above`,
 }
 
@@ -144,6 +150,88 @@ Example workflow:
 	RunE: runGenService,
 }
 
+var genDepsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Generate renovate.json or .github/dependabot.yml",
+	Long: `Generate dependency update config scoped to what's actually in the repo.
+
+Detects:
+- Go modules (every go.mod)
+- npm packages (every package.json)
+- GitHub Actions (.github/workflows)
+- xplat.yaml tool pins (binary.source repo + version)
+
+Renovate (default) gets a custom regex manager so xplat.yaml pins are
+updated from GitHub tags. Dependabot has no custom managers, so those pins
+are only listed as a comment.
+
+Examples:
+  xplat gen deps                        # renovate.json
+  xplat gen deps --format=dependabot    # .github/dependabot.yml`,
+	RunE: runGenDeps,
+}
+
+var genGovernanceCmd = &cobra.Command{
+	Use:   "governance",
+	Short: "Generate CODEOWNERS and issue/PR templates",
+	Long: `Generate GitHub governance files from xplat.yaml, so every plat-* repo
+has the same owners and templates.
+
+Creates:
+- .github/CODEOWNERS from author and maintainers
+- .github/ISSUE_TEMPLATE/bug_report.md with the repo's tasks in the reproduction steps
+- .github/ISSUE_TEMPLATE/feature_request.md
+- .github/pull_request_template.md with the repo's tasks as a checklist
+
+The tasks are the build, test and lint tasks the Taskfile defines.
+Existing files are kept unless --force is given.
+
+xplat.yaml:
+  author: joeblew999
+  maintainers:
+    - octocat
+    - my-org/platform-team
+
+Examples:
+  xplat gen governance
+  xplat gen governance --force`,
+	RunE: runGenGovernance,
+}
+
+var genCommunityCmd = &cobra.Command{
+	Use:   "community",
+	Short: "Generate LICENSE, SECURITY.md and .github/FUNDING.yml",
+	Long: `Generate community health files from xplat.yaml metadata, so every
+repo states its license, how to report vulnerabilities and how to sponsor
+it the same way.
+
+Creates:
+- LICENSE for MIT, Apache-2.0 or BSD-3-Clause, from license and copyright
+  (default: author). Only written when missing, or with --force.
+- SECURITY.md reporting policy, to security.contact or else GitHub private
+  vulnerability reporting
+- .github/FUNDING.yml from funding, when set
+
+SECURITY.md and FUNDING.yml are regenerated inside an "xplat:begin" /
+"xplat:end" block, so text added around the block is kept. A file without
+the block is only replaced with --force.
+
+xplat.yaml:
+  license: MIT
+  author: joeblew999
+  copyright: Joe Blew       # optional
+  security:
+    contact: security@example.com
+  funding:
+    github: [joeblew999]
+    custom: [https://example.com/donate]
+
+Examples:
+  xplat gen community
+  xplat gen community --force`,
+	RunE: runGenCommunity,
+}
+
 var genAllCmd = &cobra.Command{
 	Use:   "all",
 	Short: "Generate all files from manifest",
@@ -157,6 +245,7 @@ func init() {
 	GenCmd.PersistentFlags().BoolVarP(&genForce, "force", "f", false, "Overwrite existing files")
 
 	genWorkflowCmd.Flags().BoolVar(&genPages, "pages", false, "Include GitHub Pages deployment (uses xplat docs build)")
+	genDepsCmd.Flags().StringVar(&genFormat, "format", "renovate", "Config format: renovate or dependabot")
 
 	GenCmd.AddCommand(genWorkflowCmd)
 	GenCmd.AddCommand(genGitignoreCmd)
@@ -164,6 +253,9 @@ func init() {
 	GenCmd.AddCommand(genTaskfileCmd)
 	GenCmd.AddCommand(genProcessCmd)
 	GenCmd.AddCommand(genServiceCmd)
+	GenCmd.AddCommand(genDepsCmd)
+	GenCmd.AddCommand(genGovernanceCmd)
+	GenCmd.AddCommand(genCommunityCmd)
 	GenCmd.AddCommand(genAllCmd)
 }
 
@@ -367,6 +459,146 @@ func runGenService(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runGenDeps(cmd *cobra.Command, args []string) error {
+	eco, err := manifest.DetectEcosystems(genDir)
+	if err != nil {
+		return err
+	}
+	if eco.IsEmpty() {
+		fmt.Println("No dependency ecosystems detected (go.mod, package.json, .github/workflows, xplat.yaml pins).")
+		return nil
+	}
+
+	var outputPath string
+	var content []byte
+	switch genFormat {
+	case "renovate":
+		outputPath = filepath.Join(genOutput, "renovate.json")
+		content, err = manifest.RenderRenovate(eco)
+	case "dependabot":
+		outputPath = filepath.Join(genOutput, ".github", "dependabot.yml")
+		content, err = manifest.RenderDependabot(eco)
+	default:
+		return fmt.Errorf("unknown format %q (valid: renovate, dependabot)", genFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(outputPath); err == nil && !genForce {
+		return fmt.Errorf("%s already exists, use --force to overwrite", outputPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+
+	fmt.Printf("Generated %s\n", outputPath)
+	fmt.Printf("  Go modules:      %d\n", len(eco.GoModDirs))
+	fmt.Printf("  npm packages:    %d\n", len(eco.NPMDirs))
+	fmt.Printf("  GitHub Actions:  %v\n", eco.GitHubActions)
+	fmt.Printf("  xplat.yaml pins: %d\n", len(eco.XplatPins))
+	return nil
+}
+
+func runGenGovernance(cmd *cobra.Command, args []string) error {
+	m, err := loadManifestForGen()
+	if err != nil {
+		return err
+	}
+	return writeGovernanceFiles(m, genOutput, genForce)
+}
+
+// writeGovernanceFiles renders the governance files for m into baseDir.
+// Existing files are kept unless force is set, so hand-written CODEOWNERS
+// and templates survive 'xplat gen all'.
+func writeGovernanceFiles(m *manifest.Manifest, baseDir string, force bool) error {
+	files, err := manifest.RenderGovernance(m, manifest.GovernanceTasks(genDir))
+	if err != nil {
+		return err
+	}
+
+	for _, f := range manifest.GovernanceFiles {
+		outputPath := filepath.Join(baseDir, filepath.FromSlash(f.Path))
+		if _, err := os.Stat(outputPath); err == nil && !force {
+			fmt.Printf("Kept existing %s (use --force to replace)\n", outputPath)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(outputPath, files[f.Path], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
+		fmt.Printf("Generated %s\n", outputPath)
+	}
+	return nil
+}
+
+func runGenCommunity(cmd *cobra.Command, args []string) error {
+	m, err := loadManifestForGen()
+	if err != nil {
+		return err
+	}
+	return writeCommunityFiles(m, genOutput, genForce)
+}
+
+// writeCommunityFiles renders the community files for m into baseDir. Only
+// their managed blocks are updated; LICENSE and files without a managed
+// block are only replaced with force.
+func writeCommunityFiles(m *manifest.Manifest, baseDir string, force bool) error {
+	files, err := manifest.RenderCommunity(m, manifest.CommunityOptions{RepoURL: genRepoURL})
+	if err != nil {
+		return err
+	}
+	if m.License != "" && manifest.LicenseTemplate(m.License) == "" {
+		fmt.Printf("No LICENSE template for %s, skipped (supported: MIT, Apache-2.0, BSD-3-Clause)\n", m.License)
+	}
+
+	for _, f := range files {
+		outputPath := filepath.Join(baseDir, filepath.FromSlash(f.Path))
+		existing, err := os.ReadFile(outputPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", outputPath, err)
+		}
+		exists := err == nil
+
+		content := f.Content
+		if f.Managed == "" {
+			if exists && !force {
+				fmt.Printf("Kept existing %s (use --force to replace)\n", outputPath)
+				continue
+			}
+		} else {
+			content, err = manifest.MergeManagedBlock(existing, f.Managed, f.Content)
+			if errors.Is(err, manifest.ErrUnmanaged) && force {
+				content, err = manifest.ManagedBlock(f.Managed, f.Content), nil
+			}
+			if errors.Is(err, manifest.ErrUnmanaged) {
+				return fmt.Errorf("%s has no xplat managed block, use --force to replace it", outputPath)
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %w", outputPath, err)
+			}
+		}
+
+		if exists && bytes.Equal(existing, content) {
+			fmt.Printf("Up to date %s\n", outputPath)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(outputPath, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", outputPath, err)
+		}
+		fmt.Printf("Generated %s\n", outputPath)
+	}
+	return nil
+}
+
 func runGenProcess(cmd *cobra.Command, args []string) error {
 	// Load lockfile to get installed packages
 	lf, err := lockfile.Load(genDir)
@@ -442,6 +674,21 @@ func runGenAll(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("Generated %s\n", envPath)
 
+	// Generate CODEOWNERS and issue/PR templates when owners are known
+	if len(m.CodeOwners()) > 0 {
+		if err := writeGovernanceFiles(m, baseDir, genForce); err != nil {
+			return fmt.Errorf("failed to generate governance files: %w", err)
+		}
+	}
+
+	// Generate LICENSE, SECURITY.md and FUNDING.yml when the copyright holder
+	// is known; an existing LICENSE is kept
+	if m.Author != "" || m.Copyright != "" {
+		if err := writeCommunityFiles(m, baseDir, false); err != nil {
+			return fmt.Errorf("failed to generate community files: %w", err)
+		}
+	}
+
 	// Load lockfile for taskfile and process generation
 	lf, err := lockfile.Load(genDir)
 	if err != nil {
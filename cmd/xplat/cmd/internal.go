@@ -19,6 +19,7 @@ import (
 	"strings"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/platform"
 	"github.com/joeblew999/xplat/internal/templates"
 	"github.com/spf13/cobra"
 )
@@ -307,6 +308,7 @@ func runInternalDevInfo(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  XPLAT_HOME:        %s\n", config.XplatHome())
 	fmt.Printf("  XPLAT_BIN:         %s\n", config.XplatBin())
 	fmt.Printf("  XPLAT_CACHE:       %s\n", config.XplatCache())
+	fmt.Printf("  XPLAT_STATE:       %s\n", config.XplatState())
 	fmt.Println()
 	fmt.Println("Internal Templates:")
 	tmplList, _ := templates.ListTemplates()
@@ -323,6 +325,7 @@ func runInternalDevInfo(cmd *cobra.Command, args []string) error {
 	fmt.Println("Runtime:")
 	fmt.Printf("  GOOS:              %s\n", runtime.GOOS)
 	fmt.Printf("  GOARCH:            %s\n", runtime.GOARCH)
+	fmt.Printf("  Native arch:       %s\n", platform.Current().Arch)
 	fmt.Printf("  Is CI:             %v\n", config.IsCI())
 	return nil
 }
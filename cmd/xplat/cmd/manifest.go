@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -105,10 +106,21 @@ Checks:
 - binary.source.go has a go.mod
 - processes reference valid task commands
 
+Findings are errors, warnings or info. With -d, every plat-* repo in the
+workspace is checked (a repo without xplat.yaml is a warning) and a summary
+is printed. --fail-on sets the lowest severity that fails the check
+(default: error; "never" always exits 0).
+
+Output formats:
+  text    Per-repo report (default)
+  github  GitHub Actions annotations (::error file=...::message)
+  json    Results and summary as JSON
+
 Examples:
   xplat manifest check                    # Check current directory
   xplat manifest check /path/to/project   # Check specific path
-  xplat manifest check -d /workspace      # Check all plat-* repos`,
+  xplat manifest check -d /workspace      # Check all plat-* repos
+  xplat manifest check -d /workspace --fail-on=warning --format=github`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runManifestCheck,
 }
@@ -142,17 +154,40 @@ Creates or updates:
 - .github/workflows/ci.yml - Unified CI workflow
 - README.md       - Basic documentation
 - .env.example    - Environment template (if env vars defined)
+- process-compose.yaml - Processes (if processes defined)
+- wrangler.toml   - Worker config (worker archetype)
+
+Without an xplat.yaml, bootstrap asks for a project archetype and a few
+answers, then generates every file from the resulting manifest:
+  go-service  Go HTTP service run under process-compose
+  hugo-site   Hugo static site with a live-reload dev server
+  worker      Cloudflare Worker in Go, run locally with wrangler dev
+  cli         Go command-line tool with release binaries
+
+--defaults skips the questions (for CI and scripted scaffolding). When
+stdin is not a terminal and neither --defaults nor --archetype is given,
+the manifest is detected from the directory as 'xplat manifest init' does.
 
 Examples:
   xplat manifest bootstrap                  # Bootstrap current directory
   xplat manifest bootstrap /path/to/repo    # Bootstrap specific path
+  xplat manifest bootstrap --archetype=worker --defaults
   xplat manifest bootstrap --force          # Overwrite existing files
   xplat manifest bootstrap --check          # Just check conformity`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runManifestBootstrap,
 }
 
-var manifestBootstrapCheck bool
+var (
+	manifestBootstrapCheck     bool
+	manifestBootstrapArchetype string
+	manifestBootstrapDefaults  bool
+)
+
+var (
+	manifestCheckFormat string
+	manifestCheckFailOn string
+)
 
 func init() {
 	// Flags for discover commands
@@ -181,11 +216,15 @@ func init() {
 	ManifestCmd.AddCommand(manifestInitCmd)
 
 	// Check command
+	manifestCheckCmd.Flags().StringVar(&manifestCheckFormat, "format", "text", "Output format: text, github or json")
+	manifestCheckCmd.Flags().StringVar(&manifestCheckFailOn, "fail-on", "error", "Lowest severity that fails the check: error, warning, info or never")
 	ManifestCmd.AddCommand(manifestCheckCmd)
 
 	// Bootstrap command
 	manifestBootstrapCmd.Flags().BoolVarP(&manifestForce, "force", "f", false, "Overwrite existing files")
 	manifestBootstrapCmd.Flags().BoolVar(&manifestBootstrapCheck, "check", false, "Just check conformity, don't create files")
+	manifestBootstrapCmd.Flags().StringVar(&manifestBootstrapArchetype, "archetype", "", "Project archetype for a new xplat.yaml: go-service, hugo-site, worker or cli")
+	manifestBootstrapCmd.Flags().BoolVar(&manifestBootstrapDefaults, "defaults", false, "Accept default answers instead of prompting")
 	ManifestCmd.AddCommand(manifestBootstrapCmd)
 }
 
@@ -268,7 +307,11 @@ func runManifestShow(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\nProcesses:\n")
 		for name, p := range m.Processes {
 			fmt.Printf("  %s:\n", name)
-			fmt.Printf("    Command: %s\n", p.Command)
+			if p.Worker != nil {
+				fmt.Printf("    Worker: %s\n", p.Worker.Dir)
+			} else {
+				fmt.Printf("    Command: %s\n", p.Command)
+			}
 			if p.Port > 0 {
 				fmt.Printf("    Port: %d\n", p.Port)
 			}
@@ -474,6 +517,19 @@ func runManifestCheck(cmd *cobra.Command, args []string) error {
 		path = manifestDir
 	}
 
+	switch manifestCheckFormat {
+	case "text", "github", "json":
+	default:
+		return fmt.Errorf("unknown format %q (valid: text, github, json)", manifestCheckFormat)
+	}
+	var failOn manifest.Severity
+	if manifestCheckFailOn != "never" {
+		var err error
+		if failOn, err = manifest.ParseSeverity(manifestCheckFailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+
 	loader := manifest.NewLoader()
 	var results []manifest.CheckResult
 
@@ -486,7 +542,7 @@ func runManifestCheck(cmd *cobra.Command, args []string) error {
 		}
 
 		if len(results) == 0 {
-			fmt.Println("No manifests found in plat-* directories")
+			fmt.Printf("No plat-* directories found in %s\n", path)
 			return nil
 		}
 	} else {
@@ -514,29 +570,25 @@ func runManifestCheck(cmd *cobra.Command, args []string) error {
 		results = append(results, result)
 	}
 
-	// Print results
-	var hasErrors bool
-	for _, r := range results {
-		if r.IsValid() && len(r.Warnings) == 0 {
-			fmt.Printf("✓ %s (%s)\n", r.Name, r.Path)
-		} else {
-			if !r.IsValid() {
-				hasErrors = true
-				fmt.Printf("✗ %s (%s)\n", r.Name, r.Path)
-				for _, e := range r.Errors {
-					fmt.Printf("  ERROR: %s\n", e)
-				}
-			} else {
-				fmt.Printf("⚠ %s (%s)\n", r.Name, r.Path)
-			}
-			for _, w := range r.Warnings {
-				fmt.Printf("  WARN: %s\n", w)
-			}
+	switch manifestCheckFormat {
+	case "text":
+		manifest.WriteCheckReport(os.Stdout, results)
+	case "github":
+		baseDir, _ := os.Getwd()
+		manifest.WriteGitHubAnnotations(os.Stdout, results, baseDir)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(map[string]interface{}{
+			"results": results,
+			"summary": manifest.Summarize(results),
+		}); err != nil {
+			return err
 		}
 	}
 
-	if hasErrors {
-		return fmt.Errorf("validation failed")
+	if failOn != "" && manifest.FailsOn(results, failOn) {
+		return fmt.Errorf("manifest check failed (--fail-on=%s)", failOn)
 	}
 	return nil
 }
@@ -577,6 +629,24 @@ func runManifestBootstrap(cmd *cobra.Command, args []string) error {
 		Verbose: manifestVerbose,
 	}
 
+	// New project: ask for the archetype and answers that shape xplat.yaml
+	if _, err := os.Stat(filepath.Join(path, manifest.ManifestFileName)); os.IsNotExist(err) {
+		spec, err := manifest.DefaultProjectSpec(path, manifestBootstrapArchetype)
+		if err != nil {
+			return err
+		}
+		switch {
+		case manifestBootstrapDefaults:
+			opts.Spec = &spec
+		case stdinIsTerminal():
+			spec = manifest.RunWizard(os.Stdin, os.Stdout, spec)
+			fmt.Println()
+			opts.Spec = &spec
+		case manifestBootstrapArchetype != "":
+			opts.Spec = &spec
+		}
+	}
+
 	result, err := manifest.Bootstrap(path, opts)
 	if err != nil {
 		return err
@@ -615,3 +685,9 @@ func runManifestBootstrap(cmd *cobra.Command, args []string) error {
 	fmt.Printf("\nBootstrap complete for %s\n", path)
 	return nil
 }
+
+// stdinIsTerminal reports whether stdin is interactive, so prompts can be answered.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/joeblew999/xplat/internal/osutil"
 	"github.com/spf13/cobra"
 )
 
+var (
+	osDryRun  bool
+	osVerbose bool
+)
+
 // OsCmd is the parent command for cross-platform OS utilities.
 var OsCmd = &cobra.Command{
 	Use:   "os",
@@ -25,7 +33,7 @@ Environment & Text:
   env      - Get environment variable
   envsubst - Substitute environment variables in text
   glob     - Expand glob pattern
-  jq       - Process JSON with jq syntax
+  jq       - Process JSON/YAML with jq syntax (-i edits files in place)
 
 Version Control:
   git      - Git operations (no git binary required)
@@ -38,16 +46,28 @@ Tools:
   which    - Find binary in managed locations or PATH
   version-file - Read/write .version file
 
+Plan Mode:
+  --dry-run  Print what would be created, copied, moved or removed, with
+             absolute paths, and change nothing
+  --verbose  Print the same plan while making the changes
+
+  Use these to check Taskfile variable expansion (e.g. Windows paths)
+  before running destructive steps. git commands do not support --dry-run.
+
 Examples:
   xplat os cat file.txt
   xplat os cp src dst -r
   xplat os envsubst --env-file .env template.yml
   xplat os glob "**/*.go"
   xplat os which go
-  xplat os fetch https://example.com/file.tar.gz`,
+  xplat os fetch https://example.com/file.tar.gz
+  xplat os rm -rf --dry-run {{.BUILD_DIR}}`,
 }
 
 func init() {
+	OsCmd.PersistentFlags().BoolVar(&osDryRun, "dry-run", false, "Print the changes with absolute paths without making them")
+	OsCmd.PersistentFlags().BoolVar(&osVerbose, "verbose", false, "Print each change as it is made")
+
 	// Add all OS utility commands as subcommands
 	OsCmd.AddCommand(CatCmd)
 	OsCmd.AddCommand(CpCmd)
@@ -66,3 +86,29 @@ func init() {
 	OsCmd.AddCommand(VersionFileCmd)
 	OsCmd.AddCommand(WhichCmd)
 }
+
+// osPlan prints an os command's plan when --dry-run or --verbose is set.
+// It returns whether the command should go ahead, or the error the plan
+// ran into. Without either flag the plan is not computed.
+func osPlan(plan func() ([]osutil.Op, error)) (bool, error) {
+	if !osDryRun && !osVerbose {
+		return true, nil
+	}
+	ops, err := plan()
+	if err != nil {
+		return false, err
+	}
+	printOsOps(ops)
+	return !osDryRun, nil
+}
+
+// printOsOps prints ops, marked as not applied in dry-run mode.
+func printOsOps(ops []osutil.Op) {
+	for _, op := range ops {
+		if osDryRun {
+			fmt.Printf("[dry-run] %s\n", op)
+		} else {
+			fmt.Println(op)
+		}
+	}
+}
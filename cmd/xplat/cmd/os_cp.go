@@ -31,7 +31,11 @@ Examples:
 		src := args[0]
 		dst := args[1]
 
-		if err := osutil.Copy(src, dst, cpRecursive); err != nil {
+		run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanCopy(src, dst, cpRecursive) })
+		if err == nil && run {
+			err = osutil.Copy(src, dst, cpRecursive)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "cp: %v\n", err)
 			os.Exit(1)
 		}
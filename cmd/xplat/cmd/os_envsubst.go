@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/a8m/envsubst"
+	"github.com/joeblew999/xplat/internal/osutil"
 	"github.com/spf13/cobra"
 )
 
@@ -109,6 +110,10 @@ func runEnvsubst(cmd *cobra.Command, args []string) error {
 	// Determine output destination
 	var output io.Writer
 	if envsubstOutput != "" {
+		run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanWrite(envsubstOutput), nil })
+		if err != nil || !run {
+			return err
+		}
 		f, err := os.Create(envsubstOutput)
 		if err != nil {
 			return fmt.Errorf("failed to create output file: %w", err)
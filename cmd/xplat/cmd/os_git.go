@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/joeblew999/xplat/internal/ghauth"
 	"github.com/joeblew999/xplat/internal/gitops"
 	"github.com/spf13/cobra"
 )
@@ -30,12 +31,31 @@ Examples:
   xplat os git checkout .src v2.0.0
   xplat os git hash .src
   xplat os git tags .src`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if osDryRun {
+			return fmt.Errorf("git commands do not support --dry-run")
+		}
+		return nil
+	},
 }
 
+var gitCloneNoAuth bool
+
 var gitCloneCmd = &cobra.Command{
 	Use:   "clone <url> <path> [version]",
 	Short: "Clone a repository (shallow)",
-	Args:  cobra.RangeArgs(2, 3),
+	Long: `Clone a repository (shallow).
+
+HTTPS clones from github.com authenticate with the first credential found,
+so private repos clone in CI without configuring git credential helpers:
+
+  1. GITHUB_TOKEN or GH_TOKEN
+  2. The gh CLI config (gh auth login)
+  3. A GitHub App: GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID and
+     GITHUB_APP_PRIVATE_KEY (PEM) or GITHUB_APP_PRIVATE_KEY_PATH
+
+Use --no-auth to clone anonymously.`,
+	Args: cobra.RangeArgs(2, 3),
 	Run: func(cmd *cobra.Command, args []string) {
 		url := args[0]
 		path := args[1]
@@ -44,13 +64,26 @@ var gitCloneCmd = &cobra.Command{
 			version = args[2]
 		}
 
+		token := ""
+		if !gitCloneNoAuth && ghauth.IsGitHubURL(url) {
+			cred, err := ghauth.ResolveCredential(cmd.Context())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "git clone: %v\n", err)
+				os.Exit(1)
+			}
+			if cred != nil {
+				token = cred.Token
+				fmt.Printf("Using GitHub credentials from %s\n", cred.Source)
+			}
+		}
+
 		fmt.Printf("Cloning %s to %s", url, path)
 		if version != "" {
 			fmt.Printf(" @ %s", version)
 		}
 		fmt.Println()
 
-		if err := gitops.Clone(url, path, version); err != nil {
+		if err := gitops.CloneWithToken(url, path, version, token); err != nil {
 			fmt.Fprintf(os.Stderr, "git clone: %v\n", err)
 			os.Exit(1)
 		}
@@ -319,6 +352,7 @@ var gitPushCmd = &cobra.Command{
 }
 
 func init() {
+	gitCloneCmd.Flags().BoolVar(&gitCloneNoAuth, "no-auth", false, "Clone without GitHub credentials")
 	gitFetchCmd.Flags().BoolVar(&gitFetchTags, "tags", false, "Fetch tags as well")
 	gitHashCmd.Flags().BoolVar(&gitHashFull, "full", false, "Show full commit hash")
 	gitCommitCmd.Flags().StringP("message", "m", "", "Commit message")
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/itchyny/gojq"
+	"github.com/joeblew999/xplat/internal/osutil"
 	"github.com/spf13/cobra"
 )
 
@@ -18,12 +19,20 @@ var JqCmd = &cobra.Command{
 	Long: `Process JSON using jq query syntax (powered by gojq).
 
 Reads JSON from file or stdin, applies the query, and outputs results.
+Files ending in .yaml or .yml are read as YAML.
+
+With -i the query's result is written back to the file instead of printed,
+keeping its key order, indentation, line endings and (for YAML) comments.
+Use it in Taskfiles instead of sed for structured edits; it behaves the
+same on Windows. --dry-run shows the write without making it.
 
 Examples:
   xplat os jq '.name' package.json
   echo '{"foo":"bar"}' | xplat os jq '.foo'
   xplat os jq '.assets[].name' < releases.json
   xplat os jq -r '.version' package.json
+  xplat os jq -i '.version = "1.2.3"' package.json
+  xplat os jq -i '.vars.VERSION = "1.2.3"' Taskfile.yml
 
 Common queries:
   .              Identity (pretty-print)
@@ -40,15 +49,17 @@ Common queries:
 }
 
 var (
-	jqRaw   bool
-	jqSlurp bool
-	jqNull  bool
+	jqRaw     bool
+	jqSlurp   bool
+	jqNull    bool
+	jqInPlace bool
 )
 
 func init() {
 	JqCmd.Flags().BoolVarP(&jqRaw, "raw-output", "r", false, "Output raw strings without quotes")
 	JqCmd.Flags().BoolVarP(&jqSlurp, "slurp", "s", false, "Read entire input into array")
 	JqCmd.Flags().BoolVarP(&jqNull, "null-input", "n", false, "Don't read input, use null")
+	JqCmd.Flags().BoolVarP(&jqInPlace, "in-place", "i", false, "Write the result back to the JSON/YAML file")
 }
 
 func runJq(cmd *cobra.Command, args []string) error {
@@ -66,6 +77,32 @@ func runJq(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("compile error: %w", err)
 	}
 
+	if jqInPlace {
+		if len(args) < 2 {
+			return fmt.Errorf("-i requires a file argument")
+		}
+		if jqSlurp || jqNull {
+			return fmt.Errorf("-i cannot be combined with -s or -n")
+		}
+		return runJqInPlace(code, args[1])
+	}
+
+	// YAML files are decoded to the same values as JSON
+	if len(args) > 1 && osutil.IsYAMLFile(args[1]) && !jqNull {
+		data, err := os.ReadFile(args[1])
+		if err != nil {
+			return fmt.Errorf("cannot open file: %w", err)
+		}
+		v, err := osutil.DecodeYAML(data)
+		if err != nil {
+			return err
+		}
+		if jqSlurp {
+			return runQuery(code, []interface{}{v})
+		}
+		return runQuery(code, v)
+	}
+
 	// Determine input source
 	var input io.Reader
 	if len(args) > 1 {
@@ -119,6 +156,62 @@ func runJq(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runJqInPlace applies the query to a JSON or YAML file and writes the
+// single result back in the file's own style.
+func runJqInPlace(code *gojq.Code, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot open file: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	isYAML := osutil.IsYAMLFile(path)
+	var input interface{}
+	if isYAML {
+		input, err = osutil.DecodeYAML(data)
+	} else if err = json.Unmarshal(data, &input); err != nil {
+		err = fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	var results []interface{}
+	iter := code.Run(input)
+	for {
+		v, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := v.(error); ok {
+			return err
+		}
+		results = append(results, v)
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("-i needs exactly one result to write, query produced %d", len(results))
+	}
+
+	var out []byte
+	if isYAML {
+		out, err = osutil.ReplaceYAML(data, results[0])
+	} else {
+		out, err = osutil.ReplaceJSON(data, results[0])
+	}
+	if err != nil {
+		return err
+	}
+
+	run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanWrite(path), nil })
+	if err != nil || !run {
+		return err
+	}
+	return os.WriteFile(path, out, info.Mode().Perm())
+}
+
 func runQuery(code *gojq.Code, input interface{}) error {
 	iter := code.Run(input)
 	for {
@@ -30,7 +30,11 @@ Examples:
 		hasError := false
 
 		for _, path := range args {
-			if err := osutil.Mkdir(path, mkdirParents); err != nil {
+			run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanMkdir(path, mkdirParents) })
+			if err == nil && run {
+				err = osutil.Mkdir(path, mkdirParents)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "mkdir: %s: %v\n", path, err)
 				hasError = true
 			}
@@ -26,7 +26,11 @@ Examples:
 		src := args[0]
 		dst := args[1]
 
-		if err := osutil.Move(src, dst); err != nil {
+		run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanMove(src, dst) })
+		if err == nil && run {
+			err = osutil.Move(src, dst)
+		}
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "mv: %s: %v\n", src, err)
 			os.Exit(1)
 		}
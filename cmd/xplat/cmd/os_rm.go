@@ -34,7 +34,11 @@ Examples:
 		hasError := false
 
 		for _, path := range args {
-			if err := osutil.Remove(path, rmRecursive, rmForce); err != nil {
+			run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanRemove(path, rmRecursive, rmForce) })
+			if err == nil && run {
+				err = osutil.Remove(path, rmRecursive, rmForce)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "rm: %s: %v\n", path, err)
 				hasError = true
 			}
@@ -26,7 +26,11 @@ Examples:
 		hasError := false
 
 		for _, path := range args {
-			if err := osutil.Touch(path); err != nil {
+			run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanTouch(path) })
+			if err == nil && run {
+				err = osutil.Touch(path)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "touch: %s: %v\n", path, err)
 				hasError = true
 			}
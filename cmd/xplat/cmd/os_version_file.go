@@ -5,6 +5,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/joeblew999/xplat/internal/osutil"
 	"github.com/spf13/cobra"
 )
 
@@ -47,7 +48,11 @@ Taskfile usage:
 		// Write mode
 		if versionFileSet != "" {
 			version := strings.TrimSpace(versionFileSet)
-			if err := os.WriteFile(path, []byte(version+"\n"), 0644); err != nil {
+			run, err := osPlan(func() ([]osutil.Op, error) { return osutil.PlanWrite(path), nil })
+			if err == nil && run {
+				err = os.WriteFile(path, []byte(version+"\n"), 0644)
+			}
+			if err != nil {
 				fmt.Fprintf(os.Stderr, "version-file: %v\n", err)
 				os.Exit(1)
 			}
@@ -16,7 +16,7 @@
 //
 // COMMANDS:
 //   xplat os which <tool>           -> just the path (scripts use this)
-//   xplat os which <tool> --all     -> all locations (debugging conflicts)
+//   xplat os which <tool> --all     -> all locations in PATH order, shadowing, pins
 //   xplat os which doctor <tool>    -> full diagnostics (versions, conflicts)
 //   Add --json to any for machine-parseable output
 //
@@ -37,6 +37,8 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/manifest"
 	"github.com/joeblew999/xplat/internal/taskfile"
 	"github.com/spf13/cobra"
 )
@@ -48,11 +50,12 @@ var (
 
 // WhichResult represents a single binary location (JSON-serializable)
 type WhichResult struct {
-	Tool    string `json:"tool"`
-	Path    string `json:"path"`
-	Source  string `json:"source"` // taskfile, convention, path, brew
-	Version string `json:"version,omitempty"`
-	Active  bool   `json:"active"`
+	Tool      string `json:"tool"`
+	Path      string `json:"path"`
+	Source    string `json:"source"` // project, taskfile, convention, path, brew, system
+	Version   string `json:"version,omitempty"`
+	Active    bool   `json:"active"`
+	PathOrder int    `json:"path_order,omitempty"` // 1-based position in PATH, 0 if not on PATH
 }
 
 // WhichDoctorResult for full diagnostics (JSON-serializable)
@@ -61,7 +64,8 @@ type WhichDoctorResult struct {
 	Found           bool          `json:"found"`
 	ActivePath      string        `json:"active_path,omitempty"`
 	ActiveVersion   string        `json:"active_version,omitempty"`
-	ExpectedVersion string        `json:"expected_version,omitempty"` // From xplat.env
+	ExpectedVersion string        `json:"expected_version,omitempty"` // From xplat.env or an xplat.yaml pin
+	ExpectedSource  string        `json:"expected_source,omitempty"`  // xplat.env or the xplat.yaml path
 	VersionMatch    *bool         `json:"version_match,omitempty"`    // nil if can't compare
 	Taskfile        string        `json:"taskfile,omitempty"`
 	Archetype       string        `json:"archetype,omitempty"`
@@ -70,6 +74,7 @@ type WhichDoctorResult struct {
 	Status          string        `json:"status"` // ok, warn, error
 	Issues          []string      `json:"issues,omitempty"`
 	Warnings        []string      `json:"warnings,omitempty"`
+	PathWarnings    []string      `json:"path_warnings,omitempty"` // PATH problems unrelated to the tool
 }
 
 // WhichCmd finds a binary in managed locations or PATH
@@ -82,7 +87,9 @@ Checks xplat-managed locations first by reflecting off Taskfiles,
 then falls back to PATH. This allows users to find tools without
 modifying their shell configuration.
 
-Use --all to show ALL locations where the binary exists.
+Use --all to show ALL locations where the binary exists, in PATH order,
+with shadowing between the project .bin and global installs, version
+mismatches against xplat.env / xplat.yaml pins, and PATH problems.
 Use --json for machine-parseable output.
 Use 'xplat os which doctor <tool>' for detailed diagnostics.
 
@@ -162,9 +169,11 @@ func outputJSON(v interface{}) {
 	_ = enc.Encode(v)
 }
 
-// runWhichAll shows all locations where the binary exists
+// runWhichAll shows all locations where the binary exists, PATH matches first
+// in PATH order, followed by managed locations that are not on PATH.
 func runWhichAll(name string) {
 	home, _ := os.UserHomeDir()
+	root := getRepoRoot()
 	result := WhichDoctorResult{
 		Tool:          name,
 		Installations: []WhichResult{},
@@ -181,12 +190,46 @@ func runWhichAll(name string) {
 		result.ActiveVersion = getToolVersion(activePath, name)
 	}
 
+	// PATH locations, in PATH order
+	projectBin := ""
+	if root != "" {
+		projectBin = config.PlatBin(root)
+	}
+	for _, inst := range findAllInPathWithVersions(name) {
+		source := inst.Source
+		if projectBin != "" && samePath(filepath.Dir(inst.Path), projectBin) {
+			source = "project"
+		}
+		result.Installations = append(result.Installations, WhichResult{
+			Tool:      name,
+			Path:      inst.Path,
+			Source:    source,
+			Version:   inst.Version,
+			Active:    inst.Path == activePath,
+			PathOrder: inst.PathOrder,
+		})
+	}
+
+	// Project .bin (PLAT_BIN), used by 'xplat task' even when not on PATH
+	if projectBin != "" {
+		loc := filepath.Join(projectBin, name+taskfile.ExeExt())
+		if fileExists(loc) && !containsResultPath(result.Installations, loc) {
+			result.Installations = append(result.Installations, WhichResult{
+				Tool:    name,
+				Path:    loc,
+				Source:  "project",
+				Version: getToolVersion(loc, name),
+				Active:  loc == activePath,
+			})
+		}
+	}
+
 	// Check Taskfile location
 	tf := findTaskfileForTool(name)
 	if tf != nil {
 		result.Taskfile = tf.Path
 		if path := getInstallPathFromTaskfile(tf, name, home); path != "" {
-			if fileExists(path) {
+			if fileExists(path) && !containsResultPath(result.Installations, path) {
 				version := getToolVersion(path, name)
 				result.Installations = append(result.Installations, WhichResult{
 					Tool:    name,
@@ -213,26 +256,27 @@ func runWhichAll(name string) {
 		}
 	}
 
-	// Check PATH locations
-	for _, inst := range findAllInPathWithVersions(name) {
-		if !containsResultPath(result.Installations, inst.Path) {
-			result.Installations = append(result.Installations, WhichResult{
-				Tool:    name,
-				Path:    inst.Path,
-				Source:  inst.Source,
-				Version: inst.Version,
-				Active:  inst.Path == activePath,
-			})
+	// Diagnose shadowing and version pins
+	result.Warnings = append(result.Warnings, shadowWarnings(result.Installations)...)
+	result.ExpectedVersion, result.ExpectedSource = getExpectedVersion(name)
+	if result.ExpectedVersion != "" {
+		if result.ActiveVersion != "" {
+			match := versionMatches(result.ActiveVersion, result.ExpectedVersion)
+			result.VersionMatch = &match
 		}
+		result.Warnings = append(result.Warnings, pinWarnings(result.Installations, result.ExpectedVersion, result.ExpectedSource)...)
 	}
+	result.PathWarnings = diagnosePath(os.Getenv("PATH"))
 
 	// Set status
 	if len(result.Installations) > 1 {
-		result.Status = "warn"
 		result.Warnings = append(result.Warnings, fmt.Sprintf("Multiple installations found (%d)", len(result.Installations)))
-	} else if len(result.Installations) == 0 {
+	}
+	if len(result.Installations) == 0 {
 		result.Status = "error"
 		result.Issues = append(result.Issues, "Not found")
+	} else if len(result.Warnings) > 0 {
+		result.Status = "warn"
 	} else {
 		result.Status = "ok"
 	}
@@ -248,21 +292,35 @@ func runWhichAll(name string) {
 		if result.ActiveVersion != "" {
 			fmt.Printf("version=%s\n", result.ActiveVersion)
 		}
+		if result.ExpectedVersion != "" {
+			fmt.Printf("expected=%s (%s)\n", result.ExpectedVersion, result.ExpectedSource)
+		}
 		if result.Taskfile != "" {
 			fmt.Printf("taskfile=%s\n", result.Taskfile)
 		}
 		fmt.Printf("installations=%d\n", len(result.Installations))
 		for _, inst := range result.Installations {
-			activeMarker := ""
+			line := fmt.Sprintf("  %s [%s]", inst.Path, inst.Source)
+			if inst.PathOrder > 0 {
+				line += fmt.Sprintf(" order=%d", inst.PathOrder)
+			} else {
+				line += " order=-"
+			}
+			if inst.Version != "" {
+				line += " version=" + inst.Version
+			}
 			if inst.Active {
-				activeMarker = " active"
+				line += " active"
 			}
-			fmt.Printf("  %s [%s]%s\n", inst.Path, inst.Source, activeMarker)
+			fmt.Println(line)
 		}
 		fmt.Printf("status=%s\n", result.Status)
 		for _, w := range result.Warnings {
 			fmt.Printf("warning=%s\n", w)
 		}
+		for _, w := range result.PathWarnings {
+			fmt.Printf("path_warning=%s\n", w)
+		}
 	}
 
 	if !result.Found {
@@ -291,10 +349,11 @@ func runWhichDoctor(name string) {
 		result.ActiveVersion = getToolVersion(activePath, name)
 	}
 
-	// Get expected version from xplat.env (source of truth)
-	expectedVersion := getExpectedVersionFromEnv(name)
+	// Get expected version from xplat.env (source of truth) or an xplat.yaml pin
+	expectedVersion, expectedSource := getExpectedVersion(name)
 	if expectedVersion != "" {
 		result.ExpectedVersion = expectedVersion
+		result.ExpectedSource = expectedSource
 	}
 
 	// Check Taskfile configuration
@@ -436,8 +495,87 @@ func containsResultPath(installations []WhichResult, path string) bool {
 	return false
 }
 
+// shadowWarnings reports where a project .bin install and a global install
+// hide each other. installations must list PATH matches first, in PATH order.
+func shadowWarnings(installations []WhichResult) []string {
+	var project *WhichResult
+	var global []WhichResult
+	for i := range installations {
+		if installations[i].Source == "project" {
+			if project == nil {
+				project = &installations[i]
+			}
+		} else if installations[i].PathOrder > 0 {
+			global = append(global, installations[i])
+		}
+	}
+	if project == nil || len(global) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	first := global[0]
+	switch {
+	case project.PathOrder == 0:
+		warnings = append(warnings, fmt.Sprintf("Project %s is not on PATH: 'xplat task' runs it, the shell runs %s", project.Path, first.Path))
+	case first.PathOrder < project.PathOrder:
+		warnings = append(warnings, fmt.Sprintf("Project %s is shadowed by %s (earlier in PATH)", project.Path, first.Path))
+	default:
+		for _, g := range global {
+			warnings = append(warnings, fmt.Sprintf("Project %s shadows global %s", project.Path, g.Path))
+		}
+	}
+	return warnings
+}
+
+// pinWarnings reports installations whose version differs from the pinned one.
+func pinWarnings(installations []WhichResult, expected, source string) []string {
+	var warnings []string
+	for _, inst := range installations {
+		if inst.Version != "" && !versionMatches(inst.Version, expected) {
+			warnings = append(warnings, fmt.Sprintf("Version mismatch: %s is %s, %s pins %s", inst.Path, inst.Version, source, expected))
+		}
+	}
+	return warnings
+}
+
+// diagnosePath reports duplicate, relative and missing PATH entries.
+func diagnosePath(pathEnv string) []string {
+	var warnings []string
+	seen := make(map[string]int)
+	for i, dir := range filepath.SplitList(pathEnv) {
+		order := i + 1
+		if dir == "" {
+			warnings = append(warnings, fmt.Sprintf("PATH entry %d is empty (means the current directory)", order))
+			continue
+		}
+		clean := filepath.Clean(dir)
+		if prev, ok := seen[clean]; ok {
+			warnings = append(warnings, fmt.Sprintf("PATH entry %d duplicates entry %d: %s", order, prev, dir))
+			continue
+		}
+		seen[clean] = order
+		if !filepath.IsAbs(dir) {
+			warnings = append(warnings, fmt.Sprintf("PATH entry %d is relative: %s", order, dir))
+		} else if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			warnings = append(warnings, fmt.Sprintf("PATH entry %d does not exist: %s", order, dir))
+		}
+	}
+	return warnings
+}
+
 // --- Helper functions ---
 
+// samePath compares two directories after cleaning and resolving symlinks.
+func samePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
+
 func getToolVersion(path string, name string) string {
 	versionFlags := []string{"--version", "-v", "version", "-V"}
 	for _, flag := range versionFlags {
@@ -503,10 +641,11 @@ func versionMatches(installed, expected string) bool {
 
 // Installation represents a found binary installation (internal)
 type Installation struct {
-	Path     string
-	Source   string
-	Version  string
-	IsActive bool
+	Path      string
+	Source    string
+	Version   string
+	IsActive  bool
+	PathOrder int // 1-based position in PATH, 0 if found outside PATH
 }
 
 func findAllInPathWithVersions(name string) []Installation {
@@ -514,7 +653,7 @@ func findAllInPathWithVersions(name string) []Installation {
 	pathEnv := os.Getenv("PATH")
 	seen := make(map[string]bool)
 
-	for _, dir := range filepath.SplitList(pathEnv) {
+	for i, dir := range filepath.SplitList(pathEnv) {
 		if dir == "" {
 			continue
 		}
@@ -528,9 +667,10 @@ func findAllInPathWithVersions(name string) []Installation {
 			}
 			version := getToolVersion(binPath, name)
 			found = append(found, Installation{
-				Path:    binPath,
-				Source:  source,
-				Version: version,
+				Path:      binPath,
+				Source:    source,
+				Version:   version,
+				PathOrder: i + 1,
 			})
 			seen[binPath] = true
 		}
@@ -679,6 +819,31 @@ func getRepoRoot() string {
 	return root
 }
 
+// getExpectedVersion returns the expected version of a tool and where it was
+// pinned: xplat.env first, then the binary source version of an xplat.yaml
+// in the repo whose binary.name is the tool.
+func getExpectedVersion(toolName string) (version, source string) {
+	if v := getExpectedVersionFromEnv(toolName); v != "" {
+		return v, "xplat.env"
+	}
+	root := getRepoRoot()
+	if root == "" {
+		return "", ""
+	}
+	eco, err := manifest.DetectEcosystems(root)
+	if err != nil {
+		return "", ""
+	}
+	for _, pin := range eco.XplatPins {
+		// Branch pins (e.g. "main") can't be compared with --version output
+		v := strings.TrimPrefix(pin.Version, "v")
+		if pin.Binary == toolName && v != "" && v[0] >= '0' && v[0] <= '9' {
+			return pin.Version, pin.File
+		}
+	}
+	return "", ""
+}
+
 // getExpectedVersionFromEnv reads TOOL_VERSION from xplat.env
 // This is the source of truth for expected versions (Taskfiles use {{.TOOL_VERSION}})
 func getExpectedVersionFromEnv(toolName string) string {
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShadowWarnings(t *testing.T) {
+	project := func(order int) WhichResult {
+		return WhichResult{Path: "/repo/.bin/foo", Source: "project", PathOrder: order}
+	}
+	global := WhichResult{Path: "/usr/local/bin/foo", Source: "system", PathOrder: 2}
+
+	tests := []struct {
+		name          string
+		installations []WhichResult
+		want          string
+	}{
+		{"project first", []WhichResult{project(1), global}, "shadows global /usr/local/bin/foo"},
+		{"global first", []WhichResult{{Path: "/usr/bin/foo", Source: "path", PathOrder: 1}, project(3)}, "is shadowed by /usr/bin/foo"},
+		{"project not on PATH", []WhichResult{global, project(0)}, "is not on PATH"},
+		{"global only", []WhichResult{global}, ""},
+		{"project only", []WhichResult{project(1)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shadowWarnings(tt.installations)
+			if tt.want == "" {
+				if len(got) != 0 {
+					t.Errorf("shadowWarnings() = %v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || !strings.Contains(got[0], tt.want) {
+				t.Errorf("shadowWarnings() = %v, want one containing %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPinWarnings(t *testing.T) {
+	installations := []WhichResult{
+		{Path: "/a/foo", Version: "1.3.0"},
+		{Path: "/b/foo", Version: "1.2.0"},
+		{Path: "/c/foo"}, // version unknown
+	}
+	got := pinWarnings(installations, "v1.3.0", "xplat.yaml")
+	if len(got) != 1 || !strings.Contains(got[0], "/b/foo is 1.2.0, xplat.yaml pins v1.3.0") {
+		t.Errorf("pinWarnings() = %v", got)
+	}
+}
+
+func TestDiagnosePath(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	pathEnv := strings.Join([]string{dir, "relative/bin", dir + string(filepath.Separator), missing, ""}, string(filepath.ListSeparator))
+
+	got := diagnosePath(pathEnv)
+	want := []string{
+		"PATH entry 2 is relative: relative/bin",
+		"PATH entry 3 duplicates entry 1",
+		"PATH entry 4 does not exist: " + missing,
+		"PATH entry 5 is empty",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("diagnosePath() = %v, want %d warnings", got, len(want))
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("warning %d = %q, want prefix %q", i, got[i], want[i])
+		}
+	}
+}
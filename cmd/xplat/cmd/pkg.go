@@ -38,6 +38,8 @@ Each package can include:
 - A CLI binary (installed to ~/.local/bin)
 - A remote Taskfile include (added to your Taskfile.yml)
 - A process configuration (added to process-compose.yaml)
+- A file bundle (taskfiles, process-compose fragments, Caddy snippets)
+  copied into your project with parameter substitution
 
 Compare with:
   - 'xplat gen' generates files from YOUR LOCAL xplat.yaml
@@ -53,6 +55,7 @@ Examples:
   xplat pkg info mailerlite         # Show package details
   xplat pkg install mailerlite      # Install binary + add taskfile
   xplat pkg install mailerlite --with-process  # Also add to process-compose.yaml
+  xplat pkg upgrade mailerlite      # Update to the latest version
   xplat pkg remove mailerlite       # Remove binary + taskfile include`,
 }
 
@@ -64,11 +67,27 @@ var pkgInstallCmd = &cobra.Command{
 This will:
 1. Download and install the binary (if package has one)
 2. Add a remote taskfile include to your Taskfile.yml
+3. Copy the package's file bundle into the project (if it has one)
 
 The taskfile include uses Task's remote include feature:
   https://taskfile.dev/experiments/remote-taskfiles/
 
-Requires TASK_X_REMOTE_TASKFILES=1 environment variable.`,
+Requires TASK_X_REMOTE_TASKFILES=1 environment variable.
+
+Bundle files may contain ${xplat.<param>} placeholders: project, package,
+version, and the parameters the package declares (e.g. port). Set them
+with --param; they are recorded in xplat-lock.yaml together with a hash
+of each installed file, so 'xplat pkg upgrade' can update files you have
+not edited and leaves edited ones alone.
+
+Deprecated versions and versions with known vulnerabilities (from the
+registry index, see 'xplat binary audit') are reported as warnings, or
+refused with --strict.
+
+Examples:
+  xplat pkg install caddy
+  xplat pkg install caddy --param port=8443
+  xplat pkg install caddy --strict`,
 	Args: cobra.ExactArgs(1),
 	RunE: runPkgInstall,
 }
@@ -93,6 +112,29 @@ var pkgRemoveCmd = &cobra.Command{
 	RunE:  runPkgRemove,
 }
 
+var pkgUpgradeCmd = &cobra.Command{
+	Use:   "upgrade <package>",
+	Short: "Upgrade an installed package to its latest version",
+	Long: `Upgrade a package installed with 'xplat pkg install'.
+
+Updates the binary and the taskfile include to the latest version, and
+re-renders the file bundle with the parameters recorded at install time
+(plus any --param overrides):
+  - files you have not edited are replaced, or removed if dropped from the bundle
+  - files you have edited are left alone (use --force to overwrite them)
+
+The new version is checked for deprecations and known vulnerabilities like
+'xplat pkg install': findings are warnings, or refuse the upgrade with --strict.
+
+Examples:
+  xplat pkg upgrade caddy
+  xplat pkg upgrade caddy --param port=9443
+  xplat pkg upgrade caddy --force
+  xplat pkg upgrade caddy --strict`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPkgUpgrade,
+}
+
 var pkgAddProcessCmd = &cobra.Command{
 	Use:   "add-process <package>",
 	Short: "Add a package's process to process-compose.yaml",
@@ -127,6 +169,9 @@ var (
 	pkgNoBinary      bool   // Skip binary install
 	pkgWithProcess   bool   // Also add to process-compose.yaml
 	pkgProcessConfig string // Path to process-compose.yaml
+	pkgNoFiles       bool   // Skip file bundle
+	pkgParams        map[string]string
+	pkgStrict        bool // Refuse deprecated or vulnerable versions
 )
 
 func init() {
@@ -136,6 +181,14 @@ func init() {
 	pkgInstallCmd.Flags().BoolVar(&pkgNoBinary, "no-binary", false, "Skip binary installation")
 	pkgInstallCmd.Flags().BoolVar(&pkgWithProcess, "with-process", false, "Also add process to process-compose.yaml")
 	pkgInstallCmd.Flags().StringVar(&pkgProcessConfig, "process-config", config.ProcessComposeGeneratedFile, "Path to process-compose config")
+	pkgInstallCmd.Flags().BoolVar(&pkgNoFiles, "no-files", false, "Skip installing the file bundle")
+	pkgInstallCmd.Flags().StringToStringVar(&pkgParams, "param", nil, "Bundle parameter (name=value, repeatable)")
+	pkgInstallCmd.Flags().BoolVar(&pkgStrict, "strict", false, "Fail instead of warning when the version is deprecated or has known vulnerabilities")
+
+	pkgUpgradeCmd.Flags().StringVar(&pkgTaskfile, "taskfile", config.DefaultTaskfile, "Path to Taskfile.yml")
+	pkgUpgradeCmd.Flags().BoolVar(&pkgForce, "force", false, "Overwrite locally edited bundle files")
+	pkgUpgradeCmd.Flags().StringToStringVar(&pkgParams, "param", nil, "Bundle parameter override (name=value, repeatable)")
+	pkgUpgradeCmd.Flags().BoolVar(&pkgStrict, "strict", false, "Fail instead of warning when the new version is deprecated or has known vulnerabilities")
 
 	pkgRemoveCmd.Flags().StringVar(&pkgTaskfile, "taskfile", config.DefaultTaskfile, "Path to Taskfile.yml")
 	pkgRemoveCmd.Flags().StringVar(&pkgProcessConfig, "process-config", config.ProcessComposeGeneratedFile, "Path to process-compose config")
@@ -147,6 +200,7 @@ func init() {
 	PkgCmd.AddCommand(pkgInfoCmd)
 	PkgCmd.AddCommand(pkgListCmd)
 	PkgCmd.AddCommand(pkgRemoveCmd)
+	PkgCmd.AddCommand(pkgUpgradeCmd)
 	PkgCmd.AddCommand(pkgAddProcessCmd)
 	PkgCmd.AddCommand(pkgRemoveProcessCmd)
 	PkgCmd.AddCommand(pkgListProcessesCmd)
@@ -162,13 +216,18 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to find package: %w", err)
 	}
 
+	if err := checkAdvisories(client, pkg.Name, pkg.GitHubRepo(), pkg.Version, pkgStrict); err != nil {
+		return err
+	}
+
 	fmt.Printf("Installing %s %s...\n", pkg.Name, pkg.Version)
 
 	var installedBinary, installedTaskfile, installedProcess bool
+	var installedFiles []lockfile.ManagedFile
 
 	// Install binary if package has one
 	if pkg.HasBinary && !pkgNoBinary {
-		if err := installBinary(pkg); err != nil {
+		if err := installBinary(pkg, pkgForce); err != nil {
 			fmt.Printf("Warning: binary install failed: %v\n", err)
 		} else {
 			installedBinary = true
@@ -193,6 +252,16 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Copy the file bundle into the project
+	if pkg.HasFiles() && !pkgNoFiles {
+		files, err := installFiles(client, pkg, pkgParams, pkgForce)
+		if err != nil {
+			fmt.Printf("Warning: file bundle install failed: %v\n", err)
+		} else {
+			installedFiles = files
+		}
+	}
+
 	// Print summary
 	fmt.Println()
 	if installedBinary {
@@ -204,9 +273,12 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 	if installedProcess {
 		fmt.Printf("✓ Added process to %s\n", pkgProcessConfig)
 	}
+	if len(installedFiles) > 0 {
+		fmt.Printf("✓ Installed %d bundle file(s)\n", len(installedFiles))
+	}
 
-	if !installedBinary && !installedTaskfile && !installedProcess {
-		if !pkg.HasBinary && pkg.TaskfilePath == "" {
+	if !installedBinary && !installedTaskfile && !installedProcess && len(installedFiles) == 0 {
+		if !pkg.HasBinary && pkg.TaskfilePath == "" && !pkg.HasFiles() {
 			fmt.Printf("Package %s is a library with no binary or taskfile.\n", pkg.Name)
 			fmt.Printf("Import path: %s\n", pkg.ImportPath)
 		}
@@ -236,8 +308,8 @@ func runPkgInstall(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write to lockfile if anything was installed
-	if installedBinary || installedTaskfile || installedProcess {
-		if err := updateLockfile(pkg, installedBinary, installedTaskfile, installedProcess); err != nil {
+	if installedBinary || installedTaskfile || installedProcess || len(installedFiles) > 0 {
+		if err := updateLockfile(pkg, installedBinary, installedTaskfile, installedProcess, installedFiles, pkgParams); err != nil {
 			fmt.Printf("Warning: failed to update lockfile: %v\n", err)
 		}
 	}
@@ -291,6 +363,25 @@ func runPkgInfo(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if pkg.HasFiles() {
+		fmt.Println()
+		fmt.Println("File bundle:")
+		for _, f := range pkg.Files {
+			fmt.Printf("  %s -> %s\n", f.Src, f.Dest)
+		}
+		if len(pkg.Params) > 0 {
+			names := make([]string, 0, len(pkg.Params))
+			for name := range pkg.Params {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Println("  Parameters (--param name=value):")
+			for _, name := range names {
+				fmt.Printf("    %s (default: %s)\n", name, pkg.Params[name])
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -357,17 +448,154 @@ func runPkgRemove(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Remove bundle files (edited ones are kept) and forget the package
+	lf, err := lockfile.Load(".")
+	if err != nil {
+		return err
+	}
+	if installed, ok := lf.GetPackage(pkg.Name); ok {
+		changes, err := registry.RemoveBundle(".", installed.Files)
+		printFileChanges(changes)
+		if err != nil {
+			return err
+		}
+		lf.RemovePackage(pkg.Name)
+		if err := lf.Save("."); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+func runPkgUpgrade(cmd *cobra.Command, args []string) error {
+	pkgName := args[0]
+
+	lf, err := lockfile.Load(".")
+	if err != nil {
+		return err
+	}
+	installed, ok := lf.GetPackage(pkgName)
+	if !ok {
+		return fmt.Errorf("package %s is not installed (see %s)", pkgName, lockfile.FileName)
+	}
+
+	client := registry.NewClient()
+	pkg, err := client.GetPackage(pkgName)
+	if err != nil {
+		return fmt.Errorf("failed to find package: %w", err)
+	}
+
+	if installed.Version == pkg.Version {
+		fmt.Printf("%s is at %s, checking files...\n", pkg.Name, pkg.Version)
+	} else {
+		if err := checkAdvisories(client, pkg.Name, pkg.GitHubRepo(), pkg.Version, pkgStrict); err != nil {
+			return err
+		}
+		fmt.Printf("Upgrading %s %s -> %s...\n", pkg.Name, installed.Version, pkg.Version)
+	}
+
+	if installed.Binary != nil && pkg.HasBinary && installed.Version != pkg.Version {
+		if err := installBinary(pkg, true); err != nil {
+			return fmt.Errorf("failed to upgrade binary: %w", err)
+		}
+		fmt.Printf("✓ Upgraded %s binary\n", pkg.BinaryName)
+	}
+
+	if installed.Taskfile != nil && pkg.TaskfilePath != "" && installed.Taskfile.URL != pkg.TaskfileURL() {
+		if err := taskfile.SetInclude(pkgTaskfile, taskfile.Include{Name: pkg.Name, Taskfile: pkg.TaskfileURL()}); err != nil {
+			return err
+		}
+		installed.Taskfile.URL = pkg.TaskfileURL()
+		installed.Taskfile.Path = pkg.TaskfilePath
+		fmt.Printf("✓ Updated %s include in %s\n", pkg.Name, pkgTaskfile)
+	}
+
+	// Re-render the bundle with the recorded params; files dropped from the
+	// bundle are removed by ApplyBundle, so this also runs for empty bundles
+	if pkg.HasFiles() || len(installed.Files) > 0 {
+		params := make(map[string]string)
+		for k, v := range installed.Params {
+			if _, ok := pkg.Params[k]; ok { // parameter may have been dropped
+				params[k] = v
+			}
+		}
+		for k, v := range pkgParams {
+			params[k] = v
+		}
+
+		values, err := registry.BundleParams(pkg, ".", params)
+		if err != nil {
+			return err
+		}
+		rendered, err := client.RenderBundle(pkg, values)
+		if err != nil {
+			return err
+		}
+		files, changes, err := registry.ApplyBundle(".", rendered, installed.Files, pkgForce)
+		if err != nil {
+			return err
+		}
+		printFileChanges(changes)
+		installed.Files = files
+		installed.Params = params
+	}
+
+	installed.Version = pkg.Version
+	lf.AddPackage(installed)
+	return lf.Save(".")
+}
+
+// installFiles renders the package's file bundle into the current project,
+// updating files a previous install left untouched.
+func installFiles(client *registry.Client, pkg *registry.Package, overrides map[string]string, force bool) ([]lockfile.ManagedFile, error) {
+	params, err := registry.BundleParams(pkg, ".", overrides)
+	if err != nil {
+		return nil, err
+	}
+	rendered, err := client.RenderBundle(pkg, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var ledger []lockfile.ManagedFile
+	if lf, err := lockfile.Load("."); err == nil {
+		if installed, ok := lf.GetPackage(pkg.Name); ok {
+			ledger = installed.Files
+		}
+	}
+
+	files, changes, err := registry.ApplyBundle(".", rendered, ledger, force)
+	printFileChanges(changes)
+	return files, err
+}
+
+// printFileChanges prints one line per bundle file change.
+func printFileChanges(changes []registry.FileChange) {
+	for _, c := range changes {
+		switch c.Action {
+		case registry.FileUnchanged:
+			continue
+		case registry.FileModified:
+			fmt.Printf("  ! %s edited locally, not updated (use --force to overwrite)\n", c.Path)
+		case registry.FileConflict:
+			fmt.Printf("  ! %s already exists and was not installed by this package, skipped (use --force to overwrite)\n", c.Path)
+		case registry.FileKept:
+			fmt.Printf("  ! %s edited locally, kept but no longer managed\n", c.Path)
+		default:
+			fmt.Printf("  %s %s\n", c.Action, c.Path)
+		}
+	}
+}
+
 // installBinary installs the package binary using xplat binary install
-func installBinary(pkg *registry.Package) error {
+func installBinary(pkg *registry.Package, force bool) error {
 	if pkg.BinaryName == "" {
 		return fmt.Errorf("package has no binary name")
 	}
 
 	// Check if already installed (unless force)
-	if !pkgForce {
+	if !force {
 		ext := osutil.BinaryExtension()
 		if path, err := exec.LookPath(pkg.BinaryName + ext); err == nil {
 			fmt.Printf("Binary %s already installed at %s\n", pkg.BinaryName, path)
@@ -384,9 +612,11 @@ func installBinary(pkg *registry.Package) error {
 		pkg.GitHubRepo(),
 	}
 
-	if pkgForce {
+	if force {
 		binaryArgs = append(binaryArgs, "--force")
 	}
+	// Already checked by pkg install and pkg upgrade
+	binaryArgs = append(binaryArgs, "--skip-advisories")
 
 	// Run as subprocess to reuse existing logic
 	xplatPath, err := os.Executable()
@@ -422,7 +652,8 @@ func installTaskfile(pkg *registry.Package) error {
 		Taskfile: pkg.TaskfileURL(),
 	}
 
-	return taskfile.AddInclude(pkgTaskfile, include)
+	// With --force, point the existing include at the package's taskfile
+	return taskfile.SetInclude(pkgTaskfile, include)
 }
 
 // removeBinary removes the installed binary
@@ -571,7 +802,7 @@ func runPkgListProcesses(cmd *cobra.Command, args []string) error {
 }
 
 // updateLockfile adds the installed package to xplat-lock.yaml
-func updateLockfile(pkg *registry.Package, hasBinary, hasTaskfile, hasProcess bool) error {
+func updateLockfile(pkg *registry.Package, hasBinary, hasTaskfile, hasProcess bool, files []lockfile.ManagedFile, params map[string]string) error {
 	lf, err := lockfile.Load(".")
 	if err != nil {
 		return err
@@ -606,6 +837,15 @@ func updateLockfile(pkg *registry.Package, hasBinary, hasTaskfile, hasProcess bo
 		}
 	}
 
+	if len(files) > 0 {
+		lfPkg.Files = files
+		lfPkg.Params = params
+	} else if prev, ok := lf.GetPackage(pkg.Name); ok {
+		// Keep the ledger of a previous install (e.g. reinstall with --no-files)
+		lfPkg.Files = prev.Files
+		lfPkg.Params = prev.Params
+	}
+
 	lf.AddPackage(lfPkg)
 	return lf.Save(".")
 }
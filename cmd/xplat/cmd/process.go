@@ -59,6 +59,7 @@ import (
 
 	pccmd "github.com/f1bonacc1/process-compose/src/cmd"
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/processcompose"
 	"github.com/spf13/cobra"
 )
 
@@ -94,6 +95,7 @@ Commands:
   recipe               Manage community recipes
   run <process>        Run single process in foreground
   tools                xplat-specific tooling (lint, fmt)
+  postmortems          Post-mortems of processes that exited non-zero
 
 New in v1.87.0:
   - Dependency Graph: visualize process dependencies
@@ -126,7 +128,16 @@ Config files (searched in order):
   - pc.yml
   - process-compose.generated.yaml
   - process-compose.yaml
-  - process-compose.yml`,
+  - process-compose.yml
+
+Per-process env profiles (xplat extension):
+  Variables in .xplat/profiles/<name>.env are injected only into processes
+  that list the profile, e.g. to give the api staging credentials:
+    processes:
+      api:
+        env_profiles: [staging]
+  xplat writes .xplat/profiles/.override.generated.yaml and passes it as an
+  extra -f. Keep .xplat/profiles/ out of git.`,
 	DisableFlagParsing: true, // Pass all args through to process-compose
 	RunE:               runProcess,
 }
@@ -135,6 +146,7 @@ func init() {
 	// Add xplat-specific subcommands
 	ProcessCmd.AddCommand(ProcessDemoCmd)
 	ProcessCmd.AddCommand(ProcessToolsCmd)
+	ProcessCmd.AddCommand(ProcessPostmortemsCmd)
 }
 
 // runProcess is the main entry point for the embedded process-compose.
@@ -150,6 +162,9 @@ func runProcess(cmd *cobra.Command, args []string) error {
 		case "tools":
 			// Handle tools subcommand
 			return ProcessToolsCmd.Execute()
+		case "postmortems":
+			ProcessPostmortemsCmd.SetArgs(args[1:])
+			return ProcessPostmortemsCmd.Execute()
 		}
 	}
 	return runProcessWithArgs(args)
@@ -169,6 +184,15 @@ func runProcessWithArgs(args []string) error {
 	// Auto-detect .env.local for per-machine port overrides
 	args = autoDetectEnvLocal(args)
 
+	// Inject per-process env profiles from .xplat/profiles/
+	args = autoApplyEnvProfiles(args)
+
+	// Decrypt .env.enc into the environment when there is no plaintext .env
+	applyEncryptedEnv("")
+
+	// Capture a post-mortem whenever a process exits non-zero
+	startPostmortemCollector(args)
+
 	// Save original args and restore after
 	origArgs := os.Args
 	defer func() { os.Args = origArgs }()
@@ -255,3 +279,45 @@ func autoDetectEnvLocal(args []string) []string {
 	// Process-compose loads .env by default, .env.local provides overrides
 	return append([]string{"-e", ".env.local"}, args...)
 }
+
+// autoApplyEnvProfiles appends a generated override config (-f) that injects
+// .xplat/profiles/<name>.env into the processes listing them in env_profiles.
+// Only applies for "up" command or no subcommand (default is up with TUI).
+func autoApplyEnvProfiles(args []string) []string {
+	if len(args) > 0 && args[0] != "up" && !strings.HasPrefix(args[0], "-") {
+		return args
+	}
+
+	// Use the last config file given with -f (the one that defines processes)
+	var configPath string
+	for i, arg := range args {
+		switch {
+		case (arg == "-f" || arg == "--config") && i+1 < len(args):
+			configPath = args[i+1]
+		case strings.HasPrefix(arg, "--config="):
+			configPath = strings.TrimPrefix(arg, "--config=")
+		case strings.HasPrefix(arg, "-f") && len(arg) > 2:
+			configPath = strings.TrimPrefix(arg[2:], "=")
+		}
+	}
+	if configPath == "" || strings.HasSuffix(configPath, processcompose.ProfileOverrideFile) {
+		return args
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return args
+	}
+
+	overridePath, err := processcompose.WriteProfileOverride(configPath, workDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: env profiles not applied: %v\n", err)
+		return args
+	}
+	if overridePath == "" {
+		return args
+	}
+
+	// Last -f wins, so the override takes precedence over every other config file
+	return append(args, "-f", overridePath)
+}
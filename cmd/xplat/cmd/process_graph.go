@@ -134,7 +134,7 @@ func buildGraphOutput(pc *processcompose.ProcessCompose, file string) GraphOutpu
 	for name, proc := range pc.Processes {
 		node := GraphNode{
 			Name:      name,
-			Command:   proc.Command,
+			Command:   proc.CommandLine(),
 			Disabled:  proc.Disabled,
 			Namespace: proc.Namespace,
 		}
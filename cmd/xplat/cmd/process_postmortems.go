@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joeblew999/xplat/internal/notify"
+	"github.com/joeblew999/xplat/internal/postmortem"
+)
+
+var (
+	postmortemsJSON     bool
+	postmortemsLimit    int
+	postmortemsPort     int
+	postmortemsInterval time.Duration
+)
+
+// ProcessPostmortemsCmd lists the post-mortems of failed processes.
+var ProcessPostmortemsCmd = &cobra.Command{
+	Use:   "postmortems",
+	Short: "List post-mortems of processes that exited non-zero",
+	Long: `List the post-mortem bundles captured when a process exited non-zero.
+
+While 'xplat process up' runs, each process that exits non-zero gets a
+bundle in ~/.xplat/state/<project>/postmortems/<time>-<process>/ with its
+last log lines, exit code, last resource stats and environment (secrets
+redacted). Bundles are also sent to the notification router
+(~/.xplat/config/notify.yaml or XPLAT_NOTIFY_WEBHOOK) with source
+process/<name>, with secrets redacted from the log lines they include.
+
+Examples:
+  xplat process postmortems                  # Newest first
+  xplat process postmortems show <id>        # Details and logs
+  xplat process postmortems watch -p 8080    # Collect from a running server`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bundles, err := postmortem.List(postmortem.DefaultDir())
+		if err != nil {
+			return err
+		}
+		if postmortemsLimit > 0 && len(bundles) > postmortemsLimit {
+			bundles = bundles[:postmortemsLimit]
+		}
+		if postmortemsJSON {
+			return printJSON(bundles)
+		}
+		if len(bundles) == 0 {
+			fmt.Println("No post-mortems")
+			return nil
+		}
+		fmt.Printf("%-36s %-16s %5s %8s  %s\n", "ID", "PROCESS", "EXIT", "RESTARTS", "LAST LOG LINE")
+		for _, b := range bundles {
+			last := ""
+			if len(b.Logs) > 0 {
+				last = b.Logs[len(b.Logs)-1]
+				if len(last) > 60 {
+					last = last[:57] + "..."
+				}
+			}
+			fmt.Printf("%-36s %-16s %5d %8d  %s\n", b.ID, b.Process, b.ExitCode, b.Restarts, last)
+		}
+		return nil
+	},
+}
+
+var processPostmortemsShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show a post-mortem",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := postmortem.Load(postmortem.DefaultDir(), args[0])
+		if err != nil {
+			return err
+		}
+		if postmortemsJSON {
+			return printJSON(b)
+		}
+		fmt.Printf("Process:  %s\n", b.Process)
+		fmt.Printf("Time:     %s\n", b.Time.Local().Format(time.RFC3339))
+		fmt.Printf("Status:   %s (exit code %d, %d restarts)\n", b.Status, b.ExitCode, b.Restarts)
+		if b.Command != "" {
+			fmt.Printf("Command:  %s\n", b.Command)
+		}
+		fmt.Printf("Stats:    pid %d, %.1f MB, %.1f%% CPU, up %s\n",
+			b.Stats.PID, float64(b.Stats.Mem)/(1<<20), b.Stats.CPU, b.Stats.Uptime.Round(time.Second))
+		fmt.Printf("Bundle:   %s\n", b.Path(postmortem.DefaultDir()))
+		fmt.Printf("\nLast %d log lines:\n", len(b.Logs))
+		fmt.Println(strings.Join(b.Logs, "\n"))
+		return nil
+	},
+}
+
+var processPostmortemsWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Collect post-mortems from a running process-compose server",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		collector, err := newPostmortemCollector(postmortemsPort)
+		if err != nil {
+			return err
+		}
+		collector.Logf = log.Printf
+		fmt.Printf("Watching process-compose on %s for failed processes (Ctrl+C to stop)...\n", collector.BaseURL)
+		collector.Run(ctx, postmortemsInterval)
+		return nil
+	},
+}
+
+func init() {
+	ProcessPostmortemsCmd.PersistentFlags().BoolVar(&postmortemsJSON, "json", false, "Output as JSON")
+	ProcessPostmortemsCmd.Flags().IntVar(&postmortemsLimit, "limit", 20, "Show at most this many (0 = all)")
+	processPostmortemsWatchCmd.Flags().IntVarP(&postmortemsPort, "port", "p", pcDefaultPort, "process-compose API port")
+	processPostmortemsWatchCmd.Flags().DurationVar(&postmortemsInterval, "interval", 2*time.Second, "Poll interval")
+
+	ProcessPostmortemsCmd.AddCommand(processPostmortemsShowCmd)
+	ProcessPostmortemsCmd.AddCommand(processPostmortemsWatchCmd)
+}
+
+// pcDefaultPort is process-compose's own default API port.
+const pcDefaultPort = 8080
+
+// newPostmortemCollector creates a collector that notifies the router.
+func newPostmortemCollector(port int) (*postmortem.Collector, error) {
+	router, err := notify.Default()
+	if err != nil {
+		return nil, err
+	}
+	collector := postmortem.NewCollector(port, postmortem.DefaultDir())
+	collector.Router = router
+	return collector, nil
+}
+
+// processAPIPort returns the API port process-compose will listen on for
+// these args: -p/--port, then PC_PORT_NUM, then the default. It returns 0
+// when there is no server in this process to collect from.
+func processAPIPort(args []string) int {
+	port := pcDefaultPort
+	if v, err := strconv.Atoi(os.Getenv("PC_PORT_NUM")); err == nil {
+		port = v
+	}
+	for i, arg := range args {
+		value := ""
+		switch {
+		case arg == "--no-server", arg == "--no-server=true":
+			return 0
+		case arg == "-D", arg == "--detached":
+			return 0 // The server runs in another process
+		case (arg == "-p" || arg == "--port") && i+1 < len(args):
+			value = args[i+1]
+		case strings.HasPrefix(arg, "--port="):
+			value = strings.TrimPrefix(arg, "--port=")
+		}
+		if v, err := strconv.Atoi(value); err == nil {
+			port = v
+		}
+	}
+	if os.Getenv("PC_NO_SERVER") == "true" || os.Getenv("PC_NO_SERVER") == "1" {
+		return 0
+	}
+	return port
+}
+
+// startPostmortemCollector collects post-mortems alongside an embedded
+// process-compose server, for 'up' and the default command.
+func startPostmortemCollector(args []string) {
+	if len(args) > 0 && args[0] != "up" && !strings.HasPrefix(args[0], "-") {
+		return
+	}
+	port := processAPIPort(args)
+	if port == 0 {
+		return
+	}
+	collector, err := newPostmortemCollector(port)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: post-mortems not collected: %v\n", err)
+		return
+	}
+	go collector.Run(context.Background(), 2*time.Second)
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
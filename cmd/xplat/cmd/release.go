@@ -7,8 +7,14 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 
+	"github.com/google/go-github/v81/github"
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/ghauth"
+	"github.com/joeblew999/xplat/internal/platform"
+	"github.com/joeblew999/xplat/internal/updater"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
@@ -46,14 +52,16 @@ type BuildMatrix struct {
 	Platforms []Platform `json:"platforms"`
 }
 
-// Standard platforms we support
+// Standard platforms we support.
+// The arm64 runners are GitHub's native ARM ones, so CGO=1 tools build there
+// without a cross-compiler; macOS builds both arches on one runner.
 var allPlatforms = []Platform{
 	{OS: "linux", Arch: "amd64", Runner: "ubuntu-latest", CrossCompile: true},
-	{OS: "linux", Arch: "arm64", Runner: "ubuntu-latest", CrossCompile: true},
+	{OS: "linux", Arch: "arm64", Runner: "ubuntu-24.04-arm", CrossCompile: true},
 	{OS: "darwin", Arch: "amd64", Runner: "macos-latest", CrossCompile: true},
 	{OS: "darwin", Arch: "arm64", Runner: "macos-latest", CrossCompile: true},
 	{OS: "windows", Arch: "amd64", Runner: "windows-latest", CrossCompile: true},
-	{OS: "windows", Arch: "arm64", Runner: "windows-latest", CrossCompile: true},
+	{OS: "windows", Arch: "arm64", Runner: "windows-11-arm", CrossCompile: true},
 }
 
 // binaryFilename generates the standard binary filename for a platform.
@@ -75,10 +83,16 @@ var ReleaseMatrixCmd = &cobra.Command{
 a JSON matrix suitable for GitHub Actions or other CI systems.
 
 For CGO=0 tools, all platforms can be cross-compiled from Linux.
-For CGO=1 tools, each platform needs a native runner.
+For CGO=1 tools, each platform needs a native runner: linux/arm64 and
+windows/arm64 build on GitHub's ARM runners (ubuntu-24.04-arm,
+windows-11-arm), both macOS arches on macos-latest.
+
+xplat's own CI builds each platform of 'xplat release matrix xplat' on its
+runner, so the arm64 releases are built on ARM machines.
 
 Examples:
   xplat release matrix tui
+  xplat release matrix xplat --format github
   xplat release matrix tui --format github`,
 	Args: cobra.ExactArgs(1),
 	RunE: runReleaseMatrix,
@@ -93,6 +107,8 @@ Taskfile release:build task with appropriate GOOS/GOARCH.
 
 For CGO=0 tools, builds all platforms from current machine.
 For CGO=1 tools, only builds for current platform (use CI for others).
+The current platform is the machine's native one, so an amd64 xplat under
+Rosetta 2 or Windows x64 emulation builds arm64.
 
 Examples:
   xplat release build tui              # Build all platforms
@@ -126,6 +142,8 @@ var ReleaseBinaryNameCmd = &cobra.Command{
 	Long: `Prints the standard binary filename for the current platform.
 
 Uses the same naming convention as release builds: {name}-{os}-{arch}{ext}
+The arch is the machine's native one, also under Rosetta 2 or Windows x64
+emulation.
 
 Examples:
   xplat release binary-name dummy     # on macOS ARM: dummy-darwin-arm64
@@ -134,11 +152,37 @@ Examples:
 	RunE: runReleaseBinaryName,
 }
 
+// ReleaseManifestsCmd generates Homebrew and Scoop manifests for a release
+var ReleaseManifestsCmd = &cobra.Command{
+	Use:   "manifests [tag]",
+	Short: "Generate Homebrew formula and Scoop manifest for an xplat release",
+	Long: `Generates a Homebrew formula and Scoop manifest for an xplat release,
+with download URLs and sha256 checksums taken from the release's checksums.txt.
+
+Without a tag, uses the latest release. Files are written to --dir.
+With --push, they are committed to the tap and bucket repositories via the
+GitHub API (requires GITHUB_TOKEN with write access to both). Unchanged files
+are not committed. The release job in CI runs it with --push after creating
+the GitHub release, using the RELEASE_MANIFESTS_TOKEN secret.
+
+Examples:
+  xplat release manifests                      # Latest release, write to .releases/
+  xplat release manifests xplat-v0.3.8 --push  # Update tap and bucket
+  xplat release manifests --push --tap me/homebrew-tap --bucket me/scoop-bucket`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReleaseManifests,
+}
+
 var (
 	matrixFormat  string
 	buildCurrent  bool
 	buildPlatform string
 	listBuildDir  string
+
+	manifestsDir    string
+	manifestsPush   bool
+	manifestsTap    string
+	manifestsBucket string
 )
 
 func init() {
@@ -146,11 +190,16 @@ func init() {
 	ReleaseBuildCmd.Flags().BoolVar(&buildCurrent, "current", false, "Only build for current platform")
 	ReleaseBuildCmd.Flags().StringVar(&buildPlatform, "platform", "", "Build for specific platform (e.g., linux/amd64)")
 	ReleaseListCmd.Flags().StringVar(&listBuildDir, "dir", ".build", "Build directory to search for binaries")
+	ReleaseManifestsCmd.Flags().StringVar(&manifestsDir, "dir", releasesDir, "Directory to write the formula and manifest to")
+	ReleaseManifestsCmd.Flags().BoolVar(&manifestsPush, "push", false, "Commit the formula and manifest to the tap and bucket repositories")
+	ReleaseManifestsCmd.Flags().StringVar(&manifestsTap, "tap", config.XplatHomebrewTap, "Homebrew tap repository (owner/name)")
+	ReleaseManifestsCmd.Flags().StringVar(&manifestsBucket, "bucket", config.XplatScoopBucket, "Scoop bucket repository (owner/name)")
 
 	ReleaseCmd.AddCommand(ReleaseMatrixCmd)
 	ReleaseCmd.AddCommand(ReleaseBuildCmd)
 	ReleaseCmd.AddCommand(ReleaseListCmd)
 	ReleaseCmd.AddCommand(ReleaseBinaryNameCmd)
+	ReleaseCmd.AddCommand(ReleaseManifestsCmd)
 }
 
 // TaskfileVars represents the vars section of a Taskfile
@@ -259,6 +308,12 @@ func parseTaskfileVars(path string) (map[string]string, error) {
 
 // getToolConfig extracts build configuration from Taskfile vars
 func getToolConfig(tool string) (*BuildMatrix, error) {
+	// xplat itself has no tool Taskfile; it is CGO=0 and built from the repo root.
+	// Its CI builds each platform on the platform's runner (see ci.yml.tmpl).
+	if tool == "xplat" {
+		return &BuildMatrix{Tool: tool, BinName: tool, Lang: "go", Platforms: slices.Clone(allPlatforms)}, nil
+	}
+
 	taskfile, err := findTaskfile(tool)
 	if err != nil {
 		return nil, err
@@ -374,7 +429,7 @@ func runReleaseBuild(cmd *cobra.Command, args []string) error {
 		// Only current platform
 		targetPlatforms = []Platform{{
 			OS:   runtime.GOOS,
-			Arch: runtime.GOARCH,
+			Arch: platform.Current().Arch,
 		}}
 	} else if buildPlatform != "" {
 		// Specific platform
@@ -394,7 +449,7 @@ func runReleaseBuild(cmd *cobra.Command, args []string) error {
 		// All platforms
 		if matrix.CGO {
 			// CGO=1: Only macOS has a universal toolchain for both arches
-			// Linux/Windows CI runners lack arm64 cross-compilers
+			// Linux/Windows build their native arch, on an ARM runner for arm64
 			if runtime.GOOS == "darwin" {
 				// macOS: Apple toolchain supports both amd64 and arm64
 				fmt.Printf("CGO=1: Building %s platforms for %s (Apple universal toolchain)\n", runtime.GOOS, tool)
@@ -405,10 +460,11 @@ func runReleaseBuild(cmd *cobra.Command, args []string) error {
 				}
 			} else {
 				// Linux/Windows: only native arch (no cross-compiler in CI)
-				fmt.Printf("CGO=1: Building for %s/%s only (no arm64 cross-compiler)\n", runtime.GOOS, runtime.GOARCH)
+				arch := platform.Current().Arch
+				fmt.Printf("CGO=1: Building for %s/%s only (other arches build on their own runner)\n", runtime.GOOS, arch)
 				targetPlatforms = []Platform{{
 					OS:   runtime.GOOS,
-					Arch: runtime.GOARCH,
+					Arch: arch,
 				}}
 			}
 		} else {
@@ -463,7 +519,7 @@ func runReleaseList(cmd *cobra.Command, args []string) error {
 
 func runReleaseBinaryName(cmd *cobra.Command, args []string) error {
 	tool := args[0]
-	fmt.Println(binaryFilename(tool, runtime.GOOS, runtime.GOARCH))
+	fmt.Println(binaryFilename(tool, runtime.GOOS, platform.Current().Arch))
 	return nil
 }
 
@@ -499,7 +555,7 @@ func buildXplatSelf(_ *cobra.Command) error {
 	inCI := os.Getenv("CI") != "" || os.Getenv("GITHUB_ACTIONS") != ""
 
 	if buildCurrent {
-		targetPlatforms = []Platform{{OS: runtime.GOOS, Arch: runtime.GOARCH}}
+		targetPlatforms = []Platform{{OS: runtime.GOOS, Arch: platform.Current().Arch}}
 	} else if buildPlatform != "" {
 		parts := strings.Split(buildPlatform, "/")
 		if len(parts) != 2 {
@@ -545,3 +601,81 @@ func buildXplatSelf(_ *cobra.Command) error {
 	fmt.Printf("\nOK: Built xplat %s for %d platform(s) -> %s/\n", version, len(targetPlatforms), releasesDir)
 	return nil
 }
+
+func runReleaseManifests(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	tag := ""
+	if len(args) > 0 {
+		tag = args[0]
+		if !strings.HasPrefix(tag, config.XplatTagPrefix) {
+			tag = config.XplatTagPrefix + tag
+		}
+	}
+
+	var token string
+	if manifestsPush {
+		var err error
+		if token, err = ghauth.RequireToken(ctx); err != nil {
+			return fmt.Errorf("--push: %w", err)
+		}
+	}
+
+	release, err := updater.GetReleaseByTag(ctx, tag)
+	if err != nil {
+		return fmt.Errorf("failed to fetch release: %w", err)
+	}
+	checksumURL, err := updater.FindChecksumURL(release)
+	if err != nil {
+		return err
+	}
+	checksums, err := updater.FetchChecksums(ctx, checksumURL)
+	if err != nil {
+		return err
+	}
+
+	data, err := updater.NewManifestData(release, checksums)
+	if err != nil {
+		return err
+	}
+	formula, scoop, err := updater.RenderManifests(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(manifestsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", manifestsDir, err)
+	}
+	files := []struct {
+		repo, path string
+		content    []byte
+	}{
+		{manifestsTap, config.XplatHomebrewFormulaPath, formula},
+		{manifestsBucket, config.XplatScoopManifestPath, scoop},
+	}
+	for _, f := range files {
+		out := filepath.Join(manifestsDir, filepath.Base(f.path))
+		if err := os.WriteFile(out, f.content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", out, err)
+		}
+		fmt.Printf("Generated %s (%s)\n", out, release.TagName)
+	}
+
+	if !manifestsPush {
+		return nil
+	}
+
+	client := github.NewClient(nil).WithAuthToken(token)
+	message := fmt.Sprintf("xplat %s", data.Version)
+	for _, f := range files {
+		changed, err := updater.PublishFile(ctx, client, f.repo, f.path, f.content, message)
+		if err != nil {
+			return err
+		}
+		if changed {
+			fmt.Printf("Pushed %s to %s\n", f.path, f.repo)
+		} else {
+			fmt.Printf("%s in %s is up to date\n", f.path, f.repo)
+		}
+	}
+	return nil
+}
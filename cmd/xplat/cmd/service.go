@@ -1,16 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"sort"
+	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/manifest"
 	"github.com/joeblew999/xplat/internal/projects"
+	"github.com/joeblew999/xplat/internal/projectstate"
 	"github.com/joeblew999/xplat/internal/service"
+	"github.com/joeblew999/xplat/internal/sitecheck"
 )
 
 // ServiceCmd is the parent command for service operations.
@@ -31,7 +39,8 @@ Examples:
   cd ~/project1 && xplat service install  # Add project to registry
   xplat service config --ui --sync        # Enable UI and sync (configure once)
   xplat service start                      # Start THE service
-  xplat service status                     # Check service status`,
+  xplat service status                     # Check service status
+  xplat service config --watchdog         # Restart processes that exit`,
 }
 
 var serviceInstallCmd = &cobra.Command{
@@ -100,6 +109,44 @@ Examples:
 	RunE: runServiceList,
 }
 
+var serviceWatchdogCmd = &cobra.Command{
+	Use:   "watchdog",
+	Short: "Run the service's processes in the foreground, restarting them when they exit",
+	Long: `Run the service's processes (process-compose, UI, MCP, the GitHub sync
+poller and the services listed in ~/.xplat/service.yaml) in the foreground,
+restarting each one with backoff when it exits.
+
+A process that exits --flap-threshold times in a row, each within a minute
+of starting, is flapping: the watchdog reports it through the notification
+router (~/.xplat/config/notify.yaml or XPLAT_NOTIFY_WEBHOOK, source
+service/<name>) as a warning. After --give-up-after reports it sends a
+critical notification and stops restarting the process, instead of
+respawning it forever.
+
+To run the installed OS service under the watchdog, set it in the config:
+  xplat service config --watchdog
+
+Extra commands to keep running, such as receivers, go in service.yaml:
+  watchdog: true
+  services:
+    - name: sync-cf-receive
+      args: [sync-cf, receive]
+
+Restarts and flap counts are saved in ~/.xplat/watchdog.json and shown by
+'xplat service status'.
+
+Examples:
+  xplat service watchdog                    # Run in the foreground
+  xplat service watchdog --give-up-after=-1 # Never give up`,
+	RunE: runServiceWatchdog,
+}
+
+var (
+	serviceWatchdogFlapThreshold int
+	serviceWatchdogGiveUpAfter   int
+	serviceWatchdogMaxBackoff    time.Duration
+)
+
 // Config command flags
 var (
 	serviceConfigNoUI         bool
@@ -110,6 +157,7 @@ var (
 	serviceConfigSyncRepos    string
 	serviceConfigSyncInterval string
 	serviceConfigReset        bool
+	serviceConfigWatchdog     bool
 )
 
 var serviceConfigCmd = &cobra.Command{
@@ -138,6 +186,12 @@ func init() {
 	serviceConfigCmd.Flags().StringVar(&serviceConfigSyncRepos, "sync-repos", "", "Repos to poll (comma-separated, empty = auto-discover)")
 	serviceConfigCmd.Flags().StringVar(&serviceConfigSyncInterval, "sync-interval", "", "Poll interval (default: 5m)")
 	serviceConfigCmd.Flags().BoolVar(&serviceConfigReset, "reset", false, "Reset to defaults (all enabled)")
+	serviceConfigCmd.Flags().BoolVar(&serviceConfigWatchdog, "watchdog", false, "Restart exited processes and report flapping (see 'xplat service watchdog')")
+
+	defaults := service.DefaultWatchdogConfig()
+	serviceWatchdogCmd.Flags().IntVar(&serviceWatchdogFlapThreshold, "flap-threshold", defaults.FlapThreshold, "Quick exits in a row reported as flapping")
+	serviceWatchdogCmd.Flags().IntVar(&serviceWatchdogGiveUpAfter, "give-up-after", defaults.GiveUpAfter, "Stop restarting after this many flap reports (-1 never)")
+	serviceWatchdogCmd.Flags().DurationVar(&serviceWatchdogMaxBackoff, "max-backoff", defaults.MaxBackoff, "Longest delay between restarts")
 
 	ServiceCmd.AddCommand(serviceInstallCmd)
 	ServiceCmd.AddCommand(serviceUninstallCmd)
@@ -148,6 +202,7 @@ func init() {
 	ServiceCmd.AddCommand(serviceStatusCmd)
 	ServiceCmd.AddCommand(serviceRunCmd)
 	ServiceCmd.AddCommand(serviceListCmd)
+	ServiceCmd.AddCommand(serviceWatchdogCmd)
 }
 
 // getServiceConfig loads config from file and converts to service.Config.
@@ -167,6 +222,8 @@ func getServiceConfig() (service.Config, error) {
 	cfg.WithSync = svcCfg.Sync
 	cfg.SyncRepos = svcCfg.SyncRepos
 	cfg.SyncInterval = svcCfg.SyncInterval
+	cfg.Watchdog = svcCfg.Watchdog
+	cfg.Services = svcCfg.Services
 
 	return cfg, nil
 }
@@ -192,7 +249,7 @@ func runServiceConfig(cmd *cobra.Command, args []string) error {
 	flagsSet := cmd.Flags().Changed("no-ui") || cmd.Flags().Changed("ui-port") ||
 		cmd.Flags().Changed("no-mcp") || cmd.Flags().Changed("mcp-port") ||
 		cmd.Flags().Changed("no-sync") || cmd.Flags().Changed("sync-repos") ||
-		cmd.Flags().Changed("sync-interval")
+		cmd.Flags().Changed("sync-interval") || cmd.Flags().Changed("watchdog")
 
 	if flagsSet {
 		// Update config with flags (--no-* disables features)
@@ -217,6 +274,9 @@ func runServiceConfig(cmd *cobra.Command, args []string) error {
 		if cmd.Flags().Changed("sync-interval") {
 			cfg.SyncInterval = serviceConfigSyncInterval
 		}
+		if cmd.Flags().Changed("watchdog") {
+			cfg.Watchdog = serviceConfigWatchdog
+		}
 
 		if err := config.SaveServiceConfig(cfg); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
@@ -246,6 +306,10 @@ func runServiceConfig(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Printf("  Sync Interval: %s\n", cfg.SyncInterval)
 	}
+	fmt.Printf("  Watchdog:      %v\n", cfg.Watchdog)
+	for _, s := range cfg.Services {
+		fmt.Printf("  Service:       %s (xplat %s)\n", s.Name, strings.Join(s.Args, " "))
+	}
 
 	return nil
 }
@@ -443,10 +507,54 @@ func runServiceStatus(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 	}
 
+	// Show restarts and flaps of the watchdog's processes
+	if states, err := service.LoadWatchdogState(service.WatchdogStatePath()); err != nil {
+		fmt.Printf("  Watchdog: %v\n", err)
+	} else if len(states) > 0 {
+		fmt.Printf("  Watchdog:\n")
+		for _, s := range states {
+			line := fmt.Sprintf("    %-16s %-8s restarts: %d  flaps: %d", s.Name, s.State, s.Restarts, s.Flaps)
+			if s.LastExit != "" && s.Restarts > 0 {
+				line += "  last exit: " + s.LastExit
+			}
+			fmt.Println(line)
+		}
+	}
+
 	// Show registered projects
 	reg, _ := projects.Load()
 	fmt.Printf("  Projects: %d registered\n", len(reg.Projects))
 
+	// Show SLO compliance for projects that declare SLOs
+	names := make([]string, 0, len(reg.Projects))
+	for name := range reg.Projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	loader := manifest.NewLoader()
+	for _, name := range names {
+		m, err := loader.LoadDir(reg.Projects[name].Path)
+		if err != nil || !m.HasSLOs() {
+			continue
+		}
+		statuses, err := sitecheck.ManifestSLOStatus(projectstate.Open(reg.Projects[name].Path), m, time.Now())
+		if err != nil {
+			fmt.Printf("  SLOs (%s): %v\n", name, err)
+			continue
+		}
+		fmt.Printf("  SLOs (%s):\n", name)
+		for _, s := range statuses {
+			mark := ""
+			if s.Checks > 0 {
+				mark = "ok"
+				if !s.Met {
+					mark = "MISSED"
+				}
+			}
+			fmt.Printf("    %-16s %s  %s\n", s.Name, s.Summary(), mark)
+		}
+	}
+
 	return nil
 }
 
@@ -465,6 +573,30 @@ func runServiceRun(cmd *cobra.Command, args []string) error {
 	return mgr.Run()
 }
 
+func runServiceWatchdog(cmd *cobra.Command, args []string) error {
+	cfg, err := getServiceConfig()
+	if err != nil {
+		return err
+	}
+
+	mgr, err := service.NewManager(cfg)
+	if err != nil {
+		return err
+	}
+
+	wcfg := service.DefaultWatchdogConfig()
+	wcfg.FlapThreshold = serviceWatchdogFlapThreshold
+	wcfg.GiveUpAfter = serviceWatchdogGiveUpAfter
+	wcfg.MaxBackoff = serviceWatchdogMaxBackoff
+	if wcfg.FlapThreshold < 1 {
+		return fmt.Errorf("--flap-threshold must be at least 1")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return mgr.RunWatchdog(ctx, wcfg)
+}
+
 func runServiceList(cmd *cobra.Command, args []string) error {
 	reg, err := projects.Load()
 	if err != nil {
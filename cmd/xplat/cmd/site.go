@@ -0,0 +1,523 @@
+// Package cmd provides CLI commands for xplat.
+//
+// site.go - Site reachability checks (check-host.net, direct, agents)
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/env"
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/projectstate"
+	"github.com/joeblew999/xplat/internal/sitecheck"
+	"github.com/spf13/cobra"
+)
+
+var (
+	siteCheckType      string
+	siteCheckProviders []string
+	siteCheckAgents    []string
+	siteCheckMaxNodes  int
+	siteCheckJSON      bool
+	siteCheckFormat    string
+	siteCheckConfig    string
+	siteCheckSLO       bool
+	siteCheckExpect    int
+	siteCheckMaxLat    time.Duration
+	siteWebhookURL     string
+	siteWebhookDryRun  bool
+	siteWatch          bool
+	siteWatchInterval  time.Duration
+	siteWatchAddr      string
+	siteHistoryFile    string
+	siteHistoryWindow  time.Duration
+	siteAgentPort      string
+	siteAgentLocation  string
+	siteSLOJSON        bool
+)
+
+// SiteCmd is the parent command for site checks.
+var SiteCmd = &cobra.Command{
+	Use:     "site",
+	Aliases: []string{"sitecheck"},
+	Short:   "Site reachability checks",
+	Long: `Check that a site is reachable, from many locations.
+
+Probes run through pluggable providers:
+  check-host  Global nodes via check-host.net (default)
+  direct      This machine
+  agent       Self-hosted agents running 'xplat site agent'
+
+Commands:
+  check    Run HTTP, DNS, TCP or redirect checks (--watch to monitor)
+  history  Show availability and latency from watch history
+  slo      Show compliance with the SLOs declared in xplat.yaml
+  agent    Serve probes for other machines`,
+}
+
+var siteCheckCmd = &cobra.Command{
+	Use:   "check [target]",
+	Short: "Check site reachability",
+	Long: `Check that a site is reachable from every provider location.
+
+The target defaults to CLOUDFLARE_DOMAIN (environment or .env).
+
+Exit status:
+  0  every check passed
+  1  one or more checks failed
+  2  no checks ran (bad flags, config or target)
+
+With --format json, the results are printed as one JSON document for CI
+pipelines and the MCP server: every result with its node IP, target IP,
+latency_ms and error category (dns, connect, tls, http, latency), a
+per-target summary, and the state change since the previous run (new,
+unchanged, failing, recovered). Errors that stop the checks are printed as
+JSON too, with exit_code 2.
+
+With --config (or a sitecheck.yaml in the current directory and no target
+argument), every target in the file is checked concurrently, each with its
+own check types, providers, expected status and latency threshold. Results
+are appended to per-target history and state files under
+~/.xplat/state/<project>/sitecheck/ (see xplat state ls).
+
+sitecheck.yaml:
+  defaults:
+    providers: [direct]
+    max_latency: 2s
+  targets:
+    - url: https://www.example.com
+      types: [http, tcp]
+      expect_status: 200
+    - name: apex
+      url: https://example.com
+      expect_status: 301
+
+With --slo, the targets are the SLOs declared in xplat.yaml. Each SLO's
+latency target becomes the target's max latency, and results are recorded
+under the SLO name for 'xplat site slo'.
+
+With --webhook, failures post an alert with the failed nodes and latency
+deltas against the target's history p50. Slack and Discord webhook URLs get a
+chat message, other URLs the alert as JSON. Posts are retried on network
+errors, 429 and 5xx. In watch mode only the transition to failing alerts.
+Use --webhook-dry-run to print the payload instead.
+
+With --watch, checks run every --interval and results are appended to a
+JSONL history file (~/.xplat/state/<project>/sitecheck/<host>.jsonl). With --addr,
+a status endpoint serves rolling availability and latency percentiles:
+  GET /status   JSON stats over --window
+  GET /health   200 if the last run passed, 503 otherwise
+
+Examples:
+  xplat site check example.com
+  xplat site check --type all
+  xplat site check --type dns,tcp --provider check-host,direct
+  xplat site check --agent https://probe-eu.example.com
+  xplat site check example.com --provider direct --expect-status 301
+  xplat site check example.com --format json
+  xplat site check --config sitecheck.yaml
+  xplat site check --slo
+  xplat site check --webhook https://hooks.slack.com/services/... --webhook-dry-run
+  xplat site check --watch --interval 10m --addr :8771
+
+process-compose:
+  sitecheck:
+    command: xplat site check --watch --interval 10m --addr :8771
+    readiness_probe:
+      http_get: {host: 127.0.0.1, port: 8771, path: /health}`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSiteCheck,
+}
+
+var siteHistoryCmd = &cobra.Command{
+	Use:   "history [target]",
+	Short: "Show availability and latency from watch history",
+	Long: `Summarize the history recorded by 'xplat site check --watch':
+availability and p50/p90/p99 latency per check type and provider.
+
+Examples:
+  xplat site history example.com
+  xplat site history example.com --window 168h`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSiteHistory,
+}
+
+var siteSLOCmd = &cobra.Command{
+	Use:   "slo",
+	Short: "Show SLO compliance",
+	Long: `Show compliance with the SLOs declared in xplat.yaml, from the history
+recorded by 'xplat site check --slo'.
+
+A check counts against an SLO if it failed or was slower than the SLO's
+latency target. Compliance is measured over each SLO's window (default 168h).
+
+xplat.yaml:
+  slos:
+    - name: api
+      process: api           # or endpoint: https://api.example.com/health
+      availability: 99.9
+      latency: 500ms
+
+Exits 1 if any SLO with history is missed.
+
+Examples:
+  xplat site slo
+  xplat site slo --json`,
+	Args: cobra.NoArgs,
+	RunE: runSiteSLO,
+}
+
+var siteAgentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Serve site checks for other machines",
+	Long: `Run a probe agent that answers GET /check?type=http&target=...
+with checks run from this machine.
+
+Point 'xplat site check --agent <url>' at one or more agents to probe
+from locations you control.
+
+Examples:
+  xplat site agent
+  xplat site agent --port 8770 --location eu-west`,
+	RunE: runSiteAgent,
+}
+
+func init() {
+	siteCheckCmd.Flags().StringVarP(&siteCheckType, "type", "t", "http", "Check types: http, dns, tcp, redirect (comma-separated) or all")
+	siteCheckCmd.Flags().StringSliceVarP(&siteCheckProviders, "provider", "p", []string{"check-host"}, "Providers: check-host, direct, agent")
+	siteCheckCmd.Flags().StringSliceVar(&siteCheckAgents, "agent", nil, "Agent URL (repeatable, implies --provider agent)")
+	siteCheckCmd.Flags().IntVar(&siteCheckMaxNodes, "max-nodes", 20, "Maximum check-host.net nodes")
+	siteCheckCmd.Flags().StringVar(&siteCheckFormat, "format", "text", "Output format: text or json")
+	siteCheckCmd.Flags().BoolVar(&siteCheckJSON, "json", false, "Output results as JSON (same as --format json)")
+	siteCheckCmd.Flags().StringVarP(&siteCheckConfig, "config", "c", "", "Multi-target config file (default: ./"+sitecheck.DefaultConfigFile+" when no target is given)")
+	siteCheckCmd.Flags().BoolVar(&siteCheckSLO, "slo", false, "Check the SLOs declared in xplat.yaml")
+	siteCheckCmd.Flags().IntVar(&siteCheckExpect, "expect-status", 0, "HTTP status the http check must return (e.g. 301)")
+	siteCheckCmd.Flags().DurationVar(&siteCheckMaxLat, "max-latency", 0, "Fail checks slower than this (e.g. 2s)")
+	siteCheckCmd.Flags().StringVar(&siteWebhookURL, "webhook", "", "Post an alert to this webhook URL when checks fail (Slack, Discord or JSON)")
+	siteCheckCmd.Flags().BoolVar(&siteWebhookDryRun, "webhook-dry-run", false, "Print the webhook payload instead of posting it")
+	siteCheckCmd.Flags().BoolVar(&siteWatch, "watch", false, "Run checks continuously and record history")
+	siteCheckCmd.Flags().DurationVar(&siteWatchInterval, "interval", 10*time.Minute, "Interval between checks in watch mode")
+	siteCheckCmd.Flags().StringVar(&siteWatchAddr, "addr", "", "Serve status endpoint on this address in watch mode (e.g. :8771)")
+	siteCheckCmd.Flags().StringVar(&siteHistoryFile, "history", "", "History file (default: ~/.xplat/state/<project>/sitecheck/<host>.jsonl)")
+	siteCheckCmd.Flags().DurationVar(&siteHistoryWindow, "window", 24*time.Hour, "Rolling window for availability stats")
+
+	siteHistoryCmd.Flags().StringVar(&siteHistoryFile, "history", "", "History file (default: ~/.xplat/state/<project>/sitecheck/<host>.jsonl)")
+	siteHistoryCmd.Flags().DurationVar(&siteHistoryWindow, "window", 24*time.Hour, "Rolling window for availability stats")
+
+	siteSLOCmd.Flags().BoolVar(&siteSLOJSON, "json", false, "Output as JSON")
+
+	siteAgentCmd.Flags().StringVar(&siteAgentPort, "port", "8770", "Port to listen on")
+	siteAgentCmd.Flags().StringVar(&siteAgentLocation, "location", "", "Location label for results (default: hostname)")
+
+	SiteCmd.AddCommand(siteCheckCmd)
+	SiteCmd.AddCommand(siteHistoryCmd)
+	SiteCmd.AddCommand(siteSLOCmd)
+	SiteCmd.AddCommand(siteAgentCmd)
+}
+
+func runSiteCheck(cmd *cobra.Command, args []string) error {
+	if siteCheckFormat != "text" && siteCheckFormat != "json" {
+		siteCheckExit(nil, fmt.Errorf("unknown format %q (valid: text, json)", siteCheckFormat))
+	}
+
+	multi, err := siteCheck(cmd, args)
+	siteCheckExit(multi, err)
+	return nil
+}
+
+// siteCheckJSONOutput reports whether results are printed as JSON.
+func siteCheckJSONOutput() bool {
+	return siteCheckJSON || siteCheckFormat == "json"
+}
+
+// siteCheckExit prints the JSON output and exits with the site check exit
+// code: ExitError if err is set, otherwise ExitOK or ExitFailed.
+// A nil report (watch mode ended) exits 0.
+func siteCheckExit(multi *sitecheck.MultiReport, err error) {
+	now := time.Now()
+	if err != nil {
+		if siteCheckJSONOutput() {
+			_ = sitecheck.WriteJSON(os.Stdout, sitecheck.ErrorOutput(err, now))
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(sitecheck.ExitError)
+	}
+	if multi == nil {
+		os.Exit(sitecheck.ExitOK)
+	}
+
+	out := sitecheck.NewOutput(multi, now)
+	if siteCheckJSONOutput() {
+		if err := sitecheck.WriteJSON(os.Stdout, out); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(sitecheck.ExitError)
+		}
+	}
+	os.Exit(out.ExitCode)
+}
+
+// siteCheck runs the checks for a target or sitecheck.yaml and prints the
+// text report. It returns nil results in watch mode.
+func siteCheck(cmd *cobra.Command, args []string) (*sitecheck.MultiReport, error) {
+	configFile := siteCheckConfig
+	if configFile == "" && len(args) == 0 {
+		if _, err := os.Stat(sitecheck.DefaultConfigFile); err == nil {
+			configFile = sitecheck.DefaultConfigFile
+		}
+	}
+	if siteCheckSLO {
+		return siteCheckSLOs()
+	}
+	if configFile != "" {
+		return siteCheckFile(configFile)
+	}
+
+	target, err := siteTarget(args)
+	if err != nil {
+		return nil, err
+	}
+
+	types, err := sitecheck.ParseCheckTypes(siteCheckType)
+	if err != nil {
+		return nil, err
+	}
+
+	names := siteCheckProviders
+	if len(siteCheckAgents) > 0 && !cmd.Flags().Changed("provider") {
+		names = []string{"agent"}
+	}
+
+	var providers []sitecheck.Provider
+	for _, name := range names {
+		provider, err := sitecheck.NewProvider(name, siteCheckAgents)
+		if err != nil {
+			return nil, err
+		}
+		if checkHost, ok := provider.(*sitecheck.CheckHostProvider); ok {
+			checkHost.MaxNodes = siteCheckMaxNodes
+		}
+		if direct, ok := provider.(*sitecheck.DirectProvider); ok && siteCheckExpect >= 300 && siteCheckExpect < 400 {
+			direct.NoFollowRedirects = true
+		}
+		providers = append(providers, provider)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	checkCfg := sitecheck.Config{
+		Target:       target,
+		Types:        types,
+		Providers:    providers,
+		ExpectStatus: siteCheckExpect,
+		MaxLatency:   siteCheckMaxLat,
+	}
+
+	if siteWatch {
+		return nil, sitecheck.RunWatch(ctx, sitecheck.WatchConfig{
+			Config:      checkCfg,
+			Interval:    siteWatchInterval,
+			HistoryFile: siteHistoryFile,
+			Window:      siteHistoryWindow,
+			Alerts:      siteAlertSink(),
+		}, siteWatchAddr)
+	}
+
+	report, err := sitecheck.Run(ctx, checkCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	historyFile := siteHistoryFile
+	if historyFile == "" {
+		historyFile = sitecheck.HistoryPath(target)
+	}
+	sendSiteAlert(ctx, report, historyFile)
+
+	multi := sitecheck.SingleReport(report)
+	if err := multi.Targets[0].RecordState(time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if !siteCheckJSONOutput() {
+		sitecheck.PrintReport(report)
+	}
+	return multi, nil
+}
+
+// siteCheckFile checks every target in a sitecheck.yaml.
+func siteCheckFile(path string) (*sitecheck.MultiReport, error) {
+	if siteWatch {
+		return nil, fmt.Errorf("--watch checks a single target; run one watch per target instead of --config")
+	}
+
+	fc, err := sitecheck.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return siteCheckTargets(fc)
+}
+
+// siteCheckSLOs checks the SLOs declared in xplat.yaml.
+func siteCheckSLOs() (*sitecheck.MultiReport, error) {
+	if siteWatch {
+		return nil, fmt.Errorf("--watch checks a single target; schedule 'xplat site check --slo' instead")
+	}
+
+	m, err := siteManifest()
+	if err != nil {
+		return nil, err
+	}
+	return siteCheckTargets(&sitecheck.FileConfig{Targets: sitecheck.SLOTargets(m)})
+}
+
+// siteCheckTargets checks every target in fc, alerts and records results.
+func siteCheckTargets(fc *sitecheck.FileConfig) (*sitecheck.MultiReport, error) {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	multi := sitecheck.RunFile(ctx, fc)
+	// Alert before recording so latency deltas compare against earlier runs
+	for _, tr := range multi.Targets {
+		if tr.Report == nil {
+			continue
+		}
+		sendSiteAlert(ctx, tr.Report, sitecheck.HistoryPath(tr.Name))
+	}
+	if err := multi.Record(time.Now()); err != nil {
+		return nil, err
+	}
+
+	if !siteCheckJSONOutput() {
+		sitecheck.PrintMultiReport(multi)
+	}
+	return multi, nil
+}
+
+// siteAlertSink returns the --webhook sink, or nil if no webhook is set.
+func siteAlertSink() *sitecheck.WebhookSink {
+	if siteWebhookURL == "" {
+		return nil
+	}
+	sink := &sitecheck.WebhookSink{URL: siteWebhookURL, DryRun: siteWebhookDryRun}
+	if siteCheckJSONOutput() {
+		sink.Out = os.Stderr // keep stdout valid JSON
+	}
+	return sink
+}
+
+// sendSiteAlert posts an alert for a failed report, with latency deltas
+// against the history in historyFile. A failed post is only a warning so
+// the check results are still reported.
+func sendSiteAlert(ctx context.Context, report *sitecheck.Report, historyFile string) {
+	sink := siteAlertSink()
+	if sink == nil || report.OK() {
+		return
+	}
+
+	records, err := sitecheck.LoadHistory(historyFile, time.Now().Add(-siteHistoryWindow))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	if err := sink.Send(ctx, sitecheck.NewAlert(report, sitecheck.ComputeStats(records), time.Now())); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+func runSiteHistory(cmd *cobra.Command, args []string) error {
+	target, err := siteTarget(args)
+	if err != nil {
+		return err
+	}
+
+	path := siteHistoryFile
+	if path == "" {
+		path = sitecheck.HistoryPath(target)
+	}
+
+	records, err := sitecheck.LoadHistory(path, time.Now().Add(-siteHistoryWindow))
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Printf("No history for %s in the last %s (%s)\n", target, siteHistoryWindow, path)
+		return nil
+	}
+
+	fmt.Printf("Site history: %s (last %s, %d results)\n\n", target, siteHistoryWindow, len(records))
+	sitecheck.PrintStats(sitecheck.ComputeStats(records))
+	return nil
+}
+
+func runSiteSLO(cmd *cobra.Command, args []string) error {
+	m, err := siteManifest()
+	if err != nil {
+		return err
+	}
+
+	statuses, err := sitecheck.ManifestSLOStatus(projectstate.Current(), m, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if siteSLOJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			return err
+		}
+	} else {
+		sitecheck.PrintSLOStatus(statuses)
+	}
+
+	for _, s := range statuses {
+		if s.Checks > 0 && !s.Met {
+			os.Exit(sitecheck.ExitFailed)
+		}
+	}
+	return nil
+}
+
+// siteManifest loads xplat.yaml from the working directory and requires SLOs.
+func siteManifest() (*manifest.Manifest, error) {
+	m, err := manifest.NewLoader().LoadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	if !m.HasSLOs() {
+		return nil, fmt.Errorf("%s declares no slos", manifest.ManifestFileName)
+	}
+	return m, nil
+}
+
+// siteTarget returns the target argument, defaulting to CLOUDFLARE_DOMAIN.
+func siteTarget(args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+
+	target := os.Getenv(env.KeyCloudflareDomain)
+	if target == "" {
+		if cfg, err := env.LoadEnv(); err == nil && !env.IsPlaceholder(cfg.CloudflareDomain) {
+			target = cfg.CloudflareDomain
+		}
+	}
+	if target == "" {
+		return "", fmt.Errorf("no target given and %s is not set", env.KeyCloudflareDomain)
+	}
+	return target, nil
+}
+
+func runSiteAgent(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	return sitecheck.RunAgent(ctx, ":"+siteAgentPort, siteAgentLocation)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/projectstate"
+)
+
+var (
+	stateCleanAll     bool
+	stateCleanMissing bool
+)
+
+// StateCmd manages per-project tool state under ~/.xplat/state.
+var StateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Manage per-project tool state",
+	Long: `Manage tool state (site check history, analytics history) kept under
+~/.xplat/state/<project>/ instead of the working tree.
+
+A project is identified by its git origin remote, so every clone and
+subdirectory of a repo shares the same state. Checkouts without a remote
+use the repository root, and other directories the directory itself.`,
+}
+
+var stateLsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List projects with tool state",
+	Long: `List project state directories, most recently written first.
+The current project is marked with *, and projects whose last known path
+no longer exists with (missing).
+
+Examples:
+  xplat state ls`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		projects, err := projectstate.List()
+		if err != nil {
+			return err
+		}
+		if len(projects) == 0 {
+			fmt.Printf("No project state in %s\n", config.XplatState())
+			return nil
+		}
+
+		current := projectstate.Current().Hash
+		fmt.Printf("  %-12s %10s  %-16s  %s\n", "HASH", "SIZE", "LAST WRITTEN", "PROJECT")
+		for _, p := range projects {
+			mark := " "
+			if p.Hash == current {
+				mark = "*"
+			}
+			name := p.ID
+			if name == "" {
+				name = "(unknown)"
+			}
+			if p.Path != "" && p.Path != p.ID {
+				name += "  " + p.Path
+			}
+			if p.Missing() {
+				name += "  (missing)"
+			}
+			written := "-"
+			if !p.ModTime.IsZero() {
+				written = p.ModTime.Local().Format("2006-01-02 15:04")
+			}
+			fmt.Printf("%s %-12s %10s  %-16s  %s\n", mark, p.Hash, formatStateSize(p.Size), written, name)
+		}
+		return nil
+	},
+}
+
+var stateCleanCmd = &cobra.Command{
+	Use:   "clean [hash|project]",
+	Short: "Delete project tool state",
+	Long: `Delete the tool state of one project, given by hash or identity as
+shown by 'xplat state ls'. Without an argument the current project is
+cleaned.
+
+Examples:
+  xplat state clean                           # current project
+  xplat state clean 3f2a9c81d0e4
+  xplat state clean github.com/joeblew999/xplat
+  xplat state clean --missing                 # projects whose path is gone
+  xplat state clean --all`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if stateCleanAll && (stateCleanMissing || len(args) > 0) {
+			return fmt.Errorf("--all cannot be combined with --missing or a project")
+		}
+		if stateCleanMissing && len(args) > 0 {
+			return fmt.Errorf("--missing cannot be combined with a project")
+		}
+
+		projects, err := projectstate.List()
+		if err != nil {
+			return err
+		}
+
+		var targets []*projectstate.Project
+		switch {
+		case stateCleanAll:
+			targets = projects
+		case stateCleanMissing:
+			for _, p := range projects {
+				if p.Missing() {
+					targets = append(targets, p)
+				}
+			}
+		case len(args) == 1:
+			p := projectstate.Find(projects, args[0])
+			if p == nil {
+				return fmt.Errorf("no state for project %q (see xplat state ls)", args[0])
+			}
+			targets = append(targets, p)
+		default:
+			p := projectstate.Find(projects, projectstate.Current().Hash)
+			if p == nil {
+				fmt.Println("No state for the current project")
+				return nil
+			}
+			targets = append(targets, p)
+		}
+
+		var freed int64
+		for _, p := range targets {
+			if err := p.Remove(); err != nil {
+				return err
+			}
+			freed += p.Size
+			name := p.ID
+			if name == "" {
+				name = p.Hash
+			}
+			fmt.Printf("Removed %s (%s)\n", name, formatStateSize(p.Size))
+		}
+		if len(targets) > 1 {
+			fmt.Printf("Removed %d projects, freed %s\n", len(targets), formatStateSize(freed))
+		}
+		return nil
+	},
+}
+
+func init() {
+	stateCleanCmd.Flags().BoolVar(&stateCleanAll, "all", false, "Delete the state of every project")
+	stateCleanCmd.Flags().BoolVar(&stateCleanMissing, "missing", false, "Delete the state of projects whose path no longer exists")
+
+	StateCmd.AddCommand(stateLsCmd)
+	StateCmd.AddCommand(stateCleanCmd)
+}
+
+// formatStateSize formats a byte count for state listings.
+func formatStateSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
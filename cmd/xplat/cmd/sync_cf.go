@@ -2,15 +2,22 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/go-task/task/v3/experiments"
+	"github.com/joeblew999/xplat/internal/config"
 	"github.com/joeblew999/xplat/internal/env"
+	"github.com/joeblew999/xplat/internal/ghauth"
+	"github.com/joeblew999/xplat/internal/notify"
 	"github.com/joeblew999/xplat/internal/synccf"
+	"github.com/joeblew999/xplat/internal/syncgh"
 	"github.com/spf13/cobra"
 )
 
@@ -35,6 +42,34 @@ func getReceiverPort(flagValue string) string {
 	return flagValue
 }
 
+// getCFCredentials returns the Cloudflare account ID and API token.
+// Priority: CF_* env vars > CLOUDFLARE_* env vars > .env file
+func getCFCredentials() (accountID, apiToken string) {
+	accountID = os.Getenv("CF_ACCOUNT_ID")
+	apiToken = os.Getenv("CF_API_TOKEN")
+
+	if accountID == "" {
+		accountID = os.Getenv(env.KeyCloudflareAccountID)
+	}
+	if apiToken == "" {
+		apiToken = os.Getenv(env.KeyCloudflareAPIToken)
+	}
+
+	if accountID == "" || apiToken == "" {
+		cfg, err := env.LoadEnv()
+		if err == nil && cfg != nil {
+			if accountID == "" {
+				accountID = cfg.Get(env.KeyCloudflareAccountID)
+			}
+			if apiToken == "" {
+				apiToken = cfg.Get(env.KeyCloudflareAPIToken)
+			}
+		}
+	}
+
+	return accountID, apiToken
+}
+
 // SyncCFCmd is the parent command for Cloudflare sync operations
 var SyncCFCmd = &cobra.Command{
 	Use:   "sync-cf",
@@ -47,14 +82,18 @@ Designed to run as part of xplat service for continuous syncing.
 Commands:
   receive        Receive events from CF Worker (round-trip validation)
   receive-state  Show current receive state
+  mock           Send scripted Worker events to a local receiver
   auth           Set up R2 credentials interactively
-  tunnel         Start cloudflared tunnel (quick or named)
+  tunnel         Start tunnel (cloudflared, tailscale or ngrok)
   tunnel-login   Authenticate cloudflared with Cloudflare
   tunnel-list    List existing named tunnels
   tunnel-create  Create a new named tunnel
   tunnel-delete  Delete a named tunnel
   tunnel-route   Add DNS route for a tunnel
   poll           Poll CF audit logs continuously
+  inventory      Snapshot zones, DNS, Pages, Workers, KV and tokens
+  budget         Alert on usage against monthly budgets (and degrade the Worker)
+  analytics      Web Analytics and zone traffic reports
   webhook        Start CF webhook server
   check          Check if cloudflared is installed
   install        Install cloudflared
@@ -63,15 +102,20 @@ Commands:
 Environment:
   CF_ACCOUNT_ID       Cloudflare account ID
   CF_API_TOKEN        Cloudflare API token
+  CLOUDFLARE_TUNNEL_PROVIDER  Tunnel provider (default: cloudflared)
   R2_ACCESS_KEY       R2 API access key
   R2_SECRET_KEY       R2 API secret key
 
 Round-Trip Validation (recommended):
   1. Deploy CF Worker:  xplat sync-cf worker deploy
   2. Start receiver:    xplat sync-cf receive --port=9091
-  3. Start tunnel:      xplat sync-cf tunnel 9091
+  3. Start tunnel:      xplat sync-cf tunnel 9091 --save-endpoint
   4. Configure SYNC_ENDPOINT on Worker to tunnel URL
 
+Offline Round-Trip (no Cloudflare account):
+  1. Start receiver:    xplat sync-cf receive --port=9091
+  2. Send events:       xplat sync-cf mock --scenario pages-deploy
+
 Quick Tunnel (random URL, no account needed):
   xplat sync-cf tunnel 8080
 
@@ -88,14 +132,21 @@ Examples:
   xplat sync-cf tunnel 8080
   xplat sync-cf tunnel --name=webhook --port=8080
   xplat sync-cf poll --interval=1m
+  xplat sync-cf inventory
+  xplat sync-cf inventory diff
+  xplat sync-cf budget
   xplat sync-cf webhook --port=9090
   xplat sync-cf worker deploy`,
 }
 
 var syncCFTunnelName string
 var syncCFTunnelPort string
+var syncCFTunnelProvider string
+var syncCFTunnelSaveEndpoint bool
 var syncCFReceivePort string
 var syncCFReceiveInvalidate bool
+var syncCFReceiveIssues string
+var syncCFReceiveTasks []string
 
 var syncCFReceiveCmd = &cobra.Command{
 	Use:   "receive",
@@ -112,8 +163,20 @@ Architecture:
 
 The receiver supports these event types:
   - pages_deploy: Pages deploy hooks (triggers cache invalidation with --invalidate)
+    and deploy notifications. Failed deploys fetch the build log (with
+    CLOUDFLARE_ACCOUNT_ID and CLOUDFLARE_API_TOKEN), classify the cause
+    (missing env var, Hugo error, npm failure), notify the configured routes
+    and, with --issues, file a GitHub issue with the first error lines
   - alert: Notification webhooks
   - logpush: Logpush HTTP destination batches
+  - workers_error: Worker error rate / CPU exceeded alerts (files GitHub issues with --issues)
+  - r2_object_created, r2_object_deleted: R2 event notifications from the Worker's queue consumer
+
+--task <event>=<task> runs a task from the Taskfile in the working directory
+when an event of that type arrives ("*" for every event). The event is
+passed as task vars: EVENT_TYPE, EVENT_ACTION, EVENT_RESOURCE, EVENT_SOURCE,
+EVENT_TIME, and when known DEPLOY_URL, COMMIT, BRANCH, PROJECT, BUCKET and
+OBJECT_KEY. Tasks run one at a time in the background.
 
 Examples:
   # Start receiver on default port
@@ -125,6 +188,13 @@ Examples:
   # Start receiver with custom port
   xplat sync-cf receive --port=9091
 
+  # File or update a GitHub issue for each Worker error alert and failed
+  # Pages build (needs GITHUB_TOKEN)
+  xplat sync-cf receive --issues joeblew999/xplat
+
+  # Run the smoke-test task (with {{.DEPLOY_URL}}) after each Pages deploy
+  xplat sync-cf receive --task pages_deploy=smoke-test
+
   # Start receiver + tunnel together
   xplat sync-cf receive --port=9091 --invalidate &
   xplat sync-cf tunnel 9091`,
@@ -142,6 +212,65 @@ Examples:
 			callbacks.OnPagesDeploy = synccf.TaskCacheInvalidator(workDir)
 		}
 
+		var filer synccf.IssueFiler
+		if syncCFReceiveIssues != "" {
+			// The receiver is long-running; the cached source re-mints App tokens
+			issues, err := syncgh.NewIssueClient(syncCFReceiveIssues, ghauth.DefaultSource())
+			if err != nil {
+				return err
+			}
+			log.Printf("Worker error alerts and failed Pages builds will file issues in: %s", syncCFReceiveIssues)
+			callbacks.OnWorkerError = synccf.WorkerErrorIssueCallback(issues)
+			filer = issues
+		}
+
+		// Failed Pages deploys are reported with an analysis of their build log
+		router, err := notify.Default()
+		if err != nil {
+			log.Printf("Warning: notifications disabled: %v", err)
+		}
+		var buildLogs *synccf.PagesLogClient
+		if accountID, apiToken := getCFCredentials(); accountID != "" && apiToken != "" {
+			buildLogs, _ = synccf.NewPagesLogClient(accountID, apiToken)
+		} else {
+			log.Printf("Warning: no Cloudflare credentials; failed Pages builds are reported without their build log")
+		}
+		onPagesFailure := synccf.PagesFailureCallback(buildLogs, filer, router)
+		if invalidate := callbacks.OnPagesDeploy; invalidate != nil {
+			callbacks.OnPagesDeploy = func(ctx context.Context, event synccf.WorkerEvent) error {
+				if err := invalidate(ctx, event); err != nil {
+					log.Printf("sync-cf receive: %v", err)
+				}
+				return onPagesFailure(ctx, event)
+			}
+		} else {
+			callbacks.OnPagesDeploy = onPagesFailure
+		}
+
+		if len(syncCFReceiveTasks) > 0 {
+			var hooks []synccf.TaskHook
+			for _, s := range syncCFReceiveTasks {
+				hook, err := synccf.ParseTaskHook(s)
+				if err != nil {
+					return err
+				}
+				log.Printf("Task %s will run on %s events", hook.Task, hook.Event)
+				hooks = append(hooks, hook)
+			}
+			// Same environment as 'xplat task': PLAT_* vars, PLAT_BIN on PATH, remote taskfiles
+			workDir, _ := os.Getwd()
+			config.SetPlatEnv(workDir)
+			_ = os.Setenv("PATH", config.PathWithPlatBin(workDir))
+			_ = os.Setenv("TASK_X_REMOTE_TASKFILES", "1")
+			experiments.Parse(workDir)
+			runTasks := synccf.TaskRunnerCallback(&synccf.TaskfileRunner{Dir: workDir}, hooks)
+			logEvent := callbacks.OnAny
+			callbacks.OnAny = func(ctx context.Context, event synccf.WorkerEvent) error {
+				_ = logEvent(ctx, event)
+				return runTasks(ctx, event)
+			}
+		}
+
 		return synccf.RunReceiveServer(port, callbacks)
 	},
 }
@@ -187,23 +316,100 @@ var syncCFReceiveStateCmd = &cobra.Command{
 	},
 }
 
+var syncCFMockScenario string
+var syncCFMockTarget string
+var syncCFMockSpeed float64
+var syncCFMockList bool
+
+var syncCFMockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "Send scripted Worker events to a local receiver",
+	Long: `Simulate the CF Worker: send a scenario of realistic events to the local
+receiver, so callbacks and dedupe can be tested without a Cloudflare account
+(e.g. in CI).
+
+A scenario is a YAML file with a list of events (type, action, resource,
+metadata, raw). Per event, "after" delays it and advances its timestamp,
+and "repeat" resends it unchanged like a retried forward, which the receiver
+must report as a duplicate. "batch" and "gzip" at the top level send all
+events in one gzipped request, like BATCH_MAX_EVENTS and FORWARD_GZIP.
+
+Built-in scenarios (by name): pages-deploy, r2-objects, worker-error.
+
+Examples:
+  xplat sync-cf mock --list
+  xplat sync-cf mock --scenario pages-deploy
+  xplat sync-cf mock --scenario ./scenarios/deploy.yaml --speed 0.1
+  xplat sync-cf mock --scenario r2-objects --target http://127.0.0.1:9091/`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if syncCFMockList {
+			for _, name := range synccf.MockScenarioNames() {
+				fmt.Println(name)
+			}
+			return nil
+		}
+		if syncCFMockScenario == "" {
+			return fmt.Errorf("--scenario is required (built-in: %s)", strings.Join(synccf.MockScenarioNames(), ", "))
+		}
+
+		scenario, err := synccf.LoadMockScenario(syncCFMockScenario)
+		if err != nil {
+			return err
+		}
+
+		target := syncCFMockTarget
+		if target == "" {
+			target = "http://127.0.0.1:" + getReceiverPort("9091") + "/"
+		}
+
+		log.Printf("sync-cf mock: %s (%d events) -> %s", scenario.Name, len(scenario.Events), target)
+		results, err := synccf.RunMockScenario(cmd.Context(), scenario, synccf.MockConfig{
+			Target: target,
+			Token:  os.Getenv("SYNC_TOKEN"),
+			Speed:  syncCFMockSpeed,
+		})
+		for _, r := range results {
+			status := "OK"
+			if r.Duplicate {
+				status = "duplicate"
+			}
+			log.Printf("  %s: %d %s", strings.Join(r.Events, ","), r.Status, status)
+		}
+		return err
+	},
+}
+
 var syncCFTunnelCmd = &cobra.Command{
 	Use:   "tunnel [port]",
-	Short: "Start cloudflared tunnel (quick or named)",
-	Long: `Start a cloudflared tunnel to expose a local port to the internet.
+	Short: "Start tunnel (cloudflared, tailscale or ngrok)",
+	Long: `Start a tunnel to expose a local port to the internet.
+
+Providers (--provider flag, or CLOUDFLARE_TUNNEL_PROVIDER in .env):
+  cloudflared  Cloudflare Tunnel (default, installed if missing)
+  tailscale    Tailscale Funnel on https://<host>.<tailnet>.ts.net
+               Requires Funnel enabled for the tailnet
+  ngrok        ngrok HTTP tunnel
+               Requires: ngrok config add-authtoken <token>
 
-Quick Tunnel (default):
+Use tailscale or ngrok on networks that block cloudflared.
+
+Quick Tunnel (cloudflared default):
   Random URL like https://xxx.trycloudflare.com
   No account needed, URL changes on each restart
 
-Named Tunnel (--name flag):
+Named Tunnel (--name flag, cloudflared only):
   Stable URL tied to your Cloudflare domain
   Requires prior setup: tunnel-login, tunnel-create, tunnel-route
 
+--save-endpoint records the tunnel URL as CLOUDFLARE_SYNC_ENDPOINT in .env,
+the Worker's SYNC_ENDPOINT, whichever provider is used.
+
 Examples:
   xplat sync-cf tunnel 8080                      # Quick tunnel
   xplat sync-cf tunnel --port=8080               # Quick tunnel with flag
-  xplat sync-cf tunnel --name=webhook --port=8080  # Named tunnel`,
+  xplat sync-cf tunnel --name=webhook --port=8080  # Named tunnel
+  xplat sync-cf tunnel 9091 --provider=tailscale   # Tailscale Funnel
+  xplat sync-cf tunnel 9091 --provider=ngrok --save-endpoint`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get port from flag > args > .env > default
@@ -224,16 +430,46 @@ Examples:
 			}
 		}
 
+		provider, err := synccf.NewProvider(getTunnelProvider(syncCFTunnelProvider), synccf.ProviderOptions{
+			TunnelName: syncCFTunnelName,
+		})
+		if err != nil {
+			return err
+		}
+
+		var onURL func(string)
+		if syncCFTunnelSaveEndpoint {
+			onURL = func(url string) {
+				if err := env.NewService(false).UpdateFields(map[string]string{env.KeyCloudflareSyncEndpoint: url}); err != nil {
+					log.Printf("Warning: failed to save %s: %v", env.KeyCloudflareSyncEndpoint, err)
+					return
+				}
+				log.Printf("Saved %s=%s to .env", env.KeyCloudflareSyncEndpoint, url)
+			}
+		}
+
 		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 		defer cancel()
 
-		if syncCFTunnelName != "" {
-			return synccf.RunNamedTunnel(ctx, syncCFTunnelName, port)
-		}
-		return synccf.RunTunnel(ctx, port)
+		return synccf.RunTunnel(ctx, provider, port, onURL)
 	},
 }
 
+// getTunnelProvider returns the tunnel provider name.
+// Priority: CLI flag > .env file > default (cloudflared)
+func getTunnelProvider(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	cfg, err := env.LoadEnv()
+	if err == nil && cfg != nil {
+		if provider := cfg.Get(env.KeyCloudflareTunnelProvider); provider != "" {
+			return provider
+		}
+	}
+	return synccf.ProviderCloudflared
+}
+
 var syncCFTunnelLoginCmd = &cobra.Command{
 	Use:   "tunnel-login",
 	Short: "Authenticate cloudflared with Cloudflare",
@@ -321,6 +557,112 @@ var syncCFPollCmd = &cobra.Command{
 	},
 }
 
+var syncCFInventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Snapshot account resources for drift auditing",
+	Long: `Capture a snapshot of the Cloudflare account into a timestamped JSON file.
+
+Captured resources:
+  - Zones and their DNS records
+  - Pages projects
+  - Workers scripts
+  - KV namespaces
+  - API tokens (names only)
+
+Snapshots are stored in ~/.xplat/cache/synccf-inventory/. Use
+'inventory diff' to see what changed between snapshots - a lightweight
+audit trail without adopting Terraform.
+
+Examples:
+  xplat sync-cf inventory
+  xplat sync-cf inventory list
+  xplat sync-cf inventory diff
+  xplat sync-cf inventory diff old.json new.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accountID, apiToken := getCFCredentials()
+		_, err := synccf.RunInventory(cmd.Context(), accountID, apiToken)
+		return err
+	},
+}
+
+var syncCFInventoryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved inventory snapshots",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		snapshots, err := synccf.ListInventorySnapshots(synccf.InventoryDir())
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			log.Printf("No snapshots yet. Run 'xplat sync-cf inventory' first.")
+			return nil
+		}
+		for _, path := range snapshots {
+			fmt.Println(path)
+		}
+		return nil
+	},
+}
+
+var syncCFInventoryDiffCmd = &cobra.Command{
+	Use:   "diff [old.json new.json]",
+	Short: "Show changes between two inventory snapshots",
+	Long: `Show resources added, removed, or changed between two snapshots.
+
+With no arguments, compares the two most recent snapshots.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 2 {
+			return fmt.Errorf("expected 0 or 2 snapshot paths, got %d", len(args))
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldPath, newPath := "", ""
+		if len(args) == 2 {
+			oldPath, newPath = args[0], args[1]
+		} else {
+			snapshots, err := synccf.ListInventorySnapshots(synccf.InventoryDir())
+			if err != nil {
+				return err
+			}
+			if len(snapshots) < 2 {
+				return fmt.Errorf("need at least 2 snapshots to diff, found %d (run 'xplat sync-cf inventory')", len(snapshots))
+			}
+			oldPath, newPath = snapshots[len(snapshots)-2], snapshots[len(snapshots)-1]
+		}
+
+		before, err := synccf.LoadInventory(oldPath)
+		if err != nil {
+			return err
+		}
+		after, err := synccf.LoadInventory(newPath)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Comparing %s -> %s\n", before.CapturedAt.Format(time.RFC3339), after.CapturedAt.Format(time.RFC3339))
+
+		changes := synccf.DiffInventory(before, after)
+		if len(changes) == 0 {
+			fmt.Println("No changes.")
+			return nil
+		}
+
+		for _, c := range changes {
+			switch c.Kind {
+			case synccf.ChangeAdded:
+				fmt.Printf("  + %s\n", c.Resource)
+			case synccf.ChangeRemoved:
+				fmt.Printf("  - %s\n", c.Resource)
+			case synccf.ChangeChanged:
+				fmt.Printf("  ~ %s (%s -> %s)\n", c.Resource, c.Old, c.New)
+			}
+		}
+		fmt.Printf("\n%d change(s)\n", len(changes))
+		return nil
+	},
+}
+
 var syncCFWebhookPort string
 
 var syncCFWebhookCmd = &cobra.Command{
@@ -352,12 +694,98 @@ Saved credentials:
   CF_ACCOUNT_ID    - Your Cloudflare account ID
   CF_API_TOKEN     - Cloudflare API token (optional)
   R2_ACCESS_KEY    - R2 API access key
-  R2_SECRET_KEY    - R2 API secret key`,
+  R2_SECRET_KEY    - R2 API secret key
+
+To mint scoped tokens via the API instead, see 'xplat sync-cf auth create-token'.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return synccf.RunAuth(os.Stdout)
 	},
 }
 
+var (
+	syncCFTokenPreset    string
+	syncCFTokenBootstrap string
+	syncCFTokenName      string
+	syncCFTokenKeep      bool
+)
+
+var syncCFAuthCreateTokenCmd = &cobra.Command{
+	Use:   "create-token",
+	Short: "Create a least-privilege API token from a preset",
+	Long: `Create a narrowly-scoped Cloudflare API token for one use case.
+
+Uses a bootstrap token with the "API Tokens Write" permission to mint a
+token with only the preset's permissions, scoped to your account, and
+saves it to your .env file under the variables that use case reads.
+
+Presets:
+  pages-events     Pages Read, Workers Scripts Read       -> CF_API_TOKEN
+  r2-rw            R2 bucket item read and write          -> R2_ACCESS_KEY, R2_SECRET_KEY
+  analytics-read   Account Analytics Read                 -> CF_ANALYTICS_API_TOKEN
+
+Created token IDs are recorded in ~/.xplat/config/synccf-tokens.json.
+Running create-token again for a preset rotates it: the new token is
+saved and the previous one is revoked (unless --keep-old).
+
+Examples:
+  CF_BOOTSTRAP_TOKEN=... xplat sync-cf auth create-token --preset r2-rw
+  xplat sync-cf auth create-token --preset analytics-read --bootstrap-token ...`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		preset, err := synccf.FindTokenPreset(syncCFTokenPreset)
+		if err != nil {
+			return err
+		}
+		bootstrap := syncCFTokenBootstrap
+		if bootstrap == "" {
+			bootstrap = os.Getenv("CF_BOOTSTRAP_TOKEN")
+		}
+		if bootstrap == "" {
+			return fmt.Errorf("bootstrap token required: set --bootstrap-token or CF_BOOTSTRAP_TOKEN")
+		}
+		accountID, _ := getCFCredentials()
+		client, err := synccf.NewTokenClient(accountID, bootstrap)
+		if err != nil {
+			return err
+		}
+
+		name := syncCFTokenName
+		if name == "" {
+			name = fmt.Sprintf("xplat-%s-%s", preset.Name, time.Now().UTC().Format("20060102"))
+		}
+		ctx := cmd.Context()
+		token, err := client.Create(ctx, preset, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created token %s (%s)\n", token.Name, token.ID)
+
+		vars := synccf.TokenEnv(preset, token)
+		if err := synccf.SaveTokenEnv(vars); err != nil {
+			return fmt.Errorf("failed to save token to .env: %w", err)
+		}
+		for _, key := range preset.EnvVars {
+			fmt.Printf("  Saved %s to .env\n", key)
+		}
+
+		previous, err := synccf.SaveTokenRecord(synccf.TokenRecordsPath(), token)
+		if err != nil {
+			return err
+		}
+		if previous == nil || previous.ID == token.ID {
+			return nil
+		}
+		if syncCFTokenKeep {
+			fmt.Printf("Previous token %s (%s) kept; revoke it when no longer used\n", previous.Name, previous.ID)
+			return nil
+		}
+		if err := client.Delete(ctx, previous.ID); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked previous token %s (%s)\n", previous.Name, previous.ID)
+		return nil
+	},
+}
+
 var syncCFCheckCmd = &cobra.Command{
 	Use:   "check",
 	Short: "Check if cloudflared is installed",
@@ -440,17 +868,35 @@ func init() {
 	// Receive flags
 	syncCFReceiveCmd.Flags().StringVar(&syncCFReceivePort, "port", "9091", "Receive server port")
 	syncCFReceiveCmd.Flags().BoolVar(&syncCFReceiveInvalidate, "invalidate", false, "Invalidate Task cache on Pages deploy events")
+	syncCFReceiveCmd.Flags().StringVar(&syncCFReceiveIssues, "issues", "", "File GitHub issues for Worker error alerts and failed Pages builds in this repo (owner/repo)")
+	syncCFReceiveCmd.Flags().StringArrayVar(&syncCFReceiveTasks, "task", nil, "Run a Taskfile task on an event type, as <event>=<task> (repeatable, event * for all)")
 
+	syncCFMockCmd.Flags().StringVar(&syncCFMockScenario, "scenario", "", "Scenario file or built-in scenario name")
+	syncCFMockCmd.Flags().StringVar(&syncCFMockTarget, "target", "", "Receiver URL (default: local receiver port)")
+	syncCFMockCmd.Flags().Float64Var(&syncCFMockSpeed, "speed", 0, "Delay multiplier for 'after' (0 = send immediately, 1 = real time)")
+	syncCFMockCmd.Flags().BoolVar(&syncCFMockList, "list", false, "List built-in scenarios")
 	syncCFPollCmd.Flags().StringVar(&syncCFPollInterval, "interval", "1m", "Poll interval")
 	syncCFWebhookCmd.Flags().StringVar(&syncCFWebhookPort, "port", "9090", "Webhook server port")
 
 	// Tunnel flags
 	syncCFTunnelCmd.Flags().StringVar(&syncCFTunnelName, "name", "", "Named tunnel name (for stable URL)")
 	syncCFTunnelCmd.Flags().StringVar(&syncCFTunnelPort, "port", "", "Local port to expose")
-
+	syncCFTunnelCmd.Flags().StringVar(&syncCFTunnelProvider, "provider", "", "Tunnel provider: "+strings.Join(synccf.Providers, ", ")+" (default: .env or cloudflared)")
+	syncCFTunnelCmd.Flags().BoolVar(&syncCFTunnelSaveEndpoint, "save-endpoint", false, "Save the tunnel URL as CLOUDFLARE_SYNC_ENDPOINT in .env")
+
+	syncCFAuthCreateTokenCmd.Flags().StringVar(&syncCFTokenPreset, "preset", "", "Token preset: pages-events, r2-rw, analytics-read")
+	syncCFAuthCreateTokenCmd.Flags().StringVar(&syncCFTokenBootstrap, "bootstrap-token", "", "Token with API Tokens Write permission (default: $CF_BOOTSTRAP_TOKEN)")
+	syncCFAuthCreateTokenCmd.Flags().StringVar(&syncCFTokenName, "name", "", "Token name (default: xplat-<preset>-<date>)")
+	syncCFAuthCreateTokenCmd.Flags().BoolVar(&syncCFTokenKeep, "keep-old", false, "Do not revoke the token this one replaces")
+	_ = syncCFAuthCreateTokenCmd.MarkFlagRequired("preset")
+	syncCFAuthCmd.AddCommand(syncCFAuthCreateTokenCmd)
 	SyncCFCmd.AddCommand(syncCFAuthCmd)
 	SyncCFCmd.AddCommand(syncCFCheckCmd)
 	SyncCFCmd.AddCommand(syncCFInstallCmd)
+	syncCFInventoryCmd.AddCommand(syncCFInventoryListCmd)
+	syncCFInventoryCmd.AddCommand(syncCFInventoryDiffCmd)
+	SyncCFCmd.AddCommand(syncCFInventoryCmd)
+	SyncCFCmd.AddCommand(syncCFMockCmd)
 	SyncCFCmd.AddCommand(syncCFPollCmd)
 	SyncCFCmd.AddCommand(syncCFReceiveCmd)
 	SyncCFCmd.AddCommand(syncCFReceiveStateCmd)
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joeblew999/xplat/internal/cfanalytics"
+)
+
+var (
+	syncCFAnalyticsDays    int
+	syncCFAnalyticsTop     int
+	syncCFAnalyticsSiteTag string
+	syncCFAnalyticsZone    string
+	syncCFAnalyticsFormat  string
+	syncCFAnalyticsHistory string
+	syncCFAnalyticsNoSave  bool
+	syncCFAnalyticsDataset string
+	syncCFAnalyticsConfig  string
+)
+
+var syncCFAnalyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Query the Cloudflare GraphQL Analytics API",
+}
+
+var syncCFAnalyticsReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report Web Analytics, zone traffic and Worker invocations",
+	Long: `Report on the last N days from these datasets (--dataset):
+  rum       Web Analytics page views per day and top pages
+  http      Zone HTTP requests, cache ratio, bandwidth and threats per day
+  workers   Worker invocations, errors and subrequests per script
+
+By default every dataset is reported whose ID is configured: rum with a
+site tag, http with a zone, and workers always.
+
+Configuration comes from the environment or .env:
+  CLOUDFLARE_ACCOUNT_ID       Account ID (or CF_ACCOUNT_ID)
+  CF_ANALYTICS_API_TOKEN      Token with Account Analytics read
+                              (falls back to CF_API_TOKEN, CLOUDFLARE_API_TOKEN)
+  CF_WEB_ANALYTICS_SITE_TAG   Web Analytics site tag
+  CLOUDFLARE_ZONE_ID          Zone for HTTP traffic
+
+Mint a read-only token with:
+  xplat sync-cf auth create-token --preset analytics-read
+Zone traffic also needs Zone Analytics read on the zone.
+
+Every run is kept in ~/.xplat/state/<project>/cfanalytics/history.jsonl
+(skip with --no-save). --history adds weekly trends over that window: the
+last week, week-over-week change, 4-week moving average and a sparkline per
+metric, so Worker errors and cache misses show next to traffic. Days that
+several runs cover use the latest run.
+
+With --config (or an analytics.yaml in the current directory and no
+--site-tag or --zone), every site in the file is reported in one run, each
+with its own history file, as one combined report with a section per site:
+
+  defaults:
+    datasets: [rum, http]
+  sites:
+    - name: docs
+      site_tag: aaaa
+      zone_id: bbbb
+    - name: blog
+      account_id: fedcba9876543210       # default: CLOUDFLARE_ACCOUNT_ID
+      token_env: CF_BLOG_ANALYTICS_TOKEN # default: the token above
+      site_tag: cccc
+
+Examples:
+  xplat sync-cf analytics report
+  xplat sync-cf analytics report --days 30 --top 50
+  xplat sync-cf analytics report --dataset http,workers
+  xplat sync-cf analytics report --format markdown > traffic.md
+  xplat sync-cf analytics report --history 12w
+  xplat sync-cf analytics report --config analytics.yaml --format markdown
+  xplat sync-cf analytics report --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := cfanalytics.LoadConfig()
+		if err != nil {
+			return err
+		}
+		weeks := 0
+		if syncCFAnalyticsHistory != "" {
+			if weeks, err = cfanalytics.ParseHistoryWindow(syncCFAnalyticsHistory); err != nil {
+				return err
+			}
+		}
+
+		configFile := syncCFAnalyticsConfig
+		if configFile == "" && syncCFAnalyticsSiteTag == "" && syncCFAnalyticsZone == "" {
+			if _, err := os.Stat(cfanalytics.DefaultConfigFile); err == nil {
+				configFile = cfanalytics.DefaultConfigFile
+			}
+		}
+		if configFile != "" {
+			return runAnalyticsFileReport(cmd, cfg, configFile, weeks)
+		}
+
+		if syncCFAnalyticsSiteTag != "" {
+			cfg.SiteTag = syncCFAnalyticsSiteTag
+		}
+		if syncCFAnalyticsZone != "" {
+			cfg.ZoneID = syncCFAnalyticsZone
+		}
+		client, err := cfg.NewClient()
+		if err != nil {
+			return err
+		}
+		datasets, err := cfanalytics.ParseDatasets(syncCFAnalyticsDataset)
+		if err != nil {
+			return err
+		}
+		report, err := client.BuildReport(cmd.Context(), cfg, cfanalytics.LastDays(syncCFAnalyticsDays), syncCFAnalyticsTop, datasets...)
+		if err != nil {
+			return err
+		}
+
+		if err := recordAnalyticsHistory(cfanalytics.HistoryPath(), report, weeks); err != nil {
+			return err
+		}
+
+		switch syncCFAnalyticsFormat {
+		case "json":
+			return printJSON(report)
+		case "markdown":
+			return report.WriteMarkdown(os.Stdout)
+		case "text":
+			printAnalyticsReport(report)
+			return nil
+		default:
+			return fmt.Errorf("unknown format %q (want text, markdown or json)", syncCFAnalyticsFormat)
+		}
+	},
+}
+
+func init() {
+	syncCFAnalyticsReportCmd.Flags().IntVar(&syncCFAnalyticsDays, "days", 7, "Number of days to report on")
+	syncCFAnalyticsReportCmd.Flags().IntVar(&syncCFAnalyticsTop, "top", cfanalytics.DefaultReportTop, "Number of top pages to list")
+	syncCFAnalyticsReportCmd.Flags().StringVar(&syncCFAnalyticsSiteTag, "site-tag", "", "Web Analytics site tag (default: CF_WEB_ANALYTICS_SITE_TAG)")
+	syncCFAnalyticsReportCmd.Flags().StringVar(&syncCFAnalyticsZone, "zone", "", "Zone ID (default: CLOUDFLARE_ZONE_ID)")
+	syncCFAnalyticsReportCmd.Flags().StringVar(&syncCFAnalyticsConfig, "config", "", "Multi-site config file (default: analytics.yaml if present)")
+	syncCFAnalyticsReportCmd.Flags().StringVar(&syncCFAnalyticsDataset, "dataset", "", "Datasets to report: rum, http, workers (comma-separated, default: all configured)")
+	syncCFAnalyticsReportCmd.Flags().StringVar(&syncCFAnalyticsFormat, "format", "text", "Output format: text, markdown or json")
+	syncCFAnalyticsReportCmd.Flags().StringVar(&syncCFAnalyticsHistory, "history", "", "Add weekly trends over this window from saved runs (e.g. 12w, 90d)")
+	syncCFAnalyticsReportCmd.Flags().BoolVar(&syncCFAnalyticsNoSave, "no-save", false, "Don't add this run to the history file")
+
+	syncCFAnalyticsCmd.AddCommand(syncCFAnalyticsReportCmd)
+	SyncCFCmd.AddCommand(syncCFAnalyticsCmd)
+}
+
+// recordAnalyticsHistory adds trends over weeks (if any) from the history
+// file to report, then appends report to it unless --no-save.
+func recordAnalyticsHistory(path string, report *cfanalytics.Report, weeks int) error {
+	if weeks > 0 {
+		history, err := cfanalytics.LoadHistory(path, report.Until.AddDate(0, 0, -7*weeks-syncCFAnalyticsDays))
+		if err != nil {
+			return err
+		}
+		report.AddTrends(history, weeks)
+	}
+	if !syncCFAnalyticsNoSave {
+		if err := cfanalytics.AppendHistory(path, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runAnalyticsFileReport reports every site in an analytics.yaml.
+func runAnalyticsFileReport(cmd *cobra.Command, cfg *cfanalytics.Config, path string, weeks int) error {
+	if syncCFAnalyticsDataset != "" {
+		return fmt.Errorf("--dataset applies to a single site; set datasets per site in %s", path)
+	}
+	fc, err := cfanalytics.LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	multi := cfanalytics.BuildFileReport(cmd.Context(), fc, cfg, cfanalytics.LastDays(syncCFAnalyticsDays), syncCFAnalyticsTop)
+	for _, s := range multi.Sites {
+		if s.Report == nil {
+			continue
+		}
+		if err := recordAnalyticsHistory(cfanalytics.SiteHistoryPath(s.Name), s.Report, weeks); err != nil {
+			return err
+		}
+	}
+
+	switch syncCFAnalyticsFormat {
+	case "json":
+		if err := printJSON(multi); err != nil {
+			return err
+		}
+	case "markdown":
+		if err := multi.WriteMarkdown(os.Stdout); err != nil {
+			return err
+		}
+	case "text":
+		for i, s := range multi.Sites {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("=== %s ===\n", s.Name)
+			if s.Error != "" {
+				fmt.Printf("Failed: %s\n", s.Error)
+				continue
+			}
+			printAnalyticsReport(s.Report)
+		}
+	default:
+		return fmt.Errorf("unknown format %q (want text, markdown or json)", syncCFAnalyticsFormat)
+	}
+
+	if !multi.OK() {
+		return fmt.Errorf("some sites failed (see report)")
+	}
+	return nil
+}
+
+func printAnalyticsReport(r *cfanalytics.Report) {
+	fmt.Printf("Traffic %s to %s\n", r.Since.Format(time.DateOnly), r.Until.Format(time.DateOnly))
+	if r.SiteTag != "" {
+		fmt.Printf("\n%-12s %12s %10s\n", "DATE", "PAGE VIEWS", "VISITS")
+		for _, d := range r.Days {
+			fmt.Printf("%-12s %12d %10d\n", d.Date, d.PageViews, d.Visits)
+		}
+		fmt.Printf("\n%-50s %12s %10s\n", "PAGE", "PAGE VIEWS", "VISITS")
+		for _, p := range r.Pages {
+			fmt.Printf("%-50s %12d %10d\n", p.Host+p.Path, p.PageViews, p.Visits)
+		}
+	}
+	if len(r.Trends) > 0 {
+		fmt.Printf("\n%-12s %12s %8s %12s  %s\n", "METRIC", "LAST WEEK", "WOW", "4W AVG", "TREND")
+		for _, t := range r.Trends {
+			fmt.Printf("%-12s %12s %8s %12s  %s\n", t.Metric, t.Format(float64(t.Last())), t.FormatWoW(), t.Format(t.MovingAvg), t.Sparkline())
+		}
+	}
+	if r.ZoneID != "" {
+		fmt.Printf("\n%-12s %12s %8s %12s %8s %10s\n", "DATE", "REQUESTS", "CACHED", "BANDWIDTH", "THREATS", "UNIQUES")
+		for _, d := range r.Zone {
+			fmt.Printf("%-12s %12d %8s %12s %8d %10d\n", d.Date, d.Requests, cfanalytics.CachedPercent(d), cfanalytics.FormatBytes(d.Bytes), d.Threats, d.Uniques)
+		}
+	}
+	if r.Has(cfanalytics.DatasetWorkers) {
+		fmt.Printf("\n%-30s %12s %8s %8s %12s\n", "WORKER", "REQUESTS", "ERRORS", "ERR %", "SUBREQUESTS")
+		for _, s := range r.WorkerTotals() {
+			fmt.Printf("%-30s %12d %8d %8s %12d\n", s.Script, s.Requests, s.Errors, cfanalytics.ErrorPercent(s), s.Subrequests)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/joeblew999/xplat/internal/notify"
+	"github.com/joeblew999/xplat/internal/synccf"
+)
+
+var (
+	syncCFBudgetFile     string
+	syncCFBudgetJSON     bool
+	syncCFBudgetInterval time.Duration
+)
+
+var syncCFBudgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Check Cloudflare usage against monthly budgets",
+	Long: `Check this month's Cloudflare usage against the budgets in
+~/.xplat/config/budgets.yaml and alert when a threshold is crossed:
+
+  workers_requests: 10000000   # Requests per calendar month (UTC)
+  r2_storage_gb: 10            # Stored GB across all buckets
+  pages_builds: 500            # Deployments per calendar month
+  thresholds: [50, 80, 100]    # Percentages to alert at (default)
+  degraded:
+    kv_namespace: <id>         # KV namespace bound to the Worker as BUDGET_KV
+    at: 100                    # Percentage that switches degraded mode on
+
+Alerts go to the notification router (~/.xplat/config/notify.yaml or
+XPLAT_NOTIFY_WEBHOOK) with source synccf/budget, once per threshold per
+month. With 'degraded' set, the sync-cf Worker is switched to degraded mode
+(Logpush and R2 events dropped, Pages deploys and alerts still forwarded)
+while any product is over the threshold, and back when usage drops.
+
+The API token needs Account Analytics read, Pages read and, for degraded
+mode, Workers KV Storage edit.
+
+Examples:
+  xplat sync-cf budget                   # Check once (for cron or a Task)
+  xplat sync-cf budget --json
+  xplat sync-cf budget watch --interval 1h
+  xplat sync-cf budget status            # Result of the last check`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, cfg, router, err := newBudgetCheck()
+		if err != nil {
+			return err
+		}
+		check, err := client.CheckBudget(cmd.Context(), cfg, router, time.Now())
+		if check == nil {
+			return err
+		}
+		if syncCFBudgetJSON {
+			if jsonErr := printJSON(check); jsonErr != nil {
+				return jsonErr
+			}
+		} else {
+			printBudgetUsage(check.Usage, check.Degraded)
+			for _, a := range check.Alerts {
+				fmt.Printf("Alerted: %s crossed %d%%\n", a.Usage.Product, a.Threshold)
+			}
+		}
+		return err
+	},
+}
+
+var syncCFBudgetWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Check budgets on an interval",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if syncCFBudgetInterval <= 0 {
+			return fmt.Errorf("--interval must be positive, got %s", syncCFBudgetInterval)
+		}
+		client, cfg, router, err := newBudgetCheck()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+
+		log.Printf("Checking Cloudflare budgets every %s (Ctrl+C to stop)", syncCFBudgetInterval)
+		ticker := time.NewTicker(syncCFBudgetInterval)
+		defer ticker.Stop()
+		for {
+			check, err := client.CheckBudget(ctx, cfg, router, time.Now())
+			if err != nil {
+				log.Printf("Budget check: %v", err)
+			}
+			if check != nil {
+				for _, u := range check.Usage {
+					log.Printf("  %-18s %6.1f%%", u.Product, u.Percent)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+var syncCFBudgetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the result of the last budget check",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		state, err := synccf.LoadBudgetState()
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Println("No budget checks yet. Run 'xplat sync-cf budget' first.")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if syncCFBudgetJSON {
+			return printJSON(state)
+		}
+		fmt.Printf("Checked %s\n", state.CheckedAt.Local().Format(time.RFC3339))
+		printBudgetUsage(state.Usage, state.Degraded)
+		return nil
+	},
+}
+
+func init() {
+	syncCFBudgetCmd.PersistentFlags().StringVar(&syncCFBudgetFile, "file", "", "Budget config (default: ~/.xplat/config/budgets.yaml)")
+	syncCFBudgetCmd.PersistentFlags().BoolVar(&syncCFBudgetJSON, "json", false, "Output as JSON")
+	syncCFBudgetWatchCmd.Flags().DurationVar(&syncCFBudgetInterval, "interval", time.Hour, "Check interval")
+
+	syncCFBudgetCmd.AddCommand(syncCFBudgetWatchCmd)
+	syncCFBudgetCmd.AddCommand(syncCFBudgetStatusCmd)
+	SyncCFCmd.AddCommand(syncCFBudgetCmd)
+}
+
+// newBudgetCheck loads what a budget check needs: the client, budgets and
+// notification router.
+func newBudgetCheck() (*synccf.BudgetClient, *synccf.BudgetConfig, *notify.Router, error) {
+	path := syncCFBudgetFile
+	if path == "" {
+		path = synccf.BudgetConfigPath()
+	}
+	cfg, err := synccf.LoadBudgetConfig(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	accountID, apiToken := getCFCredentials()
+	client, err := synccf.NewBudgetClient(accountID, apiToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	router, err := notify.Default()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !router.Enabled() {
+		log.Printf("Warning: no notification routes configured; alerts are only printed")
+	}
+	return client, cfg, router, nil
+}
+
+func printBudgetUsage(usage []synccf.BudgetUsage, degraded bool) {
+	fmt.Printf("%-18s %16s %16s %8s\n", "PRODUCT", "USED", "BUDGET", "PERCENT")
+	for _, u := range usage {
+		used, limit := fmt.Sprintf("%.0f", u.Used), fmt.Sprintf("%.0f", u.Limit)
+		if u.Unit == "GB" {
+			used, limit = fmt.Sprintf("%.2f GB", u.Used), fmt.Sprintf("%.2f GB", u.Limit)
+		}
+		fmt.Printf("%-18s %16s %16s %7.1f%%\n", u.Product, used, limit, u.Percent)
+	}
+	if degraded {
+		fmt.Println("\nWorker is in degraded mode.")
+	}
+}
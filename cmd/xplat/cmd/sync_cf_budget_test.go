@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncCFBudgetWatchRejectsNonPositiveInterval(t *testing.T) {
+	old := syncCFBudgetInterval
+	defer func() { syncCFBudgetInterval = old }()
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		syncCFBudgetInterval = interval
+		err := syncCFBudgetWatchCmd.RunE(syncCFBudgetWatchCmd, nil)
+		if err == nil || !strings.Contains(err.Error(), "--interval must be positive") {
+			t.Errorf("interval %s: got %v", interval, err)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -13,7 +15,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/joeblew999/xplat/internal/config"
-	"github.com/joeblew999/xplat/internal/synccf"
+	"github.com/joeblew999/xplat/internal/ghauth"
+	"github.com/joeblew999/xplat/internal/notify"
 	"github.com/joeblew999/xplat/internal/syncgh"
 )
 
@@ -32,15 +35,19 @@ Quick Start (zero config real-time sync):
 
 Commands:
   relay       Start webhook relay with CF tunnel (easiest setup)
+  tunnel      Forward webhooks via smee.io, self-hosted SSE, or cloudflared
   poll        Poll for updates continuously (no webhook needed)
   webhook     Start webhook server only
   sse-client  Connect to gosmee server for SSE relay
   state       Capture/display GitHub repo state
   release     Get latest release tag for a repo
-  discover    Find repos from Taskfile.yml remote includes
+  discover    Find repos from Taskfile, go.mod, xplat.yaml, process-compose
+  policy      Enforce branch protection, labels, merge settings, webhooks
 
 Environment:
-  GITHUB_TOKEN    GitHub token for API (increases rate limit 60→5000/hour)
+  GITHUB_TOKEN    GitHub token for API (increases rate limit 60→5000/hour);
+                  GH_TOKEN, the gh CLI login or a GitHub App (GITHUB_APP_ID,
+                  GITHUB_APP_INSTALLATION_ID, GITHUB_APP_PRIVATE_KEY) also work
 
 Sync Methods:
 
@@ -107,7 +114,11 @@ var syncGHStateCmd = &cobra.Command{
 
 		log.Printf("Capturing state for %s...", repo)
 
-		state, err := syncgh.CaptureState(parts[0], parts[1], os.Getenv("GITHUB_TOKEN"))
+		token, err := ghauth.Token(cmd.Context())
+		if err != nil {
+			return err
+		}
+		state, err := syncgh.CaptureState(parts[0], parts[1], token)
 		if err != nil {
 			return fmt.Errorf("failed to capture state: %w", err)
 		}
@@ -139,7 +150,11 @@ var syncGHReleaseCmd = &cobra.Command{
 			return fmt.Errorf("invalid repo format: %s (expected owner/repo)", repo)
 		}
 
-		tag, err := syncgh.GetLatestRelease(parts[0], parts[1], os.Getenv("GITHUB_TOKEN"))
+		token, err := ghauth.Token(cmd.Context())
+		if err != nil {
+			return err
+		}
+		tag, err := syncgh.GetLatestRelease(parts[0], parts[1], token)
 		if err != nil {
 			return err
 		}
@@ -153,6 +168,15 @@ var syncGHPollInterval string
 
 var syncGHPollRepos string
 var syncGHPollInvalidate bool
+var syncGHPollFrom string
+var syncGHPollStateStore string
+var syncGHPollHealthPort int
+var syncGHPollStateFormat string
+var syncGHFilterFile string
+var syncGHPollActivity string
+var syncGHPollActivityEvents string
+var syncGHPollActivityLabels string
+var syncGHPollMention string
 
 var syncGHPollCmd = &cobra.Command{
 	Use:   "poll",
@@ -160,9 +184,34 @@ var syncGHPollCmd = &cobra.Command{
 	Long: `Poll GitHub repositories for updates continuously.
 
 Uses StatefulPoller to track commit hashes and only trigger on actual changes.
-State is persisted to ~/.xplat/cache/syncgh-poll-state.json
+State is persisted to ~/.xplat/cache/syncgh-poll-state.json by default.
+
+Use --state (or XPLAT_SYNCGH_STATE) to share state between machines:
+  file:<dir>            Local directory
+  r2:<remote>:<path>    R2/S3 via rclone (e.g. r2:r2:xplat-state/syncgh)
+  nats:<bucket>         NATS KV bucket (server from NATS_URL)
 
 If --repos is not specified, auto-discovers repos from Taskfile.yml remote includes.
+Use --from to discover from other sources too (see 'xplat sync-gh discover').
+
+An event filter (sync-gh-filter.yaml in the project, or --filter) limits which
+changes invalidate, by branch, changed path and actor:
+
+  branches: [main]
+  paths: ["Taskfile.yml", "taskfiles/**"]
+  ignore_actors: ["*[bot]"]
+  repos:
+    joeblew999/xplat:
+      paths: ["taskfiles/**"]
+
+Path and actor filters cost one compare API call per change.
+
+With --activity, the same repos are also watched for new, labeled and
+mentioning issues, pull requests and discussions. Each item is sent through
+the notification router (~/.xplat/config/notify.yaml or XPLAT_NOTIFY_WEBHOOK)
+with source syncgh/issues or syncgh/discussions; mentions of --mention are
+warnings, everything else info. The first poll only records where to start,
+so existing activity is not replayed. Discussions need a GitHub token.
 
 Examples:
   # Auto-discover repos from Taskfile.yml
@@ -175,7 +224,22 @@ Examples:
   xplat sync-gh poll --repos=joeblew999/xplat,go-task/task --interval=1h
 
   # Poll with Task cache invalidation
-  xplat sync-gh poll --repos=joeblew999/xplat --invalidate`,
+  xplat sync-gh poll --repos=joeblew999/xplat --invalidate
+
+  # Also watch same-owner go.mod dependencies
+  xplat sync-gh poll --from=taskfile,gomod
+
+  # Share state across machines via NATS KV
+  xplat sync-gh poll --state=nats:xplat-state
+
+  # Serve /healthz, /metrics (Prometheus) and /status for probes
+  xplat sync-gh poll --health-port=8772
+
+  # Only invalidate for changes to included taskfiles
+  xplat sync-gh poll --invalidate --filter=sync-gh-filter.yaml
+
+  # Route new issues, security labels and mentions to chat
+  xplat sync-gh poll --activity=issues,discussions --activity-labels=security --mention=joeblew999`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		interval, err := time.ParseDuration(syncGHPollInterval)
 		if err != nil {
@@ -197,12 +261,16 @@ Examples:
 				}
 			}
 		} else {
-			// Auto-discover from Taskfile.yml
-			discovered, err := syncgh.DiscoverReposFromProject(workDir)
+			// Auto-discover from project files (Taskfile.yml by default)
+			sources, err := syncgh.ParseDiscoverSources(syncGHPollFrom)
+			if err != nil {
+				return err
+			}
+			discovered, err := syncgh.DiscoverProjectRepos(workDir, sources)
 			if err != nil {
 				log.Printf("Warning: failed to discover repos: %v", err)
 			}
-			repos = syncgh.DiscoverReposToConfigs(discovered)
+			repos = syncgh.DiscoverReposToConfigs(syncgh.DiscoveredRepoNames(discovered))
 		}
 
 		if len(repos) == 0 {
@@ -214,19 +282,49 @@ Examples:
 			log.Printf("  - %s", r.Subsystem)
 		}
 
-		// Use StatefulPoller for state persistence
-		poller, err := syncgh.NewStatefulPoller(interval, repos, os.Getenv("GITHUB_TOKEN"))
+		store, err := getPollStateStore(syncGHPollStateStore)
+		if err != nil {
+			return err
+		}
+		log.Printf("Poll state: %s", store)
+
+		// Use StatefulPoller for state persistence. The cached source
+		// re-mints GitHub App tokens, which expire after an hour.
+		auth := ghauth.DefaultSource()
+		if _, err := auth.Token(cmd.Context()); err != nil {
+			return err
+		}
+		poller, err := syncgh.NewStatefulPollerWithStore(interval, repos, auth, store)
 		if err != nil {
 			return fmt.Errorf("failed to create poller: %w", err)
 		}
 
+		if syncGHPollHealthPort > 0 {
+			poller.StartHealthServer(syncGHPollHealthPort)
+		}
+
+		filter, err := loadSyncGHFilter(workDir)
+		if err != nil {
+			return err
+		}
+		poller.SetFilter(filter)
+
+		if syncGHPollActivity != "" {
+			if err := startActivityWatcher(repos, store, interval, auth); err != nil {
+				return err
+			}
+		}
+
 		// Wire up callback
 		if syncGHPollInvalidate {
 			log.Printf("Task cache invalidation enabled for: %s", workDir)
 			poller.OnChange(syncgh.TaskCacheInvalidator(workDir))
 		} else {
-			poller.OnChange(func(repo, ref, oldHash, newHash string) {
-				log.Printf("Change detected: %s@%s (%s -> %s)", repo, ref, oldHash, newHash)
+			poller.OnChange(func(c syncgh.Change) {
+				log.Printf("Change detected: %s", c.Summary())
+				if c.CompareURL != "" {
+					log.Printf("  %s", c.CompareURL)
+				}
 			})
 		}
 
@@ -234,50 +332,183 @@ Examples:
 	},
 }
 
+// startActivityWatcher watches the polled repos for issue and discussion
+// activity in the background, routing items through the notify router.
+func startActivityWatcher(repos []syncgh.RepoConfig, store syncgh.StateStore, interval time.Duration, auth ghauth.TokenSource) error {
+	kinds, err := syncgh.ParseActivityKinds(syncGHPollActivity)
+	if err != nil {
+		return err
+	}
+	events, err := syncgh.ParseActivityEvents(syncGHPollActivityEvents)
+	if err != nil {
+		return err
+	}
+	cfg := syncgh.ActivityConfig{Kinds: kinds, Events: events, Mention: syncGHPollMention}
+	for _, l := range strings.Split(syncGHPollActivityLabels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			cfg.Labels = append(cfg.Labels, l)
+		}
+	}
+
+	router, err := notify.Default()
+	if err != nil {
+		return err
+	}
+	if !router.Enabled() {
+		log.Printf("Warning: no notification routes (%s or %s); activity is only logged", notify.ConfigPath(), notify.EnvWebhook)
+	}
+
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.Subsystem
+	}
+	watcher, err := syncgh.NewActivityWatcher(names, cfg, auth, store)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	go watcher.Start(ctx, interval, syncgh.ActivityNotifier(ctx, router))
+	return nil
+}
+
+// loadSyncGHFilter returns the event filter from --filter, else the
+// project's sync-gh-filter.yaml, else nil (no filtering).
+func loadSyncGHFilter(workDir string) (*syncgh.EventFilter, error) {
+	if syncGHFilterFile != "" {
+		filter, err := syncgh.LoadEventFilter(syncGHFilterFile)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Event filter: %s", syncGHFilterFile)
+		return filter, nil
+	}
+	filter, err := syncgh.LoadProjectEventFilter(workDir)
+	if filter != nil {
+		log.Printf("Event filter: %s", syncgh.DefaultFilterFile)
+	}
+	return filter, err
+}
+
+// getPollStateStore returns the poll state backend.
+// Priority: --state flag > XPLAT_SYNCGH_STATE > local cache file
+func getPollStateStore(flagValue string) (syncgh.StateStore, error) {
+	if flagValue != "" {
+		return syncgh.ParseStateStore(flagValue)
+	}
+	return syncgh.DefaultStateStore()
+}
+
 var syncGHPollStateCmd = &cobra.Command{
 	Use:   "poll-state",
 	Short: "Show current poll state (tracked repos and commit hashes)",
+	Long: `Show the poll state: per repo the last commit hash, when it was last
+polled and last changed, the last error and the next expected poll.
+
+A repo is stale when it hasn't been polled for two intervals, meaning the
+poller has stopped. Exits with status 1 if any repo is stale or failing.
+
+Examples:
+  xplat sync-gh poll-state
+  xplat sync-gh poll-state --format json
+  xplat sync-gh poll-state --format prometheus > /var/lib/node_exporter/syncgh.prom`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		state, err := syncgh.LoadPollState()
+		store, err := getPollStateStore(syncGHPollStateStore)
 		if err != nil {
-			return fmt.Errorf("failed to load poll state: %w", err)
+			return err
 		}
 
-		if len(state.Repos) == 0 {
-			fmt.Println("No repos tracked yet. Run 'xplat sync-gh poll' first.")
-			return nil
+		state, err := syncgh.LoadPollStateFrom(store)
+		if err != nil {
+			return fmt.Errorf("failed to load poll state: %w", err)
 		}
-
-		fmt.Printf("Poll state (%s):\n", config.XplatCache()+"/syncgh-poll-state.json")
-		fmt.Printf("Updated: %s\n\n", state.UpdatedAt.Format(time.RFC3339))
-
-		for key, info := range state.Repos {
-			fmt.Printf("  %s\n", key)
-			fmt.Printf("    Commit:  %s\n", info.CommitHash)
-			fmt.Printf("    Checked: %s\n", info.LastChecked.Format(time.RFC3339))
+		statuses := state.Status(time.Now())
+
+		switch syncGHPollStateFormat {
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(statuses); err != nil {
+				return err
+			}
+		case "prometheus":
+			syncgh.WritePollMetrics(os.Stdout, statuses)
+		case "text", "":
+			if len(statuses) == 0 {
+				fmt.Println("No repos tracked yet. Run 'xplat sync-gh poll' first.")
+				return nil
+			}
+			printPollStatuses(store, state, statuses)
+		default:
+			return fmt.Errorf("unknown format %q (valid: text, json, prometheus)", syncGHPollStateFormat)
 		}
 
+		for _, s := range statuses {
+			if !s.Healthy() {
+				os.Exit(1)
+			}
+		}
 		return nil
 	},
 }
 
+// printPollStatuses prints poll statuses as text.
+func printPollStatuses(store syncgh.StateStore, state *syncgh.PollState, statuses []syncgh.RepoPollStatus) {
+	fmt.Printf("Poll state (%s):\n", store)
+	fmt.Printf("Updated: %s\n\n", state.UpdatedAt.Format(time.RFC3339))
+
+	for _, s := range statuses {
+		marker := ""
+		if s.Stale {
+			marker = " [STALE]"
+		}
+		fmt.Printf("  %s@%s%s\n", s.Repo, s.Ref, marker)
+		fmt.Printf("    Commit:    %s\n", s.CommitHash)
+		fmt.Printf("    Checked:   %s\n", s.LastChecked.Format(time.RFC3339))
+		if !s.LastChanged.IsZero() {
+			fmt.Printf("    Changed:   %s\n", s.LastChanged.Format(time.RFC3339))
+		}
+		fmt.Printf("    Next poll: %s\n", s.NextPoll.Format(time.RFC3339))
+		if s.LastError != "" {
+			fmt.Printf("    Error:     %s\n", s.LastError)
+		}
+	}
+}
+
+var syncGHDiscoverFrom string
+
 var syncGHDiscoverCmd = &cobra.Command{
 	Use:   "discover",
-	Short: "Discover GitHub repos from Taskfile.yml remote includes",
-	Long: `Scan Taskfile.yml files in the current project for remote includes
-and list the GitHub repos that would be watched.
+	Short: "Discover GitHub repos from Taskfile, go.mod, xplat.yaml and process-compose",
+	Long: `Scan the current project for GitHub repo references and list the repos
+that would be watched.
+
+Sources:
+  taskfile         Remote includes in Taskfile.yml files
+  gomod            go.mod require lines (same owner as the module, direct only)
+  xplat            xplat.yaml binary source and dependencies
+  process-compose  github.com references in process-compose commands
 
-This shows what repos the sync poller would auto-discover.`,
+Each repo is tagged with the sources it was found in.
+
+Examples:
+  xplat sync-gh discover                          # All sources
+  xplat sync-gh discover --from=taskfile,gomod    # Only Taskfile includes and go.mod`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		workDir, _ := os.Getwd()
 
-		repos, err := syncgh.DiscoverReposFromProject(workDir)
+		sources, err := syncgh.ParseDiscoverSources(syncGHDiscoverFrom)
+		if err != nil {
+			return err
+		}
+
+		repos, err := syncgh.DiscoverProjectRepos(workDir, sources)
 		if err != nil {
 			return fmt.Errorf("failed to discover repos: %w", err)
 		}
 
 		if len(repos) == 0 {
-			fmt.Println("No remote GitHub repos found in Taskfile.yml includes.")
+			fmt.Println("No GitHub repos found.")
 			fmt.Println("Add remote includes like:")
 			fmt.Println("  includes:")
 			fmt.Println("    remote:")
@@ -285,19 +516,206 @@ This shows what repos the sync poller would auto-discover.`,
 			return nil
 		}
 
-		fmt.Printf("Discovered %d GitHub repo(s) from Taskfile.yml:\n\n", len(repos))
+		fmt.Printf("Discovered %d GitHub repo(s):\n\n", len(repos))
 		for _, repo := range repos {
-			fmt.Printf("  %s\n", repo)
+			tags := make([]string, len(repo.Sources))
+			for i, s := range repo.Sources {
+				tags[i] = string(s)
+			}
+			fmt.Printf("  %-40s [%s]\n", repo.Repo, strings.Join(tags, ", "))
 		}
 
 		fmt.Println()
 		fmt.Println("To poll these repos:")
-		fmt.Println("  xplat sync-gh poll --invalidate")
+		if syncGHDiscoverFrom == "" {
+			fmt.Println("  xplat sync-gh poll --invalidate --from=all")
+		} else {
+			fmt.Printf("  xplat sync-gh poll --invalidate --from=%s\n", syncGHDiscoverFrom)
+		}
+
+		return nil
+	},
+}
+
+var syncGHPolicyFile string
+var syncGHPolicyRepos string
+var syncGHPolicyFrom string
+var syncGHPolicyDryRun bool
+
+var syncGHPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Enforce branch protection, labels, merge settings and webhooks",
+	Long: `Check and enforce repo settings from a policy file across repos.
+
+The policy file sets branch protection, labels, merge settings and required
+webhooks:
+
+  branches:
+    default:                  # The repo's default branch
+      required_reviews: 1
+      required_checks: [ci]
+      strict: true
+      linear_history: true
+  labels:
+    - name: bug
+      color: d73a4a
+      description: Something isn't working
+  merge:
+    allow_merge_commit: false
+    allow_squash_merge: true
+    delete_branch_on_merge: true
+  webhooks:
+    - url: https://hooks.example.com/webhook
+      events: [push, release]
+      secret_env: WEBHOOK_SECRET
+
+Protected branches get exactly the policy's protection. Labels and webhooks
+are added or corrected, never deleted. Webhooks are matched by URL and
+corrected for their events, json content type and active flag. A secret_env
+that is not set fails the repo instead of creating an unsigned hook.
+
+Each run saves the drift it found to <dir>/policy-drift.json. Drift that was
+not in the previous snapshot is reported as an alert.
+
+Environment:
+  GITHUB_TOKEN    Token with admin access to the repos (required)`,
+}
+
+var syncGHPolicyApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply a repo policy to discovered repos",
+	Long: `Apply a repo policy to the given repos, or to the repos discovered in
+the project (see 'xplat sync-gh discover').
+
+Examples:
+  # Show what would change
+  xplat sync-gh policy apply --file repo-policy.yaml --dry-run
+
+  # Enforce on two repos
+  xplat sync-gh policy apply --file repo-policy.yaml --repos=joeblew999/xplat,joeblew999/plat-garage`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		drift, err := runSyncGHPolicy(!syncGHPolicyDryRun)
+		if err != nil {
+			return err
+		}
+		if syncGHPolicyDryRun && len(drift) > 0 {
+			fmt.Println("\nDry run: no changes made. Run without --dry-run to apply.")
+		}
+		return nil
+	},
+}
+
+var syncGHPolicyCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report repo policy drift without changing anything",
+	Long: `Report where repos differ from the policy and exit non-zero if any do.
+Suitable for a scheduled CI job.
 
+Examples:
+  xplat sync-gh policy check --file repo-policy.yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		drift, err := runSyncGHPolicy(false)
+		if err != nil {
+			return err
+		}
+		if len(drift) > 0 {
+			return fmt.Errorf("%d setting(s) differ from %s", len(drift), syncGHPolicyFile)
+		}
 		return nil
 	},
 }
 
+// runSyncGHPolicy checks every repo against the policy file, applies the
+// fixes if apply is set, and saves the drift snapshot.
+func runSyncGHPolicy(apply bool) ([]syncgh.PolicyDrift, error) {
+	policy, err := syncgh.LoadPolicy(syncGHPolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []string
+	if syncGHPolicyRepos != "" {
+		for _, r := range strings.Split(syncGHPolicyRepos, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				repos = append(repos, r)
+			}
+		}
+	} else {
+		workDir, _ := os.Getwd()
+		sources, err := syncgh.ParseDiscoverSources(syncGHPolicyFrom)
+		if err != nil {
+			return nil, err
+		}
+		discovered, err := syncgh.DiscoverProjectRepos(workDir, sources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover repos: %w", err)
+		}
+		repos = syncgh.DiscoveredRepoNames(discovered)
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no repos found. Use --repos=owner/repo or --from to discover them")
+	}
+
+	token, err := ghauth.Token(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	client, err := syncgh.NewPolicyClient(token)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := syncgh.LoadPolicyReport(syncGHStateDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	var all []syncgh.PolicyDrift
+	for _, repo := range repos {
+		drift, err := client.Check(ctx, repo, policy)
+		if err != nil {
+			return nil, err
+		}
+		if len(drift) == 0 {
+			fmt.Printf("%s: ok\n", repo)
+			continue
+		}
+
+		fmt.Printf("%s: %d setting(s) differ\n", repo, len(drift))
+		for _, d := range drift {
+			target := d.Target
+			if d.Field != "" {
+				target += "." + d.Field
+			}
+			fmt.Printf("  ~ %-8s %-40s %s -> %s\n", d.Kind, target, d.Have, d.Want)
+		}
+		all = append(all, drift...)
+
+		if apply {
+			if err := client.Apply(ctx, repo, policy, drift); err != nil {
+				return nil, err
+			}
+			fmt.Printf("  ✓ applied\n")
+		}
+	}
+
+	for _, d := range syncgh.NewDrift(previous, all) {
+		fmt.Printf("ALERT: new drift %s\n", d)
+	}
+
+	report := &syncgh.PolicyReport{
+		CheckedAt: time.Now().UTC(),
+		Repos:     repos,
+		Drift:     all,
+		Applied:   apply && len(all) > 0,
+	}
+	if err := syncgh.SavePolicyReport(report, syncGHStateDir); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
 var syncGHWebhookPort string
 var syncGHWebhookInvalidate bool
 
@@ -307,18 +725,100 @@ var syncGHWebhookCmd = &cobra.Command{
 	Long: `Start a webhook server to receive GitHub events.
 
 When --invalidate is set, push events will trigger Task cache invalidation,
-enabling real-time sync of remote taskfiles.
+enabling real-time sync of remote taskfiles. Pushes and releases can be
+limited by branch, changed path and actor with an event filter
+(sync-gh-filter.yaml in the project, or --filter; see 'xplat sync-gh poll --help').
+
+With --archive, every received payload is stored under
+webhooks/<delivery-id>.json in a state store (see 'xplat sync-gh archive').
 
 Examples:
   xplat sync-gh webhook --port=8763
-  xplat sync-gh webhook --port=8763 --invalidate`,
-	Run: func(cmd *cobra.Command, args []string) {
+  xplat sync-gh webhook --port=8763 --invalidate
+  xplat sync-gh webhook --archive
+  xplat sync-gh webhook --archive=r2:r2:xplat-state/syncgh`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archive, err := newSyncGHArchive()
+		if err != nil {
+			return err
+		}
+
+		workDir, _ := os.Getwd()
+		filter, err := loadSyncGHFilter(workDir)
+		if err != nil {
+			return err
+		}
+
+		config := syncgh.WebhookConfig{
+			Port:       syncGHWebhookPort,
+			Invalidate: syncGHWebhookInvalidate,
+			Archive:    archive,
+			Filter:     filter,
+		}
 		if syncGHWebhookInvalidate {
-			workDir, _ := os.Getwd()
-			syncgh.RunWebhookWithInvalidation(syncGHWebhookPort, workDir)
-		} else {
-			syncgh.RunWebhook(syncGHWebhookPort)
+			config.WorkDir = workDir
+			log.Printf("Task cache invalidation enabled for: %s", config.WorkDir)
+		}
+		return syncgh.NewWebhookServerWithConfig(config).Run()
+	},
+}
+
+var syncGHArchiveURL string
+var syncGHArchiveBody bool
+
+// newSyncGHArchive returns the payload archive for --archive, or nil if unset.
+func newSyncGHArchive() (*syncgh.PayloadArchive, error) {
+	if syncGHArchiveURL == "" {
+		return nil, nil
+	}
+	return syncgh.NewPayloadArchive(syncGHArchiveURL)
+}
+
+var syncGHArchiveCmd = &cobra.Command{
+	Use:   "archive <delivery-id>",
+	Short: "Fetch an archived webhook payload by delivery ID",
+	Long: `Fetch a webhook payload archived by 'sync-gh webhook --archive' or
+'sync-gh sse-client --archive'.
+
+--archive takes the same state store spec as the poll state (see
+'xplat sync-gh poll --help'):
+  file                 ~/.xplat/cache/webhooks/ (default for a bare --archive)
+  file:<dir>           <dir>/webhooks/
+  r2:<remote>:<path>   R2/S3 via rclone, shared between machines
+  nats:<bucket>        NATS KV bucket
+
+The delivery ID is the X-GitHub-Delivery header, shown in the webhook
+server log and by 'sync-gh replay --list-deliveries'.
+
+Examples:
+  # Archived record (headers, event, receive time and payload)
+  xplat sync-gh archive 72d3162e-cc78-11e3-81ab-4c9367dc0958 --archive
+
+  # From the R2 archive, just the payload, e.g. to replay it by hand
+  xplat sync-gh archive 72d3162e-... --archive=r2:r2:xplat-state/syncgh --body | \
+    curl -X POST -H 'X-GitHub-Event: push' -d @- http://localhost:8763/webhook`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if syncGHArchiveURL == "" {
+			syncGHArchiveURL = "file"
+		}
+		archive, err := newSyncGHArchive()
+		if err != nil {
+			return err
+		}
+
+		p, err := archive.Get(cmd.Context(), args[0])
+		if err != nil {
+			return err
 		}
+
+		if syncGHArchiveBody {
+			_, err = os.Stdout.Write(p.Payload())
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(p)
 	},
 }
 
@@ -380,12 +880,18 @@ var syncGHSSETargetPort string
 var syncGHSSESaveDir string
 var syncGHSSEIgnoreEvents string
 var syncGHSSEHealthPort int
+var syncGHSSENoBuffer bool
 
 // SSE Server flags
 var syncGHServerPort string
 var syncGHServerPublicURL string
 var syncGHServerSecrets string
 
+var syncGHRelayProvider string
+var syncGHTunnelProvider string
+var syncGHTunnelURL string
+var syncGHTunnelIgnoreEvents string
+
 var syncGHRelayCmd = &cobra.Command{
 	Use:   "relay",
 	Short: "Start webhook relay with Cloudflare tunnel (zero config real-time sync)",
@@ -399,16 +905,24 @@ This command:
 The tunnel URL changes each time (quick tunnel), so you'll need to
 update the GitHub webhook URL after each restart.
 
-For stable URLs, use named tunnels (see 'xplat sync-cf' commands).
+For stable URLs, use named tunnels (see 'xplat sync-cf' commands), or
+relay through smee.io or your own SSE server with --provider.
 
 Usage:
   xplat sync-gh relay
+  xplat sync-gh relay --provider=smee
+  xplat sync-gh relay --provider=sse --url=https://webhook.example.com
 
-Then configure GitHub webhook to POST to: <tunnel-url>/webhook
+Then configure GitHub webhook to POST to the displayed URL.
 Push events will automatically invalidate your Task cache.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		workDir, _ := os.Getwd()
 
+		provider, err := newSyncGHTunnelProvider(syncGHRelayProvider)
+		if err != nil {
+			return err
+		}
+
 		// Start webhook server in background
 		go func() {
 			log.Printf("Starting local webhook handler on port %s with cache invalidation", syncGHWebhookPort)
@@ -418,34 +932,74 @@ Push events will automatically invalidate your Task cache.`,
 		// Give server time to start
 		time.Sleep(200 * time.Millisecond)
 
-		// Setup signal handling for graceful shutdown
-		ctx, cancel := context.WithCancel(context.Background())
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 		defer cancel()
 
-		sigCh := make(chan os.Signal, 1)
-		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		go func() {
-			<-sigCh
-			log.Printf("Shutting down...")
-			cancel()
-		}()
+		return syncgh.RunTunnel(ctx, provider, localWebhookURL(syncGHWebhookPort))
+	},
+}
+
+var syncGHTunnelCmd = &cobra.Command{
+	Use:   "tunnel",
+	Short: "Forward GitHub webhooks to a local handler via a tunnel provider",
+	Long: `Expose a local webhook handler to GitHub through a tunnel provider.
 
-		// Start cloudflared tunnel
-		log.Printf("Starting Cloudflare quick tunnel...")
-		log.Printf("")
-		log.Printf("Once the tunnel URL appears, configure your GitHub webhook to POST to:")
-		log.Printf("  <tunnel-url>/webhook")
-		log.Printf("")
+Providers:
+  smee         Relay through a smee.io channel (new channel unless --url is set)
+  sse          Relay through a self-hosted SSE server (xplat sync-gh server)
+  cloudflared  Cloudflare quick tunnel straight to the local port
 
-		portInt := 8763
-		if _, err := fmt.Sscanf(syncGHWebhookPort, "%d", &portInt); err != nil {
-			portInt = 8763
+Unlike 'relay', this does not start a webhook handler. Run one separately
+(e.g. 'xplat sync-gh webhook --invalidate').
+
+Examples:
+  xplat sync-gh tunnel
+  xplat sync-gh tunnel --provider=smee --url=https://smee.io/abc123
+  xplat sync-gh tunnel --provider=sse --url=https://webhook.example.com
+  xplat sync-gh tunnel --provider=cloudflared --port=8763`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := newSyncGHTunnelProvider(syncGHTunnelProvider)
+		if err != nil {
+			return err
 		}
 
-		return synccf.RunTunnel(ctx, portInt)
+		ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer cancel()
+
+		return syncgh.RunTunnel(ctx, provider, localWebhookURL(syncGHWebhookPort))
 	},
 }
 
+// newSyncGHTunnelProvider builds the named tunnel provider from the shared tunnel flags.
+func newSyncGHTunnelProvider(name string) (syncgh.TunnelProvider, error) {
+	var ignoreEvents []string
+	for _, e := range strings.Split(syncGHTunnelIgnoreEvents, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			ignoreEvents = append(ignoreEvents, e)
+		}
+	}
+
+	return syncgh.NewTunnelProvider(name, syncgh.TunnelOptions{
+		ServerURL:    syncGHTunnelURL,
+		IgnoreEvents: ignoreEvents,
+		BufferDir:    syncGHBufferDir(syncGHWebhookPort),
+	})
+}
+
+// syncGHBufferDir returns the directory relayed events are buffered in
+// until the local handler on port accepts them, or "" with --no-buffer.
+func syncGHBufferDir(port string) string {
+	if syncGHSSENoBuffer {
+		return ""
+	}
+	return filepath.Join(config.XplatCache(), "syncgh", "sse-buffer", port)
+}
+
+// localWebhookURL returns the local webhook handler URL for a port.
+func localWebhookURL(port string) string {
+	return fmt.Sprintf("http://localhost:%s/webhook", port)
+}
+
 var syncGHSSEClientCmd = &cobra.Command{
 	Use:   "sse-client <server-url>",
 	Short: "Connect to a gosmee server and forward events to local webhook handler",
@@ -469,11 +1023,20 @@ Example usage:
   # Save payloads for debugging/replay
   xplat sync-gh sse-client https://webhook.example.com/abc123 --save-dir=./webhooks
 
+  # Archive payloads by delivery ID (see 'xplat sync-gh archive')
+  xplat sync-gh sse-client https://webhook.example.com/abc123 --archive
+
   # Ignore certain event types
   xplat sync-gh sse-client https://webhook.example.com/abc123 --ignore-event=ping,status
 
   # Enable health endpoint for K8s probes
-  xplat sync-gh sse-client https://webhook.example.com/abc123 --health-port=8080`,
+  xplat sync-gh sse-client https://webhook.example.com/abc123 --health-port=8080
+
+Events the local handler does not accept (it is down or returns 5xx) are
+buffered in ~/.xplat/cache/syncgh/sse-buffer/<port>/ and retried with
+backoff, in order, until it does - including across restarts - so no push
+is missed. Events it rejects with a 4xx are moved to rejected/ in that
+directory. Use --no-buffer to drop undeliverable events instead.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		serverURL := args[0]
@@ -489,21 +1052,29 @@ Example usage:
 			}
 		}
 
-		if syncGHWebhookInvalidate {
-			// All-in-one: local webhook handler + SSE client + cache invalidation
-			workDir, _ := os.Getwd()
-			return syncgh.RunSSEClientWithInvalidation(serverURL, workDir, syncGHSSETargetPort, syncGHSSESaveDir, ignoreEvents, syncGHSSEHealthPort)
+		archive, err := newSyncGHArchive()
+		if err != nil {
+			return err
 		}
 
-		// Use full config for advanced options
-		targetURL := fmt.Sprintf("http://localhost:%s/webhook", syncGHSSETargetPort)
-		return syncgh.RunSSEClientWithOptions(context.Background(), syncgh.SSEClientConfig{
+		config := syncgh.SSEClientConfig{
 			ServerURL:    serverURL,
-			TargetURL:    targetURL,
 			SaveDir:      syncGHSSESaveDir,
+			Archive:      archive,
 			IgnoreEvents: ignoreEvents,
 			HealthPort:   syncGHSSEHealthPort,
-		})
+			BufferDir:    syncGHBufferDir(syncGHSSETargetPort),
+		}
+
+		if syncGHWebhookInvalidate {
+			// All-in-one: local webhook handler + SSE client + cache invalidation
+			workDir, _ := os.Getwd()
+			return syncgh.RunSSEClientWithInvalidation(config, workDir, syncGHSSETargetPort)
+		}
+
+		// Use full config for advanced options
+		config.TargetURL = fmt.Sprintf("http://localhost:%s/webhook", syncGHSSETargetPort)
+		return syncgh.RunSSEClientWithOptions(context.Background(), config)
 	},
 }
 
@@ -514,6 +1085,9 @@ var syncGHReplaySince string
 var syncGHReplaySaveDir string
 var syncGHReplayIgnoreEvents string
 var syncGHReplayContinuous bool
+var syncGHReplayEvents string
+var syncGHReplayFailed bool
+var syncGHReplayRedeliver bool
 
 var syncGHReplayCmd = &cobra.Command{
 	Use:   "replay <owner/repo> [hook-id] [target-url]",
@@ -544,7 +1118,13 @@ Examples:
   xplat sync-gh replay owner/repo 12345 http://localhost:8763/webhook --continuous
 
   # Save payloads while replaying
-  xplat sync-gh replay owner/repo 12345 http://localhost:8763/webhook --save-dir=./webhooks`,
+  xplat sync-gh replay owner/repo 12345 http://localhost:8763/webhook --save-dir=./webhooks
+
+  # Replay only failed push and release deliveries
+  xplat sync-gh replay owner/repo 12345 http://localhost:8763/webhook --event=push,release --failed
+
+  # Ask GitHub to redeliver failed deliveries to the hook's own URL
+  xplat sync-gh replay owner/repo 12345 --redeliver --failed`,
 	Args: cobra.RangeArgs(1, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Parse owner/repo
@@ -558,7 +1138,11 @@ Examples:
 			owner = orgRepo
 		}
 
-		token := os.Getenv("GITHUB_TOKEN")
+		// A --continuous replay runs for hours, so GitHub App tokens are re-minted
+		token := ghauth.DefaultSource()
+		if _, err := token.Token(cmd.Context()); err != nil {
+			return err
+		}
 
 		// List hooks
 		if syncGHReplayListHooks {
@@ -580,13 +1164,14 @@ Examples:
 			return syncgh.RunReplayListDeliveries(owner, repo, hookID, token)
 		}
 
-		// Need target URL for replay
-		if len(args) < 3 {
-			return fmt.Errorf("target URL is required for replay (e.g., http://localhost:8763/webhook)")
+		// Need target URL for replay, unless GitHub redelivers to the hook URL
+		var targetURL string
+		if len(args) >= 3 {
+			targetURL = args[2]
+		} else if !syncGHReplayRedeliver {
+			return fmt.Errorf("target URL is required for replay (e.g., http://localhost:8763/webhook), or use --redeliver")
 		}
 
-		targetURL := args[2]
-
 		// Parse since time
 		var sinceTime time.Time
 		if syncGHReplaySince != "" {
@@ -608,6 +1193,17 @@ Examples:
 			}
 		}
 
+		// Parse event filter
+		var events []string
+		if syncGHReplayEvents != "" {
+			for _, e := range strings.Split(syncGHReplayEvents, ",") {
+				e = strings.TrimSpace(e)
+				if e != "" {
+					events = append(events, e)
+				}
+			}
+		}
+
 		return syncgh.RunReplay(syncgh.ReplayConfig{
 			Owner:        owner,
 			Repo:         repo,
@@ -616,6 +1212,9 @@ Examples:
 			Since:        sinceTime,
 			SaveDir:      syncGHReplaySaveDir,
 			IgnoreEvents: ignoreEvents,
+			Events:       events,
+			FailedOnly:   syncGHReplayFailed,
+			Redeliver:    syncGHReplayRedeliver,
 			Continuous:   syncGHReplayContinuous,
 			Token:        token,
 		})
@@ -652,7 +1251,10 @@ Full relay setup:
   1. Start server:  xplat sync-gh server --port=3333
   2. Start tunnel:  xplat sync-cf tunnel --port=3333
   3. Configure GitHub webhook to: <tunnel-url>/<channel>
-  4. Connect client: xplat sync-gh sse-client <tunnel-url>/<channel> --invalidate`,
+  4. Connect client: xplat sync-gh sse-client <tunnel-url>/<channel> --invalidate
+
+Managed hosting (stable URL without a local tunnel):
+  xplat sync-gh server deploy --target=fly`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var secrets []string
 		if syncGHServerSecrets != "" {
@@ -668,6 +1270,71 @@ Full relay setup:
 	},
 }
 
+// SSE server deploy flags
+var syncGHDeployTarget string
+var syncGHDeployApp string
+var syncGHDeployRegion string
+var syncGHDeployProject string
+var syncGHDeployVersion string
+var syncGHDeployBuildDir string
+var syncGHDeployDryRun bool
+
+var syncGHServerDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy the SSE server to Fly.io or Google Cloud Run",
+	Long: `Package the gosmee-compatible SSE server and deploy it to a managed platform.
+
+This gives you a stable, self-hosted webhook relay URL so production
+setups do not depend on smee.io availability.
+
+The command:
+  1. Writes a build context (Dockerfile running the xplat release binary)
+  2. Provisions the target (idempotent - reuses existing app/service)
+  3. Sets webhook secrets on the platform
+  4. Deploys and prints the public URL
+
+Targets:
+  fly       Uses flyctl (FLY_API_TOKEN or 'fly auth login')
+  cloudrun  Uses gcloud (active gcloud account and project)
+
+Examples:
+  # Deploy to Fly.io
+  xplat sync-gh server deploy --target=fly --app=my-webhook-relay
+
+  # Deploy to Cloud Run with signature validation
+  xplat sync-gh server deploy --target=cloudrun --project=my-project --secrets=s3cret
+
+  # Show what would run without deploying
+  xplat sync-gh server deploy --target=fly --dry-run --build-dir=./.deploy`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, err := syncgh.ParseDeployTarget(syncGHDeployTarget)
+		if err != nil {
+			return err
+		}
+
+		var secrets []string
+		if syncGHServerSecrets != "" {
+			for _, s := range strings.Split(syncGHServerSecrets, ",") {
+				s = strings.TrimSpace(s)
+				if s != "" {
+					secrets = append(secrets, s)
+				}
+			}
+		}
+
+		return syncgh.RunDeploy(syncgh.DeployConfig{
+			Target:         target,
+			App:            syncGHDeployApp,
+			Region:         syncGHDeployRegion,
+			Project:        syncGHDeployProject,
+			Version:        syncGHDeployVersion,
+			WebhookSecrets: secrets,
+			BuildDir:       syncGHDeployBuildDir,
+			DryRun:         syncGHDeployDryRun,
+		})
+	},
+}
+
 func init() {
 	syncGHStateCmd.Flags().StringVar(&syncGHStateDir, "dir", ".github/state", "State directory")
 	syncGHStateCmd.Flags().BoolVar(&syncGHShowOnly, "show", false, "Display current state without fetching")
@@ -675,35 +1342,92 @@ func init() {
 	syncGHPollCmd.Flags().StringVar(&syncGHPollInterval, "interval", config.DefaultSyncInterval, "Poll interval (e.g., 5m, 1h)")
 	syncGHPollCmd.Flags().StringVar(&syncGHPollRepos, "repos", "", "Repos to poll (comma-separated: owner/repo,owner2/repo2)")
 	syncGHPollCmd.Flags().BoolVar(&syncGHPollInvalidate, "invalidate", false, "Invalidate Task cache on change")
+	syncGHPollCmd.Flags().StringVar(&syncGHPollFrom, "from", "taskfile", "Discovery sources when --repos is not set (taskfile,gomod,xplat,process-compose or all)")
+	syncGHPollCmd.Flags().StringVar(&syncGHPollStateStore, "state", "", "Poll state backend: file[:dir], r2:<remote>:<path>, nats:<bucket> (default: $XPLAT_SYNCGH_STATE or local file)")
+	syncGHPollCmd.Flags().IntVar(&syncGHPollHealthPort, "health-port", 0, "Port for /healthz, /metrics and /status (0 = disabled)")
+	syncGHPollCmd.Flags().StringVar(&syncGHFilterFile, "filter", "", "Event filter file (default: sync-gh-filter.yaml if present)")
+	syncGHPollCmd.Flags().StringVar(&syncGHPollActivity, "activity", "", "Also watch activity: issues, discussions (comma-separated)")
+	syncGHPollCmd.Flags().StringVar(&syncGHPollActivityEvents, "activity-events", "", "Activity events to route: new, labeled, mentioned (default: all)")
+	syncGHPollCmd.Flags().StringVar(&syncGHPollActivityLabels, "activity-labels", "", "Only route labeled events for these labels (comma-separated)")
+	syncGHPollCmd.Flags().StringVar(&syncGHPollMention, "mention", "", "GitHub login whose @mentions are routed")
+	syncGHPollStateCmd.Flags().StringVar(&syncGHPollStateStore, "state", "", "Poll state backend (see 'sync-gh poll --help')")
+	syncGHPollStateCmd.Flags().StringVar(&syncGHPollStateFormat, "format", "text", "Output format: text, json, prometheus")
+
+	syncGHDiscoverCmd.Flags().StringVar(&syncGHDiscoverFrom, "from", "", "Discovery sources (taskfile,gomod,xplat,process-compose; default all)")
+
+	for _, c := range []*cobra.Command{syncGHPolicyApplyCmd, syncGHPolicyCheckCmd} {
+		c.Flags().StringVar(&syncGHPolicyFile, "file", "repo-policy.yaml", "Policy file")
+		c.Flags().StringVar(&syncGHPolicyRepos, "repos", "", "Repos to check (comma-separated: owner/repo,owner2/repo2)")
+		c.Flags().StringVar(&syncGHPolicyFrom, "from", "", "Discovery sources when --repos is not set (taskfile,gomod,xplat,process-compose; default all)")
+		c.Flags().StringVar(&syncGHStateDir, "dir", ".github/state", "State directory for the drift snapshot")
+		syncGHPolicyCmd.AddCommand(c)
+	}
+	syncGHPolicyApplyCmd.Flags().BoolVar(&syncGHPolicyDryRun, "dry-run", false, "Show the changes without applying them")
 
 	syncGHWebhookCmd.Flags().StringVar(&syncGHWebhookPort, "port", config.DefaultWebhookPort, "Webhook server port")
 	syncGHWebhookCmd.Flags().BoolVar(&syncGHWebhookInvalidate, "invalidate", false, "Invalidate Task cache on push events")
+	syncGHWebhookCmd.Flags().StringVar(&syncGHArchiveURL, "archive", "", "State store to archive payloads to (file, file:<dir>, r2:<remote>:<path>, nats:<bucket>)")
+	syncGHWebhookCmd.Flags().Lookup("archive").NoOptDefVal = "file"
+	syncGHWebhookCmd.Flags().StringVar(&syncGHFilterFile, "filter", "", "Event filter file (default: sync-gh-filter.yaml if present)")
+
+	syncGHArchiveCmd.Flags().StringVar(&syncGHArchiveURL, "archive", "", "State store the payloads are archived in (default: file)")
+	syncGHArchiveCmd.Flags().Lookup("archive").NoOptDefVal = "file"
+	syncGHArchiveCmd.Flags().BoolVar(&syncGHArchiveBody, "body", false, "Print only the original payload")
 
 	syncGHWebhookAddCmd.Flags().StringVar(&syncGHWebhookAddEvents, "events", "push,release,workflow_run,page_build,deployment_status", "Webhook events")
 
 	syncGHSSEClientCmd.Flags().StringVar(&syncGHSSETargetPort, "port", config.DefaultWebhookPort, "Local webhook server port")
 	syncGHSSEClientCmd.Flags().BoolVar(&syncGHWebhookInvalidate, "invalidate", false, "Start local webhook server with cache invalidation")
 	syncGHSSEClientCmd.Flags().StringVar(&syncGHSSESaveDir, "save-dir", "", "Save webhook payloads to disk for debugging/replay")
+	syncGHSSEClientCmd.Flags().StringVar(&syncGHArchiveURL, "archive", "", "State store to archive payloads to (file, file:<dir>, r2:<remote>:<path>, nats:<bucket>)")
+	syncGHSSEClientCmd.Flags().Lookup("archive").NoOptDefVal = "file"
 	syncGHSSEClientCmd.Flags().StringVar(&syncGHSSEIgnoreEvents, "ignore-event", "", "Comma-separated event types to ignore (e.g., ping,status)")
 	syncGHSSEClientCmd.Flags().IntVar(&syncGHSSEHealthPort, "health-port", 0, "Port for health endpoint (0 = disabled)")
+	syncGHSSEClientCmd.Flags().BoolVar(&syncGHSSENoBuffer, "no-buffer", false, "Drop events the local handler does not accept instead of buffering them on disk")
 
 	syncGHServerCmd.Flags().StringVar(&syncGHServerPort, "port", "3333", "Server port")
 	syncGHServerCmd.Flags().StringVar(&syncGHServerPublicURL, "public-url", "", "Public URL for webhook configuration (optional)")
 	syncGHServerCmd.Flags().StringVar(&syncGHServerSecrets, "secrets", "", "Comma-separated webhook secrets for signature validation")
 
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHDeployTarget, "target", "fly", "Deploy target (fly, cloudrun)")
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHDeployApp, "app", "xplat-sse", "App (Fly) or service (Cloud Run) name")
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHDeployRegion, "region", "", "Deploy region (default: iad for Fly, us-central1 for Cloud Run)")
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHDeployProject, "project", "", "Google Cloud project ID (Cloud Run only)")
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHDeployVersion, "version", "latest", "xplat release to run in the container: v1.2.3 or the tag xplat-v1.2.3 (latest = newest release)")
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHServerSecrets, "secrets", "", "Comma-separated webhook secrets for signature validation")
+	syncGHServerDeployCmd.Flags().StringVar(&syncGHDeployBuildDir, "build-dir", "", "Directory for the generated build context (default: temp dir)")
+	syncGHServerDeployCmd.Flags().BoolVar(&syncGHDeployDryRun, "dry-run", false, "Write build context and print commands without deploying")
+	syncGHServerCmd.AddCommand(syncGHServerDeployCmd)
+
 	syncGHReplayCmd.Flags().BoolVar(&syncGHReplayListHooks, "list-hooks", false, "List webhooks on the repo/org")
 	syncGHReplayCmd.Flags().BoolVar(&syncGHReplayListDeliveries, "list-deliveries", false, "List recent deliveries for a hook")
 	syncGHReplayCmd.Flags().StringVar(&syncGHReplaySince, "since", "", "Replay deliveries since this time (format: 2006-01-02T15:04:05)")
 	syncGHReplayCmd.Flags().StringVar(&syncGHReplaySaveDir, "save-dir", "", "Save payloads to disk for debugging/replay")
 	syncGHReplayCmd.Flags().StringVar(&syncGHReplayIgnoreEvents, "ignore-event", "", "Comma-separated event types to ignore")
 	syncGHReplayCmd.Flags().BoolVar(&syncGHReplayContinuous, "continuous", false, "Keep watching for new deliveries")
+	syncGHReplayCmd.Flags().StringVar(&syncGHReplayEvents, "event", "", "Comma-separated event types to replay (e.g. push,release,workflow_run)")
+	syncGHReplayCmd.Flags().BoolVar(&syncGHReplayFailed, "failed", false, "Only replay deliveries that failed (non-2xx)")
+	syncGHReplayCmd.Flags().BoolVar(&syncGHReplayRedeliver, "redeliver", false, "Use GitHub's redelivery API instead of POSTing to a local target")
 
 	syncGHRelayCmd.Flags().StringVar(&syncGHWebhookPort, "port", config.DefaultWebhookPort, "Local webhook server port")
-
+	syncGHRelayCmd.Flags().StringVar(&syncGHRelayProvider, "provider", syncgh.ProviderCloudflared, "Tunnel provider: smee, sse, cloudflared")
+	syncGHRelayCmd.Flags().StringVar(&syncGHTunnelURL, "url", "", "smee.io channel or SSE server URL (smee/sse providers)")
+	syncGHRelayCmd.Flags().StringVar(&syncGHTunnelIgnoreEvents, "ignore-event", "", "Comma-separated event types to ignore (smee/sse providers)")
+	syncGHRelayCmd.Flags().BoolVar(&syncGHSSENoBuffer, "no-buffer", false, "Drop events the local handler does not accept instead of buffering them on disk (smee/sse providers)")
+
+	syncGHTunnelCmd.Flags().StringVar(&syncGHWebhookPort, "port", config.DefaultWebhookPort, "Local webhook server port")
+	syncGHTunnelCmd.Flags().StringVar(&syncGHTunnelProvider, "provider", syncgh.ProviderSmee, "Tunnel provider: smee, sse, cloudflared")
+	syncGHTunnelCmd.Flags().StringVar(&syncGHTunnelURL, "url", "", "smee.io channel or SSE server URL (smee/sse providers)")
+	syncGHTunnelCmd.Flags().StringVar(&syncGHTunnelIgnoreEvents, "ignore-event", "", "Comma-separated event types to ignore (smee/sse providers)")
+	syncGHTunnelCmd.Flags().BoolVar(&syncGHSSENoBuffer, "no-buffer", false, "Drop events the local handler does not accept instead of buffering them on disk (smee/sse providers)")
+
+	SyncGHCmd.AddCommand(syncGHArchiveCmd)
 	SyncGHCmd.AddCommand(syncGHDiscoverCmd)
 	SyncGHCmd.AddCommand(syncGHPollCmd)
 	SyncGHCmd.AddCommand(syncGHPollStateCmd)
+	SyncGHCmd.AddCommand(syncGHPolicyCmd)
 	SyncGHCmd.AddCommand(syncGHRelayCmd)
+	SyncGHCmd.AddCommand(syncGHTunnelCmd)
 	SyncGHCmd.AddCommand(syncGHReleaseCmd)
 	SyncGHCmd.AddCommand(syncGHReplayCmd)
 	SyncGHCmd.AddCommand(syncGHServerCmd)
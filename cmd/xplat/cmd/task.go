@@ -93,11 +93,24 @@ var TaskCmd = &cobra.Command{
 This provides the same functionality as the standalone 'task' binary,
 but bundled into xplat for simpler bootstrapping.
 
+Tasks can declare resource limits in an xplat: block, enforced for the
+whole run (cgroups on Linux, job objects on Windows, nice/ulimit on macOS):
+
+  tasks:
+    build:
+      xplat:
+        nice: 10       # 0-19
+        cpu: 2         # cores
+        memory: 4GiB
+      cmds:
+        - go build ./...
+
 Examples:
   xplat task build
   xplat task -t taskfiles/Taskfile.dummy.yml release:build
   xplat task --list
-  xplat task build -- --some-arg-for-task`,
+  xplat task build -- --some-arg-for-task
+  xplat task stats                  # Slowest tasks, trends, cache hits`,
 	DisableFlagParsing: true, // We parse flags ourselves to match Task exactly
 	RunE:               runTask,
 }
@@ -320,6 +333,9 @@ func runTask(cmd *cobra.Command, osArgs []string) error {
 		_ = os.Setenv("PATH", config.PathWithPlatBin(workDir))
 	}
 
+	// Decrypt .env.enc into the environment when there is no plaintext .env
+	applyEncryptedEnv(workDir)
+
 	// Enable remote taskfiles experiment by default in xplat
 	// This allows projects to include taskfiles from URLs
 	_ = os.Setenv("TASK_X_REMOTE_TASKFILES", "1")
@@ -421,6 +437,8 @@ func runTask(cmd *cobra.Command, osArgs []string) error {
 	}
 
 	// Setup the executor (loads Taskfile, validates, etc.)
+	// Timing starts here so remote Taskfile downloads count towards the run
+	runStart := time.Now()
 	if err := e.Setup(); err != nil {
 		return err
 	}
@@ -503,6 +521,19 @@ func runTask(cmd *cobra.Command, osArgs []string) error {
 		return e.Status(ctx, calls...)
 	}
 
+	// Enforce the tasks' xplat: resource limits (nice, cpu, memory)
+	if !taskDry && !taskSummary {
+		release, err := applyTaskLimits(e, calls)
+		if err != nil {
+			return err
+		}
+		defer release()
+	}
+
 	// Run the tasks
-	return e.Run(ctx, calls...)
+	err := e.Run(ctx, calls...)
+	if !taskWatch && !taskDry && !taskSummary {
+		recordTaskRun(e, calls, runStart, err)
+	}
+	return err
 }
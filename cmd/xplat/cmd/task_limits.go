@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-task/task/v3"
+
+	"github.com/joeblew999/xplat/internal/reslimit"
+)
+
+// applyTaskLimits enforces the xplat: resource limits declared by the called
+// tasks and the tasks they run (deps and task: commands, see
+// internal/reslimit). Limits apply to the whole run; with several tasks the
+// strictest of each limit wins.
+func applyTaskLimits(e *task.Executor, calls []*task.Call) (func(), error) {
+	limits, err := collectTaskLimits(e, calls)
+	if err != nil {
+		return func() {}, err
+	}
+	if limits.IsZero() {
+		return func() {}, nil
+	}
+
+	release, skipped, err := reslimit.Apply(limits)
+	if err != nil {
+		return release, fmt.Errorf("resource limits: %w", err)
+	}
+	if !taskSilent {
+		fmt.Fprintf(os.Stderr, "task: resource limits %s\n", limits)
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(os.Stderr, "task: warning: limit not enforced: %s\n", s)
+	}
+	return release, nil
+}
+
+// collectTaskLimits merges the limits of calls and, transitively, of the
+// tasks they run. Remote Taskfiles and tasks that cannot be resolved (such
+// as templated dep names) are skipped here, go-task reports them when running.
+func collectTaskLimits(e *task.Executor, calls []*task.Call) (reslimit.Limits, error) {
+	var limits reslimit.Limits
+	seen := make(map[string]bool)
+	for len(calls) > 0 {
+		call := calls[0]
+		calls = calls[1:]
+		if seen[call.Task] {
+			continue
+		}
+		seen[call.Task] = true
+
+		t, err := e.GetTask(call)
+		if err != nil || t.Location == nil || strings.Contains(t.Location.Taskfile, "://") {
+			continue
+		}
+		l, err := reslimit.FromTaskfile(t.Location.Taskfile, t.Location.Line)
+		if err != nil {
+			return limits, err
+		}
+		limits = limits.Merge(l)
+
+		for _, dep := range t.Deps {
+			calls = append(calls, &task.Call{Task: dep.Task, Vars: dep.Vars, Indirect: true})
+		}
+		for _, cmd := range t.Cmds {
+			if cmd.Task != "" {
+				calls = append(calls, &task.Call{Task: cmd.Task, Vars: cmd.Vars, Indirect: true})
+			}
+		}
+	}
+	return limits, nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-task/task/v3"
+
+	"github.com/joeblew999/xplat/internal/reslimit"
+)
+
+func TestCollectTaskLimitsIncludesDeps(t *testing.T) {
+	dir := t.TempDir()
+	taskfile := `version: '3'
+tasks:
+  build:
+    deps: [generate]
+    xplat:
+      nice: 5
+    cmds:
+      - task: compile
+  generate:
+    xplat:
+      memory: 1GiB
+    cmds:
+      - echo generate
+  compile:
+    xplat:
+      cpu: 2
+    cmds:
+      - task: build
+`
+	if err := os.WriteFile(filepath.Join(dir, "Taskfile.yml"), []byte(taskfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := task.NewExecutor(task.WithDir(dir), task.WithStdout(io.Discard), task.WithStderr(io.Discard))
+	if err := e.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := collectTaskLimits(e, []*task.Call{{Task: "build"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := reslimit.Limits{Nice: 5, CPU: 2, Memory: 1 << 30}
+	if got != want {
+		t.Errorf("limits = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-task/task/v3"
+	"github.com/go-task/task/v3/errors"
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/taskstats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	taskStatsWindow time.Duration
+	taskStatsLimit  int
+	taskStatsCI     bool
+	taskStatsLocal  bool
+	taskStatsJSON   bool
+	taskStatsClear  bool
+)
+
+// taskStatsCmd reports on recorded task runs.
+var taskStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show slowest tasks, trends and remote include cache hits",
+	Long: `Show where time goes in 'xplat task' runs.
+
+Every run is recorded locally in ~/.xplat/cache/taskstats/runs.jsonl
+(set XPLAT_TASK_STATS=0 to disable). The report lists tasks by total time
+with run count, failures, p50/p90/max duration and the trend (newer half
+of runs vs older half), plus the remote include cache hit rate.
+
+Examples:
+  xplat task stats
+  xplat task stats --window 168h --limit 10
+  xplat task stats --ci          # CI runs only
+  xplat task stats --json`,
+	RunE: runTaskStats,
+}
+
+func init() {
+	taskStatsCmd.Flags().DurationVar(&taskStatsWindow, "window", 30*24*time.Hour, "Only include runs within this window")
+	taskStatsCmd.Flags().IntVarP(&taskStatsLimit, "limit", "n", 20, "Maximum tasks to show (0 for all)")
+	taskStatsCmd.Flags().BoolVar(&taskStatsCI, "ci", false, "Only include CI runs")
+	taskStatsCmd.Flags().BoolVar(&taskStatsLocal, "local", false, "Only include local (non-CI) runs")
+	taskStatsCmd.Flags().BoolVar(&taskStatsJSON, "json", false, "Output summary as JSON")
+	taskStatsCmd.Flags().BoolVar(&taskStatsClear, "clear", false, "Delete recorded task runs")
+
+	TaskCmd.AddCommand(taskStatsCmd)
+}
+
+func runTaskStats(cmd *cobra.Command, args []string) error {
+	path := taskstats.Path()
+
+	if taskStatsClear {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear task stats: %w", err)
+		}
+		fmt.Printf("Cleared %s\n", path)
+		return nil
+	}
+
+	records, err := taskstats.Load(path, time.Now().Add(-taskStatsWindow))
+	if err != nil {
+		return err
+	}
+
+	filtered := records[:0]
+	for _, rec := range records {
+		if (taskStatsCI && !rec.CI) || (taskStatsLocal && rec.CI) {
+			continue
+		}
+		filtered = append(filtered, rec)
+	}
+
+	sum := taskstats.Compute(filtered)
+	if taskStatsJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(sum)
+	}
+
+	if sum.Runs == 0 {
+		fmt.Printf("No task runs recorded in the last %s (%s)\n", taskStatsWindow, path)
+		return nil
+	}
+	taskstats.Print(sum, taskStatsLimit)
+	return nil
+}
+
+// recordTaskRun appends a run to the task stats history.
+// Failures to record never affect the task result.
+func recordTaskRun(e *task.Executor, calls []*task.Call, start time.Time, runErr error) {
+	if !taskstats.Enabled() {
+		return
+	}
+	// A mistyped task name is not a run worth tracking
+	var notFound *errors.TaskNotFoundError
+	if stderrors.As(runErr, &notFound) {
+		return
+	}
+
+	names := make([]string, 0, len(calls))
+	for _, c := range calls {
+		names = append(names, c.Task)
+	}
+
+	rec := taskstats.Record{
+		Time:     start,
+		Tasks:    names,
+		Dir:      e.Dir,
+		Duration: time.Since(start),
+		OK:       runErr == nil,
+		CI:       config.IsCI(),
+	}
+	if runErr != nil {
+		rec.Error = runErr.Error()
+	}
+	rec.RemoteCached, rec.RemoteDownloaded = taskstats.RemoteCacheUse(e.Taskfile, e.TempDir.Remote, start)
+
+	_ = taskstats.Append(taskstats.Path(), rec)
+}
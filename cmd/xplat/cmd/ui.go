@@ -28,6 +28,7 @@ DEPRECATED: This command is deprecated. Use 'xplat up' instead for the unified U
 The UI provides:
   - List of all available tasks from Taskfile.yml
   - Click-to-run task execution with live output
+  - Taskfile include tree with the remote include cache state
   - Process-compose status view (if running)
 
 Examples:
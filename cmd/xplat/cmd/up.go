@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -10,14 +12,20 @@ import (
 )
 
 var (
-	upPort        string
-	upNoBrowser   bool
-	upTaskfile    string
-	upDir         string
-	upPCPort      int
-	upNoTasks     bool
-	upNoProcesses bool
-	upNoSetup     bool
+	upPort           string
+	upNoBrowser      bool
+	upTaskfile       string
+	upDir            string
+	upPCPort         int
+	upNoTasks        bool
+	upNoProcesses    bool
+	upNoSetup        bool
+	upMock           bool
+	upEnvReload      string
+	upAuthToken      string
+	upBasicAuth      string
+	upReadOnly       bool
+	upIdentityHeader string
 )
 
 // UpCmd starts the unified xplat web UI.
@@ -28,18 +36,69 @@ var UpCmd = &cobra.Command{
 
 This is the primary way to run xplat's web UI. It provides:
   - Dashboard: Overview of your project
-  - Tasks: Run Taskfile tasks with live output
+  - Tasks: Run Taskfile tasks with live output, in its terminal colors
+  - History: Past task runs with their output, searchable and re-runnable,
+    saved in ~/.xplat/history
+  - Schedules: Run tasks every N minutes or on a cron expression while the
+    UI is up, saved in ~/.xplat/schedules.yaml; runs land in History and
+    schedules whose last run failed are counted on the tab
+  - Includes: The Taskfile include tree, with the cache state of each
+    remote include and per-include cache invalidation
   - Processes: Monitor process-compose processes
-  - Setup: Configure environment and services
+  - Env: Edit .env, with the variables xplat.yaml requires that are missing;
+    secret values are masked and saves get the setup wizard's fast
+    (format) validation
+  - Setup: The environment setup wizard ('xplat setup wizard') under /setup
+  - Audit: Who ran tasks, restarted processes, edited env or changed
+    Cloudflare Pages, as a hash-chained log in
+    ~/.xplat/state/<project>/audit/ui.jsonl
+
+The ☾/☀ toggle in the nav switches between light and dark mode (default:
+the OS preference) and is remembered by the browser.
+
+With --env-reload, the UI watches .env and, when keys change, restarts (or
+sends SIGHUP to) the running processes that use them: processes whose
+command or environment references the key as $KEY / ${KEY}, and processes of
+the xplat.yaml manifest when it lists the key under env. Restart mode first has
+process-compose reload the project, so .env is re-read and processes whose
+config expands the key restart with the new value; a process that only
+inherits the key from process-compose's own environment keeps the old value
+until process-compose restarts. The processes page and desktop
+notifications show what was changed and what was reloaded.
+
+Caddy also exposes the UI on the LAN, so it can require a token
+(--auth-token or XPLAT_UI_TOKEN) or basic auth (--basic-auth user:password
+or XPLAT_UI_BASIC_AUTH). Browsers sign in once with the token and keep a
+session cookie; scripts send it as "Authorization: Bearer <token>". The
+browser xplat up opens is signed in already. --read-only hides task runs,
+process actions, .env values and the setup wizard, does not run schedules,
+and rejects POSTs to /api/. POSTs to
+/api/ from a browser must carry the session's CSRF token, which the UI's
+pages add.
+
+Actions are attributed to the basic auth user, else XPLAT_UI_USER (default:
+the OS user). Behind an authenticating proxy on the same host (Cloudflare
+Access, Caddy forward_auth), --identity-header names the header the proxy
+sets, e.g. Cf-Access-Authenticated-User-Email. Only set it when every route
+to the UI goes through that proxy: anything reaching Caddy or the UI
+directly can send the header itself.
 
 The UI is driven by your project's configuration (Taskfile.yml, process-compose.yaml).
 
+If Caddy is already running, the UI registers in the Caddy service registry
+under /xplat/ (https://localhost/xplat/setup for the setup wizard), next to
+the standalone wizard's /admin/ entry.
+
 Examples:
   xplat up                     # Start with all features on port 8760
   xplat up -p 9000             # Start on port 9000
   xplat up --no-browser        # Don't open browser (for service mode)
   xplat up --no-setup          # Disable setup wizard
-  xplat up -d /path/to/project # Use specific project directory`,
+  xplat up --mock              # Setup wizard in mock mode (no real API calls)
+  xplat up -d /path/to/project # Use specific project directory
+  xplat up --env-reload        # Restart processes affected by .env edits
+  xplat up --env-reload=sighup # Signal them to reload instead
+  XPLAT_UI_TOKEN=s3cret xplat up --read-only  # Safe to share on the LAN`,
 	RunE: runUp,
 }
 
@@ -52,6 +111,13 @@ func init() {
 	UpCmd.Flags().BoolVar(&upNoTasks, "no-tasks", false, "Disable task UI")
 	UpCmd.Flags().BoolVar(&upNoProcesses, "no-processes", false, "Disable process view")
 	UpCmd.Flags().BoolVar(&upNoSetup, "no-setup", false, "Disable setup wizard")
+	UpCmd.Flags().BoolVar(&upMock, "mock", false, "Run the setup wizard in mock mode (no real API calls)")
+	UpCmd.Flags().StringVar(&upEnvReload, "env-reload", "", "On .env changes, restart or sighup the affected processes")
+	UpCmd.Flags().Lookup("env-reload").NoOptDefVal = web.EnvReloadRestart
+	UpCmd.Flags().StringVar(&upAuthToken, "auth-token", "", "Require this token to use the UI (or XPLAT_UI_TOKEN)")
+	UpCmd.Flags().StringVar(&upBasicAuth, "basic-auth", "", "Require basic auth as user:password (or XPLAT_UI_BASIC_AUTH)")
+	UpCmd.Flags().BoolVar(&upReadOnly, "read-only", false, "Browse only: no task runs, process actions or setup wizard")
+	UpCmd.Flags().StringVar(&upIdentityHeader, "identity-header", "", "Trust this proxy header as the user in the audit log (or XPLAT_UI_IDENTITY_HEADER)")
 }
 
 func runUp(cmd *cobra.Command, args []string) error {
@@ -62,6 +128,27 @@ func runUp(cmd *cobra.Command, args []string) error {
 	cfg.EnableTasks = !upNoTasks
 	cfg.EnableProcesses = !upNoProcesses
 	cfg.EnableSetup = !upNoSetup
+	cfg.MockMode = upMock
+	cfg.AuthToken = upAuthToken
+	if cfg.AuthToken == "" {
+		cfg.AuthToken = os.Getenv("XPLAT_UI_TOKEN")
+	}
+	cfg.BasicAuth = upBasicAuth
+	if cfg.BasicAuth == "" {
+		cfg.BasicAuth = os.Getenv("XPLAT_UI_BASIC_AUTH")
+	}
+	cfg.ReadOnly = upReadOnly
+	cfg.IdentityHeader = upIdentityHeader
+	if cfg.IdentityHeader == "" {
+		cfg.IdentityHeader = os.Getenv("XPLAT_UI_IDENTITY_HEADER")
+	}
+
+	switch upEnvReload {
+	case "", web.EnvReloadRestart, web.EnvReloadSighup:
+		cfg.EnvReload = upEnvReload
+	default:
+		return fmt.Errorf("invalid --env-reload %q (use %s or %s)", upEnvReload, web.EnvReloadRestart, web.EnvReloadSighup)
+	}
 
 	if upTaskfile != "" {
 		cfg.Taskfile = upTaskfile
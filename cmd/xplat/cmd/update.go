@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/joeblew999/xplat/internal/platform"
 	"github.com/joeblew999/xplat/internal/updater"
 	"github.com/spf13/cobra"
 )
@@ -53,26 +54,35 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Current version: %s\n", currentVersion)
 	fmt.Printf("Latest version:  %s\n", latestVersion)
 
-	// Check if update needed
-	if !updateForce && currentVersion == latestVersion {
+	// Check if update needed; an emulated xplat (Rosetta 2, Windows x64
+	// emulation) reinstalls to switch to the native build
+	native := updater.NativeAvailable(release)
+	if !updateForce && currentVersion == latestVersion && !native {
 		fmt.Println("Already up to date.")
 		return nil
 	}
+	if native {
+		fmt.Printf("Running as %s, native build available.\n", platform.Current())
+	}
 
 	if updateCheck {
-		if currentVersion != latestVersion {
+		if currentVersion != latestVersion || native {
 			fmt.Println("\nUpdate available! Run 'xplat update' to install.")
 		}
 		return nil
 	}
 
 	// Find the right asset for this platform
-	downloadURL, err := updater.FindAssetURL(release)
+	assetName, downloadURL, err := updater.FindAsset(release)
 	if err != nil {
 		return err
 	}
+	if assetName != updater.GetAssetName() {
+		plat := platform.Current()
+		_, note := platform.Fallback(plat.OS, plat.Arch)
+		fmt.Printf("Note: %s\n", note)
+	}
 
-	assetName := updater.GetAssetName()
 	fmt.Printf("\nDownloading %s...\n", assetName)
 
 	newVersion, err := updater.Update(ctx, currentVersion, updateForce)
@@ -6,6 +6,7 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/joeblew999/xplat/internal/platform"
 	"github.com/joeblew999/xplat/internal/updater"
 	"github.com/spf13/cobra"
 )
@@ -48,7 +49,7 @@ func runVersion(cmd *cobra.Command, args []string) {
 
 	// Verbose output
 	fmt.Printf("xplat %s\n", version)
-	fmt.Printf("  Platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Printf("  Platform: %s\n", platform.Current())
 	fmt.Printf("  Go:       %s\n", runtime.Version())
 
 	// Check for updates
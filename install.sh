@@ -32,6 +32,15 @@ case "$ARCH" in
     *) echo "Unsupported architecture: $ARCH"; exit 1 ;;
 esac
 
+# A shell under Rosetta 2, or Git Bash under Windows x64 emulation, reports
+# amd64 on an ARM machine: install the native arm64 build
+if [ "$OS" = "darwin" ] && [ "$(sysctl -n sysctl.proc_translated 2>/dev/null)" = "1" ]; then
+    ARCH="arm64"
+fi
+if [ "$OS" = "windows" ] && [ "${PROCESSOR_ARCHITEW6432:-$PROCESSOR_ARCHITECTURE}" = "ARM64" ]; then
+    ARCH="arm64"
+fi
+
 # Windows needs .exe extension
 EXT=""
 if [ "$OS" = "windows" ]; then
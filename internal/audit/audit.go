@@ -0,0 +1,236 @@
+// Package audit keeps a tamper-evident, append-only log of actions.
+//
+// Entries are JSON lines. Each entry carries the hash of the entry before
+// it, and its own hash covers its fields plus that previous hash, so
+// editing, removing or reordering any entry breaks the chain from that
+// point on. Verify walks the chain and reports the first broken entry.
+//
+// The chain shows tampering; it does not prevent it. Ship the log (or its
+// last hash) somewhere else to make a rewrite of the whole file detectable.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// Outcomes of an action.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Entry is one audited action.
+type Entry struct {
+	Seq     int               `json:"seq"`
+	Time    time.Time         `json:"time"`
+	User    string            `json:"user"`
+	Action  string            `json:"action"` // e.g. task.run, process.stop, env.update
+	Target  string            `json:"target,omitempty"`
+	Outcome string            `json:"outcome"`
+	Error   string            `json:"error,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+	Prev    string            `json:"prev"`
+	Hash    string            `json:"hash"`
+}
+
+// computeHash returns the hash of the entry's fields and previous hash.
+func (e *Entry) computeHash() string {
+	c := *e
+	c.Hash = ""
+	data, _ := json.Marshal(&c) // Struct field order and sorted map keys keep this stable
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Log appends entries to a log file.
+type Log struct {
+	Path string
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// Open returns a log writing to path. The file is created on first Append.
+func Open(path string) *Log {
+	return &Log{Path: path, now: time.Now}
+}
+
+// Append records an action. It fills in Seq, Time (if unset), Prev and Hash.
+// Other processes appending to the same file are serialized with a lock file.
+func (l *Log) Append(e Entry) (*Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	unlock, err := statestore.LockFile(l.Path, statestore.DefaultLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	last, err := lastEntry(l.Path)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		e.Seq = last.Seq + 1
+		e.Prev = last.Hash
+	} else {
+		e.Seq = 1
+		e.Prev = ""
+	}
+	if e.Time.IsZero() {
+		e.Time = l.now().UTC()
+	}
+	if e.Outcome == "" {
+		e.Outcome = OutcomeOK
+	}
+	e.Hash = e.computeHash()
+
+	line, err := json.Marshal(&e)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.Path), config.DefaultDirPerms); err != nil {
+		return nil, fmt.Errorf("failed to create audit log dir: %w", err)
+	}
+	f, err := os.OpenFile(l.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return &e, nil
+}
+
+// Record appends an action with its outcome taken from err.
+func (l *Log) Record(user, action, target string, details map[string]string, err error) (*Entry, error) {
+	e := Entry{User: user, Action: action, Target: target, Details: details, Outcome: OutcomeOK}
+	if err != nil {
+		e.Outcome = OutcomeError
+		e.Error = err.Error()
+	}
+	return l.Append(e)
+}
+
+// lastEntry returns the last entry in the file, or nil if there is none.
+// Only the tail of the file is read, so appends don't slow down as the log
+// grows.
+func lastEntry(path string) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	const chunk = 64 * 1024
+	var tail, line []byte
+	for off := info.Size(); off > 0; {
+		n := min(int64(chunk), off)
+		off -= n
+		buf := make([]byte, n)
+		if _, err := f.ReadAt(buf, off); err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		tail = append(buf, tail...)
+
+		trimmed := bytes.TrimRight(tail, "\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			line = trimmed[i+1:]
+			break
+		}
+		if off == 0 {
+			line = trimmed
+		}
+	}
+	if len(line) == 0 {
+		return nil, nil
+	}
+
+	var e Entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, fmt.Errorf("audit log last line: %w", err)
+	}
+	return &e, nil
+}
+
+// Read returns every entry in the log, oldest first. A missing file is an
+// empty log.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", n, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// ErrTampered is returned by Verify when the hash chain is broken.
+var ErrTampered = errors.New("audit log hash chain is broken")
+
+// Verify checks the hash chain and returns the number of entries. On a
+// broken chain the error wraps ErrTampered and names the first bad entry.
+func Verify(path string) (int, error) {
+	entries, err := Read(path)
+	if err != nil {
+		return 0, err
+	}
+	prev := ""
+	for i, e := range entries {
+		switch {
+		case e.Seq != i+1:
+			return i, fmt.Errorf("%w: entry %d has seq %d", ErrTampered, i+1, e.Seq)
+		case e.Prev != prev:
+			return i, fmt.Errorf("%w: entry %d does not follow entry %d", ErrTampered, e.Seq, i)
+		case e.computeHash() != e.Hash:
+			return i, fmt.Errorf("%w: entry %d was modified", ErrTampered, e.Seq)
+		}
+		prev = e.Hash
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func writeLog(t *testing.T, n int) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit", "ui.jsonl")
+	log := Open(path)
+	for i := 0; i < n; i++ {
+		var err error
+		if i == 1 {
+			err = errors.New("exit status 1")
+		}
+		if _, logErr := log.Record("alice@example.com", "task.run", fmt.Sprintf("build-%d", i), nil, err); logErr != nil {
+			t.Fatal(logErr)
+		}
+	}
+	return path
+}
+
+func TestAppendChain(t *testing.T) {
+	path := writeLog(t, 3)
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	prev := ""
+	for i, e := range entries {
+		if e.Seq != i+1 || e.Prev != prev || e.Hash == "" {
+			t.Errorf("entry %d: seq %d prev %q hash %q", i, e.Seq, e.Prev, e.Hash)
+		}
+		prev = e.Hash
+	}
+	if entries[1].Outcome != OutcomeError || entries[1].Error != "exit status 1" {
+		t.Errorf("entry 2 = %+v, want error outcome", entries[1])
+	}
+	if entries[0].Outcome != OutcomeOK {
+		t.Errorf("entry 1 outcome = %q, want ok", entries[0].Outcome)
+	}
+
+	n, err := Verify(path)
+	if err != nil || n != 3 {
+		t.Errorf("Verify = %d, %v; want 3, nil", n, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines [][]byte) [][]byte
+	}{
+		{"modified", func(lines [][]byte) [][]byte {
+			lines[1] = bytes.Replace(lines[1], []byte("alice@example.com"), []byte("bob@example.com"), 1)
+			return lines
+		}},
+		{"removed", func(lines [][]byte) [][]byte {
+			return append(lines[:1], lines[2:]...)
+		}},
+		{"reordered", func(lines [][]byte) [][]byte {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeLog(t, 4)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+			lines = tt.tamper(lines)
+			if err := os.WriteFile(path, append(bytes.Join(lines, []byte("\n")), '\n'), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Verify(path)
+			if !errors.Is(err, ErrTampered) {
+				t.Errorf("Verify = %v, want ErrTampered", err)
+			}
+		})
+	}
+}
+
+func TestVerifyTruncatedTail(t *testing.T) {
+	// Dropping the newest entries keeps a valid chain; only an external copy
+	// of the last hash catches that, as the package doc says.
+	path := writeLog(t, 3)
+	entries, _ := Read(path)
+	data, _ := os.ReadFile(path)
+	lines := strings.SplitAfter(string(data), "\n")
+	if err := os.WriteFile(path, []byte(lines[0]+lines[1]), 0600); err != nil {
+		t.Fatal(err)
+	}
+	n, err := Verify(path)
+	if err != nil || n != 2 {
+		t.Errorf("Verify = %d, %v; want 2, nil", n, err)
+	}
+	if got, _ := Read(path); got[1].Hash != entries[1].Hash {
+		t.Error("remaining entries changed")
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	entries, err := Read(filepath.Join(t.TempDir(), "none.jsonl"))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Read = %v, %v; want empty", entries, err)
+	}
+}
+
+func TestConcurrentAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ui.jsonl")
+
+	// Two Logs on one file stand in for two processes
+	logs := []*Log{Open(path), Open(path)}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := logs[i%2].Record("local", "process.restart", "api", nil, nil); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	n, err := Verify(path)
+	if err != nil || n != 20 {
+		t.Errorf("Verify = %d, %v; want 20, nil", n, err)
+	}
+}
+
+func TestAppendAfterLargeEntry(t *testing.T) {
+	// The previous entry is found from the file's tail, also when it is
+	// longer than one read chunk.
+	path := writeLog(t, 2)
+	log := Open(path)
+	big := map[string]string{"keys": strings.Repeat("K", 150*1024)}
+	if _, err := log.Record("alice@example.com", "env.update", ".env", big, nil); err != nil {
+		t.Fatal(err)
+	}
+	e, err := log.Record("alice@example.com", "task.run", "build", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Seq != 4 {
+		t.Errorf("seq = %d, want 4", e.Seq)
+	}
+	if n, err := Verify(path); err != nil || n != 4 {
+		t.Errorf("Verify = %d, %v; want 4, nil", n, err)
+	}
+}
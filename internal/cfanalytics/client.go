@@ -0,0 +1,598 @@
+// Package cfanalytics is a typed client for the Cloudflare GraphQL
+// Analytics API: Web Analytics (RUM), zone HTTP traffic, Workers and R2.
+//
+//	a, err := cfanalytics.New(accountID, token)
+//	views, err := a.FetchRUM(ctx, cfanalytics.LastDays(7), siteTag)
+//	days, err := a.FetchZoneHTTP(ctx, cfanalytics.LastDays(7), zoneID)
+//
+// LoadConfig reads the account, token, site tag and zone from the
+// environment or .env, the same way the sync-cf commands do.
+package cfanalytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the Cloudflare GraphQL Analytics API.
+const DefaultEndpoint = "https://api.cloudflare.com/client/v4/graphql"
+
+// Analytics defaults.
+const (
+	DefaultPageSize   = 1000 // Rows per GraphQL query (Cloudflare caps most datasets at 10000)
+	DefaultMaxRetries = 3
+	DefaultRetryDelay = time.Second
+
+	// minWindow is the narrowest time range paginate splits down to.
+	minWindow = time.Minute
+)
+
+// Client queries the Cloudflare GraphQL Analytics API.
+//
+// The API has no cursors: a query returns at most PageSize rows. When a
+// query comes back full, the client splits the time range in half and
+// queries each half, then merges rows that share the same dimensions.
+type Client struct {
+	AccountID  string
+	PageSize   int
+	MaxRetries int           // Retries on 429, 5xx and GraphQL rate-limit errors
+	RetryDelay time.Duration // Doubled on each retry
+
+	apiToken   string
+	endpoint   string
+	httpClient *http.Client
+}
+
+// Range is a half-open time range [Since, Until).
+type Range struct {
+	Since time.Time
+	Until time.Time
+}
+
+// LastDays returns the range covering the n days before now.
+func LastDays(n int) Range {
+	until := time.Now().UTC()
+	return Range{Since: until.AddDate(0, 0, -n), Until: until}
+}
+
+// RUMStat is page views and visits for one site, day and path (Web Analytics).
+type RUMStat struct {
+	SiteTag   string `json:"site_tag"`
+	Date      string `json:"date"` // YYYY-MM-DD
+	Host      string `json:"host"`
+	Path      string `json:"path"`
+	PageViews int64  `json:"page_views"`
+	Visits    int64  `json:"visits"`
+}
+
+// WorkerStat is invocation counts for one Worker script and day.
+type WorkerStat struct {
+	Script      string `json:"script"`
+	Date        string `json:"date"`
+	Requests    int64  `json:"requests"`
+	Errors      int64  `json:"errors"`
+	Subrequests int64  `json:"subrequests"`
+}
+
+// R2OperationStat is request counts for one bucket, operation and day.
+type R2OperationStat struct {
+	Bucket   string `json:"bucket"`
+	Action   string `json:"action"` // e.g. PutObject, GetObject
+	Date     string `json:"date"`
+	Requests int64  `json:"requests"`
+}
+
+// R2StorageStat is the latest stored size of one bucket.
+type R2StorageStat struct {
+	Bucket        string `json:"bucket"`
+	Objects       int64  `json:"objects"`
+	PayloadBytes  int64  `json:"payload_bytes"`
+	MetadataBytes int64  `json:"metadata_bytes"`
+}
+
+// ZoneHTTPStat is HTTP traffic for one zone and day.
+type ZoneHTTPStat struct {
+	Date           string `json:"date"`
+	Requests       int64  `json:"requests"`
+	CachedRequests int64  `json:"cached_requests"`
+	Bytes          int64  `json:"bytes"`
+	CachedBytes    int64  `json:"cached_bytes"`
+	Threats        int64  `json:"threats"`
+	PageViews      int64  `json:"page_views"`
+	Uniques        int64  `json:"uniques"` // Unique visitor IPs
+}
+
+// New creates an analytics client for an account.
+// The token needs the Account Analytics read permission.
+func New(accountID, apiToken string) (*Client, error) {
+	if apiToken == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+	if accountID == "" {
+		return nil, fmt.Errorf("account ID is required")
+	}
+	return &Client{
+		AccountID:  accountID,
+		PageSize:   DefaultPageSize,
+		MaxRetries: DefaultMaxRetries,
+		RetryDelay: DefaultRetryDelay,
+		apiToken:   apiToken,
+		endpoint:   DefaultEndpoint,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// SetEndpoint points the client at another GraphQL endpoint (tests).
+func (a *Client) SetEndpoint(endpoint string) {
+	a.endpoint = endpoint
+}
+
+// graphQLError is one entry of a GraphQL "errors" array.
+type graphQLError struct {
+	Message string `json:"message"`
+}
+
+// Query runs a GraphQL query and decodes the "data" object into out.
+// Transient failures are retried with exponential backoff.
+func (a *Client) Query(ctx context.Context, query string, vars map[string]interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("encode query: %w", err)
+	}
+
+	delay := a.RetryDelay
+	for attempt := 0; ; attempt++ {
+		data, retry, err := a.do(ctx, body)
+		if err == nil {
+			if err := json.Unmarshal(data, out); err != nil {
+				return fmt.Errorf("decode data: %w", err)
+			}
+			return nil
+		}
+		if !retry || attempt >= a.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// do sends one GraphQL request. It returns the raw "data" object, or an
+// error and whether the request is worth retrying.
+func (a *Client) do(ctx context.Context, body []byte) (json.RawMessage, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+a.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return nil, retry, fmt.Errorf("GraphQL API error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var gqlResp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+		return nil, false, fmt.Errorf("decode response: %w", err)
+	}
+	if len(gqlResp.Errors) > 0 {
+		msgs := make([]string, len(gqlResp.Errors))
+		retry := false
+		for i, e := range gqlResp.Errors {
+			msgs[i] = e.Message
+			if strings.Contains(strings.ToLower(e.Message), "rate limit") {
+				retry = true
+			}
+		}
+		return nil, retry, fmt.Errorf("GraphQL error: %s", strings.Join(msgs, "; "))
+	}
+	return gqlResp.Data, false, nil
+}
+
+// rangeVars returns the common query variables for an account and range.
+func (a *Client) rangeVars(r Range) map[string]interface{} {
+	return map[string]interface{}{
+		"accountTag": a.AccountID,
+		"since":      r.Since.UTC().Format(time.RFC3339),
+		"until":      r.Until.UTC().Format(time.RFC3339),
+		"limit":      a.PageSize,
+	}
+}
+
+const rumQuery = `query RUM($accountTag: string!, $since: Time!, $until: Time!, $limit: uint64!, $siteTag: string) {
+  viewer {
+    accounts(filter: {accountTag: $accountTag}) {
+      rumPageloadEventsAdaptiveGroups(limit: $limit, filter: {datetime_geq: $since, datetime_lt: $until, siteTag: $siteTag}) {
+        count
+        sum { visits }
+        dimensions { siteTag date requestHost requestPath }
+      }
+    }
+  }
+}`
+
+// FetchRUM returns Web Analytics page views per site, day and path.
+// An empty siteTag returns every site in the account.
+func (a *Client) FetchRUM(ctx context.Context, r Range, siteTag string) ([]RUMStat, error) {
+	rows, err := paginate(ctx, a, r, func(ctx context.Context, r Range) ([]RUMStat, error) {
+		vars := a.rangeVars(r)
+		if siteTag != "" {
+			vars["siteTag"] = siteTag
+		}
+		var data struct {
+			Viewer struct {
+				Accounts []struct {
+					Groups []struct {
+						Count      int64                  `json:"count"`
+						Sum        struct{ Visits int64 } `json:"sum"`
+						Dimensions struct {
+							SiteTag     string `json:"siteTag"`
+							Date        string `json:"date"`
+							RequestHost string `json:"requestHost"`
+							RequestPath string `json:"requestPath"`
+						} `json:"dimensions"`
+					} `json:"rumPageloadEventsAdaptiveGroups"`
+				} `json:"accounts"`
+			} `json:"viewer"`
+		}
+		if err := a.Query(ctx, rumQuery, vars, &data); err != nil {
+			return nil, fmt.Errorf("RUM query: %w", err)
+		}
+		var rows []RUMStat
+		for _, acc := range data.Viewer.Accounts {
+			for _, g := range acc.Groups {
+				rows = append(rows, RUMStat{
+					SiteTag:   g.Dimensions.SiteTag,
+					Date:      g.Dimensions.Date,
+					Host:      g.Dimensions.RequestHost,
+					Path:      g.Dimensions.RequestPath,
+					PageViews: g.Count,
+					Visits:    g.Sum.Visits,
+				})
+			}
+		}
+		return rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows = mergeRows(rows,
+		func(s RUMStat) string { return s.SiteTag + "|" + s.Date + "|" + s.Host + "|" + s.Path },
+		func(into *RUMStat, s RUMStat) { into.PageViews += s.PageViews; into.Visits += s.Visits })
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Date != rows[j].Date {
+			return rows[i].Date < rows[j].Date
+		}
+		return rows[i].PageViews > rows[j].PageViews
+	})
+	return rows, nil
+}
+
+const workersQuery = `query Workers($accountTag: string!, $since: Time!, $until: Time!, $limit: uint64!, $scriptName: string) {
+  viewer {
+    accounts(filter: {accountTag: $accountTag}) {
+      workersInvocationsAdaptive(limit: $limit, filter: {datetime_geq: $since, datetime_lt: $until, scriptName: $scriptName}) {
+        sum { requests errors subrequests }
+        dimensions { scriptName date }
+      }
+    }
+  }
+}`
+
+// FetchWorkers returns Worker invocations per script and day.
+// An empty script returns every Worker in the account.
+func (a *Client) FetchWorkers(ctx context.Context, r Range, script string) ([]WorkerStat, error) {
+	rows, err := paginate(ctx, a, r, func(ctx context.Context, r Range) ([]WorkerStat, error) {
+		vars := a.rangeVars(r)
+		if script != "" {
+			vars["scriptName"] = script
+		}
+		var data struct {
+			Viewer struct {
+				Accounts []struct {
+					Groups []struct {
+						Sum struct {
+							Requests    int64 `json:"requests"`
+							Errors      int64 `json:"errors"`
+							Subrequests int64 `json:"subrequests"`
+						} `json:"sum"`
+						Dimensions struct {
+							ScriptName string `json:"scriptName"`
+							Date       string `json:"date"`
+						} `json:"dimensions"`
+					} `json:"workersInvocationsAdaptive"`
+				} `json:"accounts"`
+			} `json:"viewer"`
+		}
+		if err := a.Query(ctx, workersQuery, vars, &data); err != nil {
+			return nil, fmt.Errorf("Workers query: %w", err)
+		}
+		var rows []WorkerStat
+		for _, acc := range data.Viewer.Accounts {
+			for _, g := range acc.Groups {
+				rows = append(rows, WorkerStat{
+					Script:      g.Dimensions.ScriptName,
+					Date:        g.Dimensions.Date,
+					Requests:    g.Sum.Requests,
+					Errors:      g.Sum.Errors,
+					Subrequests: g.Sum.Subrequests,
+				})
+			}
+		}
+		return rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows = mergeRows(rows,
+		func(s WorkerStat) string { return s.Script + "|" + s.Date },
+		func(into *WorkerStat, s WorkerStat) {
+			into.Requests += s.Requests
+			into.Errors += s.Errors
+			into.Subrequests += s.Subrequests
+		})
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Date != rows[j].Date {
+			return rows[i].Date < rows[j].Date
+		}
+		return rows[i].Script < rows[j].Script
+	})
+	return rows, nil
+}
+
+const r2OperationsQuery = `query R2Operations($accountTag: string!, $since: Time!, $until: Time!, $limit: uint64!) {
+  viewer {
+    accounts(filter: {accountTag: $accountTag}) {
+      r2OperationsAdaptiveGroups(limit: $limit, filter: {datetime_geq: $since, datetime_lt: $until}) {
+        sum { requests }
+        dimensions { bucketName actionType date }
+      }
+    }
+  }
+}`
+
+// FetchR2Operations returns R2 requests per bucket, operation and day.
+func (a *Client) FetchR2Operations(ctx context.Context, r Range) ([]R2OperationStat, error) {
+	rows, err := paginate(ctx, a, r, func(ctx context.Context, r Range) ([]R2OperationStat, error) {
+		var data struct {
+			Viewer struct {
+				Accounts []struct {
+					Groups []struct {
+						Sum        struct{ Requests int64 } `json:"sum"`
+						Dimensions struct {
+							BucketName string `json:"bucketName"`
+							ActionType string `json:"actionType"`
+							Date       string `json:"date"`
+						} `json:"dimensions"`
+					} `json:"r2OperationsAdaptiveGroups"`
+				} `json:"accounts"`
+			} `json:"viewer"`
+		}
+		if err := a.Query(ctx, r2OperationsQuery, a.rangeVars(r), &data); err != nil {
+			return nil, fmt.Errorf("R2 operations query: %w", err)
+		}
+		var rows []R2OperationStat
+		for _, acc := range data.Viewer.Accounts {
+			for _, g := range acc.Groups {
+				rows = append(rows, R2OperationStat{
+					Bucket:   g.Dimensions.BucketName,
+					Action:   g.Dimensions.ActionType,
+					Date:     g.Dimensions.Date,
+					Requests: g.Sum.Requests,
+				})
+			}
+		}
+		return rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows = mergeRows(rows,
+		func(s R2OperationStat) string { return s.Bucket + "|" + s.Action + "|" + s.Date },
+		func(into *R2OperationStat, s R2OperationStat) { into.Requests += s.Requests })
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Date != rows[j].Date {
+			return rows[i].Date < rows[j].Date
+		}
+		if rows[i].Bucket != rows[j].Bucket {
+			return rows[i].Bucket < rows[j].Bucket
+		}
+		return rows[i].Action < rows[j].Action
+	})
+	return rows, nil
+}
+
+const r2StorageQuery = `query R2Storage($accountTag: string!, $since: Time!, $until: Time!, $limit: uint64!) {
+  viewer {
+    accounts(filter: {accountTag: $accountTag}) {
+      r2StorageAdaptiveGroups(limit: $limit, filter: {datetime_geq: $since, datetime_lt: $until}) {
+        max { objectCount payloadSize metadataSize }
+        dimensions { bucketName }
+      }
+    }
+  }
+}`
+
+// FetchR2Storage returns the largest stored size of each bucket in the range.
+func (a *Client) FetchR2Storage(ctx context.Context, r Range) ([]R2StorageStat, error) {
+	var data struct {
+		Viewer struct {
+			Accounts []struct {
+				Groups []struct {
+					Max struct {
+						ObjectCount  int64 `json:"objectCount"`
+						PayloadSize  int64 `json:"payloadSize"`
+						MetadataSize int64 `json:"metadataSize"`
+					} `json:"max"`
+					Dimensions struct {
+						BucketName string `json:"bucketName"`
+					} `json:"dimensions"`
+				} `json:"r2StorageAdaptiveGroups"`
+			} `json:"accounts"`
+		} `json:"viewer"`
+	}
+	if err := a.Query(ctx, r2StorageQuery, a.rangeVars(r), &data); err != nil {
+		return nil, fmt.Errorf("R2 storage query: %w", err)
+	}
+
+	var rows []R2StorageStat
+	for _, acc := range data.Viewer.Accounts {
+		for _, g := range acc.Groups {
+			rows = append(rows, R2StorageStat{
+				Bucket:        g.Dimensions.BucketName,
+				Objects:       g.Max.ObjectCount,
+				PayloadBytes:  g.Max.PayloadSize,
+				MetadataBytes: g.Max.MetadataSize,
+			})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Bucket < rows[j].Bucket })
+	return rows, nil
+}
+
+const zoneHTTPQuery = `query ZoneHTTP($zoneTag: string!, $since: Date!, $until: Date!, $limit: uint64!) {
+  viewer {
+    zones(filter: {zoneTag: $zoneTag}) {
+      httpRequests1dGroups(limit: $limit, filter: {date_geq: $since, date_lt: $until}) {
+        sum { requests cachedRequests bytes cachedBytes threats pageViews }
+        uniq { uniques }
+        dimensions { date }
+      }
+    }
+  }
+}`
+
+// FetchZoneHTTP returns daily HTTP traffic for a zone. The dataset is
+// rolled up per day, so a range ending part-way through a day includes
+// that whole day.
+func (a *Client) FetchZoneHTTP(ctx context.Context, r Range, zoneID string) ([]ZoneHTTPStat, error) {
+	if zoneID == "" {
+		return nil, fmt.Errorf("zone ID is required")
+	}
+	since := r.Since.UTC().Truncate(24 * time.Hour)
+	until := r.Until.UTC()
+	if t := until.Truncate(24 * time.Hour); !t.Equal(until) {
+		until = t.AddDate(0, 0, 1)
+	}
+	vars := map[string]interface{}{
+		"zoneTag": zoneID,
+		"since":   since.Format(time.DateOnly),
+		"until":   until.Format(time.DateOnly),
+		"limit":   a.PageSize,
+	}
+
+	var data struct {
+		Viewer struct {
+			Zones []struct {
+				Groups []struct {
+					Sum struct {
+						Requests       int64 `json:"requests"`
+						CachedRequests int64 `json:"cachedRequests"`
+						Bytes          int64 `json:"bytes"`
+						CachedBytes    int64 `json:"cachedBytes"`
+						Threats        int64 `json:"threats"`
+						PageViews      int64 `json:"pageViews"`
+					} `json:"sum"`
+					Uniq struct {
+						Uniques int64 `json:"uniques"`
+					} `json:"uniq"`
+					Dimensions struct {
+						Date string `json:"date"`
+					} `json:"dimensions"`
+				} `json:"httpRequests1dGroups"`
+			} `json:"zones"`
+		} `json:"viewer"`
+	}
+	if err := a.Query(ctx, zoneHTTPQuery, vars, &data); err != nil {
+		return nil, fmt.Errorf("zone HTTP query: %w", err)
+	}
+
+	var rows []ZoneHTTPStat
+	for _, z := range data.Viewer.Zones {
+		for _, g := range z.Groups {
+			rows = append(rows, ZoneHTTPStat{
+				Date:           g.Dimensions.Date,
+				Requests:       g.Sum.Requests,
+				CachedRequests: g.Sum.CachedRequests,
+				Bytes:          g.Sum.Bytes,
+				CachedBytes:    g.Sum.CachedBytes,
+				Threats:        g.Sum.Threats,
+				PageViews:      g.Sum.PageViews,
+				Uniques:        g.Uniq.Uniques,
+			})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Date < rows[j].Date })
+	return rows, nil
+}
+
+// paginate runs fetch over r. A full page means rows may be missing, so the
+// range is split in half and each half is fetched the same way.
+func paginate[T any](ctx context.Context, a *Client, r Range, fetch func(context.Context, Range) ([]T, error)) ([]T, error) {
+	rows, err := fetch(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) < a.PageSize {
+		return rows, nil
+	}
+
+	span := r.Until.Sub(r.Since)
+	if span <= minWindow {
+		return nil, fmt.Errorf("more than %d rows between %s and %s", a.PageSize, r.Since.Format(time.RFC3339), r.Until.Format(time.RFC3339))
+	}
+	mid := r.Since.Add(span / 2).Truncate(time.Second)
+
+	first, err := paginate(ctx, a, Range{Since: r.Since, Until: mid}, fetch)
+	if err != nil {
+		return nil, err
+	}
+	second, err := paginate(ctx, a, Range{Since: mid, Until: r.Until}, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return append(first, second...), nil
+}
+
+// mergeRows combines rows with the same key, which appear when a range was
+// split across a day boundary.
+func mergeRows[T any](rows []T, key func(T) string, add func(*T, T)) []T {
+	index := make(map[string]int, len(rows))
+	merged := make([]T, 0, len(rows))
+	for _, row := range rows {
+		k := key(row)
+		if i, ok := index[k]; ok {
+			add(&merged[i], row)
+			continue
+		}
+		index[k] = len(merged)
+		merged = append(merged, row)
+	}
+	return merged
+}
@@ -0,0 +1,175 @@
+package cfanalytics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	a, err := New("acc", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetEndpoint(srv.URL)
+	a.RetryDelay = time.Millisecond
+	return a
+}
+
+// graphQLRequest decodes a test request body.
+func graphQLRequest(t *testing.T, r *http.Request) (string, map[string]interface{}) {
+	t.Helper()
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Fatal(err)
+	}
+	return req.Query, req.Variables
+}
+
+func TestQueryRetries(t *testing.T) {
+	var calls int32
+	a := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"rate limiter budget depleted, try again later"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"ok":true}}`))
+		}
+	})
+
+	var out struct{ OK bool }
+	if err := a.Query(context.Background(), "{ ok }", nil, &out); err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if !out.OK || calls != 3 {
+		t.Errorf("out = %+v, calls = %d", out, calls)
+	}
+
+	// Query errors are not retried
+	calls = 0
+	a = newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"data":null,"errors":[{"message":"unknown field foo"}]}`))
+	})
+	if err := a.Query(context.Background(), "{ foo }", nil, &out); err == nil || !strings.Contains(err.Error(), "unknown field foo") {
+		t.Errorf("Query() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestFetchWorkersPaginates(t *testing.T) {
+	r := Range{
+		Since: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC),
+	}
+
+	var ranges []string
+	a := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		query, vars := graphQLRequest(t, req)
+		if !strings.Contains(query, "workersInvocationsAdaptive") || vars["accountTag"] != "acc" {
+			t.Errorf("unexpected query %s %v", query, vars)
+		}
+		since, until := vars["since"].(string), vars["until"].(string)
+		ranges = append(ranges, since+"/"+until)
+
+		// The whole range overflows the page; each day fits
+		groups := []map[string]interface{}{
+			{"sum": map[string]int{"requests": 10, "errors": 1}, "dimensions": map[string]string{"scriptName": "api", "date": since[:10]}},
+			{"sum": map[string]int{"requests": 5}, "dimensions": map[string]string{"scriptName": "sync", "date": since[:10]}},
+		}
+		if since == "2026-10-01T00:00:00Z" && until == "2026-10-03T00:00:00Z" {
+			groups = append(groups, groups...)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"viewer": map[string]interface{}{"accounts": []interface{}{
+				map[string]interface{}{"workersInvocationsAdaptive": groups},
+			}}},
+		})
+	})
+	a.PageSize = 3
+
+	stats, err := a.FetchWorkers(context.Background(), r, "")
+	if err != nil {
+		t.Fatalf("FetchWorkers() error = %v", err)
+	}
+	if len(ranges) != 3 {
+		t.Errorf("queried ranges = %v", ranges)
+	}
+	want := []WorkerStat{
+		{Script: "api", Date: "2026-10-01", Requests: 10, Errors: 1},
+		{Script: "sync", Date: "2026-10-01", Requests: 5},
+		{Script: "api", Date: "2026-10-02", Requests: 10, Errors: 1},
+		{Script: "sync", Date: "2026-10-02", Requests: 5},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("stats = %+v", stats)
+	}
+	for i := range want {
+		if stats[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, stats[i], want[i])
+		}
+	}
+}
+
+func TestMergeRows(t *testing.T) {
+	rows := []RUMStat{
+		{Date: "2026-10-01", Path: "/", PageViews: 3, Visits: 2},
+		{Date: "2026-10-01", Path: "/docs", PageViews: 1, Visits: 1},
+		{Date: "2026-10-01", Path: "/", PageViews: 4, Visits: 1},
+	}
+	merged := mergeRows(rows,
+		func(s RUMStat) string { return s.Date + s.Path },
+		func(into *RUMStat, s RUMStat) { into.PageViews += s.PageViews; into.Visits += s.Visits })
+	if len(merged) != 2 || merged[0].PageViews != 7 || merged[0].Visits != 3 {
+		t.Errorf("merged = %+v", merged)
+	}
+}
+
+func TestFetchZoneHTTP(t *testing.T) {
+	a := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		query, vars := graphQLRequest(t, req)
+		if !strings.Contains(query, "httpRequests1dGroups") || vars["zoneTag"] != "zone" {
+			t.Errorf("unexpected query %s %v", query, vars)
+		}
+		// A range ending mid-day includes that day
+		if vars["since"] != "2026-10-14" || vars["until"] != "2026-10-17" {
+			t.Errorf("dates = %v..%v", vars["since"], vars["until"])
+		}
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"zones":[{"httpRequests1dGroups":[
+			{"sum":{"requests":200,"cachedRequests":150,"bytes":4096},"uniq":{"uniques":12},"dimensions":{"date":"2026-10-16"}},
+			{"sum":{"requests":100},"uniq":{"uniques":5},"dimensions":{"date":"2026-10-15"}}
+		]}]}}}`))
+	})
+
+	r := Range{
+		Since: time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC),
+	}
+	days, err := a.FetchZoneHTTP(context.Background(), r, "zone")
+	if err != nil {
+		t.Fatalf("FetchZoneHTTP() error = %v", err)
+	}
+	if len(days) != 2 || days[0].Date != "2026-10-15" || days[1].CachedRequests != 150 || days[1].Uniques != 12 {
+		t.Errorf("days = %+v", days)
+	}
+
+	if _, err := a.FetchZoneHTTP(context.Background(), r, ""); err == nil {
+		t.Error("FetchZoneHTTP without a zone should fail")
+	}
+}
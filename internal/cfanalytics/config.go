@@ -0,0 +1,70 @@
+package cfanalytics
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joeblew999/xplat/internal/env"
+)
+
+// Environment variables read by LoadConfig, on top of the Cloudflare keys
+// in internal/env.
+const (
+	// EnvAPIToken is where the analytics-read token preset is saved.
+	EnvAPIToken = "CF_ANALYTICS_API_TOKEN"
+	// EnvSiteTag is the Web Analytics site tag of the site to report on.
+	EnvSiteTag = "CF_WEB_ANALYTICS_SITE_TAG"
+)
+
+// Config is what a report needs. Empty SiteTag and ZoneID skip the
+// Web Analytics and zone sections.
+type Config struct {
+	AccountID string `json:"account_id"`
+	APIToken  string `json:"-"`
+	SiteTag   string `json:"site_tag,omitempty"`
+	ZoneID    string `json:"zone_id,omitempty"`
+	Endpoint  string `json:"-"` // GraphQL endpoint (default: DefaultEndpoint)
+}
+
+// LoadConfig reads the config from the environment, falling back to .env
+// (or .env.enc). In each, the token is the first of CF_ANALYTICS_API_TOKEN,
+// CF_API_TOKEN and CLOUDFLARE_API_TOKEN that is set, so a dedicated
+// analytics-read token wins over the general one.
+func LoadConfig() (*Config, error) {
+	file, err := env.LoadEnvValues()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+	lookup := func(keys ...string) string {
+		for _, k := range keys {
+			if v := os.Getenv(k); v != "" {
+				return v
+			}
+		}
+		for _, k := range keys {
+			if v := file[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	return &Config{
+		AccountID: lookup("CF_ACCOUNT_ID", env.KeyCloudflareAccountID),
+		APIToken:  lookup(EnvAPIToken, "CF_API_TOKEN", env.KeyCloudflareAPIToken),
+		SiteTag:   lookup(EnvSiteTag),
+		ZoneID:    lookup(env.KeyCloudflareZoneID),
+	}, nil
+}
+
+// NewClient creates a client from the config.
+func (c *Config) NewClient() (*Client, error) {
+	a, err := New(c.AccountID, c.APIToken)
+	if err != nil {
+		return nil, err
+	}
+	if c.Endpoint != "" {
+		a.SetEndpoint(c.Endpoint)
+	}
+	return a, nil
+}
@@ -0,0 +1,38 @@
+package cfanalytics
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeblew999/xplat/internal/env"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "CLOUDFLARE_ACCOUNT_ID=acc\nCLOUDFLARE_API_TOKEN=general\nCF_ANALYTICS_API_TOKEN=analytics\nCLOUDFLARE_ZONE_ID=zone\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env.SetEnvFileForTesting(path)
+	t.Cleanup(env.ResetEnvFile)
+	for _, k := range []string{"CF_ACCOUNT_ID", "CF_API_TOKEN", env.KeyCloudflareAccountID, env.KeyCloudflareAPIToken, env.KeyCloudflareZoneID, EnvAPIToken} {
+		t.Setenv(k, "")
+	}
+	t.Setenv(EnvSiteTag, "site")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{AccountID: "acc", APIToken: "analytics", SiteTag: "site", ZoneID: "zone"}
+	if *cfg != want {
+		t.Errorf("LoadConfig() = %+v, want %+v", *cfg, want)
+	}
+
+	// The environment wins over .env
+	t.Setenv("CF_API_TOKEN", "from-env")
+	if cfg, _ := LoadConfig(); cfg.APIToken != "from-env" {
+		t.Errorf("APIToken = %q, want from-env", cfg.APIToken)
+	}
+}
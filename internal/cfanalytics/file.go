@@ -0,0 +1,210 @@
+package cfanalytics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/projectstate"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the multi-site config looked up in the working directory.
+const DefaultConfigFile = "analytics.yaml"
+
+// SiteConfig is one site in an analytics.yaml.
+// Empty fields take their value from the file's defaults, then from the
+// environment (see LoadConfig).
+type SiteConfig struct {
+	// Name identifies the site in reports and history files
+	Name string `yaml:"name"`
+
+	// AccountID is the account the site belongs to (the GraphQL accountTag)
+	AccountID string `yaml:"account_id,omitempty"`
+	SiteTag   string `yaml:"site_tag,omitempty"`
+	ZoneID    string `yaml:"zone_id,omitempty"`
+
+	// TokenEnv names the variable holding this account's API token, for
+	// sites spread over accounts with separate tokens
+	TokenEnv string `yaml:"token_env,omitempty"`
+
+	// Datasets to report (default: every dataset the site has an ID for)
+	Datasets []string `yaml:"datasets,omitempty"`
+}
+
+// FileConfig is an analytics.yaml:
+//
+//	defaults:
+//	  account_id: 0123456789abcdef
+//	  datasets: [rum, http]
+//	sites:
+//	  - name: docs
+//	    site_tag: aaaa
+//	    zone_id: bbbb
+//	  - name: blog
+//	    account_id: fedcba9876543210
+//	    token_env: CF_BLOG_ANALYTICS_TOKEN
+//	    site_tag: cccc
+type FileConfig struct {
+	Defaults SiteConfig   `yaml:"defaults,omitempty"`
+	Sites    []SiteConfig `yaml:"sites"`
+}
+
+// LoadFile reads and validates an analytics.yaml.
+func LoadFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var fc FileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(fc.Sites) == 0 {
+		return nil, fmt.Errorf("%s: no sites", path)
+	}
+
+	seen := make(map[string]bool)
+	for i, s := range fc.Sites {
+		if s.Name == "" {
+			return nil, fmt.Errorf("%s: site %d has no name", path, i+1)
+		}
+		if strings.ContainsAny(s.Name, `/\`) {
+			return nil, fmt.Errorf("%s: site name %q must not contain a path separator", path, s.Name)
+		}
+		if seen[s.Name] {
+			return nil, fmt.Errorf("%s: duplicate site name %q", path, s.Name)
+		}
+		seen[s.Name] = true
+		if _, err := ParseDatasets(strings.Join(s.Resolve(fc.Defaults).Datasets, ",")); err != nil {
+			return nil, fmt.Errorf("%s: site %q: %w", path, s.Name, err)
+		}
+	}
+	return &fc, nil
+}
+
+// Resolve returns the site with empty fields filled from defaults.
+func (s SiteConfig) Resolve(defaults SiteConfig) SiteConfig {
+	if s.AccountID == "" {
+		s.AccountID = defaults.AccountID
+	}
+	if s.SiteTag == "" {
+		s.SiteTag = defaults.SiteTag
+	}
+	if s.ZoneID == "" {
+		s.ZoneID = defaults.ZoneID
+	}
+	if s.TokenEnv == "" {
+		s.TokenEnv = defaults.TokenEnv
+	}
+	if len(s.Datasets) == 0 {
+		s.Datasets = defaults.Datasets
+	}
+	return s
+}
+
+// Config builds the report config for a resolved site. The account and
+// token fall back to base, the config loaded from the environment.
+func (s SiteConfig) Config(base *Config) (*Config, error) {
+	cfg := &Config{AccountID: base.AccountID, APIToken: base.APIToken, SiteTag: s.SiteTag, ZoneID: s.ZoneID, Endpoint: base.Endpoint}
+	if s.AccountID != "" {
+		cfg.AccountID = s.AccountID
+	}
+	if s.TokenEnv != "" {
+		cfg.APIToken = os.Getenv(s.TokenEnv)
+		if cfg.APIToken == "" {
+			return nil, fmt.Errorf("site %q: %s is not set", s.Name, s.TokenEnv)
+		}
+	}
+	return cfg, nil
+}
+
+// SiteHistoryPath returns the history file of a site from an analytics.yaml
+// in the current project: ~/.xplat/state/<project>/cfanalytics/<name>.jsonl
+func SiteHistoryPath(name string) string {
+	return filepath.Join(projectstate.ToolDir("cfanalytics"), name+".jsonl")
+}
+
+// SiteReport is the report of one site in a multi-site run.
+type SiteReport struct {
+	Name   string  `json:"name"`
+	Report *Report `json:"report,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+// MultiReport is the combined report of every site in an analytics.yaml.
+type MultiReport struct {
+	Since time.Time    `json:"since"`
+	Until time.Time    `json:"until"`
+	Sites []SiteReport `json:"sites"`
+}
+
+// OK reports whether every site's report was built.
+func (m *MultiReport) OK() bool {
+	for _, s := range m.Sites {
+		if s.Error != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// BuildFileReport builds the report of every site in fc over r, one site
+// at a time to stay within the API's rate limits. A site that fails is
+// recorded with its error and the others still run.
+func BuildFileReport(ctx context.Context, fc *FileConfig, base *Config, r Range, top int) *MultiReport {
+	multi := &MultiReport{Since: r.Since.UTC(), Until: r.Until.UTC()}
+	for _, site := range fc.Sites {
+		site = site.Resolve(fc.Defaults)
+		report, err := buildSiteReport(ctx, site, base, r, top)
+		sr := SiteReport{Name: site.Name, Report: report}
+		if err != nil {
+			sr.Error = err.Error()
+		}
+		multi.Sites = append(multi.Sites, sr)
+	}
+	return multi
+}
+
+func buildSiteReport(ctx context.Context, site SiteConfig, base *Config, r Range, top int) (*Report, error) {
+	cfg, err := site.Config(base)
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	datasets, err := ParseDatasets(strings.Join(site.Datasets, ","))
+	if err != nil {
+		return nil, err
+	}
+	return client.BuildReport(ctx, cfg, r, top, datasets...)
+}
+
+// WriteMarkdown writes one combined report with a section per site.
+func (m *MultiReport) WriteMarkdown(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# Traffic report\n\n%s to %s, %d sites\n", m.Since.Format(time.DateOnly), m.Until.Format(time.DateOnly), len(m.Sites)); err != nil {
+		return err
+	}
+	for _, s := range m.Sites {
+		if _, err := fmt.Fprintf(w, "\n## %s\n", s.Name); err != nil {
+			return err
+		}
+		if s.Error != "" {
+			if _, err := fmt.Fprintf(w, "\nFailed: %s\n", s.Error); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.Report.writeSections(w, "###"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
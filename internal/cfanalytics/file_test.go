@@ -0,0 +1,104 @@
+package cfanalytics
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "analytics.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	fc, err := LoadFile(write(`
+defaults:
+  account_id: acc
+  datasets: [rum]
+sites:
+  - name: docs
+    site_tag: aaaa
+  - name: blog
+    account_id: other
+    token_env: BLOG_TOKEN
+    site_tag: cccc
+    datasets: [rum, workers]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := fc.Sites[0].Resolve(fc.Defaults)
+	if docs.AccountID != "acc" || len(docs.Datasets) != 1 {
+		t.Errorf("docs = %+v", docs)
+	}
+
+	base := &Config{AccountID: "env-acc", APIToken: "env-token"}
+	blog := fc.Sites[1].Resolve(fc.Defaults)
+	if _, err := blog.Config(base); err == nil {
+		t.Error("Config() without BLOG_TOKEN should fail")
+	}
+	t.Setenv("BLOG_TOKEN", "blog-token")
+	cfg, err := blog.Config(base)
+	if err != nil || cfg.AccountID != "other" || cfg.APIToken != "blog-token" || cfg.SiteTag != "cccc" {
+		t.Errorf("Config() = %+v, %v", cfg, err)
+	}
+
+	for _, bad := range []string{
+		"sites: []",
+		"sites:\n  - site_tag: x",
+		"sites:\n  - name: a\n  - name: a",
+		"sites:\n  - name: ../a",
+		"sites:\n  - name: a\n    datasets: [dns]",
+	} {
+		if _, err := LoadFile(write(bad)); err == nil {
+			t.Errorf("LoadFile(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBuildFileReport(t *testing.T) {
+	a := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		_, vars := graphQLRequest(t, req)
+		if vars["siteTag"] == "broken" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"accounts":[{"rumPageloadEventsAdaptiveGroups":[
+			{"count":5,"sum":{"visits":3},"dimensions":{"siteTag":"` + vars["siteTag"].(string) + `","date":"2026-10-15","requestHost":"example.com","requestPath":"/"}}
+		]}]}}}`))
+	})
+
+	fc := &FileConfig{
+		Defaults: SiteConfig{Datasets: []string{DatasetRUM}},
+		Sites:    []SiteConfig{{Name: "docs", SiteTag: "aaaa"}, {Name: "old", SiteTag: "broken"}},
+	}
+	base := &Config{AccountID: "acc", APIToken: "token", Endpoint: a.endpoint}
+	r := Range{Since: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), Until: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)}
+
+	multi := BuildFileReport(context.Background(), fc, base, r, 0)
+	if multi.OK() || len(multi.Sites) != 2 {
+		t.Fatalf("multi = %+v", multi)
+	}
+	if multi.Sites[0].Report == nil || multi.Sites[0].Report.Days[0].PageViews != 5 || multi.Sites[1].Error == "" {
+		t.Errorf("sites = %+v", multi.Sites)
+	}
+
+	var md strings.Builder
+	if err := multi.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"2 sites", "## docs\n", "### Web Analytics", "## old\n\nFailed:"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package cfanalytics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/projectstate"
+)
+
+// Snapshot is one report run as kept in the history file. Only the daily
+// series are kept; top pages change too much to trend.
+type Snapshot struct {
+	Time      time.Time      `json:"time"`
+	AccountID string         `json:"account_id,omitempty"`
+	SiteTag   string         `json:"site_tag,omitempty"`
+	ZoneID    string         `json:"zone_id,omitempty"`
+	Days      []DayStat      `json:"days,omitempty"`
+	Zone      []ZoneHTTPStat `json:"zone,omitempty"`
+	Workers   []WorkerStat   `json:"workers,omitempty"`
+}
+
+// HistoryPath returns the current project's history file:
+// ~/.xplat/state/<project>/cfanalytics/history.jsonl
+func HistoryPath() string {
+	return filepath.Join(projectstate.ToolDir("cfanalytics"), "history.jsonl")
+}
+
+// AppendHistory appends the report's daily series to the history file.
+func AppendHistory(path string, r *Report) error {
+	if err := os.MkdirAll(filepath.Dir(path), config.DefaultDirPerms); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, config.DefaultFilePerms)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	snap := Snapshot{Time: time.Now().UTC(), AccountID: r.AccountID, SiteTag: r.SiteTag, ZoneID: r.ZoneID, Days: r.Days, Zone: r.Zone, Workers: r.Workers}
+	if err := json.NewEncoder(f).Encode(snap); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
+
+// LoadHistory reads snapshots taken after since, oldest first. A missing
+// file returns no snapshots. Malformed lines are skipped.
+func LoadHistory(path string, since time.Time) ([]Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var snaps []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var s Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			continue
+		}
+		if s.Time.Before(since) {
+			continue
+		}
+		snaps = append(snaps, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return snaps, nil
+}
+
+// ParseHistoryWindow parses a history window such as "12w", "90d" or "720h"
+// and returns it in whole weeks (at least one).
+func ParseHistoryWindow(s string) (int, error) {
+	var days float64
+	switch {
+	case strings.HasSuffix(s, "w"):
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "w"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid history window %q", s)
+		}
+		days = float64(n * 7)
+	case strings.HasSuffix(s, "d"):
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid history window %q", s)
+		}
+		days = float64(n)
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid history window %q (want e.g. 12w, 90d)", s)
+		}
+		days = d.Hours() / 24
+	}
+	if days <= 0 {
+		return 0, fmt.Errorf("history window %q must be positive", s)
+	}
+	weeks := int(days+6) / 7
+	return weeks, nil
+}
+
+// Trend is one metric summed per week, oldest week first.
+type Trend struct {
+	Metric string  `json:"metric"`
+	Weeks  []int64 `json:"weeks"`
+	// Seen marks weeks with at least one day of data; other weeks are 0
+	// in Weeks and drawn as gaps.
+	Seen []bool `json:"seen"`
+	// WoW is the change of the last week against the one before, in percent.
+	// Nil when either week has no data or the previous week is 0.
+	WoW *float64 `json:"wow,omitempty"`
+	// MovingAvg is the mean of the last (up to) four weeks with data.
+	MovingAvg float64 `json:"moving_avg"`
+}
+
+// trendMetrics are the metrics trended, in report order, with the dataset
+// each comes from. Uniques are summed daily uniques, so they count a
+// returning visitor once per day. Worker metrics are summed over scripts.
+var trendMetrics = []struct {
+	name    string
+	dataset string
+	day     func(DayStat) int64
+	zone    func(ZoneHTTPStat) int64
+	worker  func(WorkerStat) int64
+}{
+	{name: "page views", dataset: DatasetRUM, day: func(d DayStat) int64 { return d.PageViews }},
+	{name: "visits", dataset: DatasetRUM, day: func(d DayStat) int64 { return d.Visits }},
+	{name: "requests", dataset: DatasetHTTP, zone: func(d ZoneHTTPStat) int64 { return d.Requests }},
+	{name: "cached", dataset: DatasetHTTP, zone: func(d ZoneHTTPStat) int64 { return d.CachedRequests }},
+	{name: "bandwidth", dataset: DatasetHTTP, zone: func(d ZoneHTTPStat) int64 { return d.Bytes }},
+	{name: "threats", dataset: DatasetHTTP, zone: func(d ZoneHTTPStat) int64 { return d.Threats }},
+	{name: "uniques", dataset: DatasetHTTP, zone: func(d ZoneHTTPStat) int64 { return d.Uniques }},
+	{name: "invocations", dataset: DatasetWorkers, worker: func(s WorkerStat) int64 { return s.Requests }},
+	{name: "errors", dataset: DatasetWorkers, worker: func(s WorkerStat) int64 { return s.Errors }},
+}
+
+// AddTrends sets r.Trends to weekly trends over the given number of weeks
+// ending with r.Until, from the history snapshots for r's site, zone and
+// account plus r itself. Later snapshots win for days that appear in several.
+func (r *Report) AddTrends(history []Snapshot, weeks int) {
+	days := make(map[string]DayStat)
+	zone := make(map[string]ZoneHTTPStat)
+	workers := make(map[string]WorkerStat) // By script and date
+	add := func(s Snapshot) {
+		if r.SiteTag != "" && s.SiteTag == r.SiteTag {
+			for _, d := range s.Days {
+				days[d.Date] = d
+			}
+		}
+		if r.ZoneID != "" && s.ZoneID == r.ZoneID {
+			for _, d := range s.Zone {
+				zone[d.Date] = d
+			}
+		}
+		if r.AccountID != "" && s.AccountID == r.AccountID {
+			for _, w := range s.Workers {
+				workers[w.Script+"|"+w.Date] = w
+			}
+		}
+	}
+	for _, s := range history {
+		add(s)
+	}
+	add(Snapshot{AccountID: r.AccountID, SiteTag: r.SiteTag, ZoneID: r.ZoneID, Days: r.Days, Zone: r.Zone, Workers: r.Workers})
+
+	// Week 0 is the oldest; the last week ends with the report's last day
+	end := r.Until.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	weekOf := func(date string) int {
+		t, err := time.Parse(time.DateOnly, date)
+		if err != nil || !t.Before(end) {
+			return -1
+		}
+		back := int(end.Sub(t).Hours()/24-1) / 7
+		if back >= weeks {
+			return -1
+		}
+		return weeks - 1 - back
+	}
+
+	r.Trends = nil
+	for _, m := range trendMetrics {
+		if !r.Has(m.dataset) {
+			continue
+		}
+		t := Trend{Metric: m.name, Weeks: make([]int64, weeks), Seen: make([]bool, weeks)}
+		switch m.dataset {
+		case DatasetRUM:
+			for date, d := range days {
+				if w := weekOf(date); w >= 0 {
+					t.Weeks[w] += m.day(d)
+					t.Seen[w] = true
+				}
+			}
+		case DatasetHTTP:
+			for date, d := range zone {
+				if w := weekOf(date); w >= 0 {
+					t.Weeks[w] += m.zone(d)
+					t.Seen[w] = true
+				}
+			}
+		case DatasetWorkers:
+			for _, s := range workers {
+				if w := weekOf(s.Date); w >= 0 {
+					t.Weeks[w] += m.worker(s)
+					t.Seen[w] = true
+				}
+			}
+		}
+		t.summarize()
+		r.Trends = append(r.Trends, t)
+	}
+}
+
+// summarize sets WoW and MovingAvg from the weekly values.
+func (t *Trend) summarize() {
+	n := len(t.Weeks)
+	if n >= 2 && t.Seen[n-1] && t.Seen[n-2] && t.Weeks[n-2] != 0 {
+		wow := float64(t.Weeks[n-1]-t.Weeks[n-2]) * 100 / float64(t.Weeks[n-2])
+		t.WoW = &wow
+	}
+
+	var sum int64
+	count := 0
+	for i := n - 1; i >= 0 && count < 4; i-- {
+		if t.Seen[i] {
+			sum += t.Weeks[i]
+			count++
+		}
+	}
+	if count > 0 {
+		t.MovingAvg = float64(sum) / float64(count)
+	}
+}
+
+// Last returns the most recent week's value.
+func (t Trend) Last() int64 {
+	if len(t.Weeks) == 0 {
+		return 0
+	}
+	return t.Weeks[len(t.Weeks)-1]
+}
+
+// FormatWoW formats the week-over-week change, "-" when unknown.
+func (t Trend) FormatWoW() string {
+	if t.WoW == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", *t.WoW)
+}
+
+// Format formats a value of the trend's metric (bytes for bandwidth).
+func (t Trend) Format(v float64) string {
+	if t.Metric == "bandwidth" {
+		return FormatBytes(int64(v))
+	}
+	if v == float64(int64(v)) {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 1, 64)
+}
+
+// sparkBlocks are the eighth-height bars a sparkline is drawn with.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the weekly values scaled between their min and max, with
+// a space for weeks without data.
+func (t Trend) Sparkline() string {
+	var lo, hi int64
+	first := true
+	for i, v := range t.Weeks {
+		if !t.Seen[i] {
+			continue
+		}
+		if first || v < lo {
+			lo = v
+		}
+		if first || v > hi {
+			hi = v
+		}
+		first = false
+	}
+
+	var b strings.Builder
+	for i, v := range t.Weeks {
+		switch {
+		case !t.Seen[i]:
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkBlocks[len(sparkBlocks)/2])
+		default:
+			b.WriteRune(sparkBlocks[int((v-lo)*int64(len(sparkBlocks)-1)/(hi-lo))])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package cfanalytics
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if snaps, err := LoadHistory(path, time.Time{}); err != nil || snaps != nil {
+		t.Fatalf("LoadHistory() on missing file = %v, %v", snaps, err)
+	}
+
+	r := &Report{SiteTag: "site", Days: []DayStat{{Date: "2026-10-15", PageViews: 5, Visits: 3}}}
+	if err := AppendHistory(path, r); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString("not json\n")
+	_ = f.Close()
+	if err := AppendHistory(path, r); err != nil {
+		t.Fatal(err)
+	}
+
+	snaps, err := LoadHistory(path, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 || !reflect.DeepEqual(snaps[0].Days, r.Days) {
+		t.Errorf("LoadHistory() = %+v", snaps)
+	}
+	if snaps, _ := LoadHistory(path, time.Now().Add(time.Hour)); len(snaps) != 0 {
+		t.Errorf("LoadHistory() after now = %d snapshots", len(snaps))
+	}
+}
+
+func TestParseHistoryWindow(t *testing.T) {
+	for in, want := range map[string]int{"12w": 12, "90d": 13, "7d": 1, "1d": 1, "336h": 2} {
+		if got, err := ParseHistoryWindow(in); err != nil || got != want {
+			t.Errorf("ParseHistoryWindow(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "w", "0w", "-3d", "soon"} {
+		if _, err := ParseHistoryWindow(in); err == nil {
+			t.Errorf("ParseHistoryWindow(%q) should fail", in)
+		}
+	}
+}
+
+func TestAddTrends(t *testing.T) {
+	history := []Snapshot{
+		// Week 0 (Sep 22-28) has no data; week 1 is from an old run
+		{SiteTag: "site", Days: []DayStat{{Date: "2026-10-01", PageViews: 10, Visits: 5}, {Date: "2026-10-08", PageViews: 1}}},
+		// A later run corrects Oct 8 and adds week 2
+		{SiteTag: "site", Days: []DayStat{{Date: "2026-10-08", PageViews: 40, Visits: 20}}},
+		// Other sites are ignored
+		{SiteTag: "other", Days: []DayStat{{Date: "2026-10-15", PageViews: 1000}}},
+	}
+	r := &Report{
+		Until:    time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Datasets: []string{DatasetRUM},
+		SiteTag:  "site",
+		Days:     []DayStat{{Date: "2026-10-13", PageViews: 30, Visits: 10}, {Date: "2026-10-19", PageViews: 20, Visits: 10}},
+	}
+	r.AddTrends(history, 4)
+
+	if len(r.Trends) != 2 {
+		t.Fatalf("trends = %+v, want page views and visits", r.Trends)
+	}
+	pv := r.Trends[0]
+	if pv.Metric != "page views" || !reflect.DeepEqual(pv.Weeks, []int64{0, 10, 40, 50}) {
+		t.Errorf("page views = %+v", pv)
+	}
+	if !reflect.DeepEqual(pv.Seen, []bool{false, true, true, true}) {
+		t.Errorf("seen = %v", pv.Seen)
+	}
+	if pv.FormatWoW() != "+25.0%" || pv.MovingAvg != 100.0/3 {
+		t.Errorf("wow = %s, moving avg = %v", pv.FormatWoW(), pv.MovingAvg)
+	}
+	if got := pv.Sparkline(); got != " ▁▆█" {
+		t.Errorf("Sparkline() = %q", got)
+	}
+
+	var md strings.Builder
+	if err := r.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| page views | 50 | +25.0% | 33.3 | ` ▁▆█` |") {
+		t.Errorf("markdown:\n%s", md.String())
+	}
+
+	flat := Trend{Weeks: []int64{3, 3}, Seen: []bool{true, true}}
+	if got := flat.Sparkline(); got != "▅▅" {
+		t.Errorf("flat Sparkline() = %q", got)
+	}
+}
+
+func TestAddTrendsWorkers(t *testing.T) {
+	history := []Snapshot{
+		{AccountID: "acc", Workers: []WorkerStat{{Script: "api", Date: "2026-10-08", Requests: 100, Errors: 1}}},
+		// Another account's Workers are ignored
+		{AccountID: "other", Workers: []WorkerStat{{Script: "api", Date: "2026-10-08", Requests: 9000}}},
+	}
+	r := &Report{
+		Until:     time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Datasets:  []string{DatasetWorkers},
+		AccountID: "acc",
+		Workers: []WorkerStat{
+			{Script: "api", Date: "2026-10-15", Requests: 80, Errors: 8},
+			{Script: "sync", Date: "2026-10-15", Requests: 20, Errors: 2},
+		},
+	}
+	r.AddTrends(history, 2)
+
+	if len(r.Trends) != 2 || r.Trends[0].Metric != "invocations" || r.Trends[1].Metric != "errors" {
+		t.Fatalf("trends = %+v, want invocations and errors", r.Trends)
+	}
+	if !reflect.DeepEqual(r.Trends[0].Weeks, []int64{100, 100}) || r.Trends[1].FormatWoW() != "+900.0%" {
+		t.Errorf("trends = %+v", r.Trends)
+	}
+}
@@ -0,0 +1,271 @@
+package cfanalytics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultReportTop is how many pages a report lists.
+const DefaultReportTop = 20
+
+// Datasets a report can include.
+const (
+	DatasetRUM     = "rum"     // Web Analytics page views and visits (needs a site tag)
+	DatasetHTTP    = "http"    // Zone requests, cache ratio, bandwidth and threats (needs a zone)
+	DatasetWorkers = "workers" // Worker invocations and errors for the account
+)
+
+// Datasets lists every dataset in report order.
+var Datasets = []string{DatasetRUM, DatasetHTTP, DatasetWorkers}
+
+// ParseDatasets parses a comma-separated dataset list such as "rum,http".
+// An empty string returns nil, which BuildReport treats as the default.
+func ParseDatasets(s string) ([]string, error) {
+	var out []string
+	for _, d := range strings.Split(s, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" || slices.Contains(out, d) {
+			continue
+		}
+		if !slices.Contains(Datasets, d) {
+			return nil, fmt.Errorf("unknown dataset %q (want %s)", d, strings.Join(Datasets, ", "))
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// PageStat is page views and visits for one page over a report's range.
+type PageStat struct {
+	Host      string `json:"host"`
+	Path      string `json:"path"`
+	PageViews int64  `json:"page_views"`
+	Visits    int64  `json:"visits"`
+}
+
+// DayStat is Web Analytics totals for one day.
+type DayStat struct {
+	Date      string `json:"date"`
+	PageViews int64  `json:"page_views"`
+	Visits    int64  `json:"visits"`
+}
+
+// Report is a traffic summary for one site, zone and account.
+type Report struct {
+	Since     time.Time      `json:"since"`
+	Until     time.Time      `json:"until"`
+	Datasets  []string       `json:"datasets"`
+	AccountID string         `json:"account_id,omitempty"` // Set with the workers dataset
+	SiteTag   string         `json:"site_tag,omitempty"`   // Set with the rum dataset
+	ZoneID    string         `json:"zone_id,omitempty"`    // Set with the http dataset
+	Days      []DayStat      `json:"days,omitempty"`       // Web Analytics per day
+	Pages     []PageStat     `json:"pages,omitempty"`      // Top pages, most viewed first
+	Zone      []ZoneHTTPStat `json:"zone,omitempty"`       // Zone HTTP traffic per day
+	Workers   []WorkerStat   `json:"workers,omitempty"`    // Worker invocations per script and day
+	Trends    []Trend        `json:"trends,omitempty"`     // Weekly trends from history (see AddTrends)
+}
+
+// Has reports whether the report includes a dataset.
+func (r *Report) Has(dataset string) bool {
+	return slices.Contains(r.Datasets, dataset)
+}
+
+// BuildReport fetches the datasets over r, keeping the top pages. With no
+// datasets it fetches rum if cfg has a site tag, http if it has a zone, and
+// workers. Asking for rum or http without the matching ID is an error.
+func (a *Client) BuildReport(ctx context.Context, cfg *Config, r Range, top int, datasets ...string) (*Report, error) {
+	if len(datasets) == 0 {
+		if cfg.SiteTag == "" && cfg.ZoneID == "" {
+			return nil, fmt.Errorf("a site tag (%s) or zone ID is required", EnvSiteTag)
+		}
+		if cfg.SiteTag != "" {
+			datasets = append(datasets, DatasetRUM)
+		}
+		if cfg.ZoneID != "" {
+			datasets = append(datasets, DatasetHTTP)
+		}
+		datasets = append(datasets, DatasetWorkers)
+	}
+	if top <= 0 {
+		top = DefaultReportTop
+	}
+	report := &Report{Since: r.Since.UTC(), Until: r.Until.UTC()}
+
+	// Fetch in report order whatever order the datasets were given in
+	for _, d := range Datasets {
+		if !slices.Contains(datasets, d) {
+			continue
+		}
+		switch d {
+		case DatasetRUM:
+			if cfg.SiteTag == "" {
+				return nil, fmt.Errorf("the %s dataset needs a site tag (%s)", d, EnvSiteTag)
+			}
+			rows, err := a.FetchRUM(ctx, r, cfg.SiteTag)
+			if err != nil {
+				return nil, err
+			}
+			report.SiteTag = cfg.SiteTag
+			report.Days, report.Pages = summarizeRUM(rows, top)
+		case DatasetHTTP:
+			if cfg.ZoneID == "" {
+				return nil, fmt.Errorf("the %s dataset needs a zone ID", d)
+			}
+			days, err := a.FetchZoneHTTP(ctx, r, cfg.ZoneID)
+			if err != nil {
+				return nil, err
+			}
+			report.ZoneID = cfg.ZoneID
+			report.Zone = days
+		case DatasetWorkers:
+			rows, err := a.FetchWorkers(ctx, r, "")
+			if err != nil {
+				return nil, err
+			}
+			report.AccountID = a.AccountID
+			report.Workers = rows
+		}
+		report.Datasets = append(report.Datasets, d)
+	}
+	if len(report.Datasets) == 0 {
+		return nil, fmt.Errorf("no datasets selected (want %s)", strings.Join(Datasets, ", "))
+	}
+	return report, nil
+}
+
+// WorkerTotals sums the report's Worker invocations per script over the
+// whole range, busiest first.
+func (r *Report) WorkerTotals() []WorkerStat {
+	totals := make([]WorkerStat, len(r.Workers))
+	for i, s := range r.Workers {
+		totals[i] = WorkerStat{Script: s.Script, Requests: s.Requests, Errors: s.Errors, Subrequests: s.Subrequests}
+	}
+	totals = mergeRows(totals,
+		func(s WorkerStat) string { return s.Script },
+		func(into *WorkerStat, s WorkerStat) {
+			into.Requests += s.Requests
+			into.Errors += s.Errors
+			into.Subrequests += s.Subrequests
+		})
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].Requests != totals[j].Requests {
+			return totals[i].Requests > totals[j].Requests
+		}
+		return totals[i].Script < totals[j].Script
+	})
+	return totals
+}
+
+// summarizeRUM totals RUM rows per day and per page.
+func summarizeRUM(rows []RUMStat, top int) ([]DayStat, []PageStat) {
+	days := make([]DayStat, len(rows))
+	for i, s := range rows {
+		days[i] = DayStat{Date: s.Date, PageViews: s.PageViews, Visits: s.Visits}
+	}
+	days = mergeRows(days,
+		func(s DayStat) string { return s.Date },
+		func(into *DayStat, s DayStat) { into.PageViews += s.PageViews; into.Visits += s.Visits })
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+	pages := make([]PageStat, len(rows))
+	for i, s := range rows {
+		pages[i] = PageStat{Host: s.Host, Path: s.Path, PageViews: s.PageViews, Visits: s.Visits}
+	}
+	pages = mergeRows(pages,
+		func(s PageStat) string { return s.Host + "|" + s.Path },
+		func(into *PageStat, s PageStat) { into.PageViews += s.PageViews; into.Visits += s.Visits })
+	sort.SliceStable(pages, func(i, j int) bool {
+		if pages[i].PageViews != pages[j].PageViews {
+			return pages[i].PageViews > pages[j].PageViews
+		}
+		return pages[i].Host+pages[i].Path < pages[j].Host+pages[j].Path
+	})
+	if len(pages) > top {
+		pages = pages[:top]
+	}
+	return days, pages
+}
+
+// WriteMarkdown writes the report as Markdown tables.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# Traffic report\n\n%s to %s\n", r.Since.Format(time.DateOnly), r.Until.Format(time.DateOnly)); err != nil {
+		return err
+	}
+	return r.writeSections(w, "##")
+}
+
+// writeSections writes the report's tables under headings of the given
+// level ("##" for a single report, "###" inside a multi-site report).
+func (r *Report) writeSections(w io.Writer, h string) error {
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	if r.SiteTag != "" {
+		printf("\n%s Web Analytics\n\n| Date | Page views | Visits |\n|---|---:|---:|\n", h)
+		for _, d := range r.Days {
+			printf("| %s | %d | %d |\n", d.Date, d.PageViews, d.Visits)
+		}
+		printf("\n%s Top pages\n\n| Page | Page views | Visits |\n|---|---:|---:|\n", h)
+		for _, p := range r.Pages {
+			printf("| %s%s | %d | %d |\n", p.Host, p.Path, p.PageViews, p.Visits)
+		}
+	}
+	if len(r.Trends) > 0 {
+		printf("\n%s Trends (%d weeks)\n\n| Metric | Last week | WoW | 4-week avg | Trend |\n|---|---:|---:|---:|---|\n", h, len(r.Trends[0].Weeks))
+		for _, t := range r.Trends {
+			printf("| %s | %s | %s | %s | `%s` |\n", t.Metric, t.Format(float64(t.Last())), t.FormatWoW(), t.Format(t.MovingAvg), t.Sparkline())
+		}
+	}
+	if r.ZoneID != "" {
+		printf("\n%s Zone traffic\n\n| Date | Requests | Cached | Bandwidth | Threats | Uniques |\n|---|---:|---:|---:|---:|---:|\n", h)
+		for _, d := range r.Zone {
+			printf("| %s | %d | %s | %s | %d | %d |\n", d.Date, d.Requests, CachedPercent(d), FormatBytes(d.Bytes), d.Threats, d.Uniques)
+		}
+	}
+	if r.Has(DatasetWorkers) {
+		printf("\n%s Workers\n\n| Script | Requests | Errors | Error rate | Subrequests |\n|---|---:|---:|---:|---:|\n", h)
+		for _, s := range r.WorkerTotals() {
+			printf("| %s | %d | %d | %s | %d |\n", s.Script, s.Requests, s.Errors, ErrorPercent(s), s.Subrequests)
+		}
+	}
+	return err
+}
+
+// ErrorPercent returns the share of a Worker's invocations that failed.
+func ErrorPercent(s WorkerStat) string {
+	if s.Requests == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(s.Errors)*100/float64(s.Requests))
+}
+
+// CachedPercent returns the share of a day's requests served from cache.
+func CachedPercent(d ZoneHTTPStat) string {
+	if d.Requests == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(d.CachedRequests)*100/float64(d.Requests))
+}
+
+// FormatBytes formats a byte count with a decimal unit (kB, MB, GB, TB).
+func FormatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 3; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGT"[exp])
+}
@@ -0,0 +1,89 @@
+package cfanalytics
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildReport(t *testing.T) {
+	a := newTestClient(t, func(w http.ResponseWriter, req *http.Request) {
+		query, _ := graphQLRequest(t, req)
+		if strings.Contains(query, "httpRequests1dGroups") {
+			_, _ = w.Write([]byte(`{"data":{"viewer":{"zones":[{"httpRequests1dGroups":[
+				{"sum":{"requests":400,"cachedRequests":100,"bytes":2500000,"threats":1},"uniq":{"uniques":30},"dimensions":{"date":"2026-10-15"}}
+			]}]}}}`))
+			return
+		}
+		if strings.Contains(query, "workersInvocationsAdaptive") {
+			_, _ = w.Write([]byte(`{"data":{"viewer":{"accounts":[{"workersInvocationsAdaptive":[
+				{"sum":{"requests":90,"errors":9},"dimensions":{"scriptName":"api","date":"2026-10-15"}},
+				{"sum":{"requests":10},"dimensions":{"scriptName":"sync","date":"2026-10-15"}},
+				{"sum":{"requests":60,"errors":3,"subrequests":4},"dimensions":{"scriptName":"api","date":"2026-10-16"}}
+			]}]}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"accounts":[{"rumPageloadEventsAdaptiveGroups":[
+			{"count":5,"sum":{"visits":3},"dimensions":{"siteTag":"site","date":"2026-10-15","requestHost":"example.com","requestPath":"/"}},
+			{"count":2,"sum":{"visits":2},"dimensions":{"siteTag":"site","date":"2026-10-15","requestHost":"example.com","requestPath":"/docs"}},
+			{"count":4,"sum":{"visits":4},"dimensions":{"siteTag":"site","date":"2026-10-16","requestHost":"example.com","requestPath":"/docs"}}
+		]}]}}}`))
+	})
+
+	r := Range{Since: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), Until: time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)}
+	report, err := a.BuildReport(context.Background(), &Config{SiteTag: "site", ZoneID: "zone"}, r, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Datasets, Datasets) || report.AccountID != "acc" {
+		t.Errorf("datasets = %v, account = %q", report.Datasets, report.AccountID)
+	}
+	if len(report.Days) != 2 || report.Days[0].PageViews != 7 || report.Days[1].Visits != 4 {
+		t.Errorf("days = %+v", report.Days)
+	}
+	if len(report.Pages) != 1 || report.Pages[0].Path != "/docs" || report.Pages[0].PageViews != 6 {
+		t.Errorf("pages = %+v, want only /docs with 6 views", report.Pages)
+	}
+
+	var md strings.Builder
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Top pages", "| example.com/docs | 6 | 6 |", "| 2026-10-15 | 400 | 25.0% | 2.5 MB | 1 | 30 |", "| api | 150 | 12 | 8.0% | 4 |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	if _, err := a.BuildReport(context.Background(), &Config{}, r, 0); err == nil {
+		t.Error("BuildReport without a site or zone should fail")
+	}
+
+	// Only the selected datasets are fetched, in report order
+	report, err = a.BuildReport(context.Background(), &Config{SiteTag: "site", ZoneID: "zone"}, r, 0, DatasetWorkers, DatasetHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Datasets, []string{DatasetHTTP, DatasetWorkers}) || report.SiteTag != "" || len(report.Days) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if _, err := a.BuildReport(context.Background(), &Config{ZoneID: "zone"}, r, 0, DatasetRUM); err == nil {
+		t.Error("the rum dataset without a site tag should fail")
+	}
+}
+
+func TestParseDatasets(t *testing.T) {
+	got, err := ParseDatasets(" workers, RUM,,workers")
+	if err != nil || !reflect.DeepEqual(got, []string{DatasetWorkers, DatasetRUM}) {
+		t.Errorf("ParseDatasets() = %v, %v", got, err)
+	}
+	if got, err := ParseDatasets(""); err != nil || got != nil {
+		t.Errorf("ParseDatasets(\"\") = %v, %v", got, err)
+	}
+	if _, err := ParseDatasets("rum,dns"); err == nil {
+		t.Error("ParseDatasets() should reject unknown datasets")
+	}
+}
@@ -94,6 +94,25 @@ const (
 	XplatTagPrefix = "xplat-"
 )
 
+// === Package manager manifests ===
+
+const (
+	// XplatDescription is the one-line description used in package manifests.
+	XplatDescription = "One binary to bootstrap and run any plat-* project"
+
+	// XplatHomebrewTap is the GitHub repository of the Homebrew tap (brew tap joeblew999/tap).
+	XplatHomebrewTap = "joeblew999/homebrew-tap"
+
+	// XplatHomebrewFormulaPath is the formula's path inside the tap repository.
+	XplatHomebrewFormulaPath = "Formula/xplat.rb"
+
+	// XplatScoopBucket is the GitHub repository of the Scoop bucket.
+	XplatScoopBucket = "joeblew999/scoop-bucket"
+
+	// XplatScoopManifestPath is the manifest's path inside the bucket repository.
+	XplatScoopManifestPath = "bucket/xplat.json"
+)
+
 // === xplat binary installation ===
 //
 // xplat ALWAYS installs to ~/.local/bin/xplat (the canonical location).
@@ -181,6 +200,14 @@ func XplatCache() string {
 	return filepath.Join(XplatHome(), "cache")
 }
 
+// XplatState returns the global xplat state directory.
+// Tool state (history, run state) lives here in one subdirectory per
+// project, so running a tool never writes into the working tree.
+// Returns ~/.xplat/state (or $XPLAT_HOME/state)
+func XplatState() string {
+	return filepath.Join(XplatHome(), "state")
+}
+
 // XplatConfig returns the global xplat config directory.
 // Used for user preferences, credentials, etc.
 // Returns ~/.xplat/config (or $XPLAT_HOME/config)
@@ -27,6 +27,19 @@ type ServiceConfig struct {
 	SyncRepos string `yaml:"sync_repos,omitempty"`
 	// SyncInterval is poll interval (default: 5m)
 	SyncInterval string `yaml:"sync_interval,omitempty"`
+
+	// Watchdog restarts the service's processes when they exit, with
+	// backoff, and reports flapping ones through the notification router
+	Watchdog bool `yaml:"watchdog,omitempty"`
+	// Services are extra xplat commands the watchdog keeps running, such as
+	// receivers (sync-cf receive) or archive clients (sync-gh sse-client)
+	Services []WatchedService `yaml:"services,omitempty"`
+}
+
+// WatchedService is an xplat command kept running by the service watchdog.
+type WatchedService struct {
+	Name string   `yaml:"name"`
+	Args []string `yaml:"args"` // xplat arguments, e.g. [sync-cf, receive]
 }
 
 // LoadServiceConfig reads the service config from ~/.xplat/service.yaml.
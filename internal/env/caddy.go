@@ -18,6 +18,7 @@ import (
 	"time"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
 )
 
 const (
@@ -102,7 +103,7 @@ func (rm *registryManager) save(registry *serviceRegistry) error {
 		return fmt.Errorf("failed to marshal service registry: %w", err)
 	}
 
-	if err := os.WriteFile(serviceRegistryPath, data, config.DefaultFilePerms); err != nil {
+	if err := statestore.WriteFile(serviceRegistryPath, data, config.DefaultFilePerms); err != nil {
 		return fmt.Errorf("failed to write service registry: %w", err)
 	}
 
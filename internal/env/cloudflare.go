@@ -2,11 +2,13 @@ package env
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 )
 
@@ -51,6 +53,95 @@ type CloudflareAccountsResponse struct {
 	} `json:"result"`
 }
 
+// CloudflareListResponse is the envelope shared by Cloudflare list endpoints
+type CloudflareListResponse struct {
+	Success    bool            `json:"success"`
+	Errors     []interface{}   `json:"errors"`
+	Result     json.RawMessage `json:"result"`
+	ResultInfo struct {
+		Page       int `json:"page"`
+		TotalPages int `json:"total_pages"`
+	} `json:"result_info"`
+}
+
+// NewCloudflareRequest creates a Cloudflare API request authenticated with token
+func NewCloudflareRequest(ctx context.Context, method, url, token string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// CloudflareListAll fetches every page of a Cloudflare list endpoint into out
+// (a pointer to a slice). path is relative to CloudflareAPIBaseURL and may
+// already carry a query string.
+func CloudflareListAll(ctx context.Context, token, path string, out interface{}) error {
+	client := &http.Client{Timeout: 30 * time.Second}
+	var all []json.RawMessage
+
+	for page := 1; ; page++ {
+		sep := "?"
+		if strings.Contains(path, "?") {
+			sep = "&"
+		}
+		reqURL := fmt.Sprintf("%s%s%spage=%d&per_page=50", CloudflareAPIBaseURL, path, sep, page)
+
+		apiResp, err := cloudflareGetList(ctx, client, reqURL, token)
+		if err != nil {
+			return err
+		}
+
+		var items []json.RawMessage
+		if len(apiResp.Result) > 0 && string(apiResp.Result) != "null" {
+			if err := json.Unmarshal(apiResp.Result, &items); err != nil {
+				return fmt.Errorf("decode result: %w", err)
+			}
+		}
+		all = append(all, items...)
+
+		if apiResp.ResultInfo.TotalPages <= page || len(items) == 0 {
+			break
+		}
+	}
+
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// cloudflareGetList fetches one page of a list endpoint
+func cloudflareGetList(ctx context.Context, client *http.Client, reqURL, token string) (*CloudflareListResponse, error) {
+	req, err := NewCloudflareRequest(ctx, "GET", reqURL, token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var apiResp CloudflareListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if !apiResp.Success {
+		return nil, fmt.Errorf("API returned success=false: %v", apiResp.Errors)
+	}
+	return &apiResp, nil
+}
+
 // ValidateCloudflareToken validates a Cloudflare API token and returns the token name
 func ValidateCloudflareToken(token string) (string, error) {
 	if token == "" || token == PlaceholderToken {
@@ -60,14 +151,11 @@ func ValidateCloudflareToken(token string) (string, error) {
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	// Verify token
-	req, err := http.NewRequest("GET", CloudflareAPITokenVerifyURL, nil)
+	req, err := NewCloudflareRequest(context.Background(), "GET", CloudflareAPITokenVerifyURL, token, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to verify token: %w", err)
@@ -95,15 +183,12 @@ func ValidateCloudflareToken(token string) (string, error) {
 	// This requires "User: API Tokens: Read" permission
 	// If token lacks this permission, validation will still succeed but won't show the name
 	tokenID := verifyResp.Result.ID
-	tokenReq, err := http.NewRequest("GET", fmt.Sprintf(CloudflareAPITokenInfoURL, tokenID), nil)
+	tokenReq, err := NewCloudflareRequest(context.Background(), "GET", fmt.Sprintf(CloudflareAPITokenInfoURL, tokenID), token, nil)
 	if err != nil {
 		// Can't create request - return without name
 		return "", nil
 	}
 
-	tokenReq.Header.Set("Authorization", "Bearer "+token)
-	tokenReq.Header.Set("Content-Type", "application/json")
-
 	tokenResp, err := client.Do(tokenReq)
 	if err != nil {
 		// Can't fetch details - return without name
@@ -140,14 +225,11 @@ func ValidateCloudflareAccount(token, accountID string) (string, error) {
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	url := fmt.Sprintf(CloudflareAPIAccountURL, accountID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := NewCloudflareRequest(context.Background(), "GET", url, token, nil)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to verify account: %w", err)
@@ -213,14 +295,11 @@ func GetCloudflareAccounts(token string) (accountID, accountName string, err err
 
 	client := &http.Client{Timeout: 10 * time.Second}
 
-	req, err := http.NewRequest("GET", CloudflareAPIAccountsURL, nil)
+	req, err := NewCloudflareRequest(context.Background(), "GET", CloudflareAPIAccountsURL, token, nil)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to fetch accounts: %w", err)
@@ -278,14 +357,11 @@ func ListZones(token, accountID string) ([]Zone, error) {
 
 	// List all zones accessible by the token (zones are automatically filtered by token permissions)
 	url := fmt.Sprintf("%s?per_page=50", CloudflareAPIZonesURL)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := NewCloudflareRequest(context.Background(), "GET", url, token, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch zones: %w", err)
@@ -357,14 +433,11 @@ func ListPagesProjects(token, accountID string) ([]PagesProject, error) {
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	url := fmt.Sprintf(CloudflareAPIPagesURL, accountID)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := NewCloudflareRequest(context.Background(), "GET", url, token, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Pages projects: %w", err)
@@ -406,14 +479,11 @@ func DeletePagesProject(token, accountID, projectName string) error {
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	url := fmt.Sprintf(CloudflareAPIPagesDeleteURL, accountID, projectName)
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := NewCloudflareRequest(context.Background(), "DELETE", url, token, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete Pages project: %w", err)
@@ -461,14 +531,11 @@ func ListPagesDomains(token, accountID, projectName string) ([]PagesDomain, erro
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	url := fmt.Sprintf(CloudflareAPIPagesDomainsURL, accountID, projectName)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := NewCloudflareRequest(context.Background(), "GET", url, token, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch domains: %w", err)
@@ -525,14 +592,11 @@ func AddPagesDomain(token, accountID, projectName, domainName string) error {
 	}
 
 	// POST request to add domain with JSON body
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	req, err := NewCloudflareRequest(context.Background(), "POST", url, token, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to add domain: %w", err)
@@ -582,14 +646,11 @@ func DeletePagesDomain(token, accountID, projectName, domainName string) error {
 	client := &http.Client{Timeout: 10 * time.Second}
 
 	url := fmt.Sprintf(CloudflareAPIPagesDeleteDomainURL, accountID, projectName, domainName)
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := NewCloudflareRequest(context.Background(), "DELETE", url, token, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to delete domain: %w", err)
@@ -6,6 +6,7 @@ const (
 	AnthropicAPIMessagesURL = "https://api.anthropic.com/v1/messages"
 
 	// Cloudflare API endpoints
+	CloudflareAPIBaseURL              = "https://api.cloudflare.com/client/v4"
 	CloudflareAPITokenVerifyURL       = "https://api.cloudflare.com/client/v4/user/tokens/verify"
 	CloudflareAPITokenInfoURL         = "https://api.cloudflare.com/client/v4/user/tokens/%s" // requires tokenID
 	CloudflareAPIAccountURL           = "https://api.cloudflare.com/client/v4/accounts/%s"    // requires accountID
@@ -0,0 +1,124 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// UpdateDotenv sets keys in the dotenv file at path, keeping comments, the
+// order of the lines and every key it does not set. New keys are appended.
+// The file is locked while it is rewritten (see statestore.LockFile), so the
+// web UI and the setup wizard do not overwrite each other's edits.
+func UpdateDotenv(path string, updates map[string]string) error {
+	for key, value := range updates {
+		if !validDotenvKey(key) {
+			return fmt.Errorf("invalid variable name %q", key)
+		}
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s: value must be a single line", key)
+		}
+		if strings.Contains(value, `"`) && strings.Contains(value, "'") {
+			return fmt.Errorf("%s: value cannot contain both quote characters", key)
+		}
+	}
+
+	unlock, err := statestore.LockFile(path, statestore.DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+	done := make(map[string]bool, len(updates))
+	for i, line := range lines {
+		key, _, ok := parseEnvLine(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		value, update := updates[key]
+		if !ok || !update {
+			continue
+		}
+		lines[i] = key + "=" + quoteDotenv(value)
+		done[key] = true
+	}
+
+	var added []string
+	for key := range updates {
+		if !done[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		lines = append(lines, key+"="+quoteDotenv(updates[key]))
+	}
+
+	return writeFileAtomic(path, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// validDotenvKey reports whether key is a shell variable name.
+func validDotenvKey(key string) bool {
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		return false
+	}
+	for _, r := range key {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// quoteDotenv quotes values with spaces, quotes or a "#", which would
+// otherwise be read as the start of a comment. Values with a double quote
+// are single-quoted.
+func quoteDotenv(value string) string {
+	switch {
+	case !strings.ContainsAny(value, " \t#\"'"):
+		return value
+	case strings.Contains(value, `"`):
+		return "'" + value + "'"
+	}
+	return `"` + value + `"`
+}
+
+// writeFileAtomic replaces path with data through a temp file, keeping the
+// mode of an existing file (0600 for a new one, as .env holds secrets).
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	orig := "# Local settings\nexport PORT=8080\nAPI_TOKEN=old  # rotated monthly\n\nDEBUG=true\n"
+	if err := os.WriteFile(path, []byte(orig), 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	err := UpdateDotenv(path, map[string]string{
+		"API_TOKEN": "new",
+		"GREETING":  "hello world",
+		"MOTTO":     `say "hi"`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	want := "# Local settings\nexport PORT=8080\nAPI_TOKEN=new\n\nDEBUG=true\nGREETING=\"hello world\"\nMOTTO='say \"hi\"'\n"
+	if string(data) != want {
+		t.Errorf("updated .env:\n%s\nwant:\n%s", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0640 {
+		t.Errorf("mode = %v, want 0640 kept", info.Mode().Perm())
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Error("lock file left behind")
+	}
+
+	for _, bad := range []map[string]string{
+		{"1BAD": "x"},
+		{"BAD-NAME": "x"},
+		{"MULTI": "a\nb"},
+	} {
+		if err := UpdateDotenv(path, bad); err == nil {
+			t.Errorf("UpdateDotenv(%v) succeeded, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// Encrypted .env support.
+//
+// A .env.enc file holds the same KEY=value lines as .env, encrypted with age
+// (or sops using age recipients), so it can be committed and shared by a team.
+// xplat decrypts it in memory only - the plaintext is never written to disk
+// except during 'xplat env edit', which removes its temp file on exit, and
+// when re-encrypting a sops file on Windows, where sops cannot read stdin.
+
+const encryptedEnvFile = ".env.enc"
+
+// recipientsFile lists age public keys (one per line) that can decrypt .env.enc
+const recipientsFile = ".age-recipients"
+
+// KeychainService is the OS keychain entry holding the age identity
+const KeychainService = "xplat-age"
+
+// Environment variables that locate the age identity (shared with sops)
+const (
+	KeyAgeKey     = "SOPS_AGE_KEY"
+	KeyAgeKeyFile = "SOPS_AGE_KEY_FILE"
+)
+
+// GetEncryptedEnvPath returns the encrypted env file path next to .env
+func GetEncryptedEnvPath() string {
+	return filepath.Join(filepath.Dir(currentEnvFile), encryptedEnvFile)
+}
+
+// EncryptedEnvExists checks if .env.enc exists
+func EncryptedEnvExists() bool {
+	_, err := os.Stat(GetEncryptedEnvPath())
+	return err == nil
+}
+
+// AgeIdentity is an age private key and where it was found
+type AgeIdentity struct {
+	Key    string // AGE-SECRET-KEY-... contents (may hold several lines)
+	Source string // env, file path or keychain
+}
+
+// ResolveAgeIdentity finds the age identity, in order:
+// SOPS_AGE_KEY, SOPS_AGE_KEY_FILE, the OS keychain, then the sops default key file.
+func ResolveAgeIdentity() (*AgeIdentity, error) {
+	if key := os.Getenv(KeyAgeKey); key != "" {
+		return &AgeIdentity{Key: key, Source: KeyAgeKey}, nil
+	}
+
+	if path := os.Getenv(KeyAgeKeyFile); path != "" {
+		return readAgeKeyFile(path)
+	}
+
+	if key, err := readKeychain(); err == nil && key != "" {
+		return &AgeIdentity{Key: key, Source: "keychain:" + KeychainService}, nil
+	}
+
+	path := defaultAgeKeyFile()
+	if _, err := os.Stat(path); err == nil {
+		return readAgeKeyFile(path)
+	}
+
+	return nil, fmt.Errorf("no age key found (set %s, %s, store one in the keychain as %q, or create %s)",
+		KeyAgeKey, KeyAgeKeyFile, KeychainService, path)
+}
+
+func readAgeKeyFile(path string) (*AgeIdentity, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age key file: %w", err)
+	}
+	return &AgeIdentity{Key: string(data), Source: path}, nil
+}
+
+// defaultAgeKeyFile returns the sops default age key location
+func defaultAgeKeyFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil || runtime.GOOS == "darwin" {
+		// sops uses ~/.config on macOS too, not ~/Library/Application Support
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "sops", "age", "keys.txt")
+}
+
+// readKeychain reads the age identity from the OS keychain
+func readKeychain() (string, error) {
+	var out []byte
+	var err error
+
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = exec.Command("security", "find-generic-password", "-s", KeychainService, "-w").Output()
+	case "linux":
+		out, err = exec.Command("secret-tool", "lookup", "service", KeychainService).Output()
+	default:
+		return "", fmt.Errorf("keychain not supported on %s", runtime.GOOS)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// IsSOPSFile returns true if the data is a sops-encrypted dotenv file
+func IsSOPSFile(data []byte) bool {
+	return bytes.Contains(data, []byte("sops_version="))
+}
+
+// DecryptEnvFile decrypts an age or sops encrypted env file in memory
+func DecryptEnvFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	identity, err := ResolveAgeIdentity()
+	if err != nil {
+		return nil, err
+	}
+
+	if IsSOPSFile(data) {
+		// sops reads the identity from the environment
+		cmd := exec.Command("sops", "--decrypt", "--input-type", "dotenv", "--output-type", "dotenv", path)
+		cmd.Env = append(os.Environ(), KeyAgeKey+"="+identity.Key)
+		return runCrypt(cmd, nil)
+	}
+
+	// age reads the identity from stdin and the ciphertext from the file
+	cmd := exec.Command("age", "--decrypt", "--identity", "-", path)
+	return runCrypt(cmd, []byte(identity.Key))
+}
+
+// EncryptEnvFile encrypts plaintext env data to path for the given age recipients.
+// If path already holds a sops file it is re-encrypted with sops, so the
+// format the team chose is kept; otherwise age is used.
+func EncryptEnvFile(path string, plaintext []byte, recipients []string) error {
+	if len(recipients) == 0 {
+		return fmt.Errorf("no age recipients")
+	}
+
+	var out []byte
+	var err error
+	if existing, readErr := os.ReadFile(path); readErr == nil && IsSOPSFile(existing) {
+		out, err = encryptSOPS(path, plaintext, recipients)
+	} else {
+		args := []string{"--encrypt", "--armor"}
+		for _, r := range recipients {
+			args = append(args, "--recipient", r)
+		}
+		out, err = runCrypt(exec.Command("age", args...), plaintext)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Written atomically: 'xplat env encrypt' removes .env once this returns,
+	// so a torn .env.enc could be the only copy left
+	if err := statestore.WriteFile(path, out, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// encryptSOPS encrypts plaintext as a sops dotenv file for the age recipients.
+// sops only reads files, so the plaintext is passed as /dev/stdin, named
+// after path so .sops.yaml creation rules still match. Windows has no
+// /dev/stdin, so there it goes through a private temp file.
+func encryptSOPS(path string, plaintext []byte, recipients []string) ([]byte, error) {
+	args := []string{"--encrypt", "--input-type", "dotenv", "--output-type", "dotenv", "--age", strings.Join(recipients, ",")}
+	if runtime.GOOS != "windows" {
+		args = append(args, "--filename-override", path, "/dev/stdin")
+		return runCrypt(exec.Command("sops", args...), plaintext)
+	}
+
+	tmp, err := os.CreateTemp("", "xplat-sops-*.env")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := tmp.Write(plaintext); err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+
+	return runCrypt(exec.Command("sops", append(args, tmpPath)...), nil)
+}
+
+// LoadRecipients returns the age recipients for .env.enc.
+// Recipients come from .age-recipients; if it doesn't exist, the public key
+// of the local identity is used so the file can at least be read back.
+func LoadRecipients() ([]string, error) {
+	path := filepath.Join(filepath.Dir(currentEnvFile), recipientsFile)
+	if data, err := os.ReadFile(path); err == nil {
+		recipients := parseRecipients(data)
+		if len(recipients) == 0 {
+			return nil, fmt.Errorf("%s has no recipients", path)
+		}
+		return recipients, nil
+	}
+
+	identity, err := ResolveAgeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	out, err := runCrypt(exec.Command("age-keygen", "-y"), []byte(identity.Key))
+	if err != nil {
+		return nil, err
+	}
+	return parseRecipients(out), nil
+}
+
+// parseRecipients parses one recipient per line, ignoring blanks and comments
+func parseRecipients(data []byte) []string {
+	var recipients []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		recipients = append(recipients, line)
+	}
+	return recipients
+}
+
+// runCrypt runs an encryption CLI with optional stdin and returns stdout
+func runCrypt(cmd *exec.Cmd, stdin []byte) ([]byte, error) {
+	name := filepath.Base(cmd.Path)
+	if cmd.Err != nil {
+		return nil, fmt.Errorf("%s not found in PATH: %w", name, cmd.Err)
+	}
+
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// ParseEnvData parses KEY=value lines, stripping comments and surrounding quotes
+func ParseEnvData(data []byte) map[string]string {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := parseEnvLine(scanner.Text())
+		if !ok || strings.HasPrefix(key, "sops_") {
+			continue
+		}
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	return vars
+}
+
+// ApplyEncryptedEnv decrypts .env.enc in dir (if present) and sets its
+// variables in the process environment, so embedded Task and process-compose
+// see them. Variables already set in the environment win.
+// Returns the number of variables applied.
+func ApplyEncryptedEnv(dir string) (int, error) {
+	path := filepath.Join(dir, encryptedEnvFile)
+	if _, err := os.Stat(path); err != nil {
+		return 0, nil
+	}
+
+	data, err := DecryptEnvFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for key, value := range ParseEnvData(data) {
+		if _, exists := os.LookupEnv(key); exists {
+			continue
+		}
+		_ = os.Setenv(key, value)
+		applied++
+	}
+	return applied, nil
+}
+
+// loadEncryptedEnv loads the configuration from .env.enc
+func loadEncryptedEnv() (*EnvConfig, error) {
+	data, err := DecryptEnvFile(GetEncryptedEnvPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", encryptedEnvFile, err)
+	}
+
+	cfg := &EnvConfig{}
+	for key, value := range ParseEnvData(data) {
+		cfg.Set(key, value)
+	}
+	return cfg, nil
+}
+
+// LoadEnvValues returns every variable in .env, or in the decrypted .env.enc
+// when there is no .env, including keys EnvConfig has no field for.
+func LoadEnvValues() (map[string]string, error) {
+	data, err := os.ReadFile(currentEnvFile)
+	if os.IsNotExist(err) {
+		if !EncryptedEnvExists() {
+			return map[string]string{}, nil
+		}
+		if data, err = DecryptEnvFile(GetEncryptedEnvPath()); err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", encryptedEnvFile, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+	return ParseEnvData(data), nil
+}
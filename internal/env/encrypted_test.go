@@ -0,0 +1,49 @@
+package env
+
+import "testing"
+
+func TestParseEnvData(t *testing.T) {
+	data := []byte(`# Environment Configuration
+CLOUDFLARE_API_TOKEN=abc123  # Cloudflare API token
+QUOTED="hello world"
+SINGLE='x'
+EMPTY=
+sops_version=3.9.0
+sops_mac=ENC[AES256_GCM,data:xyz]
+not a line
+`)
+
+	got := ParseEnvData(data)
+	want := map[string]string{
+		"CLOUDFLARE_API_TOKEN": "abc123",
+		"QUOTED":               "hello world",
+		"SINGLE":               "x",
+		"EMPTY":                "",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ParseEnvData() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("ParseEnvData()[%q] = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestIsSOPSFile(t *testing.T) {
+	if !IsSOPSFile([]byte("KEY=ENC[AES256_GCM,data:abc]\nsops_version=3.9.0\n")) {
+		t.Error("expected sops dotenv file to be detected")
+	}
+	if IsSOPSFile([]byte("-----BEGIN AGE ENCRYPTED FILE-----\n")) {
+		t.Error("age armored file detected as sops")
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	data := []byte("# team\nage1abc\n\n  age1def  \n")
+	got := parseRecipients(data)
+	if len(got) != 2 || got[0] != "age1abc" || got[1] != "age1def" {
+		t.Errorf("parseRecipients() = %v", got)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+
+	"github.com/joeblew999/xplat/internal/statestore"
 )
 
 const envFile = ".env"
@@ -28,19 +31,43 @@ func GetTestEnvFile() string {
 	return envFileTest
 }
 
+// updateHook is called after Service writes fields (see SetUpdateHook)
+var updateHook func(keys []string, err error)
+
+// SetUpdateHook registers a function called after every Service write with
+// the keys written (never their values) and the write error, e.g. to audit
+// env edits made through the web UI. nil removes the hook.
+func SetUpdateHook(fn func(keys []string, err error)) {
+	updateHook = fn
+}
+
+// notifyUpdate calls the update hook with the sorted keys of fieldUpdates.
+func notifyUpdate(fieldUpdates map[string]string, err error) {
+	if updateHook == nil {
+		return
+	}
+	keys := make([]string, 0, len(fieldUpdates))
+	for key := range fieldUpdates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	updateHook(keys, err)
+}
+
 // Environment variable keys used throughout the codebase
 const (
-	KeyCloudflareAPIToken      = "CLOUDFLARE_API_TOKEN"
-	KeyCloudflareAPITokenName  = "CLOUDFLARE_API_TOKEN_NAME"
-	KeyCloudflareAccountID     = "CLOUDFLARE_ACCOUNT_ID"
-	KeyCloudflareDomain        = "CLOUDFLARE_DOMAIN"
-	KeyCloudflareZoneID        = "CLOUDFLARE_ZONE_ID"
-	KeyCloudflarePageProject   = "CLOUDFLARE_PAGE_PROJECT_NAME"
-	KeyCloudflareWorkerName    = "CLOUDFLARE_WORKER_NAME"
-	KeyCloudflareSyncEndpoint  = "CLOUDFLARE_SYNC_ENDPOINT"
-	KeyCloudflareReceiverPort  = "CLOUDFLARE_RECEIVER_PORT"
-	KeyClaudeAPIKey            = "CLAUDE_API_KEY"
-	KeyClaudeWorkspaceName     = "CLAUDE_WORKSPACE_NAME"
+	KeyCloudflareAPIToken       = "CLOUDFLARE_API_TOKEN"
+	KeyCloudflareAPITokenName   = "CLOUDFLARE_API_TOKEN_NAME"
+	KeyCloudflareAccountID      = "CLOUDFLARE_ACCOUNT_ID"
+	KeyCloudflareDomain         = "CLOUDFLARE_DOMAIN"
+	KeyCloudflareZoneID         = "CLOUDFLARE_ZONE_ID"
+	KeyCloudflarePageProject    = "CLOUDFLARE_PAGE_PROJECT_NAME"
+	KeyCloudflareWorkerName     = "CLOUDFLARE_WORKER_NAME"
+	KeyCloudflareSyncEndpoint   = "CLOUDFLARE_SYNC_ENDPOINT"
+	KeyCloudflareReceiverPort   = "CLOUDFLARE_RECEIVER_PORT"
+	KeyCloudflareTunnelProvider = "CLOUDFLARE_TUNNEL_PROVIDER"
+	KeyClaudeAPIKey             = "CLAUDE_API_KEY"
+	KeyClaudeWorkspaceName      = "CLAUDE_WORKSPACE_NAME"
 )
 
 // Placeholder values used in .env.example and validation
@@ -51,17 +78,18 @@ const (
 
 // EnvConfig holds environment configuration
 type EnvConfig struct {
-	CloudflareToken        string
-	CloudflareTokenName    string
-	CloudflareAccount      string
-	CloudflareDomain       string
-	CloudflareZoneID       string
-	CloudflareProject      string
-	CloudflareWorkerName   string
-	CloudflareSyncEndpoint string
-	CloudflareReceiverPort string
-	ClaudeAPIKey           string
-	ClaudeWorkspace        string
+	CloudflareToken          string
+	CloudflareTokenName      string
+	CloudflareAccount        string
+	CloudflareDomain         string
+	CloudflareZoneID         string
+	CloudflareProject        string
+	CloudflareWorkerName     string
+	CloudflareSyncEndpoint   string
+	CloudflareReceiverPort   string
+	CloudflareTunnelProvider string
+	ClaudeAPIKey             string
+	ClaudeWorkspace          string
 }
 
 // FieldInfo holds metadata about an environment variable field
@@ -85,6 +113,7 @@ var envFieldsInOrder = []FieldInfo{
 	{Key: KeyCloudflareWorkerName, Default: "xplat-sync", Description: "Cloudflare Worker name for event sync", DisplayName: "Cloudflare Worker Name", SyncToGitHub: false, Validate: false},
 	{Key: KeyCloudflareSyncEndpoint, Default: "", Description: "Sync endpoint URL (tunnel URL for event forwarding)", DisplayName: "Sync Endpoint URL", SyncToGitHub: false, Validate: false},
 	{Key: KeyCloudflareReceiverPort, Default: "9091", Description: "Local receiver port for sync events", DisplayName: "Receiver Port", SyncToGitHub: false, Validate: false},
+	{Key: KeyCloudflareTunnelProvider, Default: "cloudflared", Description: "Tunnel provider for the receiver (cloudflared, tailscale or ngrok)", DisplayName: "Tunnel Provider", SyncToGitHub: false, Validate: false},
 	{Key: KeyClaudeAPIKey, Default: "your-api-key-here", Description: "Claude API key (required for translation)", DisplayName: "Claude API Key", SyncToGitHub: false, Validate: true},
 	{Key: KeyClaudeWorkspaceName, Default: "", Description: "Claude workspace name", DisplayName: "Claude Workspace Name", SyncToGitHub: false, Validate: true},
 }
@@ -163,6 +192,8 @@ func (cfg *EnvConfig) Get(key string) string {
 		return cfg.CloudflareSyncEndpoint
 	case KeyCloudflareReceiverPort:
 		return cfg.CloudflareReceiverPort
+	case KeyCloudflareTunnelProvider:
+		return cfg.CloudflareTunnelProvider
 	case KeyClaudeAPIKey:
 		return cfg.ClaudeAPIKey
 	case KeyClaudeWorkspaceName:
@@ -192,6 +223,8 @@ func (cfg *EnvConfig) Set(key, value string) bool {
 		cfg.CloudflareSyncEndpoint = value
 	case KeyCloudflareReceiverPort:
 		cfg.CloudflareReceiverPort = value
+	case KeyCloudflareTunnelProvider:
+		cfg.CloudflareTunnelProvider = value
 	case KeyClaudeAPIKey:
 		cfg.ClaudeAPIKey = value
 	case KeyClaudeWorkspaceName:
@@ -236,6 +269,10 @@ func LoadEnv() (*EnvConfig, error) {
 	file, err := os.Open(currentEnvFile)
 	if err != nil {
 		if os.IsNotExist(err) {
+			// Fall back to the encrypted .env.enc (decrypted in memory only)
+			if EncryptedEnvExists() {
+				return loadEncryptedEnv()
+			}
 			return cfg, nil // Return empty config if file doesn't exist
 		}
 		return nil, fmt.Errorf("failed to open .env: %w", err)
@@ -322,8 +359,15 @@ func writeEnvLine(b *strings.Builder, key, value, description string) {
 	b.WriteString("\n")
 }
 
-// WriteEnv writes the complete configuration to .env
+// WriteEnv writes the complete configuration to .env. Variables it does not
+// manage (e.g. added in the web UI's Env tab) are kept after its own.
 func WriteEnv(cfg *EnvConfig) error {
+	unlock, err := statestore.LockFile(currentEnvFile, statestore.DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	var content strings.Builder
 
 	writeEnvHeader(&content)
@@ -340,6 +384,13 @@ func WriteEnv(cfg *EnvConfig) error {
 		writeEnvLine(&content, field.Key, value, field.Description)
 	}
 
+	if other := otherEnvLines(currentEnvFile); len(other) > 0 {
+		content.WriteString("\n")
+		for _, line := range other {
+			content.WriteString(line + "\n")
+		}
+	}
+
 	if err := os.WriteFile(currentEnvFile, []byte(content.String()), 0600); err != nil {
 		return fmt.Errorf("failed to write .env: %w", err)
 	}
@@ -347,6 +398,23 @@ func WriteEnv(cfg *EnvConfig) error {
 	return nil
 }
 
+// otherEnvLines returns the KEY=value lines of the env file at path whose
+// keys are not in envFieldsInOrder.
+func otherEnvLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, _, ok := parseEnvLine(strings.TrimPrefix(strings.TrimSpace(line), "export "))
+		if ok && GetFieldInfo(key) == nil {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return lines
+}
+
 // EnvExists checks if .env file exists
 func EnvExists() bool {
 	_, err := os.Stat(currentEnvFile)
@@ -465,7 +533,9 @@ func (s *Service) ValidateAndUpdateFields(fieldUpdates map[string]string) (map[s
 
 	// All valid - perform atomic update
 	// UpdateEnvPartial reloads from disk, so no stale data issues
-	if err := UpdateEnvPartial(updateCfg); err != nil {
+	err = UpdateEnvPartial(updateCfg)
+	notifyUpdate(fieldUpdates, err)
+	if err != nil {
 		return results, err
 	}
 
@@ -480,7 +550,9 @@ func (s *Service) UpdateFields(fieldUpdates map[string]string) error {
 		updateCfg.Set(key, value)
 	}
 
-	return UpdateEnvPartial(updateCfg)
+	err := UpdateEnvPartial(updateCfg)
+	notifyUpdate(fieldUpdates, err)
+	return err
 }
 
 // ResultsToSlice converts map results to slice format for legacy functions
@@ -15,10 +15,10 @@ func RenderNavigation(currentPage string) h.H {
 		if currentPage == page {
 			return h.Li(h.Strong(h.Text(label)))
 		}
-		return h.Li(h.A(h.Href(href), h.Text(label)))
+		return h.Li(h.A(h.Href(link(href)), h.Text(label)))
 	}
 
-	return h.Nav(
+	nav := h.Nav(
 		h.Ul(
 			navItem("home", "Overview", "/"),
 			navItem("cloudflare", "Cloudflare", "/cloudflare"),
@@ -26,6 +26,12 @@ func RenderNavigation(currentPage string) h.H {
 			navItem("deploy", "Deploy", "/deploy"),
 		),
 	)
+
+	// Mounted in another app: show its header above the wizard navigation
+	if mount.Header != nil {
+		return h.Div(mount.Header(), nav)
+	}
+	return nav
 }
 
 // BuildCloudflareURL builds a Cloudflare dashboard URL, replacing :account placeholder with actual account ID when available
@@ -220,7 +220,7 @@ func RenderPrerequisiteError(missing []PrerequisiteCheck) h.H {
 		listItems = append(listItems, h.Li(
 			h.Text(item.DisplayName+" - "),
 			h.A(
-				h.Href(item.StepPath),
+				h.Href(link(item.StepPath)),
 				h.Text(item.StepLabel),
 			),
 		))
@@ -123,14 +123,14 @@ func cloudflarePage(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 							buttonText = fmt.Sprintf("▶️ Continue Setup (Step %d)", firstIncompleteStep.StepNumber)
 						}
 						return h.A(
-							h.Href(firstIncompleteStep.Path),
+							h.Href(link(firstIncompleteStep.Path)),
 							h.Attr("role", "button"),
 							h.Text(buttonText),
 						)
 					}
 					if completedSteps == totalSteps {
 						return h.A(
-							h.Href("/deploy"),
+							h.Href(link("/deploy")),
 							h.Attr("role", "button"),
 							h.Attr("class", "contrast"),
 							h.Text("✅ Setup Complete - Go to Deploy"),
@@ -139,7 +139,7 @@ func cloudflarePage(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 					return h.Text("")
 				}(),
 				h.A(
-					h.Href(Step1Info.Path),
+					h.Href(link(Step1Info.Path)),
 					h.Attr("role", "button"),
 					h.Attr("class", "secondary outline"),
 					h.Text("Start from Step 1"),
@@ -53,7 +53,7 @@ func cloudflareStep1Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 		// Validation passed - redirect to step 2
 		saveMessage.SetValue("success:Token validated! Moving to step 2...")
 		c.Sync()
-		c.ExecScript("window.location.href = '/cloudflare/step2'")
+		c.ExecScript("window.location.href = '" + link("/cloudflare/step2") + "'")
 	})
 
 	c.View(func() h.H {
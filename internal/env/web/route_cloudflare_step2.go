@@ -63,7 +63,7 @@ func cloudflareStep2Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 		// Validation passed - redirect to step 3
 		saveMessage.SetValue("success:Account ID validated! Moving to step 3...")
 		c.Sync()
-		c.ExecScript("window.location.href = '/cloudflare/step3'")
+		c.ExecScript("window.location.href = '" + link("/cloudflare/step3") + "'")
 	})
 
 	c.View(func() h.H {
@@ -132,7 +132,7 @@ func cloudflareStep3Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 		// Success - redirect to step 4
 		saveMessage.SetValue("success:Domain selected! Moving to step 4...")
 		c.Sync()
-		c.ExecScript("window.location.href = '/cloudflare/step4'")
+		c.ExecScript("window.location.href = '" + link("/cloudflare/step4") + "'")
 	})
 
 	c.View(func() h.H {
@@ -111,6 +111,7 @@ func cloudflareStep4Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 
 		// Call delete API with automatic custom domain cleanup
 		removedDomains, err := env.DeletePagesProjectWithCleanup(token, accountID, projectName)
+		audit("pages.project.delete", projectName, err)
 		if err != nil {
 			deleteMessage.SetValue("error:Failed to delete project: " + err.Error())
 			c.Sync()
@@ -147,6 +148,7 @@ func cloudflareStep4Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 		c.Sync()
 
 		result := env.CreatePagesProject(projectName, mockMode)
+		audit("pages.project.create", projectName, result.Error)
 
 		createInProgress.SetValue(false)
 		if result.Error != nil {
@@ -193,7 +195,7 @@ func cloudflareStep4Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 		// Success! Redirect to Step 5 (Custom Domain Setup)
 		saveMessage.SetValue("success:✅ Configuration saved successfully!")
 		c.Sync()
-		c.ExecScript("window.location.href = '/cloudflare/step5'")
+		c.ExecScript("window.location.href = '" + link("/cloudflare/step5") + "'")
 	})
 
 	c.View(func() h.H {
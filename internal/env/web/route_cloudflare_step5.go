@@ -120,6 +120,7 @@ func cloudflareStep5Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 
 		// Add domain via Cloudflare Pages API
 		err := env.AddPagesDomain(apiToken, accountID, projectName, customDomain)
+		audit("pages.domain.attach", projectName+"/"+customDomain, err)
 
 		isAttaching.SetValue(false)
 		if err != nil {
@@ -170,6 +171,7 @@ func cloudflareStep5Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 				c.Sync()
 
 				err := env.DeletePagesDomain(apiToken, accountID, projectName, domainName)
+				audit("pages.domain.remove", projectName+"/"+domainName, err)
 
 				isRemoving.SetValue(false)
 				if err != nil {
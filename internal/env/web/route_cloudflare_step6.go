@@ -6,6 +6,7 @@ import (
 	"github.com/go-via/via"
 	"github.com/go-via/via/h"
 	"github.com/joeblew999/xplat/internal/env"
+	"github.com/joeblew999/xplat/internal/synccf"
 )
 
 // Step6Info is the metadata for Step 6 - single source of truth
@@ -18,6 +19,7 @@ var Step6Info = WizardStepInfo{
 		env.KeyCloudflareWorkerName,
 		env.KeyCloudflareSyncEndpoint,
 		env.KeyCloudflareReceiverPort,
+		env.KeyCloudflareTunnelProvider,
 	},
 	Prerequisites: []PrerequisiteCheck{
 		{
@@ -58,6 +60,10 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 	if receiverPort == "" {
 		receiverPort = "9091"
 	}
+	tunnelProvider := cfg.Get(env.KeyCloudflareTunnelProvider)
+	if tunnelProvider == "" {
+		tunnelProvider = synccf.ProviderCloudflared
+	}
 
 	// Signals for UI state
 	status := c.Signal("")
@@ -69,17 +75,20 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 	// Editable field signals
 	workerNameSignal := c.Signal(workerName)
 	receiverPortSignal := c.Signal(receiverPort)
+	tunnelProviderSignal := c.Signal(tunnelProvider)
 
 	// Save configuration action
 	saveConfigAction := c.Action(func() {
 		newWorkerName := workerNameSignal.String()
 		newReceiverPort := receiverPortSignal.String()
+		newTunnelProvider := tunnelProviderSignal.String()
 
 		// Update config
 		svc := env.NewService(mockMode)
 		err := svc.UpdateFields(map[string]string{
-			env.KeyCloudflareWorkerName:   newWorkerName,
-			env.KeyCloudflareReceiverPort: newReceiverPort,
+			env.KeyCloudflareWorkerName:     newWorkerName,
+			env.KeyCloudflareReceiverPort:   newReceiverPort,
+			env.KeyCloudflareTunnelProvider: newTunnelProvider,
 		})
 
 		if err != nil {
@@ -143,8 +152,10 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 			port = "9091"
 		}
 
+		provider := tunnelProviderSignal.String()
+
 		isStartingTunnel.SetValue(true)
-		status.SetValue("info:Starting cloudflared tunnel for port " + port + "...")
+		status.SetValue("info:Starting " + provider + " tunnel for port " + port + "...")
 		c.Sync()
 
 		// The tunnel runs as a background service
@@ -158,7 +169,7 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 			return
 		}
 
-		status.SetValue("info:Start tunnel with: xplat sync-cf tunnel " + port)
+		status.SetValue("info:Start tunnel with: " + tunnelCommand(port, provider))
 		c.Sync()
 	})
 
@@ -197,7 +208,7 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 					),
 					h.Li(
 						h.Strong(h.Text("Start Tunnel: ")),
-						h.Text("A cloudflared tunnel connects the Worker to your local receiver"),
+						h.Text("A tunnel (cloudflared, Tailscale Funnel or ngrok) connects the Worker to your local receiver"),
 					),
 					h.Li(
 						h.Strong(h.Text("Validation: ")),
@@ -224,8 +235,8 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
         | SYNC_ENDPOINT (tunnel URL)
         v
 +------------------+
-| cloudflared      |  <-- Quick tunnel (random URL) or named tunnel
-| tunnel           |
+| tunnel           |  <-- cloudflared, tailscale or ngrok
+| provider         |
 +------------------+
         |
         v
@@ -277,6 +288,15 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 				),
 				h.Small(h.Text("Local port for the event receiver server")),
 
+				// Tunnel Provider
+				h.Label(
+					h.Attr("for", "tunnelProvider"),
+					h.Style("margin-top: 1rem;"),
+					h.Text("Tunnel Provider"),
+				),
+				h.Select(tunnelProviderOptions(tunnelProviderSignal.String(), tunnelProviderSignal.Bind())...),
+				h.Small(h.Text("Use tailscale or ngrok if your network blocks cloudflared")),
+
 				// Tunnel URL (read-only, shows when tunnel is active)
 				h.If(tunnelURL.String() != "",
 					h.Div(
@@ -356,7 +376,7 @@ func cloudflareStep6Page(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 				h.Div(
 					h.Style("display: grid; grid-template-columns: 2fr 1fr; gap: 1rem; align-items: center; margin-bottom: 1rem; padding: 1rem; border: 1px solid var(--pico-muted-border-color); border-radius: 0.25rem;"),
 					h.Div(
-						h.Strong(h.Text("C. Start cloudflared Tunnel")),
+						h.Strong(h.Text("C. Start " + tunnelProviderSignal.String() + " Tunnel")),
 						h.Br(),
 						h.Small(h.Text("Create a tunnel to expose the receiver to the Worker")),
 					),
@@ -390,9 +410,9 @@ xplat sync-cf worker deploy
 xplat sync-cf receive --port=`+receiverPortSignal.String()+` --invalidate
 
 # 3. Start the tunnel (in another terminal)
-xplat sync-cf tunnel `+receiverPortSignal.String()+`
+`+tunnelCommand(receiverPortSignal.String(), tunnelProviderSignal.String())+`
 
-# 4. Copy the tunnel URL and set SYNC_ENDPOINT in Worker`),
+# 4. Set SYNC_ENDPOINT in Worker to the saved CLOUDFLARE_SYNC_ENDPOINT`),
 					),
 				),
 			),
@@ -481,3 +501,29 @@ xplat sync-cf tunnel `+receiverPortSignal.String()+`
 		)
 	})
 }
+
+// tunnelCommand returns the CLI command that starts the tunnel for port and
+// records its URL as the sync endpoint.
+func tunnelCommand(port, provider string) string {
+	cmd := "xplat sync-cf tunnel " + port
+	if provider != "" && provider != synccf.ProviderCloudflared {
+		cmd += " --provider=" + provider
+	}
+	return cmd + " --save-endpoint"
+}
+
+// tunnelProviderOptions builds the tunnel provider select.
+func tunnelProviderOptions(selected string, bind h.H) []h.H {
+	opts := []h.H{
+		h.ID("tunnelProvider"),
+		bind,
+	}
+	for _, name := range synccf.Providers {
+		opt := []h.H{h.Attr("value", name), h.Text(name)}
+		if name == selected {
+			opt = append(opt, h.Attr("selected", "selected"))
+		}
+		opts = append(opts, h.Option(opt...))
+	}
+	return opts
+}
@@ -53,6 +53,7 @@ func deployPage(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 
 		// Run build and deploy (no branch = preview only)
 		result := env.BuildAndDeploy(currentProject, "", mockMode)
+		audit("pages.deploy.preview", currentProject, result.Error)
 
 		previewInProgress.SetValue(false)
 		if result.Error != nil {
@@ -97,6 +98,7 @@ func deployPage(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 
 		// Run build and deploy (branch=main = production)
 		result := env.BuildAndDeploy(currentProject, "main", mockMode)
+		audit("pages.deploy.production", currentProject, result.Error)
 
 		productionInProgress.SetValue(false)
 		if result.Error != nil {
@@ -273,7 +275,7 @@ func deployPage(c *via.Context, cfg *env.EnvConfig, mockMode bool) {
 
 			h.Div(
 				h.Style("margin-top: 2rem;"),
-				h.A(h.Href("/"), h.Text("← Back to Overview")),
+				h.A(h.Href(link("/")), h.Text("← Back to Overview")),
 			),
 		)
 	})
@@ -239,7 +239,7 @@ func renderProgressCard(title string, completed, total int, href, buttonText str
 			h.Style("display: flex; justify-content: space-between; align-items: center; gap: 1rem;"),
 			statusBadge,
 			h.A(
-				h.Href(href),
+				h.Href(link(href)),
 				h.Attr("role", "button"),
 				h.Attr("class", "outline"),
 				h.Text(buttonText),
@@ -4,10 +4,12 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/go-via/via"
 	"github.com/go-via/via-plugin-picocss/picocss"
+	"github.com/go-via/via/h"
 
 	"github.com/joeblew999/xplat/internal/config"
 	"github.com/joeblew999/xplat/internal/env"
@@ -109,6 +111,66 @@ func serveSetupGUIWithOptions(mockMode bool) {
 		LogLvl:  via.LogLevelWarn, // Reduce noise from benign SSE race conditions
 	})
 
+	RegisterRoutes(v, MountOptions{MockMode: mockMode})
+
+	v.Start()
+}
+
+// MountOptions configures where and how RegisterRoutes mounts the wizard.
+type MountOptions struct {
+	// Prefix mounts the wizard under a path (e.g. "/setup"); empty for the root
+	Prefix string
+
+	// BasePath is prepended to links but not to routes, for an app served
+	// by a proxy that strips that path (e.g. "/xplat" behind Caddy)
+	BasePath string
+
+	// MockMode skips real API validation
+	MockMode bool
+
+	// Header is rendered above the wizard navigation on every page
+	// (e.g. the tabs of the app the wizard is mounted in)
+	Header func() h.H
+
+	// Audit, if set, is called after each action that changes Cloudflare
+	// or deploys, e.g. audit("pages.project.delete", "my-site", err)
+	Audit func(action, target string, err error)
+}
+
+// mount is the active mount, read by route and RenderNavigation
+var mount MountOptions
+
+// audit reports an action to the mount's Audit hook.
+func audit(action, target string, err error) {
+	if mount.Audit != nil {
+		mount.Audit(action, target, err)
+	}
+}
+
+// route returns a wizard path under the mount prefix.
+func route(path string) string {
+	if mount.Prefix == "" {
+		return path
+	}
+	if path == "/" {
+		return mount.Prefix
+	}
+	return mount.Prefix + path
+}
+
+// link returns the href of a wizard path: route(path) under the mount's BasePath.
+func link(path string) string {
+	return mount.BasePath + route(path)
+}
+
+// RegisterRoutes registers the wizard pages on a Via instance.
+// The standalone wizard mounts at the root; the unified xplat UI mounts it
+// under /setup so both share one server.
+func RegisterRoutes(v *via.V, opts MountOptions) {
+	opts.Prefix = strings.TrimSuffix(opts.Prefix, "/")
+	mount = opts
+	mockMode := opts.MockMode
+
 	// Helper to load fresh config for each page request
 	loadConfig := func() *env.EnvConfig {
 		svc := env.NewService(mockMode)
@@ -121,46 +183,44 @@ func serveSetupGUIWithOptions(mockMode bool) {
 	}
 
 	// Register routes - each loads fresh config
-	v.Page("/", func(c *via.Context) {
+	v.Page(route("/"), func(c *via.Context) {
 		homePage(c, loadConfig(), mockMode)
 	})
 
 	// Cloudflare setup wizard - 6 steps
-	v.Page("/cloudflare", func(c *via.Context) {
+	v.Page(route("/cloudflare"), func(c *via.Context) {
 		cloudflarePage(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/cloudflare/step1", func(c *via.Context) {
+	v.Page(route("/cloudflare/step1"), func(c *via.Context) {
 		cloudflareStep1Page(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/cloudflare/step2", func(c *via.Context) {
+	v.Page(route("/cloudflare/step2"), func(c *via.Context) {
 		cloudflareStep2Page(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/cloudflare/step3", func(c *via.Context) {
+	v.Page(route("/cloudflare/step3"), func(c *via.Context) {
 		cloudflareStep3Page(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/cloudflare/step4", func(c *via.Context) {
+	v.Page(route("/cloudflare/step4"), func(c *via.Context) {
 		cloudflareStep4Page(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/cloudflare/step5", func(c *via.Context) {
+	v.Page(route("/cloudflare/step5"), func(c *via.Context) {
 		cloudflareStep5Page(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/cloudflare/step6", func(c *via.Context) {
+	v.Page(route("/cloudflare/step6"), func(c *via.Context) {
 		cloudflareStep6Page(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/claude", func(c *via.Context) {
+	v.Page(route("/claude"), func(c *via.Context) {
 		claudePage(c, loadConfig(), mockMode)
 	})
 
-	v.Page("/deploy", func(c *via.Context) {
+	v.Page(route("/deploy"), func(c *via.Context) {
 		deployPage(c, loadConfig(), mockMode)
 	})
-
-	v.Start()
 }
@@ -26,7 +26,7 @@ func RenderWizardNavigation(registry *WizardRegistry, currentStep *WizardStepInf
 	// Back button
 	if prevStep != nil {
 		elements = append(elements,
-			h.A(h.Href(prevStep.Path), h.Text("← Back: "+prevStep.Title)),
+			h.A(h.Href(link(prevStep.Path)), h.Text("← Back: "+prevStep.Title)),
 			h.Text(" "),
 		)
 	}
@@ -41,18 +41,18 @@ func RenderWizardNavigation(registry *WizardRegistry, currentStep *WizardStepInf
 				elements = append(elements,
 					h.Button(h.Text("Next: "+nextStep.Title+" →"), action.OnClick()),
 					h.Text(" or "),
-					h.A(h.Href(nextStep.Path), h.Text("Skip")),
+					h.A(h.Href(link(nextStep.Path)), h.Text("Skip")),
 				)
 			} else {
 				// Fallback to direct link if type assertion fails
 				elements = append(elements,
-					h.A(h.Href(nextStep.Path), h.Attr("role", "button"), h.Text("Next: "+nextStep.Title+" →")),
+					h.A(h.Href(link(nextStep.Path)), h.Attr("role", "button"), h.Text("Next: "+nextStep.Title+" →")),
 				)
 			}
 		} else {
 			// Direct link navigation
 			elements = append(elements,
-				h.A(h.Href(nextStep.Path), h.Attr("role", "button"), h.Text("Next: "+nextStep.Title+" →")),
+				h.A(h.Href(link(nextStep.Path)), h.Attr("role", "button"), h.Text("Next: "+nextStep.Title+" →")),
 			)
 		}
 	} else {
@@ -88,7 +88,7 @@ func RenderWizardBreadcrumbs(registry *WizardRegistry, cfg *env.EnvConfig, curre
 		} else {
 			// Other steps - clickable link
 			itemContent = h.A(
-				h.Href(step.Path),
+				h.Href(link(step.Path)),
 				h.Text(fmt.Sprintf("%d. %s", step.StepNumber, step.Title)),
 			)
 		}
@@ -204,7 +204,7 @@ func RenderStepCard(step *WizardStepInfo, status StepStatus) h.H {
 					h.Text(statusText),
 				),
 				h.A(
-					h.Href(step.Path),
+					h.Href(link(step.Path)),
 					h.Attr("role", "button"),
 					h.Attr("class", "outline"),
 					h.Text("Configure"),
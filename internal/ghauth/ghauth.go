@@ -0,0 +1,263 @@
+// Package ghauth resolves GitHub credentials for API calls, authenticated
+// clones and private raw fetches: GITHUB_TOKEN or GH_TOKEN, the gh CLI
+// config, or a GitHub App installation token.
+//
+// It is a leaf package, so loaders and clients anywhere in xplat can
+// authenticate without importing syncgh.
+package ghauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+	"gopkg.in/yaml.v3"
+)
+
+// GitHub App credentials, used when no token is set.
+const (
+	EnvAppID             = "GITHUB_APP_ID"
+	EnvAppInstallationID = "GITHUB_APP_INSTALLATION_ID"
+	EnvAppPrivateKey     = "GITHUB_APP_PRIVATE_KEY"      // PEM contents
+	EnvAppPrivateKeyPath = "GITHUB_APP_PRIVATE_KEY_PATH" // Or a PEM file
+)
+
+// Credential is a GitHub token and where it came from.
+type Credential struct {
+	Token   string
+	Source  string    // GITHUB_TOKEN, GH_TOKEN, gh config or GitHub App
+	Expires time.Time // Zero unless the token is short-lived
+}
+
+// CredentialChain resolves a GitHub token without git credential helpers:
+//
+//  1. GITHUB_TOKEN, then GH_TOKEN
+//  2. The gh CLI config (hosts.yml oauth_token for github.com)
+//  3. A GitHub App installation token (GITHUB_APP_ID,
+//     GITHUB_APP_INSTALLATION_ID and GITHUB_APP_PRIVATE_KEY or _PATH)
+//
+// The same chain serves API calls, authenticated clones and private raw fetches.
+type CredentialChain struct {
+	// GhConfigDir overrides where the gh config is read from
+	GhConfigDir string
+
+	// APIBaseURL overrides the API used for GitHub App tokens (GitHub Enterprise or tests)
+	APIBaseURL string
+
+	now func() time.Time
+}
+
+// ResolveCredential resolves a token with the default chain.
+// It returns nil, nil when no credential is configured.
+func ResolveCredential(ctx context.Context) (*Credential, error) {
+	return (&CredentialChain{}).Resolve(ctx)
+}
+
+// Token returns the default chain's token, or "" when no credential is
+// configured.
+func Token(ctx context.Context) (string, error) {
+	cred, err := ResolveCredential(ctx)
+	if err != nil || cred == nil {
+		return "", err
+	}
+	return cred.Token, nil
+}
+
+// ErrNoCredential is returned by RequireToken when no credential is configured.
+var ErrNoCredential = errors.New("no GitHub credential: set GITHUB_TOKEN or GH_TOKEN, run 'gh auth login', or configure a GitHub App (" + EnvAppID + ")")
+
+// RequireToken is Token for callers that cannot work unauthenticated.
+func RequireToken(ctx context.Context) (string, error) {
+	token, err := Token(ctx)
+	if err == nil && token == "" {
+		err = ErrNoCredential
+	}
+	return token, err
+}
+
+// Resolve returns the first credential found, or nil, nil when there is none.
+// A GitHub App that is configured but fails to mint a token is an error.
+func (c *CredentialChain) Resolve(ctx context.Context) (*Credential, error) {
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token := os.Getenv(env); token != "" {
+			return &Credential{Token: token, Source: env}, nil
+		}
+	}
+
+	if token := c.ghConfigToken(); token != "" {
+		return &Credential{Token: token, Source: "gh config"}, nil
+	}
+
+	if os.Getenv(EnvAppID) != "" {
+		return c.appCredential(ctx)
+	}
+	return nil, nil
+}
+
+// ghConfigDir returns the gh CLI config dir, following gh's own lookup.
+func (c *CredentialChain) ghConfigDir() string {
+	if c.GhConfigDir != "" {
+		return c.GhConfigDir
+	}
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
+
+// ghConfigToken reads the github.com token from the gh hosts.yml. Tokens gh
+// keeps in the system keyring are not visible here.
+func (c *CredentialChain) ghConfigToken() string {
+	dir := c.ghConfigDir()
+	if dir == "" {
+		return ""
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "hosts.yml"))
+	if err != nil {
+		return ""
+	}
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return ""
+	}
+	return hosts["github.com"].OAuthToken
+}
+
+// appCredential mints an installation token for the configured GitHub App.
+func (c *CredentialChain) appCredential(ctx context.Context) (*Credential, error) {
+	appID := os.Getenv(EnvAppID)
+	installationID, err := strconv.ParseInt(os.Getenv(EnvAppInstallationID), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s is set but %s is missing or invalid", EnvAppID, EnvAppInstallationID)
+	}
+
+	keyPEM := []byte(os.Getenv(EnvAppPrivateKey))
+	if len(keyPEM) == 0 {
+		path := os.Getenv(EnvAppPrivateKeyPath)
+		if path == "" {
+			return nil, fmt.Errorf("%s is set but neither %s nor %s is", EnvAppID, EnvAppPrivateKey, EnvAppPrivateKeyPath)
+		}
+		if keyPEM, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+		}
+	}
+	key, err := parseRSAPrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+	jwt, err := appJWT(appID, key, now())
+	if err != nil {
+		return nil, err
+	}
+
+	client := github.NewClient(nil).WithAuthToken(jwt)
+	if c.APIBaseURL != "" {
+		base := strings.TrimSuffix(c.APIBaseURL, "/") + "/"
+		u, err := url.Parse(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid API base URL: %w", err)
+		}
+		client.BaseURL = u
+	}
+	token, _, err := client.Apps.CreateInstallationToken(ctx, installationID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+	return &Credential{Token: token.GetToken(), Source: "GitHub App", Expires: token.GetExpiresAt().Time}, nil
+}
+
+// parseRSAPrivateKey parses a PKCS#1 or PKCS#8 PEM key, as GitHub issues PKCS#1.
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// appJWT returns the RS256 JWT a GitHub App authenticates with. It is
+// backdated a minute for clock drift and valid for the 10 minute maximum.
+func appJWT(appID string, key *rsa.PrivateKey, now time.Time) (string, error) {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"RS256","typ":"JWT"}`))
+	// The issuer is the numeric app ID, or the app's client ID
+	var iss interface{} = appID
+	if id, err := strconv.ParseInt(appID, 10, 64); err == nil {
+		iss = id
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": iss,
+	})
+	if err != nil {
+		return "", err
+	}
+	signed := header + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign GitHub App JWT: %w", err)
+	}
+	return signed + "." + enc.EncodeToString(sig), nil
+}
+
+// IsGitHubURL reports whether a URL is served by GitHub, so a GitHub token
+// may be sent to it. Tokens are never sent over plain HTTP.
+func IsGitHubURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "github.com", "api.github.com", "raw.githubusercontent.com", "codeload.github.com", "objects.githubusercontent.com":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package ghauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearCredentialEnv unsets every credential source for a test.
+func clearCredentialEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{"GITHUB_TOKEN", "GH_TOKEN", EnvAppID, EnvAppInstallationID, EnvAppPrivateKey, EnvAppPrivateKeyPath} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestCredentialChainOrder(t *testing.T) {
+	clearCredentialEnv(t)
+	ghDir := t.TempDir()
+	chain := &CredentialChain{GhConfigDir: ghDir}
+
+	cred, err := chain.Resolve(context.Background())
+	if err != nil || cred != nil {
+		t.Fatalf("no sources: got %+v, %v", cred, err)
+	}
+
+	hosts := "github.com:\n    user: octocat\n    oauth_token: gho_config\n    git_protocol: https\n"
+	if err := os.WriteFile(filepath.Join(ghDir, "hosts.yml"), []byte(hosts), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if cred, _ := chain.Resolve(context.Background()); cred == nil || cred.Token != "gho_config" || cred.Source != "gh config" {
+		t.Errorf("gh config: got %+v", cred)
+	}
+
+	t.Setenv("GH_TOKEN", "gh_env")
+	if cred, _ := chain.Resolve(context.Background()); cred == nil || cred.Source != "GH_TOKEN" {
+		t.Errorf("GH_TOKEN: got %+v", cred)
+	}
+
+	t.Setenv("GITHUB_TOKEN", "github_env")
+	if cred, _ := chain.Resolve(context.Background()); cred == nil || cred.Token != "github_env" {
+		t.Errorf("GITHUB_TOKEN: got %+v", cred)
+	}
+}
+
+func TestCredentialChainGitHubApp(t *testing.T) {
+	clearCredentialEnv(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		jwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			t.Fatalf("malformed JWT %q", jwt)
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+			t.Errorf("JWT signature: %v", err)
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		_ = json.Unmarshal(payload, &claims)
+		if claims["iss"] != float64(1234) || claims["iat"] != float64(now.Add(-time.Minute).Unix()) {
+			t.Errorf("claims = %v", claims)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":"ghs_install","expires_at":"2026-10-16T11:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv(EnvAppID, "1234")
+	chain := &CredentialChain{GhConfigDir: t.TempDir(), APIBaseURL: srv.URL, now: func() time.Time { return now }}
+	if _, err := chain.Resolve(context.Background()); err == nil {
+		t.Error("expected an error without an installation ID")
+	}
+
+	t.Setenv(EnvAppInstallationID, "42")
+	t.Setenv(EnvAppPrivateKeyPath, keyPath)
+	cred, err := chain.Resolve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cred.Token != "ghs_install" || cred.Source != "GitHub App" || !cred.Expires.Equal(now.Add(time.Hour)) {
+		t.Errorf("got %+v", cred)
+	}
+}
+
+func TestIsGitHubURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://github.com/joeblew999/xplat":                   true,
+		"https://raw.githubusercontent.com/o/r/main/xplat.yaml": true,
+		"https://API.GitHub.com/repos/o/r":                      true,
+		"http://github.com/o/r":                                 false,
+		"https://github.com.evil.example/o/r":                   false,
+		"https://gitlab.com/o/r":                                false,
+		"git@github.com:o/r.git":                                false,
+	}
+	for url, want := range tests {
+		if got := IsGitHubURL(url); got != want {
+			t.Errorf("IsGitHubURL(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
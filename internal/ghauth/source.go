@@ -0,0 +1,117 @@
+package ghauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenSource returns the GitHub token to send with a request, or "" to
+// send the request unauthenticated.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a TokenSource for a fixed token ("" for none).
+type StaticToken string
+
+// Token returns the fixed token.
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// refreshBefore is how long before a credential expires it is re-minted, so
+// a request in flight never carries a token that lapses mid-call.
+const refreshBefore = 5 * time.Minute
+
+// CachedSource resolves a credential once and keeps it until shortly before
+// it expires. GitHub App installation tokens last an hour, so long-running
+// pollers and receivers must use a CachedSource rather than a token string.
+type CachedSource struct {
+	chain *CredentialChain
+
+	mu   sync.Mutex
+	cred *Credential
+	done bool // Resolved at least once (cred may be nil: no credential)
+}
+
+// NewCachedSource returns a source backed by chain.
+func NewCachedSource(chain *CredentialChain) *CachedSource {
+	return &CachedSource{chain: chain}
+}
+
+// DefaultSource returns a cached source backed by the default chain.
+func DefaultSource() *CachedSource {
+	return NewCachedSource(&CredentialChain{})
+}
+
+// Credential returns the cached credential, resolving it again when it is
+// about to expire. It returns nil, nil when no credential is configured.
+func (s *CachedSource) Credential(ctx context.Context) (*Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done && !s.expiring() {
+		return s.cred, nil
+	}
+	cred, err := s.chain.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.cred, s.done = cred, true
+	return cred, nil
+}
+
+// expiring reports whether the cached credential needs re-minting.
+func (s *CachedSource) expiring() bool {
+	if s.cred == nil || s.cred.Expires.IsZero() {
+		return false
+	}
+	now := time.Now
+	if s.chain.now != nil {
+		now = s.chain.now
+	}
+	return !now().Add(refreshBefore).Before(s.cred.Expires)
+}
+
+// Token returns the current token, or "" when no credential is configured.
+func (s *CachedSource) Token(ctx context.Context) (string, error) {
+	cred, err := s.Credential(ctx)
+	if err != nil || cred == nil {
+		return "", err
+	}
+	return cred.Token, nil
+}
+
+// Transport is an http.RoundTripper that authenticates each request with
+// the current token from Source.
+type Transport struct {
+	Source TokenSource
+	Base   http.RoundTripper // http.DefaultTransport when nil
+}
+
+// RoundTrip adds the Authorization header and sends the request.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve GitHub credentials: %w", err)
+	}
+	if token == "" {
+		return base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return base.RoundTrip(req)
+}
+
+// NewHTTPClient returns an HTTP client authenticated by src, for
+// github.NewClient.
+func NewHTTPClient(src TokenSource) *http.Client {
+	return &http.Client{Transport: &Transport{Source: src}}
+}
@@ -0,0 +1,76 @@
+package ghauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachedSourceRemintsBeforeExpiry(t *testing.T) {
+	clearCredentialEnv(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvAppID, "1234")
+	t.Setenv(EnvAppInstallationID, "42")
+	t.Setenv(EnvAppPrivateKey, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})))
+
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	mints := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mints++
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"ghs_%d","expires_at":%q}`, mints, now.Add(time.Hour).Format(time.RFC3339))
+	}))
+	defer srv.Close()
+
+	src := NewCachedSource(&CredentialChain{GhConfigDir: t.TempDir(), APIBaseURL: srv.URL, now: func() time.Time { return now }})
+	ctx := context.Background()
+	for range 2 {
+		if token, err := src.Token(ctx); err != nil || token != "ghs_1" {
+			t.Fatalf("fresh token: got %q, %v", token, err)
+		}
+	}
+
+	// Still well inside the hour: the cached token is reused
+	now = now.Add(30 * time.Minute)
+	if token, _ := src.Token(ctx); token != "ghs_1" {
+		t.Errorf("after 30m: got %q, want cached ghs_1", token)
+	}
+
+	// Within refreshBefore of expiry: a new token is minted
+	now = now.Add(27 * time.Minute)
+	if token, _ := src.Token(ctx); token != "ghs_2" {
+		t.Errorf("near expiry: got %q, want ghs_2", token)
+	}
+	if mints != 2 {
+		t.Errorf("minted %d tokens, want 2", mints)
+	}
+}
+
+func TestTransportSetsAuthorization(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	for _, src := range []TokenSource{StaticToken("ghp_x"), StaticToken("")} {
+		resp, err := NewHTTPClient(src).Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	if len(got) != 2 || got[0] != "Bearer ghp_x" || got[1] != "" {
+		t.Errorf("Authorization headers = %q", got)
+	}
+}
@@ -10,14 +10,25 @@ import (
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
 
 // Clone clones a repository to the specified path at a specific version/branch
 func Clone(url, path, version string) error {
+	return CloneWithToken(url, path, version, "")
+}
+
+// CloneWithToken clones like Clone, sending token over HTTPS basic auth
+// (the x-access-token form GitHub accepts for PATs, gh and App tokens).
+// An empty token clones anonymously.
+func CloneWithToken(url, path, version, token string) error {
 	opts := &git.CloneOptions{
 		URL:   url,
 		Depth: 1,
 	}
+	if token != "" {
+		opts.Auth = &githttp.BasicAuth{Username: "x-access-token", Password: token}
+	}
 
 	// If version is specified, clone at that reference
 	if version != "" {
@@ -279,3 +290,40 @@ func Push(path string) error {
 
 	return nil
 }
+
+// Root returns the top-level directory of the repository containing path,
+// searching parent directories like the git binary does.
+func Root(path string) (string, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to open repo: %w", err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	return worktree.Filesystem.Root(), nil
+}
+
+// RemoteURL returns the first URL of a remote (e.g. "origin") of the
+// repository containing path.
+func RemoteURL(path, name string) (string, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to open repo: %w", err)
+	}
+
+	remote, err := repo.Remote(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %w", name, err)
+	}
+
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return "", fmt.Errorf("remote %s has no URL", name)
+	}
+
+	return urls[0], nil
+}
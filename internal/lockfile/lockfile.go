@@ -29,6 +29,16 @@ type Package struct {
 	Binary      *Binary  `yaml:"binary,omitempty"`
 	Taskfile    *Taskfile `yaml:"taskfile,omitempty"`
 	Process     *Process `yaml:"process,omitempty"`
+	Params      map[string]string `yaml:"params,omitempty"` // Bundle parameter overrides, reused on upgrade
+	Files       []ManagedFile     `yaml:"files,omitempty"`  // Files installed from the package bundle
+}
+
+// ManagedFile is a bundle file written into the project. SHA256 is the hash
+// of the content as written, so local edits can be told apart on upgrade.
+type ManagedFile struct {
+	Path   string `yaml:"path"`
+	Source string `yaml:"source"`
+	SHA256 string `yaml:"sha256"`
 }
 
 // Binary represents an installed binary.
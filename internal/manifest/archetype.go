@@ -0,0 +1,253 @@
+package manifest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/processcompose"
+)
+
+// Project archetypes for 'xplat manifest bootstrap'.
+const (
+	ArchetypeGoService = "go-service"
+	ArchetypeHugoSite  = "hugo-site"
+	ArchetypeWorker    = "worker"
+	ArchetypeCLI       = "cli"
+)
+
+// Archetype is a kind of project bootstrap can scaffold.
+type Archetype struct {
+	Name        string
+	Description string
+	Port        int // Default port of the project's process, 0 for none
+}
+
+// Archetypes lists the archetypes in the order the wizard offers them.
+var Archetypes = []Archetype{
+	{Name: ArchetypeGoService, Description: "Go HTTP service run under process-compose", Port: 8080},
+	{Name: ArchetypeHugoSite, Description: "Hugo static site with a live-reload dev server", Port: config.DefaultHugoPort},
+	{Name: ArchetypeWorker, Description: "Cloudflare Worker in Go (syumai/workers), run locally with wrangler dev", Port: processcompose.DefaultWorkerPort},
+	{Name: ArchetypeCLI, Description: "Go command-line tool with release binaries"},
+}
+
+// FindArchetype returns the archetype with the given name.
+func FindArchetype(name string) (Archetype, error) {
+	for _, a := range Archetypes {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	names := make([]string, len(Archetypes))
+	for i, a := range Archetypes {
+		names[i] = a.Name
+	}
+	return Archetype{}, fmt.Errorf("unknown archetype %q (want one of: %s)", name, strings.Join(names, ", "))
+}
+
+// ProjectSpec holds the answers that shape a new project. Bootstrap turns
+// it into xplat.yaml, and every other file is generated from that manifest.
+type ProjectSpec struct {
+	Archetype   string
+	Name        string
+	Description string
+	Author      string
+	License     string
+	Module      string   // Go module path (go-service, cli and worker)
+	Port        int      // Port of the project's process (go-service, hugo-site and worker)
+	Env         []string // Extra required environment variables
+}
+
+// DefaultProjectSpec returns the defaults for a new project in dir. The
+// module path comes from go.mod when there is one.
+func DefaultProjectSpec(dir, archetype string) (ProjectSpec, error) {
+	if archetype == "" {
+		archetype = ArchetypeGoService
+	}
+	a, err := FindArchetype(archetype)
+	if err != nil {
+		return ProjectSpec{}, err
+	}
+
+	name := filepath.Base(dir)
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+	module := DetectGoModule(filepath.Join(dir, "go.mod"))
+	if module == "" {
+		// plat-* repos live under the same owner as xplat
+		module = "github.com/" + path.Dir(config.XplatRepo) + "/" + name
+	}
+
+	return ProjectSpec{
+		Archetype:   a.Name,
+		Name:        name,
+		Description: name + " - powered by xplat",
+		License:     "MIT",
+		Module:      module,
+		Port:        a.Port,
+	}, nil
+}
+
+// RunWizard asks for each answer on w, reading replies from r. An empty
+// reply (or end of input) keeps the default shown in brackets.
+func RunWizard(r io.Reader, w io.Writer, spec ProjectSpec) ProjectSpec {
+	reader := bufio.NewReader(r)
+	ask := func(label, def string) string {
+		if def != "" {
+			_, _ = fmt.Fprintf(w, "%s [%s]: ", label, def)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s: ", label)
+		}
+		input, _ := reader.ReadString('\n')
+		if input = strings.TrimSpace(input); input != "" {
+			return input
+		}
+		return def
+	}
+
+	_, _ = fmt.Fprintln(w, "Project archetype:")
+	def := 1
+	for i, a := range Archetypes {
+		if a.Name == spec.Archetype {
+			def = i + 1
+		}
+		_, _ = fmt.Fprintf(w, "  %d) %-11s %s\n", i+1, a.Name, a.Description)
+	}
+	for {
+		choice := ask("Archetype", strconv.Itoa(def))
+		if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(Archetypes) {
+			choice = Archetypes[n-1].Name
+		}
+		a, err := FindArchetype(choice)
+		if err == nil {
+			if a.Name != spec.Archetype {
+				spec.Archetype = a.Name
+				spec.Port = a.Port
+			}
+			break
+		}
+		_, _ = fmt.Fprintln(w, "  ", err)
+	}
+
+	spec.Name = ask("Name", spec.Name)
+	spec.Description = ask("Description", spec.Description)
+	spec.Author = ask("Author", spec.Author)
+	spec.License = ask("License", spec.License)
+	if spec.Archetype != ArchetypeHugoSite {
+		spec.Module = ask("Go module", spec.Module)
+	}
+	if spec.Port > 0 {
+		for {
+			port, err := strconv.Atoi(ask("Port", strconv.Itoa(spec.Port)))
+			if err == nil && port > 0 && port < 65536 {
+				spec.Port = port
+				break
+			}
+			_, _ = fmt.Fprintln(w, "   port must be a number between 1 and 65535")
+		}
+	}
+	if env := ask("Required env vars (comma-separated)", strings.Join(spec.Env, ",")); env != "" {
+		spec.Env = nil
+		for _, name := range strings.Split(env, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				spec.Env = append(spec.Env, name)
+			}
+		}
+	}
+	return spec
+}
+
+// Manifest returns the xplat.yaml manifest for the spec.
+func (s ProjectSpec) Manifest() (*Manifest, error) {
+	if _, err := FindArchetype(s.Archetype); err != nil {
+		return nil, err
+	}
+	if s.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	m := &Manifest{
+		APIVersion:  DefaultAPIVersion,
+		Kind:        "Package",
+		Name:        s.Name,
+		Version:     "main",
+		Description: s.Description,
+		Author:      s.Author,
+		License:     s.License,
+		Language:    "go",
+		Archetype:   s.Archetype,
+	}
+	env := &EnvConfig{}
+
+	switch s.Archetype {
+	case ArchetypeGoService, ArchetypeCLI:
+		m.Binary = &BinaryConfig{
+			Name:   s.Name,
+			Source: &SourceConfig{Go: s.Module + "/cmd/" + s.Name},
+		}
+		if s.Archetype == ArchetypeGoService {
+			m.Processes = map[string]ProcessConfig{
+				"server": {Command: "task run", Port: s.Port, HealthPath: "/health"},
+			}
+			env.Optional = append(env.Optional, EnvVar{
+				Name:        "PORT",
+				Description: "Port the server listens on",
+				Default:     strconv.Itoa(s.Port),
+			})
+		}
+	case ArchetypeHugoSite:
+		// Hugo runs through 'go run', so CI only needs Go
+		m.Processes = map[string]ProcessConfig{
+			"site": {Command: "task dev", Port: s.Port, HealthPath: "/"},
+		}
+		env.Optional = append(env.Optional, EnvVar{
+			Name:        "HUGO_BASEURL",
+			Description: "Base URL the site is published at",
+		})
+		m.Gitignore = &GitignoreConfig{Patterns: []string{"public/", "resources/", ".hugo_build.lock"}}
+	case ArchetypeWorker:
+		m.Processes = map[string]ProcessConfig{
+			"worker": {Port: s.Port, Worker: &WorkerConfig{Dir: "."}},
+		}
+		m.Gitignore = &GitignoreConfig{Patterns: []string{".wrangler/"}}
+		env.Required = append(env.Required,
+			EnvVar{Name: "CLOUDFLARE_ACCOUNT_ID", Description: "Cloudflare account to deploy to"},
+			EnvVar{Name: "CLOUDFLARE_API_TOKEN", Description: "API token with Workers Scripts edit", Instructions: "https://dash.cloudflare.com/profile/api-tokens"},
+		)
+	}
+
+	for _, name := range s.Env {
+		env.Required = append(env.Required, EnvVar{Name: name})
+	}
+	if len(env.Required) > 0 || len(env.Optional) > 0 {
+		m.Env = env
+	}
+	return m, nil
+}
+
+// WriteProjectManifest writes the spec's xplat.yaml to dir.
+func WriteProjectManifest(dir string, spec ProjectSpec) (*Manifest, error) {
+	m, err := spec.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	header := fmt.Sprintf("# xplat.yaml - Package manifest for xplat ecosystem\n# Archetype: %s (regenerate files with: xplat manifest bootstrap --force)\n", spec.Archetype)
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), append([]byte(header), data...), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,138 @@
+package manifest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/processcompose"
+)
+
+func TestRunWizard(t *testing.T) {
+	spec, err := DefaultProjectSpec(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.Archetype != ArchetypeGoService || spec.Port != 8080 {
+		t.Errorf("DefaultProjectSpec() = %+v", spec)
+	}
+
+	// Archetype by number after a bad reply, keep author and license, retry
+	// a bad port and add env vars
+	in := "9\n2\nsite\nMy site\n\n\nnope\n4000\nAPI_KEY, ,SECRET\n"
+	var out bytes.Buffer
+	spec = RunWizard(strings.NewReader(in), &out, spec)
+
+	if spec.Archetype != ArchetypeHugoSite || spec.Name != "site" || spec.Description != "My site" {
+		t.Errorf("RunWizard() = %+v", spec)
+	}
+	if spec.Port != 4000 || spec.License != "MIT" {
+		t.Errorf("RunWizard() port/license = %d/%s", spec.Port, spec.License)
+	}
+	if !reflect.DeepEqual(spec.Env, []string{"API_KEY", "SECRET"}) {
+		t.Errorf("RunWizard() env = %v", spec.Env)
+	}
+	if strings.Contains(out.String(), "Go module") {
+		t.Error("hugo-site should not ask for a Go module")
+	}
+	if !strings.Contains(out.String(), `unknown archetype "9"`) {
+		t.Errorf("expected archetype error, got:\n%s", out.String())
+	}
+
+	// End of input keeps every default
+	def, _ := DefaultProjectSpec(t.TempDir(), ArchetypeCLI)
+	if got := RunWizard(strings.NewReader(""), &out, def); !reflect.DeepEqual(got, def) {
+		t.Errorf("RunWizard() on EOF = %+v, want %+v", got, def)
+	}
+}
+
+func TestProjectSpecManifest(t *testing.T) {
+	for _, a := range Archetypes {
+		spec := ProjectSpec{Archetype: a.Name, Name: "plat-x", Module: "github.com/o/plat-x", Port: a.Port}
+		m, err := spec.Manifest()
+		if err != nil {
+			t.Fatalf("%s: %v", a.Name, err)
+		}
+		if m.Archetype != a.Name || m.Language != "go" {
+			t.Errorf("%s: archetype/language = %s/%s", a.Name, m.Archetype, m.Language)
+		}
+		if hasBinary := m.Binary != nil; hasBinary != (a.Name == ArchetypeGoService || a.Name == ArchetypeCLI) {
+			t.Errorf("%s: binary = %+v", a.Name, m.Binary)
+		}
+		if m.HasProcesses() != (a.Port > 0) {
+			t.Errorf("%s: processes = %v", a.Name, m.Processes)
+		}
+		if err := NewLoader().validate(m); err != nil {
+			t.Errorf("%s: invalid manifest: %v", a.Name, err)
+		}
+	}
+
+	if _, err := (ProjectSpec{Archetype: "rails", Name: "x"}).Manifest(); err == nil {
+		t.Error("expected error for unknown archetype")
+	}
+}
+
+func TestBootstrapWithSpec(t *testing.T) {
+	dir := t.TempDir()
+	spec, err := DefaultProjectSpec(dir, ArchetypeWorker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec.Name = "plat-edge"
+
+	result, err := Bootstrap(dir, BootstrapOptions{Spec: &spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("bootstrap errors: %v", result.Errors)
+	}
+
+	for _, f := range []string{"xplat.yaml", "Taskfile.yml", "process-compose.yaml", ".env.example", "wrangler.toml", ".github/workflows/ci.yml"} {
+		if _, err := os.Stat(filepath.Join(dir, f)); err != nil {
+			t.Errorf("missing %s", f)
+		}
+	}
+	if result.Manifest.Archetype != ArchetypeWorker {
+		t.Errorf("manifest archetype = %q", result.Manifest.Archetype)
+	}
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	var tf map[string]any
+	if err := yaml.Unmarshal([]byte(read("Taskfile.yml")), &tf); err != nil {
+		t.Fatalf("Taskfile.yml is not valid YAML: %v", err)
+	}
+	if !strings.Contains(read("Taskfile.yml"), "wrangler deploy") {
+		t.Error("Taskfile.yml missing worker deploy task")
+	}
+	var pc processcompose.ProcessCompose
+	if err := yaml.Unmarshal([]byte(read("process-compose.yaml")), &pc); err != nil {
+		t.Fatalf("process-compose.yaml is not valid YAML: %v", err)
+	}
+	workerFound := false
+	for _, proc := range pc.Processes {
+		if strings.HasPrefix(proc.CommandLine(), "wrangler dev --local --ip 127.0.0.1 --port 8787") {
+			workerFound = true
+		}
+	}
+	if !workerFound {
+		t.Errorf("process-compose.yaml:\n%s", read("process-compose.yaml"))
+	}
+	if !strings.Contains(read(".env.example"), "CLOUDFLARE_API_TOKEN") {
+		t.Error(".env.example missing CLOUDFLARE_API_TOKEN")
+	}
+	if !strings.Contains(read("wrangler.toml"), `name = "plat-edge"`) {
+		t.Errorf("wrangler.toml:\n%s", read("wrangler.toml"))
+	}
+}
@@ -2,12 +2,14 @@ package manifest
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/joeblew999/xplat/internal/ghauth"
 	"github.com/joeblew999/xplat/internal/processcompose"
 	"github.com/joeblew999/xplat/internal/syncgh"
 	"github.com/joeblew999/xplat/internal/taskfile"
@@ -19,6 +21,11 @@ type BootstrapOptions struct {
 	Force   bool // Overwrite existing files
 	DryRun  bool // Just show what would be done
 	Verbose bool // Print details
+
+	// Spec shapes a new xplat.yaml (see RunWizard). When nil, the manifest
+	// is detected from the directory as 'xplat manifest init' does. It is
+	// ignored when xplat.yaml already exists.
+	Spec *ProjectSpec
 }
 
 // BootstrapResult tracks what was created/updated.
@@ -32,6 +39,7 @@ type BootstrapResult struct {
 
 // Bootstrap ensures a directory has all standard plat-* files.
 // It creates or updates: xplat.yaml, Taskfile.yml, .gitignore, .github/workflows/ci.yml, README.md
+// and, depending on the manifest, .env.example, process-compose.yaml and wrangler.toml.
 func Bootstrap(dir string, opts BootstrapOptions) (*BootstrapResult, error) {
 	result := &BootstrapResult{}
 
@@ -53,8 +61,13 @@ func Bootstrap(dir string, opts BootstrapOptions) (*BootstrapResult, error) {
 		}
 		result.Skipped = append(result.Skipped, "xplat.yaml (exists)")
 	} else {
-		// Create new manifest
-		_, err := Init(dir, InitOptions{Force: opts.Force})
+		// Create new manifest, from the wizard's answers when given
+		var err error
+		if opts.Spec != nil {
+			_, err = WriteProjectManifest(dir, *opts.Spec)
+		} else {
+			_, err = Init(dir, InitOptions{Force: opts.Force})
+		}
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("xplat.yaml: %v", err))
 		} else {
@@ -82,6 +95,13 @@ func Bootstrap(dir string, opts BootstrapOptions) (*BootstrapResult, error) {
 	if m.Binary != nil && m.Binary.Name != "" {
 		binaryName = m.Binary.Name
 	}
+	port := 0
+	for _, proc := range m.Processes {
+		if proc.Port > 0 {
+			port = proc.Port
+			break
+		}
+	}
 
 	// 2. Generate Taskfile.yml
 	taskfilePath := filepath.Join(dir, "Taskfile.yml")
@@ -99,6 +119,8 @@ func Bootstrap(dir string, opts BootstrapOptions) (*BootstrapResult, error) {
 		if m.Language != "" {
 			tfOpts.Language = m.Language
 		}
+		tfOpts.Archetype = m.Archetype
+		tfOpts.Port = port
 		// Pass external repo info if present
 		if m.Binary != nil && m.Binary.Source != nil && m.Binary.Source.IsExternalRepo() {
 			tfOpts.IsExternalRepo = true
@@ -140,6 +162,8 @@ func Bootstrap(dir string, opts BootstrapOptions) (*BootstrapResult, error) {
 		gen := NewGenerator(nil)
 		wfOpts := WorkflowOptions{
 			Language: m.Language,
+			// Sites and Workers build the same artifact on every OS
+			SingleOS: m.Archetype == ArchetypeHugoSite || m.Archetype == ArchetypeWorker,
 		}
 		// Check if this is an external repo project
 		if m.Binary != nil && m.Binary.Source != nil && m.Binary.Source.IsExternalRepo() {
@@ -216,6 +240,20 @@ func Bootstrap(dir string, opts BootstrapOptions) (*BootstrapResult, error) {
 		}
 	}
 
+	// 7b. Generate wrangler.toml for Workers
+	if m.Archetype == ArchetypeWorker {
+		wranglerPath := filepath.Join(dir, "wrangler.toml")
+		if err := generateIfNeeded(wranglerPath, opts, result, func() error {
+			content, err := templates.RenderExternal("wrangler.toml.tmpl", templates.WranglerData{Name: projectName})
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(wranglerPath, content, 0644)
+		}); err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("wrangler.toml: %v", err))
+		}
+	}
+
 	// 8. Generate taskfiles/Taskfile.service.yml for remote include by consumers
 	serviceTaskfilePath := filepath.Join(dir, "taskfiles", "Taskfile.service.yml")
 	if err := generateIfNeeded(serviceTaskfilePath, opts, result, func() error {
@@ -354,11 +392,26 @@ func generateProcessCompose(path string, m *Manifest) error {
 			command = strings.Replace(command, "task run", "task dev", 1)
 		}
 
+		workingDir := "."
+		var entrypoint []string
+		if proc.Worker != nil {
+			// Workers run under wrangler dev, see processcompose.WorkerEntrypoint
+			port := proc.Port
+			if port == 0 {
+				port = processcompose.DefaultWorkerPort
+			}
+			command = ""
+			entrypoint = processcompose.WorkerEntrypoint(fmt.Sprintf("%d", port), proc.Worker.Vars)
+			workingDir = proc.Worker.Dir
+		}
+
 		pcProc := &processcompose.Process{
-			Command:    command,
-			WorkingDir: ".",
-			Disabled:   proc.Disabled,
-			Namespace:  proc.Namespace,
+			Command:     command,
+			Entrypoint:  entrypoint,
+			WorkingDir:  workingDir,
+			Disabled:    proc.Disabled,
+			Namespace:   proc.Namespace,
+			EnvProfiles: proc.EnvProfiles,
 			Shutdown: &processcompose.Shutdown{
 				Signal:         15, // SIGTERM
 				TimeoutSeconds: 10,
@@ -453,8 +506,8 @@ func enableGitHubPages(dir string, verbose bool) error {
 		return err
 	}
 
-	// Try using GITHUB_TOKEN directly (no gh CLI needed)
-	if os.Getenv("GITHUB_TOKEN") != "" {
+	// Try the GitHub credential chain directly (no gh CLI needed)
+	if token, _ := ghauth.Token(context.Background()); token != "" {
 		if err := syncgh.EnablePages(owner, repo); err == nil {
 			return nil
 		}
@@ -463,7 +516,7 @@ func enableGitHubPages(dir string, verbose bool) error {
 
 	// Fall back to gh CLI
 	if _, err := exec.LookPath("gh"); err != nil {
-		return fmt.Errorf("neither GITHUB_TOKEN nor gh CLI available")
+		return fmt.Errorf("neither a GitHub token nor gh CLI available")
 	}
 
 	fullRepo := owner + "/" + repo
@@ -2,32 +2,109 @@ package manifest
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
+// Severity is the level of a check finding.
+type Severity string
+
+// Finding severities, most severe first.
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// rank orders severities: higher is more severe.
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 3
+	case SeverityWarning:
+		return 2
+	case SeverityInfo:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast returns true if s is as severe as min or more.
+func (s Severity) AtLeast(min Severity) bool {
+	return min.rank() > 0 && s.rank() >= min.rank()
+}
+
+// ParseSeverity parses a severity name.
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(s)); sev {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return sev, nil
+	}
+	return "", fmt.Errorf("unknown severity %q (valid: error, warning, info)", s)
+}
+
+// Finding is one issue found by Check.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	File     string   `json:"file"` // manifest file the finding is about
+	Message  string   `json:"message"`
+}
+
 // CheckResult holds the result of a manifest validation check.
 type CheckResult struct {
-	Name     string
-	Path     string
-	Errors   []string
-	Warnings []string
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	File     string    `json:"file"` // manifest file
+	Findings []Finding `json:"findings"`
+}
+
+func (r *CheckResult) add(sev Severity, msg string) {
+	r.Findings = append(r.Findings, Finding{Severity: sev, File: r.File, Message: msg})
 }
 
 // AddError adds an error to the result.
 func (r *CheckResult) AddError(msg string) {
-	r.Errors = append(r.Errors, msg)
+	r.add(SeverityError, msg)
 }
 
 // AddWarning adds a warning to the result.
 func (r *CheckResult) AddWarning(msg string) {
-	r.Warnings = append(r.Warnings, msg)
+	r.add(SeverityWarning, msg)
+}
+
+// AddInfo adds an informational finding to the result.
+func (r *CheckResult) AddInfo(msg string) {
+	r.add(SeverityInfo, msg)
+}
+
+// Count returns the number of findings with a severity.
+func (r *CheckResult) Count(sev Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == sev {
+			n++
+		}
+	}
+	return n
 }
 
 // IsValid returns true if there are no errors.
 func (r *CheckResult) IsValid() bool {
-	return len(r.Errors) == 0
+	return r.Count(SeverityError) == 0
+}
+
+// Worst returns the most severe finding level, or "" if there are none.
+func (r *CheckResult) Worst() Severity {
+	var worst Severity
+	for _, f := range r.Findings {
+		if f.Severity.rank() > worst.rank() {
+			worst = f.Severity
+		}
+	}
+	return worst
 }
 
 // Check performs deep validation of a manifest against its filesystem.
@@ -36,6 +113,7 @@ func Check(m *Manifest, repoPath string) CheckResult {
 	result := CheckResult{
 		Name: m.Name,
 		Path: repoPath,
+		File: filepath.Join(repoPath, ManifestFileName),
 	}
 
 	// Check taskfile.path exists
@@ -58,13 +136,32 @@ func Check(m *Manifest, repoPath string) CheckResult {
 	if len(m.Processes) > 0 {
 		hasTaskfile := checkTaskfileExists(m, repoPath)
 
-		for name, proc := range m.Processes {
-			if strings.HasPrefix(proc.Command, "task ") && !hasTaskfile {
+		names := make([]string, 0, len(m.Processes))
+		for name := range m.Processes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := m.Processes[name]
+			if w := p.Worker; w != nil {
+				if _, err := os.Stat(filepath.Join(repoPath, w.Dir, "wrangler.toml")); err != nil {
+					result.AddError(fmt.Sprintf("process '%s' worker.dir '%s' has no wrangler.toml", name, w.Dir))
+				}
+				continue
+			}
+			if strings.HasPrefix(p.Command, "task ") && !hasTaskfile {
 				result.AddWarning(fmt.Sprintf("process '%s' uses task command but no Taskfile found", name))
 			}
 		}
 	}
 
+	// Check SLO declarations, which monitors turn into alert thresholds
+	for _, slo := range m.SLOs {
+		for _, problem := range m.validateSLO(slo) {
+			result.AddError(problem)
+		}
+	}
+
 	// Check required env vars have descriptions
 	if m.Env != nil {
 		for _, v := range m.Env.Required {
@@ -84,6 +181,13 @@ func Check(m *Manifest, repoPath string) CheckResult {
 		result.AddWarning("missing author")
 	}
 
+	if m.License == "" {
+		result.AddInfo("missing license")
+	}
+	if m.Taskfile == nil {
+		result.AddInfo("no taskfile section (tasks can't be included remotely)")
+	}
+
 	return result
 }
 
@@ -118,8 +222,19 @@ func CheckAll(loader *Loader, rootPath string) ([]CheckResult, error) {
 		}
 
 		repoPath := filepath.Join(rootPath, entry.Name())
+		manifestPath := filepath.Join(repoPath, ManifestFileName)
+		if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+			result := CheckResult{Name: entry.Name(), Path: repoPath, File: manifestPath}
+			result.AddWarning("no " + ManifestFileName)
+			results = append(results, result)
+			continue
+		}
+
 		m, err := loader.LoadDir(repoPath)
 		if err != nil {
+			result := CheckResult{Name: entry.Name(), Path: repoPath, File: manifestPath}
+			result.AddError(err.Error())
+			results = append(results, result)
 			continue
 		}
 
@@ -129,3 +244,91 @@ func CheckAll(loader *Loader, rootPath string) ([]CheckResult, error) {
 
 	return results, nil
 }
+
+// CheckSummary counts findings across check results.
+type CheckSummary struct {
+	Repos    int `json:"repos"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+	Infos    int `json:"infos"`
+}
+
+// Summarize counts the findings in results.
+func Summarize(results []CheckResult) CheckSummary {
+	sum := CheckSummary{Repos: len(results)}
+	for i := range results {
+		sum.Errors += results[i].Count(SeverityError)
+		sum.Warnings += results[i].Count(SeverityWarning)
+		sum.Infos += results[i].Count(SeverityInfo)
+	}
+	return sum
+}
+
+// FailsOn returns true if any finding is at least as severe as min.
+func FailsOn(results []CheckResult, min Severity) bool {
+	for _, r := range results {
+		for _, f := range r.Findings {
+			if f.Severity.AtLeast(min) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteCheckReport writes a per-repo report followed by a summary line.
+func WriteCheckReport(w io.Writer, results []CheckResult) {
+	labels := map[Severity]string{SeverityError: "ERROR", SeverityWarning: "WARN", SeverityInfo: "INFO"}
+	for _, r := range results {
+		mark := "✓"
+		switch r.Worst() {
+		case SeverityError:
+			mark = "✗"
+		case SeverityWarning:
+			mark = "⚠"
+		}
+		_, _ = fmt.Fprintf(w, "%s %s (%s)\n", mark, r.Name, r.Path)
+		for _, sev := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+			for _, f := range r.Findings {
+				if f.Severity == sev {
+					_, _ = fmt.Fprintf(w, "  %s: %s\n", labels[sev], f.Message)
+				}
+			}
+		}
+	}
+
+	sum := Summarize(results)
+	if sum.Repos > 1 {
+		_, _ = fmt.Fprintf(w, "\n%d repos: %d errors, %d warnings, %d info\n", sum.Repos, sum.Errors, sum.Warnings, sum.Infos)
+	}
+}
+
+// WriteGitHubAnnotations writes findings as GitHub Actions workflow commands
+// (::error file=...::message). File paths are made relative to baseDir.
+func WriteGitHubAnnotations(w io.Writer, results []CheckResult, baseDir string) {
+	for _, r := range results {
+		for _, f := range r.Findings {
+			file := f.File
+			if rel, err := filepath.Rel(baseDir, file); err == nil {
+				file = rel
+			}
+			level := string(f.Severity)
+			if f.Severity == SeverityInfo {
+				level = "notice"
+			}
+			_, _ = fmt.Fprintf(w, "::%s file=%s,title=%s::%s\n",
+				level, escapeAnnotationProperty(filepath.ToSlash(file)),
+				escapeAnnotationProperty("xplat manifest: "+r.Name), escapeAnnotationData(f.Message))
+		}
+	}
+}
+
+// escapeAnnotationData escapes a workflow command message.
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a workflow command property value.
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
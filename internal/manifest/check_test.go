@@ -0,0 +1,184 @@
+package manifest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckAllSeverities(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"plat-a/xplat.yaml": `name: a
+version: v1.0.0
+description: Valid manifest
+author: joeblew999
+license: MIT
+taskfile:
+  path: Taskfile.yml
+`,
+		"plat-a/Taskfile.yml": "version: '3'\n",
+		"plat-b/xplat.yaml": `name: b
+version: v1.0.0
+taskfile:
+  path: missing.yml
+`,
+		"plat-c/README.md": "no manifest\n",
+		"other/xplat.yaml": "name: other\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := CheckAll(NewLoader(), root)
+	if err != nil {
+		t.Fatalf("CheckAll failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3 (plat-* only)", len(results))
+	}
+
+	worst := map[string]Severity{}
+	for _, r := range results {
+		worst[r.Name] = r.Worst()
+	}
+	if worst["a"] != "" {
+		t.Errorf("a: worst = %q, want no findings", worst["a"])
+	}
+	if worst["b"] != SeverityError {
+		t.Errorf("b: worst = %q, want error", worst["b"])
+	}
+	if worst["plat-c"] != SeverityWarning {
+		t.Errorf("plat-c: worst = %q, want warning", worst["plat-c"])
+	}
+
+	sum := Summarize(results)
+	if sum.Repos != 3 || sum.Errors != 1 || sum.Warnings != 3 || sum.Infos != 1 {
+		t.Errorf("Summarize = %+v", sum)
+	}
+
+	tests := []struct {
+		min  Severity
+		want bool
+	}{
+		{SeverityError, true},
+		{SeverityWarning, true},
+		{SeverityInfo, true},
+	}
+	for _, tt := range tests {
+		if got := FailsOn(results, tt.min); got != tt.want {
+			t.Errorf("FailsOn(%s) = %v, want %v", tt.min, got, tt.want)
+		}
+	}
+	if FailsOn(results[:1], SeverityInfo) {
+		t.Error("FailsOn(info) for a valid manifest = true, want false")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Severity
+		wantErr bool
+	}{
+		{"error", SeverityError, false},
+		{"WARNING", SeverityWarning, false},
+		{"info", SeverityInfo, false},
+		{"fatal", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSeverity(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSeverity(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSeverity(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "ws")
+	r := CheckResult{Name: "b,c", Path: filepath.Join(base, "plat-b"), File: filepath.Join(base, "plat-b", ManifestFileName)}
+	r.AddError("taskfile.path 'x.yml' does not exist")
+	r.AddInfo("100% done\nnext")
+
+	var buf bytes.Buffer
+	WriteGitHubAnnotations(&buf, []CheckResult{r}, base)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"::error file=plat-b/xplat.yaml,title=xplat manifest%3A b%2Cc::taskfile.path 'x.yml' does not exist",
+		"::notice file=plat-b/xplat.yaml,title=xplat manifest%3A b%2Cc::100%25 done%0Anext",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), buf.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d:\n got %s\nwant %s", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestCheckWorkerDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "workers", "sync-cf"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "workers", "sync-cf", "wrangler.toml"), []byte("name = \"w\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manifest{Name: "w", Processes: map[string]ProcessConfig{
+		"worker":  {Worker: &WorkerConfig{Dir: "workers/sync-cf"}},
+		"missing": {Worker: &WorkerConfig{Dir: "workers/none"}},
+	}}
+	var errs []string
+	for _, f := range Check(m, root).Findings {
+		if f.Severity == SeverityError {
+			errs = append(errs, f.Message)
+		}
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "workers/none") {
+		t.Errorf("errors = %v, want only workers/none", errs)
+	}
+}
+
+func TestCheckSLOs(t *testing.T) {
+	m := &Manifest{
+		Name:      "a",
+		Processes: map[string]ProcessConfig{"api": {Command: "api", Port: 8080}, "job": {Command: "job"}},
+		SLOs: []SLOConfig{
+			{Name: "api", Process: "api", Availability: 99.9, Latency: "500ms"},
+			{Name: "slow", Endpoint: "https://example.com", Availability: 99, Latency: "fast"},
+			{Name: "high", Endpoint: "https://example.com", Availability: 120},
+			{Name: "job", Process: "job", Availability: 99},
+			{Name: "none", Availability: 99},
+		},
+	}
+	result := Check(m, t.TempDir())
+	var errs []string
+	for _, f := range result.Findings {
+		if f.Severity == SeverityError {
+			errs = append(errs, f.Message)
+		}
+	}
+	if len(errs) != 4 {
+		t.Errorf("errors = %v, want 4 (slow, high, job, none)", errs)
+	}
+	for _, e := range errs {
+		if strings.Contains(e, "'api'") {
+			t.Errorf("valid slo reported: %s", e)
+		}
+	}
+}
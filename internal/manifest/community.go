@@ -0,0 +1,188 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/templates"
+)
+
+// licenseTemplates maps lower-cased SPDX IDs to their LICENSE templates.
+var licenseTemplates = map[string]string{
+	"mit":          "license_mit.tmpl",
+	"apache-2.0":   "license_apache-2.0.tmpl",
+	"bsd-3-clause": "license_bsd-3-clause.tmpl",
+}
+
+// LicenseTemplate returns the LICENSE template for an SPDX license ID, or ""
+// when xplat has none for it.
+func LicenseTemplate(license string) string {
+	return licenseTemplates[strings.ToLower(strings.TrimSpace(license))]
+}
+
+// Managed block comment styles.
+const (
+	CommentMarkdown = "<!--"
+	CommentHash     = "#"
+)
+
+// CommunityFile is a file 'xplat gen community' writes.
+type CommunityFile struct {
+	Path    string // Relative to the repo root
+	Content []byte
+	// Managed is the comment style of the file's managed block. Only the
+	// block is regenerated and the rest of the file is kept. Files without
+	// one (LICENSE) are only written when missing.
+	Managed string
+}
+
+// CommunityOptions tune RenderCommunity.
+type CommunityOptions struct {
+	RepoURL string // Base URL for GitHub repos (e.g., "https://github.com/joeblew999")
+	Year    int    // Copyright year (default: this year)
+}
+
+// RenderCommunity renders LICENSE, SECURITY.md and, with funding links,
+// .github/FUNDING.yml for a manifest. LICENSE is left out when the license
+// has no template.
+func RenderCommunity(m *Manifest, opts CommunityOptions) ([]CommunityFile, error) {
+	data := templates.CommunityData{
+		Name:        m.Name,
+		Holder:      m.Copyright,
+		Year:        opts.Year,
+		Supported:   "Only the latest release receives security fixes.",
+		AdvisoryURL: strings.TrimSuffix(opts.RepoURL, "/") + "/" + m.RepoName() + "/security/advisories/new",
+	}
+	if data.Holder == "" {
+		data.Holder = m.Author
+	}
+	if data.Year == 0 {
+		data.Year = time.Now().Year()
+	}
+	if sec := m.Security; sec != nil {
+		if sec.Supported != "" {
+			data.Supported = sec.Supported
+		}
+		if sec.Contact != "" {
+			url, err := contactURL(sec.Contact)
+			if err != nil {
+				return nil, err
+			}
+			data.Contact, data.ContactURL = sec.Contact, url
+		}
+	}
+
+	var files []CommunityFile
+	if tmpl := LicenseTemplate(m.License); tmpl != "" {
+		if data.Holder == "" {
+			return nil, fmt.Errorf("xplat.yaml needs an author or copyright to generate LICENSE")
+		}
+		content, err := templates.RenderProject(tmpl, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render LICENSE: %w", err)
+		}
+		files = append(files, CommunityFile{Path: "LICENSE", Content: content})
+	}
+
+	content, err := templates.RenderProject("security.md.tmpl", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SECURITY.md: %w", err)
+	}
+	files = append(files, CommunityFile{Path: "SECURITY.md", Content: content, Managed: CommentMarkdown})
+
+	if m.Funding != nil {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(m.Funding); err != nil {
+			return nil, fmt.Errorf("failed to render FUNDING.yml: %w", err)
+		}
+		if content := buf.Bytes(); string(content) != "{}\n" {
+			files = append(files, CommunityFile{Path: ".github/FUNDING.yml", Content: content, Managed: CommentHash})
+		}
+	}
+	return files, nil
+}
+
+// contactURL returns the link for a security contact: the URL itself, or a
+// mailto: link for an email address.
+func contactURL(contact string) (string, error) {
+	switch {
+	case strings.Contains(contact, "://"):
+		return contact, nil
+	case strings.Contains(contact, "@") && !strings.ContainsAny(contact, " <>"):
+		return "mailto:" + contact, nil
+	}
+	return "", fmt.Errorf("security.contact %q must be an email address or URL", contact)
+}
+
+// ErrUnmanaged is returned by MergeManagedBlock for a file with content but
+// no managed block.
+var ErrUnmanaged = errors.New("no xplat managed block")
+
+// managedMarkers returns the begin marker prefix and end marker line of a
+// managed block in the comment style.
+func managedMarkers(comment string) (begin, end string) {
+	if comment == CommentHash {
+		return "# xplat:begin", "# xplat:end"
+	}
+	return "<!-- xplat:begin", "<!-- xplat:end -->"
+}
+
+// ManagedBlock wraps content in managed block markers.
+func ManagedBlock(comment string, content []byte) []byte {
+	begin, end := managedMarkers(comment)
+	note := " (generated by 'xplat gen community' from xplat.yaml, edits inside this block are replaced)"
+	if comment == CommentMarkdown {
+		note += " -->"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(begin + note + "\n")
+	buf.Write(content)
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(end + "\n")
+	return buf.Bytes()
+}
+
+// MergeManagedBlock replaces the managed block in existing with content,
+// keeping everything around it. An empty file becomes just the block. A file
+// with content but no block returns ErrUnmanaged.
+func MergeManagedBlock(existing []byte, comment string, content []byte) ([]byte, error) {
+	block := ManagedBlock(comment, content)
+	if len(bytes.TrimSpace(existing)) == 0 {
+		return block, nil
+	}
+
+	beginPrefix, endLine := managedMarkers(comment)
+	lines := strings.SplitAfter(string(existing), "\n")
+	begin, end := -1, -1
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if begin < 0 && strings.HasPrefix(line, beginPrefix) {
+			begin = i
+		} else if begin >= 0 && line == endLine {
+			end = i
+			break
+		}
+	}
+	switch {
+	case begin < 0:
+		return nil, ErrUnmanaged
+	case end < 0:
+		return nil, fmt.Errorf("xplat managed block is missing its %q line", endLine)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(strings.Join(lines[:begin], ""))
+	buf.Write(block)
+	buf.WriteString(strings.Join(lines[end+1:], ""))
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+package manifest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderCommunity(t *testing.T) {
+	m := &Manifest{
+		Name:     "plat-x",
+		Author:   "joeblew999",
+		License:  "mit",
+		Funding:  &FundingConfig{GitHub: []string{"joeblew999"}, Custom: []string{"https://example.com/donate"}},
+		Security: &SecurityConfig{Contact: "security@example.com"},
+	}
+	files, err := RenderCommunity(m, CommunityOptions{RepoURL: "https://github.com/joeblew999/", Year: 2026})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, f := range files {
+		got[f.Path] = string(f.Content)
+	}
+
+	if license := got["LICENSE"]; !strings.HasPrefix(license, "MIT License\n\nCopyright (c) 2026 joeblew999\n") {
+		t.Errorf("LICENSE:\n%s", license)
+	}
+	if security := got["SECURITY.md"]; !strings.Contains(security, "[security@example.com](mailto:security@example.com)") {
+		t.Errorf("SECURITY.md:\n%s", security)
+	}
+	if funding := got[".github/FUNDING.yml"]; funding != "github:\n  - joeblew999\ncustom:\n  - https://example.com/donate\n" {
+		t.Errorf("FUNDING.yml:\n%s", funding)
+	}
+
+	// Without a contact, reports go to GitHub private advisories
+	m.Security, m.Funding, m.License = nil, nil, "GPL-3.0"
+	files, err = RenderCommunity(m, CommunityOptions{RepoURL: "https://github.com/joeblew999"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "SECURITY.md" {
+		t.Fatalf("rendered %v, want only SECURITY.md for a license without template", files)
+	}
+	if !strings.Contains(string(files[0].Content), "https://github.com/joeblew999/plat-x/security/advisories/new") {
+		t.Errorf("SECURITY.md:\n%s", files[0].Content)
+	}
+
+	if _, err := RenderCommunity(&Manifest{Name: "plat-x", License: "MIT"}, CommunityOptions{}); err == nil {
+		t.Error("expected error for LICENSE without a copyright holder")
+	}
+	m.Security = &SecurityConfig{Contact: "the security team"}
+	if _, err := RenderCommunity(m, CommunityOptions{}); err == nil {
+		t.Error("expected error for a contact that is neither email nor URL")
+	}
+}
+
+func TestMergeManagedBlock(t *testing.T) {
+	first, err := MergeManagedBlock(nil, CommentHash, []byte("github: [a]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(first), "# xplat:begin") || !strings.HasSuffix(string(first), "github: [a]\n# xplat:end\n") {
+		t.Errorf("new file:\n%s", first)
+	}
+
+	// Text around the block survives regeneration
+	edited := "# Sponsors\n" + string(first) + "patreon: me\n"
+	merged, err := MergeManagedBlock([]byte(edited), CommentHash, []byte("github: [b]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Sponsors\n" + strings.Replace(string(first), "[a]", "[b]", 1) + "patreon: me\n"
+	if string(merged) != want {
+		t.Errorf("merged:\n%s\nwant:\n%s", merged, want)
+	}
+
+	if _, err := MergeManagedBlock([]byte("# Security\nhand written\n"), CommentMarkdown, []byte("x\n")); !errors.Is(err, ErrUnmanaged) {
+		t.Errorf("file without block: %v, want ErrUnmanaged", err)
+	}
+	if _, err := MergeManagedBlock([]byte("<!-- xplat:begin -->\nx\n"), CommentMarkdown, []byte("x\n")); err == nil || errors.Is(err, ErrUnmanaged) {
+		t.Errorf("unterminated block: %v, want error", err)
+	}
+}
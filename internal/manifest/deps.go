@@ -0,0 +1,212 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/templates"
+)
+
+// skipDepDirs are directories never scanned for dependency manifests.
+var skipDepDirs = map[string]bool{
+	".git":         true,
+	".src":         true,
+	".bin":         true,
+	".data":        true,
+	".task":        true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Ecosystems describes the dependency ecosystems present in a repo.
+// Directories are relative to the scanned root, using "/" for the root itself.
+type Ecosystems struct {
+	GoModDirs     []string   // directories containing go.mod
+	NPMDirs       []string   // directories containing package.json
+	GitHubActions bool       // .github/workflows exists
+	XplatPins     []XplatPin // binary.source repo+version pins in xplat.yaml files
+}
+
+// XplatPin is a tool version pinned in an xplat.yaml binary source.
+type XplatPin struct {
+	File    string // path relative to the scanned root
+	Binary  string // binary.name
+	Repo    string // owner/repo
+	Version string // pinned tag or branch
+}
+
+// IsEmpty returns true if no ecosystems were detected.
+func (e *Ecosystems) IsEmpty() bool {
+	return len(e.GoModDirs) == 0 && len(e.NPMDirs) == 0 && !e.GitHubActions && len(e.XplatPins) == 0
+}
+
+// DetectEcosystems scans baseDir for Go modules, npm packages, GitHub Actions
+// workflows and xplat.yaml tool pins.
+func DetectEcosystems(baseDir string) (*Ecosystems, error) {
+	eco := &Ecosystems{}
+	loader := NewLoader()
+
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != baseDir && skipDepDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+		dir := "/" + filepath.ToSlash(filepath.Dir(rel))
+		if dir == "/." {
+			dir = "/"
+		}
+
+		switch d.Name() {
+		case "go.mod":
+			eco.GoModDirs = append(eco.GoModDirs, dir)
+		case "package.json":
+			eco.NPMDirs = append(eco.NPMDirs, dir)
+		case "xplat.yaml":
+			m, err := loader.LoadFile(path)
+			if err != nil {
+				// Not every xplat.yaml is a valid manifest (e.g. fixtures); skip it
+				return nil
+			}
+			if pin, ok := xplatPin(m); ok {
+				pin.File = filepath.ToSlash(rel)
+				eco.XplatPins = append(eco.XplatPins, pin)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", baseDir, err)
+	}
+
+	if info, err := os.Stat(filepath.Join(baseDir, ".github", "workflows")); err == nil && info.IsDir() {
+		eco.GitHubActions = true
+	}
+
+	sort.Strings(eco.GoModDirs)
+	sort.Strings(eco.NPMDirs)
+	return eco, nil
+}
+
+// xplatPin extracts a pinned GitHub repo+version from a manifest's binary source.
+func xplatPin(m *Manifest) (XplatPin, bool) {
+	if m.Binary == nil || !m.Binary.Source.IsExternalRepo() || m.Binary.Source.Version == "" {
+		return XplatPin{}, false
+	}
+
+	repo := m.Binary.Source.Repo
+	repo = strings.TrimPrefix(repo, "https://")
+	repo = strings.TrimPrefix(repo, "github.com/")
+	repo = strings.TrimSuffix(repo, ".git")
+	if strings.Count(repo, "/") != 1 {
+		// Only github.com owner/repo sources can be tracked
+		return XplatPin{}, false
+	}
+
+	return XplatPin{Binary: m.Binary.Name, Repo: repo, Version: m.Binary.Source.Version}, true
+}
+
+// xplatPinRegex matches the repo + version pair in an xplat.yaml binary source.
+// Renovate uses named groups to extract the dependency and current version.
+const xplatPinRegex = `repo:\s*["']?(?:https://)?(?:github\.com/)?(?<depName>[\w.-]+/[\w.-]+?)(?:\.git)?["']?\s*\n\s*version:\s*["']?(?<currentValue>[^\s"']+)`
+
+// renovateConfig is the subset of renovate.json that xplat generates.
+type renovateConfig struct {
+	Schema          string            `json:"$schema"`
+	Extends         []string          `json:"extends"`
+	EnabledManagers []string          `json:"enabledManagers"`
+	PackageRules    []renovateRule    `json:"packageRules,omitempty"`
+	CustomManagers  []renovateManager `json:"customManagers,omitempty"`
+}
+
+type renovateRule struct {
+	MatchManagers []string `json:"matchManagers"`
+	GroupName     string   `json:"groupName"`
+}
+
+type renovateManager struct {
+	CustomType          string   `json:"customType"`
+	Description         string   `json:"description"`
+	ManagerFilePatterns []string `json:"managerFilePatterns"`
+	MatchStrings        []string `json:"matchStrings"`
+	DatasourceTemplate  string   `json:"datasourceTemplate"`
+}
+
+// RenderRenovate renders a renovate.json scoped to the detected ecosystems.
+func RenderRenovate(eco *Ecosystems) ([]byte, error) {
+	cfg := renovateConfig{
+		Schema:  "https://docs.renovatebot.com/renovate-schema.json",
+		Extends: []string{"config:recommended"},
+	}
+
+	if len(eco.GoModDirs) > 0 {
+		cfg.EnabledManagers = append(cfg.EnabledManagers, "gomod")
+		cfg.PackageRules = append(cfg.PackageRules, renovateRule{
+			MatchManagers: []string{"gomod"},
+			GroupName:     "go modules",
+		})
+	}
+	if len(eco.NPMDirs) > 0 {
+		cfg.EnabledManagers = append(cfg.EnabledManagers, "npm")
+		cfg.PackageRules = append(cfg.PackageRules, renovateRule{
+			MatchManagers: []string{"npm"},
+			GroupName:     "npm packages",
+		})
+	}
+	if eco.GitHubActions {
+		cfg.EnabledManagers = append(cfg.EnabledManagers, "github-actions")
+		cfg.PackageRules = append(cfg.PackageRules, renovateRule{
+			MatchManagers: []string{"github-actions"},
+			GroupName:     "github actions",
+		})
+	}
+	if len(eco.XplatPins) > 0 {
+		cfg.EnabledManagers = append(cfg.EnabledManagers, "custom.regex")
+		cfg.CustomManagers = append(cfg.CustomManagers, renovateManager{
+			CustomType:          "regex",
+			Description:         "xplat.yaml binary source version pins",
+			ManagerFilePatterns: []string{`/(^|/)xplat\.yaml$/`},
+			MatchStrings:        []string{xplatPinRegex},
+			DatasourceTemplate:  "github-tags",
+		})
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal renovate config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// RenderDependabot renders a .github/dependabot.yml scoped to the detected ecosystems.
+// Dependabot has no custom managers, so xplat.yaml pins are listed as a comment only.
+func RenderDependabot(eco *Ecosystems) ([]byte, error) {
+	data := templates.DependabotData{
+		GoModDirs:     eco.GoModDirs,
+		NPMDirs:       eco.NPMDirs,
+		GitHubActions: eco.GitHubActions,
+	}
+	for _, pin := range eco.XplatPins {
+		data.UntrackedPins = append(data.UntrackedPins, fmt.Sprintf("%s: %s@%s", pin.File, pin.Repo, pin.Version))
+	}
+
+	content, err := templates.RenderProject("dependabot.yml.tmpl", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render dependabot config: %w", err)
+	}
+	return content, nil
+}
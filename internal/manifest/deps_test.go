@@ -0,0 +1,104 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDetectEcosystems(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                          "module example.com/app\n",
+		"web/package.json":                "{}\n",
+		"web/node_modules/x/package.json": "{}\n",
+		".github/workflows/ci.yml":        "name: ci\n",
+		"tools/xplat.yaml": `name: tool
+version: main
+binary:
+  name: tool
+  source:
+    repo: https://github.com/owner/tool.git
+    version: v1.2.3
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	eco, err := DetectEcosystems(root)
+	if err != nil {
+		t.Fatalf("DetectEcosystems failed: %v", err)
+	}
+
+	if len(eco.GoModDirs) != 1 || eco.GoModDirs[0] != "/" {
+		t.Errorf("GoModDirs = %v, want [/]", eco.GoModDirs)
+	}
+	if len(eco.NPMDirs) != 1 || eco.NPMDirs[0] != "/web" {
+		t.Errorf("NPMDirs = %v, want [/web] (node_modules skipped)", eco.NPMDirs)
+	}
+	if !eco.GitHubActions {
+		t.Error("expected GitHubActions to be detected")
+	}
+	if len(eco.XplatPins) != 1 || eco.XplatPins[0].Repo != "owner/tool" || eco.XplatPins[0].Version != "v1.2.3" {
+		t.Errorf("XplatPins = %+v, want owner/tool@v1.2.3", eco.XplatPins)
+	}
+
+	data, err := RenderRenovate(eco)
+	if err != nil {
+		t.Fatalf("RenderRenovate failed: %v", err)
+	}
+	var cfg renovateConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("renovate.json is not valid JSON: %v", err)
+	}
+	if got := strings.Join(cfg.EnabledManagers, ","); got != "gomod,npm,github-actions,custom.regex" {
+		t.Errorf("enabledManagers = %s", got)
+	}
+
+	dependabot, err := RenderDependabot(eco)
+	if err != nil {
+		t.Fatalf("RenderDependabot failed: %v", err)
+	}
+	for _, want := range []string{"package-ecosystem: gomod", `directory: "/web"`, "package-ecosystem: github-actions", "owner/tool@v1.2.3"} {
+		if !strings.Contains(string(dependabot), want) {
+			t.Errorf("dependabot.yml missing %q:\n%s", want, dependabot)
+		}
+	}
+}
+
+func TestXplatPinRegex(t *testing.T) {
+	re := regexp.MustCompile(xplatPinRegex)
+
+	tests := []struct {
+		input   string
+		dep     string
+		version string
+	}{
+		{"    repo: https://github.com/owner/tool.git\n    version: v1.2.3\n", "owner/tool", "v1.2.3"},
+		{"    repo: github.com/owner/tool\n    version: \"v0.9.0\"\n", "owner/tool", "v0.9.0"},
+	}
+
+	for _, tt := range tests {
+		m := re.FindStringSubmatch(tt.input)
+		if m == nil {
+			t.Errorf("regex did not match %q", tt.input)
+			continue
+		}
+		if dep := m[re.SubexpIndex("depName")]; dep != tt.dep {
+			t.Errorf("depName = %q, want %q", dep, tt.dep)
+		}
+		if v := m[re.SubexpIndex("currentValue")]; v != tt.version {
+			t.Errorf("currentValue = %q, want %q", v, tt.version)
+		}
+	}
+}
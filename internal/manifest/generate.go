@@ -10,9 +10,9 @@ import (
 	"github.com/joeblew999/xplat/internal/templates"
 )
 
-// portEnvVar derives the environment variable name for a process port.
+// PortEnvVar derives the environment variable name for a process port.
 // e.g., "web" -> "WEB_PORT", "api-server" -> "API_SERVER_PORT"
-func portEnvVar(processName string) string {
+func PortEnvVar(processName string) string {
 	name := strings.ToUpper(processName)
 	name = strings.ReplaceAll(name, "-", "_")
 	return name + "_PORT"
@@ -103,9 +103,16 @@ func (g *Generator) GenerateProcessCompose(outputPath string) error {
 				Namespace:  p.Namespace,
 				DependsOn:  p.DependsOn,
 				Port:       p.Port,
-				PortEnvVar: portEnvVar(name),
+				PortEnvVar: PortEnvVar(name),
 				HealthPath: p.HealthPath,
 				HTTPS:      p.HTTPS,
+				EnvProfiles: p.EnvProfiles,
+			}
+			if p.Worker != nil {
+				input.Worker = &processcompose.WorkerInput{
+					Dir:  p.Worker.Dir,
+					Vars: p.Worker.Vars,
+				}
 			}
 			if p.Readiness != nil {
 				input.Readiness = &processcompose.ReadinessConfig{
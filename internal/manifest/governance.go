@@ -0,0 +1,89 @@
+package manifest
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/taskfile"
+	"github.com/joeblew999/xplat/internal/templates"
+)
+
+// GovernanceFiles are the files 'xplat gen governance' writes, relative to
+// the repo root, in the order they are generated.
+var GovernanceFiles = []struct {
+	Path     string
+	Template string
+}{
+	{".github/CODEOWNERS", "codeowners.tmpl"},
+	{".github/ISSUE_TEMPLATE/bug_report.md", "bug_report.md.tmpl"},
+	{".github/ISSUE_TEMPLATE/feature_request.md", "feature_request.md.tmpl"},
+	{".github/pull_request_template.md", "pull_request_template.md.tmpl"},
+}
+
+// checklistTasks are the tasks put in the issue and PR checklists, in
+// order, when the repo's Taskfile defines them. They match the tasks the
+// generated CI workflow runs.
+var checklistTasks = []string{"build", "test", "lint"}
+
+// CodeOwners returns the author and maintainers as CODEOWNERS owners
+// ("@user", "@org/team" or an email address), without duplicates.
+// Entries that are neither a handle nor an email (e.g. "Jane Doe") are skipped.
+func (m *Manifest) CodeOwners() []string {
+	var owners []string
+	seen := make(map[string]bool)
+	for _, name := range append([]string{m.Author}, m.Maintainers...) {
+		owner := strings.TrimSpace(name)
+		if owner == "" || strings.ContainsAny(owner, " <>") {
+			continue
+		}
+		if !strings.Contains(owner, "@") || strings.HasPrefix(owner, "@") {
+			owner = "@" + strings.TrimPrefix(owner, "@")
+		}
+		if key := strings.ToLower(owner); !seen[key] {
+			seen[key] = true
+			owners = append(owners, owner)
+		}
+	}
+	return owners
+}
+
+// GovernanceTasks returns the checklist tasks defined in the Taskfile in
+// repoDir. Without a Taskfile it returns all of them, since the generated
+// CI workflow expects them to exist.
+func GovernanceTasks(repoDir string) []string {
+	tf, err := taskfile.Parse(filepath.Join(repoDir, "Taskfile.yml"))
+	if err != nil {
+		return checklistTasks
+	}
+	var tasks []string
+	for _, name := range checklistTasks {
+		if task, ok := tf.GetTask(name); ok && !task.Internal {
+			tasks = append(tasks, name)
+		}
+	}
+	return tasks
+}
+
+// RenderGovernance renders GovernanceFiles for a manifest, keyed by path.
+// tasks are embedded in the bug report reproduction steps and the PR checklist.
+func RenderGovernance(m *Manifest, tasks []string) (map[string][]byte, error) {
+	data := templates.GovernanceData{
+		Name:   m.Name,
+		Owners: m.CodeOwners(),
+		Tasks:  tasks,
+	}
+	if len(data.Owners) == 0 {
+		return nil, fmt.Errorf("xplat.yaml needs an author or maintainers to generate CODEOWNERS")
+	}
+
+	files := make(map[string][]byte, len(GovernanceFiles))
+	for _, f := range GovernanceFiles {
+		content, err := templates.RenderProject(f.Template, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", f.Path, err)
+		}
+		files[f.Path] = content
+	}
+	return files, nil
+}
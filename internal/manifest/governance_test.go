@@ -0,0 +1,55 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	m := &Manifest{
+		Author:      "joeblew999",
+		Maintainers: []string{"@octocat", "org/team", "Jane Doe", "ops@example.com", "JoeBlew999"},
+	}
+	want := []string{"@joeblew999", "@octocat", "@org/team", "ops@example.com"}
+	if got := m.CodeOwners(); !reflect.DeepEqual(got, want) {
+		t.Errorf("CodeOwners() = %v, want %v", got, want)
+	}
+}
+
+func TestRenderGovernance(t *testing.T) {
+	dir := t.TempDir()
+	taskfile := "version: '3'\ntasks:\n  build:\n    cmds: [go build]\n  lint:\n    cmds: [go vet]\n  test:\n    internal: true\n"
+	if err := os.WriteFile(filepath.Join(dir, "Taskfile.yml"), []byte(taskfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := GovernanceTasks(dir)
+	if !reflect.DeepEqual(tasks, []string{"build", "lint"}) {
+		t.Errorf("GovernanceTasks() = %v", tasks)
+	}
+	if got := GovernanceTasks(t.TempDir()); !reflect.DeepEqual(got, checklistTasks) {
+		t.Errorf("GovernanceTasks() without Taskfile = %v", got)
+	}
+
+	files, err := RenderGovernance(&Manifest{Name: "plat-x", Author: "joeblew999"}, tasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(GovernanceFiles) {
+		t.Errorf("rendered %d files", len(files))
+	}
+	if owners := string(files[".github/CODEOWNERS"]); !strings.Contains(owners, "\n* @joeblew999\n") {
+		t.Errorf("CODEOWNERS:\n%s", owners)
+	}
+	pr := string(files[".github/pull_request_template.md"])
+	if !strings.Contains(pr, "- [ ] `xplat task lint` passes") || strings.Contains(pr, "task test") {
+		t.Errorf("PR template:\n%s", pr)
+	}
+
+	if _, err := RenderGovernance(&Manifest{Name: "plat-x"}, tasks); err == nil {
+		t.Error("expected error without owners")
+	}
+}
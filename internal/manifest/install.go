@@ -9,6 +9,8 @@ import (
 	"runtime"
 	"strings"
 	"text/template"
+
+	"github.com/joeblew999/xplat/internal/platform"
 )
 
 // Installer installs binaries from manifests.
@@ -172,48 +174,27 @@ func (i *Installer) installGitHub(name string, gh *GitHubSource, version string)
 		return fmt.Errorf("invalid asset template: %w", err)
 	}
 
-	var buf bytes.Buffer
-	data := map[string]string{
-		"OS":   runtime.GOOS,
-		"ARCH": runtime.GOARCH,
-	}
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Errorf("asset template failed: %w", err)
-	}
-	assetName = buf.String()
-
 	// Build download URL
 	ref := version
 	if ref == "" || ref == "dev" {
 		ref = "latest"
 	}
 
-	var downloadURL string
-	if ref == "latest" {
-		downloadURL = fmt.Sprintf("https://github.com/%s/releases/latest/download/%s",
-			gh.Repo, assetName)
-	} else {
-		downloadURL = fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
-			gh.Repo, ref, assetName)
-	}
-
-	if i.verbose {
-		fmt.Printf("Downloading: %s\n", downloadURL)
-	}
-
-	// Use xplat fetch to download
-	xplatPath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to find xplat: %w", err)
-	}
-
 	binPath := i.binaryPath(name)
-	cmd := exec.Command(xplatPath, "fetch", downloadURL, "-o", binPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	err = i.fetchBinary(binPath, func(data map[string]string) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("asset template failed: %w", err)
+		}
+		if ref == "latest" {
+			return fmt.Sprintf("https://github.com/%s/releases/latest/download/%s",
+				gh.Repo, buf.String()), nil
+		}
+		return fmt.Sprintf("https://github.com/%s/releases/download/%s/%s",
+			gh.Repo, ref, buf.String()), nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Make executable
@@ -232,6 +213,48 @@ func (i *Installer) installGitHub(name string, gh *GitHubSource, version string)
 	return nil
 }
 
+// fetchBinary downloads a binary to binPath with xplat fetch, from the URL
+// urlFor builds from the OS and ARCH template data. ARCH is the machine's
+// native arch, so an emulated xplat (Rosetta 2, Windows x64 emulation)
+// installs native binaries; if that download fails, the arch the OS
+// emulates is tried next.
+func (i *Installer) fetchBinary(binPath string, urlFor func(data map[string]string) (string, error)) error {
+	xplatPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find xplat: %w", err)
+	}
+
+	plat := platform.Current()
+	tried := map[string]bool{}
+	var fetchErr error
+	for n, arch := range plat.Archs() {
+		downloadURL, err := urlFor(map[string]string{"OS": plat.OS, "ARCH": arch})
+		if err != nil {
+			return err
+		}
+		if tried[downloadURL] {
+			break // The URL doesn't depend on ARCH
+		}
+		tried[downloadURL] = true
+
+		if n > 0 {
+			_, note := platform.Fallback(plat.OS, plat.Arch)
+			fmt.Printf("Note: %s\n", note)
+		}
+		if i.verbose {
+			fmt.Printf("Downloading: %s\n", downloadURL)
+		}
+
+		cmd := exec.Command(xplatPath, "fetch", downloadURL, "-o", binPath)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if fetchErr = cmd.Run(); fetchErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("download failed: %w", fetchErr)
+}
+
 // installNPM installs using npm/bun.
 func (i *Installer) installNPM(name, pkg, version string) error {
 	// Prefer bun if available
@@ -270,34 +293,17 @@ func (i *Installer) installURL(name, urlTemplate, version string) error {
 		return fmt.Errorf("invalid URL template: %w", err)
 	}
 
-	var buf bytes.Buffer
-	data := map[string]string{
-		"OS":      runtime.GOOS,
-		"ARCH":    runtime.GOARCH,
-		"VERSION": version,
-	}
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return fmt.Errorf("URL template failed: %w", err)
-	}
-	downloadURL := buf.String()
-
-	if i.verbose {
-		fmt.Printf("Downloading: %s\n", downloadURL)
-	}
-
-	// Use xplat fetch
-	xplatPath, err := os.Executable()
-	if err != nil {
-		return fmt.Errorf("failed to find xplat: %w", err)
-	}
-
 	binPath := i.binaryPath(name)
-	cmd := exec.Command(xplatPath, "fetch", downloadURL, "-o", binPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	err = i.fetchBinary(binPath, func(data map[string]string) (string, error) {
+		data["VERSION"] = version
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return "", fmt.Errorf("URL template failed: %w", err)
+		}
+		return buf.String(), nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Make executable
@@ -1,6 +1,7 @@
 package manifest
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,9 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
 )
 
 const (
@@ -24,6 +28,11 @@ const (
 // Loader loads manifests from local files or remote URLs.
 type Loader struct {
 	httpClient *http.Client
+
+	// GitHub token, resolved on the first GitHub fetch
+	tokenOnce sync.Once
+	token     string
+	tokenErr  error
 }
 
 // NewLoader creates a new manifest loader.
@@ -51,8 +60,24 @@ func (l *Loader) LoadDir(dir string) (*Manifest, error) {
 }
 
 // LoadURL loads a manifest from a remote URL.
+// GitHub URLs are fetched with the ghauth credential chain when a credential
+// is configured, so manifests in private repos load too.
 func (l *Loader) LoadURL(url string) (*Manifest, error) {
-	resp, err := l.httpClient.Get(url)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if ghauth.IsGitHubURL(url) {
+		token, err := l.githubToken()
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := l.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
@@ -84,6 +109,22 @@ func (l *Loader) LoadGitHub(repo string) (*Manifest, error) {
 	return l.LoadURL(url)
 }
 
+// githubToken resolves the GitHub credential once per loader, as a GitHub
+// App token is minted on each resolve.
+func (l *Loader) githubToken() (string, error) {
+	l.tokenOnce.Do(func() {
+		cred, err := ghauth.ResolveCredential(context.Background())
+		if err != nil {
+			l.tokenErr = fmt.Errorf("failed to resolve GitHub credentials: %w", err)
+			return
+		}
+		if cred != nil {
+			l.token = cred.Token
+		}
+	})
+	return l.token, l.tokenErr
+}
+
 // parse parses manifest YAML data.
 func (l *Loader) parse(data []byte, source string) (*Manifest, error) {
 	var m Manifest
@@ -0,0 +1,103 @@
+package manifest
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultSLOWindow is the rolling window an SLO is measured over when the
+// manifest does not set one.
+const DefaultSLOWindow = 7 * 24 * time.Hour
+
+// SLOConfig declares a service-level objective. Monitors read it to set
+// their alert thresholds: sitecheck fails checks slower than Latency, and
+// compliance is the share of passing checks over Window.
+//
+//	slos:
+//	  - name: api
+//	    process: api          # or endpoint: https://api.example.com/health
+//	    availability: 99.9
+//	    latency: 500ms
+type SLOConfig struct {
+	Name string `yaml:"name"`
+
+	// Endpoint is the URL to probe. Leave empty to derive it from Process.
+	Endpoint string `yaml:"endpoint,omitempty"`
+
+	// Process names a manifest process; its port and health_path form the endpoint
+	Process string `yaml:"process,omitempty"`
+
+	// Availability is the target share of passing checks, in percent (e.g. 99.9)
+	Availability float64 `yaml:"availability"`
+
+	// Latency is the slowest a passing check may be, as a Go duration (e.g. 500ms)
+	Latency string `yaml:"latency,omitempty"`
+
+	// Window is the rolling window compliance is measured over (default: 168h)
+	Window string `yaml:"window,omitempty"`
+}
+
+// LatencyTarget returns the parsed latency target, or 0 if none is set.
+func (s SLOConfig) LatencyTarget() time.Duration {
+	d, _ := time.ParseDuration(s.Latency)
+	return d
+}
+
+// WindowDuration returns the parsed window, or DefaultSLOWindow.
+func (s SLOConfig) WindowDuration() time.Duration {
+	if d, err := time.ParseDuration(s.Window); err == nil && d > 0 {
+		return d
+	}
+	return DefaultSLOWindow
+}
+
+// HasSLOs returns true if the manifest declares SLOs.
+func (m *Manifest) HasSLOs() bool {
+	return len(m.SLOs) > 0
+}
+
+// SLOEndpoint returns the URL an SLO is measured against: its Endpoint, or
+// the local health URL of its process.
+func (m *Manifest) SLOEndpoint(s SLOConfig) string {
+	if s.Endpoint != "" {
+		return s.Endpoint
+	}
+	p, ok := m.Processes[s.Process]
+	if !ok || p.Port == 0 {
+		return ""
+	}
+	scheme := "http"
+	if p.HTTPS {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://localhost:%d%s", scheme, p.Port, p.HealthPath)
+}
+
+// validateSLO returns the problems with one SLO declaration.
+func (m *Manifest) validateSLO(s SLOConfig) []string {
+	var problems []string
+	if s.Name == "" {
+		problems = append(problems, "slo has no name")
+		s.Name = "?"
+	}
+	if s.Availability <= 0 || s.Availability > 100 {
+		problems = append(problems, fmt.Sprintf("slo '%s' availability must be between 0 and 100", s.Name))
+	}
+	if s.Latency != "" {
+		if d, err := time.ParseDuration(s.Latency); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("slo '%s' latency '%s' is not a duration", s.Name, s.Latency))
+		}
+	}
+	if s.Window != "" {
+		if d, err := time.ParseDuration(s.Window); err != nil || d <= 0 {
+			problems = append(problems, fmt.Sprintf("slo '%s' window '%s' is not a duration", s.Name, s.Window))
+		}
+	}
+	switch {
+	case s.Endpoint == "" && s.Process == "":
+		problems = append(problems, fmt.Sprintf("slo '%s' needs an endpoint or a process", s.Name))
+	case s.Endpoint == "" && m.SLOEndpoint(s) == "":
+		problems = append(problems, fmt.Sprintf("slo '%s' process '%s' is not a process with a port", s.Name, s.Process))
+	}
+	return problems
+}
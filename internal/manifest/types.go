@@ -9,9 +9,12 @@ type Manifest struct {
 	Version     string `yaml:"version"`
 	Description string `yaml:"description"`
 	Author      string `yaml:"author"`
+	Maintainers []string `yaml:"maintainers,omitempty"` // GitHub users or teams (e.g., "octocat", "org/team")
 	License     string `yaml:"license"`
+	Copyright   string `yaml:"copyright,omitempty"` // LICENSE copyright holder, defaults to author
 	Repo        string `yaml:"repo,omitempty"`     // GitHub repo name (e.g., "plat-rush"), defaults to name
 	Language    string `yaml:"language,omitempty"` // Primary language: go, rust, bun (for CI setup)
+	Archetype   string `yaml:"archetype,omitempty"` // Bootstrap archetype: go-service, hugo-site, worker, cli
 
 	Binary       *BinaryConfig            `yaml:"binary,omitempty"`
 	Taskfile     *TaskfileConfig          `yaml:"taskfile,omitempty"`
@@ -19,6 +22,9 @@ type Manifest struct {
 	Env          *EnvConfig               `yaml:"env,omitempty"`
 	Dependencies *DependenciesConfig      `yaml:"dependencies,omitempty"`
 	Gitignore    *GitignoreConfig         `yaml:"gitignore,omitempty"`
+	SLOs         []SLOConfig              `yaml:"slos,omitempty"`
+	Funding      *FundingConfig           `yaml:"funding,omitempty"`
+	Security     *SecurityConfig          `yaml:"security,omitempty"`
 	Core         bool                     `yaml:"core,omitempty"` // Core infrastructure package
 }
 
@@ -30,6 +36,24 @@ func (m *Manifest) RepoName() string {
 	return m.Name
 }
 
+// FundingConfig lists sponsor links for .github/FUNDING.yml. The keys are
+// GitHub's FUNDING.yml keys.
+type FundingConfig struct {
+	GitHub         []string `yaml:"github,omitempty"` // GitHub Sponsors users
+	Patreon        string   `yaml:"patreon,omitempty"`
+	OpenCollective string   `yaml:"open_collective,omitempty"`
+	KoFi           string   `yaml:"ko_fi,omitempty"`
+	Liberapay      string   `yaml:"liberapay,omitempty"`
+	BuyMeACoffee   string   `yaml:"buy_me_a_coffee,omitempty"`
+	Custom         []string `yaml:"custom,omitempty"` // Other sponsor URLs
+}
+
+// SecurityConfig defines the SECURITY.md policy.
+type SecurityConfig struct {
+	Contact   string `yaml:"contact,omitempty"`   // Email or URL for reports (default: GitHub private advisories)
+	Supported string `yaml:"supported,omitempty"` // Supported versions (default: the latest release)
+}
+
 // BinaryConfig defines how to install the package binary.
 type BinaryConfig struct {
 	Name           string        `yaml:"name"`
@@ -90,6 +114,19 @@ type ProcessConfig struct {
 	Readiness  *ReadinessProbe  `yaml:"readiness,omitempty"`
 	Schedule   *ScheduleConfig  `yaml:"schedule,omitempty"` // v1.87.0: cron/interval scheduling
 	DevMode    bool             `yaml:"dev_mode,omitempty"` // Use "task dev" for hot reload
+	EnvProfiles []string        `yaml:"env_profiles,omitempty"` // .xplat/profiles/<name>.env injected into this process only
+	Worker      *WorkerConfig   `yaml:"worker,omitempty"`       // Run a Cloudflare Worker locally instead of Command
+}
+
+// WorkerConfig runs a Cloudflare Worker (e.g. workers/sync-cf, built with
+// syumai/workers) locally under workerd via "wrangler dev --local", so the
+// Worker side of a pipeline works offline. Command is ignored when set.
+type WorkerConfig struct {
+	// Dir is the Worker directory containing wrangler.toml, relative to the project root.
+	Dir string `yaml:"dir"`
+
+	// Vars are Worker environment bindings (e.g. SYNC_ENDPOINT), passed as --var KEY:VALUE.
+	Vars map[string]string `yaml:"vars,omitempty"`
 }
 
 // ScheduleConfig defines scheduling for a process (process-compose v1.87.0+).
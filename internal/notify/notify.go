@@ -0,0 +1,227 @@
+// Package notify routes notifications from xplat subsystems (process
+// post-mortems, budget alerts, watchdogs, pollers) to chat webhooks.
+//
+// Routes are read from ~/.xplat/config/notify.yaml:
+//
+//	routes:
+//	  - sources: ["process/*"]     # path.Match patterns on Message.Source
+//	    min_severity: warning      # info (default), warning or critical
+//	    webhook: https://hooks.slack.com/services/...
+//	  - webhook: https://discord.com/api/webhooks/...
+//
+// XPLAT_NOTIFY_WEBHOOK adds a catch-all route, so a single webhook needs no
+// config file. A message goes to every route it matches.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/config"
+)
+
+// EnvWebhook is the env var holding a catch-all webhook URL.
+const EnvWebhook = "XPLAT_NOTIFY_WEBHOOK"
+
+// Severity orders messages for min_severity filtering.
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) rank() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Message is one notification.
+type Message struct {
+	Source   string            `json:"source"` // e.g. process/api, synccf/budget
+	Severity Severity          `json:"severity"`
+	Title    string            `json:"title"`
+	Text     string            `json:"text,omitempty"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+}
+
+// Render returns the message as a short chat message.
+func (m *Message) Render() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s", m.Severity, m.Title)
+	if m.Text != "" {
+		b.WriteString("\n" + m.Text)
+	}
+	keys := make([]string, 0, len(m.Fields))
+	for k := range m.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "\n- %s: %s", k, m.Fields[k])
+	}
+	return b.String()
+}
+
+// Route sends matching messages to a webhook.
+type Route struct {
+	Sources     []string `yaml:"sources,omitempty"` // Empty matches every source
+	MinSeverity Severity `yaml:"min_severity,omitempty"`
+	Webhook     string   `yaml:"webhook"`
+}
+
+// Matches reports whether a message should go to this route.
+func (r Route) Matches(m *Message) bool {
+	if m.Severity.rank() < r.MinSeverity.rank() {
+		return false
+	}
+	if len(r.Sources) == 0 {
+		return true
+	}
+	for _, pattern := range r.Sources {
+		if ok, _ := path.Match(pattern, m.Source); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Router delivers messages to every matching route.
+type Router struct {
+	Routes []Route `yaml:"routes"`
+
+	Client *http.Client
+}
+
+// ConfigPath returns the router config file.
+func ConfigPath() string {
+	return filepath.Join(config.XplatConfig(), "notify.yaml")
+}
+
+// LoadRouter reads the router config from path, plus the
+// XPLAT_NOTIFY_WEBHOOK catch-all route. A missing file is not an error.
+func LoadRouter(path string) (*Router, error) {
+	r := &Router{}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, r); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	case !os.IsNotExist(err):
+		return nil, fmt.Errorf("failed to read notify config: %w", err)
+	}
+	for i, route := range r.Routes {
+		if route.Webhook == "" {
+			return nil, fmt.Errorf("%s: route %d has no webhook", path, i+1)
+		}
+	}
+	if url := os.Getenv(EnvWebhook); url != "" {
+		r.Routes = append(r.Routes, Route{Webhook: url})
+	}
+	return r, nil
+}
+
+// Default loads the router from ConfigPath and the environment.
+func Default() (*Router, error) {
+	return LoadRouter(ConfigPath())
+}
+
+// Enabled reports whether the router has any routes.
+func (r *Router) Enabled() bool {
+	return r != nil && len(r.Routes) > 0
+}
+
+// Notify sends a message to every matching route. Delivery to one route
+// failing does not stop the others; the errors are joined.
+func (r *Router) Notify(ctx context.Context, m *Message) error {
+	if !r.Enabled() {
+		return nil
+	}
+	if m.Time.IsZero() {
+		m.Time = time.Now().UTC()
+	}
+	if m.Severity == "" {
+		m.Severity = SeverityInfo
+	}
+
+	var errs []error
+	for _, route := range r.Routes {
+		if !route.Matches(m) {
+			continue
+		}
+		if err := r.post(ctx, route.Webhook, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Body returns the request body for a message, formatted for the webhook's
+// service: Slack and Discord get a chat message, anything else the Message
+// as JSON.
+func Body(url string, m *Message) ([]byte, error) {
+	return WebhookBody(url, m.Render(), m)
+}
+
+// WebhookBody returns a webhook request body: text as a chat message for
+// Slack and Discord URLs, v as JSON for any other URL.
+func WebhookBody(url, text string, v interface{}) ([]byte, error) {
+	switch {
+	case strings.Contains(url, "hooks.slack.com"):
+		return json.Marshal(map[string]string{"text": text})
+	case strings.Contains(url, "discord.com/api/webhooks"), strings.Contains(url, "discordapp.com/api/webhooks"):
+		if len(text) > 2000 { // Discord message limit
+			text = text[:1997] + "..."
+		}
+		return json.Marshal(map[string]string{"content": text})
+	default:
+		return json.Marshal(v)
+	}
+}
+
+func (r *Router) post(ctx context.Context, url string, m *Message) error {
+	body, err := Body(url, m)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRouteMatches(t *testing.T) {
+	route := Route{Sources: []string{"process/*"}, MinSeverity: SeverityWarning}
+	tests := []struct {
+		msg  Message
+		want bool
+	}{
+		{Message{Source: "process/api", Severity: SeverityWarning}, true},
+		{Message{Source: "process/api", Severity: SeverityCritical}, true},
+		{Message{Source: "process/api", Severity: SeverityInfo}, false},
+		{Message{Source: "synccf/budget", Severity: SeverityCritical}, false},
+	}
+	for _, tt := range tests {
+		if got := route.Matches(&tt.msg); got != tt.want {
+			t.Errorf("Matches(%s %s) = %v, want %v", tt.msg.Source, tt.msg.Severity, got, tt.want)
+		}
+	}
+	if !(Route{}).Matches(&Message{Source: "anything"}) {
+		t.Error("empty route should match every message")
+	}
+}
+
+func TestRouterNotify(t *testing.T) {
+	var got []Message
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m Message
+		if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+			t.Error(err)
+		}
+		got = append(got, m)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "notify.yaml")
+	config := "routes:\n" +
+		"  - sources: [\"process/*\"]\n    webhook: " + srv.URL + "/process\n" +
+		"  - min_severity: critical\n    webhook: " + srv.URL + "/fail\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvWebhook, "")
+
+	r, err := LoadRouter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Notify(context.Background(), &Message{Source: "process/api", Title: "down"}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Severity != SeverityInfo || got[0].Time.IsZero() {
+		t.Fatalf("delivered = %+v", got)
+	}
+
+	// A failing route is reported, and does not stop the other routes
+	err = r.Notify(context.Background(), &Message{Source: "process/api", Severity: SeverityCritical, Title: "down"})
+	if err == nil || !strings.Contains(err.Error(), "500") || len(got) != 3 {
+		t.Errorf("err = %v, delivered %d", err, len(got))
+	}
+}
+
+func TestLoadRouterEnv(t *testing.T) {
+	t.Setenv(EnvWebhook, "https://hooks.slack.com/services/x")
+	r, err := LoadRouter(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Routes) != 1 || !r.Enabled() {
+		t.Fatalf("routes = %+v", r.Routes)
+	}
+
+	body, err := Body(r.Routes[0].Webhook, &Message{Severity: SeverityWarning, Title: "t", Fields: map[string]string{"b": "2", "a": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"text":"[warning] t\n- a: 1\n- b: 2"}`; string(body) != want {
+		t.Errorf("Slack body = %s, want %s", body, want)
+	}
+}
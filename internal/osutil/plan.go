@@ -0,0 +1,163 @@
+package osutil
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Op is one file system change an operation would make, with absolute
+// paths, so Taskfile variable expansion can be checked before running.
+type Op struct {
+	Action string // mkdir, copy, move, remove, create, touch, write
+	Path   string
+	Target string // Destination for copy and move
+	Detail string // Extra context, e.g. the file count of a removed directory
+}
+
+func (o Op) String() string {
+	s := o.Action + " " + o.Path
+	if o.Target != "" {
+		s += " -> " + o.Target
+	}
+	if o.Detail != "" {
+		s += " (" + o.Detail + ")"
+	}
+	return s
+}
+
+// Abs returns path made absolute, or path unchanged if that fails.
+func Abs(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// PlanMkdir returns the directories Mkdir would create, outermost first.
+func PlanMkdir(path string, parents bool) ([]Op, error) {
+	abs := Abs(path)
+	if info, err := os.Stat(abs); err == nil {
+		if parents && info.IsDir() {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mkdir %s: file exists", path)
+	}
+
+	var missing []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		missing = append(missing, dir)
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	if len(missing) > 1 && !parents {
+		return nil, fmt.Errorf("mkdir %s: no such file or directory", path)
+	}
+
+	ops := make([]Op, 0, len(missing))
+	for i := len(missing) - 1; i >= 0; i-- {
+		ops = append(ops, Op{Action: "mkdir", Path: missing[i]})
+	}
+	return ops, nil
+}
+
+// PlanRemove returns what Remove would delete. A directory is one op
+// with the number of files in it.
+func PlanRemove(path string, recursive, force bool) ([]Op, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if os.IsNotExist(err) && force {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []Op{{Action: "remove", Path: Abs(path)}}, nil
+	}
+	if !recursive {
+		return nil, fmt.Errorf("%s: is a directory (use recursive to remove)", path)
+	}
+
+	files := 0
+	_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files++
+		}
+		return nil
+	})
+	return []Op{{Action: "remove", Path: Abs(path), Detail: fmt.Sprintf("directory, %d files", files)}}, nil
+}
+
+// PlanCopy returns each file Copy would write, merging into existing
+// directories like Copy does.
+func PlanCopy(src, dst string, recursive bool) ([]Op, error) {
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []Op{{Action: "copy", Path: Abs(src), Target: Abs(dst), Detail: existsDetail(dst)}}, nil
+	}
+	if !recursive {
+		return nil, fmt.Errorf("%s: is a directory (use recursive to copy)", src)
+	}
+
+	var ops []Op
+	err = filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if d.IsDir() {
+			if _, err := os.Stat(target); os.IsNotExist(err) {
+				ops = append(ops, Op{Action: "mkdir", Path: Abs(target)})
+			}
+			return nil
+		}
+		ops = append(ops, Op{Action: "copy", Path: Abs(path), Target: Abs(target), Detail: existsDetail(target)})
+		return nil
+	})
+	return ops, err
+}
+
+// PlanMove returns the rename Move would do.
+func PlanMove(src, dst string) ([]Op, error) {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return nil, err
+	}
+	if dstInfo, err := os.Stat(dst); err == nil && dstInfo.IsDir() {
+		dst = filepath.Join(dst, srcInfo.Name())
+	}
+	return []Op{{Action: "move", Path: Abs(src), Target: Abs(dst), Detail: existsDetail(dst)}}, nil
+}
+
+// PlanTouch returns whether Touch would create the file or update its times.
+func PlanTouch(path string) ([]Op, error) {
+	if _, err := os.Stat(path); err == nil {
+		return []Op{{Action: "touch", Path: Abs(path)}}, nil
+	}
+	return []Op{{Action: "create", Path: Abs(path)}}, nil
+}
+
+// PlanWrite returns the op for writing a whole file.
+func PlanWrite(path string) []Op {
+	return []Op{{Action: "write", Path: Abs(path), Detail: existsDetail(path)}}
+}
+
+// existsDetail notes that an op replaces an existing file.
+func existsDetail(path string) string {
+	if _, err := os.Stat(path); err == nil {
+		return "overwrite"
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package osutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanDoesNotChangeFiles(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sub", "a.txt"), []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := PlanMkdir(filepath.Join(dir, "x", "y"), true)
+	if err != nil || len(ops) != 2 || ops[0].Path != filepath.Join(dir, "x") {
+		t.Errorf("PlanMkdir() = %v, %v", ops, err)
+	}
+	if _, err := PlanMkdir(filepath.Join(dir, "x", "y"), false); err == nil {
+		t.Error("PlanMkdir() without parents: expected error")
+	}
+
+	dst := filepath.Join(dir, "dst")
+	ops, err = PlanCopy(src, dst, true)
+	if err != nil || len(ops) != 3 || ops[2].Target != filepath.Join(dst, "sub", "a.txt") {
+		t.Errorf("PlanCopy() = %v, %v", ops, err)
+	}
+
+	ops, err = PlanRemove(src, true, false)
+	if err != nil || len(ops) != 1 || ops[0].Detail != "directory, 1 files" {
+		t.Errorf("PlanRemove() = %v, %v", ops, err)
+	}
+	if ops, err := PlanRemove(filepath.Join(dir, "missing"), false, true); err != nil || len(ops) != 0 {
+		t.Errorf("PlanRemove() with force = %v, %v", ops, err)
+	}
+
+	for _, p := range []string{filepath.Join(dir, "x"), dst} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s was created by planning", p)
+		}
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Errorf("src removed by planning: %v", err)
+	}
+}
@@ -0,0 +1,432 @@
+package osutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Structured edits ('xplat os jq -i') write a changed value back over the
+// file it was read from. Values that did not change keep their original
+// text, objects keep their key order (new keys go last, sorted) and the
+// file keeps its indentation, line endings and final newline, so the diff
+// shows only the edit. YAML comments on unchanged nodes are kept too.
+
+// IsYAMLFile reports whether path has a YAML extension.
+func IsYAMLFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ReplaceJSON encodes v as JSON in the style of orig, the document v was
+// decoded from.
+func ReplaceJSON(orig []byte, v any) ([]byte, error) {
+	trimmed := bytes.TrimSpace(orig)
+	indent := detectIndent(trimmed, "  ")
+	if len(trimmed) > 0 && !bytes.Contains(trimmed, []byte("\n")) {
+		indent = "" // Single-line document stays on one line
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, v, json.RawMessage(trimmed), indent, 0); err != nil {
+		return nil, err
+	}
+	return matchLineEndings(orig, buf.Bytes()), nil
+}
+
+// writeJSON writes v at depth, reusing the text of orig (the value at the
+// same place in the original document, or nil) where v has not changed.
+func writeJSON(buf *bytes.Buffer, v any, orig json.RawMessage, indent string, depth int) error {
+	if orig != nil {
+		var old any
+		if json.Unmarshal(orig, &old) == nil && reflect.DeepEqual(normalizeNumbers(old), normalizeNumbers(v)) {
+			buf.Write(orig)
+			return nil
+		}
+	}
+
+	newline := func(d int) {
+		if indent != "" {
+			buf.WriteByte('\n')
+			buf.WriteString(strings.Repeat(indent, d))
+		}
+	}
+	sep := ": "
+	if indent == "" {
+		sep = ":"
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		if len(val) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		origKeys, origFields := jsonObject(orig)
+		buf.WriteByte('{')
+		for i, key := range orderKeys(origKeys, val) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(depth + 1)
+			if err := writeJSONScalar(buf, key); err != nil {
+				return err
+			}
+			buf.WriteString(sep)
+			if err := writeJSON(buf, val[key], origFields[key], indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		buf.WriteByte('}')
+	case []any:
+		if len(val) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		var origItems []json.RawMessage
+		_ = json.Unmarshal(orig, &origItems)
+		buf.WriteByte('[')
+		for i, item := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			newline(depth + 1)
+			var o json.RawMessage
+			if i < len(origItems) {
+				o = origItems[i]
+			}
+			if err := writeJSON(buf, item, o, indent, depth+1); err != nil {
+				return err
+			}
+		}
+		newline(depth)
+		buf.WriteByte(']')
+	default:
+		return writeJSONScalar(buf, val)
+	}
+	return nil
+}
+
+// writeJSONScalar writes v without escaping <, > and &.
+func writeJSONScalar(buf *bytes.Buffer, v any) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("cannot encode %v: %w", v, err)
+	}
+	buf.Write(bytes.TrimRight(b.Bytes(), "\n"))
+	return nil
+}
+
+// jsonObject returns the keys of a JSON object in document order and the
+// text of each value. Anything else has no keys.
+func jsonObject(raw json.RawMessage) ([]string, map[string]json.RawMessage) {
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return nil, nil
+	}
+	var keys []string
+	fields := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil
+		}
+		key, _ := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil
+		}
+		if _, dup := fields[key]; !dup {
+			keys = append(keys, key)
+		}
+		fields[key] = value
+	}
+	return keys, fields
+}
+
+// orderKeys returns the keys of m: those in origKeys first, in that order,
+// then the new ones sorted.
+func orderKeys(origKeys []string, m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, k := range origKeys {
+		if _, ok := m[k]; ok {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	var added []string
+	for k := range m {
+		if !seen[k] {
+			added = append(added, k)
+		}
+	}
+	sort.Strings(added)
+	return append(keys, added...)
+}
+
+// normalizeNumbers converts whole numbers to float64, so a value decoded
+// from JSON compares equal to the same value produced by a jq query.
+func normalizeNumbers(v any) any {
+	switch val := v.(type) {
+	case int:
+		return float64(val)
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, x := range val {
+			out[k] = normalizeNumbers(x)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, x := range val {
+			out[i] = normalizeNumbers(x)
+		}
+		return out
+	}
+	return v
+}
+
+// DecodeYAML decodes a single YAML document into the values jq works on:
+// maps, slices, strings, float64/int numbers, bools and nil.
+func DecodeYAML(data []byte) (any, error) {
+	doc, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, nil
+	}
+	return yamlValue(doc)
+}
+
+// ReplaceYAML encodes v as YAML in the style of orig, the document v was
+// decoded from. Comments on unchanged nodes are kept.
+func ReplaceYAML(orig []byte, v any) ([]byte, error) {
+	doc, err := parseYAML(orig)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = &yaml.Node{}
+	}
+	if err := updateYAML(doc, v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(len(detectIndent(bytes.ReplaceAll(orig, []byte("- "), []byte("  ")), "  ")))
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("cannot encode YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	if !bytes.HasSuffix(orig, []byte("\n")) && len(orig) > 0 {
+		out = bytes.TrimRight(out, "\n")
+	}
+	return matchLineEndings(orig, out), nil
+}
+
+// parseYAML returns the content node of a single-document YAML file, or
+// nil for an empty file.
+func parseYAML(data []byte) (*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	var next yaml.Node
+	if dec.Decode(&next) == nil {
+		return nil, fmt.Errorf("multi-document YAML is not supported")
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	return doc.Content[0], nil
+}
+
+// yamlValue converts a node to the value jq sees.
+func yamlValue(n *yaml.Node) (any, error) {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			v, err := yamlValue(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			m[n.Content[i].Value] = v
+		}
+		return m, nil
+	case yaml.SequenceNode:
+		s := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := yamlValue(c)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		return s, nil
+	case yaml.ScalarNode:
+		switch n.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!bool":
+			var b bool
+			err := n.Decode(&b)
+			return b, err
+		case "!!int":
+			var i int64
+			if err := n.Decode(&i); err != nil {
+				var f float64
+				err := n.Decode(&f)
+				return f, err
+			}
+			if i >= math.MinInt && i <= math.MaxInt {
+				return int(i), nil
+			}
+			return float64(i), nil
+		case "!!float":
+			var f float64
+			err := n.Decode(&f)
+			return f, err
+		}
+		return n.Value, nil // Strings, timestamps and custom tags
+	}
+	return nil, fmt.Errorf("unsupported YAML node at line %d", n.Line)
+}
+
+// updateYAML changes n in place to encode v, leaving unchanged nodes (and
+// their comments and quoting) as they are.
+func updateYAML(n *yaml.Node, v any) error {
+	if old, err := yamlValue(n); err == nil && reflect.DeepEqual(normalizeNumbers(old), normalizeNumbers(v)) {
+		return nil
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		if n.Kind == yaml.MappingNode {
+			var content []*yaml.Node
+			var origKeys []string
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i].Value
+				newVal, ok := val[key]
+				if !ok {
+					continue // Deleted
+				}
+				if err := updateYAML(n.Content[i+1], newVal); err != nil {
+					return err
+				}
+				content = append(content, n.Content[i], n.Content[i+1])
+				origKeys = append(origKeys, key)
+			}
+			for _, key := range orderKeys(origKeys, val)[len(origKeys):] {
+				keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+				valueNode, err := newYAMLNode(val[key])
+				if err != nil {
+					return err
+				}
+				content = append(content, keyNode, valueNode)
+			}
+			n.Content = content
+			return nil
+		}
+	case []any:
+		if n.Kind == yaml.SequenceNode {
+			if len(val) < len(n.Content) {
+				n.Content = n.Content[:len(val)]
+			}
+			for i, item := range val {
+				if i < len(n.Content) {
+					if err := updateYAML(n.Content[i], item); err != nil {
+						return err
+					}
+					continue
+				}
+				itemNode, err := newYAMLNode(item)
+				if err != nil {
+					return err
+				}
+				n.Content = append(n.Content, itemNode)
+			}
+			return nil
+		}
+	}
+
+	// A different kind of value: replace the node, keeping its comments
+	// and, for strings replacing strings, its quoting
+	replacement, err := newYAMLNode(v)
+	if err != nil {
+		return err
+	}
+	if _, isString := v.(string); isString && n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str" {
+		replacement.Style = n.Style
+	}
+	replacement.HeadComment, replacement.LineComment, replacement.FootComment = n.HeadComment, n.LineComment, n.FootComment
+	*n = *replacement
+	return nil
+}
+
+// newYAMLNode encodes v as a new node, with object keys sorted.
+func newYAMLNode(v any) (*yaml.Node, error) {
+	var n yaml.Node
+	if f, ok := v.(float64); ok && f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		v = int64(f) // jq numbers are floats; write 3, not 3.0
+	}
+	if err := n.Encode(v); err != nil {
+		return nil, fmt.Errorf("cannot encode %v as YAML: %w", v, err)
+	}
+	return &n, nil
+}
+
+// detectIndent returns the leading whitespace of the first indented line
+// of data, or def if no line is indented.
+func detectIndent(data []byte, def string) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if ws := line[:len(line)-len(trimmed)]; ws != "" && strings.TrimSpace(trimmed) != "" {
+			return ws
+		}
+	}
+	return def
+}
+
+// matchLineEndings gives out the line endings and final newline of orig.
+func matchLineEndings(orig, out []byte) []byte {
+	out = bytes.ReplaceAll(out, []byte("\r\n"), []byte("\n"))
+	out = bytes.TrimRight(out, "\n")
+	if bytes.HasSuffix(bytes.TrimRight(orig, " \t"), []byte("\n")) || len(orig) == 0 {
+		out = append(out, '\n')
+	}
+	if bytes.Contains(orig, []byte("\r\n")) {
+		out = bytes.ReplaceAll(out, []byte("\n"), []byte("\r\n"))
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package osutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestReplaceJSON(t *testing.T) {
+	orig := "{\r\n\t\"name\": \"app\",\r\n\t\"version\": \"1.0.0\",\r\n\t\"n\": 1.50,\r\n\t\"old\": true\r\n}\r\n"
+	var v map[string]any
+	if err := json.Unmarshal([]byte(orig), &v); err != nil {
+		t.Fatal(err)
+	}
+	v["version"] = "1.2.3"
+	v["added"] = "<x>"
+	delete(v, "old")
+
+	got, err := ReplaceJSON([]byte(orig), v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\r\n\t\"name\": \"app\",\r\n\t\"version\": \"1.2.3\",\r\n\t\"n\": 1.50,\r\n\t\"added\": \"<x>\"\r\n}\r\n"
+	if string(got) != want {
+		t.Errorf("ReplaceJSON() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestReplaceJSONSingleLine(t *testing.T) {
+	got, err := ReplaceJSON([]byte(`{"a":1,"b":2}`), map[string]any{"a": 1.0, "b": 3.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"a":1,"b":3}`; string(got) != want {
+		t.Errorf("ReplaceJSON() = %q, want %q", got, want)
+	}
+}
+
+func TestReplaceYAML(t *testing.T) {
+	orig := `# Build settings
+version: "1.0" # bumped by release
+vars:
+    A: 1
+    B: keep
+    L:
+        - a
+        - b
+`
+	v, err := DecodeYAML([]byte(orig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[string]any)
+	m["version"] = "2.0"
+	vars := m["vars"].(map[string]any)
+	vars["A"] = 3.0 // jq numbers are floats
+	vars["L"] = append(vars["L"].([]any), "c")
+
+	got, err := ReplaceYAML([]byte(orig), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Build settings
+version: "2.0" # bumped by release
+vars:
+    A: 3
+    B: keep
+    L:
+        - a
+        - b
+        - c
+`
+	if string(got) != want {
+		t.Errorf("ReplaceYAML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDecodeYAML(t *testing.T) {
+	v, err := DecodeYAML([]byte("a: 1\nb: 1.5\nc: yes\nd: null\ne: 2024-01-02\nf: &x [1]\ng: *x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := v.(map[string]any)
+	if m["a"] != 1 || m["b"] != 1.5 || m["c"] != "yes" || m["d"] != nil || m["e"] != "2024-01-02" {
+		t.Errorf("DecodeYAML() = %#v", m)
+	}
+	if g, ok := m["g"].([]any); !ok || len(g) != 1 || g[0] != 1 {
+		t.Errorf("alias g = %#v, want [1]", m["g"])
+	}
+
+	if _, err := DecodeYAML([]byte("a: 1\n---\nb: 2\n")); err == nil {
+		t.Error("DecodeYAML() accepted a multi-document file")
+	}
+}
+
+func TestIsYAMLFile(t *testing.T) {
+	for path, want := range map[string]bool{"Taskfile.yml": true, "x.YAML": true, "package.json": false, "yml": false} {
+		if got := IsYAMLFile(path); got != want {
+			t.Errorf("IsYAMLFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
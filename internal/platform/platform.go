@@ -0,0 +1,120 @@
+// Package platform reports the machine xplat runs on, which is not always
+// the one it was built for: an amd64 xplat runs on Apple silicon under
+// Rosetta 2 and on Windows ARM64 under x64 emulation, where runtime.GOARCH
+// says amd64. Binary downloads use the native arch, so those machines get
+// arm64 binaries, and fall back to an arch the OS emulates when a release
+// has no native build:
+//
+//   - macOS arm64: amd64 under Rosetta 2 (softwareupdate --install-rosetta)
+//   - Windows arm64: amd64 under the x64 emulation built into Windows 11
+//   - Windows amd64: 386 under WOW64
+//   - Linux arm64: no fallback, amd64 binaries need qemu-user and binfmt_misc
+package platform
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Platform is an OS and the arch of the machine.
+type Platform struct {
+	OS   string // GOOS
+	Arch string // Native GOARCH of the machine
+
+	// ProcessArch is the GOARCH xplat was built for, which differs from
+	// Arch when xplat itself is emulated.
+	ProcessArch string
+}
+
+var (
+	currentOnce sync.Once
+	current     Platform
+)
+
+// Current returns the platform xplat is running on.
+func Current() Platform {
+	currentOnce.Do(func() {
+		current = Platform{OS: runtime.GOOS, Arch: runtime.GOARCH, ProcessArch: runtime.GOARCH}
+		if arch := nativeArch(); arch != "" {
+			current.Arch = arch
+		}
+	})
+	return current
+}
+
+// Emulated reports whether xplat runs under emulation (Rosetta 2, Windows
+// x64 emulation or WOW64).
+func (p Platform) Emulated() bool {
+	return p.ProcessArch != "" && p.ProcessArch != p.Arch
+}
+
+// String returns "os/arch", noting how xplat runs when it is emulated.
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Arch
+	if p.Emulated() {
+		s += fmt.Sprintf(" (xplat is %s, running under %s)", p.ProcessArch, Emulator(p.OS, p.Arch, p.ProcessArch))
+	}
+	return s
+}
+
+// Archs returns the archs to look for binaries of, native first, then the
+// emulated fallback if the OS has one.
+func (p Platform) Archs() []string {
+	archs := []string{p.Arch}
+	if fallback, _ := Fallback(p.OS, p.Arch); fallback != "" {
+		archs = append(archs, fallback)
+	}
+	return archs
+}
+
+// Fallback returns the arch whose binaries run on goos/arch under emulation
+// when there is no native build, and a note saying so to print when one is
+// used. It returns "" when the OS has no emulation for arch.
+func Fallback(goos, arch string) (string, string) {
+	var fallback string
+	switch {
+	case goos == "darwin" && arch == "arm64":
+		fallback = "amd64"
+	case goos == "windows" && arch == "arm64":
+		fallback = "amd64"
+	case goos == "windows" && arch == "amd64":
+		fallback = "386"
+	default:
+		return "", ""
+	}
+	note := fmt.Sprintf("no %s/%s build, using %s/%s under %s", goos, arch, goos, fallback, Emulator(goos, arch, fallback))
+	if goos == "darwin" {
+		note += " (install it with: softwareupdate --install-rosetta)"
+	}
+	return fallback, note
+}
+
+// Emulator names what runs guest binaries on goos/native.
+func Emulator(goos, native, guest string) string {
+	switch {
+	case goos == "darwin":
+		return "Rosetta 2"
+	case goos == "windows" && native == "arm64":
+		return "Windows " + guest + " emulation"
+	case goos == "windows":
+		return "WOW64"
+	}
+	return "compatibility mode"
+}
+
+// normalizeMachine maps a kernel machine name (uname -m) to a GOARCH.
+func normalizeMachine(m string) string {
+	switch m {
+	case "x86_64", "amd64":
+		return "amd64"
+	case "aarch64", "arm64", "aarch64_be", "armv8b", "armv8l":
+		return "arm64"
+	case "i386", "i486", "i586", "i686":
+		return "386"
+	}
+	if len(m) >= 4 && m[:4] == "armv" {
+		return "arm"
+	}
+	return ""
+}
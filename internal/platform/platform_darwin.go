@@ -0,0 +1,19 @@
+package platform
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// nativeArch detects Rosetta 2: sysctl.proc_translated is 1 for a
+// translated process and missing on Intel Macs.
+func nativeArch() string {
+	if runtime.GOARCH != "amd64" {
+		return ""
+	}
+	if translated, err := unix.SysctlUint32("sysctl.proc_translated"); err == nil && translated == 1 {
+		return "arm64"
+	}
+	return ""
+}
@@ -0,0 +1,13 @@
+package platform
+
+import "golang.org/x/sys/unix"
+
+// nativeArch reads the kernel's machine name, so a 32-bit build on a 64-bit
+// kernel still gets 64-bit binaries.
+func nativeArch() string {
+	var u unix.Utsname
+	if err := unix.Uname(&u); err != nil {
+		return ""
+	}
+	return normalizeMachine(unix.ByteSliceToString(u.Machine[:]))
+}
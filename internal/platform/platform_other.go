@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package platform
+
+func nativeArch() string {
+	return ""
+}
@@ -0,0 +1,72 @@
+package platform
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestArchs(t *testing.T) {
+	tests := []struct {
+		p    Platform
+		want []string
+	}{
+		{Platform{OS: "darwin", Arch: "arm64"}, []string{"arm64", "amd64"}},
+		{Platform{OS: "darwin", Arch: "amd64"}, []string{"amd64"}},
+		{Platform{OS: "windows", Arch: "arm64"}, []string{"arm64", "amd64"}},
+		{Platform{OS: "windows", Arch: "amd64"}, []string{"amd64", "386"}},
+		{Platform{OS: "linux", Arch: "arm64"}, []string{"arm64"}},
+		{Platform{OS: "linux", Arch: "amd64"}, []string{"amd64"}},
+	}
+	for _, tt := range tests {
+		if got := tt.p.Archs(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s/%s Archs() = %v, want %v", tt.p.OS, tt.p.Arch, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackNote(t *testing.T) {
+	if _, note := Fallback("darwin", "arm64"); !strings.Contains(note, "Rosetta 2") || !strings.Contains(note, "softwareupdate --install-rosetta") {
+		t.Errorf("darwin note = %q", note)
+	}
+	if _, note := Fallback("windows", "arm64"); note != "no windows/arm64 build, using windows/amd64 under Windows amd64 emulation" {
+		t.Errorf("windows note = %q", note)
+	}
+	if arch, note := Fallback("linux", "arm64"); arch != "" || note != "" {
+		t.Errorf("linux Fallback() = %q, %q, want none", arch, note)
+	}
+}
+
+func TestString(t *testing.T) {
+	rosetta := Platform{OS: "darwin", Arch: "arm64", ProcessArch: "amd64"}
+	if !rosetta.Emulated() || rosetta.String() != "darwin/arm64 (xplat is amd64, running under Rosetta 2)" {
+		t.Errorf("Rosetta String() = %q", rosetta.String())
+	}
+	native := Platform{OS: "linux", Arch: "arm64", ProcessArch: "arm64"}
+	if native.Emulated() || native.String() != "linux/arm64" {
+		t.Errorf("native String() = %q", native.String())
+	}
+}
+
+func TestNormalizeMachine(t *testing.T) {
+	for in, want := range map[string]string{
+		"x86_64":  "amd64",
+		"aarch64": "arm64",
+		"arm64":   "arm64",
+		"armv7l":  "arm",
+		"i686":    "386",
+		"riscv64": "",
+	} {
+		if got := normalizeMachine(in); got != want {
+			t.Errorf("normalizeMachine(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCurrent(t *testing.T) {
+	p := Current()
+	if p.OS != runtime.GOOS || p.ProcessArch != runtime.GOARCH || p.Arch == "" {
+		t.Errorf("Current() = %+v", p)
+	}
+}
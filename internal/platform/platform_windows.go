@@ -0,0 +1,26 @@
+package platform
+
+import (
+	"debug/pe"
+
+	"golang.org/x/sys/windows"
+)
+
+// nativeArch asks IsWow64Process2 for the machine's native architecture,
+// which covers both WOW64 (386 on amd64) and x64 emulation on ARM64. It
+// needs Windows 10 1709; older versions have no ARM64 and report nothing.
+func nativeArch() string {
+	var process, native uint16
+	if err := windows.IsWow64Process2(windows.CurrentProcess(), &process, &native); err != nil {
+		return ""
+	}
+	switch native {
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		return "arm64"
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		return "amd64"
+	case pe.IMAGE_FILE_MACHINE_I386:
+		return "386"
+	}
+	return ""
+}
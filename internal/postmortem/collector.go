@@ -0,0 +1,212 @@
+package postmortem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+// processState is the subset of a process-compose /processes entry used here.
+type processState struct {
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace"`
+	Status    string        `json:"status"`
+	Age       time.Duration `json:"age"`
+	Restarts  int           `json:"restarts"`
+	ExitCode  int           `json:"exit_code"`
+	PID       int           `json:"pid"`
+	Mem       int64         `json:"mem"`
+	CPU       float64       `json:"cpu"`
+	IsRunning bool          `json:"is_running"`
+}
+
+// failed reports whether the process has exited non-zero and is not running.
+func (s processState) failed() bool {
+	if s.IsRunning || s.ExitCode == 0 {
+		return false
+	}
+	switch s.Status {
+	case "Completed", "Error", "Restarting":
+		return true
+	}
+	return false
+}
+
+// Collector polls process-compose and saves a bundle each time a process
+// exits non-zero.
+type Collector struct {
+	BaseURL  string
+	Dir      string
+	LogLines int
+
+	// Router is notified of each bundle; nil disables notifications
+	Router *notify.Router
+
+	// Logf reports captured bundles and errors; nil keeps quiet (e.g. under the TUI)
+	Logf func(format string, args ...interface{})
+
+	client   *http.Client
+	captured map[string]bool  // Failed runs already captured, until the process runs again
+	stats    map[string]Stats // Last stats seen while running
+	now      func() time.Time
+}
+
+// NewCollector creates a collector for the process-compose API on port,
+// saving bundles under dir.
+func NewCollector(port int, dir string) *Collector {
+	return &Collector{
+		BaseURL:  fmt.Sprintf("http://localhost:%d", port),
+		Dir:      dir,
+		LogLines: DefaultLogLines,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		captured: make(map[string]bool),
+		stats:    make(map[string]Stats),
+		now:      time.Now,
+	}
+}
+
+// Run polls until ctx is done. Poll errors (e.g. the server not up yet)
+// are retried quietly.
+func (c *Collector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		bundles, err := c.Check(ctx)
+		if err != nil {
+			continue
+		}
+		for _, b := range bundles {
+			c.logf("Post-mortem: %s exited with code %d, saved %s", b.Process, b.ExitCode, b.Path(c.Dir))
+		}
+	}
+}
+
+// Check polls once and returns the bundles captured.
+func (c *Collector) Check(ctx context.Context) ([]*Bundle, error) {
+	var resp struct {
+		Data []processState `json:"data"`
+	}
+	if err := c.get(ctx, "/processes", &resp); err != nil {
+		return nil, err
+	}
+
+	var bundles []*Bundle
+	for _, s := range resp.Data {
+		if s.IsRunning {
+			delete(c.captured, s.Name)
+			c.stats[s.Name] = Stats{PID: s.PID, Mem: s.Mem, CPU: s.CPU, Uptime: s.Age}
+			continue
+		}
+		if !s.failed() || c.captured[s.Name] {
+			continue
+		}
+		c.captured[s.Name] = true
+
+		b := c.capture(ctx, s)
+		if err := b.Save(c.Dir); err != nil {
+			c.logf("Post-mortem: %v", err)
+			continue
+		}
+		c.notify(ctx, b)
+		bundles = append(bundles, b)
+	}
+	return bundles, nil
+}
+
+// capture builds a bundle. Logs and environment are best effort: a bundle
+// without them still records the exit.
+func (c *Collector) capture(ctx context.Context, s processState) *Bundle {
+	at := c.now().UTC()
+	b := &Bundle{
+		ID:        newID(s.Name, at),
+		Process:   s.Name,
+		Namespace: s.Namespace,
+		Status:    s.Status,
+		ExitCode:  s.ExitCode,
+		Restarts:  s.Restarts,
+		Time:      at,
+		Stats:     c.stats[s.Name],
+	}
+	if b.Stats.PID == 0 {
+		b.Stats.PID = s.PID
+	}
+
+	var logs struct {
+		Logs []string `json:"logs"`
+	}
+	name := url.PathEscape(s.Name)
+	if err := c.get(ctx, fmt.Sprintf("/process/logs/%s/0/%d", name, c.LogLines), &logs); err == nil {
+		b.Logs = logs.Logs
+	}
+
+	// process-compose serves the process config with Go field names
+	var info struct {
+		Command     string
+		Environment []string
+	}
+	if err := c.get(ctx, "/process/info/"+name, &info); err == nil {
+		b.Command = info.Command
+		b.Env = RedactEnv(info.Environment)
+		b.env = info.Environment
+	}
+	return b
+}
+
+func (c *Collector) notify(ctx context.Context, b *Bundle) {
+	if !c.Router.Enabled() {
+		return
+	}
+	tail := b.Logs
+	if len(tail) > 5 {
+		tail = tail[len(tail)-5:]
+	}
+	// Chat webhooks are less private than the bundle on disk
+	tail = RedactLines(tail, b.env)
+	err := c.Router.Notify(ctx, &notify.Message{
+		Source:   "process/" + b.Process,
+		Severity: notify.SeverityWarning,
+		Title:    fmt.Sprintf("Process %s exited with code %d", b.Process, b.ExitCode),
+		Text:     strings.Join(tail, "\n"),
+		Fields: map[string]string{
+			"post-mortem": b.Path(c.Dir),
+			"restarts":    fmt.Sprintf("%d", b.Restarts),
+		},
+		Time: b.Time,
+	})
+	if err != nil {
+		c.logf("Post-mortem: notification failed: %v", err)
+	}
+}
+
+func (c *Collector) logf(format string, args ...interface{}) {
+	if c.Logf != nil {
+		c.Logf(format, args...)
+	}
+}
+
+func (c *Collector) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to process-compose: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("process-compose returned status %d for %s", resp.StatusCode, path)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
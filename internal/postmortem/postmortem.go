@@ -0,0 +1,195 @@
+// Package postmortem captures a bundle for each process-compose process
+// that exits non-zero: its last log lines, exit code, last resource stats
+// and redacted environment, saved under ~/.xplat/state/<project>/postmortems/.
+//
+// A Collector polls the process-compose API. 'xplat process up' runs one
+// alongside the server, and 'xplat process postmortems watch' runs one
+// against a server started elsewhere.
+package postmortem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/projectstate"
+)
+
+// DefaultDir returns where the current project's bundles are saved.
+func DefaultDir() string {
+	return projectstate.ToolDir("postmortems")
+}
+
+// DefaultLogLines is how many log lines a bundle keeps.
+const DefaultLogLines = 200
+
+// Bundle is the post-mortem of one failed process run. It is saved as
+// <id>/postmortem.json, with the logs also in <id>/logs.txt.
+type Bundle struct {
+	ID        string            `json:"id"`
+	Process   string            `json:"process"`
+	Namespace string            `json:"namespace,omitempty"`
+	Status    string            `json:"status"`
+	ExitCode  int               `json:"exit_code"`
+	Restarts  int               `json:"restarts"`
+	Time      time.Time         `json:"time"`
+	Command   string            `json:"command,omitempty"`
+	Stats     Stats             `json:"stats"`
+	Env       map[string]string `json:"env,omitempty"` // Secrets redacted
+	Logs      []string          `json:"logs,omitempty"`
+
+	env []string // Unredacted KEY=VALUE pairs, for RedactLines; not saved
+}
+
+// Stats are the last resource stats seen while the process was running.
+type Stats struct {
+	PID    int           `json:"pid,omitempty"`
+	Mem    int64         `json:"mem_bytes,omitempty"`
+	CPU    float64       `json:"cpu_percent,omitempty"`
+	Uptime time.Duration `json:"uptime,omitempty"`
+}
+
+// Path returns the bundle's directory.
+func (b *Bundle) Path(dir string) string {
+	return filepath.Join(dir, b.ID)
+}
+
+// newID returns a sortable bundle ID, e.g. 20261016T101500Z-api.
+func newID(process string, at time.Time) string {
+	return at.UTC().Format("20060102T150405Z") + "-" + process
+}
+
+// Save writes the bundle under dir.
+func (b *Bundle) Save(dir string) error {
+	path := b.Path(dir)
+	if err := os.MkdirAll(path, config.DefaultDirPerms); err != nil {
+		return fmt.Errorf("failed to create post-mortem dir: %w", err)
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The environment is redacted, but may still be sensitive
+	if err := os.WriteFile(filepath.Join(path, "postmortem.json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write post-mortem: %w", err)
+	}
+	logs := strings.Join(b.Logs, "\n")
+	if logs != "" {
+		logs += "\n"
+	}
+	if err := os.WriteFile(filepath.Join(path, "logs.txt"), []byte(logs), 0600); err != nil {
+		return fmt.Errorf("failed to write post-mortem logs: %w", err)
+	}
+	return nil
+}
+
+// Load reads a bundle by ID.
+func Load(dir, id string) (*Bundle, error) {
+	data, err := os.ReadFile(filepath.Join(dir, id, "postmortem.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no post-mortem '%s'", id)
+		}
+		return nil, fmt.Errorf("failed to read post-mortem: %w", err)
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse post-mortem %s: %w", id, err)
+	}
+	return &b, nil
+}
+
+// List returns the saved bundles, newest first. A missing dir means none.
+func List(dir string) ([]*Bundle, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read post-mortems: %w", err)
+	}
+	var bundles []*Bundle
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		b, err := Load(dir, e.Name())
+		if err != nil {
+			continue // Not a bundle, or one still being written
+		}
+		bundles = append(bundles, b)
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].ID > bundles[j].ID })
+	return bundles, nil
+}
+
+// secretMarkers are name fragments of env vars whose values are redacted.
+var secretMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "AUTH", "CREDENTIAL", "PRIVATE", "DSN"}
+
+// Redacted is the value stored for redacted env vars.
+const Redacted = "[redacted]"
+
+// isSecret reports whether an env var's name looks like it holds a secret.
+func isSecret(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactEnv turns KEY=VALUE pairs into a map, replacing the values of
+// secret-looking vars.
+func RedactEnv(pairs []string) map[string]string {
+	env := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		if isSecret(key) {
+			value = Redacted
+		}
+		env[key] = value
+	}
+	return env
+}
+
+// assignment matches KEY=VALUE, KEY: VALUE and "KEY": "VALUE" in log lines.
+var assignment = regexp.MustCompile(`\b([A-Za-z_][A-Za-z0-9_]*)("?\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,{}\[\]]+)`)
+
+// minSecretLen is the shortest env value RedactLines looks for, so short
+// values like "1" don't blank out every line.
+const minSecretLen = 6
+
+// RedactLines returns the lines with the values of the secret-looking vars
+// among the KEY=VALUE pairs in env replaced, plus any assignment in the
+// lines (KEY=VALUE, KEY: VALUE, JSON fields) whose key looks secret.
+func RedactLines(lines, env []string) []string {
+	var secrets []string
+	for _, pair := range env {
+		if key, value, _ := strings.Cut(pair, "="); isSecret(key) && len(value) >= minSecretLen {
+			secrets = append(secrets, value)
+		}
+	}
+
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		for _, secret := range secrets {
+			line = strings.ReplaceAll(line, secret, Redacted)
+		}
+		out[i] = assignment.ReplaceAllStringFunc(line, func(m string) string {
+			parts := assignment.FindStringSubmatch(m)
+			if !isSecret(parts[1]) {
+				return m
+			}
+			return parts[1] + parts[2] + Redacted
+		})
+	}
+	return out
+}
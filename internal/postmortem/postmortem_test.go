@@ -0,0 +1,123 @@
+package postmortem
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+func TestRedactEnv(t *testing.T) {
+	env := RedactEnv([]string{"PORT=8080", "CF_API_TOKEN=abc", "db_password=x", "R2_SECRET_KEY=y", "EMPTY="})
+	want := map[string]string{"PORT": "8080", "CF_API_TOKEN": Redacted, "db_password": Redacted, "R2_SECRET_KEY": Redacted, "EMPTY": ""}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+}
+
+func TestCollectorCheck(t *testing.T) {
+	api := struct {
+		running bool
+		exit    int
+	}{running: true}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/processes":
+			status := "Running"
+			if !api.running {
+				status = "Completed"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": []map[string]interface{}{
+				{"name": "api", "status": status, "is_running": api.running, "exit_code": api.exit, "pid": 42, "mem": 1 << 20, "cpu": 12.5, "restarts": 1},
+				{"name": "job", "status": "Completed", "is_running": false, "exit_code": 0},
+			}})
+		case strings.HasPrefix(r.URL.Path, "/process/logs/api/"):
+			_, _ = w.Write([]byte(`{"logs":["starting","panic: boom"]}`))
+		case r.URL.Path == "/process/info/api":
+			_, _ = w.Write([]byte(`{"Command":"./api","Environment":["PORT=9000","API_TOKEN=t"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var notified []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m notify.Message
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		notified = append(notified, m.Source+": "+m.Title)
+	}))
+	defer hook.Close()
+
+	dir := t.TempDir()
+	c := NewCollector(0, dir)
+	c.BaseURL = srv.URL
+	c.Router = &notify.Router{Routes: []notify.Route{{Webhook: hook.URL}}}
+	c.now = func() time.Time { return time.Date(2026, 10, 16, 10, 15, 0, 0, time.UTC) }
+
+	// Running: only stats are recorded
+	if bundles, err := c.Check(context.Background()); err != nil || len(bundles) != 0 {
+		t.Fatalf("running: bundles = %v, err = %v", bundles, err)
+	}
+
+	api.running, api.exit = false, 2
+	bundles, err := c.Check(context.Background())
+	if err != nil || len(bundles) != 1 {
+		t.Fatalf("failed: bundles = %v, err = %v", bundles, err)
+	}
+	b := bundles[0]
+	if b.ID != "20261016T101500Z-api" || b.ExitCode != 2 || b.Stats.Mem != 1<<20 || b.Command != "./api" {
+		t.Errorf("bundle = %+v", b)
+	}
+	if b.Env["API_TOKEN"] != Redacted || b.Env["PORT"] != "9000" {
+		t.Errorf("env = %v", b.Env)
+	}
+	if len(notified) != 1 || notified[0] != "process/api: Process api exited with code 2" {
+		t.Errorf("notified = %v", notified)
+	}
+
+	// The same failed run is captured once
+	if bundles, _ := c.Check(context.Background()); len(bundles) != 0 {
+		t.Errorf("captured again: %v", bundles)
+	}
+
+	logs, err := os.ReadFile(filepath.Join(dir, b.ID, "logs.txt"))
+	if err != nil || string(logs) != "starting\npanic: boom\n" {
+		t.Errorf("logs.txt = %q, %v", logs, err)
+	}
+	listed, err := List(dir)
+	if err != nil || len(listed) != 1 || listed[0].ID != b.ID {
+		t.Errorf("List() = %v, %v", listed, err)
+	}
+}
+
+func TestRedactLines(t *testing.T) {
+	env := []string{"PORT=9000", "API_TOKEN=tok-123456", "DEBUG=1"}
+	lines := []string{
+		"listening on 9000",
+		"auth with tok-123456 failed",
+		"DB_PASSWORD=hunter22 host=db",
+		`config: {"aws_secret_key": "abc"} client_secret: xyz`,
+	}
+	want := []string{
+		"listening on 9000",
+		"auth with " + Redacted + " failed",
+		"DB_PASSWORD=" + Redacted + " host=db",
+		`config: {"aws_secret_key": ` + Redacted + `} client_secret: ` + Redacted,
+	}
+	got := RedactLines(lines, env)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package preview runs lightweight per-branch preview environments: the
+// project's processes on branch-offset ports, routed through Caddy under
+// /preview/<branch>/, with no containers.
+//
+// Ports come from the <NAME>_PORT variables the generated process-compose
+// config already reads (see manifest.PortEnvVar), so a preview only has to
+// set them in the environment. Each preview also gets its own
+// process-compose API port, so several branches can run side by side.
+//
+// State is kept per project under ~/.xplat/state/<project>/preview.
+package preview
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/projectstate"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// StateDir returns where the previews of the project in workDir are kept.
+func StateDir(workDir string) string {
+	return projectstate.Open(workDir).ToolDir("preview")
+}
+
+// Port offsets are a multiple of offsetStep in [offsetBase, offsetBase+offsetSlots*offsetStep).
+const (
+	offsetBase  = 1000
+	offsetStep  = 100
+	offsetSlots = 40
+)
+
+// Preview is a running (or planned) preview environment for one branch.
+type Preview struct {
+	Branch    string    `json:"branch"`
+	Slug      string    `json:"slug"`
+	Offset    int       `json:"offset"`
+	PCPort    int       `json:"pc_port"` // process-compose API port
+	Processes []Process `json:"processes"`
+	TunnelURL string    `json:"tunnel_url,omitempty"`
+	Caddy     []string  `json:"caddy,omitempty"` // Registered Caddy service names
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Process is one previewed process with a port.
+type Process struct {
+	Name    string `json:"name"`
+	EnvVar  string `json:"env_var"`
+	Port    int    `json:"port"`
+	Path    string `json:"path"`    // Caddy path, e.g. /preview/feat-x/web
+	Service string `json:"service"` // Caddy service name
+}
+
+// Slug returns the branch name reduced to lowercase letters, digits and
+// hyphens, for use in paths and service names.
+func Slug(branch string) string {
+	var sb strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(branch) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && sb.Len() > 0 {
+			sb.WriteByte('-')
+			hyphen = true
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "-")
+}
+
+// PortOffset returns the preferred port offset for a branch. It is derived
+// from the branch name, so a branch gets the same ports every time it is
+// previewed unless another preview already has them.
+func PortOffset(branch string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(Slug(branch)))
+	return offsetBase + int(h.Sum32()%offsetSlots)*offsetStep
+}
+
+// FreeOffset returns the branch's PortOffset, or the next slot after it
+// that none of the running previews of other branches uses.
+func FreeOffset(branch string, running []*Preview) (int, error) {
+	taken := make(map[int]bool, len(running))
+	for _, p := range running {
+		if p.Slug != Slug(branch) {
+			taken[p.Offset] = true
+		}
+	}
+	slot := (PortOffset(branch) - offsetBase) / offsetStep
+	for i := 0; i < offsetSlots; i++ {
+		offset := offsetBase + ((slot+i)%offsetSlots)*offsetStep
+		if !taken[offset] {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("all %d preview port slots are in use; stop a preview with 'xplat dev preview down'", offsetSlots)
+}
+
+// New plans a preview of the manifest's processes for a branch, on ports
+// the running previews don't use. Processes without a port and disabled
+// processes are left out.
+func New(m *manifest.Manifest, branch string, running []*Preview) (*Preview, error) {
+	slug := Slug(branch)
+	if slug == "" {
+		return nil, fmt.Errorf("branch %q has no usable name for a preview", branch)
+	}
+	offset, err := FreeOffset(branch, running)
+	if err != nil {
+		return nil, err
+	}
+	p := &Preview{
+		Branch: branch,
+		Slug:   slug,
+		Offset: offset,
+		PCPort: config.DefaultProcessComposePort + offset,
+	}
+
+	names := make([]string, 0, len(m.Processes))
+	for name := range m.Processes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		proc := m.Processes[name]
+		if proc.Port == 0 || proc.Disabled {
+			continue
+		}
+		port := proc.Port + offset
+		if port > 65535 {
+			return nil, fmt.Errorf("process '%s' port %d + offset %d is out of range", name, proc.Port, offset)
+		}
+		p.Processes = append(p.Processes, Process{
+			Name:    name,
+			EnvVar:  manifest.PortEnvVar(name),
+			Port:    port,
+			Path:    fmt.Sprintf("/preview/%s/%s", slug, name),
+			Service: fmt.Sprintf("preview-%s-%s", slug, name),
+		})
+	}
+	if len(p.Processes) == 0 {
+		return nil, fmt.Errorf("no processes with a port to preview")
+	}
+	return p, nil
+}
+
+// Env returns the environment for the preview's processes: each
+// <NAME>_PORT, plus XPLAT_PREVIEW (the slug) and XPLAT_PREVIEW_PATH.
+func (p *Preview) Env() []string {
+	env := []string{
+		"XPLAT_PREVIEW=" + p.Slug,
+		"XPLAT_PREVIEW_PATH=/preview/" + p.Slug + "/",
+	}
+	for _, proc := range p.Processes {
+		env = append(env, fmt.Sprintf("%s=%d", proc.EnvVar, proc.Port))
+	}
+	return env
+}
+
+// stateVersion is the Preview schema version (see statestore)
+const stateVersion = 1
+
+// statePath returns the state file for a preview slug.
+func statePath(workDir, slug string) string {
+	return filepath.Join(StateDir(workDir), slug+".json")
+}
+
+// Save records the preview so 'xplat dev preview down' can tear it down.
+func (p *Preview) Save(workDir string) error {
+	if err := statestore.New[Preview](statePath(workDir, p.Slug), stateVersion).Save(p); err != nil {
+		return fmt.Errorf("failed to save preview state: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the preview's state.
+func (p *Preview) Remove(workDir string) error {
+	if err := os.Remove(statePath(workDir, p.Slug)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove preview state: %w", err)
+	}
+	return nil
+}
+
+// Load reads the state of the preview for a branch.
+func Load(workDir, branch string) (*Preview, error) {
+	p, err := statestore.New[Preview](statePath(workDir, Slug(branch)), stateVersion).Load()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no preview running for branch '%s'", branch)
+		}
+		return nil, fmt.Errorf("failed to read preview state: %w", err)
+	}
+	return p, nil
+}
+
+// List returns the recorded previews, ordered by branch.
+func List(workDir string) ([]*Preview, error) {
+	matches, err := filepath.Glob(filepath.Join(StateDir(workDir), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var previews []*Preview
+	for _, path := range matches {
+		p, err := statestore.New[Preview](path, stateVersion).Load()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read preview state: %w", err)
+		}
+		previews = append(previews, p)
+	}
+	sort.Slice(previews, func(i, j int) bool { return previews[i].Branch < previews[j].Branch })
+	return previews, nil
+}
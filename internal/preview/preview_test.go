@@ -0,0 +1,127 @@
+package preview
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/manifest"
+)
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"main":              "main",
+		"feat/Login-Page":   "feat-login-page",
+		"fix//double__sep_": "fix-double-sep",
+		"-/-":               "",
+	}
+	for branch, want := range tests {
+		if got := Slug(branch); got != want {
+			t.Errorf("Slug(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestPortOffset(t *testing.T) {
+	for _, branch := range []string{"main", "feat/a", "feat/b", "release-1.2"} {
+		off := PortOffset(branch)
+		if off < offsetBase || off >= offsetBase+offsetSlots*offsetStep || off%offsetStep != 0 {
+			t.Errorf("PortOffset(%q) = %d, out of range", branch, off)
+		}
+		if PortOffset(branch) != off {
+			t.Errorf("PortOffset(%q) is not stable", branch)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	m := &manifest.Manifest{Processes: map[string]manifest.ProcessConfig{
+		"web":    {Command: "web", Port: 8080},
+		"api":    {Command: "api", Port: 9000},
+		"worker": {Command: "worker"},
+		"old":    {Command: "old", Port: 7000, Disabled: true},
+	}}
+
+	p, err := New(m, "feat/x", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	off := PortOffset("feat/x")
+	if p.PCPort != config.DefaultProcessComposePort+off {
+		t.Errorf("PCPort = %d", p.PCPort)
+	}
+	want := []Process{
+		{Name: "api", EnvVar: "API_PORT", Port: 9000 + off, Path: "/preview/feat-x/api", Service: "preview-feat-x-api"},
+		{Name: "web", EnvVar: "WEB_PORT", Port: 8080 + off, Path: "/preview/feat-x/web", Service: "preview-feat-x-web"},
+	}
+	if !reflect.DeepEqual(p.Processes, want) {
+		t.Errorf("Processes = %+v, want %+v", p.Processes, want)
+	}
+
+	if _, err := New(&manifest.Manifest{}, "main", nil); err == nil {
+		t.Error("expected error for manifest without ports")
+	}
+}
+
+func TestFreeOffset(t *testing.T) {
+	preferred := PortOffset("feat/x")
+	next := preferred + offsetStep
+	if next >= offsetBase+offsetSlots*offsetStep {
+		next = offsetBase
+	}
+
+	running := []*Preview{{Slug: "other", Offset: preferred}}
+	if got, err := FreeOffset("feat/x", running); err != nil || got != next {
+		t.Errorf("FreeOffset() with a collision = %d, %v; want %d", got, err, next)
+	}
+	// Its own earlier preview doesn't count
+	running = []*Preview{{Slug: "feat-x", Offset: preferred}}
+	if got, err := FreeOffset("feat/x", running); err != nil || got != preferred {
+		t.Errorf("FreeOffset() = %d, %v; want %d", got, err, preferred)
+	}
+
+	running = nil
+	for i := 0; i < offsetSlots; i++ {
+		running = append(running, &Preview{Slug: fmt.Sprintf("b%d", i), Offset: offsetBase + i*offsetStep})
+	}
+	if _, err := FreeOffset("feat/x", running); err == nil {
+		t.Error("FreeOffset() with every slot taken succeeded")
+	}
+}
+
+func TestSaveLoadList(t *testing.T) {
+	t.Setenv("XPLAT_HOME", t.TempDir())
+	dir := t.TempDir()
+	m := &manifest.Manifest{Processes: map[string]manifest.ProcessConfig{"web": {Command: "web", Port: 8080}}}
+
+	for _, branch := range []string{"feat/b", "feat/a"} {
+		running, err := List(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := New(m, branch, running)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Save(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p, err := Load(dir, "feat/a")
+	if err != nil || p.Branch != "feat/a" {
+		t.Fatalf("Load() = %+v, %v", p, err)
+	}
+	if err := p.Remove(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir, "feat/a"); err == nil {
+		t.Error("expected error after Remove")
+	}
+
+	previews, err := List(dir)
+	if err != nil || len(previews) != 1 || previews[0].Branch != "feat/b" {
+		t.Errorf("List() = %v, %v", previews, err)
+	}
+}
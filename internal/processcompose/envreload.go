@@ -0,0 +1,126 @@
+package processcompose
+
+import (
+	"context"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Env reload finds the processes that use the keys changed in .env, so
+// 'xplat up --env-reload' can restart or signal just those instead of
+// leaving them running with stale values.
+
+// ReadDotenv reads a dotenv file such as .env. A missing file has no keys.
+func ReadDotenv(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return parseDotenv(f)
+}
+
+// ChangedKeys returns the keys added, removed or changed between old and
+// new, sorted.
+func ChangedKeys(old, new map[string]string) []string {
+	var changed []string
+	for k, v := range new {
+		if ov, ok := old[k]; !ok || ov != v {
+			changed = append(changed, k)
+		}
+	}
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// varRefPattern matches $KEY and ${KEY} references.
+var varRefPattern = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ProcessVars returns the variables a process references as $KEY or ${KEY}
+// in its command, entrypoint, working_dir or environment.
+func ProcessVars(p *Process) []string {
+	seen := make(map[string]bool)
+	fields := append([]string{p.Command, p.WorkingDir}, p.Entrypoint...)
+	for _, s := range append(fields, p.Environment...) {
+		// $$ is a literal $ in process-compose configs
+		s = strings.ReplaceAll(s, "$$", "")
+		for _, m := range varRefPattern.FindAllStringSubmatch(s, -1) {
+			seen[m[1]] = true
+		}
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// AffectedProcesses returns, sorted, the processes of pc using one of the
+// changed keys: referenced in the config (see ProcessVars) or declared in
+// declared[process], the env section of the xplat.yaml manifest the process
+// was generated from. Disabled processes are skipped.
+func AffectedProcesses(pc *ProcessCompose, declared map[string][]string, changed []string) []string {
+	if len(changed) == 0 {
+		return nil
+	}
+	changedSet := make(map[string]bool, len(changed))
+	for _, k := range changed {
+		changedSet[k] = true
+	}
+
+	var affected []string
+	for name, p := range pc.Processes {
+		if p == nil || p.Disabled {
+			continue
+		}
+		for _, k := range append(ProcessVars(p), declared[name]...) {
+			if changedSet[k] {
+				affected = append(affected, name)
+				break
+			}
+		}
+	}
+	sort.Strings(affected)
+	return affected
+}
+
+// WatchDotenv polls the dotenv file at path every interval and calls fn with
+// the old and new values when a key changes (comment and formatting edits
+// are ignored). A change must read the same on two polls in a row, so a file
+// caught mid-write is not reported. It returns when ctx is done.
+func WatchDotenv(ctx context.Context, path string, interval time.Duration, fn func(old, new map[string]string)) {
+	current, _ := ReadDotenv(path)
+	var pending map[string]string
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, err := ReadDotenv(path)
+		if err != nil {
+			continue // Unreadable, try again next tick
+		}
+		switch {
+		case len(ChangedKeys(current, next)) == 0:
+			pending = nil
+		case pending != nil && len(ChangedKeys(pending, next)) == 0:
+			fn(current, next)
+			current, pending = next, nil
+		default:
+			pending = next
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package processcompose
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChangedKeys(t *testing.T) {
+	old := map[string]string{"A": "1", "B": "2", "C": "3"}
+	new := map[string]string{"A": "1", "B": "20", "D": "4"}
+	if got, want := ChangedKeys(old, new), []string{"B", "C", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedKeys() = %v, want %v", got, want)
+	}
+	if got := ChangedKeys(old, old); got != nil {
+		t.Errorf("ChangedKeys(same) = %v", got)
+	}
+}
+
+func TestAffectedProcesses(t *testing.T) {
+	pc := &ProcessCompose{Processes: map[string]*Process{
+		"api":    {Command: "./api --db ${DATABASE_URL}"},
+		"worker": {Command: "./worker", Environment: []string{"QUEUE=$REDIS_URL/jobs"}},
+		"web":    {Command: "echo $$DATABASE_URL"}, // Literal $, not a reference
+		"mailer": {Command: "./mailer"},
+		"old":    {Command: "./old $DATABASE_URL", Disabled: true},
+	}}
+	declared := map[string][]string{"mailer": {"SMTP_PASSWORD"}}
+
+	got := AffectedProcesses(pc, declared, []string{"DATABASE_URL", "SMTP_PASSWORD"})
+	if want := []string{"api", "mailer"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AffectedProcesses() = %v, want %v", got, want)
+	}
+	got = AffectedProcesses(pc, declared, []string{"REDIS_URL"})
+	if want := []string{"worker"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AffectedProcesses(REDIS_URL) = %v, want %v", got, want)
+	}
+}
+
+func TestWatchDotenv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("A=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan []string, 1)
+	go WatchDotenv(ctx, path, 10*time.Millisecond, func(old, new map[string]string) {
+		changes <- ChangedKeys(old, new)
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	// A comment-only edit is not a change
+	if err := os.WriteFile(path, []byte("# note\nA=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("A=2\nB=3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-changes:
+		if want := []string{"A", "B"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("changed = %v, want %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change detected")
+	}
+}
@@ -172,6 +172,8 @@ type ProcessInput struct {
 	HTTPS       bool
 	Readiness   *ReadinessConfig
 	Schedule    *ScheduleConfig // v1.87.0: cron/interval scheduling
+	EnvProfiles []string        // Profile names from .xplat/profiles/ (xplat extension)
+	Worker      *WorkerInput    // Run a Cloudflare Worker locally instead of Command
 }
 
 // ScheduleConfig holds schedule configuration for cron/interval processes.
@@ -200,6 +202,25 @@ func ProcessFromInput(input *ProcessInput) *Process {
 		Namespace: input.Namespace,
 	}
 
+	// Worker processes run under wrangler dev, with /health as readiness path
+	if input.Worker != nil {
+		defaults := *input
+		input = &defaults
+		if input.Port == 0 {
+			input.Port = DefaultWorkerPort
+		}
+		if input.HealthPath == "" {
+			input.HealthPath = DefaultWorkerHealthPath
+		}
+		proc.Command = ""
+		proc.Entrypoint = WorkerEntrypoint(formatPort(input.Port, input.PortEnvVar), input.Worker.Vars)
+		proc.WorkingDir = input.Worker.Dir
+	}
+
+	if len(input.EnvProfiles) > 0 {
+		proc.EnvProfiles = input.EnvProfiles
+	}
+
 	// Add dependencies
 	if len(input.DependsOn) > 0 {
 		proc.DependsOn = make(map[string]DepCfg)
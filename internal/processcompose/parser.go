@@ -22,7 +22,8 @@ type ProcessCompose struct {
 
 // Process represents a single process definition.
 type Process struct {
-	Command        string            `yaml:"command"`
+	Command        string            `yaml:"command,omitempty"`
+	Entrypoint     []string          `yaml:"entrypoint,omitempty"` // Run without a shell when Command is empty
 	WorkingDir     string            `yaml:"working_dir,omitempty"`
 	Disabled       bool              `yaml:"disabled,omitempty"`
 	Environment    []string          `yaml:"environment,omitempty"`
@@ -33,6 +34,10 @@ type Process struct {
 	LivenessProbe  *ReadinessProbe   `yaml:"liveness_probe,omitempty"`
 	Namespace      string            `yaml:"namespace,omitempty"`
 	Schedule       *Schedule         `yaml:"schedule,omitempty"` // v1.87.0: cron/interval scheduling
+
+	// EnvProfiles names .xplat/profiles/<name>.env files injected into this
+	// process only. xplat extension: ignored by process-compose, applied by 'xplat process'.
+	EnvProfiles []string `yaml:"env_profiles,omitempty"`
 }
 
 // Schedule defines process scheduling (process-compose v1.87.0+).
@@ -143,6 +148,20 @@ func (p *Process) UsesTaskCommand() bool {
 	return strings.HasPrefix(p.Command, "task ")
 }
 
+// IsWorker returns true if the process runs a Cloudflare Worker locally (see WorkerEntrypoint).
+func (p *Process) IsWorker() bool {
+	return strings.HasPrefix(p.CommandLine(), "wrangler dev ")
+}
+
+// CommandLine returns the command for display: Command, or the entrypoint
+// joined with spaces when the process runs without a shell.
+func (p *Process) CommandLine() string {
+	if p.Command != "" || len(p.Entrypoint) == 0 {
+		return p.Command
+	}
+	return strings.Join(p.Entrypoint, " ")
+}
+
 // UsesTaskHealth returns true if the readiness probe uses "task <subsystem>:health".
 func (p *Process) UsesTaskHealth() bool {
 	if p.ReadinessProbe == nil || p.ReadinessProbe.Exec == nil {
@@ -0,0 +1,164 @@
+package processcompose
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Env profiles inject extra variables into specific processes only.
+//
+// A profile is a dotenv file at .xplat/profiles/<name>.env. A process opts in
+// with env_profiles in pc.yaml (or xplat.yaml, which generates it):
+//
+//	processes:
+//	  api:
+//	    command: xplat task api:run
+//	    env_profiles: [staging]
+//
+// process-compose has no per-process env_file, so 'xplat process' writes an
+// override config that sets the profile variables in each process's
+// environment and passes it as an extra -f. Later profiles win.
+
+// ProfilesDir is the project-relative directory holding profile env files.
+const ProfilesDir = ".xplat/profiles"
+
+// ProfileOverrideFile is the generated override config (inside ProfilesDir,
+// so it is git-ignored along with the profiles).
+const ProfileOverrideFile = ".override.generated.yaml"
+
+// LoadProfile reads .xplat/profiles/<name>.env from dir.
+func LoadProfile(dir, name string) (map[string]string, error) {
+	path := filepath.Join(dir, name+".env")
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("profile %q not found (expected %s)", name, path)
+		}
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	vars, err := parseDotenv(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// parseDotenv reads KEY=value lines, skipping blanks and # comments. An
+// "export " prefix and matching surrounding quotes are removed.
+func parseDotenv(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		vars[key] = value
+	}
+	return vars, scanner.Err()
+}
+
+// profileOverride is the minimal process-compose config that only sets environments.
+type profileOverride struct {
+	Processes map[string]profileOverrideProcess `yaml:"processes"`
+}
+
+type profileOverrideProcess struct {
+	Environment []string `yaml:"environment"`
+}
+
+// BuildProfileOverride returns an override config setting each process's
+// profile variables, or nil if no process uses profiles.
+func BuildProfileOverride(pc *ProcessCompose, profilesDir string) ([]byte, error) {
+	override := profileOverride{Processes: make(map[string]profileOverrideProcess)}
+	cache := make(map[string]map[string]string)
+
+	for name, proc := range pc.Processes {
+		if len(proc.EnvProfiles) == 0 {
+			continue
+		}
+
+		merged := make(map[string]string)
+		for _, profile := range proc.EnvProfiles {
+			vars, ok := cache[profile]
+			if !ok {
+				var err error
+				vars, err = LoadProfile(profilesDir, profile)
+				if err != nil {
+					return nil, fmt.Errorf("process %s: %w", name, err)
+				}
+				cache[profile] = vars
+			}
+			for k, v := range vars {
+				merged[k] = v
+			}
+		}
+
+		keys := make([]string, 0, len(merged))
+		for k := range merged {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		env := make([]string, 0, len(keys))
+		for _, k := range keys {
+			// process-compose expands ${VAR} in config files; $$ keeps a literal $
+			env = append(env, k+"="+strings.ReplaceAll(merged[k], "$", "$$"))
+		}
+		override.Processes[name] = profileOverrideProcess{Environment: env}
+	}
+
+	if len(override.Processes) == 0 {
+		return nil, nil
+	}
+
+	data, err := yaml.Marshal(override)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile override: %w", err)
+	}
+	header := "# GENERATED by xplat process from " + ProfilesDir + " - DO NOT EDIT OR COMMIT\n"
+	return append([]byte(header), data...), nil
+}
+
+// WriteProfileOverride parses configPath and writes the profile override next
+// to the profiles in workDir. It returns the override path, or "" if no
+// process uses profiles.
+func WriteProfileOverride(configPath, workDir string) (string, error) {
+	pc, err := Parse(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	profilesDir := filepath.Join(workDir, ProfilesDir)
+	data, err := BuildProfileOverride(pc, profilesDir)
+	if err != nil || data == nil {
+		return "", err
+	}
+
+	path := filepath.Join(profilesDir, ProfileOverrideFile)
+	// Profiles hold credentials, so the override is owner-only like .env
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
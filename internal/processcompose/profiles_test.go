@@ -0,0 +1,70 @@
+package processcompose
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestBuildProfileOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeProfile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name+".env"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeProfile("staging", "# staging creds\nDATABASE_URL=postgres://staging\nAPI_KEY=\"s3cr$t\"\n")
+	writeProfile("debug", "export LOG_LEVEL=debug\nDATABASE_URL=postgres://debug\n")
+
+	pc := &ProcessCompose{Processes: map[string]*Process{
+		"api":    {Command: "xplat task api:run", EnvProfiles: []string{"staging", "debug"}},
+		"worker": {Command: "xplat task worker:run", EnvProfiles: []string{"staging"}},
+		"web":    {Command: "xplat task web:run"},
+	}}
+
+	data, err := BuildProfileOverride(pc, dir)
+	if err != nil {
+		t.Fatalf("BuildProfileOverride failed: %v", err)
+	}
+
+	var got profileOverride
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("override is not valid YAML: %v\n%s", err, data)
+	}
+
+	if _, ok := got.Processes["web"]; ok {
+		t.Error("web has no profiles but got an override")
+	}
+
+	want := map[string][]string{
+		// debug comes after staging, so its DATABASE_URL wins; $ is escaped for process-compose
+		"api":    {"API_KEY=s3cr$$t", "DATABASE_URL=postgres://debug", "LOG_LEVEL=debug"},
+		"worker": {"API_KEY=s3cr$$t", "DATABASE_URL=postgres://staging"},
+	}
+	for name, env := range want {
+		if strings.Join(got.Processes[name].Environment, ",") != strings.Join(env, ",") {
+			t.Errorf("%s environment = %v, want %v", name, got.Processes[name].Environment, env)
+		}
+	}
+}
+
+func TestBuildProfileOverrideNoProfiles(t *testing.T) {
+	pc := &ProcessCompose{Processes: map[string]*Process{"web": {Command: "run"}}}
+	data, err := BuildProfileOverride(pc, t.TempDir())
+	if err != nil || data != nil {
+		t.Errorf("BuildProfileOverride() = %q, %v; want nil, nil", data, err)
+	}
+}
+
+func TestBuildProfileOverrideMissingProfile(t *testing.T) {
+	pc := &ProcessCompose{Processes: map[string]*Process{
+		"api": {Command: "run", EnvProfiles: []string{"prod"}},
+	}}
+	if _, err := BuildProfileOverride(pc, t.TempDir()); err == nil {
+		t.Error("expected error for missing profile")
+	}
+}
@@ -68,8 +68,8 @@ func (r TaskCommandRule) Check(pc *ProcessCompose) []Violation {
 	var violations []Violation
 
 	for name, proc := range pc.Processes {
-		if proc.Disabled {
-			continue // Skip disabled processes
+		if proc.Disabled || proc.IsWorker() {
+			continue // Skip disabled processes and wrangler-run Workers
 		}
 
 		if !proc.UsesTaskCommand() {
@@ -77,7 +77,7 @@ func (r TaskCommandRule) Check(pc *ProcessCompose) []Violation {
 				File:     pc.Path,
 				Line:     pc.FindProcessLineNumber(name),
 				Rule:     r.Name(),
-				Message:  fmt.Sprintf("process '%s' should use 'task %s:run' instead of '%s'", name, name, proc.Command),
+				Message:  fmt.Sprintf("process '%s' should use 'task %s:run' instead of '%s'", name, name, proc.CommandLine()),
 				Severity: SeverityWarning,
 			})
 		} else {
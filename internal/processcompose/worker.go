@@ -0,0 +1,37 @@
+package processcompose
+
+import "sort"
+
+// DefaultWorkerPort is the wrangler dev port used when a worker process has none.
+const DefaultWorkerPort = 8787
+
+// DefaultWorkerHealthPath is the readiness path for worker processes
+// (workers/sync-cf serves /health).
+const DefaultWorkerHealthPath = "/health"
+
+// WorkerInput describes a Cloudflare Worker run locally under workerd.
+type WorkerInput struct {
+	Dir  string            // Worker directory containing wrangler.toml
+	Vars map[string]string // Worker bindings, passed as --var KEY:VALUE
+}
+
+// WorkerEntrypoint returns the wrangler command line that runs a Worker
+// locally. wrangler dev --local runs the Worker in workerd without a
+// Cloudflare account, building it first via the [build] command in
+// wrangler.toml. It is used as the process entrypoint, so process-compose
+// starts wrangler without a shell and the --var values need no quoting on
+// any platform; ${VAR} references are still expanded when the config loads.
+func WorkerEntrypoint(port string, vars map[string]string) []string {
+	args := []string{"wrangler", "dev", "--local", "--ip", "127.0.0.1", "--port", port}
+
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--var", k+":"+vars[k])
+	}
+
+	return args
+}
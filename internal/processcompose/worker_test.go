@@ -0,0 +1,50 @@
+package processcompose
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProcessFromInputWorker(t *testing.T) {
+	input := &ProcessInput{
+		Name:       "sync-cf",
+		PortEnvVar: "SYNC_CF_PORT",
+		Worker: &WorkerInput{
+			Dir: "workers/sync-cf",
+			Vars: map[string]string{
+				"SYNC_ENDPOINT": "http://127.0.0.1:9091/cf/webhook",
+				"SYNC_TOKEN":    "dev token's",
+			},
+		},
+	}
+	proc := ProcessFromInput(input)
+
+	// No shell runs the entrypoint, so values are passed as-is on every platform
+	want := []string{"wrangler", "dev", "--local", "--ip", "127.0.0.1", "--port", "${SYNC_CF_PORT:-8787}",
+		"--var", "SYNC_ENDPOINT:http://127.0.0.1:9091/cf/webhook",
+		"--var", "SYNC_TOKEN:dev token's"}
+	if proc.Command != "" || !slices.Equal(proc.Entrypoint, want) {
+		t.Errorf("Command = %q, Entrypoint = %q\nwant Entrypoint %q", proc.Command, proc.Entrypoint, want)
+	}
+	if !proc.IsWorker() {
+		t.Error("IsWorker() = false")
+	}
+	if vars := ProcessVars(proc); !slices.Contains(vars, "SYNC_CF_PORT") {
+		t.Errorf("ProcessVars = %v, want SYNC_CF_PORT", vars)
+	}
+	if proc.WorkingDir != "workers/sync-cf" {
+		t.Errorf("WorkingDir = %q", proc.WorkingDir)
+	}
+	if proc.ReadinessProbe == nil || proc.ReadinessProbe.HTTPGet.Path != "/health" || proc.ReadinessProbe.HTTPGet.Port != "${SYNC_CF_PORT:-8787}" {
+		t.Errorf("ReadinessProbe = %+v", proc.ReadinessProbe)
+	}
+	if input.Port != 0 || input.HealthPath != "" {
+		t.Errorf("input was modified: %+v", input)
+	}
+
+	// Workers are exempt from the task-command convention
+	pc := &ProcessCompose{Processes: map[string]*Process{"sync-cf": proc}}
+	if v := (TaskCommandRule{}).Check(pc); len(v) != 0 {
+		t.Errorf("TaskCommandRule violations = %+v", v)
+	}
+}
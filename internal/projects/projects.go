@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
 	"gopkg.in/yaml.v3"
 )
 
@@ -64,7 +65,7 @@ func (r *Registry) Save() error {
 		return fmt.Errorf("failed to marshal registry: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, config.DefaultFilePerms); err != nil {
+	if err := statestore.WriteFile(path, data, config.DefaultFilePerms); err != nil {
 		return fmt.Errorf("failed to write registry: %w", err)
 	}
 
@@ -0,0 +1,262 @@
+// Package projectstate places tool state under ~/.xplat/state, one
+// directory per project, so tools never write dot-files into the working
+// tree and find the same state from any subdirectory of a project.
+//
+// A project is identified by its git "origin" remote, normalized so the
+// HTTPS and SSH forms match (github.com/owner/repo). Checkouts without a
+// remote fall back to the repository root, and non-repos to the directory
+// itself. The identity is hashed into the directory name:
+//
+//	~/.xplat/state/<hash>/project.json     identity and last known path
+//	~/.xplat/state/<hash>/sitecheck/...    per-tool state
+//	~/.xplat/state/<hash>/cfanalytics/...
+package projectstate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/gitops"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// metaFile is the file in each project directory describing the project.
+const metaFile = "project.json"
+
+// Project is one project's state directory.
+type Project struct {
+	ID   string `json:"id"`   // Normalized git remote, or the project path
+	Path string `json:"path"` // Project root the state was last written from
+	Hash string `json:"-"`    // Directory name under the state directory
+
+	// Set by List
+	Size    int64     `json:"-"`
+	ModTime time.Time `json:"-"` // Newest file in the directory
+}
+
+// Identify returns the identity and root of the project containing dir.
+func Identify(dir string) (id, root string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	root, err = gitops.Root(abs)
+	if err != nil {
+		return abs, abs
+	}
+	if remote, err := gitops.RemoteURL(root, "origin"); err == nil {
+		if id := NormalizeRemote(remote); id != "" {
+			return id, root
+		}
+	}
+	return root, root
+}
+
+// NormalizeRemote reduces a git remote URL to host/path, so
+// https://github.com/o/r.git and git@github.com:o/r are the same project.
+func NormalizeRemote(remote string) string {
+	s := strings.TrimSpace(remote)
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	} else if i := strings.Index(s, ":"); i > 0 && !strings.Contains(s[:i], "/") {
+		// scp-like syntax: [user@]host:path
+		s = s[:i] + "/" + s[i+1:]
+	}
+	if i := strings.Index(s, "@"); i >= 0 && i < strings.Index(s+"/", "/") {
+		s = s[i+1:]
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "/"), ".git")
+
+	host, path, _ := strings.Cut(s, "/")
+	if host == "" || path == "" {
+		return ""
+	}
+	return strings.ToLower(host) + "/" + path
+}
+
+// Hash returns the directory name for a project identity.
+func Hash(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// cache remembers opened projects and registered state directories, so
+// repeated lookups in a long-running process don't run git or re-read
+// project.json each time.
+var cache struct {
+	sync.Mutex
+	opened     map[string]Project // dir -> project
+	registered map[string]Project // project.json path -> identity written
+}
+
+// Open returns the project containing dir. The identity is looked up once
+// per directory and process.
+func Open(dir string) *Project {
+	cache.Lock()
+	defer cache.Unlock()
+	if p, ok := cache.opened[dir]; ok {
+		return &p
+	}
+
+	id, root := Identify(dir)
+	p := Project{ID: id, Path: root, Hash: Hash(id)}
+	if cache.opened == nil {
+		cache.opened = make(map[string]Project)
+	}
+	cache.opened[dir] = p
+	return &p
+}
+
+// Current returns the project containing the working directory.
+func Current() *Project {
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+	return Open(wd)
+}
+
+// Dir returns the project's state directory.
+func (p *Project) Dir() string {
+	return filepath.Join(config.XplatState(), p.Hash)
+}
+
+// ToolDir returns a tool's directory in the project's state directory,
+// recording the project's identity there on first use.
+func (p *Project) ToolDir(tool string) string {
+	if err := p.register(); err != nil {
+		// State still works; 'xplat state ls' just can't name the project
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return filepath.Join(p.Dir(), tool)
+}
+
+// ToolDir returns a tool's state directory for the current project:
+// ~/.xplat/state/<hash>/<tool>
+func ToolDir(tool string) string {
+	return Current().ToolDir(tool)
+}
+
+// register writes project.json unless it already describes p.
+func (p *Project) register() error {
+	path := filepath.Join(p.Dir(), metaFile)
+	identity := Project{ID: p.ID, Path: p.Path}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if cache.registered[path] == identity {
+		return nil
+	}
+	if cache.registered == nil {
+		cache.registered = make(map[string]Project)
+	}
+
+	if data, err := os.ReadFile(path); err == nil {
+		var existing Project
+		if json.Unmarshal(data, &existing) == nil && existing == identity {
+			cache.registered[path] = identity
+			return nil
+		}
+	}
+
+	data, err := json.MarshalIndent(identity, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode project state: %w", err)
+	}
+	if err := statestore.WriteFile(path, append(data, '\n'), config.DefaultFilePerms); err != nil {
+		return fmt.Errorf("failed to record project state: %w", err)
+	}
+	cache.registered[path] = identity
+	return nil
+}
+
+// List returns every project with a state directory, most recently
+// written first.
+func List() ([]*Project, error) {
+	entries, err := os.ReadDir(config.XplatState())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read state directory: %w", err)
+	}
+
+	var projects []*Project
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		p := &Project{Hash: e.Name()}
+		if data, err := os.ReadFile(filepath.Join(p.Dir(), metaFile)); err == nil {
+			_ = json.Unmarshal(data, p)
+		}
+		p.Size, p.ModTime = dirUsage(p.Dir())
+		projects = append(projects, p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ModTime.After(projects[j].ModTime) })
+	return projects, nil
+}
+
+// Find returns the listed project whose hash or identity is key.
+func Find(projects []*Project, key string) *Project {
+	for _, p := range projects {
+		if p.Hash == key || (p.ID != "" && p.ID == key) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Missing reports whether the project's last known path no longer exists.
+func (p *Project) Missing() bool {
+	if p.Path == "" {
+		return false
+	}
+	_, err := os.Stat(p.Path)
+	return os.IsNotExist(err)
+}
+
+// Remove deletes the project's state directory.
+func (p *Project) Remove() error {
+	if p.Hash == "" {
+		return fmt.Errorf("project has no state directory")
+	}
+	if err := os.RemoveAll(p.Dir()); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", p.Dir(), err)
+	}
+
+	cache.Lock()
+	delete(cache.registered, filepath.Join(p.Dir(), metaFile))
+	cache.Unlock()
+	return nil
+}
+
+// dirUsage returns the total size and newest modification time of the
+// files under dir.
+func dirUsage(dir string) (int64, time.Time) {
+	var size int64
+	var newest time.Time
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			size += info.Size()
+			if info.ModTime().After(newest) {
+				newest = info.ModTime()
+			}
+		}
+		return nil
+	})
+	return size, newest
+}
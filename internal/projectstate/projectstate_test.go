@@ -0,0 +1,96 @@
+package projectstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+)
+
+func TestNormalizeRemote(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/joeblew999/xplat.git":      "github.com/joeblew999/xplat",
+		"git@github.com:joeblew999/xplat.git":          "github.com/joeblew999/xplat",
+		"ssh://git@GitHub.com/joeblew999/xplat":        "github.com/joeblew999/xplat",
+		"https://token@github.com/joeblew999/xplat/":   "github.com/joeblew999/xplat",
+		"https://gitlab.example.com/group/sub/project": "gitlab.example.com/group/sub/project",
+		"not-a-remote": "",
+	}
+	for in, want := range tests {
+		if got := NormalizeRemote(in); got != want {
+			t.Errorf("NormalizeRemote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Not a repo: the directory itself
+	if id, root := Identify(sub); id != sub || root != sub {
+		t.Errorf("Identify() outside a repo = %q, %q", id, root)
+	}
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A repo without a remote: the repository root
+	if id, root := Identify(sub); id != dir || root != dir {
+		t.Errorf("Identify() without a remote = %q, %q", id, root)
+	}
+
+	if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"git@github.com:o/r.git"}}); err != nil {
+		t.Fatal(err)
+	}
+	if id, root := Identify(sub); id != "github.com/o/r" || root != dir {
+		t.Errorf("Identify() with a remote = %q, %q", id, root)
+	}
+}
+
+func TestListAndRemove(t *testing.T) {
+	t.Setenv("XPLAT_HOME", t.TempDir())
+	project := t.TempDir()
+
+	p := Open(project)
+	dir := p.ToolDir("sitecheck")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "example.com.jsonl"), []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].Hash != p.Hash || projects[0].ID != project || projects[0].Size == 0 {
+		t.Fatalf("List() = %+v", projects)
+	}
+	if Find(projects, project) == nil || Find(projects, "other") != nil {
+		t.Error("Find() by identity failed")
+	}
+	if projects[0].Missing() {
+		t.Error("Missing() for an existing project")
+	}
+
+	if err := projects[0].Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if projects, _ := List(); len(projects) != 0 {
+		t.Errorf("List() after Remove() = %+v", projects)
+	}
+
+	// The registration cache is dropped with the directory
+	Open(project).ToolDir("sitecheck")
+	if _, err := os.Stat(filepath.Join(p.Dir(), metaFile)); err != nil {
+		t.Errorf("project.json not rewritten after Remove(): %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package registry
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/lockfile"
+	"golang.org/x/mod/semver"
+)
+
+// Deprecation marks a package, or its versions before Before, as deprecated.
+type Deprecation struct {
+	Message     string `yaml:"message"`
+	Replacement string `yaml:"replacement,omitempty"` // Package to use instead
+	Before      string `yaml:"before,omitempty"`      // First version that is not deprecated (empty: all versions)
+}
+
+// Advisory is a known vulnerability affecting a range of versions:
+// Introduced <= version < Fixed.
+type Advisory struct {
+	ID         string `yaml:"id"` // CVE-2026-12345 or GHSA-xxxx-xxxx-xxxx
+	Summary    string `yaml:"summary"`
+	Severity   string `yaml:"severity,omitempty"`   // low, moderate, high, critical
+	Introduced string `yaml:"introduced,omitempty"` // First affected version (empty: all versions before Fixed)
+	Fixed      string `yaml:"fixed,omitempty"`      // First fixed version (empty: no fix yet)
+	URL        string `yaml:"url,omitempty"`
+}
+
+// Finding kinds.
+const (
+	FindingDeprecated = "deprecated"
+	FindingAdvisory   = "advisory"
+)
+
+// Finding is a deprecation or advisory affecting an installed version.
+type Finding struct {
+	Project  string `json:"project,omitempty"` // Directory of the lockfile (AuditLockfiles)
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	Kind     string `json:"kind"`
+	ID       string `json:"id,omitempty"`
+	Severity string `json:"severity,omitempty"`
+	Message  string `json:"message"`
+	Fixed    string `json:"fixed,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// String formats the finding for install warnings.
+func (f Finding) String() string {
+	if f.Kind == FindingDeprecated {
+		return fmt.Sprintf("%s %s is deprecated: %s", f.Package, f.Version, f.Message)
+	}
+	s := fmt.Sprintf("%s %s is affected by %s", f.Package, f.Version, f.ID)
+	if f.Severity != "" {
+		s += " (" + f.Severity + ")"
+	}
+	s += ": " + f.Message
+	if f.Fixed != "" {
+		s += ", fixed in " + f.Fixed
+	}
+	return s
+}
+
+// Findings returns the deprecation and advisories affecting version of the
+// entry. A version that is not semver ("dev", "latest", a branch) is taken
+// as the newest release: only open-ended ranges apply to it.
+func (e IndexEntry) Findings(version string) []Finding {
+	var findings []Finding
+	if d := e.Deprecated; d != nil && inRange(version, "", d.Before) {
+		msg := d.Message
+		if d.Replacement != "" {
+			msg += " (use " + d.Replacement + ")"
+		}
+		findings = append(findings, Finding{Package: e.Name, Version: version, Kind: FindingDeprecated, Message: msg})
+	}
+	for _, a := range e.Advisories {
+		if !inRange(version, a.Introduced, a.Fixed) {
+			continue
+		}
+		findings = append(findings, Finding{
+			Package:  e.Name,
+			Version:  version,
+			Kind:     FindingAdvisory,
+			ID:       a.ID,
+			Severity: a.Severity,
+			Message:  a.Summary,
+			Fixed:    a.Fixed,
+			URL:      a.URL,
+		})
+	}
+	return findings
+}
+
+// inRange reports whether introduced <= version < fixed, with empty bounds open.
+func inRange(version, introduced, fixed string) bool {
+	v := canonicalVersion(version)
+	if v == "" {
+		return fixed == ""
+	}
+	if introduced != "" && semver.Compare(v, canonicalVersion(introduced)) < 0 {
+		return false
+	}
+	return fixed == "" || semver.Compare(v, canonicalVersion(fixed)) < 0
+}
+
+// canonicalVersion returns version as "vMAJOR.MINOR.PATCH...", or "" if it
+// is not semver. The "v" prefix is optional in the index.
+func canonicalVersion(version string) string {
+	if version != "" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return semver.Canonical(version)
+}
+
+// Entry returns the index entry for a package: by name, else the only entry
+// for repo (e.g. "joeblew999/plat-nats" or "github.com/joeblew999/plat-nats").
+func (idx *Index) Entry(name, repo string) (IndexEntry, bool) {
+	if e, ok := idx.Packages[name]; ok {
+		e.Name = name
+		return e, true
+	}
+	if repo == "" {
+		return IndexEntry{}, false
+	}
+	repo = normalizeRepo(repo)
+	var found []IndexEntry
+	for n, e := range idx.Packages {
+		if normalizeRepo(e.Repo) == repo {
+			e.Name = n
+			found = append(found, e)
+		}
+	}
+	if len(found) != 1 {
+		return IndexEntry{}, false
+	}
+	return found[0], true
+}
+
+func normalizeRepo(repo string) string {
+	repo = strings.TrimPrefix(repo, "https://")
+	repo = strings.TrimPrefix(repo, "github:")
+	repo = strings.TrimSuffix(strings.TrimSuffix(repo, "/"), ".git")
+	if !strings.Contains(strings.SplitN(repo, "/", 2)[0], ".") {
+		repo = "github.com/" + repo
+	}
+	return strings.ToLower(repo)
+}
+
+// Findings returns the deprecation and advisories in the index affecting
+// version of a package (see Index.Entry).
+func (c *Client) Findings(name, repo, version string) ([]Finding, error) {
+	index, err := c.FetchIndex()
+	if err != nil {
+		return nil, err
+	}
+	entry, ok := index.Entry(name, repo)
+	if !ok {
+		return nil, nil
+	}
+	findings := entry.Findings(version)
+	for i := range findings {
+		findings[i].Package = name
+	}
+	return findings, nil
+}
+
+// AuditLockfiles checks the packages of every xplat-lock.yaml under root
+// (hidden directories and node_modules skipped) against the index.
+func (idx *Index) AuditLockfiles(root string) ([]Finding, error) {
+	var findings []Finding
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable directories
+		}
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != lockfile.FileName {
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		lf, err := lockfile.Load(dir)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range lf.ListPackages() {
+			var repo string
+			if strings.HasPrefix(pkg.Source, "github:") {
+				repo = pkg.Source
+			}
+			entry, ok := idx.Entry(pkg.Name, repo)
+			if !ok {
+				continue
+			}
+			for _, f := range entry.Findings(pkg.Version) {
+				f.Project = dir
+				f.Package = pkg.Name
+				findings = append(findings, f)
+			}
+		}
+		return nil
+	})
+	return findings, err
+}
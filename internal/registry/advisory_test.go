@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeblew999/xplat/internal/lockfile"
+	"gopkg.in/yaml.v3"
+)
+
+const advisoryIndex = `
+packages:
+  plat-nats:
+    repo: github.com/joeblew999/plat-nats
+    description: NATS server
+    advisories:
+      - id: CVE-2026-1000
+        summary: Auth bypass on leaf nodes
+        severity: high
+        introduced: v0.2.0
+        fixed: v0.4.1
+      - id: GHSA-xxxx-yyyy-zzzz
+        summary: Unpatched DoS
+  old-tool:
+    repo: github.com/joeblew999/old-tool
+    deprecated:
+      message: no longer maintained
+      replacement: plat-nats
+`
+
+func loadAdvisoryIndex(t *testing.T) *Index {
+	t.Helper()
+	var idx Index
+	if err := yaml.Unmarshal([]byte(advisoryIndex), &idx); err != nil {
+		t.Fatal(err)
+	}
+	return &idx
+}
+
+func TestFindings(t *testing.T) {
+	idx := loadAdvisoryIndex(t)
+	nats, ok := idx.Entry("plat-nats", "")
+	if !ok {
+		t.Fatal("plat-nats not found")
+	}
+
+	tests := map[string][]string{
+		"v0.1.0": {"GHSA-xxxx-yyyy-zzzz"},
+		"0.2.0":  {"CVE-2026-1000", "GHSA-xxxx-yyyy-zzzz"},
+		"v0.4.0": {"CVE-2026-1000", "GHSA-xxxx-yyyy-zzzz"},
+		"v0.4.1": {"GHSA-xxxx-yyyy-zzzz"},
+		"dev":    {"GHSA-xxxx-yyyy-zzzz"},
+	}
+	for version, want := range tests {
+		var got []string
+		for _, f := range nats.Findings(version) {
+			got = append(got, f.ID)
+		}
+		if len(got) != len(want) {
+			t.Errorf("Findings(%q) = %v, want %v", version, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Findings(%q) = %v, want %v", version, got, want)
+			}
+		}
+	}
+
+	// Looked up by repo when the name is not in the index
+	old, ok := idx.Entry("old", "joeblew999/Old-Tool")
+	if !ok || old.Name != "old-tool" {
+		t.Fatalf("Entry() by repo = %+v, %v", old, ok)
+	}
+	f := old.Findings("v1.0.0")
+	if len(f) != 1 || f[0].Kind != FindingDeprecated || f[0].String() != "old-tool v1.0.0 is deprecated: no longer maintained (use plat-nats)" {
+		t.Errorf("Findings() = %+v", f)
+	}
+}
+
+func TestAuditLockfiles(t *testing.T) {
+	root := t.TempDir()
+	for dir, version := range map[string]string{"plat-a": "v0.3.0", "plat-b": "v0.5.0", ".hidden": "v0.3.0"} {
+		path := filepath.Join(root, dir)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		lf, _ := lockfile.Load(path)
+		lf.AddPackage(lockfile.Package{Name: "plat-nats", Version: version, Source: "registry:plat-nats"})
+		if err := lf.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	findings, err := loadAdvisoryIndex(t).AuditLockfiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// plat-a: both advisories, plat-b: the unfixed one
+	if len(findings) != 3 {
+		t.Fatalf("AuditLockfiles() = %+v", findings)
+	}
+	for _, f := range findings {
+		if filepath.Base(f.Project) == ".hidden" {
+			t.Errorf("hidden directory audited: %+v", f)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/lockfile"
+)
+
+// paramPattern matches ${xplat.<name>} placeholders in bundle files.
+// This syntax does not clash with Task's {{.VAR}} or shell ${VAR}.
+var paramPattern = regexp.MustCompile(`\$\{xplat\.([A-Za-z0-9_]+)\}`)
+
+// Built-in bundle parameters, always available.
+const (
+	ParamProject = "project" // Project directory name
+	ParamPackage = "package" // Package name
+	ParamVersion = "version" // Package version
+)
+
+// BundleParams returns the parameter values for a package's bundle:
+// built-ins, then the package's defaults, then overrides. Overrides must
+// name a parameter the package declares.
+func BundleParams(pkg *Package, projectDir string, overrides map[string]string) (map[string]string, error) {
+	abs, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{
+		ParamProject: filepath.Base(abs),
+		ParamPackage: pkg.Name,
+		ParamVersion: pkg.Version,
+	}
+	for k, v := range pkg.Params {
+		params[k] = v
+	}
+	for k, v := range overrides {
+		if _, ok := pkg.Params[k]; !ok {
+			return nil, fmt.Errorf("package %s has no parameter %q", pkg.Name, k)
+		}
+		params[k] = v
+	}
+	return params, nil
+}
+
+// ExpandParams replaces ${xplat.<name>} placeholders. Unknown names are an
+// error, so a typo in a bundle never installs a half-substituted file.
+func ExpandParams(s string, params map[string]string) (string, error) {
+	var missing []string
+	out := paramPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := paramPattern.FindStringSubmatch(m)[1]
+		v, ok := params[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unknown parameter(s): %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// RenderedFile is a bundle file ready to be written into the project.
+type RenderedFile struct {
+	Path    string // Project-relative destination (slash-separated)
+	Source  string // Src in the package repo
+	Content []byte
+}
+
+// FetchFile downloads a file from the package repo at the package version.
+func (c *Client) FetchFile(pkg *Package, path string) ([]byte, error) {
+	ref := pkg.Version
+	if ref == "" {
+		ref = "main"
+	}
+	url := fmt.Sprintf("%s/repos/%s/contents/%s?ref=%s", c.apiURL, pkg.GitHubRepo(), path, ref)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3.raw")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s@%s returned HTTP %d", path, ref, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// RenderBundle fetches the package's bundle files and substitutes params
+// in their destinations and content.
+func (c *Client) RenderBundle(pkg *Package, params map[string]string) ([]RenderedFile, error) {
+	files := make([]RenderedFile, 0, len(pkg.Files))
+	for _, f := range pkg.Files {
+		dest, err := ExpandParams(f.Dest, params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Dest, err)
+		}
+		if err := checkBundlePath(dest); err != nil {
+			return nil, err
+		}
+
+		data, err := c.FetchFile(pkg, f.Src)
+		if err != nil {
+			return nil, err
+		}
+		content, err := ExpandParams(string(data), params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Src, err)
+		}
+
+		files = append(files, RenderedFile{Path: filepath.ToSlash(dest), Source: f.Src, Content: []byte(content)})
+	}
+	return files, nil
+}
+
+// checkBundlePath rejects destinations outside the project.
+func checkBundlePath(dest string) error {
+	clean := filepath.Clean(filepath.FromSlash(dest))
+	if dest == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("bundle destination %q is outside the project", dest)
+	}
+	return nil
+}
+
+// File actions reported by ApplyBundle.
+const (
+	FileCreated   = "created"
+	FileUpdated   = "updated"
+	FileUnchanged = "unchanged"
+	FileModified  = "modified" // Edited locally, left alone
+	FileConflict  = "conflict" // Exists but not installed by this package, left alone
+	FileRemoved   = "removed"
+	FileKept      = "kept" // No longer in the bundle but edited locally, left and unmanaged
+)
+
+// FileChange is one file's outcome in ApplyBundle.
+type FileChange struct {
+	Path   string
+	Action string
+}
+
+// ApplyBundle writes rendered files into dir, using the ledger from the
+// previous install to update files cleanly:
+//   - files still matching the ledger hash are replaced or removed
+//   - locally edited files are left alone (unless force) and stay in the ledger
+//   - existing files the package never installed are not overwritten (unless force)
+//
+// It returns the new ledger and the changes, sorted by path.
+func ApplyBundle(dir string, files []RenderedFile, ledger []lockfile.ManagedFile, force bool) ([]lockfile.ManagedFile, []FileChange, error) {
+	previous := make(map[string]lockfile.ManagedFile, len(ledger))
+	for _, f := range ledger {
+		previous[f.Path] = f
+	}
+
+	var next []lockfile.ManagedFile
+	var changes []FileChange
+	seen := make(map[string]bool)
+
+	for _, f := range files {
+		seen[f.Path] = true
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		sum := hashContent(f.Content)
+		entry := lockfile.ManagedFile{Path: f.Path, Source: f.Source, SHA256: sum}
+
+		current, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", f.Path, err)
+		}
+		exists := err == nil
+		prev, managed := previous[f.Path]
+
+		action := FileCreated
+		switch {
+		case !exists:
+		case bytes.Equal(current, f.Content):
+			action = FileUnchanged
+		case managed && hashContent(current) == prev.SHA256, force:
+			action = FileUpdated
+		case managed:
+			// Keep the old hash so the edit is still detected next time
+			next = append(next, prev)
+			changes = append(changes, FileChange{f.Path, FileModified})
+			continue
+		default:
+			changes = append(changes, FileChange{f.Path, FileConflict})
+			continue
+		}
+
+		if action != FileUnchanged {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, nil, fmt.Errorf("failed to create directory for %s: %w", f.Path, err)
+			}
+			if err := os.WriteFile(path, f.Content, 0644); err != nil {
+				return nil, nil, fmt.Errorf("failed to write %s: %w", f.Path, err)
+			}
+		}
+		next = append(next, entry)
+		changes = append(changes, FileChange{f.Path, action})
+	}
+
+	// Files dropped from the bundle
+	for _, prev := range ledger {
+		if seen[prev.Path] {
+			continue
+		}
+		action, err := removeManagedFile(dir, prev)
+		if err != nil {
+			return nil, nil, err
+		}
+		if action != "" {
+			changes = append(changes, FileChange{prev.Path, action})
+		}
+	}
+
+	sort.Slice(next, func(i, j int) bool { return next[i].Path < next[j].Path })
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return next, changes, nil
+}
+
+// RemoveBundle removes the ledger's files that were not edited locally.
+func RemoveBundle(dir string, ledger []lockfile.ManagedFile) ([]FileChange, error) {
+	var changes []FileChange
+	for _, f := range ledger {
+		action, err := removeManagedFile(dir, f)
+		if err != nil {
+			return changes, err
+		}
+		if action != "" {
+			changes = append(changes, FileChange{f.Path, action})
+		}
+	}
+	return changes, nil
+}
+
+// removeManagedFile deletes a managed file if it still has the installed
+// content. Returns FileRemoved, FileKept, or "" if it was already gone.
+func removeManagedFile(dir string, f lockfile.ManagedFile) (string, error) {
+	path := filepath.Join(dir, filepath.FromSlash(f.Path))
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", f.Path, err)
+	}
+	if hashContent(current) != f.SHA256 {
+		return FileKept, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return "", fmt.Errorf("failed to remove %s: %w", f.Path, err)
+	}
+	return FileRemoved, nil
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
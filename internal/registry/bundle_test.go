@@ -0,0 +1,189 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joeblew999/xplat/internal/lockfile"
+)
+
+func TestExpandParams(t *testing.T) {
+	params := map[string]string{"project": "site", "port": "8443"}
+
+	got, err := ExpandParams("listen: ${xplat.port} # {{.PORT}} ${HOME} ${xplat.project}", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "listen: 8443 # {{.PORT}} ${HOME} site"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := ExpandParams("${xplat.prot}", params); err == nil {
+		t.Error("expected error for unknown parameter")
+	}
+}
+
+func TestBundleParams(t *testing.T) {
+	pkg := &Package{Name: "caddy", Version: "v1.0.0", Params: map[string]string{"port": "443"}}
+	dir := filepath.Join(t.TempDir(), "my-site")
+
+	params, err := BundleParams(pkg, dir, map[string]string{"port": "8443"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["project"] != "my-site" || params["package"] != "caddy" || params["version"] != "v1.0.0" || params["port"] != "8443" {
+		t.Errorf("params = %v", params)
+	}
+
+	if _, err := BundleParams(pkg, dir, map[string]string{"nope": "1"}); err == nil {
+		t.Error("expected error for undeclared parameter")
+	}
+}
+
+func TestRenderBundle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/caddy/contents/bundle/site.caddy" || r.URL.Query().Get("ref") != "v1.0.0" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(":${xplat.port} {\n\trespond \"${xplat.project}\"\n}\n"))
+	}))
+	defer srv.Close()
+
+	pkg := &Package{
+		Name: "caddy", Version: "v1.0.0", RepoURL: "https://github.com/o/caddy",
+		Files: []BundleFile{{Src: "bundle/site.caddy", Dest: "caddy/${xplat.project}.caddy"}},
+	}
+	client := NewClient().WithAPIURL(srv.URL)
+
+	files, err := client.RenderBundle(pkg, map[string]string{"project": "site", "port": "8443"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "caddy/site.caddy" || string(files[0].Content) != ":8443 {\n\trespond \"site\"\n}\n" {
+		t.Errorf("files = %+v", files)
+	}
+
+	pkg.Files[0].Dest = "../outside.caddy"
+	if _, err := client.RenderBundle(pkg, map[string]string{}); err == nil {
+		t.Error("expected error for destination outside the project")
+	}
+}
+
+func TestApplyBundle(t *testing.T) {
+	dir := t.TempDir()
+	read := func(p string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, p))
+		return string(data)
+	}
+	write := func(p, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, p)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, p), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	actions := func(changes []FileChange) map[string]string {
+		m := make(map[string]string)
+		for _, c := range changes {
+			m[c.Path] = c.Action
+		}
+		return m
+	}
+
+	// Install: a.yml and b.yml are new, c.yml exists but is not ours
+	write("c.yml", "user\n")
+	ledger, changes, err := ApplyBundle(dir, []RenderedFile{
+		{Path: "t/a.yml", Content: []byte("a1\n")},
+		{Path: "b.yml", Content: []byte("b1\n")},
+		{Path: "c.yml", Content: []byte("c1\n")},
+	}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := actions(changes)
+	if got["t/a.yml"] != FileCreated || got["b.yml"] != FileCreated || got["c.yml"] != FileConflict {
+		t.Errorf("install changes = %v", got)
+	}
+	if len(ledger) != 2 || read("c.yml") != "user\n" {
+		t.Errorf("ledger = %+v, c.yml = %q", ledger, read("c.yml"))
+	}
+
+	// Upgrade: a.yml untouched -> updated, b.yml edited -> left alone,
+	// and a dropped file is removed
+	write("b.yml", "b1 edited\n")
+	ledger = append(ledger, lockfile.ManagedFile{Path: "old.yml", SHA256: hashContent([]byte("old\n"))})
+	write("old.yml", "old\n")
+
+	ledger, changes, err = ApplyBundle(dir, []RenderedFile{
+		{Path: "t/a.yml", Content: []byte("a2\n")},
+		{Path: "b.yml", Content: []byte("b2\n")},
+	}, ledger, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = actions(changes)
+	if got["t/a.yml"] != FileUpdated || got["b.yml"] != FileModified || got["old.yml"] != FileRemoved {
+		t.Errorf("upgrade changes = %v", got)
+	}
+	if read("t/a.yml") != "a2\n" || read("b.yml") != "b1 edited\n" {
+		t.Errorf("a = %q, b = %q", read("t/a.yml"), read("b.yml"))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.yml")); !os.IsNotExist(err) {
+		t.Error("old.yml should be removed")
+	}
+
+	// The edit is still detected on the next upgrade; --force overwrites it
+	_, changes, err = ApplyBundle(dir, []RenderedFile{{Path: "b.yml", Content: []byte("b3\n")}}, ledger, false)
+	if err != nil || actions(changes)["b.yml"] != FileModified {
+		t.Errorf("second upgrade changes = %v, err = %v", changes, err)
+	}
+	ledger, changes, err = ApplyBundle(dir, []RenderedFile{{Path: "b.yml", Content: []byte("b3\n")}}, ledger, true)
+	if err != nil || actions(changes)["b.yml"] != FileUpdated || read("b.yml") != "b3\n" {
+		t.Errorf("forced upgrade changes = %v, err = %v", changes, err)
+	}
+
+	// Remove: only unedited files go
+	write("t/a.yml", "a2 edited\n")
+	ledger = append(ledger, lockfile.ManagedFile{Path: "t/a.yml", SHA256: hashContent([]byte("a2\n"))})
+	changes, err = RemoveBundle(dir, ledger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = actions(changes)
+	if got["b.yml"] != FileRemoved || got["t/a.yml"] != FileKept {
+		t.Errorf("remove changes = %v", got)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestFetchFileAuthenticated(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_test")
+	var auth []string
+	client := NewClient()
+	client.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		rec := httptest.NewRecorder()
+		_, _ = rec.WriteString("ok")
+		return rec.Result(), nil
+	})}
+
+	pkg := &Package{Name: "private", Version: "v1.0.0", RepoURL: "https://github.com/o/private"}
+	if _, err := client.FetchFile(pkg, "bundle/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	// A non-GitHub API (e.g. a test server) never gets the token
+	client.WithAPIURL("http://127.0.0.1:1")
+	_, _ = client.FetchFile(pkg, "bundle/a.txt")
+
+	if len(auth) != 2 || auth[0] != "Bearer ghp_test" || auth[1] != "" {
+		t.Errorf("Authorization headers = %q", auth)
+	}
+}
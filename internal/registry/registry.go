@@ -7,9 +7,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
 )
 
 // Index URL points to the raw index.yaml in the xplat repo.
@@ -29,6 +32,9 @@ type IndexEntry struct {
 	Name        string `yaml:"-"`           // Package name (set during list)
 	Repo        string `yaml:"repo"`        // e.g., "github.com/litesql/ha"
 	Description string `yaml:"description"` // Short description
+
+	Deprecated *Deprecation `yaml:"deprecated,omitempty"`
+	Advisories []Advisory   `yaml:"advisories,omitempty"` // Known vulnerabilities (see Findings)
 }
 
 // Client provides access to the package registry using the hybrid approach:
@@ -36,8 +42,13 @@ type IndexEntry struct {
 // 2. Each repo's xplat.yaml provides full package metadata
 type Client struct {
 	indexURL   string
+	apiURL     string
 	httpClient *http.Client
 	indexCache *Index
+
+	tokenOnce sync.Once // GitHub credential, resolved on the first GitHub request
+	token     string
+	tokenErr  error
 }
 
 // NewClient creates a new registry client.
@@ -48,6 +59,7 @@ func NewClient() *Client {
 	}
 	return &Client{
 		indexURL: url,
+		apiURL:   "https://api.github.com",
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
@@ -60,13 +72,23 @@ func (c *Client) WithIndexURL(url string) *Client {
 	return c
 }
 
+// WithAPIURL sets a custom GitHub API base URL (for testing).
+func (c *Client) WithAPIURL(url string) *Client {
+	c.apiURL = url
+	return c
+}
+
 // FetchIndex downloads and parses the central index.
 func (c *Client) FetchIndex() (*Index, error) {
 	if c.indexCache != nil {
 		return c.indexCache, nil
 	}
 
-	resp, err := c.httpClient.Get(c.indexURL)
+	req, err := http.NewRequest("GET", c.indexURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch index: %w", err)
 	}
@@ -85,6 +107,23 @@ func (c *Client) FetchIndex() (*Index, error) {
 	return &index, nil
 }
 
+// do sends a request, authenticated with the ghauth credential chain when
+// it goes to GitHub, so packages in private repos resolve too.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if ghauth.IsGitHubURL(req.URL.String()) {
+		c.tokenOnce.Do(func() {
+			c.token, c.tokenErr = ghauth.Token(req.Context())
+		})
+		if c.tokenErr != nil {
+			return nil, fmt.Errorf("failed to resolve GitHub credentials: %w", c.tokenErr)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+	}
+	return c.httpClient.Do(req)
+}
+
 // LookupRepo returns the repo URL for a package name.
 // Returns the name itself if it looks like a direct repo URL (contains "/").
 func (c *Client) LookupRepo(name string) (string, error) {
@@ -112,7 +151,7 @@ func (c *Client) FetchManifest(repo string) (*Package, error) {
 	// Use GitHub API to avoid CDN caching issues with raw.githubusercontent.com
 	// The API returns fresh content immediately after pushes
 	repoPath := strings.TrimPrefix(repo, "github.com/")
-	url := fmt.Sprintf("https://api.github.com/repos/%s/contents/xplat.yaml?ref=main", repoPath)
+	url := fmt.Sprintf("%s/repos/%s/contents/xplat.yaml?ref=main", c.apiURL, repoPath)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -121,7 +160,7 @@ func (c *Client) FetchManifest(repo string) (*Package, error) {
 	// Request raw content directly
 	req.Header.Set("Accept", "application/vnd.github.v3.raw")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manifest from %s: %w", repo, err)
 	}
@@ -166,6 +205,12 @@ func (c *Client) FetchManifest(repo string) (*Package, error) {
 		pkg.TaskfilePath = m.Taskfile.Path
 	}
 
+	// Map file bundle
+	if m.Files != nil {
+		pkg.Files = m.Files.Bundle
+		pkg.Params = m.Files.Params
+	}
+
 	// Map process config (use "default" process from map or the single "process" field)
 	if proc := m.GetDefaultProcess(); proc != nil && proc.Command != "" {
 		pkg.Process = &ProcessConfig{
@@ -21,6 +21,18 @@ type Package struct {
 	License      string         `json:"license"`
 	Author       string         `json:"author"`
 	Process      *ProcessConfig `json:"process,omitempty"`
+
+	// Files is the bundle of project files (taskfiles, process-compose
+	// fragments, Caddy snippets) installed with parameter substitution.
+	Files  []BundleFile      `json:"files,omitempty"`
+	Params map[string]string `json:"params,omitempty"` // Parameter defaults
+}
+
+// BundleFile is a file shipped by a package: Src in the package repo,
+// installed to Dest in the project (both may use ${xplat.<param>}).
+type BundleFile struct {
+	Src  string `json:"src" yaml:"src"`
+	Dest string `json:"dest" yaml:"dest"`
 }
 
 // ProcessConfig defines how a package runs as a long-running process.
@@ -50,6 +62,11 @@ func (p *Package) HasProcess() bool {
 	return p.Process != nil && p.Process.Command != ""
 }
 
+// HasFiles returns true if the package ships a file bundle.
+func (p *Package) HasFiles() bool {
+	return len(p.Files) > 0
+}
+
 // GitHubRepo extracts owner/repo from the repo_url.
 // e.g., "https://github.com/joeblew999/ubuntu-website" -> "joeblew999/ubuntu-website"
 func (p *Package) GitHubRepo() string {
@@ -82,6 +99,13 @@ type Manifest struct {
 	Taskfile    *ManifestTF               `yaml:"taskfile,omitempty"`
 	Process     *ManifestProc             `yaml:"process,omitempty"`   // Singular (legacy)
 	Processes   map[string]*ManifestProc  `yaml:"processes,omitempty"` // Map format (preferred)
+	Files       *ManifestFiles            `yaml:"files,omitempty"`
+}
+
+// ManifestFiles is the file bundle config from xplat.yaml.
+type ManifestFiles struct {
+	Params map[string]string `yaml:"params,omitempty"` // Parameter name -> default value
+	Bundle []BundleFile      `yaml:"bundle"`
 }
 
 // GetDefaultProcess returns the "default" process or the first process from the map,
@@ -0,0 +1,223 @@
+// Package reslimit enforces the CPU, memory and niceness limits a task
+// declares in its xplat: block, so a heavyweight build can't starve the dev
+// services managed by process-compose:
+//
+//	tasks:
+//	  build:
+//	    xplat:
+//	      nice: 10       # 0-19, higher is lower priority
+//	      cpu: 2         # cores, fractions allowed (0.5)
+//	      memory: 4GiB   # KB/MB/GB (1000) or KiB/MiB/GiB (1024)
+//	    cmds:
+//	      - go build ./...
+//
+// go-task ignores unknown task keys, so the block is invisible to it; xplat
+// reads it from the Taskfile source. Limits are applied to the xplat process
+// itself and inherited by every command it starts:
+//
+//   - Linux: xplat re-runs itself under systemd-run in a transient scope
+//     (CPUQuota, MemoryMax); without systemd it creates a cgroup v2 child
+//     group (cpu.max, memory.max) if its cgroup is delegated to it alone,
+//     else the limits are reported as not enforced; nice via setpriority
+//   - Windows: a job object with a CPU rate cap, a job memory limit and a
+//     lower priority class
+//   - macOS: nice via setpriority and RLIMIT_AS (ulimit -v); the
+//     CPU limit is not enforced
+package reslimit
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaxNice is the lowest scheduling priority.
+const MaxNice = 19
+
+// MinCPU is the smallest CPU limit in cores. Linux rejects cgroup quotas
+// under 1ms per 100ms period.
+const MinCPU = 0.01
+
+// Limits are the resource limits of a task. Zero values mean no limit.
+type Limits struct {
+	Nice   int     `yaml:"nice"`
+	CPU    float64 `yaml:"cpu"`    // Cores
+	Memory Bytes   `yaml:"memory"` // Bytes
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l.Nice == 0 && l.CPU == 0 && l.Memory == 0
+}
+
+// Validate checks the limits are in range.
+func (l Limits) Validate() error {
+	if l.Nice < 0 || l.Nice > MaxNice {
+		return fmt.Errorf("nice %d out of range 0-%d", l.Nice, MaxNice)
+	}
+	if l.CPU < 0 {
+		return fmt.Errorf("cpu %g must not be negative", l.CPU)
+	}
+	if l.CPU > 0 && l.CPU < MinCPU {
+		return fmt.Errorf("cpu %g is below the minimum of %g cores", l.CPU, MinCPU)
+	}
+	if l.Memory < 0 {
+		return fmt.Errorf("memory %d must not be negative", l.Memory)
+	}
+	return nil
+}
+
+// Merge returns the stricter of each limit, for runs of several tasks.
+func (l Limits) Merge(o Limits) Limits {
+	if o.Nice > l.Nice {
+		l.Nice = o.Nice
+	}
+	if o.CPU > 0 && (l.CPU == 0 || o.CPU < l.CPU) {
+		l.CPU = o.CPU
+	}
+	if o.Memory > 0 && (l.Memory == 0 || o.Memory < l.Memory) {
+		l.Memory = o.Memory
+	}
+	return l
+}
+
+// String formats the set limits, e.g. "nice=10 cpu=2 memory=4GiB".
+func (l Limits) String() string {
+	var parts []string
+	if l.Nice != 0 {
+		parts = append(parts, fmt.Sprintf("nice=%d", l.Nice))
+	}
+	if l.CPU != 0 {
+		parts = append(parts, "cpu="+strconv.FormatFloat(l.CPU, 'f', -1, 64))
+	}
+	if l.Memory != 0 {
+		parts = append(parts, "memory="+l.Memory.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// Bytes is a memory size, written in YAML as a number of bytes or with a
+// unit suffix: 512MB, 4GiB.
+type Bytes int64
+
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	// Longest suffixes first so "MiB" is not read as "B"
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+	{"k", 1 << 10}, {"m", 1 << 20}, {"g", 1 << 30}, {"t", 1 << 40},
+	{"b", 1},
+}
+
+// ParseBytes parses a memory size such as "512MB", "4GiB" or "1073741824".
+// The single-letter suffixes K, M, G and T are binary, as in docker and ulimit.
+func ParseBytes(s string) (Bytes, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(str, u.suffix) {
+			str, mult = strings.TrimSpace(strings.TrimSuffix(str, u.suffix)), u.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	return Bytes(n * float64(mult)), nil
+}
+
+// UnmarshalYAML accepts a number of bytes or a size string.
+func (b *Bytes) UnmarshalYAML(node *yaml.Node) error {
+	v, err := ParseBytes(node.Value)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// String formats the size with the largest exact binary unit.
+func (b Bytes) String() string {
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if b != 0 && int64(b)%u.size == 0 {
+			return fmt.Sprintf("%d%s", int64(b)/u.size, u.suffix)
+		}
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
+
+// taskMeta is the xplat: block of a task.
+type taskMeta struct {
+	Xplat Limits `yaml:"xplat"`
+}
+
+// FromTaskfile reads the limits of the task whose key is on line of the
+// Taskfile at path (go-task's ast.Task.Location). Tasks without an xplat:
+// block have zero limits.
+func FromTaskfile(path string, line int) (Limits, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Limits{}, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return Limits{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return Limits{}, nil
+	}
+	tasks := mappingValue(doc.Content[0], "tasks")
+	if tasks == nil || tasks.Kind != yaml.MappingNode {
+		return Limits{}, nil
+	}
+	for i := 0; i+1 < len(tasks.Content); i += 2 {
+		key, value := tasks.Content[i], tasks.Content[i+1]
+		if key.Line != line || value.Kind != yaml.MappingNode {
+			continue
+		}
+		var meta taskMeta
+		if err := value.Decode(&meta); err != nil {
+			return Limits{}, fmt.Errorf("%s: task %s: xplat: %w", path, key.Value, err)
+		}
+		if err := meta.Xplat.Validate(); err != nil {
+			return Limits{}, fmt.Errorf("%s: task %s: xplat: %w", path, key.Value, err)
+		}
+		return meta.Xplat, nil
+	}
+	return Limits{}, nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// Apply enforces l on the current process and everything it starts. The
+// returned release undoes what can be undone (e.g. removes the cgroup) and
+// must be called once the tasks are done. skipped lists limits this platform
+// or environment could not enforce.
+func Apply(l Limits) (release func(), skipped []string, err error) {
+	if err := l.Validate(); err != nil {
+		return func() {}, nil, err
+	}
+	if l.IsZero() {
+		return func() {}, nil, nil
+	}
+	return apply(l)
+}
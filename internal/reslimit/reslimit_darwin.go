@@ -0,0 +1,26 @@
+package reslimit
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+func apply(l Limits) (func(), []string, error) {
+	var skipped []string
+	if l.Nice != 0 {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, 0, l.Nice); err != nil {
+			return func() {}, nil, fmt.Errorf("nice: %w", err)
+		}
+	}
+	if l.CPU != 0 {
+		skipped = append(skipped, "cpu (no CPU rate limit on this platform, use nice)")
+	}
+	if l.Memory != 0 {
+		lim := &unix.Rlimit{Cur: uint64(l.Memory), Max: uint64(l.Memory)}
+		if err := unix.Setrlimit(unix.RLIMIT_AS, lim); err != nil {
+			skipped = append(skipped, fmt.Sprintf("memory (rlimit: %v)", err))
+		}
+	}
+	return func() {}, skipped, nil
+}
@@ -0,0 +1,215 @@
+package reslimit
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	cgroupRoot  = "/sys/fs/cgroup"
+	cpuPeriod   = 100000 // cpu.max period in microseconds
+	minCPUQuota = 1000   // Kernel minimum cpu.max quota in microseconds
+)
+
+// scopeEnv marks an xplat re-executed in a systemd scope with the limits.
+const scopeEnv = "XPLAT_RESLIMIT_SCOPE"
+
+func apply(l Limits) (func(), []string, error) {
+	release := func() {}
+
+	if l.Nice != 0 {
+		if err := setNice(l.Nice); err != nil {
+			return release, nil, fmt.Errorf("nice: %w", err)
+		}
+	}
+
+	if l.CPU == 0 && l.Memory == 0 {
+		return release, nil, nil
+	}
+	if os.Getenv(scopeEnv) == l.String() {
+		return release, nil, nil // Already running in the scope
+	}
+
+	// systemd owns the session's cgroups, so ask it for a scope with the
+	// limits and re-run xplat in it; this only returns if that fails
+	scopeErr := execInScope(l)
+
+	// Without systemd (containers), create the group directly, which needs
+	// xplat to be alone in a delegated cgroup
+	rel, err := joinCgroup(l)
+	if err == nil {
+		return rel, nil, nil
+	}
+
+	var skipped []string
+	reason := fmt.Sprintf("systemd-run: %v; cgroup: %v", scopeErr, err)
+	if l.CPU != 0 {
+		skipped = append(skipped, fmt.Sprintf("cpu (%s)", reason))
+	}
+	if l.Memory != 0 {
+		skipped = append(skipped, fmt.Sprintf("memory (%s)", reason))
+	}
+	return release, skipped, nil
+}
+
+// execInScope replaces xplat with itself running under systemd-run in a
+// transient scope with CPUQuota and MemoryMax set. The scope keeps the
+// terminal, environment and working directory, and ends with the run.
+func execInScope(l Limits) error {
+	systemdRun, err := exec.LookPath("systemd-run")
+	if err != nil {
+		return err
+	}
+	user := os.Geteuid() != 0
+	if user {
+		if _, err := os.Stat(filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "bus")); err != nil {
+			return fmt.Errorf("no user session bus")
+		}
+	} else if _, err := os.Stat("/run/systemd/system"); err != nil {
+		return fmt.Errorf("systemd is not running")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append([]string{systemdRun}, scopeArgs(l, user)...)
+	args = append(append(args, "--", self), os.Args[1:]...)
+	env := append(os.Environ(), scopeEnv+"="+l.String())
+	return unix.Exec(systemdRun, args, env)
+}
+
+// scopeArgs returns the systemd-run flags for a scope with the limits.
+func scopeArgs(l Limits, user bool) []string {
+	args := []string{"--scope", "--quiet", "--collect"}
+	if user {
+		args = append(args, "--user")
+	}
+	if l.CPU != 0 {
+		percent := int(math.Round(l.CPU * 100))
+		if percent < 1 {
+			percent = 1
+		}
+		args = append(args, "-p", fmt.Sprintf("CPUQuota=%d%%", percent))
+	}
+	if l.Memory != 0 {
+		args = append(args, "-p", fmt.Sprintf("MemoryMax=%d", int64(l.Memory)))
+	}
+	return args
+}
+
+// setNice lowers the priority of every thread: Linux niceness is per
+// thread, and children inherit it from whichever thread forks them.
+func setNice(nice int) error {
+	tids, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return unix.Setpriority(unix.PRIO_PROCESS, 0, nice)
+	}
+	for _, t := range tids {
+		tid, err := strconv.Atoi(t.Name())
+		if err != nil {
+			continue
+		}
+		if err := unix.Setpriority(unix.PRIO_PROCESS, tid, nice); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinCgroup moves the process into a new cgroup v2 group below its current
+// one with cpu.max and memory.max set. A cgroup that has controllers enabled
+// for its children can't hold processes itself, so xplat first moves into the
+// new group, then enables the controllers on its old group; that fails if
+// other processes (a shell) share it.
+func joinCgroup(l Limits) (func(), error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return nil, err
+	}
+	var current string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if rest, ok := strings.CutPrefix(line, "0::"); ok {
+			current = filepath.Join(cgroupRoot, rest)
+		}
+	}
+	if current == "" {
+		return nil, fmt.Errorf("cgroup v2 not mounted")
+	}
+
+	var controllers []string
+	if l.CPU != 0 {
+		controllers = append(controllers, "cpu")
+	}
+	if l.Memory != 0 {
+		controllers = append(controllers, "memory")
+	}
+	enabled, err := os.ReadFile(filepath.Join(current, "cgroup.subtree_control"))
+	if err != nil {
+		return nil, err
+	}
+	var enable []string
+	for _, c := range controllers {
+		if !slices.Contains(strings.Fields(string(enabled)), c) {
+			enable = append(enable, c)
+		}
+	}
+
+	dir := filepath.Join(current, fmt.Sprintf("xplat-task-%d", os.Getpid()))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		return nil, err
+	}
+	pid := []byte(strconv.Itoa(os.Getpid()))
+	undo := func() {
+		// A group can only be removed once empty, and processes can only go
+		// back once the controllers are off again
+		if len(enable) > 0 {
+			_ = os.WriteFile(filepath.Join(current, "cgroup.subtree_control"), []byte(controlList("-", enable)), 0o644)
+		}
+		_ = os.WriteFile(filepath.Join(current, "cgroup.procs"), pid, 0o644)
+		_ = os.Remove(dir)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "cgroup.procs"), pid, 0o644); err != nil {
+		undo()
+		return nil, err
+	}
+	if len(enable) > 0 {
+		if err := os.WriteFile(filepath.Join(current, "cgroup.subtree_control"), []byte(controlList("+", enable)), 0o644); err != nil {
+			enable = nil
+			undo()
+			return nil, fmt.Errorf("enable %s on %s: %w", strings.Join(controllers, ", "), current, err)
+		}
+	}
+
+	if l.CPU != 0 {
+		quota := max(int(l.CPU*cpuPeriod), minCPUQuota)
+		if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(fmt.Sprintf("%d %d", quota, cpuPeriod)), 0o644); err != nil {
+			undo()
+			return nil, err
+		}
+	}
+	if l.Memory != 0 {
+		if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.FormatInt(int64(l.Memory), 10)), 0o644); err != nil {
+			undo()
+			return nil, err
+		}
+	}
+	return undo, nil
+}
+
+// controlList formats a cgroup.subtree_control write, e.g. "+cpu +memory".
+func controlList(op string, controllers []string) string {
+	parts := make([]string, len(controllers))
+	for i, c := range controllers {
+		parts[i] = op + c
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,32 @@
+package reslimit
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScopeArgs(t *testing.T) {
+	tests := []struct {
+		l    Limits
+		user bool
+		want []string
+	}{
+		{Limits{CPU: 2, Memory: 4 << 30}, true, []string{"--scope", "--quiet", "--collect", "--user", "-p", "CPUQuota=200%", "-p", "MemoryMax=4294967296"}},
+		{Limits{CPU: 0.5}, false, []string{"--scope", "--quiet", "--collect", "-p", "CPUQuota=50%"}},
+		{Limits{CPU: 0.001, Nice: 5}, true, []string{"--scope", "--quiet", "--collect", "--user", "-p", "CPUQuota=1%"}},
+	}
+	for _, tt := range tests {
+		if got := scopeArgs(tt.l, tt.user); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("scopeArgs(%s) = %v, want %v", tt.l, got, tt.want)
+		}
+	}
+}
+
+func TestControlList(t *testing.T) {
+	if got := controlList("+", []string{"cpu", "memory"}); got != "+cpu +memory" {
+		t.Errorf("controlList(+) = %q", got)
+	}
+	if got := controlList("-", []string{"memory"}); got != "-memory" {
+		t.Errorf("controlList(-) = %q", got)
+	}
+}
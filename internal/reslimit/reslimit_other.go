@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !windows
+
+package reslimit
+
+func apply(l Limits) (func(), []string, error) {
+	return func() {}, []string{l.String() + " (not supported on this platform)"}, nil
+}
@@ -0,0 +1,92 @@
+package reslimit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Bytes
+	}{
+		{"1073741824", 1 << 30},
+		{"4GiB", 4 << 30},
+		{"512MB", 512e6},
+		{"512M", 512 << 20},
+		{"1.5 GiB", 3 << 29},
+		{"100b", 100},
+	}
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "lots", "-1GB"} {
+		if _, err := ParseBytes(in); err == nil {
+			t.Errorf("ParseBytes(%q) should fail", in)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := Limits{Nice: 5, CPU: 4}
+	b := Limits{Nice: 10, CPU: 2, Memory: 1 << 30}
+	got := a.Merge(b).Merge(Limits{})
+	want := Limits{Nice: 10, CPU: 2, Memory: 1 << 30}
+	if got != want {
+		t.Errorf("Merge() = %+v, want %+v", got, want)
+	}
+	if s := got.String(); s != "nice=10 cpu=2 memory=1GiB" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestFromTaskfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Taskfile.yml")
+	src := `version: '3'
+
+tasks:
+  build:
+    xplat:
+      nice: 10
+      cpu: 0.5
+      memory: 2GiB
+    cmds:
+      - go build ./...
+
+  test: go test ./...
+
+  bad:
+    xplat:
+      nice: 40
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FromTaskfile(path, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Limits{Nice: 10, CPU: 0.5, Memory: 2 << 30}); got != want {
+		t.Errorf("build = %+v, want %+v", got, want)
+	}
+
+	if got, err := FromTaskfile(path, 12); err != nil || !got.IsZero() {
+		t.Errorf("test = %+v, %v; want no limits", got, err)
+	}
+	if _, err := FromTaskfile(path, 14); err == nil {
+		t.Error("nice out of range should fail")
+	}
+}
+
+func TestValidateCPU(t *testing.T) {
+	for cpu, ok := range map[float64]bool{0: true, 0.01: true, 0.5: true, 0.005: false, -1: false} {
+		if err := (Limits{CPU: cpu}).Validate(); (err == nil) != ok {
+			t.Errorf("Validate(cpu=%g) = %v, want ok=%v", cpu, err, ok)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package reslimit
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// JOBOBJECT_CPU_RATE_CONTROL_INFORMATION, not in x/sys/windows.
+type cpuRateControl struct {
+	ControlFlags uint32
+	CpuRate      uint32 // Percentage of all CPUs times 100
+}
+
+const (
+	cpuRateControlEnable  = 0x1
+	cpuRateControlHardCap = 0x4
+)
+
+func apply(l Limits) (func(), []string, error) {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return func() {}, nil, fmt.Errorf("job object: %w", err)
+	}
+	release := func() { _ = windows.CloseHandle(job) }
+
+	var info windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+	if l.Nice != 0 {
+		info.BasicLimitInformation.LimitFlags |= windows.JOB_OBJECT_LIMIT_PRIORITY_CLASS
+		info.BasicLimitInformation.PriorityClass = priorityClass(l.Nice)
+	}
+	if l.Memory != 0 {
+		info.BasicLimitInformation.LimitFlags |= windows.JOB_OBJECT_LIMIT_JOB_MEMORY
+		info.JobMemoryLimit = uintptr(l.Memory)
+	}
+	if info.BasicLimitInformation.LimitFlags != 0 {
+		if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+			uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+			release()
+			return func() {}, nil, fmt.Errorf("job limits: %w", err)
+		}
+	}
+
+	var skipped []string
+	if l.CPU != 0 {
+		rate := cpuRateControl{
+			ControlFlags: cpuRateControlEnable | cpuRateControlHardCap,
+			CpuRate:      uint32(min(l.CPU/float64(runtime.NumCPU()), 1) * 10000),
+		}
+		if _, err := windows.SetInformationJobObject(job, windows.JobObjectCpuRateControlInformation,
+			uintptr(unsafe.Pointer(&rate)), uint32(unsafe.Sizeof(rate))); err != nil {
+			skipped = append(skipped, fmt.Sprintf("cpu (job object: %v)", err))
+		}
+	}
+
+	if err := windows.AssignProcessToJobObject(job, windows.CurrentProcess()); err != nil {
+		release()
+		return func() {}, nil, fmt.Errorf("job object: %w", err)
+	}
+	// Closing the handle keeps the job alive while xplat is assigned to it
+	return release, skipped, nil
+}
+
+// priorityClass maps Unix niceness onto a Windows priority class.
+func priorityClass(nice int) uint32 {
+	if nice >= 10 {
+		return windows.IDLE_PRIORITY_CLASS
+	}
+	return windows.BELOW_NORMAL_PRIORITY_CLASS
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/notify"
 	"github.com/joeblew999/xplat/internal/projects"
 	"github.com/joeblew999/xplat/internal/updater"
 	"github.com/kardianos/service"
@@ -23,20 +24,22 @@ const (
 
 // Config holds service configuration.
 type Config struct {
-	Name        string // Service name (e.g., "xplat" or "xplat-myproject")
-	DisplayName string // Human-readable name
-	Description string // Service description
-	WorkDir     string // Working directory for the service
-	UserService bool   // Install as user service (not root)
-	AutoUpdate  bool   // Enable automatic updates (default: true)
-	Version     string // Current version (injected at build time)
-	WithUI      bool   // Start Task UI alongside process-compose
-	UIPort      string // Port for Task UI (default: "3000")
-	WithMCP     bool   // Start MCP HTTP server alongside process-compose
-	MCPPort     string // Port for MCP server (default: "8765")
-	WithSync    bool   // Start GitHub sync poller for Task cache invalidation
-	SyncRepos   string // Comma-separated list of repos to poll (e.g., "owner/repo,owner2/repo2")
-	SyncInterval string // Poll interval (e.g., "5m", "1h")
+	Name         string                  // Service name (e.g., "xplat" or "xplat-myproject")
+	DisplayName  string                  // Human-readable name
+	Description  string                  // Service description
+	WorkDir      string                  // Working directory for the service
+	UserService  bool                    // Install as user service (not root)
+	AutoUpdate   bool                    // Enable automatic updates (default: true)
+	Version      string                  // Current version (injected at build time)
+	WithUI       bool                    // Start Task UI alongside process-compose
+	UIPort       string                  // Port for Task UI (default: "3000")
+	WithMCP      bool                    // Start MCP HTTP server alongside process-compose
+	MCPPort      string                  // Port for MCP server (default: "8765")
+	WithSync     bool                    // Start GitHub sync poller for Task cache invalidation
+	SyncRepos    string                  // Comma-separated list of repos to poll (e.g., "owner/repo,owner2/repo2")
+	SyncInterval string                  // Poll interval (e.g., "5m", "1h")
+	Watchdog     bool                    // Restart exited processes with backoff and report flapping
+	Services     []config.WatchedService // Extra xplat commands to keep running (watchdog only)
 }
 
 // DefaultConfig returns the default service configuration.
@@ -67,27 +70,39 @@ func ConfigForProject(projectDir string) Config {
 
 // program implements the service.Interface.
 type program struct {
-	cmd        *exec.Cmd
-	uiCmd      *exec.Cmd  // Task UI process (if WithUI is enabled)
-	mcpCmd     *exec.Cmd  // MCP HTTP server process (if WithMCP is enabled)
-	syncCmd    *exec.Cmd  // GitHub sync poller (if WithSync is enabled)
-	workDir    string
-	xplatBin   string
-	autoUpdate bool
-	version    string
-	withUI     bool
-	uiPort     string
-	withMCP    bool
-	mcpPort    string
-	withSync   bool
-	syncRepos  string
+	cmd          *exec.Cmd
+	uiCmd        *exec.Cmd // Task UI process (if WithUI is enabled)
+	mcpCmd       *exec.Cmd // MCP HTTP server process (if WithMCP is enabled)
+	syncCmd      *exec.Cmd // GitHub sync poller (if WithSync is enabled)
+	workDir      string
+	xplatBin     string
+	autoUpdate   bool
+	version      string
+	withUI       bool
+	uiPort       string
+	withMCP      bool
+	mcpPort      string
+	withSync     bool
+	syncRepos    string
 	syncInterval string
-	stopChan   chan struct{}
+	watchdog     bool
+	services     []config.WatchedService
+	stopWatchdog context.CancelFunc
+	stopChan     chan struct{}
 }
 
 func (p *program) Start(s service.Service) error {
 	log.Printf("Starting %s service...", s.String())
 	p.stopChan = make(chan struct{})
+	if p.autoUpdate {
+		go p.updateLoop()
+	}
+	if p.watchdog {
+		ctx, cancel := context.WithCancel(context.Background())
+		p.stopWatchdog = cancel
+		go p.newWatchdog(DefaultWatchdogConfig()).Run(ctx, p.children())
+		return nil
+	}
 	go p.run()
 	if p.withUI {
 		go p.runUI()
@@ -98,17 +113,84 @@ func (p *program) Start(s service.Service) error {
 	if p.withSync {
 		go p.runSync()
 	}
-	if p.autoUpdate {
-		go p.updateLoop()
-	}
 	return nil
 }
 
-func (p *program) runUI() {
+// newWatchdog creates a watchdog for the service's processes, reporting
+// through the notification router.
+func (p *program) newWatchdog(cfg WatchdogConfig) *Watchdog {
+	w := NewWatchdog(p.xplatBin, p.workDir)
+	w.Config = cfg
+	if router, err := notify.Default(); err != nil {
+		log.Printf("Warning: watchdog notifications disabled: %v", err)
+	} else {
+		w.Router = router
+	}
+	return w
+}
+
+// children returns the processes the watchdog keeps running: the same as
+// without it, plus the services from service.yaml.
+func (p *program) children() []Child {
+	children := []Child{{Name: "process", Args: p.processArgs()}}
+	if p.withUI {
+		children = append(children, Child{Name: "ui", Args: p.uiArgs()})
+	}
+	if p.withMCP {
+		children = append(children, Child{Name: "mcp", Args: p.mcpArgs()})
+	}
+	if p.withSync {
+		children = append(children, Child{Name: "sync", Args: p.syncArgs()})
+	}
+	for _, s := range p.services {
+		children = append(children, Child{Name: s.Name, Args: s.Args})
+	}
+	return children
+}
+
+func (p *program) uiArgs() []string {
 	// Use unified 'up' command with --no-browser for service mode
-	args := []string{"up", "--no-browser", "-p", p.uiPort}
+	return []string{"up", "--no-browser", "-p", p.uiPort}
+}
+
+func (p *program) mcpArgs() []string {
+	return []string{"mcp", "serve", "--http", ":" + p.mcpPort}
+}
+
+func (p *program) syncArgs() []string {
+	// If repos not specified, poll command will auto-discover from Taskfile.yml
+	args := []string{"sync-gh", "poll", "--interval=" + p.syncInterval, "--invalidate"}
+	if p.syncRepos != "" {
+		args = append(args, "--repos="+p.syncRepos)
+	}
+	return args
+}
+
+// processArgs runs process-compose headless with the configs of all
+// enabled projects in the registry.
+func (p *program) processArgs() []string {
+	args := []string{"process"}
+
+	// Load all enabled project configs from registry
+	reg, err := projects.Load()
+	if err != nil {
+		log.Printf("Warning: failed to load project registry: %v", err)
+	} else {
+		configFiles := reg.EnabledConfigFiles()
+		if len(configFiles) > 0 {
+			log.Printf("Loading %d project config(s) from registry", len(configFiles))
+			for _, cfg := range configFiles {
+				args = append(args, "-f", cfg)
+			}
+		}
+	}
+
+	// Add headless mode flags (no TUI, no server, suitable for service)
+	return append(args, "-t=false", "--no-server")
+}
 
-	p.uiCmd = exec.Command(p.xplatBin, args...)
+func (p *program) runUI() {
+	p.uiCmd = exec.Command(p.xplatBin, p.uiArgs()...)
 	p.uiCmd.Dir = p.workDir
 	p.uiCmd.Stdout = os.Stdout
 	p.uiCmd.Stderr = os.Stderr
@@ -121,10 +203,7 @@ func (p *program) runUI() {
 }
 
 func (p *program) runMCP() {
-	// Build MCP command args for HTTP mode
-	args := []string{"mcp", "serve", "--http", ":" + p.mcpPort}
-
-	p.mcpCmd = exec.Command(p.xplatBin, args...)
+	p.mcpCmd = exec.Command(p.xplatBin, p.mcpArgs()...)
 	p.mcpCmd.Dir = p.workDir
 	p.mcpCmd.Stdout = os.Stdout
 	p.mcpCmd.Stderr = os.Stderr
@@ -137,14 +216,7 @@ func (p *program) runMCP() {
 }
 
 func (p *program) runSync() {
-	// Build sync-gh poll command args
-	// If repos not specified, poll command will auto-discover from Taskfile.yml
-	args := []string{"sync-gh", "poll", "--interval=" + p.syncInterval, "--invalidate"}
-	if p.syncRepos != "" {
-		args = append(args, "--repos="+p.syncRepos)
-	}
-
-	p.syncCmd = exec.Command(p.xplatBin, args...)
+	p.syncCmd = exec.Command(p.xplatBin, p.syncArgs()...)
 	p.syncCmd.Dir = p.workDir
 	p.syncCmd.Stdout = os.Stdout
 	p.syncCmd.Stderr = os.Stderr
@@ -161,25 +233,7 @@ func (p *program) runSync() {
 }
 
 func (p *program) run() {
-	// Build command args: start with "process"
-	args := []string{"process"}
-
-	// Load all enabled project configs from registry
-	reg, err := projects.Load()
-	if err != nil {
-		log.Printf("Warning: failed to load project registry: %v", err)
-	} else {
-		configFiles := reg.EnabledConfigFiles()
-		if len(configFiles) > 0 {
-			log.Printf("Loading %d project config(s) from registry", len(configFiles))
-			for _, cfg := range configFiles {
-				args = append(args, "-f", cfg)
-			}
-		}
-	}
-
-	// Add headless mode flags (no TUI, no server, suitable for service)
-	args = append(args, "-t=false", "--no-server")
+	args := p.processArgs()
 
 	// Run process-compose with all configs
 	p.cmd = exec.Command(p.xplatBin, args...)
@@ -273,6 +327,9 @@ func (p *program) Stop(s service.Service) error {
 	if p.stopChan != nil {
 		close(p.stopChan)
 	}
+	if p.stopWatchdog != nil {
+		p.stopWatchdog() // Interrupts the children
+	}
 	if p.cmd != nil && p.cmd.Process != nil {
 		// Send SIGTERM/SIGINT to gracefully stop
 		_ = p.cmd.Process.Signal(os.Interrupt)
@@ -292,6 +349,7 @@ func (p *program) Stop(s service.Service) error {
 // Manager manages service lifecycle operations.
 type Manager struct {
 	svc    service.Service
+	prg    *program
 	config Config
 }
 
@@ -347,6 +405,8 @@ func NewManager(cfg Config) (*Manager, error) {
 		withSync:     cfg.WithSync,
 		syncRepos:    cfg.SyncRepos,
 		syncInterval: syncInterval,
+		watchdog:     cfg.Watchdog,
+		services:     cfg.Services,
 	}
 
 	svc, err := service.New(prg, svcConfig)
@@ -356,6 +416,7 @@ func NewManager(cfg Config) (*Manager, error) {
 
 	return &Manager{
 		svc:    svc,
+		prg:    prg,
 		config: cfg,
 	}, nil
 }
@@ -443,6 +504,18 @@ func (m *Manager) Run() error {
 	return m.svc.Run()
 }
 
+// RunWatchdog runs the service's processes under a watchdog in the
+// foreground until ctx is done, without the OS service manager. It refuses
+// to run while the OS service is running, which would start them twice.
+func (m *Manager) RunWatchdog(ctx context.Context, cfg WatchdogConfig) error {
+	if status, _ := m.svc.Status(); status == service.StatusRunning {
+		return fmt.Errorf("service %s is running; stop it first, or set watchdog: true in %s to run it under the watchdog",
+			m.config.Name, config.XplatServiceConfig())
+	}
+	m.prg.newWatchdog(cfg).Run(ctx, m.prg.children())
+	return nil
+}
+
 // Platform returns the service platform (e.g., "darwin-launchd", "linux-systemd").
 func (m *Manager) Platform() string {
 	return service.Platform()
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/notify"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// Child is an xplat command the watchdog keeps running.
+type Child struct {
+	Name string   // e.g. "sync", "ui" or a service from service.yaml
+	Args []string // xplat arguments, e.g. ["sync-gh", "poll"]
+}
+
+// WatchdogConfig tunes restarts and flap detection.
+type WatchdogConfig struct {
+	MinBackoff    time.Duration // First restart delay (default 1s)
+	MaxBackoff    time.Duration // Restart delay cap; doubles per quick exit (default 5m)
+	StableAfter   time.Duration // A run this long resets the backoff and flap count (default 1m)
+	FlapThreshold int           // Exits in a row, each before StableAfter, reported as a flap (default 5)
+	GiveUpAfter   int           // Stop restarting after this many flap reports (default 3, -1 never)
+}
+
+// DefaultWatchdogConfig returns the default restart and flap settings.
+func DefaultWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		MinBackoff:    time.Second,
+		MaxBackoff:    5 * time.Minute,
+		StableAfter:   time.Minute,
+		FlapThreshold: 5,
+		GiveUpAfter:   3,
+	}
+}
+
+// Child states.
+const (
+	ChildRunning = "running"
+	ChildBackoff = "backoff" // Waiting to restart
+	ChildGaveUp  = "gave-up" // Flapped GiveUpAfter times; not restarted
+	ChildStopped = "stopped"
+)
+
+// ChildState is the watchdog's view of a child, saved to WatchdogStatePath.
+type ChildState struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Restarts  int       `json:"restarts"`
+	Flaps     int       `json:"flaps"`
+	LastExit  string    `json:"last_exit,omitempty"`
+	StartedAt time.Time `json:"started_at,omitempty"`
+}
+
+// WatchdogStatePath is where the watchdog saves its child states.
+func WatchdogStatePath() string {
+	return filepath.Join(config.XplatHome(), "watchdog.json")
+}
+
+// LoadWatchdogState reads the child states the watchdog last saved. A
+// missing file has no states.
+func LoadWatchdogState(path string) ([]ChildState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var states []ChildState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("invalid watchdog state %s: %w", path, err)
+	}
+	return states, nil
+}
+
+// Watchdog runs children, restarting them with backoff when they exit.
+// A child that keeps exiting is reported through the notification router
+// as flapping, and after GiveUpAfter reports it is left stopped rather than
+// respawned forever.
+type Watchdog struct {
+	Bin       string   // xplat binary
+	Dir       string   // Working directory
+	Env       []string // Environment (nil inherits)
+	Config    WatchdogConfig
+	Router    *notify.Router // Flap reports (optional)
+	StatePath string         // Where child states are saved (optional)
+
+	// RunChild runs a child until it exits (tests replace it)
+	RunChild func(ctx context.Context, c Child) error
+
+	mu     sync.Mutex
+	states map[string]*ChildState
+}
+
+// NewWatchdog creates a watchdog running xplatBin in workDir.
+func NewWatchdog(xplatBin, workDir string) *Watchdog {
+	return &Watchdog{
+		Bin:       xplatBin,
+		Dir:       workDir,
+		Env:       config.FullEnv(workDir),
+		Config:    DefaultWatchdogConfig(),
+		StatePath: WatchdogStatePath(),
+	}
+}
+
+// Run runs the children until ctx is done, then stops them.
+func (w *Watchdog) Run(ctx context.Context, children []Child) {
+	w.mu.Lock()
+	w.states = make(map[string]*ChildState, len(children))
+	for _, c := range children {
+		w.states[c.Name] = &ChildState{Name: c.Name, State: ChildStopped}
+	}
+	w.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range children {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.supervise(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	for _, c := range children {
+		w.update(c.Name, func(s *ChildState) {
+			if s.State != ChildGaveUp {
+				s.State = ChildStopped
+			}
+		})
+	}
+}
+
+// States returns the current child states, by name.
+func (w *Watchdog) States() []ChildState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	states := make([]ChildState, 0, len(w.states))
+	for _, s := range w.states {
+		states = append(states, *s)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// supervise keeps one child running until ctx is done or it flaps too often.
+func (w *Watchdog) supervise(ctx context.Context, c Child) {
+	cfg := w.Config
+	backoff := cfg.MinBackoff
+	quickExits := 0 // In a row, each before StableAfter
+
+	for {
+		started := time.Now()
+		w.update(c.Name, func(s *ChildState) {
+			s.State, s.StartedAt = ChildRunning, started
+		})
+		log.Printf("watchdog: starting %s: xplat %s", c.Name, strings.Join(c.Args, " "))
+		err := w.runChild(ctx, c)
+		if ctx.Err() != nil {
+			return
+		}
+
+		exit := "exited"
+		if err != nil {
+			exit = err.Error()
+		}
+		if time.Since(started) >= cfg.StableAfter {
+			backoff, quickExits = cfg.MinBackoff, 0
+		} else {
+			quickExits++
+		}
+		flapped := quickExits >= cfg.FlapThreshold
+
+		var flaps int
+		w.update(c.Name, func(s *ChildState) {
+			s.Restarts++
+			s.LastExit = exit
+			s.State = ChildBackoff
+			if flapped {
+				s.Flaps++
+			}
+			flaps = s.Flaps
+		})
+
+		if flapped {
+			quickExits = 0
+			gaveUp := cfg.GiveUpAfter >= 0 && flaps >= cfg.GiveUpAfter
+			w.reportFlap(ctx, c, flaps, exit, gaveUp)
+			if gaveUp {
+				w.update(c.Name, func(s *ChildState) { s.State = ChildGaveUp })
+				log.Printf("watchdog: %s flapped %d times, not restarting it", c.Name, flaps)
+				return
+			}
+		}
+
+		log.Printf("watchdog: %s %s, restarting in %s", c.Name, exit, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, cfg.MaxBackoff)
+	}
+}
+
+// reportFlap sends a flapping child to the notification router: a warning,
+// or critical when the watchdog gives up on it.
+func (w *Watchdog) reportFlap(ctx context.Context, c Child, flaps int, exit string, gaveUp bool) {
+	msg := &notify.Message{
+		Source:   "service/" + c.Name,
+		Severity: notify.SeverityWarning,
+		Title:    fmt.Sprintf("xplat service %s is flapping", c.Name),
+		Text: fmt.Sprintf("Exited %d times in a row within %s of starting, last: %s",
+			w.Config.FlapThreshold, w.Config.StableAfter, exit),
+		Fields: map[string]string{
+			"command": "xplat " + strings.Join(c.Args, " "),
+			"flaps":   fmt.Sprint(flaps),
+		},
+	}
+	if gaveUp {
+		msg.Severity = notify.SeverityCritical
+		msg.Title = fmt.Sprintf("xplat service %s keeps failing, watchdog gave up", c.Name)
+		msg.Text += "\nIt will not be restarted until the service is restarted."
+	}
+	log.Printf("watchdog: %s", msg.Title)
+	if err := w.Router.Notify(ctx, msg); err != nil {
+		log.Printf("watchdog: failed to send notification: %v", err)
+	}
+}
+
+// runChild runs c with RunChild, or as an xplat subprocess that is
+// interrupted when ctx is done.
+func (w *Watchdog) runChild(ctx context.Context, c Child) error {
+	if w.RunChild != nil {
+		return w.RunChild(ctx, c)
+	}
+	cmd := exec.CommandContext(ctx, w.Bin, c.Args...)
+	cmd.Dir = w.Dir
+	cmd.Env = w.Env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 10 * time.Second // Then killed (no interrupt on Windows)
+	return cmd.Run()
+}
+
+// update changes the state of a child and saves all states.
+func (w *Watchdog) update(name string, fn func(*ChildState)) {
+	w.mu.Lock()
+	if s, ok := w.states[name]; ok {
+		fn(s)
+	}
+	w.mu.Unlock()
+	w.save()
+}
+
+// save writes the child states to StatePath. Failures are logged only.
+func (w *Watchdog) save() {
+	if w.StatePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(w.States(), "", "  ")
+	if err == nil {
+		err = statestore.WriteFile(w.StatePath, data, config.DefaultFilePerms)
+	}
+	if err != nil {
+		log.Printf("watchdog: failed to save state: %v", err)
+	}
+}
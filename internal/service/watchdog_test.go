@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+func TestWatchdogFlapsAndGivesUp(t *testing.T) {
+	var mu sync.Mutex
+	var messages []notify.Message
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m notify.Message
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		mu.Lock()
+		messages = append(messages, m)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	statePath := filepath.Join(t.TempDir(), "watchdog.json")
+	w := &Watchdog{
+		Config: WatchdogConfig{
+			MinBackoff:    time.Millisecond,
+			MaxBackoff:    2 * time.Millisecond,
+			StableAfter:   time.Hour,
+			FlapThreshold: 3,
+			GiveUpAfter:   2,
+		},
+		Router:    &notify.Router{Routes: []notify.Route{{Webhook: hook.URL}}},
+		StatePath: statePath,
+		RunChild: func(ctx context.Context, c Child) error {
+			if c.Name == "steady" {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			return errors.New("exit status 1")
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx, []Child{{Name: "receiver", Args: []string{"sync-cf", "receive"}}, {Name: "steady"}})
+		close(done)
+	}()
+
+	// The failing child gives up after two flaps of three exits each
+	deadline := time.After(5 * time.Second)
+	for {
+		states := w.States()
+		if len(states) == 2 && states[0].State == ChildGaveUp {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("watchdog did not give up: %+v", states)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	states, err := LoadWatchdogState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, steady := states[0], states[1]
+	if receiver.Restarts != 6 || receiver.Flaps != 2 || receiver.State != ChildGaveUp || receiver.LastExit != "exit status 1" {
+		t.Errorf("receiver state = %+v, want 6 restarts, 2 flaps, gave up", receiver)
+	}
+	if steady.Restarts != 0 || steady.State != ChildStopped {
+		t.Errorf("steady state = %+v, want no restarts, stopped", steady)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(messages) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(messages))
+	}
+	if m := messages[0]; m.Source != "service/receiver" || m.Severity != notify.SeverityWarning {
+		t.Errorf("first notification = %s %s, want service/receiver warning", m.Source, m.Severity)
+	}
+	if m := messages[1]; m.Severity != notify.SeverityCritical || m.Fields["command"] != "xplat sync-cf receive" {
+		t.Errorf("second notification = %s %v, want critical for xplat sync-cf receive", m.Severity, m.Fields)
+	}
+}
+
+func TestWatchdogStableRunResetsFlaps(t *testing.T) {
+	runs := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &Watchdog{
+		Config: WatchdogConfig{
+			MinBackoff:    time.Millisecond,
+			MaxBackoff:    time.Millisecond,
+			StableAfter:   20 * time.Millisecond,
+			FlapThreshold: 2,
+			GiveUpAfter:   1,
+		},
+		RunChild: func(ctx context.Context, c Child) error {
+			runs++
+			if runs == 6 {
+				cancel()
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			if runs%2 == 0 {
+				time.Sleep(30 * time.Millisecond) // Stable run between quick exits
+			}
+			return nil
+		},
+	}
+	w.Run(ctx, []Child{{Name: "poller"}})
+
+	if s := w.States()[0]; s.Flaps != 0 || s.Restarts != 5 {
+		t.Errorf("state = %+v, want 5 restarts and no flaps", s)
+	}
+}
@@ -0,0 +1,123 @@
+package sitecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AgentProvider runs checks on self-hosted agents ('xplat site agent').
+// Each agent runs a DirectProvider check and returns its results.
+type AgentProvider struct {
+	// Agents are agent base URLs (e.g. "https://probe-eu.example.com")
+	Agents []string
+
+	// Timeout per agent request (default: 20s)
+	Timeout time.Duration
+}
+
+// Name implements Provider.
+func (p *AgentProvider) Name() string { return "agent" }
+
+// Check implements Provider.
+// An unreachable agent is reported as a failed result rather than an error,
+// so one bad agent doesn't hide the others.
+func (p *AgentProvider) Check(ctx context.Context, checkType CheckType, target string) ([]Result, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 20 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	var results []Result
+	for _, agent := range p.Agents {
+		agentResults, err := checkAgent(ctx, client, agent, checkType, target)
+		if err != nil {
+			results = append(results, Result{Location: agent, Error: err.Error()})
+			continue
+		}
+		results = append(results, agentResults...)
+	}
+	return results, nil
+}
+
+func checkAgent(ctx context.Context, client *http.Client, agent string, checkType CheckType, target string) ([]Result, error) {
+	q := url.Values{"type": {string(checkType)}, "target": {target}}
+	endpoint := strings.TrimSuffix(agent, "/") + "/check?" + q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("agent unreachable: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("agent returned %d", resp.StatusCode)
+	}
+
+	var results []Result
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("failed to decode agent response: %w", err)
+	}
+	return results, nil
+}
+
+// AgentHandler serves GET /check?type=<type>&target=<target> using provider
+// (usually a DirectProvider), for use with AgentProvider on other machines.
+func AgentHandler(provider Provider) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/check", func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("target")
+		if target == "" {
+			http.Error(w, "target is required", http.StatusBadRequest)
+			return
+		}
+		checkType := CheckType(r.URL.Query().Get("type"))
+		if checkType == "" {
+			checkType = CheckHTTP
+		}
+
+		results, err := provider.Check(r.Context(), checkType, target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(results)
+	})
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// RunAgent serves AgentHandler with a DirectProvider on addr until ctx is done.
+func RunAgent(ctx context.Context, addr string, location string) error {
+	server := &http.Server{
+		Addr:    addr,
+		Handler: AgentHandler(&DirectProvider{Location: location}),
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Site check agent listening on %s", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("agent server failed: %w", err)
+	}
+	return nil
+}
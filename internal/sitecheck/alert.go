@@ -0,0 +1,221 @@
+package sitecheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+// maxLatencyDeltas caps the latency deltas included in an alert.
+const maxLatencyDeltas = 5
+
+// Alert is the compact payload posted to a webhook when checks fail.
+type Alert struct {
+	Target   string         `json:"target"`
+	Time     time.Time      `json:"time"`
+	Failed   int            `json:"failed"`
+	Total    int            `json:"total"`
+	Failures []AlertFailure `json:"failures"`
+
+	// LatencyDeltas are the checks slowest relative to their history p50
+	LatencyDeltas []LatencyDelta `json:"latency_deltas,omitempty"`
+}
+
+// AlertFailure is one failed check in an Alert.
+type AlertFailure struct {
+	Provider string    `json:"provider"`
+	Location string    `json:"location,omitempty"`
+	Type     CheckType `json:"type"`
+	Error    string    `json:"error,omitempty"`
+	Detail   string    `json:"detail,omitempty"`
+}
+
+// LatencyDelta compares a check's latency with its history p50.
+type LatencyDelta struct {
+	Provider string        `json:"provider"`
+	Location string        `json:"location,omitempty"`
+	Type     CheckType     `json:"type"`
+	Latency  time.Duration `json:"latency"`
+	Baseline time.Duration `json:"baseline"` // p50 for the provider and type
+	Delta    time.Duration `json:"delta"`
+}
+
+// NewAlert builds an alert from a report, or nil if every check passed.
+// baseline is ComputeStats over the target's history (may be empty).
+func NewAlert(report *Report, baseline []Stats, at time.Time) *Alert {
+	if report.OK() {
+		return nil
+	}
+
+	alert := &Alert{Target: report.Target, Time: at, Total: len(report.Results)}
+	for _, r := range report.Results {
+		if !r.OK {
+			alert.Failures = append(alert.Failures, AlertFailure{
+				Provider: r.Provider,
+				Location: r.Location,
+				Type:     r.Type,
+				Error:    r.Error,
+				Detail:   r.Detail,
+			})
+		}
+	}
+	alert.Failed = len(alert.Failures)
+
+	p50 := make(map[string]time.Duration)
+	for _, s := range baseline {
+		p50[s.Provider+"/"+string(s.Type)] = s.P50
+	}
+	for _, r := range report.Results {
+		base, ok := p50[r.Provider+"/"+string(r.Type)]
+		if !ok || base == 0 || r.Latency <= base {
+			continue
+		}
+		alert.LatencyDeltas = append(alert.LatencyDeltas, LatencyDelta{
+			Provider: r.Provider,
+			Location: r.Location,
+			Type:     r.Type,
+			Latency:  r.Latency,
+			Baseline: base,
+			Delta:    r.Latency - base,
+		})
+	}
+	sort.Slice(alert.LatencyDeltas, func(i, j int) bool {
+		return alert.LatencyDeltas[i].Delta > alert.LatencyDeltas[j].Delta
+	})
+	if len(alert.LatencyDeltas) > maxLatencyDeltas {
+		alert.LatencyDeltas = alert.LatencyDeltas[:maxLatencyDeltas]
+	}
+	return alert
+}
+
+// Text renders the alert as a short chat message.
+func (a *Alert) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Site check failed: %s (%d of %d checks)\n", a.Target, a.Failed, a.Total)
+	for _, f := range a.Failures {
+		location := f.Location
+		if location == "" {
+			location = "-"
+		}
+		reason := f.Error
+		if reason == "" {
+			reason = f.Detail
+		}
+		fmt.Fprintf(&b, "- %s %s %s: %s\n", f.Type, f.Provider, location, reason)
+	}
+	for _, d := range a.LatencyDeltas {
+		fmt.Fprintf(&b, "- slow: %s %s %s %dms (+%dms vs p50)\n",
+			d.Type, d.Provider, d.Location, d.Latency.Milliseconds(), d.Delta.Milliseconds())
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// WebhookSink posts alerts to a webhook URL.
+//
+// Slack (hooks.slack.com) and Discord (discord.com/api/webhooks) URLs get a
+// chat message; any other URL gets the Alert as JSON.
+type WebhookSink struct {
+	URL string
+
+	// DryRun prints the request body to Out instead of posting it
+	DryRun bool
+	Out    io.Writer
+
+	// Retries after a network error, 429 or 5xx (default: 3)
+	Retries int
+
+	// Backoff before the first retry, doubled each attempt (default: 1s)
+	Backoff time.Duration
+
+	Client *http.Client
+}
+
+// Body returns the request body for an alert, formatted for the URL's service.
+func (s *WebhookSink) Body(alert *Alert) ([]byte, error) {
+	return notify.WebhookBody(s.URL, alert.Text(), alert)
+}
+
+// Send posts an alert, retrying transient failures. A nil alert is a no-op.
+func (s *WebhookSink) Send(ctx context.Context, alert *Alert) error {
+	if alert == nil {
+		return nil
+	}
+
+	body, err := s.Body(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	if s.DryRun {
+		out := s.Out
+		if out == nil {
+			out = os.Stdout
+		}
+		_, _ = fmt.Fprintf(out, "Webhook dry run: POST %s\n%s\n", s.URL, body)
+		return nil
+	}
+
+	retries := s.Retries
+	if retries <= 0 {
+		retries = 3
+	}
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := s.post(ctx, client, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("failed to send alert to webhook: %w", lastErr)
+}
+
+// post sends one request and reports whether a failure is worth retrying.
+func (s *WebhookSink) post(ctx context.Context, client *http.Client, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned %d", resp.StatusCode)
+}
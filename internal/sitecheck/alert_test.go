@@ -0,0 +1,120 @@
+package sitecheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func failingReport() *Report {
+	return &Report{
+		Target: "example.com",
+		Results: []Result{
+			{Provider: "check-host", Location: "Frankfurt", Type: CheckHTTP, OK: false, Error: "Connection timed out"},
+			{Provider: "check-host", Location: "Tokyo", Type: CheckHTTP, OK: true, Latency: 900 * time.Millisecond},
+			{Provider: "direct", Location: "laptop", Type: CheckHTTP, OK: true, Latency: 100 * time.Millisecond},
+		},
+	}
+}
+
+func TestNewAlert(t *testing.T) {
+	ok := &Report{Target: "example.com", Results: []Result{{OK: true}}}
+	if NewAlert(ok, nil, time.Now()) != nil {
+		t.Error("NewAlert() for a passing report should be nil")
+	}
+
+	baseline := []Stats{
+		{Provider: "check-host", Type: CheckHTTP, P50: 300 * time.Millisecond},
+		{Provider: "direct", Type: CheckHTTP, P50: 200 * time.Millisecond},
+	}
+	alert := NewAlert(failingReport(), baseline, time.Now())
+	if alert.Failed != 1 || alert.Total != 3 || alert.Failures[0].Location != "Frankfurt" {
+		t.Errorf("alert = %+v", alert)
+	}
+	if len(alert.LatencyDeltas) != 1 || alert.LatencyDeltas[0].Delta != 600*time.Millisecond {
+		t.Errorf("LatencyDeltas = %+v, want Tokyo +600ms only", alert.LatencyDeltas)
+	}
+	if text := alert.Text(); !strings.Contains(text, "Frankfurt: Connection timed out") || !strings.Contains(text, "+600ms") {
+		t.Errorf("Text() = %q", text)
+	}
+}
+
+func TestWebhookSinkBody(t *testing.T) {
+	alert := NewAlert(failingReport(), nil, time.Now())
+
+	tests := []struct {
+		url string
+		key string
+	}{
+		{"https://hooks.slack.com/services/T/B/X", "text"},
+		{"https://discord.com/api/webhooks/1/abc", "content"},
+		{"https://example.com/hook", "target"},
+	}
+	for _, tt := range tests {
+		body, err := (&WebhookSink{URL: tt.url}).Body(alert)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := got[tt.key]; !ok {
+			t.Errorf("%s: body %s has no %q", tt.url, body, tt.key)
+		}
+	}
+}
+
+func TestWebhookSinkSend(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"ok", []int{200}, 1, false},
+		{"retry then ok", []int{503, 429, 204}, 3, false},
+		{"no retry on 400", []int{400, 200}, 1, true},
+		{"give up", []int{500, 500, 500}, 3, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[len(tt.statuses)-1]
+				if calls < len(tt.statuses) {
+					status = tt.statuses[calls]
+				}
+				calls++
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			sink := &WebhookSink{URL: server.URL, Retries: 2, Backoff: time.Millisecond}
+			err := sink.Send(context.Background(), NewAlert(failingReport(), nil, time.Now()))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestWebhookSinkDryRun(t *testing.T) {
+	var out bytes.Buffer
+	sink := &WebhookSink{URL: "http://127.0.0.1:1/never", DryRun: true, Out: &out}
+	if err := sink.Send(context.Background(), NewAlert(failingReport(), nil, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Webhook dry run: POST http://127.0.0.1:1/never") || !strings.Contains(out.String(), `"failed":1`) {
+		t.Errorf("dry run output = %q", out.String())
+	}
+}
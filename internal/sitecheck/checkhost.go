@@ -0,0 +1,264 @@
+package sitecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckHostURL is the check-host.net API base URL.
+const CheckHostURL = "https://check-host.net"
+
+// CheckHostProvider runs checks from check-host.net's global nodes.
+type CheckHostProvider struct {
+	// BaseURL overrides the API URL (for tests)
+	BaseURL string
+
+	// MaxNodes limits how many locations run the check
+	MaxNodes int
+
+	// PollInterval between result fetches (default: 2s)
+	PollInterval time.Duration
+
+	// Timeout waiting for all nodes to report (default: 30s)
+	Timeout time.Duration
+
+	client *http.Client
+}
+
+// NewCheckHostProvider creates a check-host.net provider with defaults.
+func NewCheckHostProvider() *CheckHostProvider {
+	return &CheckHostProvider{
+		BaseURL:      CheckHostURL,
+		MaxNodes:     20,
+		PollInterval: 2 * time.Second,
+		Timeout:      30 * time.Second,
+		client:       &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name implements Provider.
+func (p *CheckHostProvider) Name() string { return "check-host" }
+
+// checkHostStart is the response to a check-* request.
+type checkHostStart struct {
+	OK        int                 `json:"ok"`
+	RequestID string              `json:"request_id"`
+	Nodes     map[string][]string `json:"nodes"` // node -> [cc, country, city, ip, asn]
+}
+
+// Check implements Provider.
+func (p *CheckHostProvider) Check(ctx context.Context, checkType CheckType, target string) ([]Result, error) {
+	var endpoint, host string
+	switch checkType {
+	case CheckHTTP:
+		endpoint, host = "check-http", targetURL(target)
+	case CheckDNS:
+		endpoint, host = "check-dns", targetHost(target)
+	case CheckTCP:
+		endpoint, host = "check-tcp", targetHostPort(target)
+	default:
+		return nil, ErrUnsupported
+	}
+
+	q := url.Values{"host": {host}}
+	if p.MaxNodes > 0 {
+		q.Set("max_nodes", strconv.Itoa(p.MaxNodes))
+	}
+
+	var start checkHostStart
+	if err := p.getJSON(ctx, "/"+endpoint+"?"+q.Encode(), &start); err != nil {
+		return nil, err
+	}
+	if start.OK != 1 || start.RequestID == "" {
+		return nil, fmt.Errorf("check-host.net rejected the check for %s", host)
+	}
+
+	raw, err := p.waitResults(ctx, start.RequestID, len(start.Nodes))
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]string, 0, len(start.Nodes))
+	for node := range start.Nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	results := make([]Result, 0, len(nodes))
+	for _, node := range nodes {
+		result := parseCheckHostResult(checkType, raw[node])
+		result.Location = checkHostLocation(node, start.Nodes[node])
+		if info := start.Nodes[node]; len(info) > 3 {
+			result.NodeIP = info[3]
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// waitResults polls check-result until every node has reported or the timeout passes.
+func (p *CheckHostProvider) waitResults(ctx context.Context, requestID string, nodes int) (map[string]json.RawMessage, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var raw map[string]json.RawMessage
+	for {
+		if err := p.getJSON(ctx, "/check-result/"+requestID, &raw); err != nil {
+			if ctx.Err() != nil && raw != nil {
+				// Timed out mid-poll: report what we have
+				return raw, nil
+			}
+			return nil, err
+		}
+
+		done := 0
+		for _, r := range raw {
+			if string(r) != "null" {
+				done++
+			}
+		}
+		if done >= nodes {
+			return raw, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			// Nodes that never reported show as failures
+			return raw, nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (p *CheckHostProvider) getJSON(ctx context.Context, path string, v any) error {
+	base := p.BaseURL
+	if base == "" {
+		base = CheckHostURL
+	}
+	client := p.client
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("check-host.net request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("check-host.net returned %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode check-host.net response: %w", err)
+	}
+	return nil
+}
+
+// checkHostLocation formats a node as "Country, City (node)".
+func checkHostLocation(node string, info []string) string {
+	name := strings.TrimSuffix(node, ".node.check-host.net")
+	if len(info) >= 3 {
+		return fmt.Sprintf("%s, %s (%s)", info[1], info[2], name)
+	}
+	return name
+}
+
+// parseCheckHostResult converts one node's raw result to a Result.
+//
+// Result formats (null while pending):
+//
+//	http: [[1, 0.13, "OK", "200", "1.2.3.4"]]
+//	dns:  [{"A": ["1.2.3.4"], "AAAA": [], "TTL": 300}]
+//	tcp:  [{"time": 0.03, "address": "1.2.3.4"}] or [{"error": "..."}]
+func parseCheckHostResult(checkType CheckType, raw json.RawMessage) Result {
+	if len(raw) == 0 || string(raw) == "null" {
+		return Result{Error: "no result (timed out)"}
+	}
+
+	switch checkType {
+	case CheckHTTP:
+		var rows [][]any
+		if err := json.Unmarshal(raw, &rows); err != nil || len(rows) == 0 || rows[0] == nil {
+			return Result{Error: "unexpected result: " + string(raw)}
+		}
+		row := rows[0]
+		result := Result{}
+		if ok, _ := row[0].(float64); ok == 1 {
+			result.OK = true
+		}
+		if len(row) > 1 {
+			if secs, ok := row[1].(float64); ok {
+				result.Latency = time.Duration(secs * float64(time.Second))
+			}
+		}
+		if len(row) > 3 {
+			result.Detail = fmt.Sprintf("%v %v", row[3], row[2])
+		} else if len(row) > 2 {
+			result.Error = fmt.Sprintf("%v", row[2])
+		}
+		if len(row) > 4 {
+			if ip, ok := row[4].(string); ok {
+				result.IP = ip
+			}
+		}
+		return result
+
+	case CheckDNS:
+		var rows []struct {
+			A    []string `json:"A"`
+			AAAA []string `json:"AAAA"`
+		}
+		if err := json.Unmarshal(raw, &rows); err != nil || len(rows) == 0 {
+			return Result{Error: "unexpected result: " + string(raw)}
+		}
+		addrs := append(rows[0].A, rows[0].AAAA...)
+		if len(addrs) == 0 {
+			return Result{Error: "no records"}
+		}
+		return Result{OK: true, IP: addrs[0], Detail: strings.Join(addrs, ", ")}
+
+	case CheckTCP:
+		var rows []struct {
+			Time    float64 `json:"time"`
+			Address string  `json:"address"`
+			Error   string  `json:"error"`
+		}
+		if err := json.Unmarshal(raw, &rows); err != nil || len(rows) == 0 {
+			return Result{Error: "unexpected result: " + string(raw)}
+		}
+		if rows[0].Error != "" {
+			return Result{Error: rows[0].Error}
+		}
+		return Result{
+			OK:      true,
+			Latency: time.Duration(rows[0].Time * float64(time.Second)),
+			IP:      rows[0].Address,
+			Detail:  rows[0].Address,
+		}
+	}
+
+	return Result{Error: "unsupported check type"}
+}
@@ -0,0 +1,206 @@
+package sitecheck
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DirectProvider probes the target from the local machine.
+type DirectProvider struct {
+	// Location labels results (default: hostname)
+	Location string
+
+	// Timeout per check (default: 10s)
+	Timeout time.Duration
+
+	// NoFollowRedirects reports a redirect's own status on http checks
+	// instead of following it (needed to expect a 301)
+	NoFollowRedirects bool
+}
+
+// Name implements Provider.
+func (p *DirectProvider) Name() string { return "direct" }
+
+func (p *DirectProvider) location() string {
+	if p.Location != "" {
+		return p.Location
+	}
+	if host, err := os.Hostname(); err == nil {
+		return host
+	}
+	return "local"
+}
+
+func (p *DirectProvider) timeout() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout
+	}
+	return 10 * time.Second
+}
+
+// Check implements Provider.
+func (p *DirectProvider) Check(ctx context.Context, checkType CheckType, target string) ([]Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout())
+	defer cancel()
+
+	result := Result{Location: p.location()}
+	start := time.Now()
+
+	// Record the address http and redirect checks connected to
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			result.IP = remoteIP(info.Conn.RemoteAddr())
+		},
+	})
+
+	var err error
+	switch checkType {
+	case CheckHTTP:
+		result.Detail, err = p.checkHTTP(ctx, target)
+	case CheckDNS:
+		result.Detail, err = p.checkDNS(ctx, target)
+	case CheckTCP:
+		result.Detail, err = p.checkTCP(ctx, target)
+	case CheckRedirect:
+		result.Detail, err = p.checkRedirect(ctx, target)
+	default:
+		return nil, ErrUnsupported
+	}
+
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		result.Category = directErrorCategory(checkType, err)
+	} else {
+		result.OK = true
+		switch checkType {
+		case CheckDNS:
+			result.IP, _, _ = strings.Cut(result.Detail, ", ")
+		case CheckTCP:
+			if host, _, err := net.SplitHostPort(result.Detail); err == nil {
+				result.IP = host
+			}
+		}
+	}
+	return []Result{result}, nil
+}
+
+// directErrorCategory classifies a local check error by its type, falling
+// back to the message for errors wrapped beyond recognition.
+func directErrorCategory(checkType CheckType, err error) ErrorCategory {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr):
+		return ErrorTLS
+	case errors.As(err, &opErr) && opErr.Op == "dial":
+		return ErrorConnect
+	}
+	return CategorizeError(checkType, err.Error())
+}
+
+// remoteIP returns the host part of a connection's remote address.
+func remoteIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
+
+func (p *DirectProvider) checkHTTP(ctx context.Context, target string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL(target), nil)
+	if err != nil {
+		return "", err
+	}
+
+	client := http.DefaultClient
+	if p.NoFollowRedirects {
+		client = &http.Client{
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		return resp.Status, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return resp.Status, nil
+}
+
+func (p *DirectProvider) checkDNS(ctx context.Context, target string) (string, error) {
+	addrs, err := net.DefaultResolver.LookupHost(ctx, targetHost(target))
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(addrs, ", "), nil
+}
+
+func (p *DirectProvider) checkTCP(ctx context.Context, target string) (string, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", targetHostPort(target))
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	return conn.RemoteAddr().String(), nil
+}
+
+// checkRedirect expects the apex domain to redirect to the www host.
+func (p *DirectProvider) checkRedirect(ctx context.Context, target string) (string, error) {
+	host := strings.TrimPrefix(targetHost(target), "www.")
+	apex := "https://" + host + "/"
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apex, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+		return resp.Status, fmt.Errorf("%s does not redirect (HTTP %d)", apex, resp.StatusCode)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return location, fmt.Errorf("invalid redirect location: %w", err)
+	}
+	if u.Hostname() != "www."+host {
+		return location, fmt.Errorf("redirects to %s, want www.%s", u.Hostname(), host)
+	}
+	return fmt.Sprintf("%d -> %s", resp.StatusCode, location), nil
+}
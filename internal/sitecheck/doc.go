@@ -0,0 +1,123 @@
+// Package sitecheck checks site reachability from many locations.
+//
+// Checks are run by pluggable probe providers, so the same check can be run
+// from check-host.net's global nodes, from the local machine, or from a list
+// of self-hosted agents.
+//
+// # Components
+//
+//   - Provider: Runs a check against a target and returns per-location results
+//   - CheckHostProvider: Global probes via the check-host.net API
+//   - DirectProvider: Probes from the local machine (no third party)
+//   - AgentProvider: Probes from self-hosted agents (xplat site agent)
+//   - AgentHandler: HTTP handler that serves DirectProvider checks to remote callers
+//   - Watcher: Runs checks on a schedule, records JSONL history, serves status
+//   - FileConfig: Multi-target sitecheck.yaml, run concurrently by RunFile
+//   - WebhookSink: Posts an Alert (failed nodes, latency deltas) to Slack, Discord or any URL
+//   - Output: Machine-readable results with error categories and state changes
+//
+// # Check Types
+//
+//   - http: GET the target and expect a 2xx/3xx status
+//   - dns: Resolve the host name
+//   - tcp: Connect to port 443 (or the target's port)
+//   - redirect: Expect the apex domain to redirect to www (direct and agent only)
+//
+// # Library Usage
+//
+//	report, err := sitecheck.Run(ctx, sitecheck.Config{
+//	    Target:    "example.com",
+//	    Types:     []sitecheck.CheckType{sitecheck.CheckHTTP, sitecheck.CheckDNS},
+//	    Providers: []sitecheck.Provider{sitecheck.NewCheckHostProvider(), &sitecheck.DirectProvider{}},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	sitecheck.PrintReport(report)
+//	if !report.OK() {
+//	    os.Exit(1)
+//	}
+//
+// # Agents
+//
+// Any machine running 'xplat site agent' answers GET /check?type=http&target=...
+// with DirectProvider results. List agents with --agent to probe from them.
+//
+// # Multiple Targets
+//
+// A sitecheck.yaml lists several targets, each with its own check types,
+// providers, expected HTTP status and latency threshold:
+//
+//	defaults:
+//	  providers: [direct]
+//	  max_latency: 2s
+//	targets:
+//	  - url: https://www.example.com
+//	    expect_status: 200
+//	  - name: apex
+//	    url: https://example.com
+//	    expect_status: 301
+//
+// RunFile checks all targets concurrently. MultiReport.Record appends each
+// target's results to its own history file and keeps a per-target state file
+// (<name>.state.json) with the consecutive failure count and last change.
+//
+// # Alerts
+//
+// NewAlert summarizes a failed report: the failed checks and the checks that
+// were slowest relative to the history p50 for their provider and type.
+// WebhookSink posts it, retrying network errors, 429 and 5xx with backoff:
+//
+//	sink := &sitecheck.WebhookSink{URL: "https://hooks.slack.com/services/..."}
+//	err := sink.Send(ctx, sitecheck.NewAlert(report, sitecheck.ComputeStats(history), time.Now()))
+//
+// Set DryRun to print the payload instead. A Watcher with Alerts set only
+// alerts when a run starts failing, not on every failing run.
+//
+// # JSON Output
+//
+// Every failed Result carries an ErrorCategory (dns, connect, tls, http or
+// latency), and results record the probing node's IP and the target IP where
+// the provider reports them. NewOutput turns a MultiReport (SingleReport for
+// one target) into a versioned Output with per-target summaries and the
+// StateChange since the previous run. 'xplat site check --format json'
+// prints it and exits with ExitOK, ExitFailed or ExitError (no checks ran).
+//
+// # Continuous Monitoring
+//
+// A Watcher runs the checks every interval, appends each result to a JSONL
+// history file and keeps rolling availability and p50/p90/p99 latency:
+//
+//	sitecheck.RunWatch(ctx, sitecheck.WatchConfig{
+//	    Config:   sitecheck.Config{Target: "example.com"},
+//	    Interval: 10 * time.Minute,
+//	}, ":8771")
+//
+// GET /status returns the rolling stats as JSON; GET /health returns 503
+// while the last run is failing, for process-compose readiness probes.
+//
+// # SLOs
+//
+// SLOs declared in xplat.yaml set the thresholds instead of flags.
+// SLOTargets turns them into targets whose MaxLatency is the SLO's latency
+// target (process SLOs probe localhost directly), and ManifestSLOStatus
+// measures each against its history over the SLO window: availability,
+// p99 and the error budget left. 'xplat service status' and the web UI's
+// process list show the same compliance.
+//
+// # CLI Commands
+//
+//	xplat site check [target]                 # HTTP check via check-host.net
+//	xplat site check --type dns,tcp,redirect  # Other check types
+//	xplat site check --provider direct        # Probe from this machine
+//	xplat site check --agent https://a.example.com --agent https://b.example.com
+//	xplat site check --expect-status 301 --max-latency 2s
+//	xplat site check --config sitecheck.yaml  # Every target in the file
+//	xplat site check --format json            # Results, categories and state as JSON
+//	xplat site check --webhook https://hooks.slack.com/services/... --webhook-dry-run
+//	xplat site check --watch --interval 10m --addr :8771
+//	xplat site check --slo                    # The SLOs declared in xplat.yaml
+//	xplat site history example.com --window 168h
+//	xplat site slo                            # SLO compliance
+//	xplat site agent --port 8770              # Serve probes for other machines
+package sitecheck
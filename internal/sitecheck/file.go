@@ -0,0 +1,349 @@
+package sitecheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/statestore"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the multi-target config looked up in the working directory.
+const DefaultConfigFile = "sitecheck.yaml"
+
+// TargetConfig is one target in a sitecheck.yaml.
+// Empty fields take their value from the file's defaults.
+type TargetConfig struct {
+	// Name identifies the target in reports and state files (default: URL host)
+	Name string `yaml:"name,omitempty"`
+
+	// URL is the host name or URL to check
+	URL string `yaml:"url,omitempty"`
+
+	Types     []string `yaml:"types,omitempty"`
+	Providers []string `yaml:"providers,omitempty"`
+	Agents    []string `yaml:"agents,omitempty"`
+
+	// ExpectStatus is the HTTP status the http check must return (e.g. 301)
+	ExpectStatus int `yaml:"expect_status,omitempty"`
+
+	// MaxLatency fails checks slower than this (e.g. 2s)
+	MaxLatency time.Duration `yaml:"max_latency,omitempty"`
+}
+
+// FileConfig is a sitecheck.yaml:
+//
+//	defaults:
+//	  providers: [direct]
+//	  max_latency: 2s
+//	targets:
+//	  - url: https://www.example.com
+//	    types: [http, tcp]
+//	    expect_status: 200
+//	  - name: apex
+//	    url: https://example.com
+//	    expect_status: 301
+type FileConfig struct {
+	Defaults TargetConfig   `yaml:"defaults,omitempty"`
+	Targets  []TargetConfig `yaml:"targets"`
+}
+
+// LoadFile reads and validates a sitecheck.yaml.
+func LoadFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var fc FileConfig
+	if err := yaml.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(fc.Targets) == 0 {
+		return nil, fmt.Errorf("%s: no targets", path)
+	}
+
+	seen := make(map[string]bool)
+	for i, t := range fc.Targets {
+		if t.URL == "" {
+			return nil, fmt.Errorf("%s: target %d has no url", path, i+1)
+		}
+		name := t.name()
+		if seen[name] {
+			return nil, fmt.Errorf("%s: duplicate target name %q", path, name)
+		}
+		seen[name] = true
+	}
+	return &fc, nil
+}
+
+func (t TargetConfig) name() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	return targetHost(t.URL)
+}
+
+// Resolve returns the target with empty fields filled from defaults.
+func (t TargetConfig) Resolve(defaults TargetConfig) TargetConfig {
+	if len(t.Types) == 0 {
+		t.Types = defaults.Types
+	}
+	if len(t.Providers) == 0 {
+		t.Providers = defaults.Providers
+	}
+	if len(t.Agents) == 0 {
+		t.Agents = defaults.Agents
+	}
+	if t.ExpectStatus == 0 {
+		t.ExpectStatus = defaults.ExpectStatus
+	}
+	if t.MaxLatency == 0 {
+		t.MaxLatency = defaults.MaxLatency
+	}
+	t.Name = t.name()
+	return t
+}
+
+// Config builds the Run config for a resolved target.
+func (t TargetConfig) Config() (Config, error) {
+	types, err := ParseCheckTypes(strings.Join(t.Types, ","))
+	if err != nil {
+		return Config{}, err
+	}
+
+	names := t.Providers
+	if len(names) == 0 {
+		names = []string{"check-host"}
+		if len(t.Agents) > 0 {
+			names = []string{"agent"}
+		}
+	}
+
+	var providers []Provider
+	for _, name := range names {
+		provider, err := NewProvider(name, t.Agents)
+		if err != nil {
+			return Config{}, err
+		}
+		// Expecting a redirect means looking at the first response
+		if direct, ok := provider.(*DirectProvider); ok && t.ExpectStatus >= 300 && t.ExpectStatus < 400 {
+			direct.NoFollowRedirects = true
+		}
+		providers = append(providers, provider)
+	}
+
+	return Config{
+		Target:       t.URL,
+		Types:        types,
+		Providers:    providers,
+		ExpectStatus: t.ExpectStatus,
+		MaxLatency:   t.MaxLatency,
+	}, nil
+}
+
+// TargetReport is one target's results in a MultiReport.
+type TargetReport struct {
+	Name     string       `json:"name"`
+	Report   *Report      `json:"report"`
+	State    *TargetState `json:"state,omitempty"`
+	Previous *TargetState `json:"previous_state,omitempty"` // state before this run
+	Error    string       `json:"error,omitempty"`          // config error, no checks ran
+}
+
+// OK returns true if the target's checks all passed.
+func (t *TargetReport) OK() bool {
+	return t.Error == "" && t.Report != nil && t.Report.OK()
+}
+
+// MultiReport holds the results of RunFile, in file order.
+type MultiReport struct {
+	Targets []*TargetReport `json:"targets"`
+}
+
+// OK returns true if every target passed.
+func (m *MultiReport) OK() bool {
+	for _, t := range m.Targets {
+		if !t.OK() {
+			return false
+		}
+	}
+	return len(m.Targets) > 0
+}
+
+// RunFile checks every target in the file concurrently.
+func RunFile(ctx context.Context, fc *FileConfig) *MultiReport {
+	multi := &MultiReport{Targets: make([]*TargetReport, len(fc.Targets))}
+
+	var wg sync.WaitGroup
+	for i, t := range fc.Targets {
+		t = t.Resolve(fc.Defaults)
+		multi.Targets[i] = &TargetReport{Name: t.Name}
+
+		cfg, err := t.Config()
+		if err != nil {
+			multi.Targets[i].Error = err.Error()
+			continue
+		}
+
+		wg.Add(1)
+		go func(tr *TargetReport) {
+			defer wg.Done()
+			report, err := Run(ctx, cfg)
+			if err != nil {
+				tr.Error = err.Error()
+				return
+			}
+			tr.Report = report
+		}(multi.Targets[i])
+	}
+	wg.Wait()
+
+	return multi
+}
+
+// TargetState is the persisted state of one target between runs.
+type TargetState struct {
+	Name                string    `json:"name"`
+	Target              string    `json:"target"`
+	OK                  bool      `json:"ok"`
+	LastRun             time.Time `json:"last_run"`
+	LastChange          time.Time `json:"last_change"` // when OK last flipped
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// StatePath returns the state file for a target name:
+// ~/.xplat/state/<project>/sitecheck/<name>.state.json
+func StatePath(name string) string {
+	return strings.TrimSuffix(HistoryPath(name), ".jsonl") + ".state.json"
+}
+
+// stateVersion is the TargetState schema version (see statestore)
+const stateVersion = 1
+
+// stateStore returns the store for a target's state file. It is locked, as
+// a scheduled run and a manual run may record the same target at once.
+func stateStore(path string) *statestore.Store[TargetState] {
+	store := statestore.New[TargetState](path, stateVersion)
+	store.Lock = true
+	return store
+}
+
+// LoadState reads a target's state file, or returns nil if there is none.
+func LoadState(path string) *TargetState {
+	state, err := stateStore(path).Load()
+	if err != nil {
+		return nil
+	}
+	return state
+}
+
+// UpdateState updates a target's state file with a new result.
+func UpdateState(path string, tr *TargetReport, at time.Time) (*TargetState, error) {
+	state, err := stateStore(path).Update(func(state *TargetState) error {
+		prev := *state
+		*state = TargetState{
+			Name:       tr.Name,
+			OK:         tr.OK(),
+			LastRun:    at,
+			LastChange: prev.LastChange,
+		}
+		if tr.Report != nil {
+			state.Target = tr.Report.Target
+		}
+		if !state.OK {
+			state.ConsecutiveFailures = prev.ConsecutiveFailures + 1
+		}
+		if prev.LastRun.IsZero() || prev.OK != state.OK {
+			state.LastChange = at
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update state: %w", err)
+	}
+	return state, nil
+}
+
+// Record appends each target's results to its history file and updates its
+// state file (both keyed by target name).
+func (m *MultiReport) Record(at time.Time) error {
+	for _, tr := range m.Targets {
+		if tr.Report != nil {
+			if err := AppendHistory(HistoryPath(tr.Name), tr.Report, at); err != nil {
+				return err
+			}
+		}
+		if err := tr.RecordState(at); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordState updates the target's state file, keeping the state before
+// this run in Previous.
+func (tr *TargetReport) RecordState(at time.Time) error {
+	path := StatePath(tr.Name)
+	tr.Previous = LoadState(path)
+	state, err := UpdateState(path, tr, at)
+	if err != nil {
+		return err
+	}
+	tr.State = state
+	return nil
+}
+
+// PrintMultiReport prints one line per target followed by its failed checks.
+func PrintMultiReport(m *MultiReport) {
+	failed := 0
+	for _, tr := range m.Targets {
+		status := "OK  "
+		if !tr.OK() {
+			status = "FAIL"
+			failed++
+		}
+
+		line := fmt.Sprintf("%s  %-20s", status, tr.Name)
+		if tr.Report != nil {
+			passed := 0
+			for _, r := range tr.Report.Results {
+				if r.OK {
+					passed++
+				}
+			}
+			line += fmt.Sprintf(" %-36s %d/%d checks", tr.Report.Target, passed, len(tr.Report.Results))
+		}
+		if tr.State != nil && !tr.State.OK {
+			line += fmt.Sprintf("  (failing %d runs, since %s)", tr.State.ConsecutiveFailures, tr.State.LastChange.Format(time.RFC3339))
+		}
+		fmt.Println(line)
+
+		if tr.Error != "" {
+			fmt.Printf("        %s\n", tr.Error)
+		}
+		if tr.Report != nil {
+			for _, r := range tr.Report.Results {
+				if r.OK {
+					continue
+				}
+				location := r.Location
+				if location == "" {
+					location = "-"
+				}
+				fmt.Printf("        %-9s %-12s %-28s %s %s\n", r.Type, r.Provider, location, r.Detail, r.Error)
+			}
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%d of %d targets failed\n", failed, len(m.Targets))
+	} else {
+		fmt.Printf("All %d targets passed\n", len(m.Targets))
+	}
+}
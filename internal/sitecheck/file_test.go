@@ -0,0 +1,169 @@
+package sitecheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr bool
+	}{
+		{"valid", "targets:\n  - url: https://www.example.com\n  - name: apex\n    url: example.com\n", false},
+		{"no targets", "defaults:\n  types: [http]\n", true},
+		{"missing url", "targets:\n  - name: x\n", true},
+		{"duplicate name", "targets:\n  - url: https://example.com\n  - url: http://example.com/other\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), DefaultConfigFile)
+			if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadFile(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTargetConfigResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultConfigFile)
+	data := `defaults:
+  providers: [direct]
+  types: [http, tcp]
+  max_latency: 1500ms
+targets:
+  - url: https://www.example.com
+  - name: apex
+    url: https://example.com
+    types: [http]
+    expect_status: 301
+`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fc, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	www := fc.Targets[0].Resolve(fc.Defaults)
+	if www.Name != "www.example.com" || len(www.Types) != 2 || www.MaxLatency != 1500*time.Millisecond {
+		t.Errorf("www resolved to %+v", www)
+	}
+
+	apex := fc.Targets[1].Resolve(fc.Defaults)
+	cfg, err := apex.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Types) != 1 || cfg.ExpectStatus != 301 || cfg.MaxLatency != 1500*time.Millisecond {
+		t.Errorf("apex config = %+v", cfg)
+	}
+	direct, ok := cfg.Providers[0].(*DirectProvider)
+	if !ok || !direct.NoFollowRedirects {
+		t.Errorf("expect_status 301 should use a non-following direct provider, got %#v", cfg.Providers[0])
+	}
+}
+
+func TestConfigEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    Config
+		result Result
+		wantOK bool
+	}{
+		{"expected 301", Config{ExpectStatus: 301}, Result{Type: CheckHTTP, OK: true, Detail: "301 Moved Permanently"}, true},
+		{"want 301 got 200", Config{ExpectStatus: 301}, Result{Type: CheckHTTP, OK: true, Detail: "200 OK"}, false},
+		{"expected 404", Config{ExpectStatus: 404}, Result{Type: CheckHTTP, Detail: "404 Not Found", Error: "HTTP 404"}, true},
+		{"no response kept", Config{ExpectStatus: 200}, Result{Type: CheckHTTP, Error: "timeout"}, false},
+		{"dns ignores status", Config{ExpectStatus: 301}, Result{Type: CheckDNS, OK: true, Detail: "1.2.3.4"}, true},
+		{"too slow", Config{MaxLatency: time.Second}, Result{Type: CheckTCP, OK: true, Latency: 2 * time.Second}, false},
+		{"fast enough", Config{MaxLatency: time.Second}, Result{Type: CheckTCP, OK: true, Latency: 500 * time.Millisecond}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.result
+			tt.cfg.evaluate(&r)
+			if r.OK != tt.wantOK {
+				t.Errorf("OK = %v, want %v (result %+v)", r.OK, tt.wantOK, r)
+			}
+			if r.OK && r.Error != "" {
+				t.Errorf("passing result has error %q", r.Error)
+			}
+		})
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://www.example.com/", http.StatusMovedPermanently)
+	}))
+	defer redirect.Close()
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+
+	fc := &FileConfig{
+		Defaults: TargetConfig{Providers: []string{"direct"}},
+		Targets: []TargetConfig{
+			{Name: "apex", URL: redirect.URL, ExpectStatus: 301},
+			{Name: "www", URL: ok.URL},
+			{Name: "wrong", URL: ok.URL, ExpectStatus: 301},
+			{Name: "bad", URL: ok.URL, Types: []string{"nope"}},
+		},
+	}
+
+	multi := RunFile(context.Background(), fc)
+	want := map[string]bool{"apex": true, "www": true, "wrong": false, "bad": false}
+	for i, tr := range multi.Targets {
+		if tr.Name != fc.Targets[i].Name {
+			t.Errorf("target %d = %s, want file order", i, tr.Name)
+		}
+		if tr.OK() != want[tr.Name] {
+			t.Errorf("%s OK = %v, want %v (%+v)", tr.Name, tr.OK(), want[tr.Name], tr.Report)
+		}
+	}
+	if multi.OK() {
+		t.Error("MultiReport.OK() = true with failing targets")
+	}
+}
+
+func TestUpdateState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "site.state.json")
+	passing := &TargetReport{Name: "site", Report: &Report{Target: "example.com", Results: []Result{{OK: true}}}}
+	failing := &TargetReport{Name: "site", Report: &Report{Target: "example.com", Results: []Result{{OK: false}}}}
+
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	steps := []struct {
+		report     *TargetReport
+		wantFails  int
+		wantChange time.Time
+	}{
+		{passing, 0, t0},
+		{failing, 1, t0.Add(time.Minute)},
+		{failing, 2, t0.Add(time.Minute)},
+		{passing, 0, t0.Add(3 * time.Minute)},
+	}
+
+	for i, step := range steps {
+		state, err := UpdateState(path, step.report, t0.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if state.ConsecutiveFailures != step.wantFails || !state.LastChange.Equal(step.wantChange) {
+			t.Errorf("step %d: failures %d, change %s; want %d, %s",
+				i, state.ConsecutiveFailures, state.LastChange, step.wantFails, step.wantChange)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package sitecheck
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// Exit codes of 'xplat site check', stable for CI pipelines and the MCP server.
+const (
+	ExitOK     = 0 // every check passed
+	ExitFailed = 1 // one or more checks failed
+	ExitError  = 2 // no checks ran: bad flags, config or target
+)
+
+// OutputVersion is the schema version of Output, bumped on breaking changes.
+const OutputVersion = 1
+
+// Output is the machine-readable result of a site check run (--format json).
+// Single-target and sitecheck.yaml runs share this shape.
+type Output struct {
+	Version   int            `json:"version"`
+	OK        bool           `json:"ok"`
+	ExitCode  int            `json:"exit_code"`
+	CheckedAt time.Time      `json:"checked_at"`
+	Targets   []TargetOutput `json:"targets"`
+	Error     string         `json:"error,omitempty"` // set with ExitError
+}
+
+// TargetOutput is one target's results and state change in an Output.
+type TargetOutput struct {
+	Name    string         `json:"name"`
+	Target  string         `json:"target,omitempty"`
+	OK      bool           `json:"ok"`
+	Error   string         `json:"error,omitempty"` // config error, no checks ran
+	Summary OutputSummary  `json:"summary"`
+	Results []ResultOutput `json:"results"`
+	State   *StateChange   `json:"state,omitempty"`
+}
+
+// OutputSummary counts a target's results, with failures by category.
+type OutputSummary struct {
+	Total      int                   `json:"total"`
+	Passed     int                   `json:"passed"`
+	Failed     int                   `json:"failed"`
+	Categories map[ErrorCategory]int `json:"categories,omitempty"`
+}
+
+// ResultOutput is a Result with its latency in milliseconds.
+type ResultOutput struct {
+	Result
+	LatencyMS int64 `json:"latency_ms"`
+}
+
+// State transitions between the previous run and this one.
+const (
+	TransitionNew       = "new"       // no previous state
+	TransitionUnchanged = "unchanged" // still passing or still failing
+	TransitionFailing   = "failing"   // was passing, now failing
+	TransitionRecovered = "recovered" // was failing, now passing
+)
+
+// StateChange compares a target's state before and after a run.
+type StateChange struct {
+	Transition string       `json:"transition"`
+	Previous   *TargetState `json:"previous,omitempty"`
+	Current    *TargetState `json:"current"`
+}
+
+// CompareState returns the state change between two runs of a target.
+func CompareState(prev, cur *TargetState) *StateChange {
+	if cur == nil {
+		return nil
+	}
+	change := &StateChange{Transition: TransitionUnchanged, Previous: prev, Current: cur}
+	switch {
+	case prev == nil:
+		change.Transition = TransitionNew
+	case prev.OK && !cur.OK:
+		change.Transition = TransitionFailing
+	case !prev.OK && cur.OK:
+		change.Transition = TransitionRecovered
+	}
+	return change
+}
+
+// SingleReport wraps a single-target report as a MultiReport, named like a
+// sitecheck.yaml target without a name.
+func SingleReport(report *Report) *MultiReport {
+	return &MultiReport{Targets: []*TargetReport{{Name: targetHost(report.Target), Report: report}}}
+}
+
+// NewOutput builds the Output for a run.
+func NewOutput(m *MultiReport, at time.Time) *Output {
+	out := &Output{
+		Version:   OutputVersion,
+		OK:        m.OK(),
+		ExitCode:  ExitOK,
+		CheckedAt: at,
+		Targets:   make([]TargetOutput, 0, len(m.Targets)),
+	}
+	if !out.OK {
+		out.ExitCode = ExitFailed
+	}
+
+	for _, tr := range m.Targets {
+		t := TargetOutput{
+			Name:    tr.Name,
+			OK:      tr.OK(),
+			Error:   tr.Error,
+			Results: []ResultOutput{},
+			State:   CompareState(tr.Previous, tr.State),
+		}
+		if tr.Report != nil {
+			t.Target = tr.Report.Target
+			for _, r := range tr.Report.Results {
+				t.Results = append(t.Results, ResultOutput{Result: r, LatencyMS: r.Latency.Milliseconds()})
+				t.Summary.Total++
+				if r.OK {
+					t.Summary.Passed++
+					continue
+				}
+				t.Summary.Failed++
+				if r.Category != "" {
+					if t.Summary.Categories == nil {
+						t.Summary.Categories = make(map[ErrorCategory]int)
+					}
+					t.Summary.Categories[r.Category]++
+				}
+			}
+		}
+		out.Targets = append(out.Targets, t)
+	}
+	return out
+}
+
+// ErrorOutput is the Output of a run that failed before any checks ran.
+func ErrorOutput(err error, at time.Time) *Output {
+	return &Output{
+		Version:   OutputVersion,
+		ExitCode:  ExitError,
+		CheckedAt: at,
+		Targets:   []TargetOutput{},
+		Error:     err.Error(),
+	}
+}
+
+// WriteJSON writes an Output as indented JSON.
+func WriteJSON(w io.Writer, out *Output) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
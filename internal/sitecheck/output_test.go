@@ -0,0 +1,88 @@
+package sitecheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompareState(t *testing.T) {
+	passing := &TargetState{OK: true}
+	failing := &TargetState{OK: false}
+
+	tests := []struct {
+		prev, cur *TargetState
+		want      string
+	}{
+		{nil, passing, TransitionNew},
+		{passing, passing, TransitionUnchanged},
+		{failing, failing, TransitionUnchanged},
+		{passing, failing, TransitionFailing},
+		{failing, passing, TransitionRecovered},
+	}
+
+	for _, tt := range tests {
+		if got := CompareState(tt.prev, tt.cur); got.Transition != tt.want {
+			t.Errorf("CompareState(%v, %v) = %q, want %q", tt.prev, tt.cur, got.Transition, tt.want)
+		}
+	}
+	if CompareState(passing, nil) != nil {
+		t.Error("CompareState without a current state should be nil")
+	}
+}
+
+func TestNewOutput(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	multi := SingleReport(&Report{Target: "https://www.example.com", Results: []Result{
+		{Provider: "direct", Type: CheckHTTP, OK: true, Latency: 120 * time.Millisecond, IP: "1.2.3.4"},
+		{Provider: "check-host", Type: CheckHTTP, Error: "Connection timed out", Category: ErrorConnect},
+		{Provider: "check-host", Type: CheckDNS, Error: "no records", Category: ErrorDNS},
+	}})
+	multi.Targets[0].Previous = &TargetState{OK: true}
+	multi.Targets[0].State = &TargetState{OK: false, ConsecutiveFailures: 1}
+
+	out := NewOutput(multi, at)
+	if out.OK || out.ExitCode != ExitFailed || out.Version != OutputVersion {
+		t.Errorf("ok %v, exit %d, version %d", out.OK, out.ExitCode, out.Version)
+	}
+
+	target := out.Targets[0]
+	if target.Name != "www.example.com" || target.Target != "https://www.example.com" {
+		t.Errorf("name %q, target %q", target.Name, target.Target)
+	}
+	if target.Summary.Total != 3 || target.Summary.Passed != 1 || target.Summary.Failed != 2 {
+		t.Errorf("summary = %+v", target.Summary)
+	}
+	if target.Summary.Categories[ErrorConnect] != 1 || target.Summary.Categories[ErrorDNS] != 1 {
+		t.Errorf("categories = %v", target.Summary.Categories)
+	}
+	if target.State == nil || target.State.Transition != TransitionFailing {
+		t.Errorf("state = %+v", target.State)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Targets []struct {
+			Results []map[string]any `json:"results"`
+		} `json:"targets"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	first := decoded.Targets[0].Results[0]
+	if first["latency_ms"] != float64(120) || first["ip"] != "1.2.3.4" || first["provider"] != "direct" {
+		t.Errorf("first result = %v", first)
+	}
+}
+
+func TestErrorOutput(t *testing.T) {
+	out := ErrorOutput(errors.New("no target"), time.Now())
+	if out.OK || out.ExitCode != ExitError || out.Error != "no target" {
+		t.Errorf("ErrorOutput = %+v", out)
+	}
+}
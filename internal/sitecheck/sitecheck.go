@@ -0,0 +1,333 @@
+package sitecheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CheckType is a kind of reachability check.
+type CheckType string
+
+// Supported check types.
+const (
+	CheckHTTP     CheckType = "http"
+	CheckDNS      CheckType = "dns"
+	CheckTCP      CheckType = "tcp"
+	CheckRedirect CheckType = "redirect"
+)
+
+// CheckTypes lists all check types in the order they run with --type all.
+var CheckTypes = []CheckType{CheckDNS, CheckTCP, CheckRedirect, CheckHTTP}
+
+// ErrUnsupported is returned by a provider that can't run a check type.
+var ErrUnsupported = errors.New("check type not supported by provider")
+
+// ErrorCategory is the stage at which a failed check failed.
+type ErrorCategory string
+
+// Error categories of failed results.
+const (
+	ErrorDNS     ErrorCategory = "dns"     // name did not resolve
+	ErrorConnect ErrorCategory = "connect" // TCP connect failed or timed out
+	ErrorTLS     ErrorCategory = "tls"     // handshake or certificate error
+	ErrorHTTP    ErrorCategory = "http"    // bad or unexpected status, bad redirect
+	ErrorLatency ErrorCategory = "latency" // succeeded but slower than MaxLatency
+)
+
+// Result is the outcome of one check from one location.
+type Result struct {
+	Provider string        `json:"provider"`
+	Location string        `json:"location"`
+	NodeIP   string        `json:"node_ip,omitempty"` // address of the probing node, if known
+	Type     CheckType     `json:"type"`
+	OK       bool          `json:"ok"`
+	Latency  time.Duration `json:"latency,omitempty"`
+	IP       string        `json:"ip,omitempty"`     // target address the probe reached or resolved
+	Detail   string        `json:"detail,omitempty"` // status code, resolved IPs, redirect target
+	Error    string        `json:"error,omitempty"`
+	Category ErrorCategory `json:"category,omitempty"` // set on failed results
+}
+
+// Provider runs checks from one or more locations.
+type Provider interface {
+	// Name returns the provider name (e.g. "check-host", "direct").
+	Name() string
+
+	// Check runs a check against target and returns one result per location.
+	// It returns ErrUnsupported if the provider can't run checkType.
+	Check(ctx context.Context, checkType CheckType, target string) ([]Result, error)
+}
+
+// Config configures Run.
+type Config struct {
+	// Target is a host name or URL (e.g. "example.com", "https://www.example.com")
+	Target string
+
+	// Types are the checks to run (default: http)
+	Types []CheckType
+
+	// Providers run the checks (default: check-host.net)
+	Providers []Provider
+
+	// ExpectStatus is the HTTP status http checks must return (0: any 2xx/3xx).
+	// Use a DirectProvider with NoFollowRedirects to expect a 3xx.
+	ExpectStatus int
+
+	// MaxLatency fails otherwise successful checks slower than this (0: no limit)
+	MaxLatency time.Duration
+}
+
+// Report holds the results of a Run.
+type Report struct {
+	Target  string   `json:"target"`
+	Results []Result `json:"results"`
+}
+
+// OK returns true if every result succeeded.
+func (r *Report) OK() bool {
+	for _, res := range r.Results {
+		if !res.OK {
+			return false
+		}
+	}
+	return len(r.Results) > 0
+}
+
+// Run runs every check type with every provider.
+// A provider that doesn't support a check type is skipped for that type.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.Target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+	if len(cfg.Types) == 0 {
+		cfg.Types = []CheckType{CheckHTTP}
+	}
+	if len(cfg.Providers) == 0 {
+		cfg.Providers = []Provider{NewCheckHostProvider()}
+	}
+
+	report := &Report{Target: cfg.Target}
+	for _, checkType := range cfg.Types {
+		for _, provider := range cfg.Providers {
+			results, err := provider.Check(ctx, checkType, cfg.Target)
+			if errors.Is(err, ErrUnsupported) {
+				continue
+			}
+			if err != nil {
+				report.Results = append(report.Results, Result{
+					Provider: provider.Name(),
+					Type:     checkType,
+					Error:    err.Error(),
+					Category: CategorizeError(checkType, err.Error()),
+				})
+				continue
+			}
+			for i := range results {
+				results[i].Provider = provider.Name()
+				results[i].Type = checkType
+				cfg.evaluate(&results[i])
+				if !results[i].OK && results[i].Category == "" {
+					results[i].Category = CategorizeError(checkType, results[i].Error)
+				}
+			}
+			report.Results = append(report.Results, results...)
+		}
+	}
+	return report, nil
+}
+
+// evaluate applies the expected status and latency threshold to a result.
+func (cfg Config) evaluate(r *Result) {
+	if cfg.ExpectStatus != 0 && r.Type == CheckHTTP {
+		// No status code means no response: keep the provider's verdict
+		if code := statusCode(r.Detail); code != 0 {
+			if code == cfg.ExpectStatus {
+				r.OK = true
+				r.Error = ""
+				r.Category = ""
+			} else {
+				r.OK = false
+				r.Error = fmt.Sprintf("HTTP %d, want %d", code, cfg.ExpectStatus)
+				r.Category = ErrorHTTP
+			}
+		}
+	}
+
+	if cfg.MaxLatency > 0 && r.OK && r.Latency > cfg.MaxLatency {
+		r.OK = false
+		r.Error = fmt.Sprintf("latency %dms exceeds %dms", r.Latency.Milliseconds(), cfg.MaxLatency.Milliseconds())
+		r.Category = ErrorLatency
+	}
+}
+
+// CategorizeError classifies a failed check's error message. Providers report
+// errors as text (check-host.net and agents over the wire), so this matches
+// on the message and falls back to the check type's own stage.
+func CategorizeError(checkType CheckType, msg string) ErrorCategory {
+	m := strings.ToLower(msg)
+	contains := func(subs ...string) bool {
+		for _, sub := range subs {
+			if strings.Contains(m, sub) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains("no such host", "lookup ", "server misbehaving", "nxdomain", "no records"):
+		return ErrorDNS
+	case contains("tls", "x509", "certificate", "ssl"):
+		return ErrorTLS
+	case contains("connection refused", "connection reset", "dial ", "connect:", "i/o timeout",
+		"timed out", "timeout", "unreachable", "no route to host"):
+		return ErrorConnect
+	case contains("http ", "status", "redirect", "want "):
+		return ErrorHTTP
+	}
+
+	switch checkType {
+	case CheckDNS:
+		return ErrorDNS
+	case CheckTCP:
+		return ErrorConnect
+	default:
+		return ErrorHTTP
+	}
+}
+
+// statusCode returns the leading HTTP status code of an http result's
+// Detail ("200 OK", "301 Moved Permanently"), or 0.
+func statusCode(detail string) int {
+	fields := strings.Fields(detail)
+	if len(fields) == 0 {
+		return 0
+	}
+	code, err := strconv.Atoi(fields[0])
+	if err != nil || code < 100 || code > 599 {
+		return 0
+	}
+	return code
+}
+
+// ParseCheckTypes parses a comma-separated list of check types ("all" for every type).
+func ParseCheckTypes(s string) ([]CheckType, error) {
+	if s == "" {
+		return []CheckType{CheckHTTP}, nil
+	}
+	if s == "all" {
+		return CheckTypes, nil
+	}
+
+	var types []CheckType
+	for _, part := range strings.Split(s, ",") {
+		t := CheckType(strings.TrimSpace(part))
+		switch t {
+		case CheckHTTP, CheckDNS, CheckTCP, CheckRedirect:
+			types = append(types, t)
+		default:
+			return nil, fmt.Errorf("unknown check type %q (valid: http, dns, tcp, redirect, all)", t)
+		}
+	}
+	return types, nil
+}
+
+// targetURL returns target as an https URL.
+func targetURL(target string) string {
+	if strings.Contains(target, "://") {
+		return target
+	}
+	return "https://" + target
+}
+
+// targetHost returns the host name of target (without port).
+func targetHost(target string) string {
+	u, err := url.Parse(targetURL(target))
+	if err != nil {
+		return target
+	}
+	return u.Hostname()
+}
+
+// targetHostPort returns host:port for target, defaulting to 443 (or 80 for http).
+func targetHostPort(target string) string {
+	u, err := url.Parse(targetURL(target))
+	if err != nil {
+		return target
+	}
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "443"
+	if u.Scheme == "http" {
+		port = "80"
+	}
+	return u.Hostname() + ":" + port
+}
+
+// PrintReport prints results grouped by check type.
+func PrintReport(report *Report) {
+	fmt.Printf("Site check: %s\n", report.Target)
+
+	var lastType CheckType
+	failed := 0
+	for _, r := range report.Results {
+		if r.Type != lastType {
+			fmt.Printf("\n%s:\n", strings.ToUpper(string(r.Type)))
+			lastType = r.Type
+		}
+
+		status := "OK  "
+		if !r.OK {
+			status = "FAIL"
+			failed++
+		}
+
+		location := r.Location
+		if location == "" {
+			location = "-"
+		}
+
+		line := fmt.Sprintf("  %s  %-12s %-32s", status, r.Provider, location)
+		if r.Latency > 0 {
+			line += fmt.Sprintf(" %6dms", r.Latency.Milliseconds())
+		}
+		if r.Detail != "" {
+			line += "  " + r.Detail
+		}
+		if r.Error != "" {
+			line += "  " + r.Error
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%d of %d checks failed\n", failed, len(report.Results))
+	} else {
+		fmt.Printf("All %d checks passed\n", len(report.Results))
+	}
+}
+
+// NewProvider creates a provider by name: "check-host", "direct" or "agent".
+// The agent provider needs at least one agent URL.
+func NewProvider(name string, agents []string) (Provider, error) {
+	switch name {
+	case "check-host", "checkhost":
+		return NewCheckHostProvider(), nil
+	case "direct", "local":
+		return &DirectProvider{}, nil
+	case "agent", "agents":
+		if len(agents) == 0 {
+			return nil, fmt.Errorf("agent provider needs at least one agent URL")
+		}
+		return &AgentProvider{Agents: agents}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (valid: check-host, direct, agent)", name)
+	}
+}
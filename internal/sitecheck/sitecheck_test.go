@@ -0,0 +1,168 @@
+package sitecheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseCheckHostResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		checkType CheckType
+		raw       string
+		wantOK    bool
+		detail    string
+	}{
+		{"http ok", CheckHTTP, `[[1, 0.13, "OK", "200", "1.2.3.4"]]`, true, "200 OK"},
+		{"http fail", CheckHTTP, `[[0, 10.0, "Connection timed out", null, null]]`, false, "<nil> Connection timed out"},
+		{"pending", CheckHTTP, `null`, false, ""},
+		{"dns ok", CheckDNS, `[{"A": ["1.2.3.4"], "AAAA": ["::1"], "TTL": 300}]`, true, "1.2.3.4, ::1"},
+		{"dns empty", CheckDNS, `[{"A": [], "AAAA": [], "TTL": null}]`, false, ""},
+		{"tcp ok", CheckTCP, `[{"time": 0.03, "address": "1.2.3.4"}]`, true, "1.2.3.4"},
+		{"tcp fail", CheckTCP, `[{"error": "Connection refused"}]`, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCheckHostResult(tt.checkType, json.RawMessage(tt.raw))
+			if got.OK != tt.wantOK {
+				t.Errorf("OK = %v, want %v (result %+v)", got.OK, tt.wantOK, got)
+			}
+			if tt.detail != "" && got.Detail != tt.detail {
+				t.Errorf("Detail = %q, want %q", got.Detail, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCheckHostProvider(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/check-http":
+			if r.URL.Query().Get("host") != "https://example.com" {
+				t.Errorf("host = %q", r.URL.Query().Get("host"))
+			}
+			_, _ = fmt.Fprint(w, `{"ok":1,"request_id":"abc","nodes":{
+				"us1.node.check-host.net":["us","USA","Los Angeles","1.1.1.1","AS1"],
+				"de1.node.check-host.net":["de","Germany","Frankfurt","2.2.2.2","AS2"]}}`)
+		case "/check-result/abc":
+			polls++
+			if polls == 1 {
+				_, _ = fmt.Fprint(w, `{"us1.node.check-host.net":[[1,0.2,"OK","200","9.9.9.9"]],"de1.node.check-host.net":null}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"us1.node.check-host.net":[[1,0.2,"OK","200","9.9.9.9"]],"de1.node.check-host.net":[[0,10,"Timeout",null,null]]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := &CheckHostProvider{BaseURL: server.URL, PollInterval: 10 * time.Millisecond, Timeout: 5 * time.Second}
+	results, err := provider.Check(context.Background(), CheckHTTP, "example.com")
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if polls != 2 {
+		t.Errorf("polls = %d, want 2", polls)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	// Sorted by node name: de1 before us1
+	if results[0].Location != "Germany, Frankfurt (de1)" || results[0].OK {
+		t.Errorf("results[0] = %+v", results[0])
+	}
+	if results[1].Location != "USA, Los Angeles (us1)" || !results[1].OK {
+		t.Errorf("results[1] = %+v", results[1])
+	}
+	if results[1].NodeIP != "1.1.1.1" || results[1].IP != "9.9.9.9" {
+		t.Errorf("results[1] node IP %q, IP %q; want 1.1.1.1, 9.9.9.9", results[1].NodeIP, results[1].IP)
+	}
+
+	if _, err := provider.Check(context.Background(), CheckRedirect, "example.com"); err != ErrUnsupported {
+		t.Errorf("redirect check error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestAgentProviderWithDirectAgent(t *testing.T) {
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer site.Close()
+
+	agent := httptest.NewServer(AgentHandler(&DirectProvider{Location: "test-agent"}))
+	defer agent.Close()
+
+	report, err := Run(context.Background(), Config{
+		Target:    site.URL,
+		Types:     []CheckType{CheckHTTP, CheckTCP},
+		Providers: []Provider{&AgentProvider{Agents: []string{agent.URL, "http://127.0.0.1:1"}}},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// Two check types x (one reachable agent + one unreachable agent)
+	if len(report.Results) != 4 {
+		t.Fatalf("got %d results, want 4: %+v", len(report.Results), report.Results)
+	}
+	for _, r := range report.Results {
+		if r.Provider != "agent" {
+			t.Errorf("Provider = %q, want agent", r.Provider)
+		}
+		if r.Location == "test-agent" && !r.OK {
+			t.Errorf("agent %s check failed: %+v", r.Type, r)
+		}
+		if r.Location != "test-agent" && r.OK {
+			t.Errorf("unreachable agent reported OK: %+v", r)
+		}
+	}
+	if report.OK() {
+		t.Error("report.OK() = true with an unreachable agent")
+	}
+}
+
+func TestParseCheckTypes(t *testing.T) {
+	types, err := ParseCheckTypes("dns, http")
+	if err != nil || len(types) != 2 || types[0] != CheckDNS || types[1] != CheckHTTP {
+		t.Errorf("ParseCheckTypes(dns, http) = %v, %v", types, err)
+	}
+	if types, _ := ParseCheckTypes("all"); len(types) != len(CheckTypes) {
+		t.Errorf("ParseCheckTypes(all) = %v", types)
+	}
+	if _, err := ParseCheckTypes("ping"); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
+
+func TestCategorizeError(t *testing.T) {
+	tests := []struct {
+		checkType CheckType
+		msg       string
+		want      ErrorCategory
+	}{
+		{CheckHTTP, `Get "https://x.invalid": dial tcp: lookup x.invalid: no such host`, ErrorDNS},
+		{CheckHTTP, `Get "https://x.com": tls: failed to verify certificate: x509: certificate has expired`, ErrorTLS},
+		{CheckHTTP, `Get "https://x.com": dial tcp 1.2.3.4:443: connect: connection refused`, ErrorConnect},
+		{CheckHTTP, "Connection timed out", ErrorConnect},
+		{CheckHTTP, "HTTP 503", ErrorHTTP},
+		{CheckRedirect, "https://x.com/ does not redirect (HTTP 200)", ErrorHTTP},
+		{CheckDNS, "no records", ErrorDNS},
+		{CheckTCP, "Connection refused", ErrorConnect},
+		{CheckTCP, "something odd", ErrorConnect},
+	}
+
+	for _, tt := range tests {
+		if got := CategorizeError(tt.checkType, tt.msg); got != tt.want {
+			t.Errorf("CategorizeError(%s, %q) = %q, want %q", tt.checkType, tt.msg, got, tt.want)
+		}
+	}
+}
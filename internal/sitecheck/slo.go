@@ -0,0 +1,132 @@
+package sitecheck
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/projectstate"
+	"github.com/joeblew999/xplat/internal/stats"
+)
+
+// SLOStatus is an SLO's compliance over its window, from the history
+// recorded for its target.
+type SLOStatus struct {
+	Name          string        `json:"name"`
+	Endpoint      string        `json:"endpoint"`
+	Process       string        `json:"process,omitempty"`
+	Target        float64       `json:"target"` // availability percent
+	LatencyTarget time.Duration `json:"latency_target,omitempty"`
+	Window        time.Duration `json:"window"`
+	Checks        int           `json:"checks"`
+	Failures      int           `json:"failures"`
+	Availability  float64       `json:"availability"` // percent
+	P99           time.Duration `json:"p99"`
+
+	// ErrorBudget is the share of allowed failures left: 1 untouched,
+	// 0 used up, below 0 overspent
+	ErrorBudget float64 `json:"error_budget"`
+
+	Met bool `json:"met"`
+}
+
+// SLOTargets returns a sitecheck target per SLO in the manifest, using the
+// SLO's latency target as the target's MaxLatency. Process endpoints are
+// local, so they are probed directly.
+func SLOTargets(m *manifest.Manifest) []TargetConfig {
+	targets := make([]TargetConfig, 0, len(m.SLOs))
+	for _, slo := range m.SLOs {
+		t := TargetConfig{
+			Name:       slo.Name,
+			URL:        m.SLOEndpoint(slo),
+			Types:      []string{string(CheckHTTP)},
+			MaxLatency: slo.LatencyTarget(),
+		}
+		if slo.Endpoint == "" {
+			t.Providers = []string{"direct"}
+		}
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// EvaluateSLO computes an SLO's compliance from history records. A check
+// only passes if it was OK and within the latency target, so history
+// recorded without a latency threshold is still judged against the SLO.
+func EvaluateSLO(slo manifest.SLOConfig, endpoint string, records []Record) SLOStatus {
+	s := SLOStatus{
+		Name:          slo.Name,
+		Endpoint:      endpoint,
+		Process:       slo.Process,
+		Target:        slo.Availability,
+		LatencyTarget: slo.LatencyTarget(),
+		Window:        slo.WindowDuration(),
+		Checks:        len(records),
+	}
+
+	var latencies []time.Duration
+	for _, rec := range records {
+		if !rec.OK || (s.LatencyTarget > 0 && rec.Latency > s.LatencyTarget) {
+			s.Failures++
+		}
+		if rec.Latency > 0 {
+			latencies = append(latencies, rec.Latency)
+		}
+	}
+	if s.Checks == 0 {
+		return s
+	}
+
+	s.Availability = 100 * float64(s.Checks-s.Failures) / float64(s.Checks)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	s.P99 = stats.Percentile(latencies, 99)
+
+	allowed := (100 - s.Target) / 100 * float64(s.Checks)
+	switch {
+	case allowed > 0:
+		s.ErrorBudget = 1 - float64(s.Failures)/allowed
+	case s.Failures == 0:
+		s.ErrorBudget = 1
+	}
+	s.Met = s.Availability >= s.Target
+	return s
+}
+
+// ManifestSLOStatus evaluates every SLO in project p's manifest against the
+// history recorded under its name by 'xplat site check --slo'.
+func ManifestSLOStatus(p *projectstate.Project, m *manifest.Manifest, now time.Time) ([]SLOStatus, error) {
+	statuses := make([]SLOStatus, 0, len(m.SLOs))
+	for _, slo := range m.SLOs {
+		records, err := LoadHistory(ProjectHistoryPath(p, slo.Name), now.Add(-slo.WindowDuration()))
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, EvaluateSLO(slo, m.SLOEndpoint(slo), records))
+	}
+	return statuses, nil
+}
+
+// Summary returns a one-line compliance summary, e.g. "99.95% of 99.9% (budget 50%)".
+func (s SLOStatus) Summary() string {
+	if s.Checks == 0 {
+		return fmt.Sprintf("no data (target %g%%)", s.Target)
+	}
+	return fmt.Sprintf("%.2f%% of %g%% (budget %.0f%%)", s.Availability, s.Target, 100*s.ErrorBudget)
+}
+
+// PrintSLOStatus prints SLO compliance as a table.
+func PrintSLOStatus(statuses []SLOStatus) {
+	fmt.Printf("%-4s  %-16s %9s %9s %8s %7s %8s  %s\n", "", "SLO", "TARGET", "AVAIL", "BUDGET", "CHECKS", "P99", "ENDPOINT")
+	for _, s := range statuses {
+		status := "OK  "
+		switch {
+		case s.Checks == 0:
+			status = "----"
+		case !s.Met:
+			status = "MISS"
+		}
+		fmt.Printf("%-4s  %-16s %8g%% %8.2f%% %7.0f%% %7d %6dms  %s\n",
+			status, s.Name, s.Target, s.Availability, 100*s.ErrorBudget, s.Checks, s.P99.Milliseconds(), s.Endpoint)
+	}
+}
@@ -0,0 +1,56 @@
+package sitecheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/manifest"
+)
+
+func TestEvaluateSLO(t *testing.T) {
+	slo := manifest.SLOConfig{Name: "api", Availability: 90, Latency: "500ms"}
+	var records []Record
+	for i := 0; i < 20; i++ {
+		records = append(records, Record{Result: Result{OK: true, Latency: 100 * time.Millisecond}})
+	}
+	// One failure and one slow success: both count against the SLO
+	records[0].OK = false
+	records[1].Latency = 900 * time.Millisecond
+
+	s := EvaluateSLO(slo, "https://api.example.com", records)
+	if s.Checks != 20 || s.Failures != 2 || s.Availability != 90 || !s.Met {
+		t.Errorf("status = %+v", s)
+	}
+	if s.ErrorBudget != 0 {
+		t.Errorf("error budget = %v, want 0 (2 of 2 allowed failures used)", s.ErrorBudget)
+	}
+
+	slo.Availability = 99
+	if s := EvaluateSLO(slo, "", records); s.Met || s.ErrorBudget >= 0 {
+		t.Errorf("status = %+v, want missed with overspent budget", s)
+	}
+
+	if s := EvaluateSLO(slo, "", nil); s.Met || s.Summary() != "no data (target 99%)" {
+		t.Errorf("no history: status = %+v, summary %q", s, s.Summary())
+	}
+}
+
+func TestSLOTargets(t *testing.T) {
+	m := &manifest.Manifest{
+		Processes: map[string]manifest.ProcessConfig{"api": {Port: 8080, HealthPath: "/health"}},
+		SLOs: []manifest.SLOConfig{
+			{Name: "api", Process: "api", Availability: 99.9, Latency: "250ms"},
+			{Name: "site", Endpoint: "https://www.example.com", Availability: 99},
+		},
+	}
+	targets := SLOTargets(m)
+	if len(targets) != 2 {
+		t.Fatalf("targets = %+v", targets)
+	}
+	if got := targets[0]; got.URL != "http://localhost:8080/health" || got.MaxLatency != 250*time.Millisecond || len(got.Providers) != 1 || got.Providers[0] != "direct" {
+		t.Errorf("process target = %+v", got)
+	}
+	if got := targets[1]; got.URL != "https://www.example.com" || got.MaxLatency != 0 || got.Providers != nil {
+		t.Errorf("endpoint target = %+v", got)
+	}
+}
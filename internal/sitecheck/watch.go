@@ -0,0 +1,330 @@
+package sitecheck
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/projectstate"
+	"github.com/joeblew999/xplat/internal/stats"
+)
+
+// Record is a Result stored in the history file.
+type Record struct {
+	Time   time.Time `json:"time"`
+	Target string    `json:"target"`
+	Result
+}
+
+// HistoryPath returns the default history file for a target in the
+// current project: ~/.xplat/state/<project>/sitecheck/<host>.jsonl
+func HistoryPath(target string) string {
+	return ProjectHistoryPath(projectstate.Current(), target)
+}
+
+// ProjectHistoryPath returns the history file for a target in project p.
+func ProjectHistoryPath(p *projectstate.Project, target string) string {
+	name := strings.ReplaceAll(targetHost(target), ":", "_")
+	return filepath.Join(p.ToolDir("sitecheck"), name+".jsonl")
+}
+
+// AppendHistory appends a report's results to a JSONL history file.
+func AppendHistory(path string, report *Report, at time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), config.DefaultDirPerms); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, config.DefaultFilePerms)
+	if err != nil {
+		return fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	enc := json.NewEncoder(f)
+	for _, r := range report.Results {
+		if err := enc.Encode(Record{Time: at, Target: report.Target, Result: r}); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadHistory reads records newer than since from a JSONL history file.
+// A missing file returns no records. Malformed lines are skipped.
+func LoadHistory(path string, since time.Time) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Time.Before(since) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	return records, nil
+}
+
+// Stats summarizes availability and latency for one check type and provider.
+type Stats struct {
+	Provider     string        `json:"provider"`
+	Type         CheckType     `json:"type"`
+	Checks       int           `json:"checks"`
+	Failures     int           `json:"failures"`
+	Availability float64       `json:"availability"` // percent
+	P50          time.Duration `json:"p50"`
+	P90          time.Duration `json:"p90"`
+	P99          time.Duration `json:"p99"`
+}
+
+// ComputeStats groups records by provider and check type.
+// Latency percentiles only include successful checks.
+func ComputeStats(records []Record) []Stats {
+	type key struct {
+		provider  string
+		checkType CheckType
+	}
+	groups := make(map[key][]Record)
+	for _, rec := range records {
+		k := key{rec.Provider, rec.Type}
+		groups[k] = append(groups[k], rec)
+	}
+
+	out := make([]Stats, 0, len(groups))
+	for k, recs := range groups {
+		s := Stats{Provider: k.provider, Type: k.checkType, Checks: len(recs)}
+
+		var latencies []time.Duration
+		for _, rec := range recs {
+			if !rec.OK {
+				s.Failures++
+				continue
+			}
+			if rec.Latency > 0 {
+				latencies = append(latencies, rec.Latency)
+			}
+		}
+		s.Availability = 100 * float64(s.Checks-s.Failures) / float64(s.Checks)
+
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		s.P50 = stats.Percentile(latencies, 50)
+		s.P90 = stats.Percentile(latencies, 90)
+		s.P99 = stats.Percentile(latencies, 99)
+
+		out = append(out, s)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Provider < out[j].Provider
+	})
+	return out
+}
+
+// WatchConfig configures a Watcher.
+type WatchConfig struct {
+	Config
+
+	// Interval between runs (default: 10m)
+	Interval time.Duration
+
+	// HistoryFile is the JSONL history path (default: HistoryPath(Target))
+	HistoryFile string
+
+	// Window is the rolling window for stats (default: 24h)
+	Window time.Duration
+
+	// Alerts receives an alert when a run starts failing (optional)
+	Alerts *WebhookSink
+}
+
+// Status is served by the Watcher's status endpoint.
+type Status struct {
+	Target   string    `json:"target"`
+	OK       bool      `json:"ok"`
+	LastRun  time.Time `json:"last_run"`
+	NextRun  time.Time `json:"next_run"`
+	Window   string    `json:"window"`
+	Stats    []Stats   `json:"stats"`
+	Failures []Result  `json:"failures,omitempty"` // failed results from the last run
+}
+
+// Watcher runs checks on a schedule and records history.
+type Watcher struct {
+	cfg WatchConfig
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewWatcher creates a watcher with defaults applied.
+func NewWatcher(cfg WatchConfig) *Watcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 24 * time.Hour
+	}
+	if cfg.HistoryFile == "" {
+		cfg.HistoryFile = HistoryPath(cfg.Target)
+	}
+	return &Watcher{
+		cfg:    cfg,
+		status: Status{Target: cfg.Target, Window: cfg.Window.String()},
+	}
+}
+
+// Run checks immediately, then every Interval until ctx is done.
+func (w *Watcher) Run(ctx context.Context) error {
+	log.Printf("Site watch: %s every %s (history: %s)", w.cfg.Target, w.cfg.Interval, w.cfg.HistoryFile)
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.RunOnce(ctx); err != nil {
+			log.Printf("Site watch: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs one round of checks, records it and refreshes the status.
+func (w *Watcher) RunOnce(ctx context.Context) error {
+	now := time.Now()
+	report, err := Run(ctx, w.cfg.Config)
+	if err != nil {
+		return err
+	}
+
+	records, err := LoadHistory(w.cfg.HistoryFile, now.Add(-w.cfg.Window))
+	if err != nil {
+		return err
+	}
+
+	// Alert on the transition to failing, with latency deltas against history
+	wasOK := w.Status().OK || w.Status().LastRun.IsZero()
+	if w.cfg.Alerts != nil && wasOK && !report.OK() {
+		if err := w.cfg.Alerts.Send(ctx, NewAlert(report, ComputeStats(records), now)); err != nil {
+			log.Printf("Site watch: %v", err)
+		}
+	}
+
+	if err := AppendHistory(w.cfg.HistoryFile, report, now); err != nil {
+		return err
+	}
+	for _, r := range report.Results {
+		records = append(records, Record{Time: now, Target: report.Target, Result: r})
+	}
+
+	var failures []Result
+	for _, r := range report.Results {
+		if !r.OK {
+			failures = append(failures, r)
+		}
+	}
+
+	w.mu.Lock()
+	w.status.OK = report.OK()
+	w.status.LastRun = now
+	w.status.NextRun = now.Add(w.cfg.Interval)
+	w.status.Stats = ComputeStats(records)
+	w.status.Failures = failures
+	w.mu.Unlock()
+
+	log.Printf("Site watch: %s - %d checks, %d failed", w.cfg.Target, len(report.Results), len(failures))
+	return nil
+}
+
+// Status returns a copy of the current status.
+func (w *Watcher) Status() Status {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.status
+}
+
+// Handler serves the watcher status:
+//
+//	GET /status  JSON status with rolling stats
+//	GET /health  200 if the last run passed, 503 otherwise (for readiness probes)
+func (w *Watcher) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(w.Status())
+	})
+	mux.HandleFunc("/health", func(rw http.ResponseWriter, r *http.Request) {
+		status := w.Status()
+		if status.LastRun.IsZero() || !status.OK {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = rw.Write([]byte("failing"))
+			return
+		}
+		_, _ = rw.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// RunWatch runs a Watcher, serving its status on addr when addr is non-empty.
+func RunWatch(ctx context.Context, cfg WatchConfig, addr string) error {
+	w := NewWatcher(cfg)
+
+	if addr != "" {
+		server := &http.Server{Addr: addr, Handler: w.Handler()}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+		go func() {
+			log.Printf("Site watch: status on http://localhost%s/status", addr)
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Site watch: status server failed: %v", err)
+			}
+		}()
+	}
+
+	return w.Run(ctx)
+}
+
+// PrintStats prints rolling stats as a table.
+func PrintStats(stats []Stats) {
+	fmt.Printf("%-10s %-12s %7s %7s %9s %8s %8s %8s\n", "TYPE", "PROVIDER", "CHECKS", "FAILED", "AVAIL", "P50", "P90", "P99")
+	for _, s := range stats {
+		fmt.Printf("%-10s %-12s %7d %7d %8.2f%% %6dms %6dms %6dms\n",
+			s.Type, s.Provider, s.Checks, s.Failures, s.Availability,
+			s.P50.Milliseconds(), s.P90.Milliseconds(), s.P99.Milliseconds())
+	}
+}
@@ -0,0 +1,123 @@
+package sitecheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeProvider returns a fixed result for every check.
+type fakeProvider struct {
+	ok      bool
+	latency time.Duration
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) Check(ctx context.Context, checkType CheckType, target string) ([]Result, error) {
+	return []Result{{Location: "here", OK: p.ok, Latency: p.latency}}, nil
+}
+
+func TestComputeStats(t *testing.T) {
+	var records []Record
+	for i := 1; i <= 10; i++ {
+		records = append(records, Record{Result: Result{
+			Provider: "direct",
+			Type:     CheckHTTP,
+			OK:       i != 10,
+			Latency:  time.Duration(i) * time.Millisecond,
+		}})
+	}
+	records = append(records, Record{Result: Result{Provider: "direct", Type: CheckDNS, OK: true, Latency: time.Millisecond}})
+
+	stats := ComputeStats(records)
+	if len(stats) != 2 {
+		t.Fatalf("got %d stats groups, want 2", len(stats))
+	}
+
+	// Sorted by type: dns before http
+	httpStats := stats[1]
+	if httpStats.Type != CheckHTTP || httpStats.Checks != 10 || httpStats.Failures != 1 {
+		t.Errorf("http stats = %+v", httpStats)
+	}
+	if httpStats.Availability != 90 {
+		t.Errorf("Availability = %v, want 90", httpStats.Availability)
+	}
+	// Successful latencies are 1..9ms
+	if httpStats.P50 != 5*time.Millisecond || httpStats.P90 != 9*time.Millisecond || httpStats.P99 != 9*time.Millisecond {
+		t.Errorf("percentiles = %v/%v/%v, want 5ms/9ms/9ms", httpStats.P50, httpStats.P90, httpStats.P99)
+	}
+}
+
+func TestHistoryRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now()
+
+	old := &Report{Target: "example.com", Results: []Result{{Provider: "direct", Type: CheckHTTP, OK: false}}}
+	recent := &Report{Target: "example.com", Results: []Result{{Provider: "direct", Type: CheckHTTP, OK: true, Latency: time.Second}}}
+
+	if err := AppendHistory(path, old, now.Add(-48*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendHistory(path, recent, now); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadHistory(path, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || !records[0].OK || records[0].Latency != time.Second || records[0].Target != "example.com" {
+		t.Errorf("LoadHistory() = %+v", records)
+	}
+
+	missing, err := LoadHistory(filepath.Join(t.TempDir(), "none.jsonl"), time.Time{})
+	if err != nil || missing != nil {
+		t.Errorf("LoadHistory(missing) = %v, %v", missing, err)
+	}
+}
+
+func TestWatcherStatus(t *testing.T) {
+	provider := &fakeProvider{ok: true, latency: 20 * time.Millisecond}
+	w := NewWatcher(WatchConfig{
+		Config:      Config{Target: "example.com", Providers: []Provider{provider}},
+		HistoryFile: filepath.Join(t.TempDir(), "history.jsonl"),
+	})
+	handler := w.Handler()
+
+	health := func() int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		return rec.Code
+	}
+
+	if code := health(); code != http.StatusServiceUnavailable {
+		t.Errorf("health before first run = %d, want 503", code)
+	}
+
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if code := health(); code != http.StatusOK {
+		t.Errorf("health after passing run = %d, want 200", code)
+	}
+
+	provider.ok = false
+	if err := w.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if code := health(); code != http.StatusServiceUnavailable {
+		t.Errorf("health after failing run = %d, want 503", code)
+	}
+
+	status := w.Status()
+	if len(status.Stats) != 1 || status.Stats[0].Checks != 2 || status.Stats[0].Availability != 50 {
+		t.Errorf("status stats = %+v", status.Stats)
+	}
+	if len(status.Failures) != 1 {
+		t.Errorf("status failures = %+v", status.Failures)
+	}
+}
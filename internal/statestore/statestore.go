@@ -0,0 +1,258 @@
+// Package statestore writes xplat's JSON state files crash-safely.
+//
+// State files (poll state, receive state, sitecheck state, previews, token
+// records) are rewritten often by long-running processes. A plain
+// os.WriteFile that is interrupted leaves a truncated file, which then fails
+// to parse on the next start. This package provides:
+//
+//   - WriteFile: atomic writes (temp file in the same dir, fsync, rename)
+//   - Marshal / Unmarshal: a "schema_version" key on the top-level object,
+//     with migration hooks that upgrade older files as they are read
+//   - Store: a typed state file combining both, with optional locking for
+//     read-modify-write cycles shared between processes
+//
+// Files written before versioning was added have no schema_version and are
+// read as version 1.
+package statestore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+)
+
+// VersionKey is the top-level key holding a file's schema version.
+const VersionKey = "schema_version"
+
+// WriteFile writes data to path atomically: readers see the old or the new
+// contents, never a partial file. Parent directories are created.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, config.DefaultDirPerms); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	// Only still there if something below failed
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Migration upgrades a decoded document by one schema version, in place.
+// Numbers are json.Number, so integers survive the round trip.
+type Migration func(doc map[string]interface{}) error
+
+// Marshal encodes v as indented JSON with the schema version as its first
+// key. v must encode to a JSON object.
+func Marshal(v interface{}, version int) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return nil, fmt.Errorf("state must be a JSON object, got %T", v)
+	}
+	head := fmt.Sprintf("{\n  %q: %d", VersionKey, version)
+	body := bytes.TrimSpace(data[1:])
+	if string(body) == "}" {
+		return []byte(head + "\n}\n"), nil
+	}
+	return []byte(head + ",\n  " + string(body) + "\n"), nil
+}
+
+// Unmarshal decodes data into v, first running the migrations needed to
+// bring it to version. migrations[i] upgrades version i+1 to i+2. A file
+// newer than version is an error, so an old binary never drops fields it
+// does not know.
+func Unmarshal(data []byte, v interface{}, version int, migrations []Migration) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return err
+	}
+
+	from := 1
+	if raw, ok := doc[VersionKey]; ok {
+		n, ok := raw.(json.Number)
+		if !ok {
+			return fmt.Errorf("invalid %s %v", VersionKey, raw)
+		}
+		parsed, err := strconv.Atoi(n.String())
+		if err != nil {
+			return fmt.Errorf("invalid %s %v", VersionKey, raw)
+		}
+		from = parsed
+	}
+	if from > version {
+		return fmt.Errorf("state is schema version %d, newer than supported version %d; upgrade xplat", from, version)
+	}
+
+	if from < version {
+		for n := from; n < version; n++ {
+			if n-1 >= len(migrations) || migrations[n-1] == nil {
+				return fmt.Errorf("no migration from schema version %d to %d", n, n+1)
+			}
+			if err := migrations[n-1](doc); err != nil {
+				return fmt.Errorf("failed to migrate state from version %d to %d: %w", n, n+1, err)
+			}
+		}
+		migrated, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		data = migrated
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Store is a JSON state file holding a T.
+type Store[T any] struct {
+	Path string
+
+	// Version is the current schema version (at least 1)
+	Version int
+
+	// Migrations[i] upgrades version i+1 to i+2
+	Migrations []Migration
+
+	// Perm is the file mode; 0 means config.DefaultFilePerms
+	Perm os.FileMode
+
+	// Lock makes Update hold <path>.lock, for state shared between processes
+	Lock bool
+}
+
+// New creates a store for the state file at path.
+func New[T any](path string, version int, migrations ...Migration) *Store[T] {
+	return &Store[T]{Path: path, Version: version, Migrations: migrations}
+}
+
+// Load reads the state. A missing file returns an error that satisfies
+// errors.Is(err, os.ErrNotExist).
+func (s *Store[T]) Load() (*T, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	v := new(T)
+	if err := Unmarshal(data, v, s.version(), s.Migrations); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.Path, err)
+	}
+	return v, nil
+}
+
+// Save writes the state atomically.
+func (s *Store[T]) Save(v *T) error {
+	data, err := Marshal(v, s.version())
+	if err != nil {
+		return err
+	}
+	perm := s.Perm
+	if perm == 0 {
+		perm = config.DefaultFilePerms
+	}
+	return WriteFile(s.Path, data, perm)
+}
+
+// Update loads the state (the zero T when there is none), applies fn and
+// saves the result. With Lock set, no other Update on the same path runs
+// in between. If fn fails nothing is saved.
+func (s *Store[T]) Update(fn func(v *T) error) (*T, error) {
+	if s.Lock {
+		unlock, err := LockFile(s.Path, DefaultLockTimeout)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+	}
+
+	v, err := s.Load()
+	if errors.Is(err, os.ErrNotExist) {
+		v, err = new(T), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(v); err != nil {
+		return nil, err
+	}
+	if err := s.Save(v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *Store[T]) version() int {
+	if s.Version < 1 {
+		return 1
+	}
+	return s.Version
+}
+
+// Lock timing. A lock older than LockStale is assumed to belong to a
+// process that crashed while holding it.
+var (
+	DefaultLockTimeout = 10 * time.Second
+	LockStale          = time.Minute
+)
+
+// LockFile takes an exclusive lock on path by creating <path>.lock, waiting
+// up to timeout. It returns the function that releases the lock.
+func LockFile(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + ".lock"
+	if err := os.MkdirAll(filepath.Dir(lockPath), config.DefaultDirPerms); err != nil {
+		return nil, fmt.Errorf("failed to create lock dir: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > LockStale {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,162 @@
+package statestore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type counterState struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "state.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != content {
+			t.Fatalf("read %q, %v; want %q", data, err, content)
+		}
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("temp files left behind: %v", entries)
+	}
+}
+
+func TestMarshalVersionFirst(t *testing.T) {
+	data, err := Marshal(&counterState{Name: "a", Count: 1}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"schema_version\": 2,\n  \"name\": \"a\",\n  \"count\": 1\n}\n"
+	if string(data) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", data, want)
+	}
+
+	empty, err := Marshal(struct{}{}, 1)
+	if err != nil || !json.Valid(empty) {
+		t.Errorf("Marshal(empty) = %s, %v", empty, err)
+	}
+	if _, err := Marshal([]int{1}, 1); err == nil {
+		t.Error("Marshal(array) should fail")
+	}
+}
+
+func TestUnmarshalMigrations(t *testing.T) {
+	// Version 1 called the field "total"; version 2 renamed it to "count"
+	migrations := []Migration{func(doc map[string]interface{}) error {
+		doc["count"] = doc["total"]
+		delete(doc, "total")
+		return nil
+	}}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    int64
+		wantErr string
+	}{
+		{"unversioned file is version 1", `{"name":"a","total":9007199254740993}`, 9007199254740993, ""},
+		{"current version", `{"schema_version":2,"count":3}`, 3, ""},
+		{"newer version", `{"schema_version":3,"count":3}`, 0, "upgrade xplat"},
+		{"corrupt", `{"count":`, 0, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got counterState
+			err := Unmarshal([]byte(tt.data), &got, 2, migrations)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.Count != tt.want {
+				t.Errorf("got %+v, %v; want count %d", got, err, tt.want)
+			}
+		})
+	}
+
+	var got counterState
+	if err := Unmarshal([]byte(`{"count":1}`), &got, 3, migrations); err == nil {
+		t.Error("expected an error for a missing migration")
+	}
+}
+
+func TestStoreUpdateLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter.json")
+	store := New[counterState](path, 1)
+	store.Lock = true
+
+	if _, err := store.Load(); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Load() on missing file = %v, want os.ErrNotExist", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.Update(func(s *counterState) error {
+				s.Count++
+				return nil
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := store.Load()
+	if err != nil || got.Count != 20 {
+		t.Errorf("Load() = %+v, %v; want count 20", got, err)
+	}
+
+	// A failed update saves nothing
+	if _, err := store.Update(func(s *counterState) error {
+		s.Count = 0
+		return errors.New("boom")
+	}); err == nil {
+		t.Error("expected the update error")
+	}
+	if got, _ := store.Load(); got.Count != 20 {
+		t.Errorf("count = %d after failed update", got.Count)
+	}
+}
+
+func TestLockFileStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path+".lock", []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LockFile(path, 100*time.Millisecond); err == nil {
+		t.Fatal("expected a timeout on a held lock")
+	}
+
+	old := time.Now().Add(-2 * LockStale)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := LockFile(path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("stale lock not taken over: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lock not released: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+// Package stats holds the summary statistics shared by xplat's history
+// reports (sitecheck watch and SLOs, task telemetry).
+package stats
+
+import "time"
+
+// Percentile returns the nearest-rank p-th percentile of sorted durations,
+// or 0 when there are none.
+func Percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
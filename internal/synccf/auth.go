@@ -216,8 +216,17 @@ func verifyAPIToken(accountID, apiToken string) error {
 
 // saveAllCredentialsToEnv saves all credentials to .env file
 func saveAllCredentialsToEnv(creds CFCredentials) error {
-	envPath := ".env"
+	return saveEnvVars(".env", map[string]string{
+		"CF_ACCOUNT_ID": creds.AccountID,
+		"CF_API_TOKEN":  creds.APIToken,
+		"R2_ACCESS_KEY": creds.R2AccessKey,
+		"R2_SECRET_KEY": creds.R2SecretKey,
+	})
+}
 
+// saveEnvVars updates or appends the non-empty vars in an env file,
+// keeping every other line as is.
+func saveEnvVars(envPath string, keysToUpdate map[string]string) error {
 	// Read existing .env content
 	existingContent := ""
 	if data, err := os.ReadFile(envPath); err == nil {
@@ -228,13 +237,6 @@ func saveAllCredentialsToEnv(creds CFCredentials) error {
 	lines := strings.Split(existingContent, "\n")
 	updated := make(map[string]bool)
 
-	keysToUpdate := map[string]string{
-		"CF_ACCOUNT_ID": creds.AccountID,
-		"CF_API_TOKEN":  creds.APIToken,
-		"R2_ACCESS_KEY": creds.R2AccessKey,
-		"R2_SECRET_KEY": creds.R2SecretKey,
-	}
-
 	for i, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		for key, value := range keysToUpdate {
@@ -247,8 +249,8 @@ func saveAllCredentialsToEnv(creds CFCredentials) error {
 
 	// Append missing keys (only if value is non-empty)
 	var toAppend []string
-	for key, value := range keysToUpdate {
-		if !updated[key] && value != "" {
+	for _, key := range sortedKeys(keysToUpdate) {
+		if value := keysToUpdate[key]; !updated[key] && value != "" {
 			toAppend = append(toAppend, fmt.Sprintf("%s=%s", key, value))
 		}
 	}
@@ -256,7 +258,7 @@ func saveAllCredentialsToEnv(creds CFCredentials) error {
 	// Build final content
 	content := strings.Join(lines, "\n")
 	if len(toAppend) > 0 {
-		if !strings.HasSuffix(content, "\n") {
+		if content != "" && !strings.HasSuffix(content, "\n") {
 			content += "\n"
 		}
 		content += strings.Join(toAppend, "\n") + "\n"
@@ -0,0 +1,492 @@
+package synccf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/cfanalytics"
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/env"
+	"github.com/joeblew999/xplat/internal/notify"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// Budgeted products.
+const (
+	ProductWorkersRequests = "workers_requests"
+	ProductR2Storage       = "r2_storage"
+	ProductPagesBuilds     = "pages_builds"
+)
+
+// BudgetSource is the notification source of budget alerts.
+const BudgetSource = "synccf/budget"
+
+// DefaultDegradedKey is the KV key the Worker checks for degraded mode.
+const DefaultDegradedKey = "degraded"
+
+// DefaultBudgetThresholds are the percentages alerted at.
+var DefaultBudgetThresholds = []int{50, 80, 100}
+
+// BudgetConfig is the monthly budget per product (~/.xplat/config/budgets.yaml):
+//
+//	workers_requests: 10000000   # Requests per calendar month (UTC)
+//	r2_storage_gb: 10            # Stored GB across all buckets
+//	pages_builds: 500            # Deployments per calendar month
+//	thresholds: [50, 80, 100]    # Percentages to alert at (default)
+//	degraded:
+//	  kv_namespace: <id>         # KV namespace bound to the Worker as BUDGET_KV
+//	  at: 100                    # Percentage that switches degraded mode on (default 100)
+//
+// Products without a budget are not measured.
+type BudgetConfig struct {
+	WorkersRequests int64           `yaml:"workers_requests,omitempty"`
+	R2StorageGB     float64         `yaml:"r2_storage_gb,omitempty"`
+	PagesBuilds     int64           `yaml:"pages_builds,omitempty"`
+	Thresholds      []int           `yaml:"thresholds,omitempty"`
+	Degraded        *DegradedConfig `yaml:"degraded,omitempty"`
+}
+
+// DegradedConfig sets the Worker's degraded-mode flag when a budget is used up.
+type DegradedConfig struct {
+	Namespace string `yaml:"kv_namespace"`
+	Key       string `yaml:"key,omitempty"` // Default: degraded
+	At        int    `yaml:"at,omitempty"`  // Default: 100
+}
+
+// BudgetUsage is one product's usage this month against its budget.
+type BudgetUsage struct {
+	Product string  `json:"product"`
+	Used    float64 `json:"used"`
+	Limit   float64 `json:"limit"`
+	Unit    string  `json:"unit"`
+	Percent float64 `json:"percent"`
+}
+
+// BudgetAlert is a threshold crossed for the first time this month.
+type BudgetAlert struct {
+	Usage     BudgetUsage `json:"usage"`
+	Threshold int         `json:"threshold"`
+}
+
+// BudgetState remembers what was alerted this month, so each threshold
+// alerts once, and whether degraded mode is on.
+type BudgetState struct {
+	Month     string         `json:"month"`   // YYYY-MM (UTC)
+	Alerted   map[string]int `json:"alerted"` // Product -> highest threshold alerted
+	Degraded  bool           `json:"degraded"`
+	Usage     []BudgetUsage  `json:"usage"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// BudgetConfigPath returns the budget config file.
+func BudgetConfigPath() string {
+	return filepath.Join(config.XplatConfig(), "budgets.yaml")
+}
+
+// budgetStatePath returns the budget state file.
+func budgetStatePath() string {
+	return filepath.Join(config.XplatCache(), "synccf-budget-state.json")
+}
+
+// budgetStateVersion is the schema version of the budget state file.
+const budgetStateVersion = 1
+
+// LoadBudgetConfig reads and validates the budget config.
+func LoadBudgetConfig(path string) (*BudgetConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget config: %w", err)
+	}
+	var cfg BudgetConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if cfg.WorkersRequests <= 0 && cfg.R2StorageGB <= 0 && cfg.PagesBuilds <= 0 {
+		return nil, fmt.Errorf("%s: no budgets set (workers_requests, r2_storage_gb, pages_builds)", path)
+	}
+	if len(cfg.Thresholds) == 0 {
+		cfg.Thresholds = append([]int(nil), DefaultBudgetThresholds...)
+	}
+	for _, t := range cfg.Thresholds {
+		if t <= 0 {
+			return nil, fmt.Errorf("%s: thresholds must be positive percentages", path)
+		}
+	}
+	sort.Ints(cfg.Thresholds)
+	if d := cfg.Degraded; d != nil {
+		if d.Namespace == "" {
+			return nil, fmt.Errorf("%s: degraded needs kv_namespace", path)
+		}
+		if d.Key == "" {
+			d.Key = DefaultDegradedKey
+		}
+		if d.At <= 0 {
+			d.At = 100
+		}
+	}
+	return &cfg, nil
+}
+
+// BudgetClient measures usage against budgets and drives degraded mode.
+// It needs Account Analytics read, Pages read and (for degraded mode)
+// Workers KV Storage edit permissions.
+type BudgetClient struct {
+	AccountID string
+	Analytics *cfanalytics.Client
+
+	apiToken   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewBudgetClient creates a budget client for an account.
+func NewBudgetClient(accountID, apiToken string) (*BudgetClient, error) {
+	analytics, err := cfanalytics.New(accountID, apiToken)
+	if err != nil {
+		return nil, err
+	}
+	return &BudgetClient{
+		AccountID:  accountID,
+		Analytics:  analytics,
+		apiToken:   apiToken,
+		baseURL:    cfAPIBase,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// SetBaseURL points the REST calls at another API base URL (tests).
+func (b *BudgetClient) SetBaseURL(baseURL string) {
+	b.baseURL = baseURL
+}
+
+// monthStart returns the start of now's calendar month in UTC.
+func monthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// Measure returns this month's usage of every budgeted product.
+func (b *BudgetClient) Measure(ctx context.Context, cfg *BudgetConfig, now time.Time) ([]BudgetUsage, error) {
+	month := cfanalytics.Range{Since: monthStart(now), Until: now.UTC()}
+	var usage []BudgetUsage
+
+	if cfg.WorkersRequests > 0 {
+		stats, err := b.Analytics.FetchWorkers(ctx, month, "")
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		for _, s := range stats {
+			total += s.Requests
+		}
+		usage = append(usage, newBudgetUsage(ProductWorkersRequests, float64(total), float64(cfg.WorkersRequests), "requests"))
+	}
+
+	if cfg.R2StorageGB > 0 {
+		day := cfanalytics.Range{Since: now.UTC().Add(-24 * time.Hour), Until: now.UTC()}
+		buckets, err := b.Analytics.FetchR2Storage(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		var bytes int64
+		for _, s := range buckets {
+			bytes += s.PayloadBytes + s.MetadataBytes
+		}
+		usage = append(usage, newBudgetUsage(ProductR2Storage, float64(bytes)/1e9, cfg.R2StorageGB, "GB"))
+	}
+
+	if cfg.PagesBuilds > 0 {
+		builds, err := b.countPagesBuilds(ctx, month.Since)
+		if err != nil {
+			return nil, err
+		}
+		usage = append(usage, newBudgetUsage(ProductPagesBuilds, float64(builds), float64(cfg.PagesBuilds), "builds"))
+	}
+
+	return usage, nil
+}
+
+func newBudgetUsage(product string, used, limit float64, unit string) BudgetUsage {
+	return BudgetUsage{Product: product, Used: used, Limit: limit, Unit: unit, Percent: used / limit * 100}
+}
+
+// countPagesBuilds counts deployments created since the given time across
+// all Pages projects. Deployments are listed newest first, so each project
+// is paged only until one is older.
+func (b *BudgetClient) countPagesBuilds(ctx context.Context, since time.Time) (int, error) {
+	var projects []struct {
+		Name string `json:"name"`
+	}
+	for page := 1; ; page++ {
+		var items []struct {
+			Name string `json:"name"`
+		}
+		totalPages, err := b.get(ctx, fmt.Sprintf("/accounts/%s/pages/projects?page=%d", b.AccountID, page), &items)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list Pages projects: %w", err)
+		}
+		projects = append(projects, items...)
+		if len(items) == 0 || page >= totalPages {
+			break
+		}
+	}
+
+	total := 0
+	for _, p := range projects {
+		for page := 1; ; page++ {
+			var deployments []struct {
+				CreatedOn time.Time `json:"created_on"`
+			}
+			path := fmt.Sprintf("/accounts/%s/pages/projects/%s/deployments?page=%d&per_page=25", b.AccountID, url.PathEscape(p.Name), page)
+			totalPages, err := b.get(ctx, path, &deployments)
+			if err != nil {
+				return 0, fmt.Errorf("failed to list deployments of %s: %w", p.Name, err)
+			}
+			older := false
+			for _, d := range deployments {
+				if d.CreatedOn.Before(since) {
+					older = true
+					break
+				}
+				total++
+			}
+			if older || len(deployments) == 0 || page >= totalPages {
+				break
+			}
+		}
+	}
+	return total, nil
+}
+
+// get calls a REST endpoint and decodes its result, returning the page count.
+func (b *BudgetClient) get(ctx context.Context, path string, out interface{}) (int, error) {
+	return restGet(ctx, b.httpClient, b.baseURL+path, b.apiToken, out)
+}
+
+// restGet calls a REST endpoint and decodes its result, returning the page count.
+func restGet(ctx context.Context, httpClient *http.Client, reqURL, apiToken string, out interface{}) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiToken)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var apiResp env.CloudflareListResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return 0, fmt.Errorf("API error (status %d): invalid response: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || !apiResp.Success {
+		return 0, fmt.Errorf("API error (status %d): %v", resp.StatusCode, apiResp.Errors)
+	}
+	if err := json.Unmarshal(apiResp.Result, out); err != nil {
+		return 0, fmt.Errorf("decode response: %w", err)
+	}
+	return apiResp.ResultInfo.TotalPages, nil
+}
+
+// SetDegraded sets (with a JSON reason as the value) or deletes the
+// degraded-mode flag in KV.
+func (b *BudgetClient) SetDegraded(ctx context.Context, d *DegradedConfig, on bool, reason string) error {
+	path := fmt.Sprintf("%s/accounts/%s/storage/kv/namespaces/%s/values/%s",
+		b.baseURL, b.AccountID, url.PathEscape(d.Namespace), url.PathEscape(d.Key))
+
+	method, body := http.MethodDelete, io.Reader(nil)
+	if on {
+		value, err := json.Marshal(map[string]string{"reason": reason, "since": time.Now().UTC().Format(time.RFC3339)})
+		if err != nil {
+			return err
+		}
+		method, body = http.MethodPut, strings.NewReader(string(value))
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+b.apiToken)
+	if on {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to set degraded mode: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	// Deleting a key that isn't there is fine
+	if resp.StatusCode != http.StatusOK && !(method == http.MethodDelete && resp.StatusCode == http.StatusNotFound) {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to set degraded mode: API error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// EvaluateBudget returns the thresholds crossed since the last evaluation
+// and records them in state. A new month starts with no alerts.
+func EvaluateBudget(cfg *BudgetConfig, state *BudgetState, usage []BudgetUsage, now time.Time) []BudgetAlert {
+	month := now.UTC().Format("2006-01")
+	if state.Month != month || state.Alerted == nil {
+		state.Month = month
+		state.Alerted = make(map[string]int)
+	}
+
+	var alerts []BudgetAlert
+	for _, u := range usage {
+		crossed := 0
+		for _, t := range cfg.Thresholds {
+			if u.Percent >= float64(t) {
+				crossed = t
+			}
+		}
+		if crossed > state.Alerted[u.Product] {
+			state.Alerted[u.Product] = crossed
+			alerts = append(alerts, BudgetAlert{Usage: u, Threshold: crossed})
+		}
+	}
+	state.Usage = usage
+	state.CheckedAt = now.UTC()
+	return alerts
+}
+
+// degradedReason returns the product that should put the Worker in degraded
+// mode, or "" when none has reached the degraded threshold.
+func degradedReason(cfg *BudgetConfig, usage []BudgetUsage) string {
+	if cfg.Degraded == nil {
+		return ""
+	}
+	for _, u := range usage {
+		if u.Percent >= float64(cfg.Degraded.At) {
+			return fmt.Sprintf("%s at %.0f%% of budget", u.Product, u.Percent)
+		}
+	}
+	return ""
+}
+
+// Message returns the notification for an alert: warning from 80%,
+// critical from 100%.
+func (a BudgetAlert) Message() *notify.Message {
+	severity := notify.SeverityInfo
+	switch {
+	case a.Threshold >= 100:
+		severity = notify.SeverityCritical
+	case a.Threshold >= 80:
+		severity = notify.SeverityWarning
+	}
+	u := a.Usage
+	return &notify.Message{
+		Source:   BudgetSource,
+		Severity: severity,
+		Title:    fmt.Sprintf("Cloudflare %s at %d%% of monthly budget", u.Product, a.Threshold),
+		Fields: map[string]string{
+			"used":    formatBudgetAmount(u.Used, u.Unit),
+			"budget":  formatBudgetAmount(u.Limit, u.Unit),
+			"percent": fmt.Sprintf("%.1f%%", u.Percent),
+		},
+		Time: time.Now(),
+	}
+}
+
+func formatBudgetAmount(v float64, unit string) string {
+	if unit == "GB" {
+		return fmt.Sprintf("%.2f GB", v)
+	}
+	return fmt.Sprintf("%.0f %s", v, unit)
+}
+
+// BudgetCheck is the result of one budget evaluation.
+type BudgetCheck struct {
+	Usage    []BudgetUsage `json:"usage"`
+	Alerts   []BudgetAlert `json:"alerts"`
+	Degraded bool          `json:"degraded"`
+}
+
+// CheckBudget measures usage, sends new threshold alerts to the router and
+// switches degraded mode when it changes. State is kept in the cache dir so
+// scheduled runs alert each threshold once per month.
+func (b *BudgetClient) CheckBudget(ctx context.Context, cfg *BudgetConfig, router *notify.Router, now time.Time) (*BudgetCheck, error) {
+	usage, err := b.Measure(ctx, cfg, now)
+	if err != nil {
+		return nil, err
+	}
+
+	store := statestore.New[BudgetState](budgetStatePath(), budgetStateVersion)
+	store.Lock = true
+
+	check := &BudgetCheck{Usage: usage}
+	var messages []*notify.Message
+	var errs []error
+	_, err = store.Update(func(state *BudgetState) error {
+		check.Alerts = EvaluateBudget(cfg, state, usage, now)
+		for _, a := range check.Alerts {
+			messages = append(messages, a.Message())
+		}
+
+		if cfg.Degraded != nil {
+			reason := degradedReason(cfg, usage)
+			on := reason != ""
+			if on != state.Degraded {
+				if err := b.SetDegraded(ctx, cfg.Degraded, on, reason); err != nil {
+					errs = append(errs, err)
+				} else {
+					state.Degraded = on
+					messages = append(messages, degradedMessage(on, reason))
+				}
+			}
+		}
+		check.Degraded = state.Degraded
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sent after the state is saved, so a slow webhook doesn't hold the lock
+	for _, m := range messages {
+		if err := router.Notify(ctx, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return check, errors.Join(errs...)
+}
+
+// degradedMessage announces degraded mode switching on or off.
+func degradedMessage(on bool, reason string) *notify.Message {
+	if on {
+		return &notify.Message{
+			Source:   BudgetSource,
+			Severity: notify.SeverityCritical,
+			Title:    "Cloudflare Worker switched to degraded mode",
+			Text:     reason + "; Logpush and R2 events are dropped until usage is back under budget",
+			Time:     time.Now(),
+		}
+	}
+	return &notify.Message{
+		Source:   BudgetSource,
+		Severity: notify.SeverityInfo,
+		Title:    "Cloudflare Worker left degraded mode",
+		Time:     time.Now(),
+	}
+}
+
+// LoadBudgetState reads the state of the last budget check.
+func LoadBudgetState() (*BudgetState, error) {
+	return statestore.New[BudgetState](budgetStatePath(), budgetStateVersion).Load()
+}
@@ -0,0 +1,245 @@
+package synccf
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+// fakeBudgetAPI serves the GraphQL and REST endpoints a budget check uses.
+type fakeBudgetAPI struct {
+	mu       sync.Mutex
+	requests int64
+	kv       map[string]string
+}
+
+func (f *fakeBudgetAPI) graphQL(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	var groups interface{}
+	key := "workersInvocationsAdaptive"
+	if strings.Contains(string(body), "r2StorageAdaptiveGroups") {
+		key = "r2StorageAdaptiveGroups"
+		groups = []map[string]interface{}{
+			{"max": map[string]int64{"payloadSize": 4e9, "metadataSize": 1e9}, "dimensions": map[string]string{"bucketName": "assets"}},
+		}
+	} else {
+		groups = []map[string]interface{}{
+			{"sum": map[string]int64{"requests": f.requests}, "dimensions": map[string]string{"scriptName": "xplat-sync-cf", "date": "2026-10-01"}},
+		}
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{"viewer": map[string]interface{}{"accounts": []interface{}{
+			map[string]interface{}{key: groups},
+		}}},
+	})
+}
+
+func (f *fakeBudgetAPI) rest(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := func(v interface{}, pages int) {
+		data, _ := json.Marshal(v)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true, "result": json.RawMessage(data),
+			"result_info": map[string]int{"page": 1, "total_pages": pages},
+		})
+	}
+
+	switch {
+	case r.URL.Path == "/accounts/acc/pages/projects":
+		result([]map[string]string{{"name": "site"}, {"name": "docs"}}, 1)
+	case r.URL.Path == "/accounts/acc/pages/projects/site/deployments":
+		if r.URL.Query().Get("page") == "1" {
+			result([]map[string]string{{"created_on": "2026-10-15T10:00:00Z"}, {"created_on": "2026-10-02T10:00:00Z"}}, 2)
+		} else {
+			result([]map[string]string{{"created_on": "2026-10-01T09:00:00Z"}, {"created_on": "2026-09-30T10:00:00Z"}}, 2)
+		}
+	case r.URL.Path == "/accounts/acc/pages/projects/docs/deployments":
+		result([]map[string]string{{"created_on": "2026-09-12T10:00:00Z"}}, 3)
+	case strings.HasPrefix(r.URL.Path, "/accounts/acc/storage/kv/namespaces/ns/values/"):
+		key := strings.TrimPrefix(r.URL.Path, "/accounts/acc/storage/kv/namespaces/ns/values/")
+		switch r.Method {
+		case http.MethodPut:
+			value, _ := io.ReadAll(r.Body)
+			f.kv[key] = string(value)
+		case http.MethodDelete:
+			delete(f.kv, key)
+		}
+		result(nil, 0)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestBudgetClient(t *testing.T, f *fakeBudgetAPI) *BudgetClient {
+	t.Helper()
+	gql := httptest.NewServer(http.HandlerFunc(f.graphQL))
+	rest := httptest.NewServer(http.HandlerFunc(f.rest))
+	t.Cleanup(gql.Close)
+	t.Cleanup(rest.Close)
+
+	b, err := NewBudgetClient("acc", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Analytics.SetEndpoint(gql.URL)
+	b.SetBaseURL(rest.URL)
+	return b
+}
+
+func TestBudgetMeasure(t *testing.T) {
+	f := &fakeBudgetAPI{requests: 600, kv: map[string]string{}}
+	b := newTestBudgetClient(t, f)
+	cfg := &BudgetConfig{WorkersRequests: 1000, R2StorageGB: 10, PagesBuilds: 6}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	usage, err := b.Measure(context.Background(), cfg, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{ProductWorkersRequests: 60, ProductR2Storage: 50, ProductPagesBuilds: 50}
+	if len(usage) != len(want) {
+		t.Fatalf("usage = %+v", usage)
+	}
+	for _, u := range usage {
+		if u.Percent != want[u.Product] {
+			t.Errorf("%s at %.1f%%, want %.1f%%", u.Product, u.Percent, want[u.Product])
+		}
+	}
+}
+
+func TestEvaluateBudget(t *testing.T) {
+	cfg := &BudgetConfig{Thresholds: DefaultBudgetThresholds}
+	state := &BudgetState{}
+	oct := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	usage := func(percent float64) []BudgetUsage {
+		return []BudgetUsage{{Product: ProductWorkersRequests, Percent: percent}}
+	}
+
+	steps := []struct {
+		now     time.Time
+		percent float64
+		want    int // Threshold alerted, 0 for none
+	}{
+		{oct, 30, 0},
+		{oct, 85, 80}, // Skipping 50 alerts only the highest crossed
+		{oct, 90, 0},  // Already alerted
+		{oct, 101, 100},
+		{oct.AddDate(0, 1, 0), 55, 50}, // New month starts over
+	}
+	for i, step := range steps {
+		alerts := EvaluateBudget(cfg, state, usage(step.percent), step.now)
+		got := 0
+		if len(alerts) > 0 {
+			got = alerts[0].Threshold
+		}
+		if got != step.want || len(alerts) > 1 {
+			t.Errorf("step %d: alerts = %+v, want threshold %d", i, alerts, step.want)
+		}
+	}
+
+	msg := BudgetAlert{Usage: BudgetUsage{Product: ProductPagesBuilds, Used: 500, Limit: 500, Unit: "builds", Percent: 100}, Threshold: 100}.Message()
+	if msg.Severity != notify.SeverityCritical || msg.Source != BudgetSource || msg.Fields["used"] != "500 builds" {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestCheckBudgetDegradedMode(t *testing.T) {
+	t.Setenv("XPLAT_HOME", t.TempDir())
+
+	var mu sync.Mutex
+	var titles []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var m notify.Message
+		_ = json.NewDecoder(r.Body).Decode(&m)
+		mu.Lock()
+		titles = append(titles, m.Title)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+	router := &notify.Router{Routes: []notify.Route{{Webhook: hook.URL}}}
+
+	f := &fakeBudgetAPI{requests: 1200, kv: map[string]string{}}
+	b := newTestBudgetClient(t, f)
+	cfg := &BudgetConfig{
+		WorkersRequests: 1000,
+		Thresholds:      DefaultBudgetThresholds,
+		Degraded:        &DegradedConfig{Namespace: "ns", Key: DefaultDegradedKey, At: 100},
+	}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	check, err := b.CheckBudget(context.Background(), cfg, router, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Degraded || len(check.Alerts) != 1 || check.Alerts[0].Threshold != 100 {
+		t.Errorf("check = %+v", check)
+	}
+	if !strings.Contains(f.kv[DefaultDegradedKey], "workers_requests") {
+		t.Errorf("kv = %v, want the degraded flag", f.kv)
+	}
+	if len(titles) != 2 {
+		t.Errorf("notifications = %q, want the alert and degraded mode", titles)
+	}
+
+	// Still over budget: nothing new to say
+	titles = nil
+	if _, err := b.CheckBudget(context.Background(), cfg, router, now); err != nil {
+		t.Fatal(err)
+	}
+	if len(titles) != 0 {
+		t.Errorf("repeated notifications = %q", titles)
+	}
+
+	// Next month the flag is cleared
+	f.requests = 10
+	check, err = b.CheckBudget(context.Background(), cfg, router, now.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Degraded || len(f.kv) != 0 {
+		t.Errorf("degraded = %v, kv = %v; want cleared", check.Degraded, f.kv)
+	}
+
+	state, err := LoadBudgetState()
+	if err != nil || state.Month != "2026-11" || state.Degraded {
+		t.Errorf("state = %+v, %v", state, err)
+	}
+}
+
+func TestLoadBudgetConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, "budgets.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadBudgetConfig(write("workers_requests: 1000\nthresholds: [90, 25]\ndegraded:\n  kv_namespace: ns\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Thresholds[0] != 25 || cfg.Degraded.Key != DefaultDegradedKey || cfg.Degraded.At != 100 {
+		t.Errorf("cfg = %+v, degraded %+v", cfg, cfg.Degraded)
+	}
+
+	for _, bad := range []string{"thresholds: [50]\n", "pages_builds: 5\ndegraded:\n  at: 90\n", "pages_builds: 5\nthresholds: [0]\n"} {
+		if _, err := LoadBudgetConfig(write(bad)); err == nil {
+			t.Errorf("LoadBudgetConfig(%q) succeeded", bad)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 	"time"
+
+	"github.com/joeblew999/xplat/internal/cfanalytics"
 )
 
 // EventType represents the type of Cloudflare event
@@ -18,7 +20,11 @@ const (
 	EventLogpush       EventType = "logpush"
 	EventPagesDeploy   EventType = "pages_deploy"
 	EventWorkersDeploy EventType = "workers_deploy"
+	EventWorkersError  EventType = "workers_error"
 	EventTunnel        EventType = "tunnel"
+
+	EventR2ObjectCreated EventType = "r2_object_created"
+	EventR2ObjectDeleted EventType = "r2_object_deleted"
 )
 
 // Event represents a normalized Cloudflare event
@@ -74,7 +80,7 @@ func (c *Client) On(eventType EventType, handler EventHandler) {
 
 // OnAny registers an event handler for all event types
 func (c *Client) OnAny(handler EventHandler) {
-	for _, et := range []EventType{EventAuditLog, EventAlert, EventLogpush, EventPagesDeploy, EventWorkersDeploy, EventTunnel} {
+	for _, et := range []EventType{EventAuditLog, EventAlert, EventLogpush, EventPagesDeploy, EventWorkersDeploy, EventWorkersError, EventTunnel, EventR2ObjectCreated, EventR2ObjectDeleted} {
 		c.On(et, handler)
 	}
 }
@@ -93,3 +99,8 @@ func (c *Client) emit(ctx context.Context, event Event) {
 func (c *Client) GetAccountID() string {
 	return c.accountID
 }
+
+// Analytics returns an analytics client using this client's credentials.
+func (c *Client) Analytics() (*cfanalytics.Client, error) {
+	return cfanalytics.New(c.accountID, c.apiToken)
+}
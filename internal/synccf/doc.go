@@ -2,9 +2,10 @@
 //
 // This package enables xplat to integrate with Cloudflare services for:
 //   - Receiving events from CF Worker (round-trip validation)
-//   - Tunnel management (cloudflared quick tunnels)
+//   - Tunnel management (cloudflared, Tailscale Funnel or ngrok)
 //   - Webhook handling (Cloudflare notifications)
 //   - Audit log polling
+//   - Account inventory snapshots and drift reports
 //   - Authentication
 //   - Task cache invalidation on Pages deploy
 //
@@ -12,11 +13,19 @@
 //
 //   - ReceiveHandler: Receives events forwarded by the CF Worker
 //   - TaskCacheInvalidator: Callback to invalidate Task cache on deploy events
+//   - WorkerErrorIssueCallback: Files a GitHub issue for Worker error alerts
+//   - PagesFailureCallback: Notifies and files an issue for failed Pages deploys, with an analysis of the build log
+//   - TaskRunnerCallback: Runs Taskfile tasks on events, with the event as task vars
+//   - R2ObjectEvent: Typed R2 event notification (object created/deleted)
 //   - Client: Main Cloudflare API client with event handling
 //   - Tunnel: Manage cloudflared tunnels (quick tunnels or named)
+//   - Provider: Tunnel backend interface (cloudflared, tailscale, ngrok)
 //   - WebhookHandler: HTTP handler for Cloudflare notification webhooks
 //   - AuditPoller: Poll Cloudflare audit logs for changes
 //   - Auth: Authentication helpers for Cloudflare API
+//   - TokenClient: Mints least-privilege API tokens from presets (pages-events, r2-rw, analytics-read)
+//   - Inventory: Snapshot of zones, DNS, Pages, Workers, KV and token names
+//   - MockScenario: Scripted Worker events for offline receiver testing
 //
 // # Round-Trip Validation (Recommended)
 //
@@ -31,6 +40,21 @@
 //
 //	Cloudflare → CF Worker → Tunnel → ReceiveHandler → Callbacks
 //
+// The Worker may gzip forwards (Content-Encoding: gzip) and batch several
+// events into a JSON array; ReceiveHandler accepts both. Raw payloads over
+// MAX_PAYLOAD_BYTES arrive with RawTruncated set and, when the Worker has an
+// R2 binding, RawRef pointing at the full body.
+//
+// # Offline Testing
+//
+// RunMockScenario plays the Worker's part: it sends a MockScenario (YAML
+// file or built-in name, see MockScenarioNames) to a receiver with the same
+// headers, batching and gzip as the Worker's forward. Repeated events keep
+// their timestamp, so they exercise the receiver's dedupe:
+//
+//	s, err := synccf.LoadMockScenario("pages-deploy")
+//	results, err := synccf.RunMockScenario(ctx, s, synccf.MockConfig{Target: "http://127.0.0.1:9091/"})
+//
 // # Receiver Usage
 //
 // Start a receiver to get events from the CF Worker:
@@ -40,6 +64,45 @@
 //	    OnAny:         synccf.DefaultLogCallback(),
 //	})
 //
+// # Worker Error Alerts
+//
+// workers_event notifications about error rates or exceeded CPU limits are
+// forwarded as workers_error events (other workers_event notifications stay
+// workers_deploy). The Worker attaches its /metrics counters at alert time.
+// WorkerErrorIssueCallback files one GitHub issue per script and alert kind,
+// commenting on it while it stays open:
+//
+//	issues, err := syncgh.NewIssueClient("owner/repo", ghauth.DefaultSource())
+//	synccf.RunReceiveServer("9091", synccf.ReceiveCallbacks{
+//	    OnWorkerError: synccf.WorkerErrorIssueCallback(issues),
+//	})
+//
+// # Running Tasks on Events
+//
+// TaskRunnerCallback runs a task through the embedded Task runner for each
+// TaskHook matching an event. Event fields (type, resource, deploy URL,
+// commit, ...) arrive as task vars, see EventTaskVars:
+//
+//	hook, _ := synccf.ParseTaskHook("pages_deploy=smoke-test")
+//	synccf.RunReceiveServer("9091", synccf.ReceiveCallbacks{
+//	    OnAny: synccf.TaskRunnerCallback(&synccf.TaskfileRunner{Dir: workDir}, []synccf.TaskHook{hook}),
+//	})
+//
+// # R2 Event Notifications
+//
+// R2 buckets can send object-create and object-delete notifications to a
+// Queue. With the Worker bound as the queue's consumer (see wrangler.toml),
+// each notification is forwarded as an r2_object_created or
+// r2_object_deleted event. Callbacks get the typed R2ObjectEvent, so tiered
+// storage and build pipelines react to bucket changes without listing:
+//
+//	synccf.RunReceiveServer("9091", synccf.ReceiveCallbacks{
+//	    OnR2ObjectCreated: func(ctx context.Context, e synccf.R2ObjectEvent) error {
+//	        log.Printf("%s/%s: %d bytes (%s)", e.Bucket, e.Object.Key, e.Object.Size, e.Action)
+//	        return nil
+//	    },
+//	})
+//
 // # Tunnel Usage
 //
 // Create a quick tunnel to expose a local port:
@@ -54,6 +117,12 @@
 //	fmt.Printf("Tunnel URL: %s\n", url)
 //	defer tunnel.Stop()
 //
+// Or pick a provider by name, for networks that block cloudflared:
+//
+//	provider, err := synccf.NewProvider(synccf.ProviderTailscale, synccf.ProviderOptions{})
+//	url, err := provider.Start(ctx, 9091)
+//	defer provider.Stop()
+//
 // # Webhook Usage
 //
 // Handle incoming Cloudflare notification webhooks:
@@ -84,6 +153,39 @@
 //	})
 //	poller.Start(ctx)
 //
+// # Inventory Snapshots
+//
+// Capture account resources and compare snapshots to detect drift:
+//
+//	inv, err := client.CaptureInventory(ctx)
+//	path, err := synccf.SaveInventory(inv, synccf.InventoryDir())
+//
+//	for _, c := range synccf.DiffInventory(before, after) {
+//	    log.Printf("%s %s", c.Kind, c.Resource)
+//	}
+//
+// # Analytics
+//
+// GraphQL Analytics queries live in internal/cfanalytics. Client.Analytics
+// returns one with this client's credentials:
+//
+//	a, err := client.Analytics()  // or cfanalytics.New(accountID, token)
+//	views, err := a.FetchRUM(ctx, cfanalytics.LastDays(7), siteTag)
+//	days, err := a.FetchZoneHTTP(ctx, cfanalytics.LastDays(7), zoneID)
+//
+// 'xplat sync-cf analytics report' prints a traffic report from the same
+// queries.
+//
+// # Budgets
+//
+// BudgetClient measures this month's Workers requests, R2 storage and Pages
+// builds against ~/.xplat/config/budgets.yaml, alerts through the
+// notification router at 50/80/100% and can switch the Worker into degraded
+// mode through a KV flag:
+//
+//	b, err := synccf.NewBudgetClient(accountID, token)
+//	check, err := b.CheckBudget(ctx, cfg, router, time.Now())
+//
 // # Environment Variables
 //
 // These can be set in your .env file (used by wizard and CLI):
@@ -93,6 +195,7 @@
 //   - CLOUDFLARE_WORKER_NAME: Name of the sync Worker (default: xplat-sync)
 //   - CLOUDFLARE_SYNC_ENDPOINT: Tunnel URL for Worker forwarding
 //   - CLOUDFLARE_RECEIVER_PORT: Local receiver port (default: 9091)
+//   - CLOUDFLARE_TUNNEL_PROVIDER: Tunnel provider (default: cloudflared)
 //
 // Legacy environment variables (still supported):
 //
@@ -102,10 +205,15 @@
 // # CLI Commands
 //
 //	xplat sync-cf receive --port=9091 --invalidate  # Receive Worker events with cache invalidation
+//	xplat sync-cf receive --issues owner/repo       # File issues for Worker error alerts
+//	xplat sync-cf receive --task pages_deploy=smoke # Run a task on each Pages deploy
 //	xplat sync-cf receive-state                     # Show processed events state
 //	xplat sync-cf tunnel --port=8080                # Start quick tunnel
 //	xplat sync-cf webhook --port=8080               # Start webhook server
 //	xplat sync-cf poll                              # Poll audit logs
+//	xplat sync-cf inventory                         # Snapshot account resources
+//	xplat sync-cf inventory diff                    # Diff the two latest snapshots
+//	xplat sync-cf budget                            # Check usage against monthly budgets
 //
 // # Web UI Integration
 //
@@ -0,0 +1,280 @@
+package synccf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
+	"github.com/joeblew999/xplat/internal/env"
+)
+
+const cfAPIBase = env.CloudflareAPIBaseURL
+
+// Inventory is a point-in-time snapshot of the resources in a Cloudflare account.
+// Only names and non-secret attributes are captured, so snapshots are safe to keep on disk.
+type Inventory struct {
+	CapturedAt    time.Time       `json:"captured_at"`
+	AccountID     string          `json:"account_id"`
+	Zones         []InventoryZone `json:"zones"`
+	PagesProjects []string        `json:"pages_projects"`
+	Workers       []string        `json:"workers"`
+	KVNamespaces  []string        `json:"kv_namespaces"`
+	Tokens        []string        `json:"tokens"`
+	// Errors records resources that could not be listed (usually missing token permissions)
+	Errors []string `json:"errors,omitempty"`
+}
+
+// InventoryZone is a zone and its DNS records
+type InventoryZone struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	Status     string               `json:"status"`
+	DNSRecords []InventoryDNSRecord `json:"dns_records"`
+}
+
+// InventoryDNSRecord is a single DNS record within a zone
+type InventoryDNSRecord struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Proxied bool   `json:"proxied"`
+	TTL     int    `json:"ttl"`
+}
+
+// ChangeKind describes how a resource changed between two snapshots
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// InventoryChange is a single difference between two snapshots
+type InventoryChange struct {
+	Kind     ChangeKind `json:"kind"`
+	Resource string     `json:"resource"`
+	Old      string     `json:"old,omitempty"`
+	New      string     `json:"new,omitempty"`
+}
+
+// CaptureInventory lists all inventoried resources in the account.
+// Resources the token cannot read are recorded in Inventory.Errors rather than failing the capture.
+func (c *Client) CaptureInventory(ctx context.Context) (*Inventory, error) {
+	inv := &Inventory{
+		CapturedAt: time.Now().UTC(),
+		AccountID:  c.accountID,
+	}
+
+	var zones []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if err := env.CloudflareListAll(ctx, c.apiToken, "/zones?account.id="+url.QueryEscape(c.accountID), &zones); err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	for _, z := range zones {
+		zone := InventoryZone{ID: z.ID, Name: z.Name, Status: z.Status}
+
+		var records []InventoryDNSRecord
+		if err := env.CloudflareListAll(ctx, c.apiToken, fmt.Sprintf("/zones/%s/dns_records", z.ID), &records); err != nil {
+			inv.Errors = append(inv.Errors, fmt.Sprintf("dns records for %s: %v", z.Name, err))
+		}
+		sort.Slice(records, func(i, j int) bool {
+			if records[i].Name != records[j].Name {
+				return records[i].Name < records[j].Name
+			}
+			if records[i].Type != records[j].Type {
+				return records[i].Type < records[j].Type
+			}
+			return records[i].Content < records[j].Content
+		})
+		zone.DNSRecords = records
+		inv.Zones = append(inv.Zones, zone)
+	}
+	sort.Slice(inv.Zones, func(i, j int) bool { return inv.Zones[i].Name < inv.Zones[j].Name })
+
+	inv.PagesProjects = c.listNames(ctx, inv, "pages projects",
+		fmt.Sprintf("/accounts/%s/pages/projects", c.accountID), "name")
+	inv.Workers = c.listNames(ctx, inv, "workers",
+		fmt.Sprintf("/accounts/%s/workers/scripts", c.accountID), "id")
+	inv.KVNamespaces = c.listNames(ctx, inv, "kv namespaces",
+		fmt.Sprintf("/accounts/%s/storage/kv/namespaces", c.accountID), "title")
+	inv.Tokens = c.listNames(ctx, inv, "tokens", "/user/tokens", "name")
+
+	return inv, nil
+}
+
+// listNames lists a resource and extracts a single string field from each item.
+// Failures are recorded on the inventory so one missing permission doesn't abort the capture.
+func (c *Client) listNames(ctx context.Context, inv *Inventory, label, path, field string) []string {
+	var items []map[string]interface{}
+	if err := env.CloudflareListAll(ctx, c.apiToken, path, &items); err != nil {
+		inv.Errors = append(inv.Errors, fmt.Sprintf("%s: %v", label, err))
+		return nil
+	}
+
+	names := make([]string, 0, len(items))
+	for _, item := range items {
+		if name, ok := item[field].(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InventoryDir returns the directory where inventory snapshots are stored
+func InventoryDir() string {
+	return filepath.Join(config.XplatCache(), "synccf-inventory")
+}
+
+// SaveInventory writes a snapshot to dir as inventory-<timestamp>.json and returns its path
+func SaveInventory(inv *Inventory, dir string) (string, error) {
+	if err := os.MkdirAll(dir, config.DefaultDirPerms); err != nil {
+		return "", fmt.Errorf("failed to create inventory dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal inventory: %w", err)
+	}
+
+	name := fmt.Sprintf("inventory-%s.json", inv.CapturedAt.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(dir, name)
+	if err := statestore.WriteFile(path, data, config.DefaultFilePerms); err != nil {
+		return "", fmt.Errorf("failed to write inventory: %w", err)
+	}
+	return path, nil
+}
+
+// LoadInventory reads a snapshot from disk
+func LoadInventory(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+
+	var inv Inventory
+	if err := json.Unmarshal(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory %s: %w", path, err)
+	}
+	return &inv, nil
+}
+
+// ListInventorySnapshots returns snapshot paths in dir, oldest first
+func ListInventorySnapshots(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "inventory-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	// Timestamps in the file name sort lexically
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// DiffInventory reports resources added, removed, or changed between two snapshots.
+// Changes are sorted by resource key so output is stable.
+func DiffInventory(before, after *Inventory) []InventoryChange {
+	oldKeys := before.resourceKeys()
+	newKeys := after.resourceKeys()
+
+	var changes []InventoryChange
+	for key, oldVal := range oldKeys {
+		newVal, ok := newKeys[key]
+		switch {
+		case !ok:
+			changes = append(changes, InventoryChange{Kind: ChangeRemoved, Resource: key, Old: oldVal})
+		case newVal != oldVal:
+			changes = append(changes, InventoryChange{Kind: ChangeChanged, Resource: key, Old: oldVal, New: newVal})
+		}
+	}
+	for key, newVal := range newKeys {
+		if _, ok := oldKeys[key]; !ok {
+			changes = append(changes, InventoryChange{Kind: ChangeAdded, Resource: key, New: newVal})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Resource < changes[j].Resource })
+	return changes
+}
+
+// resourceKeys flattens the inventory into resource key -> comparable attributes
+func (inv *Inventory) resourceKeys() map[string]string {
+	keys := make(map[string]string)
+
+	for _, z := range inv.Zones {
+		keys["zone/"+z.Name] = "status=" + z.Status
+		for _, r := range z.DNSRecords {
+			key := fmt.Sprintf("dns/%s/%s %s %s", z.Name, r.Type, r.Name, r.Content)
+			keys[key] = fmt.Sprintf("proxied=%t ttl=%d", r.Proxied, r.TTL)
+		}
+	}
+	for _, name := range inv.PagesProjects {
+		keys["pages/"+name] = ""
+	}
+	for _, name := range inv.Workers {
+		keys["worker/"+name] = ""
+	}
+	for _, name := range inv.KVNamespaces {
+		keys["kv/"+name] = ""
+	}
+	for _, name := range inv.Tokens {
+		keys["token/"+name] = ""
+	}
+
+	return keys
+}
+
+// RunInventory captures an inventory snapshot and saves it to the inventory directory.
+// This is the main entry point for the CLI command.
+func RunInventory(ctx context.Context, accountID, apiToken string) (string, error) {
+	if accountID == "" || apiToken == "" {
+		return "", fmt.Errorf("CF_ACCOUNT_ID and CF_API_TOKEN environment variables required")
+	}
+
+	client, err := NewClient(Config{
+		APIToken:  apiToken,
+		AccountID: accountID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create CF client: %w", err)
+	}
+
+	inv, err := client.CaptureInventory(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := SaveInventory(inv, InventoryDir())
+	if err != nil {
+		return "", err
+	}
+
+	dnsCount := 0
+	for _, z := range inv.Zones {
+		dnsCount += len(z.DNSRecords)
+	}
+	log.Printf("Inventory captured at %s", inv.CapturedAt.Format(time.RFC3339))
+	log.Printf("  Zones:          %d (%d DNS records)", len(inv.Zones), dnsCount)
+	log.Printf("  Pages projects: %d", len(inv.PagesProjects))
+	log.Printf("  Workers:        %d", len(inv.Workers))
+	log.Printf("  KV namespaces:  %d", len(inv.KVNamespaces))
+	log.Printf("  Tokens:         %d", len(inv.Tokens))
+	for _, e := range inv.Errors {
+		log.Printf("  Warning: could not list %s", e)
+	}
+	log.Printf("Saved: %s", path)
+
+	return path, nil
+}
@@ -0,0 +1,82 @@
+package synccf
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffInventory(t *testing.T) {
+	before := &Inventory{
+		Zones: []InventoryZone{{
+			Name:   "example.com",
+			Status: "active",
+			DNSRecords: []InventoryDNSRecord{
+				{Type: "A", Name: "www.example.com", Content: "1.2.3.4", Proxied: true, TTL: 1},
+				{Type: "CNAME", Name: "old.example.com", Content: "example.com", TTL: 1},
+			},
+		}},
+		Workers: []string{"xplat-sync"},
+		Tokens:  []string{"deploy"},
+	}
+	after := &Inventory{
+		Zones: []InventoryZone{{
+			Name:   "example.com",
+			Status: "active",
+			DNSRecords: []InventoryDNSRecord{
+				{Type: "A", Name: "www.example.com", Content: "1.2.3.4", Proxied: false, TTL: 1},
+			},
+		}},
+		Workers:       []string{"xplat-sync"},
+		PagesProjects: []string{"docs"},
+		Tokens:        []string{"deploy"},
+	}
+
+	changes := DiffInventory(before, after)
+
+	expected := []InventoryChange{
+		{Kind: ChangeChanged, Resource: "dns/example.com/A www.example.com 1.2.3.4", Old: "proxied=true ttl=1", New: "proxied=false ttl=1"},
+		{Kind: ChangeRemoved, Resource: "dns/example.com/CNAME old.example.com example.com", Old: "proxied=false ttl=1"},
+		{Kind: ChangeAdded, Resource: "pages/docs"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+	for i, want := range expected {
+		if changes[i] != want {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want)
+		}
+	}
+
+	if got := DiffInventory(after, after); len(got) != 0 {
+		t.Errorf("identical snapshots should have no changes, got %+v", got)
+	}
+}
+
+func TestSaveLoadInventory(t *testing.T) {
+	dir := t.TempDir()
+
+	first := &Inventory{CapturedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Workers: []string{"a"}}
+	second := &Inventory{CapturedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Workers: []string{"b"}}
+
+	for _, inv := range []*Inventory{second, first} {
+		if _, err := SaveInventory(inv, dir); err != nil {
+			t.Fatalf("SaveInventory failed: %v", err)
+		}
+	}
+
+	snapshots, err := ListInventorySnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(snapshots))
+	}
+
+	latest, err := LoadInventory(snapshots[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(latest.Workers) != 1 || latest.Workers[0] != "b" {
+		t.Errorf("latest snapshot = %+v, want workers [b]", latest)
+	}
+}
@@ -0,0 +1,255 @@
+package synccf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed scenarios/*.yaml
+var builtinScenarios embed.FS
+
+// MockScenario is a scripted sequence of Worker events, replayed against a
+// local receiver by RunMockScenario to test callbacks and dedupe offline.
+type MockScenario struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description,omitempty"`
+	Batch       bool        `yaml:"batch,omitempty"` // Send all events as one JSON array (BATCH_MAX_EVENTS > 1)
+	Gzip        bool        `yaml:"gzip,omitempty"`  // gzip request bodies (FORWARD_GZIP)
+	Events      []MockEvent `yaml:"events"`
+}
+
+// MockEvent is one event in a scenario. Fields mirror WorkerEvent.
+type MockEvent struct {
+	Type      string                 `yaml:"type"`
+	Action    string                 `yaml:"action"`
+	Resource  string                 `yaml:"resource"`
+	Source    string                 `yaml:"source,omitempty"` // Defaults to "mock"
+	AccountID string                 `yaml:"account_id,omitempty"`
+	Metadata  map[string]interface{} `yaml:"metadata,omitempty"`
+	Raw       interface{}            `yaml:"raw,omitempty"` // Sent as JSON
+
+	// After is the delay before this event, relative to the previous one.
+	// It also advances the event timestamp.
+	After string `yaml:"after,omitempty"`
+
+	// Repeat sends the event this many extra times with the same timestamp,
+	// as the Worker does when it retries a forward. The receiver should
+	// treat the copies as duplicates.
+	Repeat int `yaml:"repeat,omitempty"`
+}
+
+// MockConfig configures where and how a scenario is sent.
+type MockConfig struct {
+	Target string       // Receiver URL (e.g. http://127.0.0.1:9091/)
+	Token  string       // Sent as Authorization: Bearer, like SYNC_TOKEN
+	Speed  float64      // Delay multiplier; 0 sends without waiting
+	Start  time.Time    // Timestamp of the first event (default: now)
+	Client *http.Client // Default: 10s timeout
+}
+
+// MockResult is the receiver's response to one request.
+type MockResult struct {
+	Events    []string // Event types in the request
+	Status    int
+	Duplicate bool // Receiver answered "OK (duplicate)"
+}
+
+// MockScenarioNames returns the names of the built-in scenarios.
+func MockScenarioNames() []string {
+	entries, _ := builtinScenarios.ReadDir("scenarios")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".yaml"))
+	}
+	return names
+}
+
+// LoadMockScenario reads a scenario file, or a built-in scenario by name
+// (e.g. "pages-deploy") when no such file exists.
+func LoadMockScenario(name string) (*MockScenario, error) {
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		builtin := path.Join("scenarios", strings.TrimSuffix(path.Base(name), ".yaml")+".yaml")
+		data, err = builtinScenarios.ReadFile(builtin)
+		if err != nil {
+			return nil, fmt.Errorf("scenario %s not found (built-in: %s)", name, strings.Join(MockScenarioNames(), ", "))
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var s MockScenario
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", name, err)
+	}
+	if len(s.Events) == 0 {
+		return nil, fmt.Errorf("scenario %s has no events", name)
+	}
+	for i, e := range s.Events {
+		if e.Type == "" {
+			return nil, fmt.Errorf("scenario %s: event %d has no type", name, i+1)
+		}
+		if e.After != "" {
+			if _, err := time.ParseDuration(e.After); err != nil {
+				return nil, fmt.Errorf("scenario %s: event %d: invalid after %q: %w", name, i+1, e.After, err)
+			}
+		}
+	}
+	return &s, nil
+}
+
+// WorkerEvents converts the scenario to the events the Worker would forward,
+// with timestamps starting at start. Repeats are included as copies.
+func (s *MockScenario) WorkerEvents(start time.Time) ([]WorkerEvent, error) {
+	var events []WorkerEvent
+	ts := start
+	for i, e := range s.Events {
+		if e.After != "" {
+			d, _ := time.ParseDuration(e.After)
+			ts = ts.Add(d)
+		}
+
+		event := WorkerEvent{
+			Type:      e.Type,
+			Timestamp: ts,
+			AccountID: e.AccountID,
+			Action:    e.Action,
+			Resource:  e.Resource,
+			Source:    e.Source,
+			Metadata:  e.Metadata,
+		}
+		if event.Source == "" {
+			event.Source = "mock"
+		}
+		if e.Raw != nil {
+			raw, err := json.Marshal(e.Raw)
+			if err != nil {
+				return nil, fmt.Errorf("event %d: failed to encode raw: %w", i+1, err)
+			}
+			event.Raw = raw
+		}
+
+		for n := 0; n <= e.Repeat; n++ {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// RunMockScenario sends a scenario's events to a receiver the way the Worker
+// forwards them: one POST per event, or a single batch when s.Batch is set.
+func RunMockScenario(ctx context.Context, s *MockScenario, cfg MockConfig) ([]MockResult, error) {
+	if cfg.Target == "" {
+		return nil, fmt.Errorf("mock target URL is required")
+	}
+	if cfg.Start.IsZero() {
+		cfg.Start = time.Now().UTC()
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	events, err := s.WorkerEvents(cfg.Start)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Batch {
+		r, err := postMockEvents(ctx, cfg, s.Gzip, events)
+		if err != nil {
+			return nil, err
+		}
+		return []MockResult{r}, nil
+	}
+
+	var results []MockResult
+	for i, event := range events {
+		if i > 0 && cfg.Speed > 0 {
+			if wait := time.Duration(float64(event.Timestamp.Sub(events[i-1].Timestamp)) * cfg.Speed); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return results, ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+
+		r, err := postMockEvents(ctx, cfg, s.Gzip, []WorkerEvent{event})
+		if err != nil {
+			return results, err
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// postMockEvents sends one request, with the headers the Worker's forward sets.
+func postMockEvents(ctx context.Context, cfg MockConfig, compress bool, events []WorkerEvent) (MockResult, error) {
+	result := MockResult{}
+	for _, e := range events {
+		result.Events = append(result.Events, e.Type)
+	}
+
+	var body []byte
+	var err error
+	if len(events) == 1 {
+		body, err = json.Marshal(events[0])
+	} else {
+		body, err = json.Marshal(events)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return result, fmt.Errorf("failed to gzip events: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return result, fmt.Errorf("failed to gzip events: %w", err)
+		}
+		body = buf.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Target, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	req.Header.Set("X-Sync-Batch-Size", strconv.Itoa(len(events)))
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return result, fmt.Errorf("failed to send events: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	result.Status = resp.StatusCode
+	result.Duplicate = strings.Contains(string(respBody), "duplicate")
+	if resp.StatusCode >= 400 {
+		return result, fmt.Errorf("receiver returned %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return result, nil
+}
@@ -0,0 +1,95 @@
+package synccf
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestReceiver(t *testing.T) *ReceiveHandler {
+	t.Helper()
+	return &ReceiveHandler{
+		state:     &ReceiverState{ProcessedEvents: make(map[string]ProcessedEvent)},
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+}
+
+func TestRunMockScenarioBuiltins(t *testing.T) {
+	h := newTestReceiver(t)
+	var pages, workerErrors int
+	var r2Created, r2Deleted []R2ObjectEvent
+	h.OnPagesDeploy(func(ctx context.Context, e WorkerEvent) error { pages++; return nil })
+	h.OnWorkerError(func(ctx context.Context, e WorkerEvent) error { workerErrors++; return nil })
+	h.OnR2ObjectCreated(func(ctx context.Context, e R2ObjectEvent) error { r2Created = append(r2Created, e); return nil })
+	h.OnR2ObjectDeleted(func(ctx context.Context, e R2ObjectEvent) error { r2Deleted = append(r2Deleted, e); return nil })
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx := context.Background()
+	cfg := MockConfig{Target: srv.URL, Start: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+	run := func(name string) []MockResult {
+		s, err := LoadMockScenario(name)
+		if err != nil {
+			t.Fatalf("LoadMockScenario(%s) failed: %v", name, err)
+		}
+		results, err := RunMockScenario(ctx, s, cfg)
+		if err != nil {
+			t.Fatalf("RunMockScenario(%s) failed: %v", name, err)
+		}
+		return results
+	}
+
+	// The retried deploy hook is reported as a duplicate and not dispatched twice
+	results := run("pages-deploy")
+	if len(results) != 3 || results[0].Duplicate || !results[1].Duplicate || results[2].Duplicate {
+		t.Errorf("pages-deploy results = %+v", results)
+	}
+	if pages != 2 {
+		t.Errorf("pages callbacks = %d, want 2", pages)
+	}
+
+	run("r2-objects")
+	if len(r2Created) != 3 || r2Created[2].CopySource == nil || len(r2Deleted) != 1 {
+		t.Errorf("r2 created = %+v, deleted = %+v", r2Created, r2Deleted)
+	}
+
+	// Batched and gzipped, like BATCH_MAX_EVENTS > 1 with FORWARD_GZIP
+	results = run("worker-error")
+	if len(results) != 1 || len(results[0].Events) != 2 || workerErrors != 1 {
+		t.Errorf("worker-error results = %+v, callbacks = %d", results, workerErrors)
+	}
+
+	// Replaying a whole scenario with the same start time is all duplicates
+	results = run("pages-deploy")
+	for _, r := range results {
+		if !r.Duplicate {
+			t.Errorf("replay result %+v, want duplicate", r)
+		}
+	}
+}
+
+func TestLoadMockScenarioErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"empty.yaml":     "name: empty\n",
+		"no-type.yaml":   "name: x\nevents:\n  - action: deploy\n",
+		"bad-after.yaml": "name: x\nevents:\n  - type: alert\n    after: soon\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadMockScenario(path); err == nil {
+			t.Errorf("LoadMockScenario(%s): expected error", name)
+		}
+	}
+
+	if _, err := LoadMockScenario("no-such-scenario"); err == nil {
+		t.Error("expected error for unknown scenario")
+	}
+}
@@ -0,0 +1,310 @@
+package synccf
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+// PagesFailureSource is the notification source of failed Pages deploys.
+const PagesFailureSource = "synccf/pages"
+
+// Build failure kinds, from AnalyzeBuildLog.
+const (
+	BuildFailureMissingEnv = "missing_env"
+	BuildFailureHugo       = "hugo"
+	BuildFailureNPM        = "npm"
+	BuildFailureUnknown    = "unknown"
+)
+
+// maxBuildErrorLines is how many error lines a build log analysis keeps.
+const maxBuildErrorLines = 10
+
+// PagesFailureIssueLabels are applied to issues filed for failed Pages deploys.
+var PagesFailureIssueLabels = []string{"cloudflare", "pages-build"}
+
+// deploymentIDKeys are the metadata keys holding a Pages deployment ID.
+var deploymentIDKeys = []string{"deployment_id", "deploymentId"}
+
+// PagesDeployFailed reports whether a pages_deploy event is for a failed
+// deployment: its status or stage status is "failure", or the notification
+// name or text says it failed.
+func PagesDeployFailed(event WorkerEvent) bool {
+	if event.Type != string(EventPagesDeploy) {
+		return false
+	}
+	for _, keys := range [][]string{{"status", "deployment_status"}, {"stage_status"}} {
+		switch strings.ToLower(deployMetadata(event.Metadata, keys)) {
+		case "failure", "failed", "error":
+			return true
+		}
+	}
+	s := strings.ToLower(event.Action + " " + metadataString(event.Metadata, "text"))
+	return strings.Contains(s, "fail")
+}
+
+// PagesDeployment returns the Pages project and deployment ID of an event,
+// or "" for those not in its metadata.
+func PagesDeployment(event WorkerEvent) (project, deploymentID string) {
+	return deployMetadata(event.Metadata, projectKeys), deployMetadata(event.Metadata, deploymentIDKeys)
+}
+
+// BuildLogAnalysis is the classified failure of a Pages build log.
+type BuildLogAnalysis struct {
+	Kind       string   `json:"kind"`                  // BuildFailure* constant
+	Summary    string   `json:"summary"`               // One line for notifications
+	MissingEnv []string `json:"missing_env,omitempty"` // Variables reported unset
+	ErrorLines []string `json:"error_lines,omitempty"` // First error lines of the log
+}
+
+var (
+	buildErrorLine = regexp.MustCompile(`(?i)(^|\s)(error|err!|fatal|failed)\b|error:|exception|exited with code|command not found|cannot find module`)
+
+	// Messages of build tools and frameworks about unset variables, e.g.
+	// "Environment variable API_KEY is not set" or "Missing env var: API_KEY".
+	missingEnvLine = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(?:environment variable|env var(?:iable)?)s?\W+([A-Z_][A-Z0-9_]*)\W+(?:is |was )?(?:not set|not defined|undefined|missing|required)`),
+		regexp.MustCompile(`(?i)missing (?:required )?(?:environment variable|env var(?:iable)?)s?\W+([A-Z_][A-Z0-9_]*)`),
+		regexp.MustCompile(`\b([A-Z][A-Z0-9_]{2,}) (?:is not set|is not defined|must be set|is required)`),
+		regexp.MustCompile(`process\.env\.([A-Z_][A-Z0-9_]*) is undefined`),
+	}
+
+	// Hugo reports "Error: error building site: ..." and, since v0.111,
+	// "ERROR <date> ..." lines.
+	hugoErrorLine = regexp.MustCompile(`(?i)error building site|hugo v\d|^ERROR \d{4}/|failed to render|template: .*:\d+`)
+
+	// npm 6 prints "npm ERR!", npm 7+ prints "npm error".
+	npmErrorLine = regexp.MustCompile(`(?i)npm (ERR!|error)|ERESOLVE|ELIFECYCLE|yarn error|pnpm ERR`)
+)
+
+// AnalyzeBuildLog classifies a failed build from its log lines: a missing
+// environment variable, a Hugo error or an npm failure, in that order, as the
+// first is often the cause of the others. The first error lines are kept
+// whatever the kind.
+func AnalyzeBuildLog(lines []string) BuildLogAnalysis {
+	var a BuildLogAnalysis
+	hugo, npm := "", ""
+	seenEnv := make(map[string]bool)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, re := range missingEnvLine {
+			if m := re.FindStringSubmatch(line); m != nil && !seenEnv[m[1]] {
+				seenEnv[m[1]] = true
+				a.MissingEnv = append(a.MissingEnv, m[1])
+			}
+		}
+		isError := buildErrorLine.MatchString(line)
+		if hugo == "" && isError && hugoErrorLine.MatchString(line) {
+			hugo = line
+		}
+		if npm == "" && npmErrorLine.MatchString(line) {
+			npm = line
+		}
+		if (isError || npmErrorLine.MatchString(line)) && len(a.ErrorLines) < maxBuildErrorLines {
+			a.ErrorLines = append(a.ErrorLines, line)
+		}
+	}
+
+	switch {
+	case len(a.MissingEnv) > 0:
+		a.Kind = BuildFailureMissingEnv
+		a.Summary = "Missing environment variable: " + strings.Join(a.MissingEnv, ", ")
+	case hugo != "":
+		a.Kind = BuildFailureHugo
+		a.Summary = "Hugo error: " + hugo
+	case npm != "":
+		a.Kind = BuildFailureNPM
+		a.Summary = "npm failure: " + npm
+	default:
+		a.Kind = BuildFailureUnknown
+		a.Summary = "Build failed"
+		if len(a.ErrorLines) > 0 {
+			a.Summary += ": " + a.ErrorLines[0]
+		}
+	}
+	return a
+}
+
+// PagesLogClient fetches Pages deployment build logs. It needs the Pages
+// read permission.
+type PagesLogClient struct {
+	AccountID string
+
+	apiToken   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPagesLogClient creates a build log client for an account.
+func NewPagesLogClient(accountID, apiToken string) (*PagesLogClient, error) {
+	if accountID == "" || apiToken == "" {
+		return nil, fmt.Errorf("account ID and API token are required")
+	}
+	return &PagesLogClient{
+		AccountID:  accountID,
+		apiToken:   apiToken,
+		baseURL:    cfAPIBase,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// SetBaseURL points the client at another API base URL (tests).
+func (c *PagesLogClient) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// BuildLog returns the build log lines of a deployment.
+func (c *PagesLogClient) BuildLog(ctx context.Context, project, deploymentID string) ([]string, error) {
+	path := fmt.Sprintf("/accounts/%s/pages/projects/%s/deployments/%s/history/logs",
+		c.AccountID, url.PathEscape(project), url.PathEscape(deploymentID))
+	var logs struct {
+		Data []struct {
+			Line string `json:"line"`
+		} `json:"data"`
+	}
+	if _, err := restGet(ctx, c.httpClient, c.baseURL+path, c.apiToken, &logs); err != nil {
+		return nil, fmt.Errorf("failed to fetch build log of %s/%s: %w", project, deploymentID, err)
+	}
+	lines := make([]string, len(logs.Data))
+	for i, l := range logs.Data {
+		lines[i] = l.Line
+	}
+	return lines, nil
+}
+
+// PagesFailureCallback returns a receive callback for failed Pages deploys:
+// it fetches and analyzes the build log, sends a notification and files (or
+// comments on) an issue per project and cause. logs, filer and router may be nil
+// to skip that step; without logs the failure is reported unanalyzed.
+func PagesFailureCallback(logs *PagesLogClient, filer IssueFiler, router *notify.Router) func(ctx context.Context, event WorkerEvent) error {
+	return func(ctx context.Context, event WorkerEvent) error {
+		if !PagesDeployFailed(event) {
+			return nil
+		}
+
+		var analysis *BuildLogAnalysis
+		project, deploymentID := PagesDeployment(event)
+		if logs != nil && project != "" && deploymentID != "" {
+			lines, err := logs.BuildLog(ctx, project, deploymentID)
+			if err != nil {
+				log.Printf("sync-cf receive: %v", err)
+			} else {
+				a := AnalyzeBuildLog(lines)
+				analysis = &a
+				log.Printf("sync-cf receive: %s/%s failed: %s", project, deploymentID, a.Summary)
+			}
+		}
+
+		if err := router.Notify(ctx, PagesFailureMessage(event, analysis)); err != nil {
+			log.Printf("sync-cf receive: notify: %v", err)
+		}
+
+		if filer == nil {
+			return nil
+		}
+		key, title, body := PagesFailureIssue(event, analysis)
+		url, created, err := filer.FileOrUpdateIssue(ctx, key, title, body, PagesFailureIssueLabels)
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Printf("sync-cf receive: filed issue %s", url)
+		} else {
+			log.Printf("sync-cf receive: updated issue %s", url)
+		}
+		return nil
+	}
+}
+
+// PagesFailureMessage returns the notification for a failed Pages deploy.
+// analysis is nil when the build log could not be fetched.
+func PagesFailureMessage(event WorkerEvent, analysis *BuildLogAnalysis) *notify.Message {
+	project, deploymentID := PagesDeployment(event)
+	if project == "" {
+		project = "unknown"
+	}
+	msg := &notify.Message{
+		Source:   PagesFailureSource,
+		Severity: notify.SeverityCritical,
+		Title:    fmt.Sprintf("Pages deploy of %s failed", project),
+		Fields:   map[string]string{},
+		Time:     event.Timestamp,
+	}
+	if deploymentID != "" {
+		msg.Fields["deployment"] = deploymentID
+	}
+	if branch := deployMetadata(event.Metadata, branchKeys); branch != "" {
+		msg.Fields["branch"] = branch
+	}
+	if commit := deployMetadata(event.Metadata, commitKeys); commit != "" {
+		msg.Fields["commit"] = commit
+	}
+	if analysis != nil {
+		msg.Text = analysis.Summary
+		msg.Fields["cause"] = analysis.Kind
+	} else if text := metadataString(event.Metadata, "text"); text != "" {
+		msg.Text = text
+	}
+	return msg
+}
+
+// PagesFailureIssue builds the dedup key, title and markdown body of the
+// issue for a failed Pages deploy: the deploy context, the build log
+// analysis and its first error lines. Failures of a project with the same
+// cause are added to one issue.
+func PagesFailureIssue(event WorkerEvent, analysis *BuildLogAnalysis) (key, title, body string) {
+	project, deploymentID := PagesDeployment(event)
+	if project == "" {
+		project = "unknown"
+	}
+	kind := BuildFailureUnknown
+	if analysis != nil {
+		kind = analysis.Kind
+	}
+
+	key = fmt.Sprintf("sync-cf/pages/%s/%s", project, kind)
+	title = fmt.Sprintf("Pages build failed: %s", project)
+	if kind != BuildFailureUnknown {
+		title += fmt.Sprintf(" (%s)", strings.ReplaceAll(kind, "_", " "))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** at %s\n", event.Action, event.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+	if text := metadataString(event.Metadata, "text"); text != "" {
+		fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(text, "\n", "\n> "))
+	}
+
+	b.WriteString("\n| Field | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| project | %s |\n", project)
+	if deploymentID != "" {
+		fmt.Fprintf(&b, "| deployment | %s |\n", deploymentID)
+	}
+	for _, f := range []struct {
+		name string
+		keys []string
+	}{{"branch", branchKeys}, {"commit", commitKeys}, {"url", deployURLKeys}} {
+		if v := deployMetadata(event.Metadata, f.keys); v != "" {
+			fmt.Fprintf(&b, "| %s | %s |\n", f.name, v)
+		}
+	}
+
+	if analysis == nil {
+		b.WriteString("\nBuild log not available.\n")
+		return key, title, strings.TrimSuffix(b.String(), "\n")
+	}
+	fmt.Fprintf(&b, "\n### Analysis\n\n%s\n", analysis.Summary)
+	if len(analysis.ErrorLines) > 0 {
+		fmt.Fprintf(&b, "\n```\n%s\n```\n", strings.Join(analysis.ErrorLines, "\n"))
+	}
+	return key, title, strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,177 @@
+package synccf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPagesDeployFailed(t *testing.T) {
+	tests := []struct {
+		name  string
+		event WorkerEvent
+		want  bool
+	}{
+		{"hook failure status", WorkerEvent{Type: "pages_deploy", Action: "deploy", Metadata: map[string]interface{}{"status": "failure"}}, true},
+		{"notification", WorkerEvent{Type: "pages_deploy", Action: "Pages deployment failed"}, true},
+		{"notification text", WorkerEvent{Type: "pages_deploy", Action: "Pages event", Metadata: map[string]interface{}{"text": "Deployment abc for docs failed"}}, true},
+		{"nested stage status", WorkerEvent{Type: "pages_deploy", Metadata: map[string]interface{}{"data": map[string]interface{}{"stage_status": "failure"}}}, true},
+		{"success", WorkerEvent{Type: "pages_deploy", Action: "Pages deployment succeeded"}, false},
+		{"other type", WorkerEvent{Type: "workers_error", Action: "Deploy failed"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PagesDeployFailed(tt.event); got != tt.want {
+				t.Errorf("PagesDeployFailed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBuildLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        string
+		kind       string
+		missingEnv []string
+		firstError string
+	}{
+		{"missing env", `
+Installing project dependencies: npm clean-install
+Executing user command: npm run build
+Error: Environment variable PUBLIC_API_URL is not set
+npm error Lifecycle script "build" failed with error:
+Failed: build command exited with code: 1`,
+			BuildFailureMissingEnv, []string{"PUBLIC_API_URL"}, "Error: Environment variable PUBLIC_API_URL is not set"},
+		{"hugo", `
+Executing user command: hugo --minify
+Start building sites …
+hugo v0.121.1-00b46fed extended linux/amd64 BuildDate=2023-12-08T08:47:45Z
+Error: error building site: render: failed to render pages: template: _default/single.html:12:3: executing "main" at <partial "x.html" .>: error calling partial
+Failed: build command exited with code: 255`,
+			BuildFailureHugo, nil, "Error: error building site: render: failed to render pages: template: _default/single.html:12:3: executing \"main\" at <partial \"x.html\" .>: error calling partial"},
+		{"hugo new style", `
+Executing user command: hugo
+ERROR 2024/01/02 03:04:05 render of "page" failed: "layouts/index.html:3:1": parse failed
+Failed: build command exited with code: 1`,
+			BuildFailureHugo, nil, `ERROR 2024/01/02 03:04:05 render of "page" failed: "layouts/index.html:3:1": parse failed`},
+		{"npm", `
+Installing project dependencies: npm clean-install
+npm ERR! code ERESOLVE
+npm ERR! ERESOLVE unable to resolve dependency tree
+Failed: Error while executing user command. Exited with error code: 1`,
+			BuildFailureNPM, nil, "npm ERR! code ERESOLVE"},
+		{"unknown", `
+Cloning repository...
+Failed: an internal error occurred`,
+			BuildFailureUnknown, nil, "Failed: an internal error occurred"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := AnalyzeBuildLog(strings.Split(tt.log, "\n"))
+			if a.Kind != tt.kind {
+				t.Errorf("Kind = %q, want %q (summary %q)", a.Kind, tt.kind, a.Summary)
+			}
+			if !reflect.DeepEqual(a.MissingEnv, tt.missingEnv) {
+				t.Errorf("MissingEnv = %v, want %v", a.MissingEnv, tt.missingEnv)
+			}
+			if len(a.ErrorLines) == 0 || a.ErrorLines[0] != tt.firstError {
+				t.Errorf("ErrorLines = %q, want first %q", a.ErrorLines, tt.firstError)
+			}
+		})
+	}
+}
+
+func TestAnalyzeBuildLogLimitsErrorLines(t *testing.T) {
+	lines := make([]string, 50)
+	for i := range lines {
+		lines[i] = "npm ERR! line"
+	}
+	if a := AnalyzeBuildLog(lines); len(a.ErrorLines) != maxBuildErrorLines {
+		t.Errorf("len(ErrorLines) = %d, want %d", len(a.ErrorLines), maxBuildErrorLines)
+	}
+}
+
+func TestPagesFailureCallback(t *testing.T) {
+	var gotPath string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success": true, "result": {"total": 3, "data": [
+			{"ts": "2026-01-02T03:04:00Z", "line": "Executing user command: npm run build"},
+			{"ts": "2026-01-02T03:04:01Z", "line": "npm error Missing script: \"build\""},
+			{"ts": "2026-01-02T03:04:02Z", "line": "Failed: build command exited with code: 1"}
+		]}}`))
+	}))
+	defer api.Close()
+
+	logs, err := NewPagesLogClient("acc", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs.SetBaseURL(api.URL)
+	filer := &recordingFiler{}
+
+	event := WorkerEvent{
+		Type:      "pages_deploy",
+		Timestamp: time.Date(2026, 1, 2, 3, 5, 0, 0, time.UTC),
+		Action:    "deploy",
+		Metadata: map[string]interface{}{
+			"project":       "docs",
+			"deployment_id": "abc123",
+			"status":        "failure",
+			"branch":        "main",
+		},
+	}
+	if err := PagesFailureCallback(logs, filer, nil)(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPath != "/accounts/acc/pages/projects/docs/deployments/abc123/history/logs" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if filer.key != "sync-cf/pages/docs/npm" {
+		t.Errorf("key = %q", filer.key)
+	}
+	if filer.title != "Pages build failed: docs (npm)" {
+		t.Errorf("title = %q", filer.title)
+	}
+	for _, want := range []string{
+		"| deployment | abc123 |",
+		"| branch | main |",
+		"### Analysis\n\nnpm failure: npm error Missing script: \"build\"",
+		"Failed: build command exited with code: 1",
+	} {
+		if !strings.Contains(filer.body, want) {
+			t.Errorf("body missing %q:\n%s", want, filer.body)
+		}
+	}
+
+	// Successful deploys are ignored
+	filer.key = ""
+	event.Metadata["status"] = "success"
+	if err := PagesFailureCallback(logs, filer, nil)(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if filer.key != "" {
+		t.Errorf("filed issue %q for a successful deploy", filer.key)
+	}
+}
+
+func TestPagesFailureMessageWithoutLog(t *testing.T) {
+	msg := PagesFailureMessage(WorkerEvent{
+		Type:     "pages_deploy",
+		Action:   "Pages deployment failed",
+		Metadata: map[string]interface{}{"text": "Deployment for docs failed", "data": map[string]interface{}{"project_name": "docs"}},
+	}, nil)
+	if msg.Title != "Pages deploy of docs failed" || msg.Text != "Deployment for docs failed" {
+		t.Errorf("message = %+v", msg)
+	}
+	if _, ok := msg.Fields["cause"]; ok {
+		t.Errorf("cause set without an analysis: %v", msg.Fields)
+	}
+}
@@ -0,0 +1,296 @@
+package synccf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tunnel provider names.
+const (
+	ProviderCloudflared = "cloudflared"
+	ProviderTailscale   = "tailscale"
+	ProviderNgrok       = "ngrok"
+)
+
+// Providers lists the available provider names.
+var Providers = []string{ProviderCloudflared, ProviderTailscale, ProviderNgrok}
+
+// providerURLTimeout is how long Start waits for a provider to report its public URL.
+const providerURLTimeout = 30 * time.Second
+
+// Provider exposes a local port on a public HTTPS URL, so the sync Worker
+// can forward events to the receiver. Some networks block cloudflared, so
+// the receiver, wizard and CLI only depend on this interface.
+type Provider interface {
+	// Name returns the provider name (e.g. "cloudflared", "ngrok").
+	Name() string
+
+	// Start exposes localhost:port and returns the public URL once it is
+	// known. The URL is empty for providers that do not report it (named
+	// cloudflared tunnels). The tunnel runs until ctx is done or Stop is called.
+	Start(ctx context.Context, port int) (string, error)
+
+	// Stop closes the tunnel.
+	Stop()
+}
+
+// ProviderOptions configures NewProvider.
+type ProviderOptions struct {
+	// TunnelName runs a named cloudflared tunnel instead of a quick tunnel (cloudflared only)
+	TunnelName string
+}
+
+// NewProvider creates a tunnel provider by name. An empty name is cloudflared.
+func NewProvider(name string, opts ProviderOptions) (Provider, error) {
+	if opts.TunnelName != "" && name != ProviderCloudflared && name != "" {
+		return nil, fmt.Errorf("named tunnels are only supported by the %s provider", ProviderCloudflared)
+	}
+	switch name {
+	case ProviderCloudflared, "":
+		return &CloudflaredProvider{TunnelName: opts.TunnelName}, nil
+	case ProviderTailscale:
+		return &TailscaleProvider{}, nil
+	case ProviderNgrok:
+		return &NgrokProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tunnel provider %q (valid: %s)", name, strings.Join(Providers, ", "))
+	}
+}
+
+// CloudflaredProvider runs a cloudflared quick tunnel, or a named tunnel
+// when TunnelName is set. cloudflared is installed if missing.
+type CloudflaredProvider struct {
+	TunnelName string
+
+	tunnel *Tunnel
+}
+
+// Name implements Provider.
+func (p *CloudflaredProvider) Name() string { return ProviderCloudflared }
+
+// Start implements Provider.
+func (p *CloudflaredProvider) Start(ctx context.Context, port int) (string, error) {
+	cfPath, err := getCloudflaredPath()
+	if err != nil {
+		return "", err
+	}
+
+	p.tunnel = NewTunnel(TunnelConfig{
+		Name:            p.TunnelName,
+		LocalPort:       port,
+		CloudflaredPath: cfPath,
+	})
+	if err := p.tunnel.Start(ctx); err != nil {
+		return "", err
+	}
+	return p.tunnel.URL(), nil
+}
+
+// Stop implements Provider.
+func (p *CloudflaredProvider) Stop() {
+	if p.tunnel != nil {
+		p.tunnel.Stop()
+	}
+}
+
+// TailscaleProvider exposes the port with Tailscale Funnel on the machine's
+// https://<host>.<tailnet>.ts.net name. Funnel must be enabled for the tailnet.
+type TailscaleProvider struct {
+	proc providerProcess
+}
+
+// Name implements Provider.
+func (p *TailscaleProvider) Name() string { return ProviderTailscale }
+
+// Start implements Provider.
+func (p *TailscaleProvider) Start(ctx context.Context, port int) (string, error) {
+	path, err := findTailscale()
+	if err != nil {
+		return "", err
+	}
+	// Runs in the foreground; the funnel is removed when the process exits
+	return p.proc.start(ctx, ProviderTailscale, tailscaleURL, path, "funnel", strconv.Itoa(port))
+}
+
+// Stop implements Provider.
+func (p *TailscaleProvider) Stop() { p.proc.stop() }
+
+// tailscaleURL extracts the funnel URL from a 'tailscale funnel' output line.
+func tailscaleURL(line string) string {
+	if !strings.Contains(line, ".ts.net") {
+		return ""
+	}
+	return strings.TrimSuffix(extractURL(line), "/")
+}
+
+// findTailscale returns the path to the tailscale CLI.
+func findTailscale() (string, error) {
+	if path, err := exec.LookPath("tailscale"); err == nil {
+		return path, nil
+	}
+	if runtime.GOOS == "darwin" {
+		// The Mac App Store build does not put the CLI on PATH
+		if app := "/Applications/Tailscale.app/Contents/MacOS/Tailscale"; fileExists(app) {
+			return app, nil
+		}
+	}
+	return "", fmt.Errorf("tailscale not found (install from https://tailscale.com/download)")
+}
+
+// NgrokProvider exposes the port with an ngrok HTTP tunnel. ngrok needs an
+// auth token: ngrok config add-authtoken <token>.
+type NgrokProvider struct {
+	proc providerProcess
+}
+
+// Name implements Provider.
+func (p *NgrokProvider) Name() string { return ProviderNgrok }
+
+// Start implements Provider.
+func (p *NgrokProvider) Start(ctx context.Context, port int) (string, error) {
+	path, err := exec.LookPath("ngrok")
+	if err != nil {
+		return "", fmt.Errorf("ngrok not found (install from https://ngrok.com/download)")
+	}
+	return p.proc.start(ctx, ProviderNgrok, ngrokURL, path,
+		"http", strconv.Itoa(port), "--log", "stdout", "--log-format", "json")
+}
+
+// Stop implements Provider.
+func (p *NgrokProvider) Stop() { p.proc.stop() }
+
+// ngrokURL extracts the public URL from an ngrok JSON log line.
+func ngrokURL(line string) string {
+	var entry struct {
+		Msg string `json:"msg"`
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return ""
+	}
+	if entry.Msg != "started tunnel" || !strings.HasPrefix(entry.URL, "https://") {
+		return ""
+	}
+	return entry.URL
+}
+
+// providerProcess runs a tunnel CLI and waits for its public URL, shared by
+// the tailscale and ngrok providers.
+type providerProcess struct {
+	mu  sync.Mutex
+	cmd *exec.Cmd
+}
+
+// start runs bin with args, logging its output, and returns the first URL
+// that parseURL finds in it.
+func (p *providerProcess) start(ctx context.Context, name string, parseURL func(string) string, bin string, args ...string) (string, error) {
+	p.mu.Lock()
+	if p.cmd != nil {
+		p.mu.Unlock()
+		return "", fmt.Errorf("tunnel already running")
+	}
+	cmd := exec.CommandContext(ctx, bin, args...)
+	p.cmd = cmd
+	p.mu.Unlock()
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return "", fmt.Errorf("failed to get stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		p.reset()
+		return "", fmt.Errorf("failed to start %s: %w", name, err)
+	}
+	log.Printf("sync-cf: %s tunnel starting (pid: %d)", name, cmd.Process.Pid)
+
+	urlCh := make(chan string, 1)
+	var wg sync.WaitGroup
+	scan := func(r io.Reader) {
+		defer wg.Done()
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := scanner.Text()
+			log.Printf("sync-cf [%s]: %s", name, line)
+			if url := parseURL(line); url != "" {
+				select {
+				case urlCh <- url:
+				default:
+				}
+			}
+		}
+	}
+	wg.Add(2)
+	go scan(stdout)
+	go scan(stderr)
+
+	// Output ends when the process exits
+	exited := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(exited)
+	}()
+
+	select {
+	case url := <-urlCh:
+		log.Printf("sync-cf: tunnel ready at %s", url)
+		return url, nil
+	case <-exited:
+		p.stop()
+		return "", fmt.Errorf("%s exited before reporting a public URL", name)
+	case <-time.After(providerURLTimeout):
+		p.stop()
+		return "", fmt.Errorf("timeout waiting for %s tunnel URL", name)
+	case <-ctx.Done():
+		p.stop()
+		return "", ctx.Err()
+	}
+}
+
+// stop interrupts the process so it can remove its tunnel, then kills it
+// if it has not exited within a few seconds.
+func (p *providerProcess) stop() {
+	p.mu.Lock()
+	cmd := p.cmd
+	p.mu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	log.Printf("sync-cf: stopping tunnel")
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		_ = cmd.Process.Kill() // No interrupt on Windows
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+	p.reset()
+}
+
+func (p *providerProcess) reset() {
+	p.mu.Lock()
+	p.cmd = nil
+	p.mu.Unlock()
+}
@@ -0,0 +1,41 @@
+package synccf
+
+import "testing"
+
+func TestProviderURL(t *testing.T) {
+	tests := []struct {
+		parse func(string) string
+		line  string
+		want  string
+	}{
+		{tailscaleURL, "https://laptop.tail1234.ts.net/", "https://laptop.tail1234.ts.net"},
+		{tailscaleURL, "|-- / proxy http://127.0.0.1:9091", ""},
+		{tailscaleURL, "Available on the internet:", ""},
+		{ngrokURL, `{"lvl":"info","msg":"started tunnel","obj":"tunnels","name":"command_line","addr":"http://localhost:9091","url":"https://ab12.ngrok-free.app"}`, "https://ab12.ngrok-free.app"},
+		{ngrokURL, `{"lvl":"info","msg":"client session established","obj":"tunnels.session"}`, ""},
+		{ngrokURL, "ERROR: authentication failed", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.parse(tt.line); got != tt.want {
+			t.Errorf("parse(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	for _, name := range append([]string{""}, Providers...) {
+		p, err := NewProvider(name, ProviderOptions{})
+		if err != nil {
+			t.Fatalf("NewProvider(%q): %v", name, err)
+		}
+		if want := name; want != "" && p.Name() != want {
+			t.Errorf("NewProvider(%q).Name() = %q", name, p.Name())
+		}
+	}
+	if _, err := NewProvider("frp", ProviderOptions{}); err == nil {
+		t.Error("NewProvider(frp) succeeded")
+	}
+	if _, err := NewProvider(ProviderNgrok, ProviderOptions{TunnelName: "webhook"}); err == nil {
+		t.Error("named ngrok tunnel accepted")
+	}
+}
@@ -0,0 +1,87 @@
+package synccf
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// R2 event notification actions, as sent by Cloudflare to the queue.
+const (
+	R2ActionPutObject               = "PutObject"
+	R2ActionCopyObject              = "CopyObject"
+	R2ActionCompleteMultipartUpload = "CompleteMultipartUpload"
+	R2ActionDeleteObject            = "DeleteObject"
+	R2ActionLifecycleDeletion       = "LifecycleDeletion"
+)
+
+// R2EventType maps an R2 notification action to its event type:
+// EventR2ObjectCreated, EventR2ObjectDeleted or "" for unknown actions.
+// Keep in sync with r2EventType in workers/sync-cf/main.go.
+func R2EventType(action string) EventType {
+	switch action {
+	case R2ActionPutObject, R2ActionCopyObject, R2ActionCompleteMultipartUpload:
+		return EventR2ObjectCreated
+	case R2ActionDeleteObject, R2ActionLifecycleDeletion:
+		return EventR2ObjectDeleted
+	}
+	return ""
+}
+
+// R2ObjectEvent is an R2 event notification message
+// (https://developers.cloudflare.com/r2/buckets/event-notifications/).
+type R2ObjectEvent struct {
+	Account    string        `json:"account"`
+	Action     string        `json:"action"`
+	Bucket     string        `json:"bucket"`
+	Object     R2Object      `json:"object"`
+	EventTime  time.Time     `json:"eventTime"`
+	CopySource *R2CopySource `json:"copySource,omitempty"` // CopyObject only
+}
+
+// R2Object identifies the object an R2 event is about.
+// Size and ETag are not set for deletions.
+type R2Object struct {
+	Key  string `json:"key"`
+	Size int64  `json:"size,omitempty"`
+	ETag string `json:"eTag,omitempty"`
+}
+
+// R2CopySource is the source object of a CopyObject event.
+type R2CopySource struct {
+	Bucket string `json:"bucket"`
+	Object string `json:"object"`
+}
+
+// Created returns true for uploads, copies and completed multipart uploads.
+func (e *R2ObjectEvent) Created() bool {
+	return R2EventType(e.Action) == EventR2ObjectCreated
+}
+
+// ParseR2ObjectEvent extracts the R2 notification from an r2_object_created
+// or r2_object_deleted event. The notification is the event's Raw body; when
+// the Worker dropped Raw, it is rebuilt from the event's metadata.
+func ParseR2ObjectEvent(event WorkerEvent) (*R2ObjectEvent, error) {
+	var e R2ObjectEvent
+	if len(event.Raw) > 0 {
+		if err := json.Unmarshal(event.Raw, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse R2 notification: %w", err)
+		}
+	} else {
+		e = R2ObjectEvent{
+			Account:   event.AccountID,
+			Action:    event.Action,
+			Bucket:    metadataString(event.Metadata, "bucket"),
+			Object:    R2Object{Key: metadataString(event.Metadata, "key"), ETag: metadataString(event.Metadata, "etag")},
+			EventTime: event.Timestamp,
+		}
+		if size, ok := event.Metadata["size"].(float64); ok {
+			e.Object.Size = int64(size)
+		}
+	}
+
+	if e.Bucket == "" || e.Object.Key == "" {
+		return nil, fmt.Errorf("event %s has no R2 bucket or object key", event.Type)
+	}
+	return &e, nil
+}
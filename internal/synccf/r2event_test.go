@@ -0,0 +1,101 @@
+package synccf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestR2EventType(t *testing.T) {
+	tests := []struct {
+		action string
+		want   EventType
+	}{
+		{R2ActionPutObject, EventR2ObjectCreated},
+		{R2ActionCopyObject, EventR2ObjectCreated},
+		{R2ActionCompleteMultipartUpload, EventR2ObjectCreated},
+		{R2ActionDeleteObject, EventR2ObjectDeleted},
+		{R2ActionLifecycleDeletion, EventR2ObjectDeleted},
+		{"GetObject", ""},
+	}
+	for _, tt := range tests {
+		if got := R2EventType(tt.action); got != tt.want {
+			t.Errorf("R2EventType(%q) = %q, want %q", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestParseR2ObjectEvent(t *testing.T) {
+	raw := `{"account":"acc","action":"CopyObject","bucket":"builds","object":{"key":"app/v1.tar.gz","size":1024,"eTag":"abc"},"eventTime":"2026-10-01T12:00:00.123Z","copySource":{"bucket":"staging","object":"app.tar.gz"}}`
+
+	e, err := ParseR2ObjectEvent(WorkerEvent{Type: string(EventR2ObjectCreated), Raw: []byte(raw)})
+	if err != nil {
+		t.Fatalf("ParseR2ObjectEvent failed: %v", err)
+	}
+	if e.Bucket != "builds" || e.Object.Key != "app/v1.tar.gz" || e.Object.Size != 1024 || e.Object.ETag != "abc" {
+		t.Errorf("parsed %+v", e)
+	}
+	if e.CopySource == nil || e.CopySource.Bucket != "staging" || !e.Created() {
+		t.Errorf("copy source = %+v, created = %v", e.CopySource, e.Created())
+	}
+
+	// Raw dropped by the Worker: rebuilt from metadata
+	e, err = ParseR2ObjectEvent(WorkerEvent{
+		Type:         string(EventR2ObjectDeleted),
+		Action:       R2ActionDeleteObject,
+		RawTruncated: true,
+		Metadata:     map[string]interface{}{"bucket": "builds", "key": "old.tar.gz"},
+	})
+	if err != nil {
+		t.Fatalf("ParseR2ObjectEvent (metadata) failed: %v", err)
+	}
+	if e.Bucket != "builds" || e.Object.Key != "old.tar.gz" || e.Created() {
+		t.Errorf("parsed %+v", e)
+	}
+
+	if _, err := ParseR2ObjectEvent(WorkerEvent{Type: string(EventR2ObjectCreated)}); err == nil {
+		t.Error("expected error for event without bucket or key")
+	}
+}
+
+func TestReceiveHandlerR2Events(t *testing.T) {
+	h := &ReceiveHandler{
+		state:     &ReceiverState{ProcessedEvents: make(map[string]ProcessedEvent)},
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+
+	var created, deleted []R2ObjectEvent
+	h.OnR2ObjectCreated(func(ctx context.Context, e R2ObjectEvent) error {
+		created = append(created, e)
+		return nil
+	})
+	h.OnR2ObjectDeleted(func(ctx context.Context, e R2ObjectEvent) error {
+		deleted = append(deleted, e)
+		return nil
+	})
+
+	// Two writes to the same object within a second are distinct events
+	body := `[
+		{"type":"r2_object_created","action":"PutObject","resource":"b/k","timestamp":"2026-10-01T12:00:00.100Z",
+		 "raw":{"action":"PutObject","bucket":"b","object":{"key":"k","size":1}}},
+		{"type":"r2_object_created","action":"PutObject","resource":"b/k","timestamp":"2026-10-01T12:00:00.900Z",
+		 "raw":{"action":"PutObject","bucket":"b","object":{"key":"k","size":2}}},
+		{"type":"r2_object_deleted","action":"LifecycleDeletion","resource":"b/old","timestamp":"2026-10-01T12:00:01Z",
+		 "raw":{"action":"LifecycleDeletion","bucket":"b","object":{"key":"old"}}}
+	]`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	if len(created) != 2 || created[1].Object.Size != 2 {
+		t.Errorf("created = %+v, want both writes", created)
+	}
+	if len(deleted) != 1 || deleted[0].Object.Key != "old" {
+		t.Errorf("deleted = %+v", deleted)
+	}
+}
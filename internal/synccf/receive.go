@@ -1,8 +1,11 @@
 package synccf
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -13,6 +16,7 @@ import (
 	"time"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
 )
 
 // WorkerEvent represents the normalized event format from sync-cf Worker.
@@ -27,6 +31,11 @@ type WorkerEvent struct {
 	Source    string                 `json:"source"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	Raw       json.RawMessage        `json:"raw,omitempty"`
+
+	// Set when the Worker dropped Raw for exceeding MAX_PAYLOAD_BYTES
+	RawTruncated bool   `json:"raw_truncated,omitempty"`
+	RawSize      int    `json:"raw_size,omitempty"`
+	RawRef       string `json:"raw_ref,omitempty"` // r2://<bucket>/<key> holding the full body
 }
 
 // ReceiverState tracks processed events to avoid duplicates
@@ -50,21 +59,43 @@ type ReceiveHandler struct {
 	onPagesDeploy func(ctx context.Context, event WorkerEvent) error
 	onAlert       func(ctx context.Context, event WorkerEvent) error
 	onLogpush     func(ctx context.Context, event WorkerEvent) error
+	onWorkerError func(ctx context.Context, event WorkerEvent) error
+	onR2Created   func(ctx context.Context, event R2ObjectEvent) error
+	onR2Deleted   func(ctx context.Context, event R2ObjectEvent) error
 	onAny         func(ctx context.Context, event WorkerEvent) error
 	state         *ReceiverState
 	statePath     string
 }
 
+// MaxReceiveBodySize caps an event request body, both as sent and after gzip
+// decompression. A batch of 100 events at the Worker's default 256KB
+// MAX_PAYLOAD_BYTES stays well below it.
+var MaxReceiveBodySize int64 = 32 * 1024 * 1024
+
+// errBodyTooLarge is returned by readEventBody for bodies over MaxReceiveBodySize.
+var errBodyTooLarge = errors.New("request body too large")
+
+// receiveStateVersion is the ReceiverState schema version (see statestore)
+const receiveStateVersion = 1
+
+// receiveStatePath returns the receiver state file.
+func receiveStatePath() string {
+	return filepath.Join(config.XplatCache(), "synccf-receive-state.json")
+}
+
 // NewReceiveHandler creates a new receive handler
 func NewReceiveHandler() *ReceiveHandler {
-	statePath := filepath.Join(config.XplatCache(), "synccf-receive-state.json")
+	statePath := receiveStatePath()
 	state := &ReceiverState{
 		ProcessedEvents: make(map[string]ProcessedEvent),
 	}
 
 	// Try to load existing state
-	if data, err := os.ReadFile(statePath); err == nil {
-		_ = json.Unmarshal(data, state)
+	if loaded, err := statestore.New[ReceiverState](statePath, receiveStateVersion).Load(); err == nil {
+		state = loaded
+		if state.ProcessedEvents == nil {
+			state.ProcessedEvents = make(map[string]ProcessedEvent)
+		}
 	}
 
 	return &ReceiveHandler{
@@ -94,6 +125,30 @@ func (h *ReceiveHandler) OnLogpush(fn func(ctx context.Context, event WorkerEven
 	h.onLogpush = fn
 }
 
+// OnWorkerError registers a callback for Worker error alerts
+// (error rate and CPU exceeded workers_event notifications)
+func (h *ReceiveHandler) OnWorkerError(fn func(ctx context.Context, event WorkerEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onWorkerError = fn
+}
+
+// OnR2ObjectCreated registers a callback for R2 object uploads, copies and
+// completed multipart uploads (R2 event notifications consumed by the Worker)
+func (h *ReceiveHandler) OnR2ObjectCreated(fn func(ctx context.Context, event R2ObjectEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onR2Created = fn
+}
+
+// OnR2ObjectDeleted registers a callback for R2 object deletions,
+// including lifecycle expirations
+func (h *ReceiveHandler) OnR2ObjectDeleted(fn func(ctx context.Context, event R2ObjectEvent) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onR2Deleted = fn
+}
+
 // OnAny registers a callback for all events
 func (h *ReceiveHandler) OnAny(fn func(ctx context.Context, event WorkerEvent) error) {
 	h.mu.Lock()
@@ -108,23 +163,101 @@ func (h *ReceiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	body, err := io.ReadAll(r.Body)
+	r.Body = http.MaxBytesReader(w, r.Body, MaxReceiveBodySize)
+	defer func() { _ = r.Body.Close() }()
+
+	body, err := readEventBody(r)
 	if err != nil {
 		log.Printf("sync-cf receive: failed to read body: %v", err)
+		var maxErr *http.MaxBytesError
+		if errors.Is(err, errBodyTooLarge) || errors.As(err, &maxErr) {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
-	defer func() { _ = r.Body.Close() }()
 
-	var event WorkerEvent
-	if err := json.Unmarshal(body, &event); err != nil {
+	events, err := parseWorkerEvents(body)
+	if err != nil {
 		log.Printf("sync-cf receive: failed to parse event: %v", err)
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	// Generate event key for deduplication
-	eventKey := fmt.Sprintf("%s:%s:%s:%d", event.Type, event.Action, event.Resource, event.Timestamp.Unix())
+	duplicates := 0
+	for _, event := range events {
+		if !h.processEvent(r.Context(), event) {
+			duplicates++
+		}
+	}
+	h.saveState()
+
+	w.WriteHeader(http.StatusOK)
+	if len(events) > 0 && duplicates == len(events) {
+		_, _ = fmt.Fprint(w, "OK (duplicate)")
+		return
+	}
+	_, _ = fmt.Fprint(w, "OK")
+}
+
+// readEventBody reads the request body, decompressing gzip from batched
+// forwards. The decompressed size is capped too, so a small gzip bomb cannot
+// exhaust memory.
+func readEventBody(r *http.Request) ([]byte, error) {
+	if r.Header.Get("Content-Encoding") != "gzip" {
+		return io.ReadAll(r.Body)
+	}
+
+	zr, err := gzip.NewReader(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+	body, err := io.ReadAll(io.LimitReader(zr, MaxReceiveBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > MaxReceiveBodySize {
+		return nil, errBodyTooLarge
+	}
+	return body, nil
+}
+
+// parseWorkerEvents parses a single event object or a batch (JSON array).
+func parseWorkerEvents(body []byte) ([]WorkerEvent, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var events []WorkerEvent
+		if err := json.Unmarshal(trimmed, &events); err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+
+	var event WorkerEvent
+	if err := json.Unmarshal(trimmed, &event); err != nil {
+		return nil, err
+	}
+	return []WorkerEvent{event}, nil
+}
+
+// dedupeKey identifies an event for deduplication. Keys are persisted in the
+// receiver state, so the format of existing event types must not change. R2
+// object events use millisecond timestamps, so quick successive writes to the
+// same object are not taken for duplicates.
+func dedupeKey(event WorkerEvent) string {
+	ts := event.Timestamp.Unix()
+	if event.Type == string(EventR2ObjectCreated) || event.Type == string(EventR2ObjectDeleted) {
+		ts = event.Timestamp.UnixMilli()
+	}
+	return fmt.Sprintf("%s:%s:%s:%d", event.Type, event.Action, event.Resource, ts)
+}
+
+// processEvent dispatches one event to the callbacks.
+// Returns false if the event was already processed.
+func (h *ReceiveHandler) processEvent(ctx context.Context, event WorkerEvent) bool {
+	eventKey := dedupeKey(event)
 
 	h.mu.RLock()
 	_, alreadyProcessed := h.state.ProcessedEvents[eventKey]
@@ -132,19 +265,22 @@ func (h *ReceiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if alreadyProcessed {
 		log.Printf("sync-cf receive: skipping duplicate event: %s", eventKey)
-		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprint(w, "OK (duplicate)")
-		return
+		return false
 	}
 
 	log.Printf("sync-cf receive: [%s] %s on %s (source: %s)", event.Type, event.Action, event.Resource, event.Source)
+	if event.RawTruncated {
+		log.Printf("sync-cf receive: raw payload (%d bytes) not included, full body at %s", event.RawSize, event.RawRef)
+	}
 
 	// Dispatch to handlers
-	ctx := r.Context()
 	h.mu.RLock()
 	onPagesDeploy := h.onPagesDeploy
 	onAlert := h.onAlert
 	onLogpush := h.onLogpush
+	onWorkerError := h.onWorkerError
+	onR2Created := h.onR2Created
+	onR2Deleted := h.onR2Deleted
 	onAny := h.onAny
 	h.mu.RUnlock()
 
@@ -168,6 +304,24 @@ func (h *ReceiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 				log.Printf("sync-cf receive: logpush handler error: %v", err)
 			}
 		}
+	case "workers_error":
+		if onWorkerError != nil {
+			if err := onWorkerError(ctx, event); err != nil {
+				log.Printf("sync-cf receive: workers_error handler error: %v", err)
+			}
+		}
+	case string(EventR2ObjectCreated), string(EventR2ObjectDeleted):
+		fn := onR2Created
+		if event.Type == string(EventR2ObjectDeleted) {
+			fn = onR2Deleted
+		}
+		if fn != nil {
+			if r2, err := ParseR2ObjectEvent(event); err != nil {
+				log.Printf("sync-cf receive: %s: %v", event.Type, err)
+			} else if err := fn(ctx, *r2); err != nil {
+				log.Printf("sync-cf receive: %s handler error: %v", event.Type, err)
+			}
+		}
 	}
 
 	// Call any handler
@@ -202,27 +356,14 @@ func (h *ReceiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	h.mu.Unlock()
 
-	// Save state
-	h.saveState()
-
-	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprint(w, "OK")
+	return true
 }
 
 func (h *ReceiveHandler) saveState() {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	// Ensure directory exists
-	_ = os.MkdirAll(filepath.Dir(h.statePath), 0755)
-
-	data, err := json.MarshalIndent(h.state, "", "  ")
-	if err != nil {
-		log.Printf("sync-cf receive: failed to marshal state: %v", err)
-		return
-	}
-
-	if err := os.WriteFile(h.statePath, data, 0644); err != nil {
+	if err := statestore.New[ReceiverState](h.statePath, receiveStateVersion).Save(h.state); err != nil {
 		log.Printf("sync-cf receive: failed to save state: %v", err)
 	}
 }
@@ -252,6 +393,15 @@ func RunReceiveServer(port string, callbacks ReceiveCallbacks) error {
 	if callbacks.OnLogpush != nil {
 		handler.OnLogpush(callbacks.OnLogpush)
 	}
+	if callbacks.OnWorkerError != nil {
+		handler.OnWorkerError(callbacks.OnWorkerError)
+	}
+	if callbacks.OnR2ObjectCreated != nil {
+		handler.OnR2ObjectCreated(callbacks.OnR2ObjectCreated)
+	}
+	if callbacks.OnR2ObjectDeleted != nil {
+		handler.OnR2ObjectDeleted(callbacks.OnR2ObjectDeleted)
+	}
 	if callbacks.OnAny != nil {
 		handler.OnAny(callbacks.OnAny)
 	}
@@ -291,10 +441,13 @@ func RunReceiveServer(port string, callbacks ReceiveCallbacks) error {
 
 // ReceiveCallbacks holds optional callbacks for receive events
 type ReceiveCallbacks struct {
-	OnPagesDeploy func(ctx context.Context, event WorkerEvent) error
-	OnAlert       func(ctx context.Context, event WorkerEvent) error
-	OnLogpush     func(ctx context.Context, event WorkerEvent) error
-	OnAny         func(ctx context.Context, event WorkerEvent) error
+	OnPagesDeploy     func(ctx context.Context, event WorkerEvent) error
+	OnAlert           func(ctx context.Context, event WorkerEvent) error
+	OnLogpush         func(ctx context.Context, event WorkerEvent) error
+	OnWorkerError     func(ctx context.Context, event WorkerEvent) error
+	OnR2ObjectCreated func(ctx context.Context, event R2ObjectEvent) error
+	OnR2ObjectDeleted func(ctx context.Context, event R2ObjectEvent) error
+	OnAny             func(ctx context.Context, event WorkerEvent) error
 }
 
 // DefaultLogCallback returns a logging callback for debugging
@@ -312,11 +465,9 @@ func DefaultLogCallback() func(ctx context.Context, event WorkerEvent) error {
 
 // LoadReceiveState loads the current receive state from disk
 func LoadReceiveState() (*ReceiverState, error) {
-	statePath := filepath.Join(config.XplatCache(), "synccf-receive-state.json")
-
-	data, err := os.ReadFile(statePath)
+	state, err := statestore.New[ReceiverState](receiveStatePath(), receiveStateVersion).Load()
 	if err != nil {
-		if os.IsNotExist(err) {
+		if errors.Is(err, os.ErrNotExist) {
 			return &ReceiverState{
 				ProcessedEvents: make(map[string]ProcessedEvent),
 			}, nil
@@ -324,10 +475,5 @@ func LoadReceiveState() (*ReceiverState, error) {
 		return nil, fmt.Errorf("failed to read state: %w", err)
 	}
 
-	var state ReceiverState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, fmt.Errorf("failed to parse state: %w", err)
-	}
-
-	return &state, nil
+	return state, nil
 }
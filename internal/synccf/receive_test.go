@@ -0,0 +1,139 @@
+package synccf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReceiveHandlerPayloads(t *testing.T) {
+	single := `{"type":"pages","action":"deploy","resource":"site","timestamp":"2026-01-02T03:04:05Z"}`
+	batch := `[
+		{"type":"pages","action":"deploy","resource":"a","timestamp":"2026-01-02T03:04:05Z"},
+		{"type":"alert","action":"fired","resource":"b","timestamp":"2026-01-02T03:04:06Z","raw_truncated":true,"raw_size":300000,"raw_ref":"r2://raw/x.json"}
+	]`
+
+	tests := []struct {
+		name       string
+		body       string
+		gzip       bool
+		wantStatus int
+		wantEvents int
+	}{
+		{name: "single object", body: single, wantStatus: http.StatusOK, wantEvents: 1},
+		{name: "single gzip", body: single, gzip: true, wantStatus: http.StatusOK, wantEvents: 1},
+		{name: "batch", body: batch, wantStatus: http.StatusOK, wantEvents: 2},
+		{name: "batch gzip", body: batch, gzip: true, wantStatus: http.StatusOK, wantEvents: 2},
+		{name: "invalid json", body: `{nope`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &ReceiveHandler{
+				state:     &ReceiverState{ProcessedEvents: make(map[string]ProcessedEvent)},
+				statePath: filepath.Join(t.TempDir(), "state.json"),
+			}
+
+			var got []WorkerEvent
+			h.OnAny(func(ctx context.Context, event WorkerEvent) error {
+				got = append(got, event)
+				return nil
+			})
+
+			body := []byte(tt.body)
+			if tt.gzip {
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				_, _ = zw.Write(body)
+				_ = zw.Close()
+				body = buf.Bytes()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/cf/webhook", bytes.NewReader(body))
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if len(got) != tt.wantEvents {
+				t.Fatalf("dispatched %d events, want %d", len(got), tt.wantEvents)
+			}
+			if tt.wantEvents == 2 && (!got[1].RawTruncated || got[1].RawRef != "r2://raw/x.json") {
+				t.Errorf("truncation fields not parsed: %+v", got[1])
+			}
+		})
+	}
+}
+
+func TestReceiveHandlerBodyLimit(t *testing.T) {
+	old := MaxReceiveBodySize
+	MaxReceiveBodySize = 1024
+	defer func() { MaxReceiveBodySize = old }()
+
+	large := bytes.Repeat([]byte(" "), 2048)
+	var bomb bytes.Buffer
+	zw := gzip.NewWriter(&bomb)
+	_, _ = zw.Write(large)
+	_ = zw.Close()
+	if bomb.Len() >= 1024 {
+		t.Fatalf("compressed body is %d bytes, want it under the limit", bomb.Len())
+	}
+
+	for name, gz := range map[string]bool{"plain": false, "gzip": true} {
+		t.Run(name, func(t *testing.T) {
+			h := &ReceiveHandler{
+				state:     &ReceiverState{ProcessedEvents: make(map[string]ProcessedEvent)},
+				statePath: filepath.Join(t.TempDir(), "state.json"),
+			}
+			body := large
+			if gz {
+				body = bomb.Bytes()
+			}
+			req := httptest.NewRequest(http.MethodPost, "/cf/webhook", bytes.NewReader(body))
+			if gz {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != http.StatusRequestEntityTooLarge {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+			}
+		})
+	}
+}
+
+func TestReceiveHandlerDuplicate(t *testing.T) {
+	h := &ReceiveHandler{
+		state:     &ReceiverState{ProcessedEvents: make(map[string]ProcessedEvent)},
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+	body := `{"type":"pages","action":"deploy","resource":"site","timestamp":"2026-01-02T03:04:05Z"}`
+
+	for i, want := range []string{"OK", "OK (duplicate)"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body))))
+		if rec.Body.String() != want {
+			t.Errorf("request %d: body = %q, want %q", i, rec.Body.String(), want)
+		}
+	}
+}
+
+func TestDedupeKey(t *testing.T) {
+	ts := time.Date(2026, 1, 2, 3, 4, 5, 678e6, time.UTC)
+	// Existing event types keep the persisted second-resolution format
+	if got, want := dedupeKey(WorkerEvent{Type: "pages_deploy", Action: "deploy", Resource: "pages", Timestamp: ts}), "pages_deploy:deploy:pages:1767323045"; got != want {
+		t.Errorf("dedupeKey(pages) = %q, want %q", got, want)
+	}
+	if got, want := dedupeKey(WorkerEvent{Type: string(EventR2ObjectCreated), Action: "PutObject", Resource: "b/k", Timestamp: ts}), "r2_object_created:PutObject:b/k:1767323045678"; got != want {
+		t.Errorf("dedupeKey(r2) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package synccf
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-task/task/v3"
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+// TaskHook runs a Taskfile task when the receiver gets an event of a type.
+type TaskHook struct {
+	Event string // event type, or "*" for every event
+	Task  string
+}
+
+// ParseTaskHook parses "<event>=<task>", e.g. "pages_deploy=smoke-test".
+func ParseTaskHook(s string) (TaskHook, error) {
+	event, name, ok := strings.Cut(s, "=")
+	event, name = strings.TrimSpace(event), strings.TrimSpace(name)
+	if !ok || event == "" || name == "" {
+		return TaskHook{}, fmt.Errorf("invalid task hook %q (want <event>=<task>, e.g. pages_deploy=smoke-test)", s)
+	}
+	return TaskHook{Event: event, Task: name}, nil
+}
+
+// Matches reports whether the hook runs for event.
+func (h TaskHook) Matches(event WorkerEvent) bool {
+	return h.Event == "*" || h.Event == event.Type
+}
+
+// TaskRunner runs a task with vars (see TaskfileRunner).
+type TaskRunner interface {
+	RunTask(ctx context.Context, name string, vars map[string]string) error
+}
+
+// TaskfileRunner runs tasks from the Taskfile in Dir with the embedded Task
+// runner. The Taskfile is loaded for every run, so edits (and remote
+// includes refreshed by TaskCacheInvalidator) are picked up without a restart.
+type TaskfileRunner struct {
+	Dir        string
+	Entrypoint string // Taskfile path (default: Task's lookup in Dir)
+}
+
+// RunTask implements TaskRunner.
+func (r *TaskfileRunner) RunTask(ctx context.Context, name string, vars map[string]string) error {
+	e := task.NewExecutor(task.WithDir(r.Dir))
+	if r.Entrypoint != "" {
+		e.Entrypoint = r.Entrypoint
+	}
+	if err := e.Setup(); err != nil {
+		return fmt.Errorf("failed to load Taskfile: %w", err)
+	}
+
+	call := &task.Call{Task: name, Vars: ast.NewVars()}
+	for k, v := range vars {
+		call.Vars.Set(k, ast.Var{Value: v})
+	}
+	return e.Run(ctx, call)
+}
+
+// Metadata keys holding the deploy context. Pages deploy hooks forward their
+// payload as metadata; notifications nest theirs under "data".
+var (
+	deployURLKeys = []string{"deployment_url", "deploy_url", "url", "alias"}
+	commitKeys    = []string{"commit_hash", "commit", "sha"}
+	branchKeys    = []string{"branch", "deployment_trigger_branch"}
+	projectKeys   = []string{"project", "project_name"}
+)
+
+// EventTaskVars returns the task vars for an event:
+//
+//	EVENT_TYPE, EVENT_ACTION, EVENT_RESOURCE, EVENT_SOURCE, EVENT_TIME
+//	ACCOUNT_ID, ZONE_ID                      when set
+//	DEPLOY_URL, COMMIT, BRANCH, PROJECT      when found in the metadata
+//	BUCKET, OBJECT_KEY                       for R2 object events
+func EventTaskVars(event WorkerEvent) map[string]string {
+	vars := map[string]string{
+		"EVENT_TYPE":     event.Type,
+		"EVENT_ACTION":   event.Action,
+		"EVENT_RESOURCE": event.Resource,
+		"EVENT_SOURCE":   event.Source,
+		"EVENT_TIME":     event.Timestamp.UTC().Format(time.RFC3339),
+	}
+	set := func(name, value string) {
+		if value != "" {
+			vars[name] = value
+		}
+	}
+	set("ACCOUNT_ID", event.AccountID)
+	set("ZONE_ID", event.ZoneID)
+	set("DEPLOY_URL", deployMetadata(event.Metadata, deployURLKeys))
+	set("COMMIT", deployMetadata(event.Metadata, commitKeys))
+	set("BRANCH", deployMetadata(event.Metadata, branchKeys))
+	set("PROJECT", deployMetadata(event.Metadata, projectKeys))
+
+	if event.Type == string(EventR2ObjectCreated) || event.Type == string(EventR2ObjectDeleted) {
+		if r2, err := ParseR2ObjectEvent(event); err == nil {
+			set("BUCKET", r2.Bucket)
+			set("OBJECT_KEY", r2.Object.Key)
+		}
+	}
+	return vars
+}
+
+// deployMetadata returns the first of keys set to a non-empty string in
+// metadata or its "data" map.
+func deployMetadata(metadata map[string]interface{}, keys []string) string {
+	for _, m := range []map[string]interface{}{metadata, nestedMap(metadata, "data")} {
+		for _, k := range keys {
+			if s, ok := m[k].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func nestedMap(m map[string]interface{}, key string) map[string]interface{} {
+	nested, _ := m[key].(map[string]interface{})
+	return nested
+}
+
+// TaskRunnerCallback returns a receive callback that runs the task of every
+// hook matching an event, with the event as task vars (see EventTaskVars):
+//
+//	runner := &synccf.TaskfileRunner{Dir: workDir}
+//	hook, _ := synccf.ParseTaskHook("pages_deploy=smoke-test")
+//	synccf.RunReceiveServer("9091", synccf.ReceiveCallbacks{
+//	    OnAny: synccf.TaskRunnerCallback(runner, []synccf.TaskHook{hook}),
+//	})
+//
+// Tasks run in the background, one at a time in arrival order, so a slow
+// task does not hold up the Worker's forward. Failures are logged; once
+// taskQueueSize runs are pending, further runs are dropped with an error.
+func TaskRunnerCallback(runner TaskRunner, hooks []TaskHook) func(ctx context.Context, event WorkerEvent) error {
+	type taskRun struct {
+		ctx   context.Context
+		name  string
+		event string
+		vars  map[string]string
+	}
+	queue := make(chan taskRun, taskQueueSize)
+	go func() {
+		for run := range queue {
+			log.Printf("sync-cf receive: running task %s for %s event", run.name, run.event)
+			if err := runner.RunTask(run.ctx, run.name, run.vars); err != nil {
+				log.Printf("sync-cf receive: task %s failed: %v", run.name, err)
+			}
+		}
+	}()
+
+	return func(ctx context.Context, event WorkerEvent) error {
+		for _, h := range hooks {
+			if !h.Matches(event) {
+				continue
+			}
+			// The task outlives the request context, which ends with the forward
+			run := taskRun{ctx: context.WithoutCancel(ctx), name: h.Task, event: event.Type, vars: EventTaskVars(event)}
+			select {
+			case queue <- run:
+			default:
+				return fmt.Errorf("task queue full, not running %s", h.Task)
+			}
+		}
+		return nil
+	}
+}
+
+// taskQueueSize is the number of task runs TaskRunnerCallback keeps pending.
+const taskQueueSize = 32
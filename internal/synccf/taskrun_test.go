@@ -0,0 +1,118 @@
+package synccf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type recordingRunner struct {
+	runs chan string
+}
+
+func (r *recordingRunner) RunTask(ctx context.Context, name string, vars map[string]string) error {
+	r.runs <- name + " " + vars["DEPLOY_URL"] + " " + vars["COMMIT"]
+	return nil
+}
+
+func TestTaskRunnerCallback(t *testing.T) {
+	var hooks []TaskHook
+	for _, s := range []string{"pages_deploy=smoke-test", "*=log:event"} {
+		h, err := ParseTaskHook(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hooks = append(hooks, h)
+	}
+	for _, bad := range []string{"pages_deploy", "=smoke-test", "pages_deploy= "} {
+		if _, err := ParseTaskHook(bad); err == nil {
+			t.Errorf("ParseTaskHook(%q) should fail", bad)
+		}
+	}
+
+	runner := &recordingRunner{runs: make(chan string, 4)}
+	callback := TaskRunnerCallback(runner, hooks)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	event := WorkerEvent{Type: "pages_deploy", Metadata: map[string]interface{}{
+		"project": "docs",
+		"data":    map[string]interface{}{"deployment_url": "https://abc.docs.pages.dev", "commit_hash": "0a1b2c"},
+	}}
+	if err := callback(ctx, event); err != nil {
+		t.Fatal(err)
+	}
+	// Tasks keep running after the forward's request ends
+	cancel()
+	if err := callback(context.Background(), WorkerEvent{Type: "alert"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"smoke-test https://abc.docs.pages.dev 0a1b2c", "log:event https://abc.docs.pages.dev 0a1b2c", "log:event  "}
+	for _, w := range want {
+		select {
+		case got := <-runner.runs:
+			if got != w {
+				t.Errorf("run = %q, want %q", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("task %q not run", w)
+		}
+	}
+}
+
+func TestEventTaskVars(t *testing.T) {
+	vars := EventTaskVars(WorkerEvent{
+		Type:      "r2_object_created",
+		Action:    "PutObject",
+		Resource:  "assets",
+		Source:    "r2_event_notification",
+		Timestamp: time.Date(2026, 10, 16, 8, 0, 0, 0, time.FixedZone("", 3600)),
+		Metadata:  map[string]interface{}{"bucket": "assets", "key": "img/logo.png", "action": "PutObject"},
+	})
+	for k, want := range map[string]string{
+		"EVENT_TYPE": "r2_object_created",
+		"EVENT_TIME": "2026-10-16T07:00:00Z",
+		"BUCKET":     "assets",
+		"OBJECT_KEY": "img/logo.png",
+	} {
+		if vars[k] != want {
+			t.Errorf("%s = %q, want %q", k, vars[k], want)
+		}
+	}
+	if _, ok := vars["DEPLOY_URL"]; ok {
+		t.Error("DEPLOY_URL set without deploy metadata")
+	}
+}
+
+func TestTaskfileRunner(t *testing.T) {
+	dir := t.TempDir()
+	taskfile := `version: '3'
+
+tasks:
+  smoke-test:
+    cmds:
+      - echo "{{.DEPLOY_URL}}" > out.txt
+`
+	if err := os.WriteFile(filepath.Join(dir, "Taskfile.yml"), []byte(taskfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &TaskfileRunner{Dir: dir}
+	if err := runner.RunTask(context.Background(), "smoke-test", map[string]string{"DEPLOY_URL": "https://docs.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(out)) != "https://docs.example.com" {
+		t.Errorf("out.txt = %q", out)
+	}
+
+	if err := runner.RunTask(context.Background(), "missing", nil); err == nil {
+		t.Error("RunTask() of a missing task should fail")
+	}
+}
@@ -0,0 +1,308 @@
+package synccf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// TokenPreset is a least-privilege API token for one sync-cf use case:
+// the permission groups it is granted and the env vars it is saved under.
+type TokenPreset struct {
+	Name        string
+	Description string
+	Permissions []string // Permission group names, as listed by the API
+	EnvVars     []string // Where the token is saved; R2 presets get an access key pair
+}
+
+// r2 reports whether the preset is saved as R2 S3 credentials.
+func (p TokenPreset) r2() bool {
+	return len(p.EnvVars) == 2
+}
+
+// TokenPresets are the presets 'xplat sync-cf auth create-token' can mint.
+var TokenPresets = []TokenPreset{
+	{
+		Name:        "pages-events",
+		Description: "Poll Pages deployments and receive Worker events",
+		Permissions: []string{"Pages Read", "Workers Scripts Read"},
+		EnvVars:     []string{"CF_API_TOKEN"},
+	},
+	{
+		Name:        "r2-rw",
+		Description: "Read and write R2 objects (S3-compatible credentials)",
+		Permissions: []string{"Workers R2 Storage Bucket Item Read", "Workers R2 Storage Bucket Item Write"},
+		EnvVars:     []string{"R2_ACCESS_KEY", "R2_SECRET_KEY"},
+	},
+	{
+		Name:        "analytics-read",
+		Description: "Query the GraphQL Analytics API",
+		Permissions: []string{"Account Analytics Read"},
+		EnvVars:     []string{"CF_ANALYTICS_API_TOKEN"},
+	},
+}
+
+// FindTokenPreset returns the preset with the given name.
+func FindTokenPreset(name string) (TokenPreset, error) {
+	names := make([]string, 0, len(TokenPresets))
+	for _, p := range TokenPresets {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return TokenPreset{}, fmt.Errorf("unknown token preset %q (available: %s)", name, strings.Join(names, ", "))
+}
+
+// TokenClient creates and deletes API tokens using a bootstrap token,
+// which needs the "API Tokens Write" permission.
+type TokenClient struct {
+	AccountID string
+
+	apiToken   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// CreatedToken is a token minted from a preset.
+type CreatedToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Preset    string    `json:"preset"`
+	CreatedAt time.Time `json:"created_at"`
+	Value     string    `json:"-"` // Only returned once, at creation
+}
+
+// NewTokenClient creates a token client for an account.
+func NewTokenClient(accountID, bootstrapToken string) (*TokenClient, error) {
+	if bootstrapToken == "" {
+		return nil, fmt.Errorf("bootstrap token is required")
+	}
+	if accountID == "" {
+		return nil, fmt.Errorf("account ID is required")
+	}
+	return &TokenClient{
+		AccountID:  accountID,
+		apiToken:   bootstrapToken,
+		baseURL:    cfAPIBase,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// SetBaseURL points the client at another API base URL (tests).
+func (c *TokenClient) SetBaseURL(baseURL string) {
+	c.baseURL = baseURL
+}
+
+// cfResponse is the Cloudflare v4 API envelope.
+type cfResponse struct {
+	Success bool            `json:"success"`
+	Errors  []cfError       `json:"errors"`
+	Result  json.RawMessage `json:"result"`
+}
+
+type cfError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (c *TokenClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var apiResp cfResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("API error (status %d): invalid response: %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || !apiResp.Success {
+		if len(apiResp.Errors) > 0 {
+			return fmt.Errorf("API error (status %d): %s", resp.StatusCode, apiResp.Errors[0].Message)
+		}
+		return fmt.Errorf("API error (status %d)", resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.Unmarshal(apiResp.Result, out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// permissionGroupIDs resolves permission group names to IDs.
+func (c *TokenClient) permissionGroupIDs(ctx context.Context, names []string) ([]string, error) {
+	var groups []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := c.do(ctx, "GET", "/user/tokens/permission_groups", nil, &groups); err != nil {
+		return nil, fmt.Errorf("failed to list permission groups: %w", err)
+	}
+	byName := make(map[string]string, len(groups))
+	for _, g := range groups {
+		byName[g.Name] = g.ID
+	}
+
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("permission group %q not found", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Create mints a token with only the preset's permissions, scoped to the
+// client's account.
+func (c *TokenClient) Create(ctx context.Context, preset TokenPreset, name string) (*CreatedToken, error) {
+	ids, err := c.permissionGroupIDs(ctx, preset.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		groups = append(groups, map[string]string{"id": id})
+	}
+
+	body := map[string]interface{}{
+		"name": name,
+		"policies": []map[string]interface{}{{
+			"effect":            "allow",
+			"resources":         map[string]string{"com.cloudflare.api.account." + c.AccountID: "*"},
+			"permission_groups": groups,
+		}},
+	}
+	var result struct {
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if err := c.do(ctx, "POST", "/user/tokens", body, &result); err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+	return &CreatedToken{
+		ID:        result.ID,
+		Name:      result.Name,
+		Preset:    preset.Name,
+		CreatedAt: time.Now().UTC(),
+		Value:     result.Value,
+	}, nil
+}
+
+// Delete revokes a token by ID.
+func (c *TokenClient) Delete(ctx context.Context, id string) error {
+	if err := c.do(ctx, "DELETE", "/user/tokens/"+id, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete token %s: %w", id, err)
+	}
+	return nil
+}
+
+// TokenEnv returns the env vars to save a created token under. R2 presets
+// become an S3 key pair: the token ID is the access key and the SHA-256 of
+// the token value is the secret key.
+func TokenEnv(preset TokenPreset, token *CreatedToken) map[string]string {
+	if preset.r2() {
+		sum := sha256.Sum256([]byte(token.Value))
+		return map[string]string{
+			preset.EnvVars[0]: token.ID,
+			preset.EnvVars[1]: hex.EncodeToString(sum[:]),
+		}
+	}
+	vars := make(map[string]string, len(preset.EnvVars))
+	for _, key := range preset.EnvVars {
+		vars[key] = token.Value
+	}
+	return vars
+}
+
+// TokenRecordsPath returns the file recording created token IDs.
+func TokenRecordsPath() string {
+	return filepath.Join(config.XplatConfig(), "synccf-tokens.json")
+}
+
+// LoadTokenRecords reads the created tokens, keyed by preset. A missing
+// file means no tokens have been created.
+func LoadTokenRecords(path string) (map[string]CreatedToken, error) {
+	records := make(map[string]CreatedToken)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return nil, fmt.Errorf("failed to read token records: %w", err)
+	}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse token records: %w", err)
+	}
+	return records, nil
+}
+
+// SaveTokenRecord records a created token under its preset and returns the
+// token it replaces, if any, so the caller can revoke it.
+func SaveTokenRecord(path string, token *CreatedToken) (*CreatedToken, error) {
+	records, err := LoadTokenRecords(path)
+	if err != nil {
+		return nil, err
+	}
+	var previous *CreatedToken
+	if old, ok := records[token.Preset]; ok {
+		previous = &old
+	}
+	records[token.Preset] = *token
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := statestore.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write token records: %w", err)
+	}
+	return previous, nil
+}
+
+// SaveTokenEnv writes a created token's env vars to the .env file.
+func SaveTokenEnv(vars map[string]string) error {
+	return saveEnvVars(".env", vars)
+}
+
+// sortedKeys returns the keys of m in order, for stable output.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
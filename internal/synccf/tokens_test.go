@@ -0,0 +1,144 @@
+package synccf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTokenClientCreate(t *testing.T) {
+	var created map[string]interface{}
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer boot" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"success":false,"errors":[{"code":1000,"message":"Invalid API Token"}]}`))
+			return
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/user/tokens/permission_groups":
+			_, _ = w.Write([]byte(`{"success":true,"result":[
+				{"id":"g1","name":"Account Analytics Read"},
+				{"id":"g2","name":"Pages Read"}]}`))
+		case r.Method == "POST" && r.URL.Path == "/user/tokens":
+			if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+				t.Fatal(err)
+			}
+			_, _ = w.Write([]byte(`{"success":true,"result":{"id":"tok1","name":"analytics","value":"secret"}}`))
+		case r.Method == "DELETE":
+			deleted = r.URL.Path
+			_, _ = w.Write([]byte(`{"success":true,"result":{"id":"old"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := NewTokenClient("acc", "boot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetBaseURL(srv.URL)
+	preset, _ := FindTokenPreset("analytics-read")
+
+	tok, err := c.Create(context.Background(), preset, "analytics")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if tok.ID != "tok1" || tok.Value != "secret" || tok.Preset != "analytics-read" {
+		t.Errorf("token = %+v", tok)
+	}
+
+	policy := created["policies"].([]interface{})[0].(map[string]interface{})
+	if _, ok := policy["resources"].(map[string]interface{})["com.cloudflare.api.account.acc"]; !ok {
+		t.Errorf("policy not scoped to account: %v", policy["resources"])
+	}
+	groups := policy["permission_groups"].([]interface{})
+	if len(groups) != 1 || groups[0].(map[string]interface{})["id"] != "g1" {
+		t.Errorf("permission_groups = %v", groups)
+	}
+
+	// Presets needing groups the API does not list fail before creating anything
+	r2, _ := FindTokenPreset("r2-rw")
+	if _, err := c.Create(context.Background(), r2, "r2"); err == nil {
+		t.Error("expected error for unknown permission group")
+	}
+
+	if err := c.Delete(context.Background(), "old"); err != nil || deleted != "/user/tokens/old" {
+		t.Errorf("Delete() error = %v, path = %q", err, deleted)
+	}
+
+	bad, _ := NewTokenClient("acc", "wrong")
+	bad.SetBaseURL(srv.URL)
+	if _, err := bad.Create(context.Background(), preset, "x"); err == nil {
+		t.Error("expected error for invalid bootstrap token")
+	}
+}
+
+func TestTokenEnv(t *testing.T) {
+	tok := &CreatedToken{ID: "id1", Value: "value1"}
+
+	pages, _ := FindTokenPreset("pages-events")
+	if got := TokenEnv(pages, tok); got["CF_API_TOKEN"] != "value1" || len(got) != 1 {
+		t.Errorf("pages-events env = %v", got)
+	}
+
+	r2, _ := FindTokenPreset("r2-rw")
+	sum := sha256.Sum256([]byte("value1"))
+	got := TokenEnv(r2, tok)
+	if got["R2_ACCESS_KEY"] != "id1" || got["R2_SECRET_KEY"] != hex.EncodeToString(sum[:]) {
+		t.Errorf("r2-rw env = %v", got)
+	}
+
+	if _, err := FindTokenPreset("admin"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+}
+
+func TestSaveTokenRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+
+	prev, err := SaveTokenRecord(path, &CreatedToken{ID: "a", Preset: "r2-rw", Value: "secret"})
+	if err != nil || prev != nil {
+		t.Fatalf("first save: prev = %v, err = %v", prev, err)
+	}
+	prev, err = SaveTokenRecord(path, &CreatedToken{ID: "b", Preset: "r2-rw"})
+	if err != nil || prev == nil || prev.ID != "a" {
+		t.Fatalf("rotation: prev = %v, err = %v", prev, err)
+	}
+
+	records, err := LoadTokenRecords(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records["r2-rw"].ID != "b" {
+		t.Errorf("records = %v", records)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret") {
+		t.Error("token value must not be recorded")
+	}
+}
+
+func TestSaveEnvVars(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("# keep\nCF_API_TOKEN=old\nOTHER=1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err := saveEnvVars(path, map[string]string{"CF_API_TOKEN": "new", "R2_SECRET_KEY": "s", "R2_ACCESS_KEY": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	want := "# keep\nCF_API_TOKEN=new\nOTHER=1\nR2_ACCESS_KEY=a\nR2_SECRET_KEY=s\n"
+	if string(data) != want {
+		t.Errorf("env file = %q, want %q", data, want)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/platform"
 )
 
 // TunnelConfig holds configuration for cloudflared tunnel
@@ -282,12 +283,24 @@ func InstallCloudflared() error {
 		return err
 	}
 
-	url, filename := getCloudflaredDownloadURL(version)
+	// Prefer the machine's native build (arm64 even when xplat runs under
+	// Rosetta 2 or Windows x64 emulation), then one the OS emulates
+	plat := platform.Current()
+	var url, filename, arch string
+	for _, arch = range plat.Archs() {
+		if url, filename = getCloudflaredDownloadURL(version, plat.OS, arch); url != "" {
+			break
+		}
+	}
 	if url == "" {
-		return fmt.Errorf("unsupported platform: %s/%s", runtime.GOOS, runtime.GOARCH)
+		return fmt.Errorf("unsupported platform: %s", plat)
+	}
+	if arch != plat.Arch {
+		_, note := platform.Fallback(plat.OS, plat.Arch)
+		log.Printf("sync-cf: %s", note)
 	}
 
-	log.Printf("sync-cf: downloading cloudflared %s for %s/%s", version, runtime.GOOS, runtime.GOARCH)
+	log.Printf("sync-cf: downloading cloudflared %s for %s/%s", version, plat.OS, arch)
 
 	// Download to temp file
 	resp, err := http.Get(url)
@@ -337,19 +350,21 @@ func InstallCloudflared() error {
 	return nil
 }
 
-func getCloudflaredDownloadURL(version string) (url, filename string) {
+// getCloudflaredDownloadURL returns the release asset for goos/goarch, ""
+// when cloudflared has no build for it (there is no windows/arm64 one).
+func getCloudflaredDownloadURL(version, goos, goarch string) (url, filename string) {
 	base := fmt.Sprintf("https://github.com/cloudflare/cloudflared/releases/download/%s", version)
 
-	switch runtime.GOOS {
+	switch goos {
 	case "darwin":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "amd64":
 			filename = "cloudflared-darwin-amd64.tgz"
 		case "arm64":
 			filename = "cloudflared-darwin-arm64.tgz"
 		}
 	case "linux":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "amd64":
 			filename = "cloudflared-linux-amd64"
 		case "arm64":
@@ -358,7 +373,7 @@ func getCloudflaredDownloadURL(version string) (url, filename string) {
 			filename = "cloudflared-linux-arm"
 		}
 	case "windows":
-		switch runtime.GOARCH {
+		switch goarch {
 		case "amd64":
 			filename = "cloudflared-windows-amd64.exe"
 		case "386":
@@ -427,77 +442,38 @@ func fileExists(path string) bool {
 	return err == nil
 }
 
-// RunTunnel is the high-level API for running a cloudflared quick tunnel.
-// It handles cloudflared installation, signal handling, and graceful shutdown.
-// This is the main entry point for the CLI command.
-func RunTunnel(ctx context.Context, port int) error {
-	cfPath, err := getCloudflaredPath()
-	if err != nil {
-		return err
-	}
-
-	tunnel := NewTunnel(TunnelConfig{
-		LocalPort:       port,
-		CloudflaredPath: cfPath,
-	})
-
-	log.Printf("Starting cloudflared quick tunnel for localhost:%d...", port)
-
-	if err := tunnel.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start tunnel: %w", err)
-	}
-
-	log.Printf("Tunnel URL: %s", tunnel.URL())
-	log.Printf("   Webhook endpoint: %s/webhook", tunnel.URL())
-	log.Printf("   CF webhook endpoint: %s/cf/webhook", tunnel.URL())
-	log.Printf("")
-	log.Printf("Press Ctrl+C to stop the tunnel")
-
-	// Wait for context cancellation
-	<-ctx.Done()
-	tunnel.Stop()
-	log.Printf("Tunnel stopped")
+// RunTunnel is the high-level API for running a tunnel with any provider.
+// It starts the provider, logs the public URL and blocks until ctx is done.
+// onURL, if not nil, is called with the URL once the tunnel is up (e.g. to
+// record it as the Worker's sync endpoint). This is the main entry point for
+// the CLI command.
+func RunTunnel(ctx context.Context, provider Provider, port int, onURL func(url string)) error {
+	log.Printf("Starting %s tunnel for localhost:%d...", provider.Name(), port)
 
-	return nil
-}
-
-// RunNamedTunnel runs a named cloudflared tunnel with a stable hostname.
-// Named tunnels require prior setup via cloudflared tunnel login and cloudflared tunnel create.
-// The hostname is tied to your Cloudflare domain (free with CF account).
-func RunNamedTunnel(ctx context.Context, name string, port int) error {
-	cfPath, err := getCloudflaredPath()
+	url, err := provider.Start(ctx, port)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to start %s tunnel: %w", provider.Name(), err)
 	}
 
-	tunnel := NewTunnel(TunnelConfig{
-		Name:            name,
-		LocalPort:       port,
-		CloudflaredPath: cfPath,
-	})
-
-	log.Printf("Starting cloudflared named tunnel '%s' for localhost:%d...", name, port)
-
-	if err := tunnel.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start tunnel: %w", err)
-	}
-
-	// For named tunnels, URL is the configured hostname
-	url := tunnel.URL()
 	if url == "" {
-		// Named tunnels don't output URL to stderr like quick tunnels
-		// The URL is the hostname configured in the tunnel
-		log.Printf("Named tunnel '%s' is running", name)
+		// Named cloudflared tunnels don't output a URL like quick tunnels;
+		// the URL is the hostname configured for the tunnel
+		log.Printf("Tunnel is running")
 		log.Printf("   Use your configured hostname to access")
 	} else {
 		log.Printf("Tunnel URL: %s", url)
+		log.Printf("   Webhook endpoint: %s/webhook", url)
+		log.Printf("   CF webhook endpoint: %s/cf/webhook", url)
+		if onURL != nil {
+			onURL(url)
+		}
 	}
 	log.Printf("")
 	log.Printf("Press Ctrl+C to stop the tunnel")
 
 	// Wait for context cancellation
 	<-ctx.Done()
-	tunnel.Stop()
+	provider.Stop()
 	log.Printf("Tunnel stopped")
 
 	return nil
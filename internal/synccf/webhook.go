@@ -64,6 +64,7 @@ func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	eventType := EventAlert
 	resource := alert.AlertType
+	kind := ""
 
 	switch alert.AlertType {
 	case "pages_event":
@@ -72,6 +73,9 @@ func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "workers_event":
 		eventType = EventWorkersDeploy
 		resource = "workers"
+		if kind = WorkerAlertKind(alert.AlertType, alert.Name); kind != "" {
+			eventType = EventWorkersError
+		}
 	case "tunnel_health_event":
 		eventType = EventTunnel
 		resource = "tunnel"
@@ -91,6 +95,9 @@ func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		},
 		Raw: alert,
 	}
+	if kind != "" {
+		event.Metadata["alert_kind"] = kind
+	}
 
 	log.Printf("sync-cf webhook: received %s event: %s", event.Type, event.Action)
 	h.client.emit(r.Context(), event)
@@ -0,0 +1,130 @@
+package synccf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Worker error alert kinds, from Cloudflare workers_event notifications.
+const (
+	WorkerAlertErrorRate   = "error_rate"
+	WorkerAlertCPUExceeded = "cpu_exceeded"
+)
+
+// WorkerAlertKind classifies a workers_event notification by its alert_type
+// and name fields. Returns "" for deploys and other non-error events. The
+// text and data are free-form (script names, counters) and are not matched.
+// Keep in sync with workersAlertKind in workers/sync-cf/main.go.
+func WorkerAlertKind(alertType, name string) string {
+	for _, field := range []string{alertType, name} {
+		s := strings.ToLower(strings.ReplaceAll(field, "_", " "))
+		switch {
+		case strings.Contains(s, "cpu") && (strings.Contains(s, "exceed") || strings.Contains(s, "limit")):
+			return WorkerAlertCPUExceeded
+		case strings.Contains(s, "error rate"), strings.Contains(s, "errors"):
+			return WorkerAlertErrorRate
+		}
+	}
+	return ""
+}
+
+// IssueFiler files or updates the issue for a key (see syncgh.IssueClient).
+type IssueFiler interface {
+	FileOrUpdateIssue(ctx context.Context, key, title, body string, labels []string) (url string, created bool, err error)
+}
+
+// WorkerErrorIssueLabels are applied to issues filed for Worker error alerts.
+var WorkerErrorIssueLabels = []string{"cloudflare", "worker-alert"}
+
+// WorkerErrorIssueCallback returns a receive callback that files a GitHub issue
+// for each Worker error alert. Repeats of an alert for the same script and kind
+// are added as comments while the issue is open.
+func WorkerErrorIssueCallback(filer IssueFiler) func(ctx context.Context, event WorkerEvent) error {
+	return func(ctx context.Context, event WorkerEvent) error {
+		key, title, body := WorkerErrorIssue(event)
+		url, created, err := filer.FileOrUpdateIssue(ctx, key, title, body, WorkerErrorIssueLabels)
+		if err != nil {
+			return err
+		}
+		if created {
+			log.Printf("sync-cf receive: filed issue %s", url)
+		} else {
+			log.Printf("sync-cf receive: updated issue %s", url)
+		}
+		return nil
+	}
+}
+
+// WorkerErrorIssue builds the dedup key, title and markdown body of the issue
+// for a Worker error alert: the alert text and data, the Worker's /metrics
+// snapshot at alert time and the raw notification payload.
+func WorkerErrorIssue(event WorkerEvent) (key, title, body string) {
+	kind := metadataString(event.Metadata, "alert_kind")
+	if kind == "" {
+		kind = "error"
+	}
+	data, _ := event.Metadata["data"].(map[string]interface{})
+	script := workerScriptName(data)
+
+	key = fmt.Sprintf("sync-cf/worker/%s/%s", script, kind)
+	title = fmt.Sprintf("Worker alert: %s on %s", strings.ReplaceAll(kind, "_", " "), script)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s** at %s\n", event.Action, event.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+	if text := metadataString(event.Metadata, "text"); text != "" {
+		fmt.Fprintf(&b, "\n> %s\n", strings.ReplaceAll(text, "\n", "\n> "))
+	}
+
+	if len(data) > 0 {
+		b.WriteString("\n| Field | Value |\n|---|---|\n")
+		keys := make([]string, 0, len(data))
+		for k := range data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "| %s | %v |\n", k, data[k])
+		}
+	}
+
+	if metrics, ok := event.Metadata["metrics"]; ok {
+		if out, err := json.MarshalIndent(metrics, "", "  "); err == nil {
+			fmt.Fprintf(&b, "\n### Worker metrics\n\n```json\n%s\n```\n", out)
+		}
+	}
+
+	switch {
+	case len(event.Raw) > 0:
+		raw := string(event.Raw)
+		var indented interface{}
+		if err := json.Unmarshal(event.Raw, &indented); err == nil {
+			if out, err := json.MarshalIndent(indented, "", "  "); err == nil {
+				raw = string(out)
+			}
+		}
+		fmt.Fprintf(&b, "\n<details><summary>Alert payload</summary>\n\n```json\n%s\n```\n\n</details>\n", raw)
+	case event.RawTruncated:
+		fmt.Fprintf(&b, "\nAlert payload (%d bytes) not included: %s\n", event.RawSize, event.RawRef)
+	}
+
+	return key, title, strings.TrimSuffix(b.String(), "\n")
+}
+
+// workerScriptName returns the Worker script an alert is about, or "unknown".
+func workerScriptName(data map[string]interface{}) string {
+	for _, k := range []string{"script_name", "script", "worker", "service"} {
+		if s, ok := data[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return "unknown"
+}
+
+func metadataString(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
@@ -0,0 +1,105 @@
+package synccf
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkerAlertKind(t *testing.T) {
+	tests := []struct {
+		alertType string
+		name      string
+		want      string
+	}{
+		{"workers_event", "Workers error rate alert", WorkerAlertErrorRate},
+		{"workers_cpu_time_exceeded", "Worker alert", WorkerAlertCPUExceeded},
+		{"workers_event", "Worker exceeded CPU limit", WorkerAlertCPUExceeded},
+		{"workers_event", "Worker deployed", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WorkerAlertKind(tt.alertType, tt.name); got != tt.want {
+				t.Errorf("WorkerAlertKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type recordingFiler struct {
+	key, title, body string
+	labels           []string
+}
+
+func (f *recordingFiler) FileOrUpdateIssue(ctx context.Context, key, title, body string, labels []string) (string, bool, error) {
+	f.key, f.title, f.body, f.labels = key, title, body, labels
+	return "https://github.com/o/r/issues/1", true, nil
+}
+
+func TestWorkerErrorIssueCallback(t *testing.T) {
+	h := &ReceiveHandler{
+		state:     &ReceiverState{ProcessedEvents: make(map[string]ProcessedEvent)},
+		statePath: filepath.Join(t.TempDir(), "state.json"),
+	}
+	filer := &recordingFiler{}
+	h.OnWorkerError(WorkerErrorIssueCallback(filer))
+
+	body := `{
+		"type": "workers_error",
+		"timestamp": "2026-01-02T03:04:05Z",
+		"action": "Workers error rate alert",
+		"resource": "workers_event",
+		"metadata": {
+			"alert_kind": "error_rate",
+			"text": "Error rate above 5%",
+			"data": {"script_name": "api", "error_rate": 0.07},
+			"metrics": {"forward_failure": 3, "total_requests": 120}
+		},
+		"raw": {"name": "Workers error rate alert"}
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if filer.key != "sync-cf/worker/api/error_rate" {
+		t.Errorf("key = %q", filer.key)
+	}
+	if filer.title != "Worker alert: error rate on api" {
+		t.Errorf("title = %q", filer.title)
+	}
+	for _, want := range []string{
+		"**Workers error rate alert** at 2026-01-02 03:04:05 UTC",
+		"> Error rate above 5%",
+		"| error_rate | 0.07 |",
+		"### Worker metrics",
+		`"total_requests": 120`,
+		"<summary>Alert payload</summary>",
+	} {
+		if !strings.Contains(filer.body, want) {
+			t.Errorf("body missing %q:\n%s", want, filer.body)
+		}
+	}
+}
+
+func TestWorkerErrorIssueTruncatedRaw(t *testing.T) {
+	_, _, body := WorkerErrorIssue(WorkerEvent{
+		Action:       "Worker exceeded CPU limit",
+		Timestamp:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Metadata:     map[string]interface{}{"alert_kind": "cpu_exceeded"},
+		RawTruncated: true,
+		RawSize:      300000,
+		RawRef:       "r2://raw/x.json",
+	})
+	if !strings.Contains(body, "(300000 bytes) not included: r2://raw/x.json") {
+		t.Errorf("body missing raw ref:\n%s", body)
+	}
+}
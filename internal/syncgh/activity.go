@@ -0,0 +1,519 @@
+package syncgh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
+	"github.com/joeblew999/xplat/internal/notify"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// Activity kinds an ActivityWatcher can watch.
+const (
+	ActivityIssues      = "issues" // Issues and pull requests
+	ActivityDiscussions = "discussions"
+)
+
+// Activity events an ActivityWatcher reports.
+const (
+	ActivityNew       = "new"
+	ActivityLabeled   = "labeled"
+	ActivityMentioned = "mentioned"
+)
+
+// ParseActivityKinds parses a comma-separated list such as "issues,discussions".
+func ParseActivityKinds(s string) ([]string, error) {
+	return parseActivityList(s, "activity", []string{ActivityIssues, ActivityDiscussions})
+}
+
+// ParseActivityEvents parses a comma-separated list such as "new,mentioned".
+// An empty string returns every event.
+func ParseActivityEvents(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return []string{ActivityNew, ActivityLabeled, ActivityMentioned}, nil
+	}
+	return parseActivityList(s, "activity event", []string{ActivityNew, ActivityLabeled, ActivityMentioned})
+}
+
+func parseActivityList(s, what string, valid []string) ([]string, error) {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v == "" || slices.Contains(out, v) {
+			continue
+		}
+		if !slices.Contains(valid, v) {
+			return nil, fmt.Errorf("unknown %s %q (valid: %s)", what, v, strings.Join(valid, ", "))
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// ActivityConfig selects what an ActivityWatcher reports.
+type ActivityConfig struct {
+	Kinds  []string // ActivityIssues, ActivityDiscussions
+	Events []string // ActivityNew, ActivityLabeled, ActivityMentioned
+
+	// Labels limits labeled events to these labels (empty: any label)
+	Labels []string
+
+	// Mention is the GitHub login whose @mentions are reported.
+	// Mentioned events are skipped without one.
+	Mention string
+}
+
+func (c ActivityConfig) wants(event string) bool {
+	if event == ActivityMentioned && c.Mention == "" {
+		return false
+	}
+	return slices.Contains(c.Events, event)
+}
+
+func (c ActivityConfig) wantsLabel(label string) bool {
+	return len(c.Labels) == 0 || slices.ContainsFunc(c.Labels, func(l string) bool { return strings.EqualFold(l, label) })
+}
+
+// ActivityItem is one issue, pull request or discussion event.
+type ActivityItem struct {
+	Repo   string    `json:"repo"`
+	Kind   string    `json:"kind"` // issue, pull_request or discussion
+	Number int       `json:"number"`
+	Title  string    `json:"title"`
+	URL    string    `json:"url"`
+	Author string    `json:"author,omitempty"` // Who opened, labeled or mentioned
+	Event  string    `json:"event"`
+	Label  string    `json:"label,omitempty"` // For labeled events
+	Time   time.Time `json:"time"`
+}
+
+// Message returns the item as a notification. The source is
+// syncgh/issues or syncgh/discussions; mentions are warnings so a route
+// with min_severity: warning gets only those.
+func (i ActivityItem) Message() *notify.Message {
+	kind := strings.ReplaceAll(i.Kind, "_", " ")
+	title := fmt.Sprintf("%s#%d %s", i.Repo, i.Number, i.Title)
+	var text string
+	switch i.Event {
+	case ActivityNew:
+		text = fmt.Sprintf("New %s by %s", kind, i.Author)
+	case ActivityLabeled:
+		text = fmt.Sprintf("%s labeled %q by %s", capitalize(kind), i.Label, i.Author)
+	case ActivityMentioned:
+		text = fmt.Sprintf("Mentioned in %s", kind)
+	}
+
+	source := "syncgh/" + ActivityIssues
+	if i.Kind == "discussion" {
+		source = "syncgh/" + ActivityDiscussions
+	}
+	severity := notify.SeverityInfo
+	if i.Event == ActivityMentioned {
+		severity = notify.SeverityWarning
+	}
+	return &notify.Message{
+		Source:   source,
+		Severity: severity,
+		Title:    title,
+		Text:     text,
+		Fields:   map[string]string{"url": i.URL},
+		Time:     i.Time,
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// ActivityState is the per-repo cursor of an ActivityWatcher.
+type ActivityState struct {
+	Repos map[string]RepoActivityState `json:"repos"`
+}
+
+// RepoActivityState is what has been reported for one repo.
+type RepoActivityState struct {
+	// Since is the time of the last check; newer activity is reported
+	Since time.Time `json:"since"`
+
+	// DiscussionLabels are the labels last seen per discussion number.
+	// Discussions have no label events, so new labels are found by diff.
+	DiscussionLabels map[int][]string `json:"discussion_labels,omitempty"`
+}
+
+// activityStateFile is the StateStore key for activity state
+const activityStateFile = "syncgh-activity-state.json"
+
+// activityStateVersion is the ActivityState schema version (see statestore)
+const activityStateVersion = 1
+
+// activityPages is the most pages of issue events read per repo and check.
+const activityPages = 3
+
+// ActivityWatcher polls repos for new, labeled and mentioning issues,
+// pull requests and discussions.
+//
+// The first check of a repo only records the time, so starting a watcher
+// does not replay a repo's history. Discussions need a token (the GraphQL
+// API has no anonymous access).
+type ActivityWatcher struct {
+	repos  []string
+	config ActivityConfig
+	store  StateStore
+	client *github.Client
+
+	mu    sync.Mutex
+	state *ActivityState
+}
+
+// NewActivityWatcher creates a watcher for "owner/repo" names, keeping its
+// cursor in store next to the poll state.
+func NewActivityWatcher(repos []string, cfg ActivityConfig, auth ghauth.TokenSource, store StateStore) (*ActivityWatcher, error) {
+	if slices.Contains(cfg.Kinds, ActivityDiscussions) {
+		token, err := tokenOf(auth)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("watching discussions needs a GitHub token (GITHUB_TOKEN)")
+		}
+	}
+
+	state := &ActivityState{}
+	data, err := store.Read(activityStateFile)
+	switch {
+	case err == nil:
+		if err := statestore.Unmarshal(data, state, activityStateVersion, nil); err != nil {
+			return nil, fmt.Errorf("failed to parse activity state from %s: %w", store, err)
+		}
+	case !errors.Is(err, ErrStateNotFound):
+		return nil, err
+	}
+	if state.Repos == nil {
+		state.Repos = make(map[string]RepoActivityState)
+	}
+
+	return &ActivityWatcher{repos: repos, config: cfg, store: store, client: newGitHubClient(auth), state: state}, nil
+}
+
+// SetBaseURL points the watcher at another API endpoint (GitHub Enterprise or tests).
+func (w *ActivityWatcher) SetBaseURL(baseURL string) error {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	w.client.BaseURL = u
+	return nil
+}
+
+// Check polls every repo once and returns the activity since the previous
+// check, oldest first. A repo that fails keeps its cursor and is retried on
+// the next check; the errors are joined.
+func (w *ActivityWatcher) Check(ctx context.Context) ([]ActivityItem, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var items []ActivityItem
+	var errs []error
+	for _, repo := range w.repos {
+		owner, name := parseRepo(repo)
+		if owner == "" || name == "" {
+			errs = append(errs, fmt.Errorf("invalid repo format: %s (expected owner/repo)", repo))
+			continue
+		}
+
+		now := time.Now().UTC()
+		rs := w.state.Repos[repo]
+		if rs.Since.IsZero() {
+			// First check: only discussions' labels are recorded
+			rs.Since = now
+			if slices.Contains(w.config.Kinds, ActivityDiscussions) {
+				if _, labels, err := w.checkDiscussions(ctx, owner, name, rs); err == nil {
+					rs.DiscussionLabels = labels
+				}
+			}
+			w.state.Repos[repo] = rs
+			continue
+		}
+
+		var found []ActivityItem
+		var err error
+		if slices.Contains(w.config.Kinds, ActivityIssues) {
+			found, err = w.checkIssues(ctx, owner, name, rs.Since)
+		}
+		if err == nil && slices.Contains(w.config.Kinds, ActivityDiscussions) {
+			var discussions []ActivityItem
+			var labels map[int][]string
+			discussions, labels, err = w.checkDiscussions(ctx, owner, name, rs)
+			found = append(found, discussions...)
+			rs.DiscussionLabels = labels
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", repo, err))
+			continue
+		}
+
+		rs.Since = now
+		w.state.Repos[repo] = rs
+		items = append(items, found...)
+	}
+
+	slices.SortStableFunc(items, func(a, b ActivityItem) int { return a.Time.Compare(b.Time) })
+
+	data, err := statestore.Marshal(w.state, activityStateVersion)
+	if err == nil {
+		err = w.store.Write(activityStateFile, data)
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to save activity state to %s: %w", w.store, err))
+	}
+	return items, errors.Join(errs...)
+}
+
+// checkIssues returns issues and pull requests opened since, plus labeled
+// and mentioned events from the repo's issue event feed.
+func (w *ActivityWatcher) checkIssues(ctx context.Context, owner, repo string, since time.Time) ([]ActivityItem, error) {
+	full := owner + "/" + repo
+	var items []ActivityItem
+
+	if w.config.wants(ActivityNew) {
+		issues, _, err := w.client.Issues.ListByRepo(ctx, owner, repo, &github.IssueListByRepoOptions{
+			State:       "all",
+			Since:       since,
+			Sort:        "created",
+			Direction:   "desc",
+			ListOptions: github.ListOptions{PerPage: 100},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, issue := range issues {
+			if !issue.GetCreatedAt().After(since) {
+				continue
+			}
+			items = append(items, issueItem(full, issue, ActivityNew, issue.GetUser().GetLogin(), issue.GetCreatedAt().Time))
+		}
+	}
+
+	if w.config.wants(ActivityLabeled) || w.config.wants(ActivityMentioned) {
+		opts := &github.ListOptions{PerPage: 100}
+	pages:
+		for page := 0; page < activityPages; page++ {
+			events, resp, err := w.client.Issues.ListRepositoryEvents(ctx, owner, repo, opts)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list issue events: %w", err)
+			}
+			// Newest first: stop at the first event already reported
+			for _, e := range events {
+				at := e.GetCreatedAt().Time
+				if !at.After(since) {
+					break pages
+				}
+				switch e.GetEvent() {
+				case "labeled":
+					if w.config.wants(ActivityLabeled) && w.config.wantsLabel(e.GetLabel().GetName()) {
+						item := issueItem(full, e.GetIssue(), ActivityLabeled, e.GetActor().GetLogin(), at)
+						item.Label = e.GetLabel().GetName()
+						items = append(items, item)
+					}
+				case "mentioned":
+					if w.config.wants(ActivityMentioned) && strings.EqualFold(e.GetActor().GetLogin(), w.config.Mention) {
+						items = append(items, issueItem(full, e.GetIssue(), ActivityMentioned, "", at))
+					}
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+	}
+	return items, nil
+}
+
+func issueItem(repo string, issue *github.Issue, event, author string, at time.Time) ActivityItem {
+	kind := "issue"
+	if issue.IsPullRequest() {
+		kind = "pull_request"
+	}
+	return ActivityItem{
+		Repo:   repo,
+		Kind:   kind,
+		Number: issue.GetNumber(),
+		Title:  issue.GetTitle(),
+		URL:    issue.GetHTMLURL(),
+		Author: author,
+		Event:  event,
+		Time:   at,
+	}
+}
+
+const discussionsQuery = `query($owner: String!, $repo: String!) {
+  repository(owner: $owner, name: $repo) {
+    discussions(first: 50, orderBy: {field: UPDATED_AT, direction: DESC}) {
+      nodes {
+        number title url body createdAt updatedAt
+        author { login }
+        labels(first: 20) { nodes { name } }
+      }
+    }
+  }
+}`
+
+// checkDiscussions returns discussions opened since rs.Since (and those
+// mentioning the configured login), plus labels added since the labels in
+// rs were recorded. It also returns the labels to record now.
+func (w *ActivityWatcher) checkDiscussions(ctx context.Context, owner, repo string, rs RepoActivityState) ([]ActivityItem, map[int][]string, error) {
+	req, err := w.client.NewRequest("POST", "graphql", map[string]any{
+		"query":     discussionsQuery,
+		"variables": map[string]string{"owner": owner, "repo": repo},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var resp struct {
+		Data struct {
+			Repository struct {
+				Discussions struct {
+					Nodes []struct {
+						Number    int       `json:"number"`
+						Title     string    `json:"title"`
+						URL       string    `json:"url"`
+						Body      string    `json:"body"`
+						CreatedAt time.Time `json:"createdAt"`
+						UpdatedAt time.Time `json:"updatedAt"`
+						Author    struct {
+							Login string `json:"login"`
+						} `json:"author"`
+						Labels struct {
+							Nodes []struct {
+								Name string `json:"name"`
+							} `json:"nodes"`
+						} `json:"labels"`
+					} `json:"nodes"`
+				} `json:"discussions"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := w.client.Do(ctx, req, &resp); err != nil {
+		return nil, nil, fmt.Errorf("failed to list discussions: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, nil, fmt.Errorf("failed to list discussions: %s", resp.Errors[0].Message)
+	}
+
+	full := owner + "/" + repo
+	labels := make(map[int][]string)
+	var items []ActivityItem
+	for _, d := range resp.Data.Repository.Discussions.Nodes {
+		item := ActivityItem{Repo: full, Kind: "discussion", Number: d.Number, Title: d.Title, URL: d.URL}
+		var names []string
+		for _, l := range d.Labels.Nodes {
+			names = append(names, l.Name)
+		}
+		labels[d.Number] = names
+
+		isNew := d.CreatedAt.After(rs.Since)
+		if isNew && w.config.wants(ActivityNew) {
+			item.Event, item.Author, item.Time = ActivityNew, d.Author.Login, d.CreatedAt
+			items = append(items, item)
+		}
+		if isNew && w.config.wants(ActivityMentioned) && mentions(d.Body, w.config.Mention) {
+			item.Event, item.Author, item.Time = ActivityMentioned, "", d.CreatedAt
+			items = append(items, item)
+		}
+		// Labels on a new discussion all count as added; on an older one
+		// only those missing at the last check. An older discussion not
+		// seen before fell outside the last page, so its labels are skipped.
+		seen, known := rs.DiscussionLabels[d.Number]
+		if w.config.wants(ActivityLabeled) && (isNew || known) {
+			for _, name := range names {
+				if isNew || !slices.Contains(seen, name) {
+					if w.config.wantsLabel(name) {
+						item.Event, item.Author, item.Label, item.Time = ActivityLabeled, "", name, d.UpdatedAt
+						items = append(items, item)
+					}
+				}
+			}
+		}
+	}
+	return items, labels, nil
+}
+
+// mentions reports whether text @-mentions login.
+func mentions(text, login string) bool {
+	if login == "" {
+		return false
+	}
+	needle := "@" + strings.ToLower(login)
+	lower := strings.ToLower(text)
+	for i := strings.Index(lower, needle); i >= 0; {
+		end := i + len(needle)
+		if end == len(lower) || !isLoginChar(lower[end]) {
+			return true
+		}
+		next := strings.Index(lower[end:], needle)
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+	return false
+}
+
+func isLoginChar(c byte) bool {
+	return c == '-' || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+}
+
+// Start checks every interval until ctx is done, passing each item to
+// onItem. Errors are logged and retried on the next tick.
+func (w *ActivityWatcher) Start(ctx context.Context, interval time.Duration, onItem func(ActivityItem)) {
+	log.Printf("sync-gh: Watching %s on %d repos (interval: %v)", strings.Join(w.config.Kinds, ", "), len(w.repos), interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		items, err := w.Check(ctx)
+		if err != nil {
+			log.Printf("sync-gh: Activity check failed: %v", err)
+		}
+		for _, item := range items {
+			onItem(item)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ActivityNotifier returns an onItem callback that logs each item and
+// sends it through the notification router (see internal/notify).
+func ActivityNotifier(ctx context.Context, router *notify.Router) func(ActivityItem) {
+	return func(item ActivityItem) {
+		msg := item.Message()
+		log.Printf("sync-gh: %s: %s", msg.Title, msg.Text)
+		if err := router.Notify(ctx, msg); err != nil {
+			log.Printf("sync-gh: Failed to notify %s: %v", msg.Title, err)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package syncgh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
+	"github.com/joeblew999/xplat/internal/notify"
+)
+
+func TestActivityWatcher(t *testing.T) {
+	var now time.Time
+	ts := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/o/r/issues", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("since") == "" {
+			t.Error("issues listed without since")
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"number": 7, "title": "Crash on start", "html_url": "https://github.com/o/r/issues/7", "created_at": ts(time.Minute), "user": map[string]string{"login": "alice"}},
+			{"number": 8, "title": "Fix", "html_url": "https://github.com/o/r/pull/8", "created_at": ts(2 * time.Minute), "user": map[string]string{"login": "bob"}, "pull_request": map[string]string{"url": "x"}},
+			// Updated since, but opened before the cursor
+			{"number": 3, "title": "Old", "created_at": ts(-time.Hour), "user": map[string]string{"login": "carol"}},
+		})
+	})
+	mux.HandleFunc("GET /repos/o/r/issues/events", func(w http.ResponseWriter, r *http.Request) {
+		issue := map[string]any{"number": 3, "title": "Old", "html_url": "https://github.com/o/r/issues/3"}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"event": "mentioned", "created_at": ts(4 * time.Minute), "actor": map[string]string{"login": "Maintainer"}, "issue": issue},
+			{"event": "labeled", "created_at": ts(3 * time.Minute), "actor": map[string]string{"login": "bob"}, "label": map[string]string{"name": "security"}, "issue": issue},
+			{"event": "labeled", "created_at": ts(3 * time.Minute), "actor": map[string]string{"login": "bob"}, "label": map[string]string{"name": "chore"}, "issue": issue},
+			{"event": "mentioned", "created_at": ts(3 * time.Minute), "actor": map[string]string{"login": "someone-else"}, "issue": issue},
+			// Before the cursor: stops the feed
+			{"event": "labeled", "created_at": ts(-time.Minute), "actor": map[string]string{"login": "bob"}, "label": map[string]string{"name": "security"}, "issue": issue},
+		})
+	})
+	discussionLabels := []string{"idea"}
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		labels := []map[string]string{}
+		for _, l := range discussionLabels {
+			labels = append(labels, map[string]string{"name": l})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"repository": map[string]any{"discussions": map[string]any{"nodes": []map[string]any{
+			{"number": 1, "title": "Roadmap", "url": "https://github.com/o/r/discussions/1", "body": "", "createdAt": ts(-time.Hour), "updatedAt": ts(5 * time.Minute), "author": map[string]string{"login": "alice"}, "labels": map[string]any{"nodes": labels}},
+			{"number": 2, "title": "Help", "url": "https://github.com/o/r/discussions/2", "body": "cc @maintainer, @maintainers", "createdAt": ts(6 * time.Minute), "updatedAt": ts(6 * time.Minute), "author": map[string]string{"login": "dave"}, "labels": map[string]any{"nodes": []any{}}},
+		}}}}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	store := &FileStateStore{Dir: t.TempDir()}
+	cfg := ActivityConfig{
+		Kinds:   []string{ActivityIssues, ActivityDiscussions},
+		Events:  []string{ActivityNew, ActivityLabeled, ActivityMentioned},
+		Labels:  []string{"security", "Security-Review", "idea", "roadmap"},
+		Mention: "maintainer",
+	}
+	w, err := NewActivityWatcher([]string{"o/r"}, cfg, ghauth.StaticToken("token"), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.SetBaseURL(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first check only sets the cursor and records discussion labels
+	if items, err := w.Check(context.Background()); err != nil || len(items) != 0 {
+		t.Fatalf("first Check() = %+v, %v", items, err)
+	}
+
+	// Reload from the store, as a restarted poller would
+	w, err = NewActivityWatcher([]string{"o/r"}, cfg, ghauth.StaticToken("token"), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.SetBaseURL(srv.URL)
+	now = w.state.Repos["o/r"].Since
+	discussionLabels = []string{"idea", "roadmap"}
+
+	items, err := w.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	type key struct {
+		kind   string
+		number int
+		event  string
+		label  string
+	}
+	var got []key
+	for _, i := range items {
+		got = append(got, key{i.Kind, i.Number, i.Event, i.Label})
+	}
+	want := []key{
+		{"issue", 7, ActivityNew, ""},
+		{"pull_request", 8, ActivityNew, ""},
+		{"issue", 3, ActivityLabeled, "security"},
+		{"issue", 3, ActivityMentioned, ""},
+		{"discussion", 1, ActivityLabeled, "roadmap"},
+		{"discussion", 2, ActivityNew, ""},
+		{"discussion", 2, ActivityMentioned, ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("items = %+v\nwant %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("items[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	msg := items[3].Message()
+	if msg.Source != "syncgh/issues" || msg.Severity != notify.SeverityWarning || msg.Title != "o/r#3 Old" {
+		t.Errorf("Message() = %+v", msg)
+	}
+	if msg := items[5].Message(); msg.Source != "syncgh/discussions" || msg.Text != "New discussion by dave" {
+		t.Errorf("Message() = %+v", msg)
+	}
+}
+
+func TestParseActivity(t *testing.T) {
+	if kinds, err := ParseActivityKinds("Issues, discussions,issues"); err != nil || len(kinds) != 2 {
+		t.Errorf("ParseActivityKinds() = %v, %v", kinds, err)
+	}
+	if _, err := ParseActivityKinds("issues,wiki"); err == nil {
+		t.Error("ParseActivityKinds() should reject wiki")
+	}
+	if events, err := ParseActivityEvents(""); err != nil || len(events) != 3 {
+		t.Errorf("ParseActivityEvents(\"\") = %v, %v", events, err)
+	}
+	if _, err := NewActivityWatcher([]string{"o/r"}, ActivityConfig{Kinds: []string{ActivityDiscussions}}, nil, &FileStateStore{Dir: t.TempDir()}); err == nil {
+		t.Error("discussions without a token should fail")
+	}
+}
+
+func TestMentions(t *testing.T) {
+	for text, want := range map[string]bool{
+		"cc @Maintainer":               true,
+		"@maintainer.":                 true,
+		"@maintainers only":            false,
+		"@maintainer-bot":              false,
+		"@maintainers and @maintainer": true,
+		"email maintainer@x.com":       false,
+	} {
+		if got := mentions(text, "maintainer"); got != want {
+			t.Errorf("mentions(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
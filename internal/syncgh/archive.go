@@ -0,0 +1,123 @@
+package syncgh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ArchiveNamespace is the key prefix of archived webhook payloads.
+const ArchiveNamespace = "webhooks/"
+
+// ErrPayloadNotArchived is returned by PayloadArchive.Get for unknown delivery IDs.
+var ErrPayloadNotArchived = errors.New("payload not archived")
+
+// ArchivedPayload is a webhook delivery as stored in the archive.
+type ArchivedPayload struct {
+	DeliveryID string            `json:"delivery_id"`
+	Event      string            `json:"event"`
+	ReceivedAt time.Time         `json:"received_at"`
+	Headers    map[string]string `json:"headers,omitempty"`
+
+	// Body is the payload: JSON bodies as-is, anything else (form-encoded
+	// deliveries) as a JSON string.
+	Body json.RawMessage `json:"body"`
+}
+
+// NewArchivedPayload builds the archive record of a received webhook.
+func NewArchivedPayload(deliveryID, event string, headers map[string]string, body []byte, receivedAt time.Time) *ArchivedPayload {
+	p := &ArchivedPayload{
+		DeliveryID: deliveryID,
+		Event:      event,
+		ReceivedAt: receivedAt.UTC(),
+		Headers:    headers,
+		Body:       json.RawMessage(body),
+	}
+	if !json.Valid(body) {
+		p.Body, _ = json.Marshal(string(body))
+	}
+	return p
+}
+
+// Payload returns the original request body.
+func (p *ArchivedPayload) Payload() []byte {
+	var s string
+	if err := json.Unmarshal(p.Body, &s); err == nil {
+		return []byte(s)
+	}
+	return p.Body
+}
+
+// PayloadArchive stores webhook payloads in a StateStore under
+// ArchiveNamespace, keyed by delivery ID: local files by default, or an R2
+// bucket (via rclone) or NATS KV bucket shared with other machines. A
+// redelivery with the same ID replaces the record.
+type PayloadArchive struct {
+	Store StateStore
+}
+
+// NewPayloadArchive creates an archive from a state store spec (see
+// ParseStateStore): "file" keeps payloads in ~/.xplat/cache/webhooks.
+func NewPayloadArchive(spec string) (*PayloadArchive, error) {
+	store, err := ParseStateStore(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &PayloadArchive{Store: store}, nil
+}
+
+// Key returns the storage key of a delivery.
+func (a *PayloadArchive) Key(deliveryID string) (string, error) {
+	if deliveryID == "" {
+		return "", fmt.Errorf("payload has no delivery ID")
+	}
+	if strings.ContainsAny(deliveryID, "/\\") || deliveryID == "." || deliveryID == ".." {
+		return "", fmt.Errorf("invalid delivery ID %q", deliveryID)
+	}
+	return ArchiveNamespace + deliveryID + ".json", nil
+}
+
+// Put stores a payload.
+func (a *PayloadArchive) Put(ctx context.Context, p *ArchivedPayload) error {
+	key, err := a.Key(p.DeliveryID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	if err := a.Store.Write(key, data); err != nil {
+		return fmt.Errorf("failed to archive payload: %w", err)
+	}
+	return nil
+}
+
+// Get fetches an archived payload by delivery ID.
+func (a *PayloadArchive) Get(ctx context.Context, deliveryID string) (*ArchivedPayload, error) {
+	key, err := a.Key(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := a.Store.Read(key)
+	if errors.Is(err, ErrStateNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrPayloadNotArchived, deliveryID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch payload: %w", err)
+	}
+
+	var p ArchivedPayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to decode archived payload: %w", err)
+	}
+	return &p, nil
+}
+
+// String describes the archive for logs.
+func (a *PayloadArchive) String() string {
+	return a.Store.String() + "/" + ArchiveNamespace
+}
@@ -0,0 +1,121 @@
+package syncgh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPayloadArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive := &PayloadArchive{Store: &FileStateStore{Dir: dir}}
+	ctx := context.Background()
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		id   string
+		body string
+	}{
+		{"json", "abc-123", `{"ref":"refs/heads/main"}`},
+		{"form", "def-456", "payload=%7B%22ref%22%7D"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewArchivedPayload(tt.id, "push", map[string]string{"X-GitHub-Event": "push"}, []byte(tt.body), at)
+			if err := archive.Put(ctx, p); err != nil {
+				t.Fatalf("Put failed: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "webhooks", tt.id+".json")); err != nil {
+				t.Errorf("not stored at webhooks/%s.json: %v", tt.id, err)
+			}
+
+			got, err := archive.Get(ctx, tt.id)
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			if string(got.Payload()) != tt.body {
+				t.Errorf("Payload() = %q, want %q", got.Payload(), tt.body)
+			}
+			if got.Event != "push" || !got.ReceivedAt.Equal(at) {
+				t.Errorf("got event %q at %v", got.Event, got.ReceivedAt)
+			}
+		})
+	}
+
+	if _, err := archive.Get(ctx, "missing"); !errors.Is(err, ErrPayloadNotArchived) {
+		t.Errorf("Get(missing) error = %v, want ErrPayloadNotArchived", err)
+	}
+	for _, id := range []string{"", "../secrets", ".."} {
+		if err := archive.Put(ctx, NewArchivedPayload(id, "push", nil, []byte("{}"), at)); err == nil {
+			t.Errorf("Put with delivery ID %q succeeded, want error", id)
+		}
+	}
+}
+
+func TestNewPayloadArchive(t *testing.T) {
+	dir := t.TempDir()
+	archive, err := NewPayloadArchive("file:" + dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := archive.String(), "file:"+dir+"/webhooks/"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if _, err := NewPayloadArchive("ftp:somewhere"); err == nil {
+		t.Error("NewPayloadArchive with unknown backend succeeded, want error")
+	}
+}
+
+func TestWebhookServerArchive(t *testing.T) {
+	archive := &PayloadArchive{Store: &FileStateStore{Dir: t.TempDir()}}
+	server := NewWebhookServerWithConfig(WebhookConfig{Archive: archive})
+
+	body := `{"zen":"Keep it logically awesome.","hook_id":1}`
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-GitHub-Delivery", "ping-1")
+	rec := httptest.NewRecorder()
+	server.HandleWebhook(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+
+	// Archiving runs in the background.
+	var p *ArchivedPayload
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if p, err = archive.Get(context.Background(), "ping-1"); !errors.Is(err, ErrPayloadNotArchived) {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if string(p.Payload()) != body || p.Event != "ping" || p.Headers["X-Github-Delivery"] != "ping-1" {
+		t.Errorf("archived = %+v", p)
+	}
+}
+
+func TestWebhookServerBodyTooLarge(t *testing.T) {
+	archive := &PayloadArchive{Store: &FileStateStore{Dir: t.TempDir()}}
+	server := NewWebhookServerWithConfig(WebhookConfig{Archive: archive})
+
+	body := `{"pad":"` + strings.Repeat("x", MaxBodySize) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Event", "ping")
+	req.Header.Set("X-GitHub-Delivery", "big-1")
+	rec := httptest.NewRecorder()
+	server.HandleWebhook(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", rec.Code)
+	}
+}
@@ -38,9 +38,9 @@ func InvalidateTaskCache(workDir string, aggressive bool) error {
 // Use this with StatefulPoller:
 //
 //	poller.OnChange(syncgh.TaskCacheInvalidator(workDir))
-func TaskCacheInvalidator(workDir string) func(repo, ref, oldHash, newHash string) {
-	return func(repo, ref, oldHash, newHash string) {
-		log.Printf("syncgh: Detected change in %s@%s (%s -> %s)", repo, ref, oldHash, newHash)
+func TaskCacheInvalidator(workDir string) func(Change) {
+	return func(c Change) {
+		log.Printf("syncgh: Detected change in %s", c.Summary())
 
 		if err := InvalidateTaskCache(workDir, true); err != nil {
 			log.Printf("syncgh: Failed to invalidate Task cache: %v", err)
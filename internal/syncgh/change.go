@@ -0,0 +1,105 @@
+package syncgh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Change is an upstream change passed to StatefulPoller.OnChange callbacks.
+// The summary comes from one compare API call per change, shared with the
+// event filter, so callbacks can build notifications or decide what to
+// invalidate without calling the API again. It is empty on the first poll
+// of a repo (nothing to compare against) and when the call fails.
+type Change struct {
+	Repo    string
+	Ref     string
+	OldHash string // Empty on the first poll of a repo
+	NewHash string
+
+	CompareURL string   // GitHub compare page for OldHash...NewHash
+	Commits    int      // Commits between the hashes, 0 when unknown
+	Authors    []string // Distinct commit authors (login, else name), oldest first
+	Actor      string   // Author of the newest commit
+	Files      []string // Changed paths; nil when unknown or over the API's file limit
+}
+
+// Summary describes the change in one line, e.g.
+// "o/r@main abc1234 -> def5678: 3 commits by alice, bob; 2 files changed".
+func (c Change) Summary() string {
+	s := fmt.Sprintf("%s@%s %s -> %s", c.Repo, c.Ref, c.OldHash, c.NewHash)
+	if c.OldHash == "" {
+		s = fmt.Sprintf("%s@%s now at %s", c.Repo, c.Ref, c.NewHash)
+	}
+	var parts []string
+	if c.Commits > 0 {
+		commits := fmt.Sprintf("%d commit%s", c.Commits, plural(c.Commits))
+		if len(c.Authors) > 0 {
+			commits += " by " + strings.Join(c.Authors, ", ")
+		}
+		parts = append(parts, commits)
+	}
+	if c.Files != nil {
+		parts = append(parts, fmt.Sprintf("%d file%s changed", len(c.Files), plural(len(c.Files))))
+	}
+	if len(parts) == 0 {
+		return s
+	}
+	return s + ": " + strings.Join(parts, "; ")
+}
+
+// event returns the change as seen by the event filter.
+func (c Change) event(tag bool) ChangeEvent {
+	return ChangeEvent{Repo: c.Repo, Ref: c.Ref, Tag: tag, Actor: c.Actor, Files: c.Files}
+}
+
+// compareURL returns the GitHub compare page between two commits of a repo.
+func compareURL(repo, oldHash, newHash string) string {
+	return fmt.Sprintf("https://github.com/%s/compare/%s...%s", repo, oldHash, newHash)
+}
+
+// summarize fills in the summary of a polled change from the compare API.
+// On failure the change keeps only its compare URL.
+func (p *Poller) summarize(ctx context.Context, c *Change) error {
+	c.CompareURL = compareURL(c.Repo, c.OldHash, c.NewHash)
+
+	owner, repo := parseRepo(c.Repo)
+	cmp, _, err := p.client.Repositories.CompareCommits(ctx, owner, repo, c.OldHash, c.NewHash, nil)
+	if err != nil {
+		return fmt.Errorf("failed to compare %s...%s: %w", c.OldHash, c.NewHash, err)
+	}
+
+	if url := cmp.GetHTMLURL(); url != "" {
+		c.CompareURL = url
+	}
+	c.Commits = cmp.GetTotalCommits()
+	if c.Commits == 0 {
+		c.Commits = len(cmp.Commits)
+	}
+	seen := make(map[string]bool)
+	for _, commit := range cmp.Commits {
+		author := commit.GetAuthor().GetLogin()
+		if author == "" {
+			author = commit.GetCommit().GetAuthor().GetName()
+		}
+		if author != "" && !seen[author] {
+			seen[author] = true
+			c.Authors = append(c.Authors, author)
+		}
+		c.Actor = author
+	}
+	if len(cmp.Files) < compareFilesLimit {
+		c.Files = make([]string, 0, len(cmp.Files))
+		for _, f := range cmp.Files {
+			c.Files = append(c.Files, f.GetFilename())
+		}
+	}
+	return nil
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
@@ -0,0 +1,116 @@
+package syncgh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+)
+
+func TestChangeSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		change Change
+		want   string
+	}{
+		{"first poll", Change{Repo: "o/r", Ref: "main", NewHash: "bbbbbbbb"}, "o/r@main now at bbbbbbbb"},
+		{"unsummarized", Change{Repo: "o/r", Ref: "main", OldHash: "aaaaaaaa", NewHash: "bbbbbbbb"}, "o/r@main aaaaaaaa -> bbbbbbbb"},
+		{"summarized", Change{
+			Repo: "o/r", Ref: "main", OldHash: "aaaaaaaa", NewHash: "bbbbbbbb",
+			Commits: 3, Authors: []string{"alice", "bob"}, Files: []string{"Taskfile.yml"},
+		}, "o/r@main aaaaaaaa -> bbbbbbbb: 3 commits by alice, bob; 1 file changed"},
+		{"too many files", Change{
+			Repo: "o/r", Ref: "v1.0.0", OldHash: "aaaaaaaa", NewHash: "bbbbbbbb", Commits: 1,
+		}, "o/r@v1.0.0 aaaaaaaa -> bbbbbbbb: 1 commit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.change.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatefulPollerChange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/repos/o/r/commits":
+			_, _ = w.Write([]byte(`[{"sha": "bbbbbbbbbbbb"}]`))
+		case "/repos/o/r/compare/aaaaaaaa...bbbbbbbb":
+			_, _ = w.Write([]byte(`{
+				"html_url": "https://github.com/o/r/compare/aaaaaaaa...bbbbbbbb",
+				"total_commits": 3,
+				"commits": [
+					{"author": {"login": "alice"}},
+					{"commit": {"author": {"name": "Bob"}}},
+					{"author": {"login": "alice"}}
+				],
+				"files": [{"filename": "Taskfile.yml"}, {"filename": "taskfiles/go.yml"}]
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store := &FileStateStore{Dir: t.TempDir()}
+	state := &PollState{Repos: map[string]RepoCommitState{}}
+	state.SetRepoHash("o/r", "main", "aaaaaaaa")
+	if err := SavePollStateTo(store, state); err != nil {
+		t.Fatal(err)
+	}
+
+	sp, err := NewStatefulPollerWithStore(time.Minute, []RepoConfig{{Subsystem: "o/r", Branch: "main"}}, nil, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp.client.BaseURL.Host = strings.TrimPrefix(server.URL, "http://")
+	sp.client.BaseURL.Scheme = "http"
+
+	var got []Change
+	sp.OnChange(func(c Change) { got = append(got, c) })
+	sp.checkAll()
+
+	want := []Change{{
+		Repo: "o/r", Ref: "main", OldHash: "aaaaaaaa", NewHash: "bbbbbbbb",
+		CompareURL: "https://github.com/o/r/compare/aaaaaaaa...bbbbbbbb",
+		Commits:    3,
+		Authors:    []string{"alice", "Bob"},
+		Actor:      "alice",
+		Files:      []string{"Taskfile.yml", "taskfiles/go.yml"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OnChange got %+v, want %+v", got, want)
+	}
+}
+
+func TestPushChange(t *testing.T) {
+	event := &github.PushEvent{
+		Ref:     github.Ptr("refs/heads/main"),
+		Compare: github.Ptr("https://github.com/o/r/compare/aaaaaaaa...bbbbbbbb"),
+		Repo:    &github.PushEventRepository{FullName: github.Ptr("o/r")},
+		Sender:  &github.User{Login: github.Ptr("alice")},
+		Commits: []*github.HeadCommit{
+			{Author: &github.CommitAuthor{Login: github.Ptr("alice")}, Modified: []string{"Taskfile.yml"}},
+			{Author: &github.CommitAuthor{Name: github.Ptr("Bob")}, Added: []string{"a.yml"}},
+		},
+	}
+	got := pushChange(event, "aaaaaaaa", "bbbbbbbb")
+	want := Change{
+		Repo: "o/r", Ref: "main", OldHash: "aaaaaaaa", NewHash: "bbbbbbbb",
+		CompareURL: "https://github.com/o/r/compare/aaaaaaaa...bbbbbbbb",
+		Commits:    2,
+		Authors:    []string{"alice", "Bob"},
+		Actor:      "alice",
+		Files:      []string{"Taskfile.yml", "a.yml"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pushChange = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,391 @@
+// Package syncgh provides GitHub sync operations.
+//
+// This file implements auto-deployment of the gosmee-compatible SSE server
+// to managed platforms (Fly.io, Google Cloud Run), so production webhook
+// relay does not depend on smee.io availability.
+package syncgh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/config"
+)
+
+// DeployTarget identifies a hosting platform for the SSE server.
+type DeployTarget string
+
+const (
+	// DeployTargetFly deploys to Fly.io using flyctl.
+	DeployTargetFly DeployTarget = "fly"
+
+	// DeployTargetCloudRun deploys to Google Cloud Run using gcloud.
+	DeployTargetCloudRun DeployTarget = "cloudrun"
+)
+
+// DeployTargets lists all supported deploy targets.
+var DeployTargets = []DeployTarget{DeployTargetFly, DeployTargetCloudRun}
+
+// deployTargetArch is the CPU architecture each target runs containers on.
+// Fly machines and Cloud Run only run amd64 images today.
+var deployTargetArch = map[DeployTarget]string{
+	DeployTargetFly:      "amd64",
+	DeployTargetCloudRun: "amd64",
+}
+
+// Arch returns the architecture of the xplat release binary baked into the
+// image for the target.
+func (t DeployTarget) Arch() string {
+	if arch, ok := deployTargetArch[t]; ok {
+		return arch
+	}
+	return "amd64"
+}
+
+// DeployConfig holds configuration for deploying the SSE server.
+type DeployConfig struct {
+	// Target is the hosting platform (fly or cloudrun)
+	Target DeployTarget
+
+	// App is the app (Fly) or service (Cloud Run) name
+	App string
+
+	// Region is the deploy region (e.g., "iad" for Fly, "us-central1" for Cloud Run)
+	Region string
+
+	// Project is the Google Cloud project ID (Cloud Run only, defaults to gcloud config)
+	Project string
+
+	// Version is the xplat release to run: "v1.2.3" or the full tag
+	// "xplat-v1.2.3" (default "latest", the repo's latest release)
+	Version string
+
+	// WebhookSecrets are set as platform secrets for signature validation (optional)
+	WebhookSecrets []string
+
+	// BuildDir is where the build context is written (default: temp dir)
+	BuildDir string
+
+	// DryRun writes the build context and prints commands without running them
+	DryRun bool
+}
+
+// DeployResult contains the outcome of a deployment.
+type DeployResult struct {
+	Target    DeployTarget
+	App       string
+	PublicURL string
+	BuildDir  string
+}
+
+// ssePort is the port the SSE server listens on inside the container.
+const ssePort = "3333"
+
+// Defaults for each target.
+const (
+	defaultDeployApp      = "xplat-sse"
+	defaultFlyRegion      = "iad"
+	defaultCloudRunRegion = "us-central1"
+)
+
+// ParseDeployTarget validates a target name.
+func ParseDeployTarget(s string) (DeployTarget, error) {
+	for _, t := range DeployTargets {
+		if string(t) == strings.ToLower(strings.TrimSpace(s)) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported deploy target: %s (supported: fly, cloudrun)", s)
+}
+
+// Deployer packages and deploys the SSE server to a hosting platform.
+type Deployer struct {
+	config DeployConfig
+}
+
+// NewDeployer creates a new deployer, filling in target-specific defaults.
+func NewDeployer(cfg DeployConfig) *Deployer {
+	if cfg.App == "" {
+		cfg.App = defaultDeployApp
+	}
+	if cfg.Version == "" {
+		cfg.Version = "latest"
+	}
+	if cfg.Version != "latest" && !strings.HasPrefix(cfg.Version, config.XplatTagPrefix) {
+		cfg.Version = config.XplatTagPrefix + cfg.Version
+	}
+	if cfg.Region == "" {
+		switch cfg.Target {
+		case DeployTargetFly:
+			cfg.Region = defaultFlyRegion
+		case DeployTargetCloudRun:
+			cfg.Region = defaultCloudRunRegion
+		}
+	}
+	return &Deployer{config: cfg}
+}
+
+// Deploy writes the build context, provisions the target, sets secrets,
+// deploys, and returns the public URL of the SSE server.
+func (d *Deployer) Deploy(ctx context.Context) (*DeployResult, error) {
+	if _, err := ParseDeployTarget(string(d.config.Target)); err != nil {
+		return nil, err
+	}
+
+	buildDir, err := d.writeBuildContext()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeployResult{
+		Target:   d.config.Target,
+		App:      d.config.App,
+		BuildDir: buildDir,
+	}
+
+	log.Printf("Deploy: Target: %s", d.config.Target)
+	log.Printf("Deploy: App: %s (region %s)", d.config.App, d.config.Region)
+	log.Printf("Deploy: Build context: %s", buildDir)
+
+	switch d.config.Target {
+	case DeployTargetFly:
+		result.PublicURL, err = d.deployFly(ctx, buildDir)
+	case DeployTargetCloudRun:
+		result.PublicURL, err = d.deployCloudRun(ctx, buildDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// writeBuildContext writes the Dockerfile (and fly.toml) to the build directory.
+func (d *Deployer) writeBuildContext() (string, error) {
+	dir := d.config.BuildDir
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "xplat-sse-deploy-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create build dir: %w", err)
+		}
+		dir = tmp
+	} else if err := os.MkdirAll(dir, config.DefaultDirPerms); err != nil {
+		return "", fmt.Errorf("failed to create build dir: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte(d.dockerfile()), config.DefaultFilePerms); err != nil {
+		return "", fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+
+	if d.config.Target == DeployTargetFly {
+		if err := os.WriteFile(filepath.Join(dir, "fly.toml"), []byte(d.flyConfig()), config.DefaultFilePerms); err != nil {
+			return "", fmt.Errorf("failed to write fly.toml: %w", err)
+		}
+	}
+
+	return dir, nil
+}
+
+// dockerfile returns a minimal image that downloads the xplat release binary
+// and runs the SSE server. PORT, PUBLIC_URL and WEBHOOK_SECRETS come from the platform.
+// "latest" follows GitHub's latest release, the same one 'xplat update' installs.
+func (d *Deployer) dockerfile() string {
+	asset := "xplat-linux-" + d.config.Target.Arch()
+	downloadURL := fmt.Sprintf("https://github.com/%s/releases/latest/download/%s", config.XplatRepo, asset)
+	if d.config.Version != "latest" {
+		downloadURL = fmt.Sprintf("https://github.com/%s/releases/download/%s/%s", config.XplatRepo, d.config.Version, asset)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by xplat sync-gh server deploy\n")
+	sb.WriteString("FROM alpine:3.20\n\n")
+	sb.WriteString("RUN apk add --no-cache ca-certificates curl\n")
+	sb.WriteString(fmt.Sprintf("RUN curl -fsSL %s -o /usr/local/bin/xplat && chmod +x /usr/local/bin/xplat\n\n", downloadURL))
+	sb.WriteString(fmt.Sprintf("ENV PORT=%s\n", ssePort))
+	sb.WriteString(fmt.Sprintf("EXPOSE %s\n\n", ssePort))
+	sb.WriteString(`CMD ["sh", "-c", "exec xplat sync-gh server --port=\"$PORT\" --public-url=\"$PUBLIC_URL\" --secrets=\"$WEBHOOK_SECRETS\""]` + "\n")
+	return sb.String()
+}
+
+// flyConfig returns the fly.toml for the SSE server.
+// SSE connections are long-lived, so auto-stop is disabled.
+func (d *Deployer) flyConfig() string {
+	var sb strings.Builder
+	sb.WriteString("# Generated by xplat sync-gh server deploy\n")
+	sb.WriteString(fmt.Sprintf("app = %q\n", d.config.App))
+	sb.WriteString(fmt.Sprintf("primary_region = %q\n\n", d.config.Region))
+	sb.WriteString("[env]\n")
+	sb.WriteString(fmt.Sprintf("  PORT = %q\n", ssePort))
+	sb.WriteString(fmt.Sprintf("  PUBLIC_URL = %q\n\n", flyPublicURL(d.config.App)))
+	sb.WriteString("[http_service]\n")
+	sb.WriteString(fmt.Sprintf("  internal_port = %s\n", ssePort))
+	sb.WriteString("  force_https = true\n")
+	sb.WriteString("  auto_stop_machines = \"off\"\n")
+	sb.WriteString("  min_machines_running = 1\n\n")
+	sb.WriteString("[[http_service.checks]]\n")
+	sb.WriteString("  method = \"GET\"\n")
+	sb.WriteString("  path = \"/health\"\n")
+	sb.WriteString("  interval = \"30s\"\n")
+	sb.WriteString("  timeout = \"5s\"\n")
+	return sb.String()
+}
+
+// flyPublicURL returns the default public URL for a Fly app.
+func flyPublicURL(app string) string {
+	return fmt.Sprintf("https://%s.fly.dev", app)
+}
+
+// deployFly provisions the Fly app (idempotent), stages secrets and deploys.
+func (d *Deployer) deployFly(ctx context.Context, buildDir string) (string, error) {
+	flyctl, err := d.findCLI("flyctl", "fly")
+	if err != nil {
+		return "", err
+	}
+
+	// Create app - ignore "already exists" so deploy is idempotent
+	if _, err := d.run(ctx, buildDir, flyctl, "apps", "create", d.config.App); err != nil {
+		if !strings.Contains(err.Error(), "already") {
+			return "", fmt.Errorf("failed to create Fly app: %w", err)
+		}
+		log.Printf("Deploy: Fly app %s already exists", d.config.App)
+	}
+
+	if len(d.config.WebhookSecrets) > 0 {
+		secret := "WEBHOOK_SECRETS=" + strings.Join(d.config.WebhookSecrets, ",")
+		if _, err := d.run(ctx, buildDir, flyctl, "secrets", "set", secret, "--app", d.config.App, "--stage"); err != nil {
+			return "", fmt.Errorf("failed to set Fly secrets: %w", err)
+		}
+	}
+
+	if _, err := d.run(ctx, buildDir, flyctl, "deploy", "--config", "fly.toml", "--remote-only", "--ha=false"); err != nil {
+		return "", fmt.Errorf("failed to deploy to Fly: %w", err)
+	}
+
+	return flyPublicURL(d.config.App), nil
+}
+
+// deployCloudRun deploys from source (Cloud Build) and reads back the service URL.
+func (d *Deployer) deployCloudRun(ctx context.Context, buildDir string) (string, error) {
+	gcloud, err := d.findCLI("gcloud")
+	if err != nil {
+		return "", err
+	}
+
+	common := []string{"--region", d.config.Region}
+	if d.config.Project != "" {
+		common = append(common, "--project", d.config.Project)
+	}
+
+	args := []string{"run", "deploy", d.config.App,
+		"--source", ".",
+		"--port", ssePort,
+		"--allow-unauthenticated",
+		"--min-instances", "1",
+		"--timeout", "3600",
+		"--quiet",
+	}
+	args = append(args, common...)
+	if len(d.config.WebhookSecrets) > 0 {
+		// gcloud uses ^@^ to switch the list delimiter so commas survive
+		args = append(args, "--set-env-vars", "^@^WEBHOOK_SECRETS="+strings.Join(d.config.WebhookSecrets, ","))
+	}
+
+	if _, err := d.run(ctx, buildDir, gcloud, args...); err != nil {
+		return "", fmt.Errorf("failed to deploy to Cloud Run: %w", err)
+	}
+
+	if d.config.DryRun {
+		return fmt.Sprintf("https://%s-<hash>-%s.a.run.app", d.config.App, d.config.Region), nil
+	}
+
+	describe := append([]string{"run", "services", "describe", d.config.App, "--format", "value(status.url)"}, common...)
+	out, err := d.run(ctx, buildDir, gcloud, describe...)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Cloud Run URL: %w", err)
+	}
+	publicURL := strings.TrimSpace(out)
+	if publicURL == "" {
+		return "", fmt.Errorf("Cloud Run returned empty service URL")
+	}
+
+	// The URL is only known after the first deploy - feed it back to the server
+	update := append([]string{"run", "services", "update", d.config.App, "--update-env-vars", "PUBLIC_URL=" + publicURL, "--quiet"}, common...)
+	if _, err := d.run(ctx, buildDir, gcloud, update...); err != nil {
+		log.Printf("Deploy: Warning: failed to set PUBLIC_URL: %v", err)
+	}
+
+	return publicURL, nil
+}
+
+// run executes a CLI command in dir, streaming output and returning stdout.
+// In dry-run mode the command is only printed.
+func (d *Deployer) run(ctx context.Context, dir, name string, args ...string) (string, error) {
+	log.Printf("Deploy: $ %s %s", filepath.Base(name), redactSecrets(strings.Join(args, " ")))
+	if d.config.DryRun {
+		return "", nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return stdout.String(), fmt.Errorf("%s: %w: %s", filepath.Base(name), err, msg)
+	}
+	return stdout.String(), nil
+}
+
+// redactSecrets hides secret values in logged command lines.
+func redactSecrets(s string) string {
+	if idx := strings.Index(s, "WEBHOOK_SECRETS="); idx >= 0 {
+		end := strings.IndexByte(s[idx:], ' ')
+		if end == -1 {
+			return s[:idx] + "WEBHOOK_SECRETS=****"
+		}
+		return s[:idx] + "WEBHOOK_SECRETS=****" + s[idx+end:]
+	}
+	return s
+}
+
+// findCLI returns the path to the first CLI found in PATH.
+// In dry-run mode a missing CLI is not an error.
+func (d *Deployer) findCLI(names ...string) (string, error) {
+	for _, name := range names {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	if d.config.DryRun {
+		return names[0], nil
+	}
+	return "", fmt.Errorf("%s not found in PATH (install it and log in first)", names[0])
+}
+
+// RunDeploy deploys the SSE server and prints the public URL.
+func RunDeploy(cfg DeployConfig) error {
+	result, err := NewDeployer(cfg).Deploy(context.Background())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("")
+	log.Printf("SSE server deployed to %s", result.Target)
+	log.Printf("  Public URL:   %s", result.PublicURL)
+	log.Printf("  New channel:  %s/new", result.PublicURL)
+	log.Printf("  Webhook URL:  %s/<channel>", result.PublicURL)
+	log.Printf("  Client:       xplat sync-gh sse-client %s/<channel> --invalidate", result.PublicURL)
+
+	fmt.Println(result.PublicURL)
+	return nil
+}
@@ -0,0 +1,138 @@
+package syncgh
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDeployTarget(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected DeployTarget
+		wantErr  bool
+	}{
+		{"fly", DeployTargetFly, false},
+		{" Fly ", DeployTargetFly, false},
+		{"cloudrun", DeployTargetCloudRun, false},
+		{"CLOUDRUN", DeployTargetCloudRun, false},
+		{"heroku", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDeployTarget(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDeployTarget(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseDeployTarget(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDeployerDockerfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DeployConfig
+		contains []string
+	}{
+		{
+			name:   "fly latest",
+			config: DeployConfig{Target: DeployTargetFly},
+			contains: []string{
+				"/releases/latest/download/xplat-linux-" + DeployTargetFly.Arch() + " ",
+				"ENV PORT=" + ssePort,
+				"EXPOSE " + ssePort,
+				"xplat sync-gh server",
+			},
+		},
+		{
+			name:   "cloudrun pinned version",
+			config: DeployConfig{Target: DeployTargetCloudRun, Version: "v1.2.3"},
+			contains: []string{
+				"/releases/download/xplat-v1.2.3/xplat-linux-" + DeployTargetCloudRun.Arch() + " ",
+			},
+		},
+		{
+			name:   "full tag",
+			config: DeployConfig{Target: DeployTargetFly, Version: "xplat-v1.2.3"},
+			contains: []string{
+				"/releases/download/xplat-v1.2.3/xplat-linux-",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDeployer(tt.config).dockerfile()
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("dockerfile missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDeployerFlyConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DeployConfig
+		contains []string
+	}{
+		{
+			name:   "defaults",
+			config: DeployConfig{Target: DeployTargetFly},
+			contains: []string{
+				`app = "` + defaultDeployApp + `"`,
+				`primary_region = "` + defaultFlyRegion + `"`,
+				`PUBLIC_URL = "https://` + defaultDeployApp + `.fly.dev"`,
+				"internal_port = " + ssePort,
+				`auto_stop_machines = "off"`,
+			},
+		},
+		{
+			name:   "custom app and region",
+			config: DeployConfig{Target: DeployTargetFly, App: "my-sse", Region: "syd"},
+			contains: []string{
+				`app = "my-sse"`,
+				`primary_region = "syd"`,
+				`PUBLIC_URL = "https://my-sse.fly.dev"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewDeployer(tt.config).flyConfig()
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("fly.toml missing %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no secret", "apps create xplat-sse", "apps create xplat-sse"},
+		{"trailing", "secrets set WEBHOOK_SECRETS=a,b", "secrets set WEBHOOK_SECRETS=****"},
+		{"middle", "secrets set WEBHOOK_SECRETS=a,b --app x --stage", "secrets set WEBHOOK_SECRETS=**** --app x --stage"},
+		{"gcloud delimiter", "--set-env-vars ^@^WEBHOOK_SECRETS=s1 --quiet", "--set-env-vars ^@^WEBHOOK_SECRETS=**** --quiet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactSecrets(tt.input); got != tt.expected {
+				t.Errorf("redactSecrets(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -1,14 +1,86 @@
 package syncgh
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/config"
 )
 
+// DiscoverSource identifies where a GitHub repo reference was found.
+type DiscoverSource string
+
+const (
+	// SourceTaskfile is a remote include in a Taskfile.
+	SourceTaskfile DiscoverSource = "taskfile"
+
+	// SourceGoMod is a require line in go.mod (same owner as the module).
+	SourceGoMod DiscoverSource = "gomod"
+
+	// SourceManifest is a binary source or dependency in xplat.yaml.
+	SourceManifest DiscoverSource = "xplat"
+
+	// SourceProcessCompose is a command reference in a process-compose file.
+	SourceProcessCompose DiscoverSource = "process-compose"
+)
+
+// AllDiscoverSources lists every supported discovery source.
+var AllDiscoverSources = []DiscoverSource{SourceTaskfile, SourceGoMod, SourceManifest, SourceProcessCompose}
+
+// DiscoveredRepo is a GitHub repo found during discovery, tagged with
+// every source it was found in.
+type DiscoveredRepo struct {
+	Repo    string           `json:"repo"`
+	Sources []DiscoverSource `json:"sources"`
+}
+
+// HasSource returns true if the repo was found in the given source.
+func (r DiscoveredRepo) HasSource(source DiscoverSource) bool {
+	for _, s := range r.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDiscoverSources parses a comma-separated source list (e.g., "taskfile,gomod").
+// Empty input or "all" returns all sources.
+func ParseDiscoverSources(s string) ([]DiscoverSource, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "all" {
+		return AllDiscoverSources, nil
+	}
+
+	var sources []DiscoverSource
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		found := false
+		for _, known := range AllDiscoverSources {
+			if string(known) == part {
+				sources = append(sources, known)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown discover source: %s (supported: taskfile, gomod, xplat, process-compose)", part)
+		}
+	}
+	return sources, nil
+}
+
 // DiscoverReposFromTaskfile scans a Taskfile.yml for remote includes and
 // extracts the GitHub repos to watch.
 //
@@ -139,6 +211,270 @@ func extractRepoFromURL(url string) string {
 	return ""
 }
 
+// DiscoverProjectRepos scans a project directory for GitHub repos using the
+// given sources and returns them tagged with where they were found.
+// Results are sorted by repo name.
+func DiscoverProjectRepos(projectDir string, sources []DiscoverSource) ([]DiscoveredRepo, error) {
+	found := make(map[string]*DiscoveredRepo)
+	var order []string
+	add := func(source DiscoverSource, repos []string) {
+		for _, repo := range repos {
+			entry, ok := found[repo]
+			if !ok {
+				entry = &DiscoveredRepo{Repo: repo}
+				found[repo] = entry
+				order = append(order, repo)
+			}
+			if !entry.HasSource(source) {
+				entry.Sources = append(entry.Sources, source)
+			}
+		}
+	}
+
+	owner := projectOwner(projectDir)
+
+	for _, source := range sources {
+		var repos []string
+		var err error
+
+		switch source {
+		case SourceTaskfile:
+			repos, err = DiscoverReposFromProject(projectDir)
+		case SourceGoMod:
+			repos, err = DiscoverReposFromGoMod(filepath.Join(projectDir, "go.mod"))
+		case SourceManifest:
+			repos, err = DiscoverReposFromManifest(filepath.Join(projectDir, "xplat.yaml"), owner)
+		case SourceProcessCompose:
+			repos, err = DiscoverReposFromProcessCompose(projectDir)
+		default:
+			return nil, fmt.Errorf("unknown discover source: %s", source)
+		}
+
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+		add(source, repos)
+	}
+
+	result := make([]DiscoveredRepo, 0, len(order))
+	for _, repo := range order {
+		result = append(result, *found[repo])
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Repo < result[j].Repo })
+
+	return result, nil
+}
+
+// DiscoveredRepoNames returns just the "owner/repo" names.
+func DiscoveredRepoNames(repos []DiscoveredRepo) []string {
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.Repo
+	}
+	return names
+}
+
+// DiscoverReposFromGoMod extracts GitHub repos from go.mod require lines.
+// Only repos with the same owner as the module itself are returned, and
+// indirect requirements are skipped - the goal is to watch sibling repos,
+// not the whole dependency tree.
+func DiscoverReposFromGoMod(goModPath string) ([]string, error) {
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, err
+	}
+
+	module, requires := parseGoMod(data)
+	owner, _ := parseRepo(repoFromModulePath(module))
+
+	seen := make(map[string]bool)
+	var repos []string
+	for _, req := range requires {
+		repo := repoFromModulePath(req)
+		if repo == "" || seen[repo] {
+			continue
+		}
+		reqOwner, _ := parseRepo(repo)
+		if owner != "" && !strings.EqualFold(reqOwner, owner) {
+			continue
+		}
+		seen[repo] = true
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
+
+// parseGoMod returns the module path and direct require paths from go.mod.
+func parseGoMod(data []byte) (string, []string) {
+	var module string
+	var requires []string
+	inRequire := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.Contains(line, "// indirect")
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "module "):
+			module = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`)
+		case line == "require (":
+			inRequire = true
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire, strings.HasPrefix(line, "require "):
+			fields := strings.Fields(strings.TrimPrefix(line, "require "))
+			if len(fields) >= 2 && !indirect {
+				requires = append(requires, strings.Trim(fields[0], `"`))
+			}
+		}
+	}
+
+	return module, requires
+}
+
+// repoFromModulePath converts "github.com/owner/repo/sub/v2" to "owner/repo".
+// Returns empty string for non-GitHub module paths.
+func repoFromModulePath(path string) string {
+	parts := strings.Split(path, "/")
+	if len(parts) < 3 || parts[0] != "github.com" {
+		return ""
+	}
+	return parts[1] + "/" + strings.TrimSuffix(parts[2], ".git")
+}
+
+// DiscoverReposFromManifest extracts GitHub repos from an xplat.yaml manifest:
+// binary sources (go, github, repo) and runtime/build dependencies.
+// Dependencies given as bare package names (e.g., "plat-caddy") are resolved
+// against owner; "owner/repo" dependencies are used as-is.
+func DiscoverReposFromManifest(manifestPath, owner string) ([]string, error) {
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest struct {
+		Binary struct {
+			Source struct {
+				Go     string `yaml:"go"`
+				Repo   string `yaml:"repo"`
+				GitHub struct {
+					Repo string `yaml:"repo"`
+				} `yaml:"github"`
+			} `yaml:"source"`
+		} `yaml:"binary"`
+		Dependencies struct {
+			Runtime []string `yaml:"runtime"`
+			Build   []string `yaml:"build"`
+		} `yaml:"dependencies"`
+	}
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var repos []string
+	add := func(repo string) {
+		if repo != "" && !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+
+	src := manifest.Binary.Source
+	add(repoFromModulePath(src.Go))
+	add(extractRepoFromURL(src.Repo))
+	if o, r := parseRepo(src.GitHub.Repo); o != "" && r != "" {
+		add(src.GitHub.Repo)
+	}
+
+	// Infer owner from the binary source when not given
+	if owner == "" && len(repos) > 0 {
+		owner, _ = parseRepo(repos[0])
+	}
+
+	deps := append(append([]string{}, manifest.Dependencies.Runtime...), manifest.Dependencies.Build...)
+	for _, dep := range deps {
+		dep = strings.TrimSpace(dep)
+		switch {
+		case dep == "":
+		case strings.Contains(dep, "/"):
+			if o, r := parseRepo(dep); o != "" && r != "" {
+				add(dep)
+			}
+		case owner != "":
+			add(owner + "/" + dep)
+		}
+	}
+
+	return repos, nil
+}
+
+// commandGitHubPattern finds github.com/owner/repo references in shell commands
+// (e.g., "go run github.com/owner/repo/cmd/tool@latest").
+var commandGitHubPattern = regexp.MustCompile(`(?:raw\.githubusercontent\.com|github\.com)/([A-Za-z0-9_.-]+)/([A-Za-z0-9_-]+(?:\.[A-Za-z0-9_-]+)*)`)
+
+// DiscoverReposFromProcessCompose extracts GitHub repos referenced in the
+// commands of process-compose files in the project directory.
+func DiscoverReposFromProcessCompose(projectDir string) ([]string, error) {
+	seen := make(map[string]bool)
+	var repos []string
+
+	for _, name := range config.ProcessComposeSearchOrder() {
+		data, err := os.ReadFile(filepath.Join(projectDir, name))
+		if err != nil {
+			continue
+		}
+
+		var pc struct {
+			Processes map[string]struct {
+				Command string `yaml:"command"`
+			} `yaml:"processes"`
+		}
+		if err := yaml.Unmarshal(data, &pc); err != nil {
+			continue // Skip files that can't be parsed
+		}
+
+		for _, proc := range pc.Processes {
+			for _, repo := range extractReposFromCommand(proc.Command) {
+				if !seen[repo] {
+					seen[repo] = true
+					repos = append(repos, repo)
+				}
+			}
+		}
+	}
+
+	return repos, nil
+}
+
+// extractReposFromCommand returns all "owner/repo" references in a command string.
+func extractReposFromCommand(command string) []string {
+	var repos []string
+	for _, m := range commandGitHubPattern.FindAllStringSubmatch(command, -1) {
+		repos = append(repos, m[1]+"/"+strings.TrimSuffix(m[2], ".git"))
+	}
+	return repos
+}
+
+// projectOwner returns the GitHub owner of the project from its go.mod module path.
+func projectOwner(projectDir string) string {
+	data, err := os.ReadFile(filepath.Join(projectDir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	module, _ := parseGoMod(data)
+	owner, _ := parseRepo(repoFromModulePath(module))
+	return owner
+}
+
 // DiscoverReposToConfigs converts discovered repos to RepoConfig slice.
 // All repos are configured to watch the "main" branch by default.
 func DiscoverReposToConfigs(repos []string) []RepoConfig {
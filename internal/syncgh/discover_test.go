@@ -1,6 +1,8 @@
 package syncgh
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -125,3 +127,138 @@ func TestDiscoverReposToConfigs(t *testing.T) {
 		}
 	}
 }
+
+func TestDiscoverReposFromGoMod(t *testing.T) {
+	tmpDir := t.TempDir()
+	goMod := `module github.com/joeblew999/xplat
+
+go 1.25
+
+require github.com/joeblew999/plat-caddy v0.1.0
+
+require (
+	github.com/joeblew999/plat-garage/cmd v0.2.0
+	github.com/joeblew999/plat-rush/v2 v2.0.0
+	github.com/spf13/cobra v1.10.2
+	golang.org/x/mod v0.20.0
+	github.com/joeblew999/plat-indirect v0.1.0 // indirect
+)
+`
+	path := filepath.Join(tmpDir, "go.mod")
+	if err := os.WriteFile(path, []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repos, err := DiscoverReposFromGoMod(path)
+	if err != nil {
+		t.Fatalf("DiscoverReposFromGoMod failed: %v", err)
+	}
+
+	expected := []string{"joeblew999/plat-caddy", "joeblew999/plat-garage", "joeblew999/plat-rush"}
+	if len(repos) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, repos)
+	}
+	for i, repo := range expected {
+		if repos[i] != repo {
+			t.Errorf("repos[%d] = %q, want %q", i, repos[i], repo)
+		}
+	}
+}
+
+func TestExtractReposFromCommand(t *testing.T) {
+	tests := []struct {
+		command  string
+		expected []string
+	}{
+		{"go run github.com/joeblew999/plat-rush/cmd/rush@latest serve", []string{"joeblew999/plat-rush"}},
+		{"xplat os fetch https://github.com/owner/repo.git//bin", []string{"owner/repo"}},
+		{"task run", nil},
+	}
+
+	for _, tt := range tests {
+		repos := extractReposFromCommand(tt.command)
+		if len(repos) != len(tt.expected) {
+			t.Errorf("extractReposFromCommand(%q) = %v, want %v", tt.command, repos, tt.expected)
+			continue
+		}
+		for i := range repos {
+			if repos[i] != tt.expected[i] {
+				t.Errorf("extractReposFromCommand(%q)[%d] = %q, want %q", tt.command, i, repos[i], tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestDiscoverProjectReposTagsSources(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module github.com/joeblew999/app\n\nrequire github.com/joeblew999/plat-caddy v0.1.0\n",
+		"Taskfile.yml": `version: '3'
+includes:
+  caddy: https://github.com/joeblew999/plat-caddy.git//Taskfile.yml
+`,
+		"xplat.yaml": `name: app
+dependencies:
+  runtime:
+    - plat-nats
+`,
+		"process-compose.yaml": `processes:
+  rush:
+    command: go run github.com/joeblew999/plat-rush@latest
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos, err := DiscoverProjectRepos(tmpDir, AllDiscoverSources)
+	if err != nil {
+		t.Fatalf("DiscoverProjectRepos failed: %v", err)
+	}
+
+	byRepo := make(map[string]DiscoveredRepo)
+	for _, r := range repos {
+		byRepo[r.Repo] = r
+	}
+
+	caddy := byRepo["joeblew999/plat-caddy"]
+	if !caddy.HasSource(SourceTaskfile) || !caddy.HasSource(SourceGoMod) {
+		t.Errorf("plat-caddy sources = %v, want taskfile and gomod", caddy.Sources)
+	}
+	if !byRepo["joeblew999/plat-nats"].HasSource(SourceManifest) {
+		t.Errorf("plat-nats should be discovered from xplat.yaml, got %v", repos)
+	}
+	if !byRepo["joeblew999/plat-rush"].HasSource(SourceProcessCompose) {
+		t.Errorf("plat-rush should be discovered from process-compose, got %v", repos)
+	}
+
+	// Filtering by source
+	only, err := DiscoverProjectRepos(tmpDir, []DiscoverSource{SourceGoMod})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(only) != 1 || only[0].Repo != "joeblew999/plat-caddy" {
+		t.Errorf("gomod-only discovery = %v, want [joeblew999/plat-caddy]", only)
+	}
+}
+
+func TestParseDiscoverSources(t *testing.T) {
+	sources, err := ParseDiscoverSources("taskfile, gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 || sources[0] != SourceTaskfile || sources[1] != SourceGoMod {
+		t.Errorf("got %v", sources)
+	}
+
+	all, _ := ParseDiscoverSources("")
+	if len(all) != len(AllDiscoverSources) {
+		t.Errorf("empty input should return all sources, got %v", all)
+	}
+
+	if _, err := ParseDiscoverSources("npm"); err == nil {
+		t.Error("expected error for unknown source")
+	}
+}
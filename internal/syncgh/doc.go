@@ -5,13 +5,22 @@
 //   - Poller: Poll GitHub repos periodically for changes (commit hashes, tags)
 //   - StatefulPoller: Poller with state persistence - only triggers on actual changes
 //   - PollState: Tracks commit hashes between polls (~/.xplat/cache/syncgh-poll-state.json)
+//   - PollHealthHandler: /healthz, /metrics (Prometheus) and /status for a running poller
+//   - ActivityWatcher: Route new, labeled and mentioning issues, PRs and discussions to notify
+//   - StateStore: Pluggable PollState backend (local file, R2 via rclone, NATS KV)
 //   - DiscoverReposFromProject: Auto-discover GitHub repos from Taskfile.yml remote includes
+//   - DiscoverProjectRepos: Discover repos from Taskfile, go.mod, xplat.yaml and process-compose
 //   - TaskCacheInvalidator: Callback to invalidate Task remote taskfile cache on change
+//   - EventFilter: Limit which changes invalidate by branch, changed path and actor (sync-gh-filter.yaml)
 //   - Webhook: HTTP server to receive GitHub webhook events
 //   - SSEServer: gosmee-compatible SSE server for webhook relay
 //   - SSEClient: SSE client for receiving webhooks from gosmee/SSE server
+//   - Deployer: Deploy the SSE server to Fly.io or Google Cloud Run
 //   - Replayer: Fetch and replay past webhook deliveries from GitHub API
-//   - Tunnel: smee.io forwarding for local webhook development
+//   - TunnelProvider: Forward webhooks via smee.io, self-hosted SSE server, or cloudflared
+//   - PayloadArchive: Archive webhook payloads by delivery ID (file, R2, NATS KV)
+//   - IssueClient: File or update keyed GitHub issues (used for Worker error alerts)
+//   - PolicyClient: Check and enforce a repo policy (branch protection, labels, merge settings, webhooks)
 //   - State: Snapshot and persist GitHub repo state (workflow runs, releases)
 //
 // # Poller Usage (Basic - No State)
@@ -24,7 +33,10 @@
 //	    {Subsystem: "owner/repo2", UseTag: true, Tag: "v1.0.0"},
 //	}
 //
-//	poller := syncgh.NewPoller(1*time.Hour, repos, os.Getenv("GITHUB_TOKEN"))
+//	// GITHUB_TOKEN, GH_TOKEN, gh config or GitHub App; App tokens are
+//	// re-minted before they expire, so long-running pollers stay authenticated
+//	auth := ghauth.DefaultSource()
+//	poller := syncgh.NewPoller(1*time.Hour, repos, auth)
 //	poller.OnUpdate(func(subsystem, oldVersion, newVersion string) {
 //	    // NOTE: oldVersion is ALWAYS empty - you must track state yourself
 //	})
@@ -35,7 +47,7 @@
 // StatefulPoller tracks commit hashes between polls and only triggers
 // callbacks when changes are detected:
 //
-//	poller, err := syncgh.NewStatefulPoller(1*time.Hour, repos, token)
+//	poller, err := syncgh.NewStatefulPoller(1*time.Hour, repos, auth)
 //	if err != nil {
 //	    log.Fatal(err)
 //	}
@@ -44,7 +56,27 @@
 //	poller.OnChange(syncgh.TaskCacheInvalidator(workDir))
 //	poller.StartAsync()
 //
-// State is persisted to ~/.xplat/cache/syncgh-poll-state.json
+// OnChange callbacks get a Change with the old and new hash and, from one
+// compare API call, the compare URL, commit count, authors and changed
+// paths, ready for notifications or cache-invalidation decisions:
+//
+//	poller.OnChange(func(c syncgh.Change) {
+//	    log.Printf("%s\n%s", c.Summary(), c.CompareURL)
+//	})
+//
+// State is persisted to ~/.xplat/cache/syncgh-poll-state.json by default.
+// When pollers run on several machines, share state through a StateStore
+// (also selectable with XPLAT_SYNCGH_STATE or 'sync-gh poll --state'):
+//
+//	store, _ := syncgh.ParseStateStore("nats:xplat-state")  // or "r2:r2:bucket/syncgh"
+//	poller, err := syncgh.NewStatefulPollerWithStore(1*time.Hour, repos, auth, store)
+//
+// The state records, per repo, the last check, last change and last error.
+// PollState.Status turns it into RepoPollStatus values with the next expected
+// poll and a Stale flag (not polled for two intervals). A running poller
+// serves the same data for probes and scrapers:
+//
+//	poller.StartHealthServer(8772)  // GET /healthz, /metrics, /status
 //
 // # Task Cache Invalidation
 //
@@ -83,6 +115,18 @@
 //   - POST /{channel}        Receive webhooks
 //   - GET  /{channel}        Channel info page
 //
+// # SSE Server Deployment
+//
+// The Deployer packages the SSE server (Dockerfile running the xplat release
+// binary) and deploys it with the platform CLI, using existing credentials:
+//
+//	result, err := syncgh.NewDeployer(syncgh.DeployConfig{
+//	    Target:         syncgh.DeployTargetFly,
+//	    App:            "my-webhook-relay",
+//	    WebhookSecrets: []string{"secret1"},
+//	}).Deploy(ctx)
+//	fmt.Println(result.PublicURL) // https://my-webhook-relay.fly.dev
+//
 // # SSE Client Usage
 //
 // The SSE client connects to a gosmee/SSE server and forwards events to a local target:
@@ -93,12 +137,33 @@
 //	    SaveDir:      "./webhooks",      // Save payloads for replay
 //	    IgnoreEvents: []string{"ping"},  // Skip these event types
 //	    HealthPort:   8080,               // K8s health probe endpoint
+//	    BufferDir:    "./sse-buffer",     // Keep events until the target accepts them
 //	})
 //	client.Run(ctx)
 //
+// With BufferDir set, delivery is at-least-once: each event is written to
+// disk with a sequence number before it is forwarded and removed only once
+// the target accepts it. While the target is down or returning 5xx, events
+// are retried with backoff and delivered in order when it returns, also
+// after a client restart. A 4xx moves the event to rejected/ so it does not
+// block the events behind it.
+//
 // Or use the convenience function with Task cache invalidation:
 //
-//	syncgh.RunSSEClientWithInvalidation(serverURL, workDir, port, saveDir, ignoreEvents, healthPort)
+//	syncgh.RunSSEClientWithInvalidation(syncgh.SSEClientConfig{ServerURL: serverURL}, workDir, port)
+//
+// # Payload Archive
+//
+// A PayloadArchive stores every received payload under
+// webhooks/<delivery-id>.json in a StateStore instead of flat SaveDir files:
+// local files by default, or R2 (via rclone) or NATS KV so other machines
+// can fetch the history. Set it on SSEClientConfig or WebhookConfig:
+//
+//	archive, _ := syncgh.NewPayloadArchive("r2:r2:xplat-state/syncgh")
+//	client := syncgh.NewSSEClient(syncgh.SSEClientConfig{ServerURL: serverURL, TargetURL: targetURL, Archive: archive})
+//
+//	p, err := archive.Get(ctx, deliveryID)  // ErrPayloadNotArchived if unknown
+//	os.Stdout.Write(p.Payload())
 //
 // # Replay Usage (Catch-up Missed Webhooks)
 //
@@ -114,6 +179,20 @@
 //	})
 //	replayer.Replay(ctx)
 //
+// Filter by event type and delivery status, or let GitHub redeliver
+// to the hook's own URL instead of POSTing locally:
+//
+//	replayer := syncgh.NewReplayer(syncgh.ReplayConfig{
+//	    Owner:      "owner",
+//	    Repo:       "repo",
+//	    HookID:     12345,
+//	    Events:     []string{"push", "release"},
+//	    FailedOnly: true,
+//	    Redeliver:  true,
+//	})
+//	replayer.Replay(ctx)
+//	syncgh.PrintReplayResults(replayer.Results())
+//
 // List hooks and deliveries:
 //
 //	hooks, _ := replayer.ListHooks(ctx)
@@ -121,13 +200,15 @@
 //
 // # Tunnel Usage (Development)
 //
-// For local development, use smee.io to forward webhooks:
+// For local development, expose the webhook handler through a TunnelProvider:
+// smee.io, a self-hosted SSE server, or a cloudflared quick tunnel.
 //
-//	// Generate a new smee.io channel
-//	url := syncgh.GenerateSmeeChannel()
+//	provider, _ := syncgh.NewTunnelProvider(syncgh.ProviderSmee, syncgh.TunnelOptions{})
+//	// or: syncgh.ProviderSSE with TunnelOptions{ServerURL: "https://webhook.example.com"}
+//	// or: syncgh.ProviderCloudflared (no third-party relay)
 //
-//	// Forward events to local server
-//	syncgh.RunTunnel(smeeURL, "http://localhost:8080/webhook")
+//	// Forward events to local server (blocks until ctx is done)
+//	syncgh.RunTunnel(ctx, provider, "http://localhost:8763/webhook")
 //
 // # Design Notes
 //
@@ -148,6 +229,18 @@
 //   - With GITHUB_TOKEN: 5000 requests/hour
 //   - Without token: 60 requests/hour
 //
+// # Repo Policy
+//
+// PolicyClient compares repos with a RepoPolicy loaded from YAML and applies
+// the differences. Each run's drift is saved with the repo state
+// (.github/state/policy-drift.json) so NewDrift can alert only on drift
+// that appeared since the last run:
+//
+//	policy, _ := syncgh.LoadPolicy("repo-policy.yaml")
+//	client, _ := syncgh.NewPolicyClient(token)
+//	drift, _ := client.Check(ctx, "owner/repo", policy)
+//	_ = client.Apply(ctx, "owner/repo", policy, drift)
+//
 // # Repo Auto-Discovery
 //
 // DiscoverReposFromProject scans Taskfile.yml files for remote includes
@@ -155,26 +248,48 @@
 //
 //	repos, err := syncgh.DiscoverReposFromProject(workDir)
 //	configs := syncgh.DiscoverReposToConfigs(repos)
-//	poller, _ := syncgh.NewStatefulPoller(5*time.Minute, configs, token)
+//	poller, _ := syncgh.NewStatefulPoller(5*time.Minute, configs, auth)
 //
 // Supported URL patterns:
 //   - https://raw.githubusercontent.com/owner/repo/branch/path
 //   - https://github.com/owner/repo.git//path
 //
+// DiscoverProjectRepos extends discovery to other project files and tags
+// each repo with the sources it was found in:
+//
+//	repos, err := syncgh.DiscoverProjectRepos(workDir, []syncgh.DiscoverSource{
+//	    syncgh.SourceTaskfile, // Taskfile remote includes
+//	    syncgh.SourceGoMod,    // go.mod requires (same owner as module)
+//	    syncgh.SourceManifest, // xplat.yaml binary source + dependencies
+//	    syncgh.SourceProcessCompose, // github.com refs in process commands
+//	})
+//
 // # CLI Commands
 //
-//	xplat sync-gh discover               # Show repos from Taskfile.yml
+//	xplat sync-gh discover               # Show repos from all project sources
+//	xplat sync-gh discover --from=taskfile,gomod  # Filter by source
 //	xplat sync-gh poll                   # Poll (auto-discover repos)
 //	xplat sync-gh poll --repos=owner/repo  # Poll specific repos
 //	xplat sync-gh poll-state             # Show tracked commit hashes
+//	xplat sync-gh poll-state --format json  # Or: prometheus
+//	xplat sync-gh poll --health-port=8772   # Serve /healthz and /metrics
+//	xplat doctor                         # Includes poller freshness
 //	xplat sync-gh webhook --port=8080    # Start webhook server
-//	xplat sync-gh tunnel <smee-url>      # Forward smee.io events locally
+//	xplat sync-gh tunnel                 # Forward via new smee.io channel
+//	xplat sync-gh tunnel --provider=cloudflared  # Forward via cloudflared quick tunnel
+//	xplat sync-gh relay --provider=sse --url=<server>  # Webhook handler + self-hosted SSE relay
 //	xplat sync-gh tunnel-setup <repo>    # Create smee channel + GitHub webhook
 //	xplat sync-gh state <owner/repo>     # Capture and save repo state
 //	xplat sync-gh release <owner/repo>   # Get latest release tag
+//	xplat sync-gh policy apply --file repo-policy.yaml --dry-run  # Diff repos against a policy
+//	xplat sync-gh policy check --file repo-policy.yaml  # Fail on drift, alert on new drift
 //	xplat sync-gh server                 # Start gosmee-compatible SSE server
+//	xplat sync-gh server deploy --target=fly  # Deploy SSE server (fly, cloudrun)
 //	xplat sync-gh sse-client <url>       # Connect to SSE server and forward events
+//	xplat sync-gh sse-client <url> --archive=<store>  # Archive payloads (file, r2:..., nats:...)
+//	xplat sync-gh archive <delivery-id> --archive=<store>  # Fetch an archived payload
 //	xplat sync-gh replay owner/repo --list-hooks  # List webhooks
 //	xplat sync-gh replay owner/repo 123 --list-deliveries  # List deliveries
 //	xplat sync-gh replay owner/repo 123 http://localhost:8763/webhook  # Replay
+//	xplat sync-gh replay owner/repo 123 --redeliver --failed --event=push  # Redeliver failed pushes
 package syncgh
@@ -5,6 +5,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/joeblew999/xplat/internal/ghauth"
 	"github.com/joeblew999/xplat/internal/syncgh"
 )
 
@@ -16,7 +17,7 @@ func Example_statefulPoller() {
 	}
 
 	// Create stateful poller (tracks commit hashes between polls)
-	poller, err := syncgh.NewStatefulPoller(5*time.Minute, repos, os.Getenv("GITHUB_TOKEN"))
+	poller, err := syncgh.NewStatefulPoller(5*time.Minute, repos, ghauth.DefaultSource())
 	if err != nil {
 		fmt.Println("Error:", err)
 		return
@@ -0,0 +1,212 @@
+package syncgh
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFilterFile is the event filter read from the project directory when
+// no --filter is given.
+const DefaultFilterFile = "sync-gh-filter.yaml"
+
+// compareFilesLimit is the most files the compare API returns. A diff that
+// hits it may be missing files, so it is treated as unknown.
+const compareFilesLimit = 300
+
+// pushCommitsLimit is the most commits a push webhook payload lists. A push
+// with that many may have more, so its files are treated as unknown.
+const pushCommitsLimit = 20
+
+// EventFilter decides which upstream changes trigger cache invalidation and
+// other change callbacks (sync-gh-filter.yaml):
+//
+//	branches: [main, "release/*"]
+//	paths: ["Taskfile.yml", "taskfiles/**"]
+//	ignore_actors: ["*[bot]"]
+//	repos:
+//	  joeblew999/xplat:
+//	    paths: ["taskfiles/**"]
+//
+// Empty fields match everything. A repo entry replaces the fields it sets.
+// Branch and path patterns are path.Match globs; in paths, "**" also matches
+// any number of directories. Actor patterns only support "*" (so "[bot]" is
+// literal) and ignore case. When the changed files or actor are unknown (e.g. a webhook
+// without a commit list) the change is let through, as dropping a real
+// change is worse than a spare invalidation.
+type EventFilter struct {
+	Branches     []string                `yaml:"branches,omitempty"`
+	Paths        []string                `yaml:"paths,omitempty"`
+	IgnoreActors []string                `yaml:"ignore_actors,omitempty"`
+	Repos        map[string]*EventFilter `yaml:"repos,omitempty"`
+}
+
+// ChangeEvent is an upstream change, from a webhook or a poll.
+type ChangeEvent struct {
+	Repo  string
+	Ref   string // Branch or tag name
+	Tag   bool   // Ref is a tag; branch filters don't apply
+	Actor string
+	Files []string // nil when unknown
+}
+
+// LoadEventFilter reads and validates a filter file.
+func LoadEventFilter(path string) (*EventFilter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event filter: %w", err)
+	}
+
+	var f EventFilter
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse event filter %s: %w", path, err)
+	}
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("event filter %s: %w", path, err)
+	}
+	for repo, rf := range f.Repos {
+		if rf == nil {
+			return nil, fmt.Errorf("event filter %s: repo %s has no settings", path, repo)
+		}
+		if len(rf.Repos) > 0 {
+			return nil, fmt.Errorf("event filter %s: repo %s: repos cannot be nested", path, repo)
+		}
+		if err := rf.validate(); err != nil {
+			return nil, fmt.Errorf("event filter %s: repo %s: %w", path, repo, err)
+		}
+	}
+	return &f, nil
+}
+
+// LoadProjectEventFilter reads DefaultFilterFile from dir. It returns nil,
+// nil when the project has none.
+func LoadProjectEventFilter(dir string) (*EventFilter, error) {
+	path := filepath.Join(dir, DefaultFilterFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	return LoadEventFilter(path)
+}
+
+// validate checks every pattern is a valid glob.
+func (f *EventFilter) validate() error {
+	for _, patterns := range [][]string{f.Branches, f.Paths} {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q", p)
+			}
+		}
+	}
+	return nil
+}
+
+// ForRepo returns the filter that applies to repo.
+func (f *EventFilter) ForRepo(repo string) *EventFilter {
+	if f == nil {
+		return nil
+	}
+	rf := &EventFilter{Branches: f.Branches, Paths: f.Paths, IgnoreActors: f.IgnoreActors}
+	if override := f.Repos[repo]; override != nil {
+		if override.Branches != nil {
+			rf.Branches = override.Branches
+		}
+		if override.Paths != nil {
+			rf.Paths = override.Paths
+		}
+		if override.IgnoreActors != nil {
+			rf.IgnoreActors = override.IgnoreActors
+		}
+	}
+	return rf
+}
+
+// NeedsDetails reports whether matching changes in repo needs the changed
+// files or actor, which a poll has to fetch separately.
+func (f *EventFilter) NeedsDetails(repo string) bool {
+	rf := f.ForRepo(repo)
+	return rf != nil && (len(rf.Paths) > 0 || len(rf.IgnoreActors) > 0)
+}
+
+// Match reports whether the change passes the filter, and if not, why.
+// A nil filter matches everything.
+func (f *EventFilter) Match(e ChangeEvent) (bool, string) {
+	rf := f.ForRepo(e.Repo)
+	if rf == nil {
+		return true, ""
+	}
+
+	if !e.Tag && len(rf.Branches) > 0 && !matchAny(rf.Branches, e.Ref) {
+		return false, fmt.Sprintf("branch %s is not in %v", e.Ref, rf.Branches)
+	}
+
+	if e.Actor != "" {
+		for _, p := range rf.IgnoreActors {
+			if matchActor(p, e.Actor) {
+				return false, fmt.Sprintf("actor %s is ignored", e.Actor)
+			}
+		}
+	}
+
+	if len(rf.Paths) > 0 && e.Files != nil {
+		for _, file := range e.Files {
+			for _, p := range rf.Paths {
+				if matchPath(p, file) {
+					return true, ""
+				}
+			}
+		}
+		return false, fmt.Sprintf("none of %d changed files match %v", len(e.Files), rf.Paths)
+	}
+
+	return true, ""
+}
+
+// matchAny reports whether s matches any of the glob patterns.
+func matchAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, s); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// actorEscaper makes everything but "*" literal in an actor pattern.
+var actorEscaper = strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "?", `\?`)
+
+// matchActor matches a login against a pattern where only "*" is special.
+func matchActor(pattern, actor string) bool {
+	ok, _ := path.Match(actorEscaper.Replace(strings.ToLower(pattern)), strings.ToLower(actor))
+	return ok
+}
+
+// matchPath matches a slash-separated file path against a glob where a "**"
+// segment matches zero or more directories.
+func matchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
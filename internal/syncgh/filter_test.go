@@ -0,0 +1,198 @@
+package syncgh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v80/github"
+)
+
+func TestEventFilterMatch(t *testing.T) {
+	filter := &EventFilter{
+		Branches:     []string{"main", "release/*"},
+		Paths:        []string{"Taskfile.yml", "taskfiles/**"},
+		IgnoreActors: []string{"*[bot]"},
+		Repos: map[string]*EventFilter{
+			"o/docs": {Paths: []string{"docs/**/*.yml"}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		event ChangeEvent
+		want  bool
+	}{
+		{"taskfile change", ChangeEvent{Repo: "o/r", Ref: "main", Files: []string{"README.md", "Taskfile.yml"}}, true},
+		{"nested include", ChangeEvent{Repo: "o/r", Ref: "release/1.0", Files: []string{"taskfiles/go/Taskfile.yml"}}, true},
+		{"unrelated files", ChangeEvent{Repo: "o/r", Ref: "main", Files: []string{"README.md", "cmd/main.go"}}, false},
+		{"other branch", ChangeEvent{Repo: "o/r", Ref: "feature", Files: []string{"Taskfile.yml"}}, false},
+		{"tags skip branch filter", ChangeEvent{Repo: "o/r", Ref: "v1.0.0", Tag: true}, true},
+		{"bot", ChangeEvent{Repo: "o/r", Ref: "main", Actor: "Dependabot[bot]", Files: []string{"Taskfile.yml"}}, false},
+		{"unknown files pass", ChangeEvent{Repo: "o/r", Ref: "main", Actor: "alice"}, true},
+		{"empty diff", ChangeEvent{Repo: "o/r", Ref: "main", Files: []string{}}, false},
+		{"repo override", ChangeEvent{Repo: "o/docs", Ref: "main", Files: []string{"docs/a/b/site.yml"}}, true},
+		{"repo override replaces paths", ChangeEvent{Repo: "o/docs", Ref: "main", Files: []string{"Taskfile.yml"}}, false},
+		{"repo override keeps branches", ChangeEvent{Repo: "o/docs", Ref: "feature", Files: []string{"docs/site.yml"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := filter.Match(tt.event)
+			if got != tt.want {
+				t.Errorf("Match = %v (%s), want %v", got, reason, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("no reason given for filtering out")
+			}
+		})
+	}
+
+	var none *EventFilter
+	if ok, _ := none.Match(ChangeEvent{Repo: "o/r", Ref: "feature"}); !ok {
+		t.Error("nil filter should match everything")
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"Taskfile.yml", "Taskfile.yml", true},
+		{"Taskfile.yml", "sub/Taskfile.yml", false},
+		{"**/Taskfile.yml", "Taskfile.yml", true},
+		{"**/Taskfile.yml", "a/b/Taskfile.yml", true},
+		{"taskfiles/**", "taskfiles/go.yml", true},
+		{"taskfiles/**", "taskfiles", true},
+		{"taskfiles/*.yml", "taskfiles/a/go.yml", false},
+		{"a/**/b/*.yml", "a/x/y/b/c.yml", true},
+		{"a/**/b/*.yml", "a/x/y/c.yml", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoadEventFilterErrors(t *testing.T) {
+	tests := map[string]string{
+		"bad pattern": "paths: [\"[\"]\n",
+		"empty repo":  "repos:\n  o/r:\n",
+		"nested":      "repos:\n  o/r:\n    repos:\n      o/s:\n        paths: [a]\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), DefaultFilterFile)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadEventFilter(path); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	filter, err := LoadProjectEventFilter(t.TempDir())
+	if err != nil || filter != nil {
+		t.Errorf("LoadProjectEventFilter without a file = %v, %v; want nil, nil", filter, err)
+	}
+}
+
+func TestPushChangeEvent(t *testing.T) {
+	event := &github.PushEvent{
+		Ref:    github.Ptr("refs/heads/main"),
+		Repo:   &github.PushEventRepository{FullName: github.Ptr("o/r")},
+		Sender: &github.User{Login: github.Ptr("alice")},
+		Commits: []*github.HeadCommit{
+			{Added: []string{"a.yml"}, Modified: []string{"Taskfile.yml"}},
+			{Modified: []string{"Taskfile.yml"}, Removed: []string{"old.yml"}},
+		},
+	}
+	got := pushChangeEvent(event)
+	want := ChangeEvent{Repo: "o/r", Ref: "main", Actor: "alice", Files: []string{"a.yml", "Taskfile.yml", "old.yml"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pushChangeEvent = %+v, want %+v", got, want)
+	}
+
+	tag := pushChangeEvent(&github.PushEvent{Ref: github.Ptr("refs/tags/v1.2.0")})
+	if !tag.Tag || tag.Ref != "v1.2.0" || tag.Files != nil {
+		t.Errorf("tag push = %+v, want tag v1.2.0 with unknown files", tag)
+	}
+
+	// The payload lists at most 20 commits, so a push that long may have
+	// changed files it doesn't show
+	long := &github.PushEvent{Ref: github.Ptr("refs/heads/main")}
+	for i := 0; i < pushCommitsLimit; i++ {
+		long.Commits = append(long.Commits, &github.HeadCommit{Modified: []string{"README.md"}})
+	}
+	if got := pushChangeEvent(long); got.Files != nil {
+		t.Errorf("push of %d commits: Files = %v, want nil (unknown)", pushCommitsLimit, got.Files)
+	}
+}
+
+func TestStatefulPollerFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		files string
+		want  bool
+	}{
+		{"matching change", `[{"filename": "taskfiles/go.yml"}]`, true},
+		{"unrelated change", `[{"filename": "README.md"}]`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compared := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch {
+				case r.URL.Path == "/repos/o/r/commits":
+					_, _ = w.Write([]byte(`[{"sha": "bbbbbbbbbbbb"}]`))
+				case r.URL.Path == "/repos/o/r/compare/aaaaaaaa...bbbbbbbb":
+					compared = true
+					_, _ = w.Write([]byte(`{"commits": [{"author": {"login": "alice"}}], "files": ` + tt.files + `}`))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			store := &FileStateStore{Dir: t.TempDir()}
+			state := &PollState{Repos: map[string]RepoCommitState{}}
+			state.SetRepoHash("o/r", "main", "aaaaaaaa")
+			if err := SavePollStateTo(store, state); err != nil {
+				t.Fatal(err)
+			}
+
+			repos := []RepoConfig{{Subsystem: "o/r", Branch: "main"}}
+			sp, err := NewStatefulPollerWithStore(time.Minute, repos, nil, store)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sp.client.BaseURL.Host = strings.TrimPrefix(server.URL, "http://")
+			sp.client.BaseURL.Scheme = "http"
+			sp.SetFilter(&EventFilter{Paths: []string{"taskfiles/**"}})
+
+			changed := false
+			sp.OnChange(func(Change) { changed = true })
+			sp.checkAll()
+
+			if !compared {
+				t.Error("compare API not called")
+			}
+			if changed != tt.want {
+				t.Errorf("OnChange called = %v, want %v", changed, tt.want)
+			}
+			if got := sp.State().GetRepoHash("o/r", "main"); got != "bbbbbbbb" {
+				t.Errorf("hash = %q, want bbbbbbbb (recorded even when filtered)", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package syncgh
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/google/go-github/v81/github"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
+)
+
+// IssueClient files GitHub issues for incidents raised by other sync services.
+//
+// Issues are keyed: the key is kept in a hidden marker in the issue body, and
+// a repeat of an incident whose issue is still open adds a comment to it
+// instead of opening a new one.
+type IssueClient struct {
+	Owner string
+	Repo  string
+
+	client *github.Client
+}
+
+// NewIssueClient creates an issue client for "owner/repo".
+// The token from auth needs issues write access to the repository.
+func NewIssueClient(repo string, auth ghauth.TokenSource) (*IssueClient, error) {
+	owner, name := parseRepo(repo)
+	if owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repo %q, expected owner/repo", repo)
+	}
+	token, err := tokenOf(auth)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required to file issues")
+	}
+
+	return &IssueClient{
+		Owner:  owner,
+		Repo:   name,
+		client: newGitHubClient(auth),
+	}, nil
+}
+
+// SetBaseURL points the client at another API endpoint (GitHub Enterprise or tests).
+func (c *IssueClient) SetBaseURL(baseURL string) error {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	c.client.BaseURL = u
+	return nil
+}
+
+// issueKeyMarker returns the hidden body marker for an issue key.
+func issueKeyMarker(key string) string {
+	return fmt.Sprintf("<!-- xplat-issue-key: %s -->", key)
+}
+
+// FileOrUpdateIssue comments on the open issue carrying key, or opens a new
+// issue with the title, body and labels if there is none.
+// Returns the issue URL and whether the issue was created.
+func (c *IssueClient) FileOrUpdateIssue(ctx context.Context, key, title, body string, labels []string) (string, bool, error) {
+	existing, err := c.findIssue(ctx, key, labels)
+	if err != nil {
+		return "", false, err
+	}
+
+	if existing != nil {
+		_, _, err := c.client.Issues.CreateComment(ctx, c.Owner, c.Repo, existing.GetNumber(), &github.IssueComment{
+			Body: github.Ptr(body),
+		})
+		if err != nil {
+			return "", false, fmt.Errorf("failed to comment on issue #%d: %w", existing.GetNumber(), err)
+		}
+		return existing.GetHTMLURL(), false, nil
+	}
+
+	req := &github.IssueRequest{
+		Title: github.Ptr(title),
+		Body:  github.Ptr(body + "\n\n" + issueKeyMarker(key)),
+	}
+	if len(labels) > 0 {
+		req.Labels = &labels
+	}
+	issue, _, err := c.client.Issues.Create(ctx, c.Owner, c.Repo, req)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create issue: %w", err)
+	}
+	return issue.GetHTMLURL(), true, nil
+}
+
+// findIssue returns the open issue whose body carries key, or nil.
+func (c *IssueClient) findIssue(ctx context.Context, key string, labels []string) (*github.Issue, error) {
+	marker := issueKeyMarker(key)
+	opts := &github.IssueListByRepoOptions{
+		State:       "open",
+		Labels:      labels,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		issues, resp, err := c.client.Issues.ListByRepo(ctx, c.Owner, c.Repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, issue := range issues {
+			if issue.IsPullRequest() {
+				continue
+			}
+			if strings.Contains(issue.GetBody(), marker) {
+				return issue, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			return nil, nil
+		}
+		opts.ListOptions.Page = resp.NextPage
+	}
+}
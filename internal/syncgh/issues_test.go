@@ -0,0 +1,108 @@
+package syncgh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
+)
+
+// fakeIssuesAPI serves the issue endpoints used by IssueClient.
+type fakeIssuesAPI struct {
+	mu       sync.Mutex
+	issues   []map[string]interface{}
+	comments map[int][]string
+}
+
+func (f *fakeIssuesAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/issues":
+		_ = json.NewEncoder(w).Encode(f.issues)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/issues":
+		var req struct{ Title, Body string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		number := len(f.issues) + 1
+		issue := map[string]interface{}{
+			"number":   number,
+			"title":    req.Title,
+			"body":     req.Body,
+			"html_url": fmt.Sprintf("https://github.com/o/r/issues/%d", number),
+		}
+		f.issues = append(f.issues, issue)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(issue)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comments"):
+		var number int
+		_, _ = fmt.Sscanf(r.URL.Path, "/repos/o/r/issues/%d/comments", &number)
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.comments[number] = append(f.comments[number], req["body"].(string))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(req)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestIssueClientFileOrUpdate(t *testing.T) {
+	api := &fakeIssuesAPI{comments: make(map[int][]string)}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := NewIssueClient("o/r", ghauth.StaticToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	url, created, err := client.FileOrUpdateIssue(ctx, "worker/api/error_rate", "Worker alert", "first", []string{"worker-alert"})
+	if err != nil {
+		t.Fatalf("FileOrUpdateIssue() error = %v", err)
+	}
+	if !created || url != "https://github.com/o/r/issues/1" {
+		t.Errorf("first alert: url = %q, created = %v", url, created)
+	}
+	if body := api.issues[0]["body"].(string); !strings.Contains(body, issueKeyMarker("worker/api/error_rate")) {
+		t.Errorf("issue body missing key marker: %q", body)
+	}
+
+	// Same key comments on the open issue
+	_, created, err = client.FileOrUpdateIssue(ctx, "worker/api/error_rate", "Worker alert", "second", []string{"worker-alert"})
+	if err != nil {
+		t.Fatalf("FileOrUpdateIssue() error = %v", err)
+	}
+	if created || len(api.issues) != 1 || len(api.comments[1]) != 1 || api.comments[1][0] != "second" {
+		t.Errorf("repeat alert: created = %v, issues = %d, comments = %v", created, len(api.issues), api.comments)
+	}
+
+	// Another key opens a new issue
+	_, created, err = client.FileOrUpdateIssue(ctx, "worker/api/cpu_exceeded", "Worker alert", "third", nil)
+	if err != nil {
+		t.Fatalf("FileOrUpdateIssue() error = %v", err)
+	}
+	if !created || len(api.issues) != 2 {
+		t.Errorf("new key: created = %v, issues = %d", created, len(api.issues))
+	}
+}
+
+func TestNewIssueClientErrors(t *testing.T) {
+	if _, err := NewIssueClient("norepo", ghauth.StaticToken("token")); err == nil {
+		t.Error("expected error for repo without owner")
+	}
+	if _, err := NewIssueClient("o/r", ghauth.StaticToken("")); err == nil {
+		t.Error("expected error without token")
+	}
+}
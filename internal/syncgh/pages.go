@@ -2,20 +2,22 @@ package syncgh
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
 )
 
 // EnablePages enables GitHub Pages for a repository using the workflow build type.
-// It uses GITHUB_TOKEN environment variable for authentication.
+// It authenticates with the ghauth credential chain.
 // Returns nil if pages are already enabled or successfully enabled.
 func EnablePages(owner, repo string) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return fmt.Errorf("GITHUB_TOKEN not set")
+	token, err := ghauth.RequireToken(context.Background())
+	if err != nil {
+		return err
 	}
 
 	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pages", owner, repo)
@@ -0,0 +1,656 @@
+package syncgh
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBranchKey in a policy's branches map means the repo's default branch.
+const DefaultBranchKey = "default"
+
+// PolicyDriftFile is the drift snapshot written next to the other state files.
+const PolicyDriftFile = "policy-drift.json"
+
+// Policy drift kinds.
+const (
+	DriftBranch  = "branch"
+	DriftLabel   = "label"
+	DriftMerge   = "merge"
+	DriftWebhook = "webhook"
+)
+
+// RepoPolicy is the settings every repo should have (repo-policy.yaml).
+//
+// Protected branches are enforced as a whole: applying replaces the branch's
+// protection with the policy, so settings not in the policy are reset.
+// Labels and webhooks are only added or corrected, never deleted.
+type RepoPolicy struct {
+	Branches map[string]*BranchPolicy `yaml:"branches,omitempty"` // Keyed by branch name or "default"
+	Labels   []LabelPolicy            `yaml:"labels,omitempty"`
+	Merge    *MergePolicy             `yaml:"merge,omitempty"`
+	Webhooks []WebhookPolicy          `yaml:"webhooks,omitempty"`
+}
+
+// BranchPolicy is the protection for one branch.
+type BranchPolicy struct {
+	RequiredReviews     int      `yaml:"required_reviews,omitempty"` // 0-6
+	DismissStaleReviews bool     `yaml:"dismiss_stale_reviews,omitempty"`
+	CodeOwnerReviews    bool     `yaml:"code_owner_reviews,omitempty"`
+	RequiredChecks      []string `yaml:"required_checks,omitempty"`
+	Strict              bool     `yaml:"strict,omitempty"` // Branch must be up to date before merging
+	EnforceAdmins       bool     `yaml:"enforce_admins,omitempty"`
+	LinearHistory       bool     `yaml:"linear_history,omitempty"`
+	AllowForcePushes    bool     `yaml:"allow_force_pushes,omitempty"`
+	AllowDeletions      bool     `yaml:"allow_deletions,omitempty"`
+}
+
+// LabelPolicy is a label every repo should have.
+type LabelPolicy struct {
+	Name        string `yaml:"name"`
+	Color       string `yaml:"color"` // 6 hex digits, no #
+	Description string `yaml:"description,omitempty"`
+}
+
+// MergePolicy holds repo merge settings. Unset fields are not checked.
+type MergePolicy struct {
+	AllowMergeCommit    *bool `yaml:"allow_merge_commit,omitempty"`
+	AllowSquashMerge    *bool `yaml:"allow_squash_merge,omitempty"`
+	AllowRebaseMerge    *bool `yaml:"allow_rebase_merge,omitempty"`
+	AllowAutoMerge      *bool `yaml:"allow_auto_merge,omitempty"`
+	DeleteBranchOnMerge *bool `yaml:"delete_branch_on_merge,omitempty"`
+}
+
+// WebhookPolicy is a webhook every repo should have, matched by URL.
+// Existing hooks are checked for their events, a json content type and
+// being active. GitHub never returns the secret, so it is only set when a
+// hook is created or its config corrected.
+type WebhookPolicy struct {
+	URL       string   `yaml:"url"`
+	Events    []string `yaml:"events,omitempty"`     // Default: push
+	SecretEnv string   `yaml:"secret_env,omitempty"` // Env var holding the secret; must be set when applying
+}
+
+// webhookContentType is the payload format every policy webhook uses.
+const webhookContentType = "json"
+
+// events returns the policy's events, sorted, defaulting to push.
+func (w WebhookPolicy) events() []string {
+	if len(w.Events) == 0 {
+		return []string{"push"}
+	}
+	events := slices.Clone(w.Events)
+	sort.Strings(events)
+	return events
+}
+
+// secret returns the webhook secret from SecretEnv, or an error when the
+// variable is named but unset, so hooks are never created unsigned.
+func (w WebhookPolicy) secret() (string, error) {
+	if w.SecretEnv == "" {
+		return "", nil
+	}
+	secret := os.Getenv(w.SecretEnv)
+	if secret == "" {
+		return "", fmt.Errorf("webhook %s: secret_env %s is not set", w.URL, w.SecretEnv)
+	}
+	return secret, nil
+}
+
+var labelColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(path string) (*RepoPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	var p RepoPolicy
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+
+	for name, b := range p.Branches {
+		if b == nil {
+			return nil, fmt.Errorf("policy %s: branch %s has no settings", path, name)
+		}
+		if b.RequiredReviews < 0 || b.RequiredReviews > 6 {
+			return nil, fmt.Errorf("policy %s: branch %s: required_reviews must be 0-6", path, name)
+		}
+	}
+	for _, l := range p.Labels {
+		if l.Name == "" {
+			return nil, fmt.Errorf("policy %s: label without a name", path)
+		}
+		if !labelColorPattern.MatchString(l.Color) {
+			return nil, fmt.Errorf("policy %s: label %s: color must be 6 hex digits", path, l.Name)
+		}
+	}
+	for _, w := range p.Webhooks {
+		if w.URL == "" {
+			return nil, fmt.Errorf("policy %s: webhook without a url", path)
+		}
+	}
+	return &p, nil
+}
+
+// PolicyDrift is one difference between a repo and the policy.
+type PolicyDrift struct {
+	Repo   string `json:"repo"`
+	Kind   string `json:"kind"`            // branch, label, merge or webhook
+	Target string `json:"target"`          // Branch, label name, merge setting or webhook URL
+	Field  string `json:"field,omitempty"` // Branch protection or label field
+	Want   string `json:"want"`
+	Have   string `json:"have"`
+}
+
+// Key identifies the drift item across snapshots.
+func (d PolicyDrift) Key() string {
+	return strings.Join([]string{d.Repo, d.Kind, d.Target, d.Field}, "|")
+}
+
+func (d PolicyDrift) String() string {
+	target := d.Target
+	if d.Field != "" {
+		target += "." + d.Field
+	}
+	return fmt.Sprintf("%s %s %s: %s -> %s", d.Repo, d.Kind, target, d.Have, d.Want)
+}
+
+// PolicyClient checks and enforces a RepoPolicy on GitHub repos.
+type PolicyClient struct {
+	client *github.Client
+}
+
+// NewPolicyClient creates a policy client. The token needs admin access to
+// the repos to read and change branch protection and webhooks.
+func NewPolicyClient(token string) (*PolicyClient, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required to check repo policy")
+	}
+	return &PolicyClient{client: github.NewClient(nil).WithAuthToken(token)}, nil
+}
+
+// SetBaseURL points the client at another API endpoint (GitHub Enterprise or tests).
+func (c *PolicyClient) SetBaseURL(baseURL string) error {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	c.client.BaseURL = u
+	return nil
+}
+
+// Check returns how "owner/repo" differs from the policy, sorted by kind and target.
+func (c *PolicyClient) Check(ctx context.Context, repo string, p *RepoPolicy) ([]PolicyDrift, error) {
+	owner, name := parseRepo(repo)
+	if owner == "" || name == "" {
+		return nil, fmt.Errorf("invalid repo %q, expected owner/repo", repo)
+	}
+
+	r, _, err := c.client.Repositories.Get(ctx, owner, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", repo, err)
+	}
+
+	var drift []PolicyDrift
+	add := func(kind, target, field, want, have string) {
+		drift = append(drift, PolicyDrift{Repo: repo, Kind: kind, Target: target, Field: field, Want: want, Have: have})
+	}
+
+	for key, want := range p.Branches {
+		branch := c.resolveBranch(key, r)
+		prot, _, err := c.client.Repositories.GetBranchProtection(ctx, owner, name, branch)
+		if errors.Is(err, github.ErrBranchNotProtected) {
+			add(DriftBranch, branch, "protection", "enabled", "none")
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s protection for %s: %w", branch, repo, err)
+		}
+		for _, f := range branchFields(want, prot) {
+			add(DriftBranch, branch, f[0], f[1], f[2])
+		}
+	}
+
+	if len(p.Labels) > 0 {
+		labels, err := c.listLabels(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, want := range p.Labels {
+			have, ok := labels[strings.ToLower(want.Name)]
+			if !ok {
+				add(DriftLabel, want.Name, "", "present", "missing")
+				continue
+			}
+			if !strings.EqualFold(have.GetColor(), want.Color) {
+				add(DriftLabel, want.Name, "color", strings.ToLower(want.Color), have.GetColor())
+			}
+			if have.GetDescription() != want.Description {
+				add(DriftLabel, want.Name, "description", strconv.Quote(want.Description), strconv.Quote(have.GetDescription()))
+			}
+		}
+	}
+
+	if p.Merge != nil {
+		for _, s := range mergeSettings(p.Merge, r) {
+			if s.want != nil && *s.want != s.have {
+				add(DriftMerge, s.name, "", strconv.FormatBool(*s.want), strconv.FormatBool(s.have))
+			}
+		}
+	}
+
+	if len(p.Webhooks) > 0 {
+		hooks, err := c.listHooks(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, want := range p.Webhooks {
+			have, ok := hooks[want.URL]
+			if !ok {
+				add(DriftWebhook, want.URL, "", "present", "missing")
+				continue
+			}
+			for _, f := range webhookFields(want, have) {
+				add(DriftWebhook, want.URL, f[0], f[1], f[2])
+			}
+		}
+	}
+
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Key() < drift[j].Key() })
+	return drift, nil
+}
+
+// Apply changes "owner/repo" to remove the drift found by Check.
+func (c *PolicyClient) Apply(ctx context.Context, repo string, p *RepoPolicy, drift []PolicyDrift) error {
+	owner, name := parseRepo(repo)
+	if owner == "" || name == "" {
+		return fmt.Errorf("invalid repo %q, expected owner/repo", repo)
+	}
+
+	branches := make(map[string]bool)
+	labels := make(map[string]bool)
+	hooks := make(map[string][]string) // Webhook URL -> drifted fields ("" = missing)
+	merge := false
+	for _, d := range drift {
+		switch d.Kind {
+		case DriftBranch:
+			branches[d.Target] = true
+		case DriftLabel:
+			labels[d.Target] = true
+		case DriftWebhook:
+			hooks[d.Target] = append(hooks[d.Target], d.Field)
+		case DriftMerge:
+			merge = true
+		}
+	}
+
+	// Check secrets before changing anything, so a missing one fails the
+	// repo cleanly instead of leaving it half applied
+	for _, want := range p.Webhooks {
+		if _, ok := hooks[want.URL]; ok {
+			if _, err := want.secret(); err != nil {
+				return err
+			}
+		}
+	}
+
+	var r *github.Repository
+	if len(branches) > 0 || merge {
+		var err error
+		r, _, err = c.client.Repositories.Get(ctx, owner, name)
+		if err != nil {
+			return fmt.Errorf("failed to get %s: %w", repo, err)
+		}
+	}
+
+	for key, want := range p.Branches {
+		branch := c.resolveBranch(key, r)
+		if !branches[branch] {
+			continue
+		}
+		if _, _, err := c.client.Repositories.UpdateBranchProtection(ctx, owner, name, branch, protectionRequest(want)); err != nil {
+			return fmt.Errorf("failed to protect %s on %s: %w", branch, repo, err)
+		}
+	}
+
+	for _, want := range p.Labels {
+		if !labels[want.Name] {
+			continue
+		}
+		if err := c.applyLabel(ctx, owner, name, want, drift); err != nil {
+			return err
+		}
+	}
+
+	if merge {
+		edit := &github.Repository{}
+		for _, s := range mergeSettings(p.Merge, r) {
+			if s.want != nil {
+				s.set(edit, *s.want)
+			}
+		}
+		if _, _, err := c.client.Repositories.Edit(ctx, owner, name, edit); err != nil {
+			return fmt.Errorf("failed to update merge settings on %s: %w", repo, err)
+		}
+	}
+
+	var existing map[string]*github.Hook
+	for _, want := range p.Webhooks {
+		fields, ok := hooks[want.URL]
+		if !ok {
+			continue
+		}
+		if slices.Contains(fields, "") {
+			if err := c.createHook(ctx, owner, name, want); err != nil {
+				return err
+			}
+			continue
+		}
+		if existing == nil {
+			var err error
+			if existing, err = c.listHooks(ctx, owner, name); err != nil {
+				return err
+			}
+		}
+		have, ok := existing[want.URL]
+		if !ok {
+			return fmt.Errorf("webhook %s on %s: not found", want.URL, repo)
+		}
+		if err := c.editHook(ctx, owner, name, have.GetID(), want, fields); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hookConfig returns the config a policy webhook is created or corrected with.
+func hookConfig(want WebhookPolicy) (*github.HookConfig, error) {
+	config := &github.HookConfig{URL: github.Ptr(want.URL), ContentType: github.Ptr(webhookContentType)}
+	secret, err := want.secret()
+	if err != nil {
+		return nil, err
+	}
+	if secret != "" {
+		config.Secret = github.Ptr(secret)
+	}
+	return config, nil
+}
+
+func (c *PolicyClient) createHook(ctx context.Context, owner, repo string, want WebhookPolicy) error {
+	config, err := hookConfig(want)
+	if err != nil {
+		return err
+	}
+	hook := &github.Hook{Config: config, Events: want.events(), Active: github.Ptr(true)}
+	if _, _, err := c.client.Repositories.CreateHook(ctx, owner, repo, hook); err != nil {
+		return fmt.Errorf("failed to create webhook %s on %s/%s: %w", want.URL, owner, repo, err)
+	}
+	return nil
+}
+
+// editHook corrects the drifted fields of an existing webhook. The config is
+// only sent when it drifted, as replacing it also replaces the secret.
+func (c *PolicyClient) editHook(ctx context.Context, owner, repo string, id int64, want WebhookPolicy, fields []string) error {
+	hook := &github.Hook{}
+	for _, f := range fields {
+		switch f {
+		case "events":
+			hook.Events = want.events()
+		case "active":
+			hook.Active = github.Ptr(true)
+		case "content_type":
+			config, err := hookConfig(want)
+			if err != nil {
+				return err
+			}
+			hook.Config = config
+		}
+	}
+	if _, _, err := c.client.Repositories.EditHook(ctx, owner, repo, id, hook); err != nil {
+		return fmt.Errorf("failed to update webhook %s on %s/%s: %w", want.URL, owner, repo, err)
+	}
+	return nil
+}
+
+// webhookFields compares a webhook with the policy, returning
+// {field, want, have} for each difference.
+func webhookFields(want WebhookPolicy, have *github.Hook) [][3]string {
+	var fields [][3]string
+	haveEvents := slices.Clone(have.Events)
+	sort.Strings(haveEvents)
+	if wantEvents := want.events(); !slices.Equal(wantEvents, haveEvents) {
+		fields = append(fields, [3]string{"events", strings.Join(wantEvents, ","), strings.Join(haveEvents, ",")})
+	}
+	if ct := have.GetConfig().GetContentType(); ct != webhookContentType {
+		fields = append(fields, [3]string{"content_type", webhookContentType, ct})
+	}
+	if !have.GetActive() {
+		fields = append(fields, [3]string{"active", "true", "false"})
+	}
+	return fields
+}
+
+// resolveBranch maps the "default" key to the repo's default branch.
+func (c *PolicyClient) resolveBranch(key string, r *github.Repository) string {
+	if key == DefaultBranchKey && r != nil && r.GetDefaultBranch() != "" {
+		return r.GetDefaultBranch()
+	}
+	return key
+}
+
+func (c *PolicyClient) applyLabel(ctx context.Context, owner, repo string, want LabelPolicy, drift []PolicyDrift) error {
+	label := &github.Label{
+		Name:        github.Ptr(want.Name),
+		Color:       github.Ptr(strings.ToLower(want.Color)),
+		Description: github.Ptr(want.Description),
+	}
+	for _, d := range drift {
+		if d.Kind == DriftLabel && d.Target == want.Name && d.Field == "" {
+			if _, _, err := c.client.Issues.CreateLabel(ctx, owner, repo, label); err != nil {
+				return fmt.Errorf("failed to create label %s on %s/%s: %w", want.Name, owner, repo, err)
+			}
+			return nil
+		}
+	}
+	if _, _, err := c.client.Issues.EditLabel(ctx, owner, repo, want.Name, label); err != nil {
+		return fmt.Errorf("failed to update label %s on %s/%s: %w", want.Name, owner, repo, err)
+	}
+	return nil
+}
+
+// listLabels returns the repo's labels keyed by lower-case name.
+func (c *PolicyClient) listLabels(ctx context.Context, owner, repo string) (map[string]*github.Label, error) {
+	labels := make(map[string]*github.Label)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.client.Issues.ListLabels(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels for %s/%s: %w", owner, repo, err)
+		}
+		for _, l := range page {
+			labels[strings.ToLower(l.GetName())] = l
+		}
+		if resp.NextPage == 0 {
+			return labels, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// listHooks returns the repo's webhooks keyed by URL.
+func (c *PolicyClient) listHooks(ctx context.Context, owner, repo string) (map[string]*github.Hook, error) {
+	hooks := make(map[string]*github.Hook)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := c.client.Repositories.ListHooks(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list webhooks for %s/%s: %w", owner, repo, err)
+		}
+		for _, h := range page {
+			if h.Config != nil {
+				hooks[h.Config.GetURL()] = h
+			}
+		}
+		if resp.NextPage == 0 {
+			return hooks, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// branchFields compares protection with the policy, returning
+// {field, want, have} for each difference.
+func branchFields(want *BranchPolicy, have *github.Protection) [][3]string {
+	var reviews int
+	var stale, codeOwners bool
+	if r := have.RequiredPullRequestReviews; r != nil {
+		reviews, stale, codeOwners = r.RequiredApprovingReviewCount, r.DismissStaleReviews, r.RequireCodeOwnerReviews
+	}
+
+	var checks []string
+	var strict bool
+	if s := have.RequiredStatusChecks; s != nil {
+		strict = s.Strict
+		if s.Checks != nil {
+			for _, c := range *s.Checks {
+				checks = append(checks, c.Context)
+			}
+		} else if s.Contexts != nil {
+			checks = append(checks, *s.Contexts...)
+		}
+	}
+	wantChecks := append([]string(nil), want.RequiredChecks...)
+	sort.Strings(checks)
+	sort.Strings(wantChecks)
+
+	var diffs [][3]string
+	cmp := func(field, w, h string) {
+		if w != h {
+			diffs = append(diffs, [3]string{field, w, h})
+		}
+	}
+	cmp("required_reviews", strconv.Itoa(want.RequiredReviews), strconv.Itoa(reviews))
+	cmp("dismiss_stale_reviews", strconv.FormatBool(want.DismissStaleReviews), strconv.FormatBool(stale))
+	cmp("code_owner_reviews", strconv.FormatBool(want.CodeOwnerReviews), strconv.FormatBool(codeOwners))
+	cmp("required_checks", "["+strings.Join(wantChecks, ",")+"]", "["+strings.Join(checks, ",")+"]")
+	cmp("strict", strconv.FormatBool(want.Strict), strconv.FormatBool(strict))
+	cmp("enforce_admins", strconv.FormatBool(want.EnforceAdmins), strconv.FormatBool(have.EnforceAdmins != nil && have.EnforceAdmins.Enabled))
+	cmp("linear_history", strconv.FormatBool(want.LinearHistory), strconv.FormatBool(have.RequireLinearHistory != nil && have.RequireLinearHistory.Enabled))
+	cmp("allow_force_pushes", strconv.FormatBool(want.AllowForcePushes), strconv.FormatBool(have.AllowForcePushes != nil && have.AllowForcePushes.Enabled))
+	cmp("allow_deletions", strconv.FormatBool(want.AllowDeletions), strconv.FormatBool(have.AllowDeletions != nil && have.AllowDeletions.Enabled))
+	return diffs
+}
+
+// protectionRequest builds the full protection for a branch from the policy.
+func protectionRequest(p *BranchPolicy) *github.ProtectionRequest {
+	req := &github.ProtectionRequest{
+		EnforceAdmins:        p.EnforceAdmins,
+		RequireLinearHistory: github.Ptr(p.LinearHistory),
+		AllowForcePushes:     github.Ptr(p.AllowForcePushes),
+		AllowDeletions:       github.Ptr(p.AllowDeletions),
+	}
+	if len(p.RequiredChecks) > 0 || p.Strict {
+		checks := make([]*github.RequiredStatusCheck, 0, len(p.RequiredChecks))
+		for _, c := range p.RequiredChecks {
+			checks = append(checks, &github.RequiredStatusCheck{Context: c})
+		}
+		req.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: p.Strict, Checks: &checks}
+	}
+	if p.RequiredReviews > 0 || p.DismissStaleReviews || p.CodeOwnerReviews {
+		req.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{
+			RequiredApprovingReviewCount: p.RequiredReviews,
+			DismissStaleReviews:          p.DismissStaleReviews,
+			RequireCodeOwnerReviews:      p.CodeOwnerReviews,
+		}
+	}
+	return req
+}
+
+// mergeSetting pairs a policy merge field with the repo's current value.
+type mergeSetting struct {
+	name string
+	want *bool
+	have bool
+	set  func(r *github.Repository, v bool)
+}
+
+func mergeSettings(p *MergePolicy, r *github.Repository) []mergeSetting {
+	return []mergeSetting{
+		{"allow_merge_commit", p.AllowMergeCommit, r.GetAllowMergeCommit(), func(r *github.Repository, v bool) { r.AllowMergeCommit = github.Ptr(v) }},
+		{"allow_squash_merge", p.AllowSquashMerge, r.GetAllowSquashMerge(), func(r *github.Repository, v bool) { r.AllowSquashMerge = github.Ptr(v) }},
+		{"allow_rebase_merge", p.AllowRebaseMerge, r.GetAllowRebaseMerge(), func(r *github.Repository, v bool) { r.AllowRebaseMerge = github.Ptr(v) }},
+		{"allow_auto_merge", p.AllowAutoMerge, r.GetAllowAutoMerge(), func(r *github.Repository, v bool) { r.AllowAutoMerge = github.Ptr(v) }},
+		{"delete_branch_on_merge", p.DeleteBranchOnMerge, r.GetDeleteBranchOnMerge(), func(r *github.Repository, v bool) { r.DeleteBranchOnMerge = github.Ptr(v) }},
+	}
+}
+
+// PolicyReport is a drift snapshot, saved with the repo state so the next
+// run can tell new drift from drift that was already reported.
+type PolicyReport struct {
+	CheckedAt time.Time     `json:"checked_at"`
+	Repos     []string      `json:"repos"`
+	Drift     []PolicyDrift `json:"drift"`
+	Applied   bool          `json:"applied"` // Drift was fixed after this check
+}
+
+// SavePolicyReport writes the report to dir/policy-drift.json.
+func SavePolicyReport(report *PolicyReport, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+	return writeJSON(filepath.Join(dir, PolicyDriftFile), report)
+}
+
+// LoadPolicyReport reads the last drift snapshot. Returns nil if there is none.
+func LoadPolicyReport(dir string) (*PolicyReport, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PolicyDriftFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drift snapshot: %w", err)
+	}
+	var report PolicyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse drift snapshot: %w", err)
+	}
+	return &report, nil
+}
+
+// NewDrift returns the drift not in the previous snapshot. Drift that was
+// applied in the previous run counts as new if it came back.
+func NewDrift(previous *PolicyReport, drift []PolicyDrift) []PolicyDrift {
+	seen := make(map[string]bool)
+	if previous != nil && !previous.Applied {
+		for _, d := range previous.Drift {
+			seen[d.Key()] = true
+		}
+	}
+	var fresh []PolicyDrift
+	for _, d := range drift {
+		if !seen[d.Key()] {
+			fresh = append(fresh, d)
+		}
+	}
+	return fresh
+}
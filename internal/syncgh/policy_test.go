@@ -0,0 +1,254 @@
+package syncgh
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakePolicyAPI serves the repo settings endpoints used by PolicyClient.
+type fakePolicyAPI struct {
+	mu         sync.Mutex
+	repo       map[string]interface{}
+	protection map[string]interface{} // nil = not protected
+	labels     []map[string]interface{}
+	hooks      []map[string]interface{}
+	writes     []string // "METHOD path" of each change
+}
+
+func (f *fakePolicyAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		f.writes = append(f.writes, r.Method+" "+r.URL.Path)
+	}
+
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	switch {
+	case r.URL.Path == "/repos/o/r" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.repo)
+	case r.URL.Path == "/repos/o/r" && r.Method == http.MethodPatch:
+		for k, v := range body {
+			f.repo[k] = v
+		}
+		_ = json.NewEncoder(w).Encode(f.repo)
+	case r.URL.Path == "/repos/o/r/branches/main/protection" && r.Method == http.MethodGet:
+		if f.protection == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Branch not protected"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.protection)
+	case r.URL.Path == "/repos/o/r/branches/main/protection" && r.Method == http.MethodPut:
+		reviews, _ := body["required_pull_request_reviews"].(map[string]interface{})
+		f.protection = map[string]interface{}{
+			"required_pull_request_reviews": reviews,
+			"required_linear_history":       map[string]interface{}{"enabled": body["required_linear_history"]},
+		}
+		_ = json.NewEncoder(w).Encode(f.protection)
+	case r.URL.Path == "/repos/o/r/labels" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.labels)
+	case r.URL.Path == "/repos/o/r/labels" && r.Method == http.MethodPost:
+		f.labels = append(f.labels, body)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(body)
+	case strings.HasPrefix(r.URL.Path, "/repos/o/r/labels/") && r.Method == http.MethodPatch:
+		name := strings.TrimPrefix(r.URL.Path, "/repos/o/r/labels/")
+		for _, l := range f.labels {
+			if l["name"] == name {
+				for k, v := range body {
+					l[k] = v
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	case r.URL.Path == "/repos/o/r/hooks" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.hooks)
+	case r.URL.Path == "/repos/o/r/hooks" && r.Method == http.MethodPost:
+		f.hooks = append(f.hooks, body)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(body)
+	case strings.HasPrefix(r.URL.Path, "/repos/o/r/hooks/") && r.Method == http.MethodPatch:
+		id := strings.TrimPrefix(r.URL.Path, "/repos/o/r/hooks/")
+		for _, h := range f.hooks {
+			if fmt.Sprint(h["id"]) == id {
+				for k, v := range body {
+					h[k] = v
+				}
+			}
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestPolicyClientCheckAndApply(t *testing.T) {
+	api := &fakePolicyAPI{
+		repo: map[string]interface{}{"default_branch": "main", "allow_merge_commit": true, "allow_squash_merge": true},
+		labels: []map[string]interface{}{
+			{"name": "bug", "color": "ffffff", "description": "Something isn't working"},
+		},
+		hooks: []map[string]interface{}{
+			{"id": 1, "config": map[string]interface{}{"url": "https://other.example.com"}},
+			{"id": 2, "events": []string{"push"}, "active": false, "config": map[string]interface{}{"url": "https://ci.example.com/hook", "content_type": "json"}},
+		},
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	client, err := NewPolicyClient("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	policyPath := filepath.Join(t.TempDir(), "repo-policy.yaml")
+	err = os.WriteFile(policyPath, []byte(`branches:
+  default:
+    required_reviews: 1
+    linear_history: true
+labels:
+  - name: bug
+    color: d73a4a
+    description: Something isn't working
+  - name: release
+    color: 0e8a16
+merge:
+  allow_merge_commit: false
+  allow_squash_merge: true
+webhooks:
+  - url: https://hooks.example.com/webhook
+    events: [push, release]
+    secret_env: POLICY_TEST_SECRET
+  - url: https://ci.example.com/hook
+    events: [push, pull_request]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy(policyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	drift, err := client.Check(ctx, "o/r", policy)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	got := make(map[string]bool)
+	for _, d := range drift {
+		got[d.Kind+" "+d.Target+" "+d.Field] = true
+	}
+	for _, want := range []string{
+		"branch main protection",
+		"label bug color",
+		"label release ",
+		"merge allow_merge_commit ",
+		"webhook https://hooks.example.com/webhook ",
+		"webhook https://ci.example.com/hook events",
+		"webhook https://ci.example.com/hook active",
+	} {
+		if !got[want] {
+			t.Errorf("missing drift %q in %v", want, drift)
+		}
+	}
+	if len(drift) != 7 {
+		t.Errorf("drift = %v", drift)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("Check() made changes: %v", api.writes)
+	}
+
+	// An unset secret_env fails before anything is changed
+	t.Setenv("POLICY_TEST_SECRET", "")
+	if err := client.Apply(ctx, "o/r", policy, drift); err == nil || !strings.Contains(err.Error(), "POLICY_TEST_SECRET") {
+		t.Errorf("Apply() without secret: got %v", err)
+	}
+	if len(api.writes) != 0 {
+		t.Errorf("Apply() without secret made changes: %v", api.writes)
+	}
+
+	t.Setenv("POLICY_TEST_SECRET", "s3cret")
+	if err := client.Apply(ctx, "o/r", policy, drift); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(api.writes) != 6 || !slices.Contains(api.writes, "PATCH /repos/o/r/hooks/2") {
+		t.Errorf("writes = %v", api.writes)
+	}
+	if created := api.hooks[2]["config"].(map[string]interface{}); created["secret"] != "s3cret" {
+		t.Errorf("created hook config = %v", created)
+	}
+
+	drift, err = client.Check(ctx, "o/r", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drift) != 0 {
+		t.Errorf("drift after apply = %v", drift)
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"reviews.yaml": "branches:\n  main:\n    required_reviews: 7\n",
+		"color.yaml":   "labels:\n  - name: bug\n    color: '#d73a4a'\n",
+		"hook.yaml":    "webhooks:\n  - events: [push]\n",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(path); err == nil {
+			t.Errorf("LoadPolicy(%s): expected error", name)
+		}
+	}
+}
+
+func TestNewDrift(t *testing.T) {
+	a := PolicyDrift{Repo: "o/r", Kind: DriftLabel, Target: "bug", Field: "color"}
+	b := PolicyDrift{Repo: "o/r", Kind: DriftMerge, Target: "allow_merge_commit"}
+	dir := t.TempDir()
+
+	previous, err := LoadPolicyReport(dir)
+	if err != nil || previous != nil {
+		t.Fatalf("LoadPolicyReport() on empty dir = %v, %v", previous, err)
+	}
+	if fresh := NewDrift(previous, []PolicyDrift{a}); len(fresh) != 1 {
+		t.Errorf("first run new drift = %v", fresh)
+	}
+
+	if err := SavePolicyReport(&PolicyReport{Repos: []string{"o/r"}, Drift: []PolicyDrift{a}}, dir); err != nil {
+		t.Fatal(err)
+	}
+	previous, err = LoadPolicyReport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh := NewDrift(previous, []PolicyDrift{a, b}); len(fresh) != 1 || fresh[0].Key() != b.Key() {
+		t.Errorf("second run new drift = %v", fresh)
+	}
+
+	// Drift that comes back after being applied is new again
+	previous.Applied = true
+	if fresh := NewDrift(previous, []PolicyDrift{a}); len(fresh) != 1 {
+		t.Errorf("after apply new drift = %v", fresh)
+	}
+}
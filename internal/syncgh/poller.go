@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/google/go-github/v81/github"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
 )
 
 // RepoConfig holds configuration for checking a repository
@@ -24,19 +26,19 @@ type Poller struct {
 	interval time.Duration
 	repos    []RepoConfig
 	onUpdate func(subsystem, oldVersion, newVersion string) // callback on update
+	onError  func(subsystem string, err error)              // callback on failed check
+	onCycle  func()                                         // callback after each polling cycle
 }
 
 // NewPoller creates a new poller with specified interval.
-// If token is provided, it will be used for authenticated requests (5000/hour vs 60/hour).
-func NewPoller(interval time.Duration, repos []RepoConfig, token string) *Poller {
-	var client *github.Client
-	if token != "" {
-		client = github.NewClient(nil).WithAuthToken(token)
+// If auth yields a token, it is used for authenticated requests (5000/hour vs 60/hour).
+func NewPoller(interval time.Duration, repos []RepoConfig, auth ghauth.TokenSource) *Poller {
+	if token, _ := tokenOf(auth); token != "" {
 		log.Printf("sync-gh: Using authenticated GitHub API (5000 req/hour)")
 	} else {
-		client = github.NewClient(nil)
 		log.Printf("sync-gh: Using unauthenticated GitHub API (60 req/hour). Set GITHUB_TOKEN for higher limits.")
 	}
+	client := newGitHubClient(auth)
 
 	return &Poller{
 		client:   client,
@@ -50,6 +52,16 @@ func (p *Poller) OnUpdate(callback func(subsystem, oldVersion, newVersion string
 	p.onUpdate = callback
 }
 
+// OnError sets the callback for when checking a repo fails
+func (p *Poller) OnError(callback func(subsystem string, err error)) {
+	p.onError = callback
+}
+
+// OnCycle sets the callback run after every polling cycle
+func (p *Poller) OnCycle(callback func()) {
+	p.onCycle = callback
+}
+
 // Start begins the polling loop (blocking)
 func (p *Poller) Start() error {
 	log.Printf("sync-gh: Starting poller (interval: %v)", p.interval)
@@ -84,9 +96,16 @@ func (p *Poller) checkAll() {
 	for _, config := range p.repos {
 		if err := p.checkRepo(config); err != nil {
 			log.Printf("sync-gh: Failed to check %s: %v", config.Subsystem, err)
+			if p.onError != nil {
+				p.onError(config.Subsystem, err)
+			}
 		}
 	}
 	log.Printf("sync-gh: Polling cycle complete")
+
+	if p.onCycle != nil {
+		p.onCycle()
+	}
 }
 
 // checkRepo checks a single repository for updates
@@ -193,3 +212,20 @@ func GetLatestRelease(owner, repo, token string) (string, error) {
 
 	return release.GetTagName(), nil
 }
+
+// newGitHubClient returns an API client that asks auth for the token on
+// every request, so a GitHub App token is re-minted before it expires.
+func newGitHubClient(auth ghauth.TokenSource) *github.Client {
+	if auth == nil {
+		return github.NewClient(nil)
+	}
+	return github.NewClient(ghauth.NewHTTPClient(auth))
+}
+
+// tokenOf returns auth's current token, "" when auth is nil.
+func tokenOf(auth ghauth.TokenSource) (string, error) {
+	if auth == nil {
+		return "", nil
+	}
+	return auth.Token(context.Background())
+}
@@ -2,13 +2,15 @@
 package syncgh
 
 import (
-	"encoding/json"
-	"os"
-	"path/filepath"
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"sync"
 	"time"
 
-	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/ghauth"
+	"github.com/joeblew999/xplat/internal/statestore"
 )
 
 // PollState tracks commit hashes for polling comparison.
@@ -19,6 +21,9 @@ type PollState struct {
 
 	// UpdatedAt is when the state was last saved
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Interval is the poll interval of the poller that saved the state
+	Interval time.Duration `json:"interval,omitempty"`
 }
 
 // RepoCommitState holds the last known commit for a repo+ref
@@ -31,26 +36,43 @@ type RepoCommitState struct {
 
 	// LastChecked is when this repo was last polled
 	LastChecked time.Time `json:"last_checked"`
+
+	// LastChanged is when the commit hash last changed
+	LastChanged time.Time `json:"last_changed,omitempty"`
+
+	// LastError is the error from the last poll, empty if it succeeded
+	LastError string `json:"last_error,omitempty"`
 }
 
 // pollStateFile is the filename for poll state persistence
 const pollStateFile = "syncgh-poll-state.json"
 
-// pollStateMutex protects concurrent access to the state file
+// pollStateVersion is the PollState schema version (see statestore)
+const pollStateVersion = 1
+
+// pollStateMutex protects concurrent access to the state store
 var pollStateMutex sync.Mutex
 
-// LoadPollState loads the poll state from disk.
-// Returns empty state if file doesn't exist.
+// LoadPollState loads the poll state from the default store (see DefaultStateStore).
+// Returns empty state if nothing has been saved yet.
 func LoadPollState() (*PollState, error) {
+	store, err := DefaultStateStore()
+	if err != nil {
+		return nil, err
+	}
+	return LoadPollStateFrom(store)
+}
+
+// LoadPollStateFrom loads the poll state from the given store.
+// Returns empty state if nothing has been saved yet.
+func LoadPollStateFrom(store StateStore) (*PollState, error) {
 	pollStateMutex.Lock()
 	defer pollStateMutex.Unlock()
 
-	statePath := filepath.Join(config.XplatCache(), pollStateFile)
-
-	data, err := os.ReadFile(statePath)
+	data, err := store.Read(pollStateFile)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Return empty state if file doesn't exist
+		if errors.Is(err, ErrStateNotFound) {
+			// Return empty state if nothing saved yet
 			return &PollState{
 				Repos: make(map[string]RepoCommitState),
 			}, nil
@@ -59,8 +81,8 @@ func LoadPollState() (*PollState, error) {
 	}
 
 	var state PollState
-	if err := json.Unmarshal(data, &state); err != nil {
-		return nil, err
+	if err := statestore.Unmarshal(data, &state, pollStateVersion, nil); err != nil {
+		return nil, fmt.Errorf("failed to parse poll state from %s: %w", store, err)
 	}
 
 	if state.Repos == nil {
@@ -70,25 +92,28 @@ func LoadPollState() (*PollState, error) {
 	return &state, nil
 }
 
-// SavePollState saves the poll state to disk.
+// SavePollState saves the poll state to the default store (see DefaultStateStore).
 func SavePollState(state *PollState) error {
-	pollStateMutex.Lock()
-	defer pollStateMutex.Unlock()
-
-	cacheDir := config.XplatCache()
-	if err := os.MkdirAll(cacheDir, config.DefaultDirPerms); err != nil {
+	store, err := DefaultStateStore()
+	if err != nil {
 		return err
 	}
+	return SavePollStateTo(store, state)
+}
+
+// SavePollStateTo saves the poll state to the given store.
+func SavePollStateTo(store StateStore, state *PollState) error {
+	pollStateMutex.Lock()
+	defer pollStateMutex.Unlock()
 
 	state.UpdatedAt = time.Now().UTC()
 
-	data, err := json.MarshalIndent(state, "", "  ")
+	data, err := statestore.Marshal(state, pollStateVersion)
 	if err != nil {
 		return err
 	}
 
-	statePath := filepath.Join(cacheDir, pollStateFile)
-	return os.WriteFile(statePath, data, config.DefaultFilePerms)
+	return store.Write(pollStateFile, data)
 }
 
 // GetRepoHash returns the last known commit hash for a repo.
@@ -101,14 +126,31 @@ func (s *PollState) GetRepoHash(repo, ref string) string {
 	return ""
 }
 
-// SetRepoHash updates the commit hash for a repo.
+// SetRepoHash records a successful poll of a repo.
 func (s *PollState) SetRepoHash(repo, ref, hash string) {
 	key := makeRepoKey(repo, ref)
-	s.Repos[key] = RepoCommitState{
-		Ref:         ref,
-		CommitHash:  hash,
-		LastChecked: time.Now().UTC(),
+	now := time.Now().UTC()
+
+	info := s.Repos[key]
+	if info.CommitHash != hash {
+		info.LastChanged = now
 	}
+	info.Ref = ref
+	info.CommitHash = hash
+	info.LastChecked = now
+	info.LastError = ""
+	s.Repos[key] = info
+}
+
+// SetRepoError records a failed poll of a repo, keeping its last known hash.
+func (s *PollState) SetRepoError(repo, ref string, err error) {
+	key := makeRepoKey(repo, ref)
+
+	info := s.Repos[key]
+	info.Ref = ref
+	info.LastChecked = time.Now().UTC()
+	info.LastError = err.Error()
+	s.Repos[key] = info
 }
 
 // HasChanged returns true if the new hash differs from stored hash.
@@ -130,66 +172,139 @@ func makeRepoKey(repo, ref string) string {
 // It tracks commit hashes between polls and only triggers callbacks on actual changes.
 type StatefulPoller struct {
 	*Poller
+	store    StateStore
+	mu       sync.Mutex // guards state while polling and serving status
 	state    *PollState
-	onChange func(repo, ref, oldHash, newHash string)
+	filter   *EventFilter
+	onChange func(Change)
+}
+
+// NewStatefulPoller creates a poller that tracks state in the default store.
+func NewStatefulPoller(interval time.Duration, repos []RepoConfig, auth ghauth.TokenSource) (*StatefulPoller, error) {
+	store, err := DefaultStateStore()
+	if err != nil {
+		return nil, err
+	}
+	return NewStatefulPollerWithStore(interval, repos, auth, store)
 }
 
-// NewStatefulPoller creates a poller that tracks state.
-func NewStatefulPoller(interval time.Duration, repos []RepoConfig, token string) (*StatefulPoller, error) {
-	state, err := LoadPollState()
+// NewStatefulPollerWithStore creates a poller that tracks state in the given store.
+// Use a shared store (R2, NATS KV) when pollers run on multiple machines.
+func NewStatefulPollerWithStore(interval time.Duration, repos []RepoConfig, auth ghauth.TokenSource, store StateStore) (*StatefulPoller, error) {
+	state, err := LoadPollStateFrom(store)
 	if err != nil {
 		return nil, err
 	}
+	state.Interval = interval
 
 	sp := &StatefulPoller{
-		Poller: NewPoller(interval, repos, token),
+		Poller: NewPoller(interval, repos, auth),
+		store:  store,
 		state:  state,
 	}
 
 	// Wire up the internal callback to check state
 	sp.Poller.OnUpdate(func(subsystem, _, newHash string) {
-		// Determine ref from config
-		ref := "main"
-		for _, r := range repos {
-			if r.Subsystem == subsystem {
-				if r.UseTag {
-					ref = r.Tag
-				} else if r.Branch != "" {
-					ref = r.Branch
-				}
-				break
-			}
-		}
+		ref := repoRef(repos, subsystem)
 
-		// Check if actually changed
-		if sp.state.HasChanged(subsystem, ref, newHash) {
-			oldHash := sp.state.GetRepoHash(subsystem, ref)
+		sp.mu.Lock()
+		changed := sp.state.HasChanged(subsystem, ref, newHash)
+		oldHash := sp.state.GetRepoHash(subsystem, ref)
+		// Always record the poll so freshness can be checked
+		sp.state.SetRepoHash(subsystem, ref, newHash)
+		sp.mu.Unlock()
 
-			// Update state
-			sp.state.SetRepoHash(subsystem, ref, newHash)
+		if !changed {
+			return
+		}
 
-			// Save state
-			if err := SavePollState(sp.state); err != nil {
-				// Log but don't fail
-				println("syncgh: Failed to save poll state:", err.Error())
-			}
+		// Save right away so the change survives a crash before the cycle ends
+		sp.save()
 
-			// Trigger callback if set
-			if sp.onChange != nil {
-				sp.onChange(subsystem, ref, oldHash, newHash)
+		c := Change{Repo: subsystem, Ref: ref, OldHash: oldHash, NewHash: newHash}
+		if oldHash != "" && (sp.onChange != nil || sp.filter.NeedsDetails(subsystem)) {
+			if err := sp.summarize(context.Background(), &c); err != nil {
+				log.Printf("syncgh: %v (change not summarized or filtered)", err)
 			}
 		}
+
+		if ok, reason := sp.filter.Match(c.event(repoIsTag(repos, subsystem))); !ok {
+			log.Printf("syncgh: Change in %s@%s filtered out: %s", subsystem, ref, reason)
+			return
+		}
+
+		// Trigger callback if set
+		if sp.onChange != nil {
+			sp.onChange(c)
+		}
+	})
+
+	sp.Poller.OnError(func(subsystem string, err error) {
+		sp.mu.Lock()
+		sp.state.SetRepoError(subsystem, repoRef(repos, subsystem), err)
+		sp.mu.Unlock()
 	})
 
+	// Persist check times and errors once per cycle
+	sp.Poller.OnCycle(sp.save)
+
 	return sp, nil
 }
 
+// save persists the poll state, logging failures.
+func (sp *StatefulPoller) save() {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	if err := SavePollStateTo(sp.store, sp.state); err != nil {
+		// Log but don't fail
+		log.Printf("syncgh: Failed to save poll state to %s: %v", sp.store, err)
+	}
+}
+
+// repoRef returns the branch or tag tracked for a repo.
+func repoRef(repos []RepoConfig, subsystem string) string {
+	for _, r := range repos {
+		if r.Subsystem == subsystem {
+			if r.UseTag {
+				return r.Tag
+			} else if r.Branch != "" {
+				return r.Branch
+			}
+			break
+		}
+	}
+	return "main"
+}
+
 // OnChange sets the callback for when a repo actually changes.
 // Unlike OnUpdate, this is only called when the commit hash differs from previous poll.
-func (sp *StatefulPoller) OnChange(callback func(repo, ref, oldHash, newHash string)) {
+// Each change costs one compare API call for its summary (see Change).
+func (sp *StatefulPoller) OnChange(callback func(Change)) {
 	sp.onChange = callback
 }
 
+// SetFilter limits which changes reach the OnChange callback. Filters on
+// paths or actors cost one compare API call per change.
+func (sp *StatefulPoller) SetFilter(filter *EventFilter) {
+	sp.filter = filter
+}
+
+// repoIsTag reports whether a repo is tracked by tag rather than branch.
+func repoIsTag(repos []RepoConfig, subsystem string) bool {
+	for _, r := range repos {
+		if r.Subsystem == subsystem {
+			return r.UseTag
+		}
+	}
+	return false
+}
+
+// Store returns the backend the poller persists state to
+func (sp *StatefulPoller) Store() StateStore {
+	return sp.store
+}
+
 // State returns the current poll state (for inspection)
 func (sp *StatefulPoller) State() *PollState {
 	return sp.state
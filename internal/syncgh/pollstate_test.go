@@ -101,7 +101,7 @@ func TestTaskCacheInvalidatorCallback(t *testing.T) {
 	callback := TaskCacheInvalidator(tmpDir)
 
 	// Simulate a change detection
-	callback("owner/repo", "main", "old123", "new456")
+	callback(Change{Repo: "owner/repo", Ref: "main", OldHash: "old123", NewHash: "new456"})
 
 	// Verify cache is gone
 	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
@@ -110,3 +110,57 @@ func TestTaskCacheInvalidatorCallback(t *testing.T) {
 
 	t.Log("✓ TaskCacheInvalidator callback works")
 }
+
+func TestParseStateStore(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected string
+		wantErr  bool
+	}{
+		{"file:/tmp/state", "file:/tmp/state", false},
+		{"r2:r2:xplat-state/syncgh", "r2:r2:xplat-state/syncgh", false},
+		{"nats:xplat-state", "nats:xplat-state", false},
+		{"r2", "", true},
+		{"nats:", "", true},
+		{"redis:foo", "", true},
+	}
+
+	for _, tt := range tests {
+		store, err := ParseStateStore(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseStateStore(%q) expected error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseStateStore(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		if store.String() != tt.expected {
+			t.Errorf("ParseStateStore(%q) = %s, want %s", tt.spec, store, tt.expected)
+		}
+	}
+}
+
+func TestPollStateWithFileStore(t *testing.T) {
+	store := &FileStateStore{Dir: filepath.Join(t.TempDir(), "shared")}
+
+	state, err := LoadPollStateFrom(store)
+	if err != nil {
+		t.Fatalf("LoadPollStateFrom failed: %v", err)
+	}
+	state.SetRepoHash("owner/repo", "main", "abc12345")
+
+	if err := SavePollStateTo(store, state); err != nil {
+		t.Fatalf("SavePollStateTo failed: %v", err)
+	}
+
+	loaded, err := LoadPollStateFrom(store)
+	if err != nil {
+		t.Fatalf("LoadPollStateFrom after save failed: %v", err)
+	}
+	if got := loaded.GetRepoHash("owner/repo", "main"); got != "abc12345" {
+		t.Errorf("GetRepoHash = %q, want abc12345", got)
+	}
+}
@@ -0,0 +1,154 @@
+package syncgh
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultPollInterval is assumed for state saved without an interval.
+const defaultPollInterval = 5 * time.Minute
+
+// RepoPollStatus is the structured status of one polled repo.
+type RepoPollStatus struct {
+	Repo        string    `json:"repo"`
+	Ref         string    `json:"ref"`
+	CommitHash  string    `json:"commit_hash"`
+	LastChecked time.Time `json:"last_checked"`
+	LastChanged time.Time `json:"last_changed,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextPoll    time.Time `json:"next_poll"`
+
+	// Stale is true when the repo hasn't been polled for two intervals,
+	// meaning the poller has stopped or is stuck
+	Stale bool `json:"stale"`
+}
+
+// Healthy returns true if the repo was polled recently and without error.
+func (s RepoPollStatus) Healthy() bool {
+	return !s.Stale && s.LastError == ""
+}
+
+// Status returns the status of every tracked repo, sorted by repo and ref.
+func (s *PollState) Status(now time.Time) []RepoPollStatus {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	statuses := make([]RepoPollStatus, 0, len(s.Repos))
+	for key, info := range s.Repos {
+		repo := key
+		if i := strings.LastIndex(key, "@"); i >= 0 {
+			repo = key[:i]
+		}
+		statuses = append(statuses, RepoPollStatus{
+			Repo:        repo,
+			Ref:         info.Ref,
+			CommitHash:  info.CommitHash,
+			LastChecked: info.LastChecked,
+			LastChanged: info.LastChanged,
+			LastError:   info.LastError,
+			NextPoll:    info.LastChecked.Add(interval),
+			Stale:       now.Sub(info.LastChecked) > 2*interval,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].Repo != statuses[j].Repo {
+			return statuses[i].Repo < statuses[j].Repo
+		}
+		return statuses[i].Ref < statuses[j].Ref
+	})
+	return statuses
+}
+
+// Status returns the current status of every polled repo.
+func (sp *StatefulPoller) Status() []RepoPollStatus {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return sp.state.Status(time.Now())
+}
+
+// WritePollMetrics writes repo statuses in the Prometheus text format.
+func WritePollMetrics(w io.Writer, statuses []RepoPollStatus) {
+	gauge := func(name, help string, value func(RepoPollStatus) int64) {
+		_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, s := range statuses {
+			_, _ = fmt.Fprintf(w, "%s{repo=%q,ref=%q} %d\n", name, s.Repo, s.Ref, value(s))
+		}
+	}
+
+	gauge("xplat_syncgh_poll_last_checked_timestamp_seconds", "Unix time the repo was last polled.",
+		func(s RepoPollStatus) int64 { return s.LastChecked.Unix() })
+	gauge("xplat_syncgh_poll_last_changed_timestamp_seconds", "Unix time the repo's commit hash last changed.",
+		func(s RepoPollStatus) int64 {
+			if s.LastChanged.IsZero() {
+				return 0
+			}
+			return s.LastChanged.Unix()
+		})
+	gauge("xplat_syncgh_poll_error", "1 if the last poll of the repo failed.",
+		func(s RepoPollStatus) int64 { return boolMetric(s.LastError != "") })
+	gauge("xplat_syncgh_poll_stale", "1 if the repo has not been polled for two intervals.",
+		func(s RepoPollStatus) int64 { return boolMetric(s.Stale) })
+}
+
+func boolMetric(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// PollHealthHandler serves poller health for process-compose probes and scrapers:
+//
+//	GET /healthz  200 if every repo is healthy, 503 otherwise
+//	GET /metrics  Prometheus metrics
+//	GET /status   JSON repo statuses
+func PollHealthHandler(status func() []RepoPollStatus) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		statuses := status()
+		for _, s := range statuses {
+			if !s.Healthy() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = fmt.Fprintf(w, "unhealthy: %s@%s\n", s.Repo, s.Ref)
+				return
+			}
+		}
+		_, _ = fmt.Fprintf(w, "ok (%d repos)\n", len(statuses))
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePollMetrics(w, status())
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status())
+	})
+	return mux
+}
+
+// StartHealthServer serves PollHealthHandler on port in the background.
+func (sp *StatefulPoller) StartHealthServer(port int) {
+	addr := fmt.Sprintf(":%d", port)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           PollHealthHandler(sp.Status),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	log.Printf("sync-gh: Health server listening on %s (/healthz, /metrics, /status)", addr)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("sync-gh: Health server error: %v", err)
+		}
+	}()
+}
@@ -0,0 +1,90 @@
+package syncgh
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollStateStatus(t *testing.T) {
+	state := &PollState{Repos: make(map[string]RepoCommitState), Interval: 5 * time.Minute}
+
+	state.SetRepoHash("owner/fresh", "main", "aaaa1111")
+	changed := state.Repos["owner/fresh@main"].LastChanged
+	state.SetRepoHash("owner/fresh", "main", "aaaa1111")
+	if !state.Repos["owner/fresh@main"].LastChanged.Equal(changed) {
+		t.Error("LastChanged moved without a hash change")
+	}
+
+	state.SetRepoHash("owner/failing", "v1.0.0", "bbbb2222")
+	state.SetRepoError("owner/failing", "v1.0.0", errors.New("rate limited"))
+	if got := state.GetRepoHash("owner/failing", "v1.0.0"); got != "bbbb2222" {
+		t.Errorf("SetRepoError dropped hash, got %q", got)
+	}
+
+	state.Repos["owner/stale@main"] = RepoCommitState{
+		Ref:         "main",
+		CommitHash:  "cccc3333",
+		LastChecked: time.Now().Add(-time.Hour),
+	}
+
+	statuses := state.Status(time.Now())
+	if len(statuses) != 3 {
+		t.Fatalf("got %d statuses, want 3", len(statuses))
+	}
+
+	want := map[string]struct{ stale, healthy bool }{
+		"owner/failing": {false, false},
+		"owner/fresh":   {false, true},
+		"owner/stale":   {true, false},
+	}
+	for _, s := range statuses {
+		w := want[s.Repo]
+		if s.Stale != w.stale || s.Healthy() != w.healthy {
+			t.Errorf("%s: stale=%v healthy=%v, want %v %v", s.Repo, s.Stale, s.Healthy(), w.stale, w.healthy)
+		}
+		if !s.NextPoll.Equal(s.LastChecked.Add(5 * time.Minute)) {
+			t.Errorf("%s: NextPoll = %s, want LastChecked+interval", s.Repo, s.NextPoll)
+		}
+	}
+	if statuses[0].Repo != "owner/failing" || statuses[0].Ref != "v1.0.0" {
+		t.Errorf("statuses not sorted or ref not split: %+v", statuses[0])
+	}
+}
+
+func TestPollHealthHandler(t *testing.T) {
+	healthy := []RepoPollStatus{{Repo: "owner/repo", Ref: "main", LastChecked: time.Unix(1700000000, 0)}}
+	failing := []RepoPollStatus{{Repo: "owner/repo", Ref: "main", LastError: "boom"}}
+
+	tests := []struct {
+		name     string
+		statuses []RepoPollStatus
+		path     string
+		want     int
+		contains string
+	}{
+		{"healthy", healthy, "/healthz", http.StatusOK, "ok (1 repos)"},
+		{"failing", failing, "/healthz", http.StatusServiceUnavailable, "owner/repo@main"},
+		{"metrics", healthy, "/metrics", http.StatusOK, `xplat_syncgh_poll_last_checked_timestamp_seconds{repo="owner/repo",ref="main"} 1700000000`},
+		{"metrics error", failing, "/metrics", http.StatusOK, `xplat_syncgh_poll_error{repo="owner/repo",ref="main"} 1`},
+		{"status", healthy, "/status", http.StatusOK, `"repo":"owner/repo"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := PollHealthHandler(func() []RepoPollStatus { return tt.statuses })
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body %q does not contain %q", rec.Body.String(), tt.contains)
+			}
+		})
+	}
+}
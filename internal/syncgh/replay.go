@@ -7,6 +7,7 @@ package syncgh
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -17,6 +18,8 @@ import (
 	"time"
 
 	"github.com/google/go-github/v81/github"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
 )
 
 // ReplayConfig holds configuration for webhook replay.
@@ -42,11 +45,22 @@ type ReplayConfig struct {
 	// IgnoreEvents skips these event types
 	IgnoreEvents []string
 
+	// Events replays only these event types (e.g. push, release, workflow_run).
+	// Empty means all events.
+	Events []string
+
+	// FailedOnly replays only deliveries that did not get a 2xx response
+	FailedOnly bool
+
+	// Redeliver asks GitHub to redeliver to the hook's configured URL
+	// instead of POSTing the payload to TargetURL locally
+	Redeliver bool
+
 	// Continuous keeps polling for new deliveries
 	Continuous bool
 
-	// Token is the GitHub token for API access
-	Token string
+	// Token supplies the GitHub token for API access (nil for none)
+	Token ghauth.TokenSource
 }
 
 // ReplayResult contains information about a replayed delivery.
@@ -56,25 +70,26 @@ type ReplayResult struct {
 	Event       string
 	DeliveredAt time.Time
 	StatusCode  int
+	Redelivered bool
 	Error       error
 }
 
+// maxReplayResults caps the results kept by a continuous replay so a
+// long-running session does not grow without bound.
+const maxReplayResults = 1000
+
 // Replayer handles webhook delivery replay from GitHub API.
 type Replayer struct {
-	config ReplayConfig
-	client *github.Client
+	config  ReplayConfig
+	client  *github.Client
+	results []ReplayResult
 }
 
 // NewReplayer creates a new webhook replayer.
 func NewReplayer(config ReplayConfig) *Replayer {
-	client := github.NewClient(nil)
-	if config.Token != "" {
-		client = client.WithAuthToken(config.Token)
-	}
-
 	return &Replayer{
 		config: config,
-		client: client,
+		client: newGitHubClient(config.Token),
 	}
 }
 
@@ -140,8 +155,8 @@ func (r *Replayer) Replay(ctx context.Context) error {
 	if r.config.HookID == 0 {
 		return fmt.Errorf("hook ID is required")
 	}
-	if r.config.TargetURL == "" {
-		return fmt.Errorf("target URL is required")
+	if r.config.TargetURL == "" && !r.config.Redeliver {
+		return fmt.Errorf("target URL is required (or use redeliver mode)")
 	}
 
 	sinceTime := r.config.Since
@@ -156,8 +171,18 @@ func (r *Replayer) Replay(ctx context.Context) error {
 	} else {
 		log.Printf("Replay: Organization: %s", r.config.Owner)
 	}
-	log.Printf("Replay: Target URL: %s", r.config.TargetURL)
+	if r.config.Redeliver {
+		log.Printf("Replay: Mode: GitHub redelivery")
+	} else {
+		log.Printf("Replay: Target URL: %s", r.config.TargetURL)
+	}
 	log.Printf("Replay: Since: %s", sinceTime.Format(time.RFC3339))
+	if len(r.config.Events) > 0 {
+		log.Printf("Replay: Events: %s", strings.Join(r.config.Events, ", "))
+	}
+	if r.config.FailedOnly {
+		log.Printf("Replay: Failed deliveries only")
+	}
 
 	for {
 		select {
@@ -180,12 +205,18 @@ func (r *Replayer) Replay(ctx context.Context) error {
 		filtered := r.filterDeliveries(deliveries, sinceTime)
 
 		for _, hd := range filtered {
-			result := r.replayDelivery(ctx, hd)
+			var result ReplayResult
+			if r.config.Redeliver {
+				result = r.redeliverDelivery(ctx, hd)
+			} else {
+				result = r.replayDelivery(ctx, hd)
+			}
 			if result.Error != nil {
 				log.Printf("Replay: Error replaying delivery %d: %v", result.DeliveryID, result.Error)
 			} else {
 				log.Printf("Replay: Replayed %s [%s] -> %d", result.Event, result.GUID, result.StatusCode)
 			}
+			r.addResult(result)
 
 			// Update since time
 			if hd.DeliveredAt != nil {
@@ -203,7 +234,23 @@ func (r *Replayer) Replay(ctx context.Context) error {
 	return nil
 }
 
+// Results returns the per-delivery results of the replay so far.
+// A continuous replay keeps only the most recent maxReplayResults.
+func (r *Replayer) Results() []ReplayResult {
+	return r.results
+}
+
+// addResult records a result, dropping the oldest once maxReplayResults is reached.
+func (r *Replayer) addResult(result ReplayResult) {
+	if len(r.results) >= maxReplayResults {
+		n := copy(r.results, r.results[len(r.results)-maxReplayResults+1:])
+		r.results = r.results[:n]
+	}
+	r.results = append(r.results, result)
+}
+
 // filterDeliveries filters and reverses deliveries (oldest first, after sinceTime).
+// Deliveries are also filtered by the configured event types and delivery status.
 func (r *Replayer) filterDeliveries(deliveries []*github.HookDelivery, sinceTime time.Time) []*github.HookDelivery {
 	var filtered []*github.HookDelivery
 
@@ -214,6 +261,9 @@ func (r *Replayer) filterDeliveries(deliveries []*github.HookDelivery, sinceTime
 		if d.DeliveredAt.Time.Before(sinceTime) {
 			break
 		}
+		if !r.matchesFilters(d) {
+			continue
+		}
 		filtered = append(filtered, d)
 	}
 
@@ -226,25 +276,88 @@ func (r *Replayer) filterDeliveries(deliveries []*github.HookDelivery, sinceTime
 	return filtered
 }
 
-// replayDelivery replays a single webhook delivery.
-func (r *Replayer) replayDelivery(ctx context.Context, hd *github.HookDelivery) ReplayResult {
-	result := ReplayResult{
+// matchesFilters reports whether a delivery passes the event and status filters.
+// Redeliveries are always skipped: they repeat an earlier delivery, and in
+// redeliver mode they are our own, so replaying them would loop.
+func (r *Replayer) matchesFilters(d *github.HookDelivery) bool {
+	if d.GetRedelivery() {
+		return false
+	}
+
+	event := d.GetEvent()
+
+	for _, ignore := range r.config.IgnoreEvents {
+		if strings.EqualFold(event, ignore) {
+			return false
+		}
+	}
+
+	if len(r.config.Events) > 0 {
+		matched := false
+		for _, want := range r.config.Events {
+			if strings.EqualFold(event, want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if r.config.FailedOnly && !deliveryFailed(d) {
+		return false
+	}
+
+	return true
+}
+
+// deliveryFailed reports whether GitHub recorded a non-2xx response for the delivery.
+// A missing status code (e.g. timeout) counts as failed.
+func deliveryFailed(d *github.HookDelivery) bool {
+	code := d.GetStatusCode()
+	return code < 200 || code >= 300
+}
+
+func newReplayResult(hd *github.HookDelivery) ReplayResult {
+	return ReplayResult{
 		DeliveryID:  hd.GetID(),
 		GUID:        hd.GetGUID(),
 		Event:       hd.GetEvent(),
 		DeliveredAt: hd.GetDeliveredAt().Time,
 	}
+}
 
-	// Check if event should be ignored
-	if len(r.config.IgnoreEvents) > 0 {
-		for _, ignore := range r.config.IgnoreEvents {
-			if strings.EqualFold(hd.GetEvent(), ignore) {
-				log.Printf("Replay: Skipping ignored event: %s", hd.GetEvent())
-				return result
-			}
-		}
+// redeliverDelivery asks GitHub to redeliver a delivery to the hook's configured URL.
+func (r *Replayer) redeliverDelivery(ctx context.Context, hd *github.HookDelivery) ReplayResult {
+	result := newReplayResult(hd)
+	result.Redelivered = true
+
+	var resp *github.Response
+	var err error
+	if r.config.Repo != "" {
+		_, resp, err = r.client.Repositories.RedeliverHookDelivery(ctx, r.config.Owner, r.config.Repo, r.config.HookID, hd.GetID())
+	} else {
+		_, resp, err = r.client.Organizations.RedeliverHookDelivery(ctx, r.config.Owner, r.config.HookID, hd.GetID())
+	}
+
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
 	}
 
+	// GitHub answers 202 Accepted, which go-github surfaces as an AcceptedError
+	var accepted *github.AcceptedError
+	if err != nil && !errors.As(err, &accepted) {
+		result.Error = fmt.Errorf("failed to redeliver: %w", err)
+	}
+
+	return result
+}
+
+// replayDelivery replays a single webhook delivery.
+func (r *Replayer) replayDelivery(ctx context.Context, hd *github.HookDelivery) ReplayResult {
+	result := newReplayResult(hd)
+
 	// Get full delivery details
 	delivery, err := r.GetDelivery(ctx, r.config.HookID, hd.GetID())
 	if err != nil {
@@ -396,8 +509,36 @@ func PrintDeliveries(deliveries []*github.HookDelivery) {
 	}
 }
 
+// PrintReplayResults prints a per-delivery report and a summary line.
+func PrintReplayResults(results []ReplayResult) {
+	if len(results) == 0 {
+		fmt.Println("No deliveries matched.")
+		return
+	}
+
+	fmt.Printf("%-12s %-15s %-8s %-25s %s\n", "ID", "Event", "Status", "Delivered At", "Result")
+	fmt.Println(strings.Repeat("-", 80))
+
+	failed := 0
+	for _, res := range results {
+		outcome := "ok"
+		if res.Redelivered {
+			outcome = "redelivered"
+		}
+		if res.Error != nil {
+			outcome = res.Error.Error()
+			failed++
+		}
+
+		fmt.Printf("%-12d %-15s %-8d %-25s %s\n",
+			res.DeliveryID, res.Event, res.StatusCode, res.DeliveredAt.Format(time.RFC3339), outcome)
+	}
+
+	fmt.Printf("\n%d replayed, %d failed\n", len(results)-failed, failed)
+}
+
 // RunReplayListHooks lists hooks for a repo or org.
-func RunReplayListHooks(owner, repo, token string) error {
+func RunReplayListHooks(owner, repo string, token ghauth.TokenSource) error {
 	replayer := NewReplayer(ReplayConfig{
 		Owner: owner,
 		Repo:  repo,
@@ -419,7 +560,7 @@ func RunReplayListHooks(owner, repo, token string) error {
 }
 
 // RunReplayListDeliveries lists deliveries for a hook.
-func RunReplayListDeliveries(owner, repo string, hookID int64, token string) error {
+func RunReplayListDeliveries(owner, repo string, hookID int64, token ghauth.TokenSource) error {
 	replayer := NewReplayer(ReplayConfig{
 		Owner:  owner,
 		Repo:   repo,
@@ -441,8 +582,14 @@ func RunReplayListDeliveries(owner, repo string, hookID int64, token string) err
 	return nil
 }
 
-// RunReplay replays webhook deliveries.
+// RunReplay replays webhook deliveries and prints a per-delivery report.
 func RunReplay(config ReplayConfig) error {
 	replayer := NewReplayer(config)
-	return replayer.Replay(context.Background())
+	err := replayer.Replay(context.Background())
+
+	if !config.Continuous {
+		PrintReplayResults(replayer.Results())
+	}
+
+	return err
 }
@@ -0,0 +1,79 @@
+package syncgh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func TestReplayerFilterDeliveries(t *testing.T) {
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	delivery := func(id int64, event string, status int, at time.Time) *github.HookDelivery {
+		return &github.HookDelivery{
+			ID:          github.Ptr(id),
+			Event:       github.Ptr(event),
+			StatusCode:  github.Ptr(status),
+			DeliveredAt: &github.Timestamp{Time: at},
+		}
+	}
+
+	redelivery := delivery(6, "push", 500, base.Add(5*time.Minute))
+	redelivery.Redelivery = github.Ptr(true)
+
+	// GitHub returns newest first; redeliveries are never replayed
+	deliveries := []*github.HookDelivery{
+		redelivery,
+		delivery(5, "workflow_run", 200, base.Add(4*time.Minute)),
+		delivery(4, "push", 500, base.Add(3*time.Minute)),
+		delivery(3, "release", 200, base.Add(2*time.Minute)),
+		delivery(2, "push", 200, base.Add(1*time.Minute)),
+		delivery(1, "push", 502, base.Add(-1*time.Minute)), // before since
+	}
+
+	tests := []struct {
+		name     string
+		config   ReplayConfig
+		expected []int64
+	}{
+		{"all since", ReplayConfig{}, []int64{2, 3, 4, 5}},
+		{"events", ReplayConfig{Events: []string{"push", "Release"}}, []int64{2, 3, 4}},
+		{"failed only", ReplayConfig{FailedOnly: true}, []int64{4}},
+		{"failed push", ReplayConfig{Events: []string{"push"}, FailedOnly: true}, []int64{4}},
+		{"ignore", ReplayConfig{IgnoreEvents: []string{"push"}}, []int64{3, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewReplayer(tt.config)
+			filtered := r.filterDeliveries(deliveries, base)
+
+			if len(filtered) != len(tt.expected) {
+				t.Fatalf("got %d deliveries, want %v", len(filtered), tt.expected)
+			}
+			for i, id := range tt.expected {
+				if filtered[i].GetID() != id {
+					t.Errorf("filtered[%d] = %d, want %d", i, filtered[i].GetID(), id)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayerResultsCapped(t *testing.T) {
+	r := NewReplayer(ReplayConfig{Continuous: true})
+	for i := 1; i <= maxReplayResults+10; i++ {
+		r.addResult(ReplayResult{DeliveryID: int64(i)})
+	}
+
+	results := r.Results()
+	if len(results) != maxReplayResults {
+		t.Fatalf("got %d results, want %d", len(results), maxReplayResults)
+	}
+	if first := results[0].DeliveryID; first != 11 {
+		t.Errorf("oldest kept result = %d, want 11", first)
+	}
+	if last := results[len(results)-1].DeliveryID; last != maxReplayResults+10 {
+		t.Errorf("newest result = %d, want %d", last, maxReplayResults+10)
+	}
+}
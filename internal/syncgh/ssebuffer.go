@@ -0,0 +1,240 @@
+package syncgh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// maxRedeliverDelay caps the backoff between redelivery attempts.
+const maxRedeliverDelay = 60 * time.Second
+
+// deliveryBuffer persists events the target has not accepted yet, so they
+// survive target outages and client restarts. Each event is one file named
+// by sequence number, and events are delivered in sequence order.
+type deliveryBuffer struct {
+	dir string
+
+	mu   sync.Mutex
+	next uint64
+}
+
+// bufferedEvent is an event waiting in the buffer.
+type bufferedEvent struct {
+	Seq         uint64            `json:"seq"`
+	EventType   string            `json:"event_type,omitempty"`
+	DeliveryID  string            `json:"delivery_id,omitempty"`
+	Headers     map[string]string `json:"headers"`
+	Body        []byte            `json:"body"`
+	Timestamp   time.Time         `json:"timestamp"`
+	ContentType string            `json:"content_type,omitempty"`
+}
+
+func (e *bufferedEvent) message() *sseMessage {
+	return &sseMessage{
+		Headers:     e.Headers,
+		Body:        e.Body,
+		EventType:   e.EventType,
+		DeliveryID:  e.DeliveryID,
+		ContentType: e.ContentType,
+		Timestamp:   e.Timestamp,
+	}
+}
+
+// openDeliveryBuffer opens the buffer in dir, continuing the sequence of
+// any events left from a previous run.
+func openDeliveryBuffer(dir string) (*deliveryBuffer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create buffer directory: %w", err)
+	}
+	b := &deliveryBuffer{dir: dir, next: 1}
+	seqs, err := b.seqs()
+	if err != nil {
+		return nil, err
+	}
+	if len(seqs) > 0 {
+		b.next = seqs[len(seqs)-1] + 1
+	}
+	return b, nil
+}
+
+func (b *deliveryBuffer) path(seq uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d.json", seq))
+}
+
+// seqs returns the sequence numbers of the buffered events, oldest first.
+func (b *deliveryBuffer) seqs() ([]uint64, error) {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read buffer: %w", err)
+	}
+	var seqs []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		if seq, err := strconv.ParseUint(name, 10, 64); err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+// add writes an event to the buffer and returns its sequence number.
+func (b *deliveryBuffer) add(msg *sseMessage) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := bufferedEvent{
+		Seq:         b.next,
+		EventType:   msg.EventType,
+		DeliveryID:  msg.DeliveryID,
+		Headers:     msg.Headers,
+		Body:        msg.Body,
+		Timestamp:   msg.Timestamp,
+		ContentType: msg.ContentType,
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+
+	// Atomic, so a crash never leaves a partial event
+	if err := statestore.WriteFile(b.path(e.Seq), data, 0o644); err != nil {
+		return 0, fmt.Errorf("failed to buffer event: %w", err)
+	}
+	b.next++
+	return e.Seq, nil
+}
+
+// load reads a buffered event.
+func (b *deliveryBuffer) load(seq uint64) (*bufferedEvent, error) {
+	data, err := os.ReadFile(b.path(seq))
+	if err != nil {
+		return nil, err
+	}
+	var e bufferedEvent
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to parse buffered event %d: %w", seq, err)
+	}
+	return &e, nil
+}
+
+// remove deletes a delivered event.
+func (b *deliveryBuffer) remove(seq uint64) error {
+	if err := os.Remove(b.path(seq)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// reject moves an event the target will never accept to rejected/, so it
+// stops blocking the events behind it but can still be inspected.
+func (b *deliveryBuffer) reject(seq uint64) error {
+	dir := filepath.Join(b.dir, "rejected")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.Rename(b.path(seq), filepath.Join(dir, filepath.Base(b.path(seq))))
+}
+
+// len returns the number of buffered events.
+func (b *deliveryBuffer) len() int {
+	seqs, _ := b.seqs()
+	return len(seqs)
+}
+
+// targetStatusError is an error response from the target.
+type targetStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *targetStatusError) Error() string {
+	return fmt.Sprintf("target returned error: %d %s", e.StatusCode, e.Body)
+}
+
+// permanent reports whether retrying cannot help: a 4xx other than
+// timeout and rate limiting means the target rejected the event itself.
+func (e *targetStatusError) permanent() bool {
+	return e.StatusCode >= 400 && e.StatusCode < 500 &&
+		e.StatusCode != 408 && e.StatusCode != 429
+}
+
+// flushBuffer delivers buffered events in order, stopping at the first
+// event the target does not accept so later events never overtake it.
+// It returns the number of events still buffered.
+func (c *SSEClient) flushBuffer() (int, error) {
+	c.deliverMu.Lock()
+	defer c.deliverMu.Unlock()
+
+	seqs, err := c.buffer.seqs()
+	if err != nil {
+		return 0, err
+	}
+	for i, seq := range seqs {
+		e, err := c.buffer.load(seq)
+		if err != nil {
+			log.Printf("SSE: Dropping unreadable buffered event %d: %v", seq, err)
+			_ = c.buffer.reject(seq)
+			continue
+		}
+
+		err = c.forwardToTarget(e.message())
+		var statusErr *targetStatusError
+		switch {
+		case err == nil:
+			log.Printf("SSE: Delivered %s event [%s] (seq %d)", e.EventType, e.DeliveryID, seq)
+			if err := c.buffer.remove(seq); err != nil {
+				return len(seqs) - i, err
+			}
+		case errors.As(err, &statusErr) && statusErr.permanent():
+			log.Printf("SSE: Target rejected %s event [%s] (seq %d): %v", e.EventType, e.DeliveryID, seq, err)
+			if err := c.buffer.reject(seq); err != nil {
+				return len(seqs) - i, err
+			}
+		default:
+			return len(seqs) - i, err
+		}
+	}
+	return 0, nil
+}
+
+// redeliverLoop retries buffered events with exponential backoff until the
+// buffer is empty, then waits for the next failed delivery.
+func (c *SSEClient) redeliverLoop(done <-chan struct{}) {
+	delay := time.Second
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(delay):
+		}
+
+		remaining, err := c.flushBuffer()
+		if err != nil {
+			delay = min(2*delay, maxRedeliverDelay)
+			log.Printf("SSE: %d event(s) buffered, target unavailable (%v), retrying in %v", remaining, err, delay)
+			continue
+		}
+
+		delay = time.Second
+		select {
+		case <-done:
+			return
+		case <-c.retry:
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package syncgh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// fakeTarget records delivery IDs and answers with status while it is set.
+type fakeTarget struct {
+	mu        sync.Mutex
+	status    int
+	delivered []string
+}
+
+func (f *fakeTarget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		return
+	}
+	f.delivered = append(f.delivered, r.Header.Get("X-GitHub-Delivery"))
+}
+
+func (f *fakeTarget) set(status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+}
+
+func sseEvent(id string) []byte {
+	return []byte(`{"bodyB":"e30=","x-github-event":"push","x-github-delivery":"` + id + `"}`)
+}
+
+func TestSSEClientBuffersUntilTargetReturns(t *testing.T) {
+	target := &fakeTarget{status: http.StatusBadGateway}
+	srv := httptest.NewServer(target)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	client := NewSSEClient(SSEClientConfig{TargetURL: srv.URL, BufferDir: dir})
+	if err := client.openBuffer(); err != nil {
+		t.Fatal(err)
+	}
+
+	client.processEvent(sseEvent("a"))
+	client.processEvent(sseEvent("b"))
+	if n := client.buffer.len(); n != 2 {
+		t.Fatalf("buffered = %d, want 2", n)
+	}
+
+	// A restarted client picks up the buffer and continues the sequence
+	client = NewSSEClient(SSEClientConfig{TargetURL: srv.URL, BufferDir: dir})
+	if err := client.openBuffer(); err != nil {
+		t.Fatal(err)
+	}
+	client.processEvent(sseEvent("c"))
+	if client.buffer.next != 4 {
+		t.Errorf("next seq = %d, want 4", client.buffer.next)
+	}
+
+	target.set(0)
+	remaining, err := client.flushBuffer()
+	if err != nil || remaining != 0 {
+		t.Fatalf("flushBuffer() = %d, %v", remaining, err)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(target.delivered, want) {
+		t.Errorf("delivered = %v, want %v", target.delivered, want)
+	}
+
+	// Once caught up, events go straight through
+	client.processEvent(sseEvent("d"))
+	if n := client.buffer.len(); n != 0 || len(target.delivered) != 4 {
+		t.Errorf("buffered = %d, delivered = %v", n, target.delivered)
+	}
+}
+
+func TestSSEClientRejectedEventDoesNotBlock(t *testing.T) {
+	target := &fakeTarget{status: http.StatusBadRequest}
+	srv := httptest.NewServer(target)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	client := NewSSEClient(SSEClientConfig{TargetURL: srv.URL, BufferDir: dir})
+	if err := client.openBuffer(); err != nil {
+		t.Fatal(err)
+	}
+
+	client.processEvent(sseEvent("bad"))
+	if n := client.buffer.len(); n != 0 {
+		t.Errorf("buffered = %d, want rejected event moved aside", n)
+	}
+	rejected, _ := os.ReadDir(filepath.Join(dir, "rejected"))
+	if len(rejected) != 1 {
+		t.Errorf("rejected = %d files, want 1", len(rejected))
+	}
+
+	target.set(0)
+	client.processEvent(sseEvent("good"))
+	if want := []string{"good"}; !reflect.DeepEqual(target.delivered, want) {
+		t.Errorf("delivered = %v, want %v", target.delivered, want)
+	}
+}
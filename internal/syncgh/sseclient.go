@@ -15,6 +15,7 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -24,15 +25,27 @@ type SSEClientConfig struct {
 	// ServerURL is the gosmee server URL (e.g., "https://webhook.example.com/channel123")
 	ServerURL string
 
+	// StreamURL overrides the SSE endpoint derived from ServerURL (optional).
+	// smee.io streams from the channel URL itself rather than /events/{channel}.
+	StreamURL string
+
 	// TargetURL is the local webhook handler URL (e.g., "http://localhost:8763/webhook")
 	TargetURL string
 
 	// SaveDir saves webhook payloads to disk for debugging/replay (optional)
 	SaveDir string
 
+	// Archive stores webhook payloads by delivery ID (optional)
+	Archive *PayloadArchive
+
 	// IgnoreEvents skips these event types (e.g., ["ping", "status"])
 	IgnoreEvents []string
 
+	// BufferDir persists events until the target accepts them (optional).
+	// Events the target cannot take are retried with backoff and delivered
+	// in order, including after a restart. Without it they are dropped.
+	BufferDir string
+
 	// HealthPort exposes a health endpoint for K8s probes (0 = disabled)
 	HealthPort int
 
@@ -45,6 +58,10 @@ type SSEClient struct {
 	config     SSEClientConfig
 	client     *http.Client
 	retryCount int
+
+	buffer    *deliveryBuffer
+	deliverMu sync.Mutex    // serializes deliveries so buffered events stay in order
+	retry     chan struct{} // wakes the redelivery loop
 }
 
 // NewSSEClient creates a new SSE client.
@@ -54,6 +71,28 @@ func NewSSEClient(config SSEClientConfig) *SSEClient {
 		client: &http.Client{
 			Timeout: 0, // No timeout for SSE connections
 		},
+		retry: make(chan struct{}, 1),
+	}
+}
+
+// openBuffer opens the configured delivery buffer, if any.
+func (c *SSEClient) openBuffer() error {
+	if c.config.BufferDir == "" || c.buffer != nil {
+		return nil
+	}
+	buffer, err := openDeliveryBuffer(c.config.BufferDir)
+	if err != nil {
+		return err
+	}
+	c.buffer = buffer
+	return nil
+}
+
+// wakeRedelivery asks the redelivery loop to retry buffered events.
+func (c *SSEClient) wakeRedelivery() {
+	select {
+	case c.retry <- struct{}{}:
+	default:
 	}
 }
 
@@ -94,6 +133,12 @@ func parseSSEData(data []byte) (*sseMessage, error) {
 	for key, value := range payload {
 		strVal, ok := value.(string)
 		if !ok {
+			// smee.io sends the body as parsed JSON rather than a string
+			if key == "body" && value != nil && msg.Body == nil {
+				if encoded, err := json.Marshal(value); err == nil {
+					msg.Body = encoded
+				}
+			}
 			continue
 		}
 
@@ -175,7 +220,7 @@ func (c *SSEClient) forwardToTarget(msg *sseMessage) error {
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("target returned error: %d %s", resp.StatusCode, string(body))
+		return &targetStatusError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return nil
@@ -267,11 +312,26 @@ func (c *SSEClient) Run(ctx context.Context) error {
 	if c.config.SaveDir != "" {
 		log.Printf("Saving payloads to %s", c.config.SaveDir)
 	}
+	if c.config.Archive != nil {
+		log.Printf("Archiving payloads to %s", c.config.Archive)
+	}
 
 	if len(c.config.IgnoreEvents) > 0 {
 		log.Printf("Ignoring events: %v", c.config.IgnoreEvents)
 	}
 
+	if err := c.openBuffer(); err != nil {
+		return err
+	}
+	if c.buffer != nil {
+		if n := c.buffer.len(); n > 0 {
+			log.Printf("Buffering undelivered events in %s (%d left from last run)", c.config.BufferDir, n)
+		} else {
+			log.Printf("Buffering undelivered events in %s", c.config.BufferDir)
+		}
+		go c.redeliverLoop(ctx.Done())
+	}
+
 	// Start health server if configured
 	if c.config.HealthPort > 0 {
 		c.startHealthServer()
@@ -303,7 +363,11 @@ func (c *SSEClient) startHealthServer() {
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprintf(w, `{"status":"ok","server":"%s"}`, c.config.ServerURL)
+		buffered := 0
+		if c.buffer != nil {
+			buffered = c.buffer.len()
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"ok","server":"%s","buffered":%d}`, c.config.ServerURL, buffered)
 	})
 
 	addr := fmt.Sprintf(":%d", c.config.HealthPort)
@@ -327,7 +391,9 @@ func (c *SSEClient) connect(ctx context.Context) error {
 	// Build the SSE events URL
 	// gosmee expects /events/{channel} endpoint
 	sseURL := c.config.ServerURL
-	if !strings.Contains(sseURL, "/events/") {
+	if c.config.StreamURL != "" {
+		sseURL = c.config.StreamURL
+	} else if !strings.Contains(sseURL, "/events/") {
 		// Extract channel from URL and construct events URL
 		parts := strings.Split(sseURL, "/")
 		if len(parts) > 0 {
@@ -445,6 +511,26 @@ func (c *SSEClient) processEvent(data []byte) {
 			log.Printf("SSE: Failed to save payload: %v", err)
 		}
 	}
+	if c.config.Archive != nil {
+		p := NewArchivedPayload(msg.DeliveryID, msg.EventType, msg.Headers, msg.Body, msg.Timestamp)
+		if err := c.config.Archive.Put(context.Background(), p); err != nil {
+			log.Printf("SSE: Failed to archive payload: %v", err)
+		}
+	}
+
+	// Buffer first so the event survives a target outage, then deliver
+	// it behind any events still waiting
+	if c.buffer != nil {
+		seq, err := c.buffer.add(msg)
+		if err == nil {
+			if _, err := c.flushBuffer(); err != nil {
+				log.Printf("SSE: Target unavailable, buffered event (seq %d): %v", seq, err)
+				c.wakeRedelivery()
+			}
+			return
+		}
+		log.Printf("SSE: Failed to buffer event, forwarding directly: %v", err)
+	}
 
 	// Forward to target
 	if err := c.forwardToTarget(msg); err != nil {
@@ -472,17 +558,18 @@ func RunSSEClientWithOptions(ctx context.Context, config SSEClientConfig) error
 }
 
 // RunSSEClientWithInvalidation starts the SSE client and also runs a local webhook
-// server that invalidates Task cache on push events.
+// server that invalidates Task cache on push events. The config's TargetURL is
+// set to the local server on port.
 //
 // This combines:
 //  1. SSE client connecting to gosmee server
 //  2. Local webhook handler that parses GitHub events
 //  3. Task cache invalidation on detected changes
-func RunSSEClientWithInvalidation(serverURL, workDir string, port string, saveDir string, ignoreEvents []string, healthPort int) error {
+func RunSSEClientWithInvalidation(config SSEClientConfig, workDir, port string) error {
 	if port == "" {
 		port = "8763"
 	}
-	targetURL := fmt.Sprintf("http://localhost:%s/webhook", port)
+	config.TargetURL = fmt.Sprintf("http://localhost:%s/webhook", port)
 
 	// Start the webhook server with cache invalidation in background
 	go func() {
@@ -494,13 +581,7 @@ func RunSSEClientWithInvalidation(serverURL, workDir string, port string, saveDi
 	time.Sleep(100 * time.Millisecond)
 
 	// Start SSE client with gosmee patterns
-	client := NewSSEClient(SSEClientConfig{
-		ServerURL:    serverURL,
-		TargetURL:    targetURL,
-		SaveDir:      saveDir,
-		IgnoreEvents: ignoreEvents,
-		HealthPort:   healthPort,
-	})
+	client := NewSSEClient(config)
 
 	return client.Run(context.Background())
 }
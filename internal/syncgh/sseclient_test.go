@@ -0,0 +1,48 @@
+package syncgh
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSSEDataBody(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		body string
+	}{
+		{"gosmee base64", `{"bodyB":"eyJyZWYiOiJtYWluIn0=","x-github-event":"push"}`, `{"ref":"main"}`},
+		{"string body", `{"body":"{\"ref\":\"main\"}","x-github-event":"push"}`, `{"ref":"main"}`},
+		{"smee object body", `{"body":{"ref":"main"},"x-github-event":"push"}`, `{"ref":"main"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseSSEData([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseSSEData failed: %v", err)
+			}
+			if msg.EventType != "push" {
+				t.Errorf("EventType = %q, want push", msg.EventType)
+			}
+
+			var got, want any
+			if err := json.Unmarshal(msg.Body, &got); err != nil {
+				t.Fatalf("body is not JSON: %q", msg.Body)
+			}
+			_ = json.Unmarshal([]byte(tt.body), &want)
+			if string(mustMarshal(t, got)) != string(mustMarshal(t, want)) {
+				t.Errorf("Body = %s, want %s", msg.Body, tt.body)
+			}
+		})
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
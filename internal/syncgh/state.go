@@ -12,6 +12,9 @@ import (
 	"time"
 
 	"github.com/google/go-github/v81/github"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
 )
 
 // State represents captured GitHub repository state
@@ -174,7 +177,7 @@ func writeJSON(path string, v any) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal %s: %w", path, err)
 	}
-	return os.WriteFile(path, data, 0644)
+	return statestore.WriteFile(path, data, config.DefaultFilePerms)
 }
 
 // FormatState returns a human-readable string representation of the state
@@ -0,0 +1,199 @@
+package syncgh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// StateStoreEnv selects the poll state backend when no explicit spec is given.
+const StateStoreEnv = "XPLAT_SYNCGH_STATE"
+
+// ErrStateNotFound is returned by a StateStore when no state has been saved yet.
+var ErrStateNotFound = errors.New("state not found")
+
+// StateStore persists poll state somewhere a poller can reach.
+// A local file is enough for a single machine; shared backends (R2, NATS KV)
+// let pollers on several machines see the same commit hashes.
+//
+// Writes are last-writer-wins: two pollers that detect the same change may
+// both fire their callbacks, but neither will miss it.
+type StateStore interface {
+	// Read returns the stored bytes for key, or ErrStateNotFound.
+	Read(key string) ([]byte, error)
+
+	// Write replaces the stored bytes for key.
+	Write(key string, data []byte) error
+
+	// String describes the store for logs (e.g. "file:~/.xplat/cache").
+	String() string
+}
+
+// ParseStateStore builds a StateStore from a spec string:
+//
+//	""  or "file"            local file in ~/.xplat/cache (default)
+//	"file:<dir>"             local file in <dir>
+//	"r2:<remote>:<path>"     R2/S3 object via rclone (e.g. r2:r2:xplat-state/syncgh)
+//	"nats:<bucket>"          NATS KV bucket via the nats CLI (server from NATS_URL)
+func ParseStateStore(spec string) (StateStore, error) {
+	spec = strings.TrimSpace(spec)
+
+	kind, rest, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "", "file":
+		dir := rest
+		if dir == "" {
+			dir = config.XplatCache()
+		}
+		return &FileStateStore{Dir: dir}, nil
+	case "r2", "rclone":
+		if rest == "" {
+			return nil, fmt.Errorf("r2 state store needs a remote path, e.g. r2:r2:bucket/prefix")
+		}
+		return &RcloneStateStore{Remote: rest}, nil
+	case "nats":
+		if rest == "" {
+			return nil, fmt.Errorf("nats state store needs a bucket, e.g. nats:xplat-state")
+		}
+		return &NATSStateStore{Bucket: rest, Server: os.Getenv("NATS_URL")}, nil
+	default:
+		return nil, fmt.Errorf("unknown state store %q (valid: file, r2, nats)", kind)
+	}
+}
+
+// DefaultStateStore returns the store selected by XPLAT_SYNCGH_STATE,
+// falling back to the local cache file.
+func DefaultStateStore() (StateStore, error) {
+	return ParseStateStore(os.Getenv(StateStoreEnv))
+}
+
+// FileStateStore keeps state as files in a local directory.
+type FileStateStore struct {
+	Dir string
+}
+
+// Read implements StateStore.
+func (s *FileStateStore) Read(key string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.Dir, key))
+	if os.IsNotExist(err) {
+		return nil, ErrStateNotFound
+	}
+	return data, err
+}
+
+// Write implements StateStore.
+func (s *FileStateStore) Write(key string, data []byte) error {
+	return statestore.WriteFile(filepath.Join(s.Dir, key), data, config.DefaultFilePerms)
+}
+
+func (s *FileStateStore) String() string {
+	return "file:" + s.Dir
+}
+
+// RcloneStateStore keeps state as objects on any rclone remote.
+// Point it at an R2 (or Garage/S3) remote configured in rclone.conf.
+type RcloneStateStore struct {
+	// Remote is the rclone path prefix, e.g. "r2:xplat-state/syncgh"
+	Remote string
+}
+
+func (s *RcloneStateStore) path(key string) string {
+	return strings.TrimSuffix(s.Remote, "/") + "/" + key
+}
+
+// Read implements StateStore.
+func (s *RcloneStateStore) Read(key string) ([]byte, error) {
+	out, stderr, err := runStateCLI(nil, "rclone", "cat", s.path(key))
+	if err != nil {
+		if strings.Contains(stderr, "not found") || strings.Contains(stderr, "doesn't exist") {
+			return nil, ErrStateNotFound
+		}
+		return nil, fmt.Errorf("rclone cat %s: %w: %s", s.path(key), err, stderr)
+	}
+	return out, nil
+}
+
+// Write implements StateStore.
+func (s *RcloneStateStore) Write(key string, data []byte) error {
+	if _, stderr, err := runStateCLI(data, "rclone", "rcat", s.path(key)); err != nil {
+		return fmt.Errorf("rclone rcat %s: %w: %s", s.path(key), err, stderr)
+	}
+	return nil
+}
+
+func (s *RcloneStateStore) String() string {
+	return "r2:" + s.Remote
+}
+
+// NATSStateStore keeps state in a NATS JetStream key-value bucket.
+type NATSStateStore struct {
+	Bucket string
+	// Server is the NATS URL (empty uses the nats CLI context default)
+	Server string
+}
+
+func (s *NATSStateStore) args(args ...string) []string {
+	if s.Server != "" {
+		args = append([]string{"--server", s.Server}, args...)
+	}
+	return args
+}
+
+// Read implements StateStore.
+func (s *NATSStateStore) Read(key string) ([]byte, error) {
+	out, stderr, err := runStateCLI(nil, "nats", s.args("kv", "get", s.Bucket, key, "--raw")...)
+	if err != nil {
+		if strings.Contains(stderr, "key not found") {
+			return nil, ErrStateNotFound
+		}
+		return nil, fmt.Errorf("nats kv get %s: %w: %s", s.Bucket, err, stderr)
+	}
+	return out, nil
+}
+
+// Write implements StateStore.
+// The bucket is created on first write if it doesn't exist.
+func (s *NATSStateStore) Write(key string, data []byte) error {
+	_, stderr, err := runStateCLI(data, "nats", s.args("kv", "put", s.Bucket, key)...)
+	if err != nil && strings.Contains(stderr, "bucket not found") {
+		if _, addStderr, addErr := runStateCLI(nil, "nats", s.args("kv", "add", s.Bucket)...); addErr != nil {
+			return fmt.Errorf("nats kv add %s: %w: %s", s.Bucket, addErr, addStderr)
+		}
+		_, stderr, err = runStateCLI(data, "nats", s.args("kv", "put", s.Bucket, key)...)
+	}
+	if err != nil {
+		return fmt.Errorf("nats kv put %s: %w: %s", s.Bucket, err, stderr)
+	}
+	return nil
+}
+
+func (s *NATSStateStore) String() string {
+	return "nats:" + s.Bucket
+}
+
+// runStateCLI runs a storage CLI, piping stdin to it when non-nil.
+func runStateCLI(stdin []byte, name string, args ...string) ([]byte, string, error) {
+	bin, err := exec.LookPath(name)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s not found in PATH: %w", name, err)
+	}
+
+	cmd := exec.Command(bin, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	return stdout.Bytes(), stderr.String(), err
+}
@@ -4,23 +4,24 @@ import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
 	"github.com/google/go-github/v81/github"
+
+	"github.com/joeblew999/xplat/internal/ghauth"
 )
 
 // ConfigureGitHubWebhook creates a webhook for a GitHub repo using go-github.
-// Requires GITHUB_TOKEN environment variable to be set.
+// Requires a GitHub token from the ghauth credential chain.
 // Uses the same go-github library as the rest of syncgh - no external binaries.
 //
 // This can be used with any webhook URL, including:
 //   - Cloudflare tunnel URLs (xplat sync-cf tunnel)
 //   - Any public URL that can receive webhooks
 func ConfigureGitHubWebhook(repo, webhookURL, events string) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return fmt.Errorf("GITHUB_TOKEN environment variable is required")
+	token, err := ghauth.RequireToken(context.Background())
+	if err != nil {
+		return err
 	}
 
 	// Parse owner/repo
@@ -73,9 +74,9 @@ func ConfigureGitHubWebhook(repo, webhookURL, events string) error {
 
 // ListWebhooks lists all webhooks for a GitHub repo.
 func ListWebhooks(repo string) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return fmt.Errorf("GITHUB_TOKEN environment variable is required")
+	token, err := ghauth.RequireToken(context.Background())
+	if err != nil {
+		return err
 	}
 
 	parts := strings.SplitN(repo, "/", 2)
@@ -115,9 +116,9 @@ func ListWebhooks(repo string) error {
 
 // DeleteWebhook deletes a webhook by ID.
 func DeleteWebhook(repo string, hookID int64) error {
-	token := os.Getenv("GITHUB_TOKEN")
-	if token == "" {
-		return fmt.Errorf("GITHUB_TOKEN environment variable is required")
+	token, err := ghauth.RequireToken(context.Background())
+	if err != nil {
+		return err
 	}
 
 	parts := strings.SplitN(repo, "/", 2)
@@ -129,7 +130,7 @@ func DeleteWebhook(repo string, hookID int64) error {
 	client := github.NewClient(nil).WithAuthToken(token)
 	ctx := context.Background()
 
-	_, err := client.Repositories.DeleteHook(ctx, owner, repoName, hookID)
+	_, err = client.Repositories.DeleteHook(ctx, owner, repoName, hookID)
 	if err != nil {
 		return fmt.Errorf("failed to delete webhook: %w", err)
 	}
@@ -0,0 +1,293 @@
+// Package syncgh provides GitHub sync operations.
+//
+// This file implements pluggable tunnel providers for receiving GitHub
+// webhooks on a machine without a public address.
+package syncgh
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/synccf"
+)
+
+// SmeeURL is the public smee.io service used by the smee provider.
+const SmeeURL = "https://smee.io"
+
+// Tunnel provider names.
+const (
+	ProviderSmee        = "smee"
+	ProviderSSE         = "sse"
+	ProviderCloudflared = "cloudflared"
+)
+
+// TunnelProviders lists the available provider names.
+var TunnelProviders = []string{ProviderSmee, ProviderSSE, ProviderCloudflared}
+
+// TunnelProvider exposes a local webhook handler to GitHub.
+type TunnelProvider interface {
+	// Name returns the provider name (e.g. "smee", "cloudflared").
+	Name() string
+
+	// Start begins forwarding public webhooks to targetURL and returns the URL
+	// to configure on the GitHub webhook. It returns once that URL is known;
+	// forwarding continues in the background until ctx is done or Stop is called.
+	Start(ctx context.Context, targetURL string) (string, error)
+
+	// Stop stops forwarding.
+	Stop()
+}
+
+// TunnelOptions configures NewTunnelProvider.
+type TunnelOptions struct {
+	// ServerURL is the smee.io channel URL (smee) or self-hosted SSE server URL (sse).
+	// For smee it is optional: a new channel is created when empty.
+	// For sse it may include a channel (https://host/channel) or just the server.
+	ServerURL string
+
+	// IgnoreEvents skips these event types (smee and sse only)
+	IgnoreEvents []string
+
+	// BufferDir persists events until the target accepts them (smee and sse only)
+	BufferDir string
+}
+
+// NewTunnelProvider creates a tunnel provider by name.
+func NewTunnelProvider(name string, opts TunnelOptions) (TunnelProvider, error) {
+	switch name {
+	case ProviderSmee, "":
+		return &SmeeProvider{Channel: opts.ServerURL, IgnoreEvents: opts.IgnoreEvents, BufferDir: opts.BufferDir}, nil
+	case ProviderSSE:
+		if opts.ServerURL == "" {
+			return nil, fmt.Errorf("sse provider requires a server URL (see 'xplat sync-gh server')")
+		}
+		return &SSEServerProvider{ServerURL: opts.ServerURL, IgnoreEvents: opts.IgnoreEvents, BufferDir: opts.BufferDir}, nil
+	case ProviderCloudflared:
+		return &CloudflaredProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown tunnel provider %q (valid: %s)", name, strings.Join(TunnelProviders, ", "))
+	}
+}
+
+// sseRelay runs an SSEClient in the background, shared by the smee and sse providers.
+type sseRelay struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+func (r *sseRelay) start(ctx context.Context, cfg SSEClientConfig) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	r.mu.Lock()
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	client := NewSSEClient(cfg)
+	go func() {
+		if err := client.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Tunnel: SSE relay stopped: %v", err)
+		}
+	}()
+}
+
+func (r *sseRelay) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+		r.cancel = nil
+	}
+}
+
+// SmeeProvider relays webhooks through a smee.io channel.
+type SmeeProvider struct {
+	// Channel is the smee.io channel URL (created on Start if empty)
+	Channel      string
+	IgnoreEvents []string
+	BufferDir    string
+
+	relay sseRelay
+}
+
+// Name implements TunnelProvider.
+func (p *SmeeProvider) Name() string { return ProviderSmee }
+
+// Start implements TunnelProvider.
+func (p *SmeeProvider) Start(ctx context.Context, targetURL string) (string, error) {
+	if p.Channel == "" {
+		channel, err := GenerateSmeeChannel()
+		if err != nil {
+			return "", err
+		}
+		p.Channel = channel
+	}
+
+	p.relay.start(ctx, SSEClientConfig{
+		ServerURL:    p.Channel,
+		StreamURL:    p.Channel,
+		TargetURL:    targetURL,
+		IgnoreEvents: p.IgnoreEvents,
+		BufferDir:    p.BufferDir,
+	})
+	return p.Channel, nil
+}
+
+// Stop implements TunnelProvider.
+func (p *SmeeProvider) Stop() { p.relay.stop() }
+
+// SSEServerProvider relays webhooks through a self-hosted gosmee-compatible
+// SSE server (xplat sync-gh server), so no third-party service is involved.
+type SSEServerProvider struct {
+	// ServerURL is the server base URL, optionally with a channel path
+	ServerURL    string
+	IgnoreEvents []string
+	BufferDir    string
+
+	relay sseRelay
+}
+
+// Name implements TunnelProvider.
+func (p *SSEServerProvider) Name() string { return ProviderSSE }
+
+// Start implements TunnelProvider.
+func (p *SSEServerProvider) Start(ctx context.Context, targetURL string) (string, error) {
+	channelURL := strings.TrimSuffix(p.ServerURL, "/")
+
+	u, err := url.Parse(channelURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL: %w", err)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		// No channel given - ask the server for a new one
+		channelURL, err = fetchNewChannel(channelURL + "/new")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	p.relay.start(ctx, SSEClientConfig{
+		ServerURL:    channelURL,
+		TargetURL:    targetURL,
+		IgnoreEvents: p.IgnoreEvents,
+		BufferDir:    p.BufferDir,
+	})
+	return channelURL, nil
+}
+
+// Stop implements TunnelProvider.
+func (p *SSEServerProvider) Stop() { p.relay.stop() }
+
+// CloudflaredProvider exposes the local handler directly with a cloudflared
+// quick tunnel. GitHub posts straight to the tunnel; no relay is involved.
+type CloudflaredProvider struct {
+	tunnel *synccf.Tunnel
+}
+
+// Name implements TunnelProvider.
+func (p *CloudflaredProvider) Name() string { return ProviderCloudflared }
+
+// Start implements TunnelProvider.
+func (p *CloudflaredProvider) Start(ctx context.Context, targetURL string) (string, error) {
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid target URL: %w", err)
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return "", fmt.Errorf("target URL must include a port: %s", targetURL)
+	}
+
+	if err := synccf.CheckCloudflared(); err != nil {
+		log.Printf("Tunnel: cloudflared not found, installing...")
+		if err := synccf.InstallCloudflared(); err != nil {
+			return "", fmt.Errorf("failed to install cloudflared: %w", err)
+		}
+	}
+
+	publicURL, tunnel, err := synccf.RunQuickTunnel(ctx, port)
+	if err != nil {
+		return "", err
+	}
+	p.tunnel = tunnel
+
+	return strings.TrimSuffix(publicURL, "/") + u.Path, nil
+}
+
+// Stop implements TunnelProvider.
+func (p *CloudflaredProvider) Stop() {
+	if p.tunnel != nil {
+		p.tunnel.Stop()
+	}
+}
+
+// GenerateSmeeChannel creates a new smee.io channel and returns its URL.
+func GenerateSmeeChannel() (string, error) {
+	client := &http.Client{
+		Timeout: 10 * time.Second,
+		// smee.io/new answers with a redirect to the new channel
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	resp, err := client.Get(SmeeURL + "/new")
+	if err != nil {
+		return "", fmt.Errorf("failed to create smee.io channel: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", fmt.Errorf("smee.io did not return a channel (status %d)", resp.StatusCode)
+	}
+	return location, nil
+}
+
+// fetchNewChannel asks a gosmee-compatible server for a new channel URL.
+func fetchNewChannel(newURL string) (string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(newURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to create channel: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server returned %d creating channel", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read channel URL: %w", err)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// RunTunnel starts the provider, logs the webhook URL and blocks until ctx is done.
+func RunTunnel(ctx context.Context, provider TunnelProvider, targetURL string) error {
+	log.Printf("Tunnel: starting %s provider...", provider.Name())
+
+	webhookURL, err := provider.Start(ctx, targetURL)
+	if err != nil {
+		return fmt.Errorf("failed to start %s tunnel: %w", provider.Name(), err)
+	}
+	defer provider.Stop()
+
+	log.Printf("")
+	log.Printf("Configure your GitHub webhook to POST to:")
+	log.Printf("  %s", webhookURL)
+	log.Printf("")
+	log.Printf("Forwarding to: %s", targetURL)
+	log.Printf("Press Ctrl+C to stop")
+
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,74 @@
+package syncgh
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewTunnelProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     TunnelOptions
+		expected string
+		wantErr  bool
+	}{
+		{"", TunnelOptions{}, ProviderSmee, false},
+		{ProviderSmee, TunnelOptions{ServerURL: "https://smee.io/abc"}, ProviderSmee, false},
+		{ProviderSSE, TunnelOptions{ServerURL: "https://webhook.example.com"}, ProviderSSE, false},
+		{ProviderSSE, TunnelOptions{}, "", true},
+		{ProviderCloudflared, TunnelOptions{}, ProviderCloudflared, false},
+		{"ngrok", TunnelOptions{}, "", true},
+	}
+
+	for _, tt := range tests {
+		provider, err := NewTunnelProvider(tt.name, tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewTunnelProvider(%q) expected error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewTunnelProvider(%q) failed: %v", tt.name, err)
+			continue
+		}
+		if provider.Name() != tt.expected {
+			t.Errorf("NewTunnelProvider(%q).Name() = %q, want %q", tt.name, provider.Name(), tt.expected)
+		}
+	}
+}
+
+func TestSSEServerProviderCreatesChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/new":
+			_, _ = fmt.Fprintf(w, "http://%s/chan123\n", r.Host)
+		case strings.HasPrefix(r.URL.Path, "/events/"):
+			w.Header().Set("Content-Type", "text/event-stream")
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewTunnelProvider(ProviderSSE, TunnelOptions{ServerURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	webhookURL, err := provider.Start(ctx, "http://localhost:8763/webhook")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer provider.Stop()
+
+	if webhookURL != server.URL+"/chan123" {
+		t.Errorf("webhook URL = %q, want %q", webhookURL, server.URL+"/chan123")
+	}
+}
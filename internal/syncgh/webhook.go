@@ -1,11 +1,14 @@
 package syncgh
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/cbrgm/githubevents/v2/githubevents"
 	"github.com/google/go-github/v80/github"
@@ -16,6 +19,12 @@ type WebhookConfig struct {
 	Port       string
 	WorkDir    string // Working directory for Task cache invalidation
 	Invalidate bool   // Enable Task cache invalidation on push events
+
+	// Filter limits which pushes and releases invalidate (optional)
+	Filter *EventFilter
+
+	// Archive stores received payloads by delivery ID (optional)
+	Archive *PayloadArchive
 }
 
 // WebhookServer handles GitHub webhook events
@@ -69,11 +78,16 @@ func NewWebhookServerWithConfig(config WebhookConfig) *WebhookServer {
 
 		log.Printf("Push: %s@%s (%s -> %s)", repo, branch, beforeSHA, afterSHA)
 
+		if ok, reason := server.config.Filter.Match(pushChangeEvent(event)); !ok {
+			log.Printf("Push: %s@%s filtered out: %s", repo, branch, reason)
+			return nil
+		}
+
 		// Invalidate Task cache if enabled
 		if server.config.Invalidate && server.config.WorkDir != "" {
 			log.Printf("Invalidating Task cache for %s...", server.config.WorkDir)
 			callback := TaskCacheInvalidator(server.config.WorkDir)
-			callback(repo, branch, beforeSHA, afterSHA)
+			callback(pushChange(event, beforeSHA, afterSHA))
 		}
 
 		return nil
@@ -87,11 +101,26 @@ func NewWebhookServerWithConfig(config WebhookConfig) *WebhookServer {
 
 		log.Printf("Release: %s [%s] %s", repo, action, release.GetTagName())
 
+		if ok, reason := server.config.Filter.Match(ChangeEvent{
+			Repo:  repo,
+			Ref:   release.GetTagName(),
+			Tag:   true,
+			Actor: event.GetSender().GetLogin(),
+		}); !ok {
+			log.Printf("Release: %s %s filtered out: %s", repo, release.GetTagName(), reason)
+			return nil
+		}
+
 		// Invalidate cache on release publish
 		if action == "published" && server.config.Invalidate && server.config.WorkDir != "" {
 			log.Printf("Invalidating Task cache for release %s...", release.GetTagName())
 			callback := TaskCacheInvalidator(server.config.WorkDir)
-			callback(repo, release.GetTagName(), "", release.GetTagName())
+			callback(Change{
+				Repo:    repo,
+				Ref:     release.GetTagName(),
+				NewHash: release.GetTagName(),
+				Actor:   event.GetSender().GetLogin(),
+			})
 		}
 
 		return nil
@@ -100,8 +129,86 @@ func NewWebhookServerWithConfig(config WebhookConfig) *WebhookServer {
 	return server
 }
 
+// pushChangeEvent builds a ChangeEvent from a push webhook. Files are the
+// union over the pushed commits, or nil when the payload lists no commits or
+// may have left some out (pushCommitsLimit).
+func pushChangeEvent(event *github.PushEvent) ChangeEvent {
+	ref := event.GetRef()
+	e := ChangeEvent{
+		Repo:  event.GetRepo().GetFullName(),
+		Ref:   strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/"),
+		Tag:   strings.HasPrefix(ref, "refs/tags/"),
+		Actor: event.GetSender().GetLogin(),
+	}
+	if e.Actor == "" {
+		e.Actor = event.GetPusher().GetName()
+	}
+
+	if len(event.Commits) == 0 || len(event.Commits) >= pushCommitsLimit {
+		return e
+	}
+	seen := make(map[string]bool)
+	e.Files = []string{}
+	for _, c := range event.Commits {
+		for _, files := range [][]string{c.Added, c.Modified, c.Removed} {
+			for _, file := range files {
+				if !seen[file] {
+					seen[file] = true
+					e.Files = append(e.Files, file)
+				}
+			}
+		}
+	}
+	return e
+}
+
+// pushChange builds the Change a push webhook describes. The payload has the
+// compare URL, commits and changed files, so no API call is needed.
+func pushChange(event *github.PushEvent, oldHash, newHash string) Change {
+	e := pushChangeEvent(event)
+	c := Change{
+		Repo:       e.Repo,
+		Ref:        e.Ref,
+		OldHash:    oldHash,
+		NewHash:    newHash,
+		CompareURL: event.GetCompare(),
+		Commits:    len(event.Commits),
+		Actor:      e.Actor,
+		Files:      e.Files,
+	}
+	seen := make(map[string]bool)
+	for _, commit := range event.Commits {
+		author := commit.GetAuthor().GetLogin()
+		if author == "" {
+			author = commit.GetAuthor().GetName()
+		}
+		if author != "" && !seen[author] {
+			seen[author] = true
+			c.Authors = append(c.Authors, author)
+		}
+	}
+	return c
+}
+
 // HandleWebhook processes incoming webhook requests
 func (s *WebhookServer) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxBodySize)
+
+	var body []byte
+	if s.config.Archive != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			if strings.Contains(err.Error(), "request body too large") {
+				http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "Bad request", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
 	err := s.handler.HandleEventRequest(r)
 	if err != nil {
 		log.Printf("Webhook error: %v", err)
@@ -109,10 +216,30 @@ func (s *WebhookServer) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if s.config.Archive != nil {
+		go s.archivePayload(r.Header.Clone(), body)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	_, _ = fmt.Fprintf(w, "OK")
 }
 
+// archivePayload stores a handled webhook in the configured archive.
+// Runs in the background so GitHub's delivery timeout is not affected.
+func (s *WebhookServer) archivePayload(header http.Header, body []byte) {
+	headers := make(map[string]string)
+	for k := range header {
+		if strings.HasPrefix(k, "X-") || k == "Content-Type" || k == "User-Agent" {
+			headers[k] = header.Get(k)
+		}
+	}
+
+	p := NewArchivedPayload(header.Get("X-GitHub-Delivery"), header.Get("X-GitHub-Event"), headers, body, time.Now())
+	if err := s.config.Archive.Put(context.Background(), p); err != nil {
+		log.Printf("Webhook: Failed to archive payload: %v", err)
+	}
+}
+
 // Run starts the webhook server
 func (s *WebhookServer) Run() error {
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -125,6 +252,9 @@ func (s *WebhookServer) Run() error {
 
 	addr := fmt.Sprintf(":%s", s.port)
 	log.Printf("Webhook server listening on %s", addr)
+	if s.config.Archive != nil {
+		log.Printf("Archiving payloads to %s", s.config.Archive)
+	}
 
 	return http.ListenAndServe(addr, nil)
 }
@@ -137,14 +267,23 @@ func RunWebhook(port string) {
 	}
 }
 
-// RunWebhookWithInvalidation starts a webhook server that invalidates Task cache on push events
+// RunWebhookWithInvalidation starts a webhook server that invalidates Task cache on push events.
+// The project's sync-gh-filter.yaml, if any, limits which events invalidate.
 func RunWebhookWithInvalidation(port, workDir string) {
+	filter, err := LoadProjectEventFilter(workDir)
+	if err != nil {
+		log.Fatal(err)
+	}
 	server := NewWebhookServerWithConfig(WebhookConfig{
 		Port:       port,
 		WorkDir:    workDir,
 		Invalidate: true,
+		Filter:     filter,
 	})
 	log.Printf("Task cache invalidation enabled for: %s", workDir)
+	if filter != nil {
+		log.Printf("Event filter: %s", DefaultFilterFile)
+	}
 	if err := server.Run(); err != nil {
 		log.Fatal(err)
 	}
@@ -18,6 +18,8 @@ type GenerateOptions struct {
 	HasTests       bool   // Include test task
 	HasLint        bool   // Include lint task
 	Language       string // "go" or "rust" (default: "go")
+	Archetype      string // Bootstrap archetype (e.g., "hugo-site"), shapes build/run/dev tasks
+	Port           int    // Default PORT for run/dev tasks (hugo-site and worker)
 	RunArgs        string // Arguments for user-facing run (e.g., "edit")
 	ServiceRunArgs string // Arguments for service/daemon mode (e.g., "edit -launch-browser=false")
 
@@ -92,6 +94,8 @@ func Generate(outputPath string, opts GenerateOptions) error {
 		MainPath:       mainPath,
 		HasTests:       opts.HasTests,
 		Language:       language,
+		Archetype:      opts.Archetype,
+		Port:           opts.Port,
 		RunArgs:        opts.RunArgs,
 		ServiceRunArgs: opts.ServiceRunArgs,
 		IsExternalRepo: opts.IsExternalRepo,
@@ -1,10 +1,12 @@
 package taskfile
 
 import (
+	"bytes"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // Include represents a taskfile include entry.
@@ -13,117 +15,355 @@ type Include struct {
 	Taskfile string // URL or path to taskfile
 }
 
-// AddInclude adds a remote taskfile include to a Taskfile.
-// It modifies the file in place, preserving existing content and formatting.
-func AddInclude(taskfilePath string, include Include) error {
-	data, err := os.ReadFile(taskfilePath)
+// minimalTaskfile is the starting point when editing a Taskfile that does not exist yet.
+const minimalTaskfile = `version: '3'
+
+tasks:
+  default:
+    desc: List available tasks
+    cmds:
+      - task --list
+`
+
+// Editor makes targeted changes to a Taskfile. It uses the YAML parser only
+// to locate sections and entries, then splices lines into the original
+// text, so comments, blank lines, quoting and key order are kept as written.
+type Editor struct {
+	path  string
+	lines []string
+	root  *yaml.Node // Top-level mapping
+}
+
+// Open loads a Taskfile for editing. A missing file starts from a minimal
+// Taskfile, which Save creates.
+func Open(path string) (*Editor, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Create minimal Taskfile with the include
-			content := createMinimalTaskfile(include)
-			return os.WriteFile(taskfilePath, []byte(content), 0644)
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read taskfile: %w", err)
 		}
-		return fmt.Errorf("failed to read taskfile: %w", err)
+		data = []byte(minimalTaskfile)
+	}
+	e := &Editor{path: path, lines: strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")}
+	if err := e.parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
+	return e, nil
+}
+
+// Bytes returns the edited Taskfile.
+func (e *Editor) Bytes() []byte {
+	return []byte(strings.Join(e.lines, "\n") + "\n")
+}
+
+// Save writes the edited Taskfile back to its path.
+func (e *Editor) Save() error {
+	return os.WriteFile(e.path, e.Bytes(), 0644)
+}
 
-	content := string(data)
+// HasInclude reports whether the Taskfile includes a namespace.
+func (e *Editor) HasInclude(namespace string) bool {
+	key, _ := e.entry("includes", namespace)
+	return key != nil
+}
 
-	// Check if include already exists
-	if strings.Contains(content, include.Name+":") && strings.Contains(content, include.Taskfile) {
-		return fmt.Errorf("include %q already exists in %s", include.Name, taskfilePath)
+// AddInclude adds an include. It fails if the namespace is already included.
+func (e *Editor) AddInclude(include Include) error {
+	if e.HasInclude(include.Name) {
+		return fmt.Errorf("include %q already exists in %s", include.Name, e.path)
 	}
+	return e.SetInclude(include)
+}
 
-	// Find or create includes section
-	modified, err := addIncludeToContent(content, include)
-	if err != nil {
-		return err
+// SetInclude adds an include, or replaces the include with the same
+// namespace in place.
+func (e *Editor) SetInclude(include Include) error {
+	return e.setEntry("includes", []string{"version"}, include.Name, map[string]string{"taskfile": include.Taskfile})
+}
+
+// RemoveInclude removes an include. Removing a namespace that is not
+// included is a no-op.
+func (e *Editor) RemoveInclude(namespace string) error {
+	return e.removeEntry("includes", namespace)
+}
+
+// AddTask adds a task. It fails if a task with that name exists.
+func (e *Editor) AddTask(name string, task Task) error {
+	if key, _ := e.entry("tasks", name); key != nil {
+		return fmt.Errorf("task %q already exists in %s", name, e.path)
 	}
+	return e.setEntry("tasks", []string{"version", "includes", "vars"}, name, taskYAML(task))
+}
 
-	// Write back
-	return os.WriteFile(taskfilePath, []byte(modified), 0644)
+// SetVar sets a global var, replacing the value of an existing var in place.
+func (e *Editor) SetVar(name, value string) error {
+	return e.setEntry("vars", []string{"version", "includes"}, name, value)
 }
 
-// RemoveInclude removes a remote taskfile include from a Taskfile.
-func RemoveInclude(taskfilePath string, namespace string) error {
-	data, err := os.ReadFile(taskfilePath)
-	if err != nil {
-		return fmt.Errorf("failed to read taskfile: %w", err)
+// taskYAML is Task with empty fields left out when marshaled.
+type taskYAML struct {
+	Desc     string         `yaml:"desc,omitempty"`
+	Deps     []any          `yaml:"deps,omitempty"`
+	Cmds     []any          `yaml:"cmds,omitempty"`
+	Status   []string       `yaml:"status,omitempty"`
+	Vars     map[string]any `yaml:"vars,omitempty"`
+	Requires *Requires      `yaml:"requires,omitempty"`
+	Internal bool           `yaml:"internal,omitempty"`
+}
+
+// parse re-reads the node tree after the lines change.
+func (e *Editor) parse() error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(e.lines, "\n")), &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return fmt.Errorf("taskfile is empty")
 	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("taskfile is not a mapping")
+	}
+	e.root = doc.Content[0]
+	return nil
+}
 
-	content := string(data)
-	modified := removeIncludeFromContent(content, namespace)
+// lookup returns the key and value nodes for a key in a mapping.
+func lookup(mapping *yaml.Node, name string) (*yaml.Node, *yaml.Node) {
+	if mapping == nil || mapping.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
 
-	return os.WriteFile(taskfilePath, []byte(modified), 0644)
+// entry returns the key and value nodes of an entry in a top-level section.
+func (e *Editor) entry(section, name string) (*yaml.Node, *yaml.Node) {
+	_, val := lookup(e.root, section)
+	return lookup(val, name)
 }
 
-// HasInclude checks if a namespace is already included.
-func HasInclude(taskfilePath string, namespace string) (bool, error) {
-	data, err := os.ReadFile(taskfilePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
+// blockEnd returns the last line (1-based) of the block starting at a key:
+// the key line plus every following line indented deeper. Blank and
+// comment lines only count when more of the block follows them.
+func (e *Editor) blockEnd(key, val *yaml.Node) int {
+	indent := key.Column - 1
+	end := key.Line
+	for n := key.Line + 1; n <= len(e.lines); n++ {
+		line := e.lines[n-1]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
 		}
-		return false, err
+		lineIndent := len(line) - len(strings.TrimLeft(line, " "))
+		// A block sequence may sit at its key's indentation
+		sameIndentItem := lineIndent == indent && val.Kind == yaml.SequenceNode &&
+			val.Style&yaml.FlowStyle == 0 && strings.HasPrefix(trimmed, "-")
+		if lineIndent <= indent && !sameIndentItem {
+			break
+		}
+		end = n
 	}
+	return end
+}
 
-	// Simple check - look for "namespace:" in includes section
-	content := string(data)
+// insert inserts lines after line n (1-based; 0 inserts at the top).
+func (e *Editor) insert(n int, lines []string) {
+	rest := append(lines, e.lines[n:]...)
+	e.lines = append(e.lines[:n], rest...)
+}
+
+// replace replaces lines from..to (1-based, inclusive).
+func (e *Editor) replace(from, to int, lines []string) {
+	rest := append(lines, e.lines[to:]...)
+	e.lines = append(e.lines[:from-1], rest...)
+}
 
-	// Find includes section
-	includesMatch := regexp.MustCompile(`(?m)^includes:\s*\n`).FindStringIndex(content)
-	if includesMatch == nil {
-		return false, nil
+// render returns an entry as lines at the given indentation. String
+// values stay on the key line; anything else is a nested block.
+func render(indent int, name string, value any) ([]string, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
 	}
+	_ = enc.Close()
 
-	// Check if namespace exists after includes:
-	afterIncludes := content[includesMatch[1]:]
-	namespacePattern := regexp.MustCompile(`(?m)^\s+` + regexp.QuoteMeta(namespace) + `:\s*$|^\s+` + regexp.QuoteMeta(namespace) + `:\s*\n`)
-	return namespacePattern.MatchString(afterIncludes), nil
+	pad := strings.Repeat(" ", indent)
+	body := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if _, ok := value.(string); ok {
+		// Multi-line strings continue as an indented block scalar
+		lines := []string{pad + name + ": " + body[0]}
+		for _, l := range body[1:] {
+			lines = append(lines, pad+l)
+		}
+		return lines, nil
+	}
+	lines := []string{pad + name + ":"}
+	for _, l := range body {
+		lines = append(lines, pad+"  "+l)
+	}
+	return lines, nil
 }
 
-func createMinimalTaskfile(include Include) string {
-	return fmt.Sprintf(`version: '3'
+// setEntry adds or replaces an entry in a top-level section, creating the
+// section after the last of the after keys present when it is missing.
+func (e *Editor) setEntry(section string, after []string, name string, value any) error {
+	sectionKey, sectionVal := lookup(e.root, section)
 
-includes:
-  %s:
-    taskfile: %s
+	if sectionKey == nil {
+		insertAt := 0
+		for _, k := range after {
+			if key, val := lookup(e.root, k); key != nil {
+				insertAt = e.blockEnd(key, val)
+			}
+		}
+		if insertAt == 0 {
+			insertAt = len(e.lines)
+		}
+		lines, err := render(2, name, value)
+		if err != nil {
+			return err
+		}
+		e.insert(insertAt, append([]string{"", section + ":"}, lines...))
+		return e.parse()
+	}
 
-tasks:
-  default:
-    desc: List available tasks
-    cmds:
-      - task --list
-`, include.Name, include.Taskfile)
+	indent := sectionKey.Column + 1
+	switch {
+	case sectionVal.Kind == yaml.MappingNode && sectionVal.Style&yaml.FlowStyle != 0:
+		if len(sectionVal.Content) > 0 {
+			return fmt.Errorf("%s: flow-style %s are not supported", e.path, section)
+		}
+		// "section: {}" becomes a block mapping
+		e.lines[sectionKey.Line-1] = strings.Repeat(" ", sectionKey.Column-1) + section + ":"
+	case sectionVal.Kind == yaml.MappingNode && len(sectionVal.Content) > 0:
+		indent = sectionVal.Content[0].Column - 1
+	case sectionVal.Kind == yaml.ScalarNode && sectionVal.Tag == "!!null":
+	default:
+		return fmt.Errorf("%s: %s is not a mapping", e.path, section)
+	}
+
+	lines, err := render(indent, name, value)
+	if err != nil {
+		return err
+	}
+	if key, val := lookup(sectionVal, name); key != nil {
+		// Keep a trailing comment on a single-line scalar value
+		if len(lines) == 1 && val.Kind == yaml.ScalarNode && val.Line == key.Line && val.LineComment != "" {
+			lines[0] += " " + val.LineComment
+		}
+		e.replace(key.Line, e.blockEnd(key, val), lines)
+	} else {
+		e.insert(e.blockEnd(sectionKey, sectionVal), lines)
+	}
+	return e.parse()
 }
 
-func addIncludeToContent(content string, include Include) (string, error) {
-	// Pattern to find includes section
-	includesPattern := regexp.MustCompile(`(?m)^includes:\s*\n`)
+// removeEntry removes an entry from a top-level section, and the section
+// itself when that leaves it empty.
+func (e *Editor) removeEntry(section, name string) error {
+	sectionKey, sectionVal := lookup(e.root, section)
+	key, val := lookup(sectionVal, name)
+	if key == nil {
+		return nil
+	}
+	if sectionVal.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("%s: flow-style %s are not supported", e.path, section)
+	}
 
-	includeEntry := fmt.Sprintf("  %s:\n    taskfile: %s\n", include.Name, include.Taskfile)
+	if len(sectionVal.Content) == 2 {
+		from := sectionKey.Line
+		// Take the blank line that separated the section with it
+		if from > 1 && strings.TrimSpace(e.lines[from-2]) == "" {
+			from--
+		}
+		e.replace(from, e.blockEnd(sectionKey, sectionVal), nil)
+	} else {
+		e.replace(key.Line, e.blockEnd(key, val), nil)
+	}
+	return e.parse()
+}
 
-	if match := includesPattern.FindStringIndex(content); match != nil {
-		// Found includes section - add after it
-		insertPos := match[1]
-		return content[:insertPos] + includeEntry + content[insertPos:], nil
+// AddInclude adds a remote taskfile include to a Taskfile.
+// It modifies the file in place, preserving existing content and formatting.
+func AddInclude(taskfilePath string, include Include) error {
+	e, err := Open(taskfilePath)
+	if err != nil {
+		return err
+	}
+	if err := e.AddInclude(include); err != nil {
+		return err
 	}
+	return e.Save()
+}
 
-	// No includes section - need to add one
-	// Try to add after version line
-	versionPattern := regexp.MustCompile(`(?m)^version:\s*['"]?\d['"]?\s*\n`)
-	if match := versionPattern.FindStringIndex(content); match != nil {
-		insertPos := match[1]
-		newIncludes := "\nincludes:\n" + includeEntry
-		return content[:insertPos] + newIncludes + content[insertPos:], nil
+// SetInclude adds a taskfile include or points an existing one at a new taskfile.
+func SetInclude(taskfilePath string, include Include) error {
+	e, err := Open(taskfilePath)
+	if err != nil {
+		return err
+	}
+	if err := e.SetInclude(include); err != nil {
+		return err
 	}
+	return e.Save()
+}
 
-	// No version line - add at beginning
-	return "version: '3'\n\nincludes:\n" + includeEntry + "\n" + content, nil
+// RemoveInclude removes a remote taskfile include from a Taskfile.
+func RemoveInclude(taskfilePath string, namespace string) error {
+	if _, err := os.Stat(taskfilePath); err != nil {
+		return fmt.Errorf("failed to read taskfile: %w", err)
+	}
+	e, err := Open(taskfilePath)
+	if err != nil {
+		return err
+	}
+	if err := e.RemoveInclude(namespace); err != nil {
+		return err
+	}
+	return e.Save()
 }
 
-func removeIncludeFromContent(content string, namespace string) string {
-	// This is a simplified removal - handles the common case
-	// Pattern: "  namespace:\n    taskfile: ..."
-	pattern := regexp.MustCompile(`(?m)^\s+` + regexp.QuoteMeta(namespace) + `:\s*\n\s+taskfile:\s*[^\n]+\n`)
-	return pattern.ReplaceAllString(content, "")
+// HasInclude checks if a namespace is already included.
+func HasInclude(taskfilePath string, namespace string) (bool, error) {
+	if _, err := os.Stat(taskfilePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	e, err := Open(taskfilePath)
+	if err != nil {
+		return false, err
+	}
+	return e.HasInclude(namespace), nil
+}
+
+// AddTask adds a task to a Taskfile, preserving existing content and formatting.
+func AddTask(taskfilePath, name string, task Task) error {
+	e, err := Open(taskfilePath)
+	if err != nil {
+		return err
+	}
+	if err := e.AddTask(name, task); err != nil {
+		return err
+	}
+	return e.Save()
+}
+
+// SetVar sets a global var in a Taskfile, preserving existing content and formatting.
+func SetVar(taskfilePath, name, value string) error {
+	e, err := Open(taskfilePath)
+	if err != nil {
+		return err
+	}
+	if err := e.SetVar(name, value); err != nil {
+		return err
+	}
+	return e.Save()
 }
@@ -0,0 +1,164 @@
+package taskfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const editTaskfile = `# Project tasks
+version: '3'
+
+includes:
+  # Shared tooling
+  tools:
+    taskfile: ./taskfiles/tools.yml
+
+vars:
+  BIN: app # binary name
+  FLAGS:
+    sh: echo -v
+
+tasks:
+  build:
+    desc: Build
+    cmds:
+      - go build -o {{.BIN}} .
+
+  # Keep tests last
+  test:
+    cmds:
+    - go test ./...
+`
+
+func editTestFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "Taskfile.yml")
+	if content != "" {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestEditorIncludes(t *testing.T) {
+	e, err := Open(editTestFile(t, editTaskfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.AddInclude(Include{Name: "mailerlite", Taskfile: "https://example.com/Taskfile.yml"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddInclude(Include{Name: "tools", Taskfile: "./other.yml"}); err == nil {
+		t.Error("expected error adding an existing include")
+	}
+	if err := e.SetInclude(Include{Name: "tools", Taskfile: "./other.yml"}); err != nil {
+		t.Fatal(err)
+	}
+	want := `# Project tasks
+version: '3'
+
+includes:
+  # Shared tooling
+  tools:
+    taskfile: ./other.yml
+  mailerlite:
+    taskfile: https://example.com/Taskfile.yml
+`
+	if got := string(e.Bytes()); got[:len(want)] != want {
+		t.Errorf("after add/set:\n%s", got)
+	}
+
+	if err := e.RemoveInclude("tools"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RemoveInclude("mailerlite"); err != nil {
+		t.Fatal(err)
+	}
+	want = "# Project tasks\nversion: '3'\n\nvars:\n"
+	if got := string(e.Bytes()); got[:len(want)] != want {
+		t.Errorf("after removing every include:\n%s", got)
+	}
+}
+
+func TestEditorVarsAndTasks(t *testing.T) {
+	path := editTestFile(t, editTaskfile)
+	if err := SetVar(path, "BIN", "server"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVar(path, "FLAGS", "-race"); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetVar(path, "OUT", "dist/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddTask(path, "lint", Task{Desc: "Lint", Cmds: []any{"golangci-lint run"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddTask(path, "build", Task{}); err == nil {
+		t.Error("expected error adding an existing task")
+	}
+
+	got, _ := os.ReadFile(path)
+	want := `# Project tasks
+version: '3'
+
+includes:
+  # Shared tooling
+  tools:
+    taskfile: ./taskfiles/tools.yml
+
+vars:
+  BIN: server # binary name
+  FLAGS: -race
+  OUT: dist/
+
+tasks:
+  build:
+    desc: Build
+    cmds:
+      - go build -o {{.BIN}} .
+
+  # Keep tests last
+  test:
+    cmds:
+    - go test ./...
+  lint:
+    desc: Lint
+    cmds:
+      - golangci-lint run
+`
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	tf, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tf.Tasks) != 3 || tf.GetVarString("BIN") != "server" {
+		t.Errorf("parsed tasks = %v, vars = %v", tf.Tasks, tf.Vars)
+	}
+}
+
+func TestAddIncludeCreatesTaskfile(t *testing.T) {
+	path := editTestFile(t, "")
+	if err := AddInclude(path, Include{Name: "ml", Taskfile: "https://example.com/t.yml"}); err != nil {
+		t.Fatal(err)
+	}
+	if has, err := HasInclude(path, "ml"); err != nil || !has {
+		t.Errorf("HasInclude() = %v, %v", has, err)
+	}
+	if has, _ := HasInclude(path, "tasks"); has {
+		t.Error("HasInclude matched a top-level key")
+	}
+	tf, err := Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tf.Tasks["default"]; !ok || len(tf.Includes) != 1 {
+		t.Errorf("tasks = %v, includes = %v", tf.Tasks, tf.Includes)
+	}
+}
@@ -1,4 +1,4 @@
-// Package taskfile provides Taskfile parsing and validation utilities.
+// Package taskfile provides Taskfile parsing, validation and in-place editing utilities.
 package taskfile
 
 import (
@@ -0,0 +1,30 @@
+// Package taskstats records 'xplat task' run durations and reports on them.
+//
+// Every 'xplat task' run appends one record to a local JSONL file, so the
+// slowest tasks, their trend over time and the remote include cache hit rate
+// can be reviewed later. Nothing leaves the machine.
+//
+// # Components
+//
+//   - Record: One task run (tasks, duration, result, CI, remote include cache use)
+//   - Append/Load: JSONL history at ~/.xplat/cache/taskstats/runs.jsonl
+//   - Compute: Per-task count, failures, p50/p90/max and trend
+//   - RemoteCacheUse: Counts remote Taskfiles loaded from cache vs downloaded
+//
+// # Trend
+//
+// The trend compares the average duration of the newer half of a task's runs
+// in the window against the older half: +40% means the task got slower.
+//
+// # Disabling
+//
+// Set XPLAT_TASK_STATS=0 to stop recording.
+//
+// # CLI Commands
+//
+//	xplat task stats                   # Slowest tasks over the last 30 days
+//	xplat task stats --window 168h     # Last week only
+//	xplat task stats --ci              # CI runs only (where CI minutes go)
+//	xplat task stats --json            # Machine-readable summary
+//	xplat task stats --clear           # Delete recorded history
+package taskstats
@@ -0,0 +1,156 @@
+package taskstats
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/stats"
+)
+
+// Stats summarizes the runs of one task (or set of tasks run together).
+type Stats struct {
+	Name     string        `json:"name"`
+	Runs     int           `json:"runs"`
+	Failures int           `json:"failures"`
+	Total    time.Duration `json:"total"`
+	P50      time.Duration `json:"p50"`
+	P90      time.Duration `json:"p90"`
+	Max      time.Duration `json:"max"`
+	Last     time.Time     `json:"last"`
+
+	// Trend is the percent change of the newer half's average duration over
+	// the older half's. Zero with fewer than 4 runs.
+	Trend float64 `json:"trend"`
+}
+
+// Summary is the full report over a set of records.
+type Summary struct {
+	Runs             int           `json:"runs"`
+	CIRuns           int           `json:"ci_runs"`
+	Total            time.Duration `json:"total"`
+	CITotal          time.Duration `json:"ci_total"`
+	Tasks            []Stats       `json:"tasks"` // slowest total time first
+	RemoteCached     int           `json:"remote_cached"`
+	RemoteDownloaded int           `json:"remote_downloaded"`
+}
+
+// CacheHitRate returns the percent of remote Taskfile loads served from the
+// cache, or -1 if no run used remote Taskfiles.
+func (s Summary) CacheHitRate() float64 {
+	loads := s.RemoteCached + s.RemoteDownloaded
+	if loads == 0 {
+		return -1
+	}
+	return 100 * float64(s.RemoteCached) / float64(loads)
+}
+
+// Compute summarizes records, which must be in time order (as Load returns them).
+func Compute(records []Record) Summary {
+	var sum Summary
+	groups := make(map[string][]Record)
+	for _, rec := range records {
+		sum.Runs++
+		sum.Total += rec.Duration
+		if rec.CI {
+			sum.CIRuns++
+			sum.CITotal += rec.Duration
+		}
+		sum.RemoteCached += rec.RemoteCached
+		sum.RemoteDownloaded += rec.RemoteDownloaded
+		groups[rec.Name()] = append(groups[rec.Name()], rec)
+	}
+
+	for name, recs := range groups {
+		s := Stats{Name: name, Runs: len(recs)}
+		durations := make([]time.Duration, 0, len(recs))
+		for _, rec := range recs {
+			if !rec.OK {
+				s.Failures++
+			}
+			s.Total += rec.Duration
+			if rec.Time.After(s.Last) {
+				s.Last = rec.Time
+			}
+			durations = append(durations, rec.Duration)
+		}
+		s.Trend = trend(durations)
+
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		s.P50 = stats.Percentile(durations, 50)
+		s.P90 = stats.Percentile(durations, 90)
+		s.Max = durations[len(durations)-1]
+
+		sum.Tasks = append(sum.Tasks, s)
+	}
+
+	sort.Slice(sum.Tasks, func(i, j int) bool {
+		if sum.Tasks[i].Total != sum.Tasks[j].Total {
+			return sum.Tasks[i].Total > sum.Tasks[j].Total
+		}
+		return sum.Tasks[i].Name < sum.Tasks[j].Name
+	})
+	return sum
+}
+
+// trend compares the newer half of durations (in run order) to the older half.
+func trend(durations []time.Duration) float64 {
+	if len(durations) < 4 {
+		return 0
+	}
+	half := len(durations) / 2
+	older := average(durations[:half])
+	newer := average(durations[len(durations)-half:])
+	if older == 0 {
+		return 0
+	}
+	return 100 * (float64(newer) - float64(older)) / float64(older)
+}
+
+func average(durations []time.Duration) time.Duration {
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	return total / time.Duration(len(durations))
+}
+
+// Print prints the summary with at most limit tasks (0 for all).
+func Print(sum Summary, limit int) {
+	fmt.Printf("Runs: %d (%d in CI), total %s (%s in CI)\n",
+		sum.Runs, sum.CIRuns, roundDuration(sum.Total), roundDuration(sum.CITotal))
+	if rate := sum.CacheHitRate(); rate >= 0 {
+		fmt.Printf("Remote includes: %d loads, %.0f%% from cache\n", sum.RemoteCached+sum.RemoteDownloaded, rate)
+	}
+	fmt.Println()
+
+	tasks := sum.Tasks
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	fmt.Printf("%-32s %5s %6s %10s %9s %9s %9s %7s\n", "TASK", "RUNS", "FAILED", "TOTAL", "P50", "P90", "MAX", "TREND")
+	for _, s := range tasks {
+		trendStr := "-"
+		if s.Runs >= 4 {
+			trendStr = fmt.Sprintf("%+.0f%%", s.Trend)
+		}
+		fmt.Printf("%-32s %5d %6d %10s %9s %9s %9s %7s\n",
+			truncate(s.Name, 32), s.Runs, s.Failures, roundDuration(s.Total),
+			roundDuration(s.P50), roundDuration(s.P90), roundDuration(s.Max), trendStr)
+	}
+}
+
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(100 * time.Millisecond)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
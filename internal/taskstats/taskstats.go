@@ -0,0 +1,146 @@
+package taskstats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+	"github.com/joeblew999/xplat/internal/config"
+)
+
+// EnvDisable disables recording when set to "0" or "false".
+const EnvDisable = "XPLAT_TASK_STATS"
+
+// Record is one 'xplat task' run.
+type Record struct {
+	Time     time.Time     `json:"time"`
+	Tasks    []string      `json:"tasks"`
+	Dir      string        `json:"dir,omitempty"`
+	Duration time.Duration `json:"duration"`
+	OK       bool          `json:"ok"`
+	Error    string        `json:"error,omitempty"`
+	CI       bool          `json:"ci,omitempty"`
+
+	// Remote Taskfiles read from the local cache vs downloaded during setup
+	RemoteCached     int `json:"remote_cached,omitempty"`
+	RemoteDownloaded int `json:"remote_downloaded,omitempty"`
+}
+
+// Name returns the task names of the run as one key.
+func (r Record) Name() string {
+	return strings.Join(r.Tasks, " ")
+}
+
+// Enabled reports whether recording is enabled.
+func Enabled() bool {
+	v := strings.ToLower(os.Getenv(EnvDisable))
+	return v != "0" && v != "false"
+}
+
+// Path returns the history file: ~/.xplat/cache/taskstats/runs.jsonl
+func Path() string {
+	return filepath.Join(config.XplatCache(), "taskstats", "runs.jsonl")
+}
+
+// Append appends a record to the history file.
+func Append(path string, rec Record) error {
+	if err := os.MkdirAll(filepath.Dir(path), config.DefaultDirPerms); err != nil {
+		return fmt.Errorf("failed to create stats directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, config.DefaultFilePerms)
+	if err != nil {
+		return fmt.Errorf("failed to open stats: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := json.NewEncoder(f).Encode(rec); err != nil {
+		return fmt.Errorf("failed to write stats: %w", err)
+	}
+	return nil
+}
+
+// Load reads records newer than since. A missing file returns no records.
+// Malformed lines are skipped.
+func Load(path string, since time.Time) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open stats: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if rec.Time.Before(since) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	return records, nil
+}
+
+// RemoteCacheUse counts the remote Taskfiles that contributed tasks to tf and
+// how many of them were downloaded since start (the rest came from the cache).
+// remoteDir is the executor's TempDir.Remote.
+func RemoteCacheUse(tf *ast.Taskfile, remoteDir string, start time.Time) (cached, downloaded int) {
+	if tf == nil {
+		return 0, 0
+	}
+
+	remote := make(map[string]bool)
+	for _, t := range tf.Tasks.All(nil) {
+		if t.Location != nil && isRemote(t.Location.Taskfile) {
+			remote[t.Location.Taskfile] = true
+		}
+	}
+	if len(remote) == 0 {
+		return 0, 0
+	}
+
+	// Task writes a .timestamp file next to each cached Taskfile when it downloads it
+	stamps, _ := filepath.Glob(filepath.Join(remoteDir, "remote", "*.timestamp"))
+	for _, stamp := range stamps {
+		data, err := os.ReadFile(stamp)
+		if err != nil {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+		if err != nil {
+			continue
+		}
+		// Timestamps have second precision
+		if !t.Before(start.Truncate(time.Second)) {
+			downloaded++
+		}
+	}
+
+	if downloaded > len(remote) {
+		downloaded = len(remote)
+	}
+	return len(remote) - downloaded, downloaded
+}
+
+func isRemote(location string) bool {
+	for _, prefix := range []string{"https://", "http://", "git@", "git::"} {
+		if strings.HasPrefix(location, prefix) {
+			return true
+		}
+	}
+	return false
+}
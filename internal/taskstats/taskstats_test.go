@@ -0,0 +1,120 @@
+package taskstats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-task/task/v3/taskfile/ast"
+)
+
+func TestAppendLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.jsonl")
+	now := time.Now()
+
+	for _, rec := range []Record{
+		{Time: now.Add(-48 * time.Hour), Tasks: []string{"old"}, Duration: time.Second, OK: true},
+		{Time: now, Tasks: []string{"build"}, Duration: 2 * time.Second, OK: true},
+	} {
+		if err := Append(path, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := Load(path, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Name() != "build" {
+		t.Fatalf("Load() = %+v, want only build", records)
+	}
+
+	missing, err := Load(filepath.Join(t.TempDir(), "none.jsonl"), time.Time{})
+	if err != nil || missing != nil {
+		t.Fatalf("Load(missing) = %v, %v", missing, err)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	var records []Record
+	for i, d := range []int{100, 100, 200, 200} {
+		records = append(records, Record{
+			Time:     time.Unix(int64(i), 0),
+			Tasks:    []string{"test"},
+			Duration: time.Duration(d) * time.Millisecond,
+			OK:       i != 3,
+			CI:       i%2 == 0,
+		})
+	}
+	records = append(records, Record{
+		Time:             time.Unix(10, 0),
+		Tasks:            []string{"lint"},
+		Duration:         50 * time.Millisecond,
+		OK:               true,
+		RemoteCached:     3,
+		RemoteDownloaded: 1,
+	})
+
+	sum := Compute(records)
+
+	if sum.Runs != 5 || sum.CIRuns != 2 {
+		t.Errorf("runs = %d (%d CI), want 5 (2 CI)", sum.Runs, sum.CIRuns)
+	}
+	if sum.CacheHitRate() != 75 {
+		t.Errorf("CacheHitRate() = %v, want 75", sum.CacheHitRate())
+	}
+	if len(sum.Tasks) != 2 || sum.Tasks[0].Name != "test" {
+		t.Fatalf("Tasks = %+v, want test first", sum.Tasks)
+	}
+
+	test := sum.Tasks[0]
+	if test.Runs != 4 || test.Failures != 1 {
+		t.Errorf("test runs/failures = %d/%d, want 4/1", test.Runs, test.Failures)
+	}
+	if test.Total != 600*time.Millisecond || test.P50 != 100*time.Millisecond || test.Max != 200*time.Millisecond {
+		t.Errorf("test durations = total %s p50 %s max %s", test.Total, test.P50, test.Max)
+	}
+	if test.Trend != 100 {
+		t.Errorf("test trend = %v, want 100", test.Trend)
+	}
+	if sum.Tasks[1].Trend != 0 {
+		t.Errorf("lint trend = %v, want 0 with one run", sum.Tasks[1].Trend)
+	}
+
+	if (Summary{}).CacheHitRate() != -1 {
+		t.Error("CacheHitRate() without remote loads should be -1")
+	}
+}
+
+func TestRemoteCacheUse(t *testing.T) {
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote")
+	if err := os.MkdirAll(remote, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	writeStamp := func(name string, at time.Time) {
+		if err := os.WriteFile(filepath.Join(remote, name+".timestamp"), []byte(at.Format(time.RFC3339)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeStamp("a", start.Add(-time.Hour)) // cached earlier
+	writeStamp("b", start)                 // downloaded this run
+
+	tf := &ast.Taskfile{Tasks: ast.NewTasks()}
+	tf.Tasks.Set("local", &ast.Task{Task: "local", Location: &ast.Location{Taskfile: "/repo/Taskfile.yml"}})
+	tf.Tasks.Set("a:x", &ast.Task{Task: "a:x", Location: &ast.Location{Taskfile: "https://example.com/a.yml"}})
+	tf.Tasks.Set("a:y", &ast.Task{Task: "a:y", Location: &ast.Location{Taskfile: "https://example.com/a.yml"}})
+	tf.Tasks.Set("b:x", &ast.Task{Task: "b:x", Location: &ast.Location{Taskfile: "https://example.com/b.yml"}})
+
+	cached, downloaded := RemoteCacheUse(tf, dir, start)
+	if cached != 1 || downloaded != 1 {
+		t.Errorf("RemoteCacheUse() = %d cached, %d downloaded, want 1, 1", cached, downloaded)
+	}
+
+	if c, d := RemoteCacheUse(nil, dir, start); c != 0 || d != 0 {
+		t.Errorf("RemoteCacheUse(nil) = %d, %d", c, d)
+	}
+}
@@ -7,6 +7,8 @@
 //   - action.yml.tmpl - GitHub Actions setup action
 //   - readme.xplat.md.tmpl - xplat's own README
 //   - taskfile.xplat.yml.tmpl - xplat's own Taskfile
+//   - homebrew.rb.tmpl - Homebrew formula for the tap
+//   - scoop.json.tmpl - Scoop manifest for the bucket
 //
 // 2. project/ - Templates for user projects (xplat gen *)
 //   - ci.yml.tmpl - GitHub Actions CI workflow
@@ -17,6 +19,13 @@
 //   - taskfile.generated.yml.tmpl - Generated taskfile with remote includes
 //   - process.generated.yml.tmpl - Generated process-compose file
 //   - service.taskfile.yml.tmpl - Service taskfile for packages
+//   - dependabot.yml.tmpl - Dependabot config for detected ecosystems
+//   - codeowners.tmpl - CODEOWNERS from manifest author and maintainers
+//   - bug_report.md.tmpl, feature_request.md.tmpl - Issue templates
+//   - pull_request_template.md.tmpl - PR template with the repo's task checklist
+//   - license_<spdx-id>.tmpl - LICENSE for MIT, Apache-2.0 and BSD-3-Clause
+//   - security.md.tmpl - SECURITY.md vulnerability reporting policy
+//   - wrangler.toml.tmpl - Cloudflare Worker config for the worker archetype
 //
 // All templates use values from internal/config/config.go as the source of truth.
 package templates
@@ -114,6 +123,25 @@ type InstallData struct {
 	StaleLocations    []string // Locations to clean up
 }
 
+// PackageManifestData holds values for homebrew.rb and scoop.json templates.
+type PackageManifestData struct {
+	Version      string                   // 0.3.8 (no "v", as package managers expect)
+	Tag          string                   // xplat-v0.3.8
+	Repo         string                   // joeblew999/xplat
+	TagPrefix    string                   // xplat-
+	BinaryName   string                   // xplat
+	Description  string                   // One-line package description
+	FormulaClass string                   // Xplat
+	TapName      string                   // joeblew999/tap
+	Assets       map[string]ManifestAsset // Keyed by "{os}-{arch}"
+}
+
+// ManifestAsset is a release binary referenced from a package manifest.
+type ManifestAsset struct {
+	URL    string
+	SHA256 string
+}
+
 // XplatReadmeData holds values for xplat's own README.md template.
 type XplatReadmeData struct {
 	Categories  []CommandCategory
@@ -231,6 +259,8 @@ type TaskfileData struct {
 	MainPath       string
 	HasTests       bool
 	Language       string // "go" or "rust"
+	Archetype      string // Bootstrap archetype: go-service, hugo-site, worker, cli (empty for plain projects)
+	Port           int    // Default PORT var for run/dev (hugo-site and worker)
 	RunArgs        string // Arguments for user-facing run (e.g., "edit" for polyform)
 	ServiceRunArgs string // Arguments for service/daemon mode (e.g., "edit -launch-browser=false")
 
@@ -240,6 +270,11 @@ type TaskfileData struct {
 	SourceVersion  string // Tag/branch to checkout (e.g., "v0.35.0")
 }
 
+// WranglerData holds values for wrangler.toml template.
+type WranglerData struct {
+	Name string // Worker name
+}
+
 // ReadmeData holds values for readme.md template.
 type ReadmeData struct {
 	Name        string
@@ -313,3 +348,29 @@ type ServiceExtraVar struct {
 	Name    string
 	Default string
 }
+
+// DependabotData holds values for dependabot.yml template.
+type DependabotData struct {
+	GoModDirs     []string // directories containing go.mod ("/" for root)
+	NPMDirs       []string // directories containing package.json
+	GitHubActions bool     // true if .github/workflows exists
+	UntrackedPins []string // xplat.yaml pins Dependabot cannot update (listed as comments)
+}
+
+// GovernanceData holds values for the CODEOWNERS, issue and PR templates.
+type GovernanceData struct {
+	Name   string   // Project name
+	Owners []string // Code owners (e.g., "@joeblew999", "@org/team")
+	Tasks  []string // Tasks a reporter or contributor should run (e.g., "build", "test")
+}
+
+// CommunityData holds values for the LICENSE and SECURITY.md templates.
+type CommunityData struct {
+	Name        string // Project name
+	Holder      string // Copyright holder
+	Year        int    // Copyright year
+	Supported   string // Supported versions paragraph
+	Contact     string // Where to report vulnerabilities (e.g., "security@example.com")
+	ContactURL  string // Link for Contact (mailto: or URL), empty to use AdvisoryURL
+	AdvisoryURL string // GitHub private vulnerability reporting URL
+}
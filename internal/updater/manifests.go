@@ -0,0 +1,161 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v81/github"
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/templates"
+)
+
+// manifestPlatforms are the release binaries referenced from package manifests.
+var manifestPlatforms = []string{
+	"darwin-amd64", "darwin-arm64",
+	"linux-amd64", "linux-arm64",
+	"windows-amd64", "windows-arm64",
+}
+
+// GetReleaseByTag fetches a release by tag (e.g. "xplat-v0.3.8").
+// An empty tag fetches the latest release.
+func GetReleaseByTag(ctx context.Context, tag string) (*Release, error) {
+	if tag == "" {
+		return GetLatestRelease(ctx)
+	}
+
+	url := "https://api.github.com/repos/" + config.XplatRepo + "/releases/tags/" + tag
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned %d for release %s", resp.StatusCode, tag)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release info: %w", err)
+	}
+
+	return &release, nil
+}
+
+// NewManifestData builds Homebrew and Scoop template data from a release and
+// its checksums (filename -> sha256). Every binary the manifests reference
+// must be in the release and have a checksum.
+func NewManifestData(release *Release, checksums map[string]string) (templates.PackageManifestData, error) {
+	data := templates.PackageManifestData{
+		Version:      strings.TrimPrefix(ParseVersion(release.TagName), "v"),
+		Tag:          release.TagName,
+		Repo:         config.XplatRepo,
+		TagPrefix:    config.XplatTagPrefix,
+		BinaryName:   "xplat",
+		Description:  config.XplatDescription,
+		FormulaClass: "Xplat",
+		TapName:      tapName(config.XplatHomebrewTap),
+		Assets:       make(map[string]templates.ManifestAsset),
+	}
+
+	urls := make(map[string]string)
+	for _, asset := range release.Assets {
+		urls[asset.Name] = asset.BrowserDownloadURL
+	}
+
+	var missing []string
+	for _, platform := range manifestPlatforms {
+		name := "xplat-" + platform
+		if strings.HasPrefix(platform, "windows-") {
+			name += ".exe"
+		}
+		url, sum := urls[name], checksums[name]
+		if url == "" || sum == "" {
+			missing = append(missing, name)
+			continue
+		}
+		data.Assets[platform] = templates.ManifestAsset{URL: url, SHA256: sum}
+	}
+	if len(missing) > 0 {
+		return data, fmt.Errorf("release %s is missing binaries or checksums: %s", release.TagName, strings.Join(missing, ", "))
+	}
+
+	return data, nil
+}
+
+// RenderManifests renders the Homebrew formula and Scoop manifest.
+func RenderManifests(data templates.PackageManifestData) (formula, scoop []byte, err error) {
+	formula, err = templates.RenderXplat("homebrew.rb.tmpl", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render Homebrew formula: %w", err)
+	}
+	scoop, err = templates.RenderXplat("scoop.json.tmpl", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render Scoop manifest: %w", err)
+	}
+	if !json.Valid(scoop) {
+		return nil, nil, fmt.Errorf("rendered Scoop manifest is not valid JSON")
+	}
+	return formula, scoop, nil
+}
+
+// PublishFile creates or updates path in a GitHub repository ("owner/name")
+// via the contents API. Returns false without committing when the file
+// already has the given content.
+func PublishFile(ctx context.Context, client *github.Client, repo, path string, content []byte, message string) (bool, error) {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" {
+		return false, fmt.Errorf("invalid repository %q (expected owner/name)", repo)
+	}
+
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.Ptr(message),
+		Content: content,
+	}
+
+	existing, _, _, err := client.Repositories.GetContents(ctx, owner, name, path, nil)
+	var ghErr *github.ErrorResponse
+	switch {
+	case err == nil && existing != nil:
+		current, err := existing.GetContent()
+		if err != nil {
+			return false, fmt.Errorf("failed to decode %s in %s: %w", path, repo, err)
+		}
+		if current == string(content) {
+			return false, nil
+		}
+		opts.SHA = existing.SHA
+		if _, _, err := client.Repositories.UpdateFile(ctx, owner, name, path, opts); err != nil {
+			return false, fmt.Errorf("failed to update %s in %s: %w", path, repo, err)
+		}
+	case errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound:
+		if _, _, err := client.Repositories.CreateFile(ctx, owner, name, path, opts); err != nil {
+			return false, fmt.Errorf("failed to create %s in %s: %w", path, repo, err)
+		}
+	default:
+		if err == nil {
+			err = fmt.Errorf("path is a directory")
+		}
+		return false, fmt.Errorf("failed to read %s in %s: %w", path, repo, err)
+	}
+
+	return true, nil
+}
+
+// tapName converts a tap repository ("owner/homebrew-tap") to the name used
+// by brew tap ("owner/tap").
+func tapName(repo string) string {
+	owner, name, _ := strings.Cut(repo, "/")
+	return owner + "/" + strings.TrimPrefix(name, "homebrew-")
+}
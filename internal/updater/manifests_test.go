@@ -0,0 +1,143 @@
+package updater
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v81/github"
+)
+
+func testRelease() (*Release, map[string]string) {
+	release := &Release{TagName: "xplat-v0.3.8"}
+	checksums := make(map[string]string)
+	for _, p := range manifestPlatforms {
+		name := "xplat-" + p
+		if strings.HasPrefix(p, "windows-") {
+			name += ".exe"
+		}
+		release.Assets = append(release.Assets, struct {
+			Name               string `json:"name"`
+			BrowserDownloadURL string `json:"browser_download_url"`
+		}{name, "https://example.com/" + name})
+		checksums[name] = "sum-" + p
+	}
+	return release, checksums
+}
+
+func TestRenderManifests(t *testing.T) {
+	release, checksums := testRelease()
+	data, err := NewManifestData(release, checksums)
+	if err != nil {
+		t.Fatalf("NewManifestData failed: %v", err)
+	}
+	if data.Version != "0.3.8" || data.TapName != "joeblew999/tap" {
+		t.Errorf("version = %q, tap = %q", data.Version, data.TapName)
+	}
+
+	formula, scoop, err := RenderManifests(data)
+	if err != nil {
+		t.Fatalf("RenderManifests failed: %v", err)
+	}
+	for _, want := range []string{
+		`version "0.3.8"`,
+		`url "https://example.com/xplat-darwin-arm64"`,
+		`sha256 "sum-linux-amd64"`,
+		`bin.install Dir["xplat-*"].first => "xplat"`,
+	} {
+		if !strings.Contains(string(formula), want) {
+			t.Errorf("formula missing %q:\n%s", want, formula)
+		}
+	}
+
+	var manifest struct {
+		Version      string `json:"version"`
+		Architecture map[string]struct {
+			URL  string `json:"url"`
+			Hash string `json:"hash"`
+		} `json:"architecture"`
+	}
+	if err := json.Unmarshal(scoop, &manifest); err != nil {
+		t.Fatalf("scoop manifest is not JSON: %v\n%s", err, scoop)
+	}
+	if manifest.Version != "0.3.8" || manifest.Architecture["64bit"].Hash != "sum-windows-amd64" ||
+		manifest.Architecture["arm64"].URL != "https://example.com/xplat-windows-arm64.exe#/xplat.exe" {
+		t.Errorf("scoop manifest = %+v", manifest)
+	}
+
+	delete(checksums, "xplat-linux-arm64")
+	if _, err := NewManifestData(release, checksums); err == nil || !strings.Contains(err.Error(), "xplat-linux-arm64") {
+		t.Errorf("expected missing checksum error, got %v", err)
+	}
+}
+
+func TestPublishFile(t *testing.T) {
+	files := map[string]string{"Formula/xplat.rb": "old"}
+	var writes int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/tap/contents/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/repos/o/tap/contents/")
+		switch r.Method {
+		case http.MethodGet:
+			content, ok := files[path]
+			if !ok {
+				http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{
+				"type": "file", "encoding": "base64", "sha": "abc",
+				"content": base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		case http.MethodPut:
+			var body struct {
+				Content []byte `json:"content"`
+				SHA     string `json:"sha"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if _, exists := files[path]; exists && body.SHA != "abc" {
+				http.Error(w, `{"message":"sha mismatch"}`, http.StatusConflict)
+				return
+			}
+			files[path] = string(body.Content)
+			writes++
+			json.NewEncoder(w).Encode(map[string]any{})
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+	ctx := context.Background()
+
+	tests := []struct {
+		path, content string
+		wantChanged   bool
+	}{
+		{"Formula/xplat.rb", "new", true},  // update
+		{"Formula/xplat.rb", "new", false}, // unchanged
+		{"bucket/xplat.json", "{}", true},  // create
+	}
+	for _, tt := range tests {
+		changed, err := PublishFile(ctx, client, "o/tap", tt.path, []byte(tt.content), "xplat 0.3.8")
+		if err != nil {
+			t.Fatalf("PublishFile(%s) failed: %v", tt.path, err)
+		}
+		if changed != tt.wantChanged || files[tt.path] != tt.content {
+			t.Errorf("PublishFile(%s) changed = %v, content = %q", tt.path, changed, files[tt.path])
+		}
+	}
+	if writes != 2 {
+		t.Errorf("writes = %d, want 2", writes)
+	}
+
+	if _, err := PublishFile(ctx, client, "notarepo", "x", nil, "m"); err == nil {
+		t.Error("expected error for invalid repository")
+	}
+}
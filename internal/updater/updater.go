@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/platform"
 )
 
 // Use config constants for updater settings.
@@ -71,24 +72,56 @@ func ParseVersion(tagName string) string {
 	return strings.TrimPrefix(tagName, config.XplatTagPrefix)
 }
 
+// currentPlatform is platform.Current, replaced in tests.
+var currentPlatform = platform.Current
+
 // GetAssetName returns the expected asset name for the current platform.
+// It uses the machine's native arch, so an xplat running under Rosetta 2 or
+// Windows x64 emulation updates itself to the native arm64 build.
 func GetAssetName() string {
+	plat := currentPlatform()
+	return assetName(plat.OS, plat.Arch)
+}
+
+// assetName returns the xplat asset name for goos/goarch.
+func assetName(goos, goarch string) string {
 	ext := ""
-	if runtime.GOOS == "windows" {
+	if goos == "windows" {
 		ext = ".exe"
 	}
-	return fmt.Sprintf("xplat-%s-%s%s", runtime.GOOS, runtime.GOARCH, ext)
+	return fmt.Sprintf("xplat-%s-%s%s", goos, goarch, ext)
 }
 
-// FindAssetURL finds the download URL for the current platform in a release.
-func FindAssetURL(release *Release) (string, error) {
-	assetName := GetAssetName()
-	for _, asset := range release.Assets {
-		if asset.Name == assetName {
-			return asset.BrowserDownloadURL, nil
+// FindAsset finds the asset for the current platform in a release: the
+// native build, or else one the OS runs under emulation (amd64 on Apple
+// silicon and Windows ARM64). It returns the asset name and download URL.
+func FindAsset(release *Release) (string, string, error) {
+	plat := currentPlatform()
+	for _, arch := range plat.Archs() {
+		name := assetName(plat.OS, arch)
+		for _, asset := range release.Assets {
+			if asset.Name == name {
+				return name, asset.BrowserDownloadURL, nil
+			}
 		}
 	}
-	return "", fmt.Errorf("no asset found for %s", assetName)
+	return "", "", fmt.Errorf("no asset found for %s", GetAssetName())
+}
+
+// NativeAvailable reports whether xplat runs under emulation and the
+// release has a native build to replace it with, even at the same version.
+func NativeAvailable(release *Release) bool {
+	if !currentPlatform().Emulated() {
+		return false
+	}
+	name, _, err := FindAsset(release)
+	return err == nil && name == GetAssetName()
+}
+
+// FindAssetURL finds the download URL for the current platform in a release.
+func FindAssetURL(release *Release) (string, error) {
+	_, url, err := FindAsset(release)
+	return url, err
 }
 
 // FindChecksumURL finds the checksum file URL in a release.
@@ -155,7 +188,11 @@ func GetExpectedChecksum(ctx context.Context, release *Release) string {
 	if err != nil {
 		return ""
 	}
-	return checksums[GetAssetName()]
+	name, _, err := FindAsset(release)
+	if err != nil {
+		return ""
+	}
+	return checksums[name]
 }
 
 // DownloadAndReplace downloads a new binary and replaces the current one.
@@ -274,7 +311,7 @@ func Update(ctx context.Context, currentVersion string, force bool) (newVersion
 
 	latestVersion := ParseVersion(release.TagName)
 
-	if !force && !NeedsUpdate(currentVersion, latestVersion) && currentVersion == latestVersion {
+	if !force && !NeedsUpdate(currentVersion, latestVersion) && currentVersion == latestVersion && !NativeAvailable(release) {
 		return latestVersion, nil // Already up to date
 	}
 
@@ -0,0 +1,49 @@
+package updater
+
+import (
+	"testing"
+
+	"github.com/joeblew999/xplat/internal/platform"
+)
+
+func TestFindAsset(t *testing.T) {
+	defer func(f func() platform.Platform) { currentPlatform = f }(currentPlatform)
+	full, _ := testRelease()
+	amd64Only := &Release{TagName: full.TagName}
+	for _, a := range full.Assets {
+		if a.Name != "xplat-darwin-arm64" && a.Name != "xplat-windows-arm64.exe" {
+			amd64Only.Assets = append(amd64Only.Assets, a)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		plat     platform.Platform
+		release  *Release
+		want     string
+		wantErr  bool
+		switchTo bool // NativeAvailable
+	}{
+		{"native", platform.Platform{OS: "linux", Arch: "arm64", ProcessArch: "arm64"}, full, "xplat-linux-arm64", false, false},
+		{"rosetta gets arm64", platform.Platform{OS: "darwin", Arch: "arm64", ProcessArch: "amd64"}, full, "xplat-darwin-arm64", false, true},
+		{"windows arm64 emulated", platform.Platform{OS: "windows", Arch: "arm64", ProcessArch: "amd64"}, full, "xplat-windows-arm64.exe", false, true},
+		{"rosetta fallback", platform.Platform{OS: "darwin", Arch: "arm64", ProcessArch: "amd64"}, amd64Only, "xplat-darwin-amd64", false, false},
+		{"windows arm64 fallback", platform.Platform{OS: "windows", Arch: "arm64", ProcessArch: "arm64"}, amd64Only, "xplat-windows-amd64.exe", false, false},
+		{"linux arm64 no fallback", platform.Platform{OS: "linux", Arch: "arm64", ProcessArch: "arm64"}, &Release{}, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentPlatform = func() platform.Platform { return tt.plat }
+			name, url, err := FindAsset(tt.release)
+			if (err != nil) != tt.wantErr || name != tt.want {
+				t.Fatalf("FindAsset() = %q, %v, want %q", name, err, tt.want)
+			}
+			if err == nil && url != "https://example.com/"+name {
+				t.Errorf("url = %q", url)
+			}
+			if got := NativeAvailable(tt.release); got != tt.switchTo {
+				t.Errorf("NativeAvailable() = %v, want %v", got, tt.switchTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package web
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/go-via/via/h"
+)
+
+// Task output is run with FORCE_COLOR=1, so it carries ANSI escape
+// sequences. ansiToHTML turns their SGR colors and styles into spans for the
+// output panes, which are dark whatever the UI theme.
+
+// ansiPalette is the 16-color palette, normal then bright (as VS Code's
+// terminal, readable on the panes' #1e1e1e background).
+var ansiPalette = [16]string{
+	"#000000", "#cd3131", "#0dbc79", "#e5e510", "#2472c8", "#bc3fbc", "#11a8cd", "#e5e5e5",
+	"#666666", "#f14c4c", "#23d18b", "#f5f543", "#3b8eea", "#d670d6", "#29b8db", "#ffffff",
+}
+
+// ansiStyle is the SGR state of the text being converted.
+type ansiStyle struct {
+	fg, bg                       string
+	bold, dim, italic, underline bool
+}
+
+// css returns the inline style of the state, "" for plain text.
+func (st ansiStyle) css() string {
+	var b strings.Builder
+	if st.fg != "" {
+		b.WriteString("color:" + st.fg + ";")
+	}
+	if st.bg != "" {
+		b.WriteString("background-color:" + st.bg + ";")
+	}
+	if st.bold {
+		b.WriteString("font-weight:bold;")
+	}
+	if st.dim {
+		b.WriteString("opacity:0.7;")
+	}
+	if st.italic {
+		b.WriteString("font-style:italic;")
+	}
+	if st.underline {
+		b.WriteString("text-decoration:underline;")
+	}
+	return b.String()
+}
+
+// ansiOutput renders terminal output for an output pane.
+func ansiOutput(s string) h.H {
+	return h.Raw(ansiToHTML(s))
+}
+
+// ansiToHTML converts terminal output to HTML: text is escaped, SGR
+// sequences (colors, bold, dim, italic, underline) become styled spans, and
+// other escape sequences (cursor movement, window titles) are dropped. A
+// carriage return within a line overwrites it, as progress bars do.
+func ansiToHTML(s string) string {
+	s = overwriteLines(s)
+
+	var b strings.Builder
+	var st ansiStyle
+	open := false
+	text := func(t string) {
+		if t == "" {
+			return
+		}
+		if css := st.css(); css != "" && !open {
+			b.WriteString(`<span style="` + css + `">`)
+			open = true
+		}
+		b.WriteString(html.EscapeString(t))
+	}
+	restyle := func(params string) {
+		next := st.apply(params)
+		if next == st {
+			return
+		}
+		if open {
+			b.WriteString("</span>")
+			open = false
+		}
+		st = next
+	}
+
+	for {
+		i := strings.IndexByte(s, 0x1b)
+		if i < 0 {
+			text(s)
+			break
+		}
+		text(s[:i])
+		s = s[i+1:]
+		if s == "" {
+			break
+		}
+
+		switch s[0] {
+		case '[': // CSI: parameters, then a final byte in @-~
+			end := 1
+			for end < len(s) && (s[end] < 0x40 || s[end] > 0x7e) {
+				end++
+			}
+			if end == len(s) {
+				s = ""
+				continue
+			}
+			if s[end] == 'm' {
+				restyle(s[1:end])
+			}
+			s = s[end+1:]
+		case ']': // OSC: up to BEL or ESC \
+			end := strings.IndexAny(s, "\a\x1b")
+			switch {
+			case end < 0:
+				s = ""
+			case s[end] == 0x1b && end+1 < len(s) && s[end+1] == '\\':
+				s = s[end+2:]
+			default:
+				s = s[end+1:]
+			}
+		default: // Two-byte sequence
+			s = s[1:]
+		}
+	}
+	if open {
+		b.WriteString("</span>")
+	}
+	return b.String()
+}
+
+// apply returns the state after an SGR sequence's parameters.
+func (st ansiStyle) apply(params string) ansiStyle {
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			code = 0 // An empty parameter resets
+		}
+		switch {
+		case code == 0:
+			st = ansiStyle{}
+		case code == 1:
+			st.bold = true
+		case code == 2:
+			st.dim = true
+		case code == 3:
+			st.italic = true
+		case code == 4:
+			st.underline = true
+		case code == 22:
+			st.bold, st.dim = false, false
+		case code == 23:
+			st.italic = false
+		case code == 24:
+			st.underline = false
+		case code >= 30 && code <= 37:
+			st.fg = ansiPalette[code-30]
+		case code >= 90 && code <= 97:
+			st.fg = ansiPalette[code-90+8]
+		case code == 39:
+			st.fg = ""
+		case code >= 40 && code <= 47:
+			st.bg = ansiPalette[code-40]
+		case code >= 100 && code <= 107:
+			st.bg = ansiPalette[code-100+8]
+		case code == 49:
+			st.bg = ""
+		case code == 38 || code == 48:
+			color, n := ansiExtendedColor(codes[i+1:])
+			i += n
+			if color != "" && code == 38 {
+				st.fg = color
+			} else if color != "" {
+				st.bg = color
+			}
+		}
+	}
+	return st
+}
+
+// ansiExtendedColor parses the parameters after 38 or 48: "5;n" (256
+// colors) or "2;r;g;b" (truecolor). It returns the color and the number of
+// parameters used.
+func ansiExtendedColor(params []string) (string, int) {
+	num := func(i int) int {
+		if i >= len(params) {
+			return -1
+		}
+		n, err := strconv.Atoi(params[i])
+		if err != nil || n < 0 || n > 255 {
+			return -1
+		}
+		return n
+	}
+	switch num(0) {
+	case 5:
+		n := num(1)
+		if n < 0 {
+			return "", len(params)
+		}
+		return ansi256(n), 2
+	case 2:
+		r, g, b := num(1), num(2), num(3)
+		if r < 0 || g < 0 || b < 0 {
+			return "", len(params)
+		}
+		return fmt.Sprintf("#%02x%02x%02x", r, g, b), 4
+	}
+	return "", len(params)
+}
+
+// ansi256 returns color n of the 256-color palette: the 16 colors, a
+// 6x6x6 cube, then 24 grays.
+func ansi256(n int) string {
+	switch {
+	case n < 16:
+		return ansiPalette[n]
+	case n < 232:
+		levels := [6]int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02x%02x%02x", levels[n/36], levels[n/6%6], levels[n%6])
+	}
+	gray := 8 + (n-232)*10
+	return fmt.Sprintf("#%02x%02x%02x", gray, gray, gray)
+}
+
+// overwriteLines keeps what follows the last carriage return of each line,
+// which is what a terminal shows.
+func overwriteLines(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		line = strings.TrimRight(line, "\r")
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			line = line[j+1:]
+		}
+		lines[i] = line
+	}
+	return strings.Join(lines, "\n")
+}
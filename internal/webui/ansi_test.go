@@ -0,0 +1,34 @@
+package web
+
+import "testing"
+
+func TestANSIToHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "task: [build] go build <./...>", "task: [build] go build &lt;./...&gt;"},
+		{"color and reset", "\x1b[32mok\x1b[0m done", `<span style="color:#0dbc79;">ok</span> done`},
+		{"empty reset", "\x1b[31mred\x1b[m plain", `<span style="color:#cd3131;">red</span> plain`},
+		{"bold bright", "\x1b[1;91mFAIL\x1b[22;39m", `<span style="color:#f14c4c;font-weight:bold;">FAIL</span>`},
+		{"background", "\x1b[41;97m ERR \x1b[49m x\x1b[0m", `<span style="color:#ffffff;background-color:#cd3131;"> ERR </span><span style="color:#ffffff;"> x</span>`},
+		{"256 colors", "\x1b[38;5;208mwarn\x1b[0m", `<span style="color:#ff8700;">warn</span>`},
+		{"256 gray", "\x1b[48;5;240mx\x1b[0m", `<span style="background-color:#585858;">x</span>`},
+		{"truecolor", "\x1b[38;2;255;128;0mx\x1b[0m", `<span style="color:#ff8000;">x</span>`},
+		{"unchanged style keeps span", "\x1b[33ma\x1b[33mb\x1b[0m", `<span style="color:#e5e510;">ab</span>`},
+		{"cursor and erase dropped", "\x1b[2K\x1b[1Gline", "line"},
+		{"osc title dropped", "\x1b]0;title\x07text", "text"},
+		{"osc st dropped", "\x1b]8;;https://x\x1b\\link\x1b]8;;\x1b\\", "link"},
+		{"carriage return overwrites", "10%\r50%\r100%\r\nnext", "100%\nnext"},
+		{"unterminated", "text\x1b[3", "text"},
+		{"span closed at end", "\x1b[4mopen", `<span style="text-decoration:underline;">open</span>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ansiToHTML(tt.in); got != tt.want {
+				t.Errorf("ansiToHTML(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -12,13 +12,26 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 
 	"github.com/go-via/via"
 	"github.com/go-via/via-plugin-picocss/picocss"
 	"github.com/go-via/via/h"
 
+	"github.com/joeblew999/xplat/internal/audit"
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/env"
+	envweb "github.com/joeblew999/xplat/internal/env/web"
+	"github.com/joeblew999/xplat/internal/sitecheck"
+)
+
+// Caddy registry entry of the UI. It is separate from the standalone setup
+// wizard's via-gui entry at /admin/*, so both can be registered at once.
+const (
+	caddyServiceName = "xplat-ui"
+	caddyPathPrefix  = "/xplat"
 )
 
 // AppConfig holds the unified web application configuration.
@@ -32,6 +45,12 @@ type AppConfig struct {
 	EnableTasks        bool   // Enable task UI routes
 	EnableProcesses    bool   // Enable process view routes
 	MockMode           bool   // Mock mode for setup wizard
+	AuditLog           string // Audit log path (default AuditLogPath(WorkDir))
+	EnvReload          string // Act on .env changes: EnvReloadRestart, EnvReloadSighup or "" (off)
+	AuthToken          string // Require this token (Bearer header, basic auth password or sign-in page)
+	BasicAuth          string // Require these "user:password" basic auth credentials
+	ReadOnly           bool   // Browse only: no task runs, process actions or setup wizard
+	IdentityHeader     string // Trust this header from a proxy on this host as the user (e.g. Cf-Access-Authenticated-User-Email)
 }
 
 // DefaultAppConfig returns sensible defaults with all features enabled.
@@ -55,6 +74,15 @@ type App struct {
 	via      *via.V
 	tasks    []TaskInfo
 	pcClient *ProcessComposeClient
+	audit    *audit.Log
+	operator string // Who Via actions are attributed to
+	basePath string // Path prefix of links, caddyPathPrefix when registered with Caddy
+
+	runs          *RunStore        // Task runs started from the UI
+	history       *History         // Persisted task runs (tasks enabled only)
+	schedules     *Scheduler       // Scheduled task runs (tasks enabled only)
+	notifications *notificationHub // Desktop notifications for opted-in browsers
+	envReload     envReloadState   // Latest .env reload (EnvReload mode only)
 }
 
 // NewApp creates a new unified web application.
@@ -67,8 +95,26 @@ func NewApp(cfg AppConfig) (*App, error) {
 		cfg.WorkDir = wd
 	}
 
+	if cfg.BasicAuth != "" {
+		if user, password, ok := strings.Cut(cfg.BasicAuth, ":"); !ok || user == "" || password == "" {
+			return nil, fmt.Errorf("basic auth must be user:password")
+		}
+	}
+
+	if cfg.AuditLog == "" {
+		cfg.AuditLog = AuditLogPath(cfg.WorkDir)
+	}
+
 	app := &App{
-		config: cfg,
+		config:        cfg,
+		audit:         audit.Open(cfg.AuditLog),
+		operator:      operatorName(),
+		runs:          &RunStore{},
+		notifications: newNotificationHub(),
+	}
+	app.runs.OnFinish = func(run TaskRun) {
+		app.notifications.Publish(runNotification(run))
+		app.saveHistory(run)
 	}
 
 	// Parse taskfile if tasks are enabled
@@ -79,6 +125,12 @@ func NewApp(cfg AppConfig) (*App, error) {
 			tasks = []TaskInfo{}
 		}
 		app.tasks = tasks
+		app.history = &History{Dir: HistoryDir()}
+		app.schedules = &Scheduler{
+			Store: &ScheduleStore{Path: SchedulesPath()},
+			Dir:   cfg.WorkDir,
+			Run:   app.runScheduled,
+		}
 	}
 
 	// Create process-compose client if processes are enabled
@@ -95,9 +147,16 @@ func (app *App) Start(ctx context.Context) error {
 	log.Printf("xplat UI listening on %s\n", url)
 
 	if app.config.OpenBrowser {
-		go func() { _ = openBrowser(url) }()
+		openURL := url
+		if app.config.AuthToken != "" {
+			openURL += "/?token=" + app.config.AuthToken // Signs this browser in
+		}
+		go func() { _ = openBrowser(openURL) }()
 	}
 
+	// Share the Caddy service registry with the standalone setup wizard
+	registered := app.config.EnableSetup && app.registerWithCaddy()
+
 	// Setup signal handler for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -105,11 +164,30 @@ func (app *App) Start(ctx context.Context) error {
 		select {
 		case <-sigChan:
 			log.Println("\nShutting down...")
-			os.Exit(0)
 		case <-ctx.Done():
-			os.Exit(0)
 		}
+		if registered {
+			if err := env.UnregisterService(caddyServiceName); err != nil {
+				log.Printf("Warning: Failed to unregister from Caddy: %v", err)
+			}
+		}
+		os.Exit(0)
+	}()
+
+	// Via listens on loopback behind the guard (auth, CSRF, read-only)
+	viaAddr, err := loopbackAddr()
+	if err != nil {
+		return err
+	}
+	go func() {
+		log.Fatalf("xplat UI: %v", http.ListenAndServe(":"+app.config.Port, proxyTo(app.config, viaAddr)))
 	}()
+	if app.config.AuthToken != "" || app.config.BasicAuth != "" {
+		log.Printf("UI requires authentication")
+	}
+	if app.config.ReadOnly {
+		log.Printf("UI is read-only")
+	}
 
 	// Create and configure Via instance
 	app.via = via.New()
@@ -118,12 +196,24 @@ func (app *App) Start(ctx context.Context) error {
 		Plugins:       []via.Plugin{picocss.Default},
 		DevMode:       os.Getenv("VIA_DEV_MODE") != "false",
 		LogLvl:        via.LogLevelWarn,
-		ServerAddress: ":" + app.config.Port,
+		ServerAddress: viaAddr,
 	})
+	app.via.AppendToHead(h.Script(h.Raw(themeHeadScript)))
+	app.via.AppendToFoot(h.Script(h.Raw(notifyScript)), h.Script(h.Raw(csrfScript)), h.Script(h.Raw(themeScript)))
 
 	// Register routes based on enabled features
 	app.registerRoutes()
 
+	if app.config.EnableTasks && !app.config.ReadOnly {
+		go app.schedules.Start(ctx)
+	}
+	if app.config.EnableProcesses {
+		go app.watchProcesses(ctx)
+		if app.config.EnvReload != "" {
+			go app.watchEnv(ctx)
+		}
+	}
+
 	// Start the server
 	app.via.Start()
 	return nil
@@ -147,19 +237,49 @@ func (app *App) registerRoutes() {
 			})
 		})
 
+		// Include tree with the remote include cache state
+		app.via.Page("/includes", func(c *via.Context) {
+			app.viaIncludesPage(c)
+		})
+		app.via.HandleFunc("GET /api/includes", app.handleIncludeGraph)
+		app.via.HandleFunc("POST /api/includes/invalidate", app.handleIncludeInvalidate)
+
 		// Register each task as a separate route
 		for _, task := range app.tasks {
-			taskName := task.Name
-			taskDesc := task.Description
-			app.via.Page("/tasks/"+taskName, func(c *via.Context) {
-				viaTaskExecutionPage(c, taskName, taskDesc, app.tasks, ViaConfig{
+			app.via.Page("/tasks/"+task.Name, func(c *via.Context) {
+				viaTaskExecutionPage(c, task, app.tasks, ViaConfig{
 					Port:               app.config.Port,
 					Taskfile:           app.config.Taskfile,
 					WorkDir:            app.config.WorkDir,
 					ProcessComposePort: app.config.ProcessComposePort,
+					Audit: func(action, target string, err error) {
+						app.record(app.operator, action, target, err)
+					},
+					Runs:     app.runs,
+					ReadOnly: app.config.ReadOnly,
 				})
 			})
 		}
+
+		// Output of a run, linked from its desktop notification
+		app.via.Page("/runs/{id}", func(c *via.Context) {
+			app.viaRunPage(c)
+		})
+
+		// Persisted runs, searchable and re-runnable after the UI restarts
+		app.via.Page("/history", func(c *via.Context) {
+			app.viaHistoryPage(c)
+		})
+		app.via.Page("/history/{id}", func(c *via.Context) {
+			app.viaHistoryRunPage(c)
+		})
+		app.via.HandleFunc("POST /api/history/{id}/rerun", app.handleHistoryRerun)
+
+		// Scheduled task runs
+		app.via.Page("/schedules", func(c *via.Context) {
+			app.viaSchedulesPage(c)
+		})
+		app.via.HandleFunc("POST /api/schedules/{id}/{action}", app.handleScheduleAction)
 	}
 
 	// Process routes
@@ -170,6 +290,8 @@ func (app *App) registerRoutes() {
 				Taskfile:           app.config.Taskfile,
 				WorkDir:            app.config.WorkDir,
 				ProcessComposePort: app.config.ProcessComposePort,
+				EnvReload:          app.envReload.Last,
+				ReadOnly:           app.config.ReadOnly,
 			})
 		})
 
@@ -191,6 +313,10 @@ func (app *App) registerRoutes() {
 			_ = json.NewEncoder(w).Encode(map[string]string{"logs": logs})
 		})
 
+		// Live log tails (SSE), per process and combined
+		app.via.HandleFunc("GET /api/process/logs/stream", logStreamHandler(app.pcClient))
+		app.via.HandleFunc("GET /api/process/logs/stream/{name}", logStreamHandler(app.pcClient))
+
 		// API endpoint to start a process (proxy to process-compose)
 		app.via.HandleFunc("POST /api/process/start/{name}", func(w http.ResponseWriter, r *http.Request) {
 			processName := r.PathValue("name")
@@ -199,7 +325,9 @@ func (app *App) registerRoutes() {
 				return
 			}
 
-			if err := app.pcClient.StartProcess(processName); err != nil {
+			err := app.pcClient.StartProcess(processName)
+			app.record(app.requestUser(r), "process.start", processName, err)
+			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -216,7 +344,9 @@ func (app *App) registerRoutes() {
 				return
 			}
 
-			if err := app.pcClient.StopProcess(processName); err != nil {
+			err := app.pcClient.StopProcess(processName)
+			app.record(app.requestUser(r), "process.stop", processName, err)
+			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -233,7 +363,9 @@ func (app *App) registerRoutes() {
 				return
 			}
 
-			if err := app.pcClient.RestartProcess(processName); err != nil {
+			err := app.pcClient.RestartProcess(processName)
+			app.record(app.requestUser(r), "process.restart", processName, err)
+			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -242,6 +374,37 @@ func (app *App) registerRoutes() {
 			_ = json.NewEncoder(w).Encode(map[string]string{"status": "restarted"})
 		})
 
+		// API endpoint to plan a group action. The page confirms the plan,
+		// then runs the steps through the endpoints above to show progress.
+		app.via.HandleFunc("GET /api/process/group/plan", func(w http.ResponseWriter, r *http.Request) {
+			processes, err := app.pcClient.ListProcesses()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			// Older process-compose has no /graph; plan without dependencies
+			deps, _ := app.pcClient.GetDependencies()
+
+			plan, err := PlanGroupAction(r.URL.Query().Get("action"), r.URL.Query().Get("target"), processes, deps)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(plan)
+		})
+
+		// API endpoint for compliance with the SLOs declared in xplat.yaml
+		app.via.HandleFunc("GET /api/slo", func(w http.ResponseWriter, r *http.Request) {
+			statuses := loadSLOStatus(app.config.WorkDir)
+			if statuses == nil {
+				statuses = []sitecheck.SLOStatus{}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(statuses)
+		})
+
 		// API endpoint for dependency graph (v1.87.0+)
 		app.via.HandleFunc("GET /api/process/graph", func(w http.ResponseWriter, r *http.Request) {
 			format := r.URL.Query().Get("format")
@@ -264,19 +427,83 @@ func (app *App) registerRoutes() {
 		})
 	}
 
-	// Setup wizard routes
-	if app.config.EnableSetup {
+	// .env editor; in read-only mode it only shows which variables are set
+	app.via.Page("/env", func(c *via.Context) {
+		app.viaEnvPage(c)
+	})
+
+	// Setup wizard routes; the wizard edits .env, so not in read-only mode
+	if app.config.EnableSetup && !app.config.ReadOnly {
 		app.registerSetupRoutes()
 	}
+
+	// Desktop notifications (opt-in per browser, see notifyScript)
+	app.via.HandleFunc("GET /api/notifications/stream", app.notifications.ServeHTTP)
+
+	// Audit log of the actions above
+	app.via.Page("/audit", func(c *via.Context) {
+		app.viaAuditPage(c)
+	})
+	app.via.HandleFunc("GET /api/audit/export", app.handleAuditExport)
 }
 
-// registerSetupRoutes registers the setup wizard routes.
+// registerSetupRoutes mounts the env setup wizard (internal/env/web) under
+// /setup, with the xplat tabs above the wizard's own navigation.
 func (app *App) registerSetupRoutes() {
-	// Setup routes will be under /setup/* prefix
-	// This will be integrated with the existing env/web routes
-	app.via.Page("/setup", func(c *via.Context) {
-		app.setupIndexPage(c)
+	if app.config.MockMode {
+		env.SetEnvFileForTesting(env.GetTestEnvFile())
+	}
+
+	env.SetUpdateHook(app.recordEnvUpdate)
+
+	envweb.RegisterRoutes(app.via, envweb.MountOptions{
+		Prefix:   "/setup",
+		BasePath: app.basePath,
+		MockMode: app.config.MockMode,
+		Header: func() h.H {
+			return app.renderNav(TabSetup)
+		},
+		Audit: func(action, target string, err error) {
+			app.record(app.operator, action, target, err)
+		},
+	})
+}
+
+// registerWithCaddy adds the UI to the Caddy service registry under
+// caddyPathPrefix when Caddy is already running, so the setup wizard gets
+// HTTPS and LAN URLs. Links then carry the prefix (see link). Returns false
+// if not registered.
+func (app *App) registerWithCaddy() bool {
+	if !env.IsCaddyRunning() {
+		return false
+	}
+
+	port, err := strconv.Atoi(app.config.Port)
+	if err != nil {
+		return false
+	}
+	result, err := env.RegisterService(env.ServiceConfig{
+		Name:          caddyServiceName,
+		Port:          port,
+		PathPattern:   caddyPathPrefix + "/*",
+		Priority:      10,
+		HealthPath:    caddyPathPrefix + "/",
+		AssetPatterns: []string{"/_*"}, // Via framework assets and endpoints are root-relative
 	})
+	if err != nil {
+		log.Printf("Warning: Failed to register with Caddy: %v", err)
+		return false
+	}
+	app.basePath = result.BasePath
+	log.Printf("Setup wizard via Caddy: %s/setup", strings.TrimSuffix(result.FullLocalURL, "/"))
+	return true
+}
+
+// link returns an href for a UI path, under the Caddy path prefix when the
+// UI is registered there. The guard strips the prefix again, so the link
+// also works on the UI's own port.
+func (app *App) link(path string) string {
+	return app.basePath + path
 }
 
 // ActiveTab represents the currently active navigation tab.
@@ -285,8 +512,13 @@ type ActiveTab string
 const (
 	TabHome      ActiveTab = "home"
 	TabTasks     ActiveTab = "tasks"
+	TabHistory   ActiveTab = "history"
+	TabSchedules ActiveTab = "schedules"
+	TabIncludes  ActiveTab = "includes"
 	TabProcesses ActiveTab = "processes"
+	TabEnv       ActiveTab = "env"
 	TabSetup     ActiveTab = "setup"
+	TabAudit     ActiveTab = "audit"
 )
 
 // unifiedIndexPage renders the main landing page with all sections.
@@ -335,13 +567,16 @@ func (app *App) unifiedIndexPage(c *via.Context) {
 						),
 					),
 
+					// Environment card
+					app.envCard(),
+
 					// Setup card
-					h.If(app.config.EnableSetup,
+					h.If(app.config.EnableSetup && !app.config.ReadOnly,
 						h.Article(
 							h.H3(h.Text("Setup")),
 							h.P(h.Text("Configure environment and external services")),
 							h.A(
-								h.Href("/setup"),
+								h.Href(app.link("/setup")),
 								h.Attr("role", "button"),
 								h.Text("Open Setup Wizard"),
 							),
@@ -353,26 +588,10 @@ func (app *App) unifiedIndexPage(c *via.Context) {
 	})
 }
 
-// setupIndexPage renders the setup wizard landing page.
-func (app *App) setupIndexPage(c *via.Context) {
-	c.View(func() h.H {
-		return h.Div(
-			app.renderNav(TabSetup),
-			h.Main(
-				h.Class("container"),
-				h.H1(h.Text("Environment Setup")),
-				h.P(h.Text("Configure your environment and external services.")),
-				h.Article(
-					h.H3(h.Text("Coming Soon")),
-					h.P(h.Text("The setup wizard will be integrated here.")),
-				),
-			),
-		)
-	})
-}
-
-// renderNav renders the unified navigation header.
-// This is a wrapper around the shared RenderNav function.
+// renderNav renders the unified navigation header, with the number of
+// failing schedules on the Schedules tab.
 func (app *App) renderNav(activeTab ActiveTab) h.H {
-	return RenderNav(string(activeTab), app.config.WorkDir)
+	return renderNav(string(activeTab), app.config.WorkDir, app.basePath, map[string]int{
+		string(TabSchedules): app.failingSchedules(),
+	})
 }
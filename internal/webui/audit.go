@@ -0,0 +1,175 @@
+package web
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+
+	"github.com/joeblew999/xplat/internal/audit"
+	"github.com/joeblew999/xplat/internal/projectstate"
+)
+
+// AuditLogPath returns the UI audit log of the project in workDir:
+// ~/.xplat/state/<project>/audit/ui.jsonl
+func AuditLogPath(workDir string) string {
+	return filepath.Join(projectstate.Open(workDir).ToolDir("audit"), "ui.jsonl")
+}
+
+// operatorName returns the identity for actions with no request to read it
+// from: XPLAT_UI_USER, else the OS user running the UI.
+func operatorName() string {
+	if name := os.Getenv("XPLAT_UI_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return "local:" + u.Username
+	}
+	return "local"
+}
+
+// requestUser returns the identity behind a request: the user the guard
+// authenticated or took from the configured identity header, else the
+// operator.
+func (app *App) requestUser(r *http.Request) string {
+	if v := r.Header.Get(userHeader); v != "" {
+		return v
+	}
+	return app.operator
+}
+
+// record appends an action to the audit log. A failed write is logged but
+// does not fail the action.
+func (app *App) record(user, action, target string, err error) {
+	if app.audit == nil {
+		return
+	}
+	if _, logErr := app.audit.Record(user, action, target, nil, err); logErr != nil {
+		log.Printf("Warning: Failed to write audit log: %v", logErr)
+	}
+}
+
+// recordEnvUpdate audits env edits made through the setup wizard. Only the
+// keys are recorded, never the values.
+func (app *App) recordEnvUpdate(keys []string, err error) {
+	if app.audit == nil {
+		return
+	}
+	details := map[string]string{"keys": strings.Join(keys, ",")}
+	if _, logErr := app.audit.Record(app.operator, "env.update", app.config.envFile(), details, err); logErr != nil {
+		log.Printf("Warning: Failed to write audit log: %v", logErr)
+	}
+}
+
+// envFile names the env file edited by the setup wizard.
+func (cfg AppConfig) envFile() string {
+	if cfg.MockMode {
+		return "mock"
+	}
+	return ".env"
+}
+
+// handleAuditExport serves the audit log as JSON lines (default) or CSV.
+func (app *App) handleAuditExport(w http.ResponseWriter, r *http.Request) {
+	entries, err := audit.Read(app.audit.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="xplat-ui-audit.csv"`)
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"seq", "time", "user", "action", "target", "outcome", "error", "hash"})
+		for _, e := range entries {
+			_ = cw.Write([]string{strconv.Itoa(e.Seq), e.Time.Format("2006-01-02T15:04:05Z07:00"), e.User, e.Action, e.Target, e.Outcome, e.Error, e.Hash})
+		}
+		cw.Flush()
+	default:
+		// The raw lines, so the export can be verified with the hash chain
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="xplat-ui-audit.jsonl"`)
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			_ = enc.Encode(e)
+		}
+	}
+}
+
+// viaAuditPage renders the audit log, newest first, with the chain status.
+func (app *App) viaAuditPage(c *via.Context) {
+	c.View(func() h.H {
+		entries, readErr := audit.Read(app.audit.Path)
+		count, verifyErr := audit.Verify(app.audit.Path)
+
+		var status h.H
+		switch {
+		case readErr != nil:
+			status = h.P(h.Style("color: var(--pico-del-color);"), h.Text(readErr.Error()))
+		case errors.Is(verifyErr, audit.ErrTampered):
+			status = h.P(h.Style("color: var(--pico-del-color);"), h.Strong(h.Text("Tampered: ")), h.Text(verifyErr.Error()))
+		default:
+			status = h.P(h.Style("color: var(--pico-ins-color);"), h.Text(fmt.Sprintf("%d entries, hash chain verified", count)))
+		}
+
+		rows := []h.H{h.THead(h.Tr(
+			h.Th(h.Text("#")), h.Th(h.Text("Time")), h.Th(h.Text("User")),
+			h.Th(h.Text("Action")), h.Th(h.Text("Target")), h.Th(h.Text("Outcome")),
+		))}
+		var body []h.H
+		for i := len(entries) - 1; i >= 0; i-- {
+			e := entries[i]
+			outcome := h.Text(e.Outcome)
+			if e.Outcome == audit.OutcomeError {
+				outcome = h.Span(h.Style("color: var(--pico-del-color);"), h.Attr("title", e.Error), h.Text(e.Outcome))
+			}
+			target := e.Target
+			if keys := e.Details["keys"]; keys != "" {
+				target += " (" + keys + ")"
+			}
+			body = append(body, h.Tr(
+				h.Td(h.Text(strconv.Itoa(e.Seq))),
+				h.Td(h.Text(e.Time.Local().Format("2006-01-02 15:04:05"))),
+				h.Td(h.Text(e.User)),
+				h.Td(h.Code(h.Text(e.Action))),
+				h.Td(h.Text(target)),
+				h.Td(outcome),
+			))
+		}
+		rows = append(rows, h.TBody(body...))
+
+		return h.Div(
+			app.renderNav(TabAudit),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.H3(h.Text("Audit Log")),
+					h.P(
+						h.Style("color: var(--pico-muted-color);"),
+						h.Text(filepath.ToSlash(app.audit.Path)),
+					),
+					status,
+					h.P(
+						h.A(h.Href("/api/audit/export"), h.Text("Export JSONL")),
+						h.Text(" · "),
+						h.A(h.Href("/api/audit/export?format=csv"), h.Text("Export CSV")),
+					),
+					h.If(len(entries) > 0, h.Table(rows...)),
+					h.If(len(entries) == 0, h.P(h.Text("No actions recorded yet."))),
+				),
+			),
+		)
+	})
+}
@@ -0,0 +1,34 @@
+package web
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestUser(t *testing.T) {
+	app := &App{operator: "local:dev"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"no headers", "127.0.0.1:5000", nil, "local:dev"},
+		{"guard user", "127.0.0.1:5000", map[string]string{userHeader: "alice@example.com"}, "alice@example.com"},
+		{"proxy header", "127.0.0.1:5000", map[string]string{"Cf-Access-Authenticated-User-Email": "mallory@example.com"}, "local:dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/process/stop/api", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if got := app.requestUser(r); got != tt.want {
+				t.Errorf("requestUser = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package web
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+
+	"github.com/joeblew999/xplat/internal/env"
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/processcompose"
+)
+
+// EnvVar is a variable on the Env tab: one declared in xplat.yaml, listed
+// in .env.example or set in .env.
+type EnvVar struct {
+	Key         string
+	Value       string // Value in .env
+	InFile      bool   // Has a line in .env
+	Example     string // Value in .env.example
+	Required    bool   // Listed under env.required in xplat.yaml
+	Description string // From xplat.yaml, else the setup wizard's field
+	Secret      bool   // Value is never sent to the browser
+}
+
+// Missing reports whether a required variable has no real value.
+func (v EnvVar) Missing() bool {
+	return v.Required && env.IsPlaceholder(v.Value)
+}
+
+// envPath is the .env file the Env tab edits: the one the setup wizard
+// edits, in the working directory.
+func (cfg AppConfig) envPath() string {
+	name := ".env"
+	if cfg.MockMode {
+		name = env.GetTestEnvFile()
+	}
+	return filepath.Join(cfg.WorkDir, name)
+}
+
+// loadEnvVars lists the variables of the project in dir whose env file is
+// envPath: the ones xplat.yaml declares first, in order, then the rest of
+// .env and .env.example by name.
+func loadEnvVars(dir, envPath string) ([]EnvVar, error) {
+	values, err := processcompose.ReadDotenv(envPath)
+	if err != nil {
+		return nil, err
+	}
+	examples, err := processcompose.ReadDotenv(filepath.Join(dir, ".env.example"))
+	if err != nil {
+		return nil, err
+	}
+
+	var vars []EnvVar
+	seen := make(map[string]bool)
+	add := func(key string, required bool, description string) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		if description == "" {
+			if field := env.GetFieldInfo(key); field != nil {
+				description = field.Description
+			}
+		}
+		value, inFile := values[key]
+		vars = append(vars, EnvVar{
+			Key:         key,
+			Value:       value,
+			InFile:      inFile,
+			Example:     examples[key],
+			Required:    required,
+			Description: description,
+			Secret:      isSecretKey(key),
+		})
+	}
+
+	if m, err := manifest.NewLoader().LoadDir(dir); err == nil && m.Env != nil {
+		for _, v := range m.Env.Required {
+			add(v.Name, true, v.Description)
+		}
+		for _, v := range m.Env.Optional {
+			add(v.Name, false, v.Description)
+		}
+	}
+
+	var rest []string
+	for _, keys := range []map[string]string{values, examples} {
+		for key := range keys {
+			if !seen[key] {
+				rest = append(rest, key)
+			}
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		add(key, false, "")
+	}
+	return vars, nil
+}
+
+// secretMarkers are parts of variable names whose values are masked.
+var secretMarkers = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "PRIVATE", "AUTH"}
+
+// isSecretKey reports whether a variable's value should be masked.
+func isSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// envUpdates returns the edits to save: changed plain values, and secrets
+// given a new value (an empty secret field leaves the value unchanged).
+func envUpdates(vars []EnvVar, inputs map[string]string) map[string]string {
+	updates := make(map[string]string)
+	for _, v := range vars {
+		input, ok := inputs[v.Key]
+		if !ok {
+			continue
+		}
+		input = strings.TrimSpace(input)
+		if v.Secret && input == "" {
+			continue
+		}
+		if input != v.Value || (!v.InFile && input != "") {
+			updates[v.Key] = input
+		}
+	}
+	return updates
+}
+
+// validateEnvFast runs the setup wizard's fast (format only) validation on
+// the wizard's fields that have a value, and returns the failures.
+func validateEnvFast(values map[string]string) []string {
+	cfg := &env.EnvConfig{}
+	for key, value := range values {
+		cfg.Set(key, value)
+	}
+	var failures []string
+	for _, field := range env.GetAllFieldsInOrder() {
+		value := values[field.Key]
+		if !field.Validate || env.IsPlaceholder(value) {
+			continue
+		}
+		if result := env.ValidateFieldFast(field.Key, value, cfg); !result.Skipped && !result.Valid {
+			failures = append(failures, fmt.Sprintf("%s: %v", field.Key, result.Error))
+		}
+	}
+	return failures
+}
+
+// viaEnvPage lists the project's variables with the required ones that are
+// missing, and saves edits to .env. Secret values are never shown; their
+// fields are left empty and only replace the value when filled in.
+func (app *App) viaEnvPage(c *via.Context) {
+	envPath := app.config.envPath()
+	vars, loadErr := loadEnvVars(app.config.WorkDir, envPath)
+	var mu sync.Mutex // Guards vars and loadErr, reloaded after a save
+
+	inputs := make(map[string]envSignal, len(vars))
+	for _, v := range vars {
+		if v.Secret || app.config.ReadOnly {
+			inputs[v.Key] = c.Signal("") // Signals are sent to the browser
+		} else {
+			inputs[v.Key] = c.Signal(v.Value)
+		}
+	}
+	newKey := c.Signal("")
+	newValue := c.Signal("")
+	status := c.Signal("")
+	statusOK := c.Signal(true)
+
+	saveAction := c.Action(func() {
+		if app.config.ReadOnly {
+			return
+		}
+		values := make(map[string]string, len(inputs))
+		for key, sig := range inputs {
+			values[key] = sig.String()
+		}
+		mu.Lock()
+		updates := envUpdates(vars, values)
+		mu.Unlock()
+		if key := strings.TrimSpace(newKey.String()); key != "" {
+			updates[key] = strings.TrimSpace(newValue.String())
+		}
+		if len(updates) == 0 {
+			status.SetValue("No changes to save.")
+			statusOK.SetValue(true)
+			c.Sync()
+			return
+		}
+
+		keys := make([]string, 0, len(updates))
+		for key := range updates {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		err := env.UpdateDotenv(envPath, updates)
+		app.recordEnvUpdate(keys, err)
+		if err != nil {
+			status.SetValue("Save failed: " + err.Error())
+			statusOK.SetValue(false)
+			c.Sync()
+			return
+		}
+
+		// Validate what is now in the file with the wizard's fast validation
+		saved, _ := processcompose.ReadDotenv(envPath)
+		if failures := validateEnvFast(saved); len(failures) > 0 {
+			status.SetValue(fmt.Sprintf("Saved %s, but validation failed: %s", strings.Join(keys, ", "), strings.Join(failures, "; ")))
+			statusOK.SetValue(false)
+		} else {
+			status.SetValue("Saved " + strings.Join(keys, ", ") + ".")
+			statusOK.SetValue(true)
+		}
+
+		mu.Lock()
+		vars, loadErr = loadEnvVars(app.config.WorkDir, envPath)
+		mu.Unlock()
+		for key, sig := range inputs {
+			if isSecretKey(key) {
+				sig.SetValue("")
+			}
+		}
+		added := newKey.String() != ""
+		newKey.SetValue("")
+		newValue.SetValue("")
+		c.Sync()
+		if added {
+			// The new variable needs a field of its own
+			c.ExecScript("setTimeout(function() { window.location.reload(); }, 1500);")
+		}
+	})
+
+	c.View(func() h.H {
+		mu.Lock()
+		vars, loadErr := vars, loadErr
+		mu.Unlock()
+
+		var missing []string
+		for _, v := range vars {
+			if v.Missing() {
+				missing = append(missing, v.Key)
+			}
+		}
+
+		var summary h.H
+		switch {
+		case loadErr != nil:
+			summary = h.P(h.Style("color: var(--pico-del-color);"), h.Text(loadErr.Error()))
+		case len(missing) > 0:
+			summary = h.P(
+				h.Style("color: var(--pico-del-color);"),
+				h.Strong(h.Text(fmt.Sprintf("%d required variable(s) missing: ", len(missing)))),
+				h.Text(strings.Join(missing, ", ")),
+			)
+		case len(vars) == 0:
+			summary = h.P(h.Text("No variables yet: nothing in .env or .env.example, and no env section in xplat.yaml."))
+		default:
+			summary = h.P(h.Style("color: var(--pico-ins-color);"), h.Text("All required variables are set."))
+		}
+
+		rows := []h.H{h.THead(h.Tr(
+			h.Th(h.Text("Variable")), h.Th(h.Text("Value")), h.Th(h.Text("Example")),
+		))}
+		var body []h.H
+		for _, v := range vars {
+			body = append(body, h.Tr(
+				h.Td(envVarLabel(v)),
+				h.Td(app.envVarField(v, inputs[v.Key])),
+				h.Td(h.Small(h.Style("color: var(--pico-muted-color);"), h.Text(envExample(v)))),
+			))
+		}
+		rows = append(rows, h.TBody(body...))
+
+		statusColor := "var(--pico-ins-color)"
+		if !statusOK.Bool() {
+			statusColor = "var(--pico-del-color)"
+		}
+
+		return h.Div(
+			app.renderNav(TabEnv),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.H3(h.Text("Environment")),
+					h.P(
+						h.Style("color: var(--pico-muted-color);"),
+						h.Text(filepath.ToSlash(envPath)),
+						h.If(app.config.EnableSetup && !app.config.ReadOnly, h.Span(
+							h.Text(" · Cloudflare and Claude credentials can be created in the "),
+							h.A(h.Href(app.link("/setup")), h.Text("Setup Wizard")),
+						)),
+					),
+					summary,
+					h.If(len(vars) > 0, h.Table(rows...)),
+					h.If(!app.config.ReadOnly, h.Div(
+						h.Div(
+							h.Attr("role", "group"),
+							h.Input(h.Type("text"), h.Placeholder("NEW_VARIABLE"), h.Value(newKey.String()), newKey.Bind()),
+							h.Input(h.Type("text"), h.Placeholder("value"), h.Value(newValue.String()), newValue.Bind()),
+						),
+						h.Button(h.Text("Save"), saveAction.OnClick()),
+						h.If(status.String() != "", h.P(
+							h.Style("color: "+statusColor+";"),
+							h.Text(status.String()),
+						)),
+					)),
+					h.If(app.config.ReadOnly, h.P(
+						h.Style("color: var(--pico-muted-color);"),
+						h.Text("Read-only: values are hidden and cannot be edited from this UI"),
+					)),
+				),
+			),
+		)
+	})
+}
+
+// envVarLabel renders a variable's name, whether it is required and its
+// description.
+func envVarLabel(v EnvVar) h.H {
+	name := v.Key
+	if v.Required {
+		name += " *"
+	}
+	style := ""
+	if v.Missing() {
+		style = "color: var(--pico-del-color);"
+	}
+	return h.Div(
+		h.Code(h.Style(style), h.Text(name)),
+		h.If(v.Description != "", h.Br()),
+		h.If(v.Description != "", h.Small(h.Style("color: var(--pico-muted-color);"), h.Text(v.Description))),
+	)
+}
+
+// envSignal is a Via signal bound to a variable's field.
+type envSignal interface {
+	boundSignal
+	SetValue(v any)
+}
+
+// envVarField renders the input for a variable: a password field for
+// secrets (empty, the value is kept unless one is typed), else a text field.
+// Read-only mode only shows whether a value is set.
+func (app *App) envVarField(v EnvVar, sig envSignal) h.H {
+	set := !env.IsPlaceholder(v.Value)
+	if sig == nil {
+		return h.Text("saved, reloading…") // Added since the page loaded
+	}
+	if app.config.ReadOnly {
+		if set {
+			return h.Text("set")
+		}
+		return h.Text("not set")
+	}
+	if v.Secret {
+		placeholder := "not set"
+		if set {
+			placeholder = "•••••••• (unchanged)"
+		}
+		return h.Input(
+			h.Type("password"),
+			h.Attr("autocomplete", "off"),
+			h.Placeholder(placeholder),
+			h.Style("margin: 0;"),
+			sig.Bind(),
+		)
+	}
+	return h.Input(
+		h.Type("text"),
+		h.Placeholder(v.Example),
+		h.Value(sig.String()),
+		h.Style("margin: 0;"),
+		sig.Bind(),
+	)
+}
+
+// envExample is the example shown next to a variable; secret examples are
+// only shown while they are placeholders.
+func envExample(v EnvVar) string {
+	if v.Secret && !env.IsPlaceholder(v.Example) {
+		return "(set in .env.example)"
+	}
+	return v.Example
+}
+
+// envCard is the dashboard card for the Env tab, with the required
+// variables that are missing.
+func (app *App) envCard() h.H {
+	vars, err := loadEnvVars(app.config.WorkDir, app.config.envPath())
+	text := "Edit .env and check the variables xplat.yaml requires"
+	if err == nil {
+		missing := 0
+		for _, v := range vars {
+			if v.Missing() {
+				missing++
+			}
+		}
+		if missing > 0 {
+			text = fmt.Sprintf("%d required variable(s) missing from .env", missing)
+		}
+	}
+	return h.Article(
+		h.H3(h.Text("Environment")),
+		h.P(h.Text(text)),
+		h.A(
+			h.Href("/env"),
+			h.Attr("role", "button"),
+			h.Text("Edit Environment"),
+		),
+	)
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadEnvVars(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"xplat.yaml": "apiVersion: xplat/v1\nkind: Package\nname: demo\nversion: 0.1.0\nenv:\n" +
+			"  required:\n    - name: DATABASE_URL\n      description: Postgres URL\n    - name: API_TOKEN\n" +
+			"  optional:\n    - name: LOG_LEVEL\n",
+		".env":         "API_TOKEN=your-token-here\nLOG_LEVEL=debug\nEXTRA=1\n",
+		".env.example": "DATABASE_URL=postgres://localhost/demo\nAPI_TOKEN=your-token-here\nCACHE_DIR=/tmp\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vars, err := loadEnvVars(dir, filepath.Join(dir, ".env"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys, missing []string
+	for _, v := range vars {
+		keys = append(keys, v.Key)
+		if v.Missing() {
+			missing = append(missing, v.Key)
+		}
+	}
+	if want := []string{"DATABASE_URL", "API_TOKEN", "LOG_LEVEL", "CACHE_DIR", "EXTRA"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if want := []string{"DATABASE_URL", "API_TOKEN"}; !reflect.DeepEqual(missing, want) {
+		t.Errorf("missing = %v, want %v (placeholders count as missing)", missing, want)
+	}
+	if vars[0].Description != "Postgres URL" || vars[0].Example != "postgres://localhost/demo" {
+		t.Errorf("DATABASE_URL = %+v", vars[0])
+	}
+	if !vars[1].Secret || vars[2].Secret {
+		t.Errorf("API_TOKEN secret = %v, LOG_LEVEL secret = %v", vars[1].Secret, vars[2].Secret)
+	}
+}
+
+func TestEnvUpdates(t *testing.T) {
+	vars := []EnvVar{
+		{Key: "LOG_LEVEL", Value: "debug", InFile: true},
+		{Key: "PORT", InFile: false},
+		{Key: "API_TOKEN", Value: "s3cret", InFile: true, Secret: true},
+		{Key: "DB_PASSWORD", Value: "pw", InFile: true, Secret: true},
+	}
+	got := envUpdates(vars, map[string]string{
+		"LOG_LEVEL":   " info ",
+		"PORT":        "",
+		"API_TOKEN":   "",
+		"DB_PASSWORD": "new-pw",
+	})
+	want := map[string]string{"LOG_LEVEL": "info", "DB_PASSWORD": "new-pw"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("envUpdates = %v, want %v (empty secret fields keep the value)", got, want)
+	}
+}
+
+func TestValidateEnvFast(t *testing.T) {
+	if failures := validateEnvFast(map[string]string{"LOG_LEVEL": "debug"}); len(failures) != 0 {
+		t.Errorf("unrelated vars failed validation: %v", failures)
+	}
+	if failures := validateEnvFast(map[string]string{"CLOUDFLARE_ACCOUNT_ID": "not-an-id!"}); len(failures) != 1 {
+		t.Errorf("invalid account ID: failures = %v, want 1", failures)
+	}
+}
@@ -0,0 +1,197 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/processcompose"
+)
+
+// Env reload modes for AppConfig.EnvReload.
+const (
+	EnvReloadRestart = "restart" // Restart affected processes through process-compose
+	EnvReloadSighup  = "sighup"  // Send affected processes SIGHUP (Unix only)
+)
+
+// envWatchInterval is how often .env is checked for changes.
+const envWatchInterval = 2 * time.Second
+
+// EnvReload summarizes one reaction to a .env change, shown on the
+// processes page and sent as a notification.
+type EnvReload struct {
+	Time      time.Time
+	Mode      string
+	Changed   []string // Keys added, removed or changed
+	Processes []string // Processes restarted or signalled
+	Errors    []string
+}
+
+// Summary is a one-line description of the reload.
+func (r EnvReload) Summary() string {
+	verb := "restarted"
+	if r.Mode == EnvReloadSighup {
+		verb = "sent SIGHUP to"
+	}
+	s := fmt.Sprintf(".env changed (%s)", strings.Join(r.Changed, ", "))
+	if len(r.Processes) == 0 {
+		s += ": no running process uses these keys"
+	} else {
+		s += ": " + verb + " " + strings.Join(r.Processes, ", ")
+	}
+	if len(r.Errors) > 0 {
+		s += "; failed: " + strings.Join(r.Errors, "; ")
+	}
+	return s
+}
+
+func (r EnvReload) notification() UINotification {
+	title := "⟳ .env reloaded"
+	if len(r.Errors) > 0 {
+		title = "✗ .env reload failed"
+	}
+	return UINotification{
+		ID:    fmt.Sprintf("env-%d", r.Time.UnixNano()),
+		Kind:  "env",
+		Title: title,
+		Body:  r.Summary(),
+		URL:   "/processes",
+		Time:  r.Time,
+	}
+}
+
+// envReloadState holds the latest reload for the processes page.
+type envReloadState struct {
+	mu   sync.Mutex
+	last *EnvReload
+}
+
+func (s *envReloadState) set(r EnvReload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = &r
+}
+
+// Last returns the latest reload, or nil if .env has not changed.
+func (s *envReloadState) Last() *EnvReload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// watchEnv reacts to .env changes in the working directory according to
+// the configured mode until ctx is done.
+func (app *App) watchEnv(ctx context.Context) {
+	path := filepath.Join(app.config.WorkDir, ".env")
+	log.Printf("Watching %s: %s affected processes on change", path, envReloadVerb(app.config.EnvReload))
+
+	processcompose.WatchDotenv(ctx, path, envWatchInterval, func(old, new map[string]string) {
+		r := app.reloadEnv(processcompose.ChangedKeys(old, new))
+		log.Print(r.Summary())
+		app.envReload.set(r)
+		app.notifications.Publish(r.notification())
+	})
+}
+
+func envReloadVerb(mode string) string {
+	if mode == EnvReloadSighup {
+		return "signal"
+	}
+	return "restart"
+}
+
+// reloadEnv restarts or signals the running processes that use changed.
+//
+// A plain process restart reuses the environment process-compose loaded at
+// startup, so restart mode first has process-compose reload the project,
+// which re-reads .env and restarts the processes whose expanded
+// configuration changed. The remaining affected processes are restarted
+// afterwards; one that only inherits a key from process-compose's own
+// environment keeps the old value until process-compose is restarted.
+func (app *App) reloadEnv(changed []string) EnvReload {
+	r := EnvReload{Time: time.Now(), Mode: app.config.EnvReload, Changed: changed}
+
+	pc, err := app.loadProcessConfig()
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return r
+	}
+	affected := processcompose.AffectedProcesses(pc, manifestEnvVars(app.config.WorkDir), changed)
+
+	processes, err := app.pcClient.ListProcesses()
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		return r
+	}
+	running := make(map[string]ProcessInfo, len(processes))
+	for _, p := range processes {
+		if p.IsRunning {
+			running[p.Name] = p
+		}
+	}
+
+	var reloaded map[string]string
+	if r.Mode != EnvReloadSighup && len(affected) > 0 {
+		if reloaded, err = app.pcClient.ReloadProject(); err != nil {
+			r.Errors = append(r.Errors, err.Error())
+		}
+	}
+
+	for _, name := range affected {
+		p, ok := running[name]
+		if !ok {
+			continue // Picks up the new values when it next starts
+		}
+		switch {
+		case r.Mode == EnvReloadSighup:
+			err = signalReload(p.PID)
+		case reloaded[name] != "":
+			err = nil // Restarted by the project reload
+		default:
+			err = app.pcClient.RestartProcess(name)
+		}
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		r.Processes = append(r.Processes, name)
+	}
+	return r
+}
+
+// loadProcessConfig parses the first process-compose config found in the
+// working directory, in 'xplat process' search order.
+func (app *App) loadProcessConfig() (*processcompose.ProcessCompose, error) {
+	for _, f := range config.ProcessComposeSearchOrder() {
+		path := filepath.Join(app.config.WorkDir, f)
+		if _, err := os.Stat(path); err == nil {
+			return processcompose.Parse(path)
+		}
+	}
+	return nil, fmt.Errorf("no process-compose config in %s", app.config.WorkDir)
+}
+
+// manifestEnvVars maps the processes of the project's xplat.yaml to the env
+// vars it declares. A missing manifest declares none.
+func manifestEnvVars(dir string) map[string][]string {
+	m, err := manifest.NewLoader().LoadDir(dir)
+	if err != nil || !m.HasEnv() {
+		return nil
+	}
+	var names []string
+	for _, v := range m.AllEnvVars() {
+		names = append(names, v.Name)
+	}
+	declared := make(map[string][]string, len(m.Processes))
+	for name := range m.Processes {
+		declared[name] = names
+	}
+	return declared
+}
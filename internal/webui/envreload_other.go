@@ -0,0 +1,13 @@
+//go:build !unix
+
+package web
+
+import (
+	"errors"
+	"runtime"
+)
+
+// signalReload asks a process to reload its configuration.
+func signalReload(pid int) error {
+	return errors.New("SIGHUP is not supported on " + runtime.GOOS + ", use --env-reload=restart")
+}
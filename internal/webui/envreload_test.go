@@ -0,0 +1,59 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReloadEnvRestart(t *testing.T) {
+	dir := t.TempDir()
+	pcYAML := `version: "0.5"
+processes:
+  api:
+    command: ./api --db $DATABASE_URL
+  web:
+    command: ./web
+    environment:
+      - "API_KEY=${API_KEY}"
+  docs:
+    command: ./docs
+`
+	if err := os.WriteFile(filepath.Join(dir, "process-compose.yaml"), []byte(pcYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/processes":
+			_, _ = w.Write([]byte(`{"data": [
+				{"name": "api", "is_running": true},
+				{"name": "web", "is_running": true},
+				{"name": "docs", "is_running": true}]}`))
+		case "/project/configuration":
+			// web's expanded environment changed, so the reload restarted it
+			_, _ = w.Write([]byte(`{"web": "updated"}`))
+		}
+	}))
+	defer srv.Close()
+
+	app := &App{config: AppConfig{WorkDir: dir, EnvReload: EnvReloadRestart}, pcClient: NewProcessComposeClient(0)}
+	app.pcClient.BaseURL = srv.URL
+
+	r := app.reloadEnv([]string{"API_KEY", "DATABASE_URL"})
+	if len(r.Errors) > 0 {
+		t.Fatalf("errors: %v", r.Errors)
+	}
+	if want := []string{"api", "web"}; !reflect.DeepEqual(r.Processes, want) {
+		t.Errorf("Processes = %v, want %v", r.Processes, want)
+	}
+	want := []string{"GET /processes", "POST /project/configuration", "POST /process/restart/api"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("API calls = %v, want %v", calls, want)
+	}
+}
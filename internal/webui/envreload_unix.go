@@ -0,0 +1,10 @@
+//go:build unix
+
+package web
+
+import "syscall"
+
+// signalReload asks a process to reload its configuration.
+func signalReload(pid int) error {
+	return syscall.Kill(pid, syscall.SIGHUP)
+}
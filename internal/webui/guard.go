@@ -0,0 +1,325 @@
+package web
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// The guard sits in front of the Via server, which only listens on loopback,
+// because Caddy also exposes the UI on the LAN. It requires the configured
+// token or basic auth, rejects POSTs to /api/ without the session's CSRF
+// token, and in read-only mode rejects them all.
+
+const (
+	sessionCookie = "xplat_ui_session" // HttpOnly session ID
+	csrfCookie    = "xplat_ui_csrf"    // CSRF token, read by csrfScript
+	csrfHeader    = "X-CSRF-Token"
+	csrfField     = "csrf_token"   // Form field alternative to csrfHeader
+	userHeader    = "X-Xplat-User" // Identity passed on to the Via server
+	maxSessions   = 1000
+)
+
+// uiSession is a browser signed in to the UI.
+type uiSession struct {
+	user string // Basic auth user, "token" or "" when auth is off
+	csrf string
+}
+
+// guard wraps the Via server with authentication, CSRF and read-only checks.
+type guard struct {
+	token    string // Accepted as Bearer token, basic auth password or ?token=
+	user     string // Basic auth credentials, when set
+	password string
+	readOnly bool
+	identity string // Trusted identity header of a proxy on this host, if any
+	next     http.Handler
+
+	mu       sync.Mutex
+	sessions map[string]*uiSession
+}
+
+// newGuard creates a guard for cfg in front of next.
+func newGuard(cfg AppConfig, next http.Handler) *guard {
+	g := &guard{
+		token:    cfg.AuthToken,
+		readOnly: cfg.ReadOnly,
+		identity: http.CanonicalHeaderKey(cfg.IdentityHeader),
+		next:     next,
+		sessions: make(map[string]*uiSession),
+	}
+	if cfg.BasicAuth != "" {
+		g.user, g.password, _ = strings.Cut(cfg.BasicAuth, ":")
+	}
+	return g
+}
+
+// authRequired reports whether a token or basic auth is configured.
+func (g *guard) authRequired() bool {
+	return g.token != "" || g.password != ""
+}
+
+// proxyTo returns a guard in front of the server at addr.
+func proxyTo(cfg AppConfig, addr string) *guard {
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: addr})
+	proxy.FlushInterval = -1 // Stream SSE (Via, logs, notifications) as written
+	return newGuard(cfg, proxy)
+}
+
+// ServeHTTP implements http.Handler.
+func (g *guard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Links carry the Caddy path prefix, which Caddy strips (see App.link);
+	// strip it here too for requests made to this port directly
+	if rest, ok := strings.CutPrefix(r.URL.Path, caddyPathPrefix); ok && (rest == "" || rest[0] == '/') {
+		if rest == "" {
+			rest = "/"
+		}
+		r.URL.Path = rest
+		r.URL.RawPath = ""
+	}
+
+	sess := g.session(r)
+
+	user, bearer, ok := g.authenticate(r, sess)
+	if !ok {
+		g.deny(w, r)
+		return
+	}
+	if sess == nil && !bearer && r.Method == http.MethodGet && isPage(r.URL.Path) {
+		sess = g.newSession(w, r, user)
+	}
+
+	// Sign in from a link (xplat up opens one), then drop the token from the URL
+	if r.Method == http.MethodGet && r.URL.Query().Has("token") {
+		q := r.URL.Query()
+		q.Del("token")
+		target := *r.URL
+		target.RawQuery = q.Encode()
+		http.Redirect(w, r, target.RequestURI(), http.StatusSeeOther)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && strings.HasPrefix(r.URL.Path, "/api/") {
+		if g.readOnly {
+			http.Error(w, "the UI is read-only", http.StatusForbidden)
+			return
+		}
+		if !bearer && !g.csrfOK(r, sess) {
+			http.Error(w, "missing or invalid CSRF token", http.StatusForbidden)
+			return
+		}
+	}
+
+	// The Via server sees every request coming from loopback, so it only
+	// takes the identity from userHeader, which the guard sets itself. A
+	// proxy's identity header is trusted only when configured, and only from
+	// a proxy on this host.
+	r.Header.Del(userHeader)
+	switch {
+	case user != "" && user != "token":
+		r.Header.Set(userHeader, user)
+	case g.identity != "" && isLoopback(r.RemoteAddr) && r.Header.Get(g.identity) != "":
+		r.Header.Set(userHeader, r.Header.Get(g.identity))
+	}
+
+	g.next.ServeHTTP(w, r)
+}
+
+// authenticate checks the request's credentials. It returns the user, and
+// whether the request carried the token as a Bearer header (not sent by
+// browsers on their own, so it needs no CSRF token).
+func (g *guard) authenticate(r *http.Request, sess *uiSession) (user string, bearer, ok bool) {
+	if !g.authRequired() {
+		return "", false, true
+	}
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		if g.token != "" && equal(strings.TrimPrefix(auth, "Bearer "), g.token) {
+			return "token", true, true
+		}
+		return "", false, false
+	}
+	if u, p, hasBasic := r.BasicAuth(); hasBasic {
+		switch {
+		case g.password != "" && equal(u, g.user) && equal(p, g.password):
+			return u, false, true
+		case g.token != "" && equal(p, g.token):
+			return "token", false, true
+		}
+		return "", false, false
+	}
+	if sess != nil && sess.user != "" {
+		return sess.user, false, true
+	}
+	if r.Method == http.MethodGet && g.token != "" && equal(r.URL.Query().Get("token"), g.token) {
+		return "token", false, true
+	}
+	return "", false, false
+}
+
+// deny answers an unauthenticated request: a basic auth challenge, or a
+// sign-in form for the token.
+func (g *guard) deny(w http.ResponseWriter, r *http.Request) {
+	if g.password != "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="xplat", charset="UTF-8"`)
+	}
+	if g.token == "" || r.Method != http.MethodGet || !isPage(r.URL.Path) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = fmt.Fprintf(w, signInPage, html.EscapeString(r.URL.Path))
+}
+
+// signInPage asks for the token and retries the page with it.
+const signInPage = `<!doctype html>
+<html><head><meta charset="utf-8"><title>xplat</title></head>
+<body style="font-family: sans-serif; max-width: 24rem; margin: 4rem auto;">
+<h2>xplat</h2>
+<form method="get" action="%s">
+<p><label>Token<br><input type="password" name="token" autofocus style="width: 100%%;"></label></p>
+<p><button type="submit">Sign in</button></p>
+</form>
+<p><small>The token is XPLAT_UI_TOKEN (or --auth-token) of 'xplat up'.</small></p>
+</body></html>
+`
+
+// session returns the request's session, or nil.
+func (g *guard) session(r *http.Request) *uiSession {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.sessions[c.Value]
+}
+
+// newSession starts a session for user and sets its cookies.
+func (g *guard) newSession(w http.ResponseWriter, r *http.Request, user string) *uiSession {
+	id, csrf := randomToken(), randomToken()
+	sess := &uiSession{user: user, csrf: csrf}
+	g.mu.Lock()
+	if len(g.sessions) >= maxSessions {
+		for old := range g.sessions {
+			delete(g.sessions, old) // Drop an arbitrary one
+			break
+		}
+	}
+	g.sessions[id] = sess
+	g.mu.Unlock()
+
+	secure := r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Value: id, Path: "/",
+		HttpOnly: true, Secure: secure, SameSite: http.SameSiteLaxMode,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name: csrfCookie, Value: csrf, Path: "/",
+		Secure: secure, SameSite: http.SameSiteStrictMode,
+	})
+	return sess
+}
+
+// csrfOK reports whether a POST carries the session's CSRF token, in the
+// X-CSRF-Token header or a csrf_token form field. Requests with no cookie,
+// Origin or Sec-Fetch-Site header do not come from a browser (curl, scripts)
+// and cannot be forged by another site, so they pass.
+func (g *guard) csrfOK(r *http.Request, sess *uiSession) bool {
+	if len(r.Cookies()) == 0 && r.Header.Get("Origin") == "" && r.Header.Get("Sec-Fetch-Site") == "" {
+		return true
+	}
+	if sess == nil {
+		return false
+	}
+	token := r.Header.Get(csrfHeader)
+	if token == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		// Read the form from a copy, leaving the body for the handler
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			return false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		form, _ := url.ParseQuery(string(body))
+		token = form.Get(csrfField)
+	}
+	return token != "" && equal(token, sess.csrf)
+}
+
+// csrfScript adds the CSRF token to the page's POSTs: fetch calls get the
+// header, forms the field.
+const csrfScript = `
+(function() {
+	function csrfToken() {
+		var m = document.cookie.match(/(?:^|; )` + csrfCookie + `=([^;]*)/);
+		return m ? m[1] : '';
+	}
+	var origFetch = window.fetch;
+	window.fetch = function(input, init) {
+		init = init || {};
+		var method = (init.method || (input instanceof Request ? input.method : 'GET')).toUpperCase();
+		if (method !== 'GET' && method !== 'HEAD') {
+			init.headers = new Headers(init.headers || {});
+			init.headers.set('` + csrfHeader + `', csrfToken());
+		}
+		return origFetch(input, init);
+	};
+	document.addEventListener('submit', function(e) {
+		var form = e.target;
+		if ((form.method || '').toLowerCase() !== 'post' || form.querySelector('input[name=` + csrfField + `]')) {
+			return;
+		}
+		var input = document.createElement('input');
+		input.type = 'hidden';
+		input.name = '` + csrfField + `';
+		input.value = csrfToken();
+		form.appendChild(input);
+	}, true);
+})();
+`
+
+// isPage reports whether path is a page rather than an API or Via endpoint.
+func isPage(path string) bool {
+	return !strings.HasPrefix(path, "/api/") && !strings.HasPrefix(path, "/_")
+}
+
+// loopbackAddr returns a free address on loopback for the Via server.
+func loopbackAddr() (string, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+	return addr, nil
+}
+
+// isLoopback reports whether a request's remote address is on this host.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func randomToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,195 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// guardedServer returns a guard for cfg in front of a handler that echoes
+// the user it was given.
+func guardedServer(cfg AppConfig) *guard {
+	return newGuard(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get(userHeader)))
+	}))
+}
+
+func serve(g *guard, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, r)
+	return w
+}
+
+func TestGuardToken(t *testing.T) {
+	g := guardedServer(AppConfig{AuthToken: "s3cret"})
+
+	if w := serve(g, httptest.NewRequest("GET", "/tasks", nil)); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Sign in") {
+		t.Errorf("no credentials: %d %q, want 401 sign-in page", w.Code, w.Body.String())
+	}
+
+	r := httptest.NewRequest("GET", "/tasks", nil)
+	r.Header.Set("Authorization", "Bearer s3cret")
+	if w := serve(g, r); w.Code != http.StatusOK {
+		t.Errorf("bearer token: %d, want 200", w.Code)
+	}
+	r.Header.Set("Authorization", "Bearer wrong")
+	if w := serve(g, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong bearer token: %d, want 401", w.Code)
+	}
+
+	// Signing in with ?token= sets a session and drops the token from the URL
+	w := serve(g, httptest.NewRequest("GET", "/tasks?token=s3cret&x=1", nil))
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/tasks?x=1" {
+		t.Fatalf("sign in: %d to %q, want 303 to /tasks?x=1", w.Code, w.Header().Get("Location"))
+	}
+	r = httptest.NewRequest("GET", "/_sse", nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	if w := serve(g, r); w.Code != http.StatusOK {
+		t.Errorf("session cookie: %d, want 200", w.Code)
+	}
+}
+
+func TestGuardBasicAuth(t *testing.T) {
+	g := guardedServer(AppConfig{BasicAuth: "alice:pw"})
+
+	w := serve(g, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("no credentials: %d, want 401 with a challenge", w.Code)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.SetBasicAuth("alice", "pw")
+	if w := serve(g, r); w.Code != http.StatusOK || w.Body.String() != "alice" {
+		t.Errorf("basic auth: %d %q, want 200 as alice", w.Code, w.Body.String())
+	}
+	r.SetBasicAuth("alice", "nope")
+	if w := serve(g, r); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong password: %d, want 401", w.Code)
+	}
+}
+
+func TestGuardCSRF(t *testing.T) {
+	g := guardedServer(AppConfig{})
+
+	page := serve(g, httptest.NewRequest("GET", "/processes", nil))
+	cookies := page.Result().Cookies()
+	var csrf string
+	for _, c := range cookies {
+		if c.Name == csrfCookie {
+			csrf = c.Value
+		}
+	}
+	if csrf == "" {
+		t.Fatal("no CSRF cookie set on a page")
+	}
+
+	post := func(header, form string) int {
+		var r *http.Request
+		if form != "" {
+			r = httptest.NewRequest("POST", "/api/history/1/rerun", strings.NewReader(url.Values{csrfField: {form}}.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		} else {
+			r = httptest.NewRequest("POST", "/api/process/stop/api", nil)
+		}
+		for _, c := range cookies {
+			r.AddCookie(c)
+		}
+		r.Header.Set("Origin", "http://localhost:8760")
+		if header != "" {
+			r.Header.Set(csrfHeader, header)
+		}
+		return serve(g, r).Code
+	}
+
+	if code := post("", ""); code != http.StatusForbidden {
+		t.Errorf("no token: %d, want 403", code)
+	}
+	if code := post("wrong", ""); code != http.StatusForbidden {
+		t.Errorf("wrong token: %d, want 403", code)
+	}
+	if code := post(csrf, ""); code != http.StatusOK {
+		t.Errorf("header token: %d, want 200", code)
+	}
+	if code := post("", csrf); code != http.StatusOK {
+		t.Errorf("form token: %d, want 200", code)
+	}
+
+	// Cross-site form posts have no session cookie (SameSite)
+	r := httptest.NewRequest("POST", "/api/process/stop/api", nil)
+	r.Header.Set("Origin", "https://evil.example")
+	if w := serve(g, r); w.Code != http.StatusForbidden {
+		t.Errorf("cross-site post: %d, want 403", w.Code)
+	}
+
+	// Scripts (no cookie or Origin) are not subject to CSRF
+	if w := serve(g, httptest.NewRequest("POST", "/api/process/stop/api", nil)); w.Code != http.StatusOK {
+		t.Errorf("script post: %d, want 200", w.Code)
+	}
+}
+
+func TestGuardReadOnly(t *testing.T) {
+	g := guardedServer(AppConfig{ReadOnly: true})
+
+	if w := serve(g, httptest.NewRequest("POST", "/api/process/restart/api", nil)); w.Code != http.StatusForbidden {
+		t.Errorf("POST in read-only mode: %d, want 403", w.Code)
+	}
+	if w := serve(g, httptest.NewRequest("GET", "/api/process/graph", nil)); w.Code != http.StatusOK {
+		t.Errorf("GET in read-only mode: %d, want 200", w.Code)
+	}
+}
+
+func TestGuardIdentityHeaders(t *testing.T) {
+	const header = "Cf-Access-Authenticated-User-Email"
+	tests := []struct {
+		name       string
+		configured string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"not configured", "", "127.0.0.1:5000", map[string]string{header: "alice@example.com"}, ""},
+		{"configured", header, "127.0.0.1:5000", map[string]string{header: "alice@example.com"}, "alice@example.com"},
+		{"other header", header, "127.0.0.1:5000", map[string]string{"X-Forwarded-User": "mallory"}, ""},
+		{"from the LAN", header, "192.0.2.10:5000", map[string]string{header: "mallory@example.com"}, ""},
+		{"forged user header", "", "127.0.0.1:5000", map[string]string{userHeader: "mallory"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := guardedServer(AppConfig{IdentityHeader: tt.configured})
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			if w := serve(g, r); w.Body.String() != tt.want {
+				t.Errorf("user = %q, want %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardStripsCaddyPrefix(t *testing.T) {
+	var got string
+	g := newGuard(AppConfig{}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Path
+	}))
+
+	tests := map[string]string{
+		caddyPathPrefix:                       "/",
+		caddyPathPrefix + "/":                 "/",
+		caddyPathPrefix + "/setup/cloudflare": "/setup/cloudflare",
+		"/setup":                              "/setup",
+		caddyPathPrefix + "ish/setup":         caddyPathPrefix + "ish/setup",
+	}
+	for path, want := range tests {
+		serve(g, httptest.NewRequest("GET", path, nil))
+		if got != want {
+			t.Errorf("%s reached the UI as %s, want %s", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+
+	"github.com/joeblew999/xplat/internal/config"
+)
+
+// Task run history: every run started from the UI is saved under
+// ~/.xplat/history as <id>.json (the run) and <id>.log (its output), so the
+// output survives closing the page or restarting the UI. The History tab
+// lists the runs of the current working directory.
+
+// Defaults for History.
+const (
+	maxHistoryRuns   = 500     // Runs kept across all projects
+	maxHistoryOutput = 1 << 20 // Output bytes kept per run (the tail)
+)
+
+// HistoryDir returns the run history directory: ~/.xplat/history
+func HistoryDir() string {
+	return filepath.Join(config.XplatHome(), "history")
+}
+
+// HistoryRun is a persisted task run.
+type HistoryRun struct {
+	ID       string    `json:"id"`
+	Task     string    `json:"task"`
+	Args     []string  `json:"args,omitempty"`
+	Dir      string    `json:"dir"`
+	User     string    `json:"user,omitempty"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	ExitCode int       `json:"exit_code"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Command returns the command line of the run.
+func (run HistoryRun) Command() string {
+	return strings.Join(append([]string{"task", run.Task}, run.Args...), " ")
+}
+
+// Duration returns how long the run took.
+func (run HistoryRun) Duration() time.Duration {
+	return run.End.Sub(run.Start).Round(time.Second)
+}
+
+// URL returns the run's history page.
+func (run HistoryRun) URL() string {
+	return "/history/" + run.ID
+}
+
+// History stores task runs as files in Dir.
+type History struct {
+	Dir string
+	Max int // Runs kept, oldest removed first (default maxHistoryRuns)
+}
+
+// Save writes a run and its output, then removes the oldest runs over Max.
+// The run's ID is derived from its start time if empty.
+func (hist *History) Save(run HistoryRun, output string) (HistoryRun, error) {
+	if run.ID == "" {
+		run.ID = historyID(run.Start)
+	}
+	if len(output) > maxHistoryOutput {
+		output = "[... output truncated ...]\n" + output[len(output)-maxHistoryOutput:]
+	}
+
+	if err := os.MkdirAll(hist.Dir, config.DefaultDirPerms); err != nil {
+		return run, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(hist.Dir, run.ID+".log"), []byte(output), config.DefaultFilePerms); err != nil {
+		return run, fmt.Errorf("failed to write run output: %w", err)
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return run, err
+	}
+	// The .json is written last: a run is listed only once its output exists
+	if err := os.WriteFile(filepath.Join(hist.Dir, run.ID+".json"), append(data, '\n'), config.DefaultFilePerms); err != nil {
+		return run, fmt.Errorf("failed to write run: %w", err)
+	}
+
+	hist.prune()
+	return run, nil
+}
+
+// List returns the runs in dir, newest first. A non-empty query keeps the
+// runs whose command or output contains it, ignoring case.
+func (hist *History) List(dir, query string) ([]HistoryRun, error) {
+	runs, err := hist.all()
+	if err != nil {
+		return nil, err
+	}
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	var matched []HistoryRun
+	for _, run := range runs {
+		if run.Dir != dir {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(run.Command()), query) {
+			output, _ := os.ReadFile(filepath.Join(hist.Dir, run.ID+".log"))
+			if !strings.Contains(strings.ToLower(string(output)), query) {
+				continue
+			}
+		}
+		matched = append(matched, run)
+	}
+	return matched, nil
+}
+
+// Get returns a run and its output.
+func (hist *History) Get(id string) (HistoryRun, string, error) {
+	if !validHistoryID(id) {
+		return HistoryRun{}, "", os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(hist.Dir, id+".json"))
+	if err != nil {
+		return HistoryRun{}, "", err
+	}
+	var run HistoryRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return HistoryRun{}, "", fmt.Errorf("invalid run %s: %w", id, err)
+	}
+	output, err := os.ReadFile(filepath.Join(hist.Dir, id+".log"))
+	if err != nil && !os.IsNotExist(err) {
+		return run, "", err
+	}
+	return run, string(output), nil
+}
+
+// all returns every saved run, newest first. Unreadable runs are skipped.
+func (hist *History) all() ([]HistoryRun, error) {
+	files, err := filepath.Glob(filepath.Join(hist.Dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var runs []HistoryRun
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+		var run HistoryRun
+		if json.Unmarshal(data, &run) != nil || !validHistoryID(run.ID) {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Start.After(runs[j].Start) })
+	return runs, nil
+}
+
+// prune removes the oldest runs over Max.
+func (hist *History) prune() {
+	limit := hist.Max
+	if limit <= 0 {
+		limit = maxHistoryRuns
+	}
+	runs, err := hist.all()
+	if err != nil || len(runs) <= limit {
+		return
+	}
+	for _, run := range runs[limit:] {
+		_ = os.Remove(filepath.Join(hist.Dir, run.ID+".json"))
+		_ = os.Remove(filepath.Join(hist.Dir, run.ID+".log"))
+	}
+}
+
+// historyID returns the ID of a run started at start.
+func historyID(start time.Time) string {
+	return strconv.FormatInt(start.UnixNano(), 10)
+}
+
+// validHistoryID reports whether id is a run ID (digits only), so it is
+// safe to use in a file name.
+func validHistoryID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// exitCode returns the exit code of a finished run: 0 on success, the
+// process exit code, or -1 if the task could not be started.
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// saveHistory persists a finished run from the RunStore.
+func (app *App) saveHistory(run TaskRun) {
+	if app.history == nil {
+		return
+	}
+	_, output, _ := app.runs.Get(run.ID)
+	user := run.User
+	if user == "" {
+		user = app.operator
+	}
+	_, err := app.history.Save(HistoryRun{
+		Task:     run.Task,
+		Args:     run.Args,
+		Dir:      app.config.WorkDir,
+		User:     user,
+		Start:    run.Start,
+		End:      run.End,
+		ExitCode: run.ExitCode,
+		Error:    run.Error,
+	}, output)
+	if err != nil {
+		log.Printf("Warning: Failed to save run history: %v", err)
+	}
+}
+
+// startRun runs a task in the background, recorded like a run started from
+// its page, and returns the run ID.
+func (app *App) startRun(user, task string, args []string) int {
+	id := app.runs.Start(task, args...)
+	app.runs.SetUser(id, user)
+	go func() {
+		err := runTaskWithCallback(task, args, app.config.WorkDir, func(line string) {
+			app.runs.AppendLine(id, line)
+		})
+		app.record(user, "task.run", task, err)
+		app.runs.Finish(id, err)
+	}()
+	return id
+}
+
+// handleHistoryRerun runs a past run's task again with the same args and
+// redirects to the new run.
+func (app *App) handleHistoryRerun(w http.ResponseWriter, r *http.Request) {
+	run, _, err := app.history.Get(r.PathValue("id"))
+	if err != nil || run.Dir != app.config.WorkDir {
+		http.NotFound(w, r)
+		return
+	}
+	id := app.startRun(app.requestUser(r), run.Task, run.Args)
+	http.Redirect(w, r, TaskRun{ID: id}.URL(), http.StatusSeeOther)
+}
+
+// rerunButton posts to the re-run endpoint of a run.
+func rerunButton(run HistoryRun, class string) h.H {
+	return h.Form(
+		h.Attr("method", "post"),
+		h.Attr("action", "/api/history/"+run.ID+"/rerun"),
+		h.Style("margin: 0;"),
+		h.Button(
+			h.Type("submit"),
+			h.Class(class),
+			h.Style("margin: 0; padding: 0.25rem 0.75rem;"),
+			h.Attr("title", "Run "+run.Command()+" again"),
+			h.Text("↻ Re-run"),
+		),
+	)
+}
+
+// viaHistoryPage lists past runs of this working directory, with a search
+// over their command and output.
+func (app *App) viaHistoryPage(c *via.Context) {
+	query := c.Signal("")
+	searchAction := c.Action(func() {
+		c.Sync()
+	})
+
+	c.View(func() h.H {
+		runs, err := app.history.List(app.config.WorkDir, query.String())
+
+		var content h.H
+		switch {
+		case err != nil:
+			content = h.P(h.Style("color: var(--pico-del-color);"), h.Text(err.Error()))
+		case len(runs) == 0 && query.String() != "":
+			content = h.P(h.Text("No runs match \"" + query.String() + "\"."))
+		case len(runs) == 0:
+			content = h.P(h.Text("No task runs yet. Runs started from the Tasks tab are saved here."))
+		default:
+			rows := []h.H{h.THead(h.Tr(
+				h.Th(h.Text("Started")), h.Th(h.Text("Command")), h.Th(h.Text("Duration")),
+				h.Th(h.Text("Exit")), h.Th(h.Text("User")), h.Th(),
+			))}
+			var body []h.H
+			for _, run := range runs {
+				exit := h.Text(strconv.Itoa(run.ExitCode))
+				if run.ExitCode != 0 {
+					exit = h.Span(h.Style("color: var(--pico-del-color);"), h.Attr("title", run.Error), h.Text(strconv.Itoa(run.ExitCode)))
+				}
+				body = append(body, h.Tr(
+					h.Td(h.Text(run.Start.Local().Format("2006-01-02 15:04:05"))),
+					h.Td(h.A(h.Href(run.URL()), h.Code(h.Text(run.Command())))),
+					h.Td(h.Text(run.Duration().String())),
+					h.Td(exit),
+					h.Td(h.Text(run.User)),
+					h.Td(h.If(!app.config.ReadOnly, rerunButton(run, "outline"))),
+				))
+			}
+			rows = append(rows, h.TBody(body...))
+			content = h.Table(rows...)
+		}
+
+		return h.Div(
+			app.renderNav(TabHistory),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.H3(h.Text("Task History")),
+					h.P(
+						h.Style("color: var(--pico-muted-color);"),
+						h.Text(filepath.ToSlash(app.history.Dir)),
+					),
+					h.Div(
+						h.Attr("role", "group"),
+						h.Input(
+							h.Type("search"),
+							h.Placeholder("Search task names, args and output"),
+							h.Value(query.String()),
+							query.Bind(),
+							searchAction.OnKeyDown("Enter"),
+						),
+						h.Button(h.Text("Search"), searchAction.OnClick()),
+					),
+					content,
+				),
+			),
+		)
+	})
+}
+
+// viaHistoryRunPage shows a past run's saved output.
+func (app *App) viaHistoryRunPage(c *via.Context) {
+	id := c.GetPathParam("id")
+
+	c.View(func() h.H {
+		run, output, err := app.history.Get(id)
+		if err != nil || run.Dir != app.config.WorkDir {
+			return h.Div(
+				app.renderNav(TabHistory),
+				h.Main(
+					h.Class("container"),
+					h.Article(
+						h.P(h.Text("Run "+id+" is not in the history of this project.")),
+						h.A(h.Href("/history"), h.Text("← History")),
+					),
+				),
+			)
+		}
+
+		statusText := fmt.Sprintf("Finished in %s, exit code %d", run.Duration(), run.ExitCode)
+		if run.Error != "" {
+			statusText = fmt.Sprintf("Failed after %s, exit code %d: %s", run.Duration(), run.ExitCode, run.Error)
+		}
+
+		return h.Div(
+			app.renderNav(TabHistory),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.Div(
+						h.Style("display: flex; justify-content: space-between; align-items: center; margin-bottom: 1rem;"),
+						h.Div(
+							h.H3(h.Style("margin: 0;"), h.Code(h.Text(run.Command()))),
+							h.Small(
+								h.Style("color: var(--pico-muted-color);"),
+								h.Text("Started "+run.Start.Local().Format("2006-01-02 15:04:05")),
+								h.If(run.User != "", h.Text(" by "+run.User)),
+							),
+						),
+						h.Div(
+							h.Style("display: flex; gap: 0.5rem;"),
+							h.If(!app.config.ReadOnly, rerunButton(run, "")),
+							h.A(
+								h.Href("/history"),
+								h.Class("secondary"),
+								h.Attr("role", "button"),
+								h.Style("padding: 0.25rem 0.75rem;"),
+								h.Text("← History"),
+							),
+						),
+					),
+					h.Div(
+						h.Style("background-color: #1e1e1e; color: #d4d4d4; padding: 1rem; border-radius: 0.5rem; min-height: 300px; font-family: 'Menlo', 'Monaco', 'Courier New', monospace; font-size: 14px; white-space: pre-wrap; overflow-y: auto; max-height: 500px;"),
+						ansiOutput(output),
+					),
+					h.Div(
+						h.Style("margin-top: 0.5rem; color: var(--pico-muted-color);"),
+						h.Small(h.Text(statusText)),
+					),
+				),
+			),
+		)
+	})
+}
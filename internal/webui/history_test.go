@@ -0,0 +1,77 @@
+package web
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	hist := &History{Dir: t.TempDir(), Max: 3}
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	save := func(task, dir string, offset time.Duration, output string) HistoryRun {
+		t.Helper()
+		run, err := hist.Save(HistoryRun{Task: task, Dir: dir, Start: start.Add(offset), End: start.Add(offset + time.Second)}, output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return run
+	}
+	build := save("build", "/proj", 0, "compiling\nok")
+	save("test", "/proj", time.Minute, "FAIL: TestParse")
+	save("build", "/other", 2*time.Minute, "compiling")
+
+	runs, err := hist.List("/proj", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].Task != "test" || runs[1].Task != "build" {
+		t.Errorf("List() = %+v, want test then build", runs)
+	}
+	if runs, _ := hist.List("/proj", "testparse"); len(runs) != 1 || runs[0].Task != "test" {
+		t.Errorf("List(output query) = %+v", runs)
+	}
+	if runs, _ := hist.List("/proj", "BUILD"); len(runs) != 1 || runs[0].ID != build.ID {
+		t.Errorf("List(task query) = %+v", runs)
+	}
+
+	run, output, err := hist.Get(build.ID)
+	if err != nil || run.Task != "build" || output != "compiling\nok" {
+		t.Errorf("Get() = %+v, %q, %v", run, output, err)
+	}
+	if _, _, err := hist.Get("../secrets"); err == nil {
+		t.Error("Get() accepted a path as ID")
+	}
+
+	// Over Max, the oldest run is removed
+	save("lint", "/proj", 3*time.Minute, "")
+	if _, _, err := hist.Get(build.ID); err == nil {
+		t.Error("oldest run should be pruned")
+	}
+}
+
+func TestHistoryOutputTruncated(t *testing.T) {
+	hist := &History{Dir: t.TempDir()}
+	run, err := hist.Save(HistoryRun{Task: "logs", Start: time.Now()}, strings.Repeat("x", maxHistoryOutput+10)+"end")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, output, _ := hist.Get(run.ID)
+	if !strings.HasPrefix(output, "[... output truncated ...]") || !strings.HasSuffix(output, "end") {
+		t.Errorf("output not truncated to its tail (%d bytes)", len(output))
+	}
+}
+
+func TestRunStoreArgsAndExitCode(t *testing.T) {
+	s := &RunStore{}
+	id := s.Start("release", "VERSION=1.2")
+	s.Finish(id, errors.New("xplat not found"))
+
+	run, _, _ := s.Get(id)
+	if !reflect.DeepEqual(run.Args, []string{"VERSION=1.2"}) || run.ExitCode != -1 {
+		t.Errorf("run = %+v, want args and exit code -1", run)
+	}
+}
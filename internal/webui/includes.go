@@ -0,0 +1,388 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-task/task/v3/taskfile"
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/config"
+)
+
+// maxIncludeDepth stops include cycles from recursing forever.
+const maxIncludeDepth = 10
+
+// IncludeNode is one Taskfile in the include tree. Remote nodes are read
+// from Task's remote cache (.task/remote) only, so building the graph never
+// downloads anything.
+type IncludeNode struct {
+	Namespace string `json:"namespace,omitempty"` // Include key in the parent ("" for the root)
+	Location  string `json:"location"`            // Path or URL
+	Remote    bool   `json:"remote,omitempty"`
+	Optional  bool   `json:"optional,omitempty"`
+
+	// Repo and Ref are the GitHub repo and branch, tag or commit of a
+	// remote include, when the URL names them.
+	Repo string `json:"repo,omitempty"`
+	Ref  string `json:"ref,omitempty"`
+
+	// CacheKey names the cached copy in .task/remote; CachedAt is when Task
+	// downloaded it. Stale means older than the cache expiry.
+	CacheKey string    `json:"cache_key,omitempty"`
+	Cached   bool      `json:"cached,omitempty"`
+	CachedAt time.Time `json:"cached_at,omitzero"`
+	Stale    bool      `json:"stale,omitempty"`
+
+	Error    string         `json:"error,omitempty"`
+	Children []*IncludeNode `json:"children,omitempty"`
+}
+
+// Status is a one-word state for display: local, cached, stale, missing or error.
+func (n *IncludeNode) Status() string {
+	switch {
+	case n.Remote && n.Stale:
+		return "stale"
+	case n.Remote && n.Cached:
+		return "cached"
+	case n.Remote && n.CacheKey != "":
+		return "missing"
+	case n.Error != "":
+		return "error"
+	default:
+		return "local"
+	}
+}
+
+// Walk calls fn for n and every node below it.
+func (n *IncludeNode) Walk(fn func(*IncludeNode)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// RemoteCacheDir returns Task's remote Taskfile cache for a project.
+func RemoteCacheDir(workDir string) string {
+	return filepath.Join(workDir, ".task", "remote")
+}
+
+// BuildIncludeGraph reads the include tree of filename (relative to
+// workDir). Cached remote includes older than expiry are marked stale.
+func BuildIncludeGraph(filename, workDir string, expiry time.Duration) (*IncludeNode, error) {
+	path := filename
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, filename)
+	}
+	root, err := taskfile.NewFileNode(path, workDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(root.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	node := &IncludeNode{Location: root.Location()}
+	addIncludes(node, root, data, filepath.Join(workDir, ".task"), expiry, 0)
+	return node, nil
+}
+
+// addIncludes parses data (the Taskfile of parent) and adds its includes.
+func addIncludes(node *IncludeNode, parent taskfile.Node, data []byte, tempDir string, expiry time.Duration, depth int) {
+	var tf Taskfile
+	if err := yaml.Unmarshal(data, &tf); err != nil {
+		node.Error = err.Error()
+		return
+	}
+	if depth >= maxIncludeDepth {
+		if len(tf.Includes) > 0 {
+			node.Error = fmt.Sprintf("includes nested deeper than %d levels", maxIncludeDepth)
+		}
+		return
+	}
+
+	namespaces := make([]string, 0, len(tf.Includes))
+	for namespace := range tf.Includes {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		include := tf.Includes[namespace]
+		child := &IncludeNode{Namespace: namespace, Location: include.Taskfile, Optional: include.Optional}
+		node.Children = append(node.Children, child)
+
+		if strings.Contains(include.Taskfile, "{{") {
+			child.Error = "templated include, resolved when Task runs"
+			continue
+		}
+		entrypoint, err := parent.ResolveEntrypoint(include.Taskfile)
+		if err != nil {
+			child.Error = err.Error()
+			continue
+		}
+		n, err := newIncludeNode(entrypoint, parent)
+		if err != nil {
+			child.Error = err.Error()
+			continue
+		}
+		child.Location = n.Location()
+
+		var content []byte
+		if remote, ok := n.(taskfile.RemoteNode); ok {
+			child.Remote = true
+			child.Repo, child.Ref = remoteOrigin(entrypoint)
+			cache := taskfile.NewCacheNode(remote, tempDir)
+			child.CacheKey = filepath.Base(cache.Location())
+			content, err = cache.Read()
+			if err != nil {
+				child.Error = "not downloaded yet (run 'xplat task --download')"
+				continue
+			}
+			child.Cached = true
+			child.CachedAt = cache.ReadTimestamp()
+			child.Stale = expiry > 0 && time.Since(child.CachedAt) > expiry
+		} else {
+			if content, err = n.Read(); err != nil {
+				child.Error = err.Error()
+				continue
+			}
+		}
+		addIncludes(child, n, content, tempDir, expiry, depth+1)
+	}
+}
+
+// newIncludeNode creates the Task node for an include. taskfile.NewNode
+// refuses remote nodes unless the remote Taskfiles experiment is enabled in
+// this process, which only 'xplat task' does, so the scheme is picked here.
+func newIncludeNode(entrypoint string, parent taskfile.Node) (taskfile.Node, error) {
+	switch {
+	case strings.Contains(entrypoint, ".git//") || strings.HasPrefix(entrypoint, "git@"):
+		return taskfile.NewGitNode(entrypoint, parent.Dir(), false, taskfile.WithParent(parent))
+	case strings.HasPrefix(entrypoint, "https://") || strings.HasPrefix(entrypoint, "http://"):
+		return taskfile.NewHTTPNode(entrypoint, parent.Dir(), false, taskfile.WithParent(parent))
+	default:
+		return taskfile.NewFileNode(entrypoint, parent.Dir(), taskfile.WithParent(parent))
+	}
+}
+
+// remoteOrigin returns the GitHub repo and ref a remote include comes from:
+// raw.githubusercontent.com/<owner>/<repo>/<ref>/..., github.com/<owner>/<repo>/raw/<ref>/...
+// or a git URL with ?ref=.
+func remoteOrigin(location string) (repo, ref string) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	if r := u.Query().Get("ref"); r != "" || strings.HasSuffix(strings.Split(u.Path, "//")[0], ".git") {
+		// git: https://github.com/owner/repo.git//path?ref=v1
+		if len(parts) >= 2 {
+			repo = parts[0] + "/" + strings.TrimSuffix(parts[1], ".git")
+		}
+		return repo, r
+	}
+
+	switch {
+	case u.Host == "raw.githubusercontent.com" && len(parts) >= 3:
+		return parts[0] + "/" + parts[1], parts[2]
+	case u.Host == "github.com" && len(parts) >= 4 && (parts[2] == "raw" || parts[2] == "blob"):
+		return parts[0] + "/" + parts[1], parts[3]
+	}
+	return "", ""
+}
+
+// InvalidateInclude deletes a cached remote Taskfile (and its checksum and
+// timestamp) so Task downloads it again on the next run. key must be the
+// CacheKey of a node in the graph.
+func InvalidateInclude(workDir string, graph *IncludeNode, key string) error {
+	found := false
+	graph.Walk(func(n *IncludeNode) {
+		if n.Remote && n.CacheKey == key {
+			found = true
+		}
+	})
+	if !found || key == "" {
+		return fmt.Errorf("no cached include %q", key)
+	}
+
+	base := strings.TrimSuffix(filepath.Join(RemoteCacheDir(workDir), key), ".yaml")
+	for _, suffix := range []string{".yaml", ".checksum", ".timestamp"} {
+		if err := os.Remove(base + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeStatusColors are the badge colors per IncludeNode.Status.
+var includeStatusColors = map[string]string{
+	"local":   "#6c757d",
+	"cached":  "#198754",
+	"stale":   "#fd7e14",
+	"missing": "#0d6efd",
+	"error":   "#dc3545",
+}
+
+// viaIncludesPage renders the Taskfile include tree with the cache state of
+// each remote include and a button to invalidate it.
+func (app *App) viaIncludesPage(c *via.Context) {
+	c.View(func() h.H {
+		expiry := config.GetTaskDefaults().CacheExpiryDuration
+		graph, err := BuildIncludeGraph(app.config.Taskfile, app.config.WorkDir, expiry)
+
+		var content h.H
+		if err != nil {
+			content = h.P(h.Style("color: var(--pico-del-color);"), h.Text(err.Error()))
+		} else {
+			stale := 0
+			graph.Walk(func(n *IncludeNode) {
+				if n.Stale {
+					stale++
+				}
+			})
+			content = h.Div(
+				h.P(
+					h.Style("color: var(--pico-muted-color);"),
+					h.Text(fmt.Sprintf("Remote includes are cached in %s and stale after %s.", filepath.ToSlash(RemoteCacheDir(app.config.WorkDir)), expiry)),
+				),
+				h.If(stale > 0,
+					h.Button(
+						h.Class("outline"),
+						h.Style("padding: 0.25rem 0.75rem;"),
+						h.Attr("onclick", "invalidateInclude('')"),
+						h.Text(fmt.Sprintf("Invalidate %d stale", stale)),
+					),
+				),
+				h.Ul(h.Style("list-style: none; padding-left: 0;"), renderIncludeNode(graph)),
+			)
+		}
+
+		return h.Div(
+			app.renderNav(TabIncludes),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.H3(h.Text("Taskfile Includes")),
+					content,
+				),
+			),
+			h.Script(h.Raw(`
+// Invalidate one cached include (or every stale one for an empty key), then reload
+function invalidateInclude(key) {
+	fetch('/api/includes/invalidate?key=' + encodeURIComponent(key), {method: 'POST'})
+		.then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+		.then(() => location.reload())
+		.catch(err => alert('Error: ' + err.message));
+}
+`)),
+		)
+	})
+}
+
+// renderIncludeNode renders a node and, nested below it, its includes.
+func renderIncludeNode(n *IncludeNode) h.H {
+	status := n.Status()
+	label := n.Location
+	if n.Namespace != "" {
+		label = n.Namespace + ": " + n.Location
+	}
+
+	line := []h.H{
+		h.Style("display: flex; flex-wrap: wrap; gap: 0.5rem; align-items: center;"),
+		h.Span(
+			h.Style("background-color: "+includeStatusColors[status]+"; color: white; padding: 0.1rem 0.5rem; border-radius: 0.25rem; font-size: 0.8rem;"),
+			h.Text(status),
+		),
+		h.Code(h.Text(label)),
+	}
+	if n.Repo != "" {
+		origin := n.Repo
+		if n.Ref != "" {
+			origin += "@" + n.Ref
+		}
+		line = append(line, h.Small(h.Text(origin)))
+	}
+	if n.Cached {
+		line = append(line, h.Small(
+			h.Style("color: var(--pico-muted-color);"),
+			h.Text("cached "+n.CachedAt.Local().Format("2006-01-02 15:04")),
+		))
+		line = append(line, h.Button(
+			h.Class("outline secondary"),
+			h.Style("padding: 0 0.5rem; margin: 0; font-size: 0.8rem;"),
+			h.Attr("onclick", fmt.Sprintf("invalidateInclude('%s')", n.CacheKey)),
+			h.Text("Invalidate"),
+		))
+	}
+	if n.Optional {
+		line = append(line, h.Small(h.Text("optional")))
+	}
+	if n.Error != "" {
+		line = append(line, h.Small(h.Style("color: var(--pico-del-color);"), h.Text(n.Error)))
+	}
+
+	item := []h.H{h.Div(line...)}
+	if len(n.Children) > 0 {
+		children := []h.H{h.Style("list-style: none; border-left: 1px solid var(--pico-muted-border-color); padding-left: 1rem; margin-left: 0.5rem;")}
+		for _, c := range n.Children {
+			children = append(children, renderIncludeNode(c))
+		}
+		item = append(item, h.Ul(children...))
+	}
+	return h.Li(item...)
+}
+
+// handleIncludeGraph serves the include tree as JSON.
+func (app *App) handleIncludeGraph(w http.ResponseWriter, r *http.Request) {
+	graph, err := BuildIncludeGraph(app.config.Taskfile, app.config.WorkDir, config.GetTaskDefaults().CacheExpiryDuration)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(graph)
+}
+
+// handleIncludeInvalidate deletes the cached copy of the include named by
+// the key query parameter, or of every stale include when key is empty.
+func (app *App) handleIncludeInvalidate(w http.ResponseWriter, r *http.Request) {
+	graph, err := BuildIncludeGraph(app.config.Taskfile, app.config.WorkDir, config.GetTaskDefaults().CacheExpiryDuration)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var keys []string
+	if key := r.URL.Query().Get("key"); key != "" {
+		keys = []string{key}
+	} else {
+		graph.Walk(func(n *IncludeNode) {
+			if n.Stale {
+				keys = append(keys, n.CacheKey)
+			}
+		})
+	}
+
+	for _, key := range keys {
+		err := InvalidateInclude(app.config.WorkDir, graph, key)
+		app.record(app.requestUser(r), "include.invalidate", key, err)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"invalidated": keys})
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-task/task/v3/taskfile"
+)
+
+func TestBuildIncludeGraph(t *testing.T) {
+	dir := t.TempDir()
+	const remoteURL = "https://raw.githubusercontent.com/joeblew999/xplat/v1.2.0/taskfiles/Taskfile.go.yml"
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Taskfile.yml", `version: '3'
+includes:
+  sub: ./sub/Taskfile.yml
+  go: `+remoteURL+`
+  tmpl: '{{.ROOT_DIR}}/x.yml'
+`)
+	write("sub/Taskfile.yml", "version: '3'\nincludes:\n  gone:\n    taskfile: ./missing.yml\n    optional: true\n")
+
+	// Cache the remote include the way Task does, downloaded two days ago
+	remote, err := taskfile.NewHTTPNode(remoteURL, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := taskfile.NewCacheNode(remote, filepath.Join(dir, ".task"))
+	if err := cache.Write([]byte("version: '3'\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.WriteTimestamp(time.Now().Add(-48 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	graph, err := BuildIncludeGraph("Taskfile.yml", dir, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(graph.Children) != 3 {
+		t.Fatalf("children = %+v", graph.Children)
+	}
+	goInc, sub, tmpl := graph.Children[0], graph.Children[1], graph.Children[2]
+
+	if goInc.Status() != "stale" || goInc.Repo != "joeblew999/xplat" || goInc.Ref != "v1.2.0" {
+		t.Errorf("remote include = %+v (%s)", goInc, goInc.Status())
+	}
+	if sub.Status() != "local" || len(sub.Children) != 1 || sub.Children[0].Status() != "error" || !sub.Children[0].Optional {
+		t.Errorf("local include = %+v", sub)
+	}
+	if tmpl.Status() != "error" {
+		t.Errorf("templated include = %+v", tmpl)
+	}
+
+	if err := InvalidateInclude(dir, graph, "../../etc/passwd"); err == nil {
+		t.Error("InvalidateInclude() accepted a key outside the graph")
+	}
+	if err := InvalidateInclude(dir, graph, goInc.CacheKey); err != nil {
+		t.Fatal(err)
+	}
+	graph, err = BuildIncludeGraph("Taskfile.yml", dir, 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := graph.Children[0].Status(); got != "missing" {
+		t.Errorf("after invalidation status = %s", got)
+	}
+}
+
+func TestRemoteOrigin(t *testing.T) {
+	tests := []struct {
+		location, repo, ref string
+	}{
+		{"https://raw.githubusercontent.com/joeblew999/xplat/main/Taskfile.yml", "joeblew999/xplat", "main"},
+		{"https://github.com/joeblew999/plat-caddy/raw/v0.3.0/taskfiles/Taskfile.service.yml", "joeblew999/plat-caddy", "v0.3.0"},
+		{"https://github.com/joeblew999/xplat.git//taskfiles/Taskfile.yml?ref=abc123", "joeblew999/xplat", "abc123"},
+		{"https://example.com/Taskfile.yml", "", ""},
+	}
+	for _, tt := range tests {
+		if repo, ref := remoteOrigin(tt.location); repo != tt.repo || ref != tt.ref {
+			t.Errorf("remoteOrigin(%q) = %q, %q, want %q, %q", tt.location, repo, ref, tt.repo, tt.ref)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// defaultLogTail is how many existing lines per process a stream starts with.
+const defaultLogTail = 200
+
+// LogLine is one line of process output.
+type LogLine struct {
+	Process string `json:"process"`
+	Line    string `json:"line"`
+}
+
+// StreamLogs tails the logs of names through process-compose's WebSocket
+// log API, calling fn for the last tail lines of each process and then for
+// every new line, until ctx is done or process-compose closes the stream.
+func (c *ProcessComposeClient) StreamLogs(ctx context.Context, names []string, tail int, fn func(LogLine)) error {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return err
+	}
+	u.Scheme = strings.Replace(u.Scheme, "http", "ws", 1)
+	u.Path = "/process/logs/ws"
+	u.RawQuery = url.Values{
+		"name":   {strings.Join(names, ",")},
+		"offset": {strconv.Itoa(tail)},
+		"follow": {"true"},
+	}.Encode()
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to stream logs: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Unblock ReadJSON when the browser goes away
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	for {
+		var msg struct {
+			Message     string `json:"message"`
+			ProcessName string `json:"process_name"`
+		}
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil || websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return nil
+			}
+			return fmt.Errorf("log stream: %w", err)
+		}
+		if msg.ProcessName != "" {
+			fn(LogLine{Process: msg.ProcessName, Line: msg.Message})
+		}
+	}
+}
+
+// logStreamHandler streams process logs as server-sent "log" events:
+//
+//	GET /api/process/logs/stream/{name}   one process
+//	GET /api/process/logs/stream?name=a,b those processes, all when omitted
+//
+// ?tail=N sets how many existing lines per process come first. A browser
+// EventSource reconnects on its own when process-compose restarts.
+func logStreamHandler(client *ProcessComposeClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		var names []string
+		if name := r.PathValue("name"); name != "" {
+			names = []string{name}
+		} else if q := r.URL.Query().Get("name"); q != "" {
+			names = strings.Split(q, ",")
+		} else {
+			processes, err := client.ListProcesses()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadGateway)
+				return
+			}
+			for _, p := range processes {
+				names = append(names, p.Name)
+			}
+		}
+		if len(names) == 0 {
+			http.Error(w, "no processes", http.StatusNotFound)
+			return
+		}
+		tail := defaultLogTail
+		if n, err := strconv.Atoi(r.URL.Query().Get("tail")); err == nil && n >= 0 {
+			tail = n
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprint(w, "retry: 5000\n\n")
+		flusher.Flush()
+
+		lines := make(chan LogLine, 256)
+		done := make(chan error, 1)
+		go func() {
+			done <- client.StreamLogs(r.Context(), names, tail, func(l LogLine) {
+				select {
+				case lines <- l:
+				case <-r.Context().Done():
+				}
+			})
+		}()
+
+		// Comments keep proxies (Caddy, cloudflared) from closing an idle stream
+		heartbeat := time.NewTicker(30 * time.Second)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-done:
+				// Flush what was read before the stream ended
+				for {
+					select {
+					case l := <-lines:
+						writeLogEvent(w, l)
+					default:
+						flusher.Flush()
+						return
+					}
+				}
+			case <-heartbeat.C:
+				_, _ = fmt.Fprint(w, ": ping\n\n")
+			case l := <-lines:
+				writeLogEvent(w, l)
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeLogEvent(w http.ResponseWriter, l LogLine) {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+}
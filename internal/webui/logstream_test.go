@@ -0,0 +1,66 @@
+package web
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestLogStream(t *testing.T) {
+	var query string
+	pc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/process/logs/ws" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.WriteJSON(map[string]string{"message": "listening on :8080", "process_name": "api"})
+		_ = conn.WriteJSON(map[string]string{"message": "ready", "process_name": "db"})
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer pc.Close()
+
+	client := NewProcessComposeClient(0)
+	client.BaseURL = pc.URL
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/process/logs/stream", logStreamHandler(client))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/api/process/logs/stream?name=api,db&tail=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if d, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			data = append(data, d)
+		}
+	}
+	want := []string{
+		`{"process":"api","line":"listening on :8080"}`,
+		`{"process":"db","line":"ready"}`,
+	}
+	if strings.Join(data, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events = %q, want %q", data, want)
+	}
+	if query != "follow=true&name=api%2Cdb&offset=10" {
+		t.Errorf("process-compose query = %q", query)
+	}
+}
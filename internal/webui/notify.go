@@ -0,0 +1,446 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+)
+
+// Desktop notifications: browsers that opt in (the bell in the nav) keep an
+// EventSource on /api/notifications/stream and show each UINotification with
+// the Notification API. Clicking one opens its URL, e.g. the run's page.
+
+// UINotification is pushed to browsers when a task run started from the UI
+// ends or a process crashes.
+type UINotification struct {
+	ID     string    `json:"id"`     // Also the Notification tag, so tabs don't show it twice
+	Kind   string    `json:"kind"`   // task, process
+	Name   string    `json:"name"`   // Task or process name
+	Status string    `json:"status"` // finished, error, crashed
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+	URL    string    `json:"url"`
+	Time   time.Time `json:"time"`
+}
+
+// notificationHub fans notifications out to the connected browsers.
+type notificationHub struct {
+	mu   sync.Mutex
+	subs map[chan UINotification]struct{}
+}
+
+func newNotificationHub() *notificationHub {
+	return &notificationHub{subs: make(map[chan UINotification]struct{})}
+}
+
+// Subscribe returns a channel of notifications and a function to unsubscribe.
+func (hub *notificationHub) Subscribe() (<-chan UINotification, func()) {
+	ch := make(chan UINotification, 16)
+	hub.mu.Lock()
+	hub.subs[ch] = struct{}{}
+	hub.mu.Unlock()
+	return ch, func() {
+		hub.mu.Lock()
+		delete(hub.subs, ch)
+		hub.mu.Unlock()
+	}
+}
+
+// Subscribers returns the number of connected browsers.
+func (hub *notificationHub) Subscribers() int {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	return len(hub.subs)
+}
+
+// Publish sends n to every subscriber. A browser that is not keeping up
+// misses it rather than blocking the task or the process watcher.
+func (hub *notificationHub) Publish(n UINotification) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	for ch := range hub.subs {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+}
+
+// ServeHTTP streams notifications as server-sent "notification" events.
+func (hub *notificationHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	// Comments keep proxies (Caddy, cloudflared) from closing an idle stream
+	heartbeat := time.NewTicker(30 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+		case n := <-ch:
+			data, err := json.Marshal(n)
+			if err != nil {
+				continue
+			}
+			_, _ = fmt.Fprintf(w, "event: notification\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
+
+// Run statuses.
+const (
+	RunRunning  = "running"
+	RunFinished = "finished"
+	RunError    = "error"
+)
+
+// TaskRun is a task run started from the UI, kept so a notification can
+// link back to its output.
+type TaskRun struct {
+	ID       int
+	Task     string
+	Args     []string
+	User     string // Who started the run, if known
+	Start    time.Time
+	End      time.Time
+	Status   string
+	ExitCode int
+	Error    string
+
+	output strings.Builder
+}
+
+// maxRuns is the number of task runs RunStore keeps.
+const maxRuns = 50
+
+// RunStore keeps the latest task runs in memory.
+type RunStore struct {
+	mu     sync.RWMutex
+	nextID int
+	runs   []*TaskRun
+
+	// OnFinish, if set, is called when a run ends
+	OnFinish func(run TaskRun)
+}
+
+// Start records a new run of task with args.
+func (s *RunStore) Start(task string, args ...string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.runs = append(s.runs, &TaskRun{ID: s.nextID, Task: task, Args: args, Start: time.Now(), Status: RunRunning})
+	if len(s.runs) > maxRuns {
+		s.runs = s.runs[len(s.runs)-maxRuns:]
+	}
+	return s.nextID
+}
+
+// SetUser records who started a run.
+func (s *RunStore) SetUser(id int, user string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run := s.find(id); run != nil {
+		run.User = user
+	}
+}
+
+// AppendLine adds an output line to a run.
+func (s *RunStore) AppendLine(id int, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if run := s.find(id); run != nil {
+		if run.output.Len() > 0 {
+			run.output.WriteByte('\n')
+		}
+		run.output.WriteString(line)
+	}
+}
+
+// Finish records the outcome of a run and calls OnFinish.
+func (s *RunStore) Finish(id int, err error) {
+	s.mu.Lock()
+	run := s.find(id)
+	if run == nil {
+		s.mu.Unlock()
+		return
+	}
+	run.End = time.Now()
+	run.Status = RunFinished
+	run.ExitCode = exitCode(err)
+	if err != nil {
+		run.Status = RunError
+		run.Error = err.Error()
+	}
+	snapshot := run.snapshot()
+	s.mu.Unlock()
+
+	if s.OnFinish != nil {
+		s.OnFinish(snapshot)
+	}
+}
+
+// Get returns a copy of a run and its output.
+func (s *RunStore) Get(id int) (TaskRun, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	run := s.find(id)
+	if run == nil {
+		return TaskRun{}, "", false
+	}
+	return run.snapshot(), run.output.String(), true
+}
+
+func (s *RunStore) find(id int) *TaskRun {
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+// snapshot copies the run without its output.
+func (run *TaskRun) snapshot() TaskRun {
+	return TaskRun{
+		ID: run.ID, Task: run.Task, Args: run.Args, User: run.User,
+		Start: run.Start, End: run.End, Status: run.Status, ExitCode: run.ExitCode, Error: run.Error,
+	}
+}
+
+// Duration returns how long the run took, or has been running.
+func (run TaskRun) Duration() time.Duration {
+	end := run.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(run.Start).Round(time.Second)
+}
+
+// URL returns the run's page.
+func (run TaskRun) URL() string {
+	return "/runs/" + strconv.Itoa(run.ID)
+}
+
+// runNotification returns the notification for a finished run.
+func runNotification(run TaskRun) UINotification {
+	n := UINotification{
+		ID:     "run-" + strconv.Itoa(run.ID),
+		Kind:   "task",
+		Name:   run.Task,
+		Status: run.Status,
+		Title:  "✓ task " + run.Task,
+		Body:   "Finished in " + run.Duration().String(),
+		URL:    run.URL(),
+		Time:   run.End,
+	}
+	if run.Status == RunError {
+		n.Title = "✗ task " + run.Task
+		n.Body = "Failed after " + run.Duration().String() + ": " + run.Error
+	}
+	return n
+}
+
+// processCrashes returns a notification for each process that exited with
+// an error or was restarted between two listings.
+func processCrashes(prev, curr []ProcessInfo, now time.Time) []UINotification {
+	before := make(map[string]ProcessInfo, len(prev))
+	for _, p := range prev {
+		before[p.Name] = p
+	}
+
+	var crashes []UINotification
+	for _, p := range curr {
+		old, ok := before[p.Name]
+		if !ok {
+			continue
+		}
+		var body string
+		switch {
+		case p.Restarts > old.Restarts:
+			body = fmt.Sprintf("Restarted (%d restarts), status %s", p.Restarts, p.Status)
+		case old.IsRunning && !p.IsRunning && p.ExitCode != 0:
+			body = fmt.Sprintf("Exited with code %d, status %s", p.ExitCode, p.Status)
+		default:
+			continue
+		}
+		crashes = append(crashes, UINotification{
+			ID:     fmt.Sprintf("process-%s-%d", p.Name, now.Unix()),
+			Kind:   "process",
+			Name:   p.Name,
+			Status: "crashed",
+			Title:  "✗ process " + p.Name,
+			Body:   body,
+			URL:    "/processes",
+			Time:   now,
+		})
+	}
+	return crashes
+}
+
+// processWatchInterval is how often processes are polled for crashes while
+// a browser is subscribed.
+const processWatchInterval = 5 * time.Second
+
+// watchProcesses publishes process crashes until ctx ends. process-compose
+// is only polled while a browser is listening.
+func (app *App) watchProcesses(ctx context.Context) {
+	ticker := time.NewTicker(processWatchInterval)
+	defer ticker.Stop()
+
+	var prev []ProcessInfo
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if app.notifications.Subscribers() == 0 {
+			prev = nil
+			continue
+		}
+		curr, err := app.pcClient.ListProcesses()
+		if err != nil {
+			continue
+		}
+		for _, n := range processCrashes(prev, curr, time.Now()) {
+			app.notifications.Publish(n)
+		}
+		prev = curr
+	}
+}
+
+// viaRunPage shows the output of a task run, the target of a run's
+// notification.
+func (app *App) viaRunPage(c *via.Context) {
+	id, _ := strconv.Atoi(c.GetPathParam("id"))
+
+	c.View(func() h.H {
+		run, output, ok := app.runs.Get(id)
+		if !ok {
+			return h.Div(
+				app.renderNav(TabTasks),
+				h.Main(
+					h.Class("container"),
+					h.Article(
+						h.P(h.Text(fmt.Sprintf("Run #%d is not available (the UI keeps the last %d runs since it started).", id, maxRuns))),
+						h.A(h.Href("/tasks"), h.Text("← Tasks")),
+					),
+				),
+			)
+		}
+
+		statusText := "Finished in " + run.Duration().String()
+		switch run.Status {
+		case RunRunning:
+			statusText = "Running for " + run.Duration().String() + " (reload for more output)"
+		case RunError:
+			statusText = "Failed after " + run.Duration().String() + ": " + run.Error
+		}
+
+		return h.Div(
+			app.renderNav(TabTasks),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.Div(
+						h.Style("display: flex; justify-content: space-between; align-items: center; margin-bottom: 1rem;"),
+						h.Div(
+							h.H3(h.Style("margin: 0;"), h.Code(h.Text(strings.Join(append([]string{"task", run.Task}, run.Args...), " ")))),
+							h.Small(
+								h.Style("color: var(--pico-muted-color);"),
+								h.Text(fmt.Sprintf("Run #%d, started %s", run.ID, run.Start.Format("15:04:05"))),
+							),
+						),
+						h.A(
+							h.Href("/tasks/"+run.Task),
+							h.Class("secondary"),
+							h.Attr("role", "button"),
+							h.Text("Run again"),
+						),
+					),
+					h.Div(
+						h.Style("background-color: #1e1e1e; color: #d4d4d4; padding: 1rem; border-radius: 0.5rem; min-height: 300px; font-family: 'Menlo', 'Monaco', 'Courier New', monospace; font-size: 14px; white-space: pre-wrap; overflow-y: auto; max-height: 500px;"),
+						ansiOutput(output),
+					),
+					h.Div(
+						h.Style("margin-top: 0.5rem; color: var(--pico-muted-color);"),
+						h.Small(h.Text(statusText)),
+					),
+				),
+			),
+		)
+	})
+}
+
+// notifyScript drives the opt-in bell in the nav (see RenderNav). The choice
+// is kept in localStorage; the stream is only opened once permission is granted.
+const notifyScript = `
+(function () {
+  const key = 'xplat-notifications';
+  const btn = document.getElementById('xplat-notify-toggle');
+  if (!btn || !('Notification' in window)) return;
+  let source = null;
+
+  function enabled() {
+    return localStorage.getItem(key) === 'on' && Notification.permission === 'granted';
+  }
+  function render() {
+    const on = enabled();
+    btn.textContent = on ? '🔔' : '🔕';
+    btn.title = on
+      ? 'Desktop notifications on (click to turn off)'
+      : 'Notify me when a task finishes or a process crashes';
+    btn.style.display = '';
+  }
+  function connect() {
+    if (source) return;
+    source = new EventSource('/api/notifications/stream');
+    source.addEventListener('notification', (e) => {
+      const n = JSON.parse(e.data);
+      const note = new Notification(n.title, { body: n.body, tag: n.id });
+      note.onclick = () => { window.focus(); location.href = n.url; note.close(); };
+    });
+  }
+  function disconnect() {
+    if (source) { source.close(); source = null; }
+  }
+
+  btn.addEventListener('click', async (e) => {
+    e.preventDefault();
+    if (enabled()) {
+      localStorage.setItem(key, 'off');
+      disconnect();
+    } else if (await Notification.requestPermission() === 'granted') {
+      localStorage.setItem(key, 'on');
+      connect();
+    }
+    render();
+  });
+
+  render();
+  if (enabled()) connect();
+})();
+`
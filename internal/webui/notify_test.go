@@ -0,0 +1,101 @@
+package web
+
+import (
+	"bufio"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNotificationStream(t *testing.T) {
+	hub := newNotificationHub()
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	// The handler subscribes after sending the headers
+	for deadline := time.Now().Add(5 * time.Second); hub.Subscribers() == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("stream did not subscribe")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	hub.Publish(UINotification{ID: "run-1", Title: "✓ task build", URL: "/runs/1"})
+
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 2 || lines[0] != "event: notification" || !strings.Contains(lines[1], `"url":"/runs/1"`) {
+		t.Errorf("event = %q", lines)
+	}
+}
+
+func TestRunStore(t *testing.T) {
+	var finished []UINotification
+	s := &RunStore{OnFinish: func(run TaskRun) {
+		finished = append(finished, runNotification(run))
+	}}
+
+	id := s.Start("build")
+	s.AppendLine(id, "compiling")
+	s.AppendLine(id, "done")
+	s.Finish(id, nil)
+
+	run, output, ok := s.Get(id)
+	if !ok || run.Status != RunFinished || output != "compiling\ndone" {
+		t.Errorf("Get() = %+v, %q, %v", run, output, ok)
+	}
+
+	failed := s.Start("test")
+	s.Finish(failed, errors.New("exit status 1"))
+	if len(finished) != 2 || finished[0].URL != "/runs/1" || !strings.HasPrefix(finished[1].Title, "✗") || !strings.Contains(finished[1].Body, "exit status 1") {
+		t.Errorf("notifications = %+v", finished)
+	}
+
+	for i := 0; i < maxRuns; i++ {
+		s.Start("lint")
+	}
+	if _, _, ok := s.Get(id); ok {
+		t.Error("oldest run should be dropped")
+	}
+}
+
+func TestProcessCrashes(t *testing.T) {
+	prev := []ProcessInfo{
+		{Name: "api", IsRunning: true},
+		{Name: "worker", IsRunning: true},
+		{Name: "migrate", IsRunning: true},
+		{Name: "web", IsRunning: true},
+	}
+	curr := []ProcessInfo{
+		{Name: "api", IsRunning: true, Restarts: 1, Status: "Running"},
+		{Name: "worker", IsRunning: false, ExitCode: 2, Status: "Error"},
+		{Name: "migrate", IsRunning: false, ExitCode: 0, Status: "Completed"},
+		{Name: "web", IsRunning: true},
+		{Name: "new", IsRunning: false, ExitCode: 1},
+	}
+
+	crashes := processCrashes(prev, curr, time.Now())
+	if len(crashes) != 2 || crashes[0].Name != "api" || crashes[1].Name != "worker" {
+		t.Fatalf("processCrashes() = %+v", crashes)
+	}
+	if crashes[1].Body != "Exited with code 2, status Error" || crashes[1].URL != "/processes" {
+		t.Errorf("crash = %+v", crashes[1])
+	}
+	if processCrashes(nil, curr, time.Now()) != nil {
+		t.Error("no crashes without a previous listing")
+	}
+}
@@ -4,12 +4,18 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/joeblew999/xplat/internal/manifest"
+	"github.com/joeblew999/xplat/internal/projectstate"
+	"github.com/joeblew999/xplat/internal/sitecheck"
 )
 
 // ProcessInfo holds information about a process from process-compose.
@@ -156,6 +162,38 @@ func (c *ProcessComposeClient) RestartProcess(name string) error {
 	return nil
 }
 
+// ReloadProject makes process-compose re-read its configuration, including
+// .env, and restart the processes whose configuration changed. It returns
+// the status of each process the reload touched (e.g. "updated").
+func (c *ProcessComposeClient) ReloadProject() (map[string]string, error) {
+	req, err := http.NewRequest("POST", c.BaseURL+"/project/configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload project: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to reload project: %s", string(body))
+	}
+
+	var status map[string]string
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &status); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return status, nil
+}
+
 // GetProcessLogs retrieves logs for a specific process.
 func (c *ProcessComposeClient) GetProcessLogs(name string, limit int) (string, error) {
 	url := fmt.Sprintf("%s/process/logs/%s/0/%d", c.BaseURL, name, limit)
@@ -244,6 +282,184 @@ func (c *ProcessComposeClient) GetGraphFormat(format string) (string, error) {
 	return string(body), nil
 }
 
+// graphAPINode is a node of the /graph response. depends_on nests the
+// dependency nodes, keyed by name.
+type graphAPINode struct {
+	Name      string                   `json:"name"`
+	DependsOn map[string]*graphAPINode `json:"depends_on,omitempty"`
+}
+
+// GetDependencies returns each process's direct dependencies from the
+// /graph endpoint. Processes without dependencies may be missing.
+func (c *ProcessComposeClient) GetDependencies() (map[string][]string, error) {
+	data, err := c.GetGraphFormat("json")
+	if err != nil {
+		return nil, err
+	}
+
+	var graph struct {
+		Nodes map[string]*graphAPINode `json:"nodes"`
+	}
+	if err := json.Unmarshal([]byte(data), &graph); err != nil {
+		return nil, fmt.Errorf("failed to parse graph response: %w", err)
+	}
+
+	deps := make(map[string][]string)
+	var walk func(n *graphAPINode)
+	walk = func(n *graphAPINode) {
+		if n == nil || deps[n.Name] != nil {
+			return
+		}
+		deps[n.Name] = []string{}
+		for name, dep := range n.DependsOn {
+			deps[n.Name] = append(deps[n.Name], name)
+			walk(dep)
+		}
+		sort.Strings(deps[n.Name])
+	}
+	for _, n := range graph.Nodes {
+		walk(n)
+	}
+	return deps, nil
+}
+
+// Group actions on the processes page.
+const (
+	GroupRestartAll        = "restart-all"        // Every process, dependencies first
+	GroupRestartDependents = "restart-dependents" // A process, then everything that depends on it
+	GroupStopNamespace     = "stop-namespace"     // A namespace's processes, dependents first
+)
+
+// GroupStep is one process operation ("restart" or "stop") in a group action.
+type GroupStep struct {
+	Process string `json:"process"`
+	Op      string `json:"op"`
+}
+
+// GroupPlan is the ordered steps of a group action, shown for confirmation
+// and then run one by one by the page.
+type GroupPlan struct {
+	Action string      `json:"action"`
+	Target string      `json:"target,omitempty"`
+	Title  string      `json:"title"`
+	Steps  []GroupStep `json:"steps"`
+}
+
+// PlanGroupAction orders a group action so a process restarts after the
+// processes it depends on, and stops before them.
+func PlanGroupAction(action, target string, processes []ProcessInfo, deps map[string][]string) (*GroupPlan, error) {
+	plan := &GroupPlan{Action: action, Target: target}
+
+	var names []string
+	op := "restart"
+	switch action {
+	case GroupRestartAll:
+		for _, p := range processes {
+			names = append(names, p.Name)
+		}
+		plan.Title = fmt.Sprintf("Restart all %d processes", len(names))
+
+	case GroupRestartDependents:
+		if !hasProcess(processes, target) {
+			return nil, fmt.Errorf("unknown process %q", target)
+		}
+		names = append([]string{target}, dependentsOf(target, deps)...)
+		plan.Title = fmt.Sprintf("Restart %s and %d dependent(s)", target, len(names)-1)
+
+	case GroupStopNamespace:
+		for _, p := range processes {
+			if p.Namespace == target && p.IsRunning {
+				names = append(names, p.Name)
+			}
+		}
+		op = "stop"
+		plan.Title = fmt.Sprintf("Stop %d running process(es) in namespace %s", len(names), target)
+
+	default:
+		return nil, fmt.Errorf("unknown group action %q", action)
+	}
+
+	ordered := dependencyOrder(names, deps)
+	if op == "stop" {
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+	for _, name := range ordered {
+		plan.Steps = append(plan.Steps, GroupStep{Process: name, Op: op})
+	}
+	return plan, nil
+}
+
+func hasProcess(processes []ProcessInfo, name string) bool {
+	for _, p := range processes {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dependentsOf returns the processes that depend on name, directly or not.
+func dependentsOf(name string, deps map[string][]string) []string {
+	seen := map[string]bool{name: true}
+	var result []string
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for proc, procDeps := range deps {
+			if seen[proc] {
+				continue
+			}
+			for _, d := range procDeps {
+				if d == current {
+					seen[proc] = true
+					result = append(result, proc)
+					queue = append(queue, proc)
+					break
+				}
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// dependencyOrder sorts names so each comes after its dependencies in the
+// set, alphabetically otherwise. Dependency cycles keep alphabetical order.
+func dependencyOrder(names []string, deps map[string][]string) []string {
+	inSet := make(map[string]bool, len(names))
+	for _, n := range names {
+		inSet[n] = true
+	}
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	done := make(map[string]bool, len(names))
+	visiting := make(map[string]bool)
+	var ordered []string
+	var visit func(n string)
+	visit = func(n string) {
+		if done[n] || visiting[n] {
+			return
+		}
+		visiting[n] = true
+		for _, d := range deps[n] {
+			if inSet[d] {
+				visit(d)
+			}
+		}
+		visiting[n] = false
+		done[n] = true
+		ordered = append(ordered, n)
+	}
+	for _, n := range sorted {
+		visit(n)
+	}
+	return ordered
+}
+
 // getStatusColor returns a color for the process status.
 func getStatusColor(status string) string {
 	switch status {
@@ -263,3 +479,17 @@ func getStatusColor(status string) string {
 		return "#6c757d" // gray
 	}
 }
+
+// loadSLOStatus evaluates the SLOs declared in the work dir's xplat.yaml,
+// keyed by process. It returns nil if there is no manifest or no SLOs.
+func loadSLOStatus(workDir string) []sitecheck.SLOStatus {
+	m, err := manifest.NewLoader().LoadDir(workDir)
+	if err != nil || !m.HasSLOs() {
+		return nil
+	}
+	statuses, err := sitecheck.ManifestSLOStatus(projectstate.Open(workDir), m, time.Now())
+	if err != nil {
+		return nil
+	}
+	return statuses
+}
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestGetDependencies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"nodes": {
+			"web": {"name": "web", "process_status": "Running", "depends_on": {
+				"api": {"name": "api", "dependency_type": "process_healthy", "depends_on": {
+					"db": {"name": "db", "dependency_type": "process_healthy"}}},
+				"cache": {"name": "cache", "dependency_type": "process_started"}}}
+		}}`))
+	}))
+	defer srv.Close()
+
+	client := NewProcessComposeClient(0)
+	client.BaseURL = srv.URL
+
+	deps, err := client.GetDependencies()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{"web": {"api", "cache"}, "api": {"db"}, "db": {}, "cache": {}}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("deps = %v, want %v", deps, want)
+	}
+}
+
+func TestPlanGroupAction(t *testing.T) {
+	processes := []ProcessInfo{
+		{Name: "web", Namespace: "app", IsRunning: true},
+		{Name: "api", Namespace: "app", IsRunning: true},
+		{Name: "worker", Namespace: "app", IsRunning: false},
+		{Name: "db", Namespace: "data", IsRunning: true},
+		{Name: "cache", Namespace: "data", IsRunning: true},
+	}
+	deps := map[string][]string{"web": {"api", "cache"}, "api": {"db"}, "worker": {"db"}}
+
+	steps := func(plan *GroupPlan) []string {
+		var s []string
+		for _, step := range plan.Steps {
+			s = append(s, step.Op+" "+step.Process)
+		}
+		return s
+	}
+
+	tests := []struct {
+		action, target string
+		want           []string
+	}{
+		{GroupRestartAll, "", []string{"restart db", "restart api", "restart cache", "restart web", "restart worker"}},
+		{GroupRestartDependents, "db", []string{"restart db", "restart api", "restart web", "restart worker"}},
+		{GroupRestartDependents, "web", []string{"restart web"}},
+		{GroupStopNamespace, "app", []string{"stop web", "stop api"}},
+	}
+	for _, tt := range tests {
+		plan, err := PlanGroupAction(tt.action, tt.target, processes, deps)
+		if err != nil {
+			t.Fatalf("%s %s: %v", tt.action, tt.target, err)
+		}
+		if got := steps(plan); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %s: steps = %v, want %v", tt.action, tt.target, got, tt.want)
+		}
+	}
+
+	if _, err := PlanGroupAction(GroupRestartDependents, "nope", processes, deps); err == nil {
+		t.Error("expected error for unknown process")
+	}
+	if _, err := PlanGroupAction("reboot", "", processes, deps); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
@@ -0,0 +1,594 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-via/via"
+	"github.com/go-via/via/h"
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/statestore"
+)
+
+// Scheduled task runs: the Schedules tab saves "run task X every N minutes"
+// or cron schedules to ~/.xplat/schedules.yaml, and the UI runs the
+// schedules of its working directory while it is up. Scheduled runs are
+// recorded like runs started from the Tasks tab (run page, History), and
+// schedules whose last run failed are counted on the nav tab.
+
+// scheduleTick is how often the scheduler checks for due schedules.
+const scheduleTick = 15 * time.Second
+
+// SchedulesPath returns the schedules file: ~/.xplat/schedules.yaml
+func SchedulesPath() string {
+	return filepath.Join(config.XplatHome(), "schedules.yaml")
+}
+
+// Schedule runs a task of a project periodically, every Every minutes or
+// on a cron expression, and remembers the outcome of its last run.
+type Schedule struct {
+	ID      string    `yaml:"id"`
+	Task    string    `yaml:"task"`
+	Args    []string  `yaml:"args,omitempty"`
+	Dir     string    `yaml:"dir"`
+	Every   int       `yaml:"every,omitempty"` // Minutes between runs
+	Cron    string    `yaml:"cron,omitempty"`  // Cron expression (5 fields or @daily etc.), instead of Every
+	Paused  bool      `yaml:"paused,omitempty"`
+	Created time.Time `yaml:"created"`
+
+	LastRun   time.Time `yaml:"last_run,omitempty"`
+	LastRunID string    `yaml:"last_run_id,omitempty"` // History run ID
+	LastExit  int       `yaml:"last_exit,omitempty"`
+	LastError string    `yaml:"last_error,omitempty"`
+}
+
+// Command returns the command line the schedule runs.
+func (s Schedule) Command() string {
+	return strings.Join(append([]string{"task", s.Task}, s.Args...), " ")
+}
+
+// When describes the schedule, e.g. "every 5 minutes" or "0 2 * * *".
+func (s Schedule) When() string {
+	if s.Cron != "" {
+		return s.Cron
+	}
+	if s.Every == 1 {
+		return "every minute"
+	}
+	return fmt.Sprintf("every %d minutes", s.Every)
+}
+
+// Failed reports whether the last run of the schedule failed.
+func (s Schedule) Failed() bool {
+	return !s.LastRun.IsZero() && (s.LastExit != 0 || s.LastError != "")
+}
+
+// spec parses the schedule's timing.
+func (s Schedule) spec() (cron.Schedule, error) {
+	if s.Cron != "" {
+		spec, err := cron.ParseStandard(s.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", s.Cron, err)
+		}
+		return spec, nil
+	}
+	if s.Every <= 0 {
+		return nil, errors.New("set a cron expression or an interval in minutes")
+	}
+	return cron.Every(time.Duration(s.Every) * time.Minute), nil
+}
+
+// validate checks a new schedule.
+func (s Schedule) validate() error {
+	if s.Task == "" {
+		return errors.New("task is required")
+	}
+	if s.Cron != "" && s.Every > 0 {
+		return errors.New("set either a cron expression or an interval, not both")
+	}
+	_, err := s.spec()
+	return err
+}
+
+// scheduleFile is the layout of schedules.yaml.
+type scheduleFile struct {
+	Schedules []Schedule `yaml:"schedules"`
+}
+
+// ScheduleStore keeps schedules in a YAML file shared by every project's UI.
+// Writes are locked (see statestore.LockFile).
+type ScheduleStore struct {
+	Path string
+}
+
+// List returns the schedules of dir, oldest first.
+func (st *ScheduleStore) List(dir string) ([]Schedule, error) {
+	all, err := st.load()
+	if err != nil {
+		return nil, err
+	}
+	var schedules []Schedule
+	for _, s := range all {
+		if s.Dir == dir {
+			schedules = append(schedules, s)
+		}
+	}
+	return schedules, nil
+}
+
+// Add validates and saves a new schedule, returning it with its ID.
+func (st *ScheduleStore) Add(s Schedule) (Schedule, error) {
+	s.Task = strings.TrimSpace(s.Task)
+	s.Cron = strings.TrimSpace(s.Cron)
+	if err := s.validate(); err != nil {
+		return s, err
+	}
+	if s.Created.IsZero() {
+		s.Created = time.Now()
+	}
+	s.ID = strconv.FormatInt(s.Created.UnixNano(), 36)
+	err := st.update(func(schedules []Schedule) ([]Schedule, error) {
+		return append(schedules, s), nil
+	})
+	return s, err
+}
+
+// Update changes the schedule with the given ID.
+func (st *ScheduleStore) Update(id string, fn func(s *Schedule)) error {
+	return st.update(func(schedules []Schedule) ([]Schedule, error) {
+		for i := range schedules {
+			if schedules[i].ID == id {
+				fn(&schedules[i])
+				return schedules, nil
+			}
+		}
+		return nil, os.ErrNotExist
+	})
+}
+
+// Remove deletes the schedule with the given ID.
+func (st *ScheduleStore) Remove(id string) error {
+	return st.update(func(schedules []Schedule) ([]Schedule, error) {
+		for i := range schedules {
+			if schedules[i].ID == id {
+				return append(schedules[:i], schedules[i+1:]...), nil
+			}
+		}
+		return nil, os.ErrNotExist
+	})
+}
+
+func (st *ScheduleStore) load() ([]Schedule, error) {
+	data, err := os.ReadFile(st.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+	var f scheduleFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", st.Path, err)
+	}
+	return f.Schedules, nil
+}
+
+// update rewrites the file with fn's result, holding its lock.
+func (st *ScheduleStore) update(fn func([]Schedule) ([]Schedule, error)) error {
+	unlock, err := statestore.LockFile(st.Path, statestore.DefaultLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	schedules, err := st.load()
+	if err != nil {
+		return err
+	}
+	if schedules, err = fn(schedules); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# Task schedules of the xplat UI (xplat up, Schedules tab)\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(scheduleFile{Schedules: schedules}); err != nil {
+		return err
+	}
+	if err := statestore.WriteFile(st.Path, buf.Bytes(), config.DefaultFilePerms); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	return nil
+}
+
+// Scheduler runs the due schedules of one working directory. Occurrences
+// missed while the UI was down are skipped, and so are occurrences due
+// while the previous run of the schedule is still going.
+type Scheduler struct {
+	Store *ScheduleStore
+	Dir   string
+
+	// Run runs a schedule's task to completion, returning when it started
+	Run func(s Schedule) (time.Time, error)
+
+	mu      sync.Mutex
+	next    map[string]time.Time // Next run per schedule ID
+	running map[string]bool
+	wg      sync.WaitGroup
+}
+
+// Start checks for due schedules until ctx is done.
+func (sch *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(scheduleTick)
+	defer ticker.Stop()
+	sch.Tick(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			sch.Tick(now)
+		}
+	}
+}
+
+// Tick starts the schedules due at now and returns their IDs.
+func (sch *Scheduler) Tick(now time.Time) []string {
+	schedules, err := sch.Store.List(sch.Dir)
+	if err != nil {
+		log.Printf("Warning: schedules: %v", err)
+		return nil
+	}
+
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	if sch.next == nil {
+		sch.next = make(map[string]time.Time)
+		sch.running = make(map[string]bool)
+	}
+
+	var started []string
+	known := make(map[string]bool, len(schedules))
+	for _, s := range schedules {
+		known[s.ID] = true
+		spec, err := s.spec()
+		if err != nil || s.Paused {
+			delete(sch.next, s.ID) // Resuming starts from the next occurrence
+			continue
+		}
+		next, ok := sch.next[s.ID]
+		if !ok {
+			next = spec.Next(now)
+			if !s.LastRun.IsZero() {
+				// Keep the interval across restarts of the UI
+				if last := spec.Next(s.LastRun); last.After(now) && last.Before(next) {
+					next = last
+				}
+			}
+			sch.next[s.ID] = next
+		}
+		if next.After(now) {
+			continue
+		}
+		sch.next[s.ID] = spec.Next(now)
+		if sch.running[s.ID] {
+			log.Printf("Schedule %s (%s): previous run still going, skipped", s.ID, s.Command())
+			continue
+		}
+		sch.running[s.ID] = true
+		started = append(started, s.ID)
+		sch.wg.Add(1)
+		go sch.run(s)
+	}
+	for id := range sch.next {
+		if !known[id] {
+			delete(sch.next, id)
+		}
+	}
+	return started
+}
+
+// Next returns when a schedule runs next, or the zero time if it is not
+// scheduled (paused, invalid or not seen by Tick yet).
+func (sch *Scheduler) Next(id string) time.Time {
+	sch.mu.Lock()
+	defer sch.mu.Unlock()
+	return sch.next[id]
+}
+
+// run runs a schedule and records the outcome.
+func (sch *Scheduler) run(s Schedule) {
+	defer sch.wg.Done()
+	start, err := sch.Run(s)
+
+	updateErr := sch.Store.Update(s.ID, func(s *Schedule) {
+		s.LastRun = start
+		s.LastRunID = historyID(start)
+		s.LastExit = exitCode(err)
+		s.LastError = ""
+		if err != nil {
+			s.LastError = err.Error()
+		}
+	})
+	if updateErr != nil && !os.IsNotExist(updateErr) {
+		log.Printf("Warning: Failed to save schedule %s: %v", s.ID, updateErr)
+	}
+
+	sch.mu.Lock()
+	delete(sch.running, s.ID)
+	sch.mu.Unlock()
+}
+
+// scheduleUser is who scheduled runs are attributed to.
+func scheduleUser(s Schedule) string {
+	return "schedule:" + s.ID
+}
+
+// runScheduled runs a schedule's task like a run started from its page:
+// with a run page, a notification and a History entry.
+func (app *App) runScheduled(s Schedule) (time.Time, error) {
+	user := scheduleUser(s)
+	id := app.runs.Start(s.Task, s.Args...)
+	app.runs.SetUser(id, user)
+	run, _, _ := app.runs.Get(id)
+
+	err := runTaskWithCallback(s.Task, s.Args, app.config.WorkDir, func(line string) {
+		app.runs.AppendLine(id, line)
+	})
+	app.record(user, "schedule.run", s.Task, err)
+	app.runs.Finish(id, err)
+	return run.Start, err
+}
+
+// failingSchedules returns how many schedules of this project failed their
+// last run.
+func (app *App) failingSchedules() int {
+	if app.schedules == nil {
+		return 0
+	}
+	schedules, err := app.schedules.Store.List(app.config.WorkDir)
+	if err != nil {
+		return 0
+	}
+	failing := 0
+	for _, s := range schedules {
+		if s.Failed() && !s.Paused {
+			failing++
+		}
+	}
+	return failing
+}
+
+// handleScheduleAction pauses, resumes or deletes a schedule of this
+// project and redirects back to the Schedules tab.
+func (app *App) handleScheduleAction(w http.ResponseWriter, r *http.Request) {
+	id, action := r.PathValue("id"), r.PathValue("action")
+	schedules, err := app.schedules.Store.List(app.config.WorkDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var s *Schedule
+	for i := range schedules {
+		if schedules[i].ID == id {
+			s = &schedules[i]
+		}
+	}
+	if s == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch action {
+	case "pause", "resume":
+		err = app.schedules.Store.Update(id, func(s *Schedule) { s.Paused = action == "pause" })
+	case "delete":
+		err = app.schedules.Store.Remove(id)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	app.record(app.requestUser(r), "schedule."+action, s.Command()+" ("+s.When()+")", err)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/schedules", http.StatusSeeOther)
+}
+
+// scheduleButton posts an action on a schedule.
+func scheduleButton(s Schedule, action, label, class string) h.H {
+	return h.Form(
+		h.Attr("method", "post"),
+		h.Attr("action", "/api/schedules/"+s.ID+"/"+action),
+		h.Style("margin: 0;"),
+		h.Button(
+			h.Type("submit"),
+			h.Class(class),
+			h.Style("margin: 0; padding: 0.25rem 0.75rem;"),
+			h.Text(label),
+		),
+	)
+}
+
+// viaSchedulesPage lists the schedules of this working directory, with a
+// form to add one.
+func (app *App) viaSchedulesPage(c *via.Context) {
+	task := c.Signal("")
+	every := c.Signal("")
+	cronExpr := c.Signal("")
+	args := c.Signal("")
+	status := c.Signal("")
+	statusOK := c.Signal(true)
+
+	addAction := c.Action(func() {
+		if app.config.ReadOnly {
+			return
+		}
+		s := Schedule{
+			Task: task.String(),
+			Args: strings.Fields(args.String()),
+			Dir:  app.config.WorkDir,
+			Cron: cronExpr.String(),
+		}
+		if v := strings.TrimSpace(every.String()); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				status.SetValue("Interval must be a positive number of minutes")
+				statusOK.SetValue(false)
+				c.Sync()
+				return
+			}
+			s.Every = n
+		}
+		s, err := app.schedules.Store.Add(s)
+		app.record(app.operator, "schedule.add", s.Command()+" ("+s.When()+")", err)
+		if err != nil {
+			status.SetValue("Not added: " + err.Error())
+			statusOK.SetValue(false)
+			c.Sync()
+			return
+		}
+		app.schedules.Tick(time.Now()) // Show its next run
+		status.SetValue("Added: " + s.Command() + ", " + s.When() + ".")
+		statusOK.SetValue(true)
+		every.SetValue("")
+		cronExpr.SetValue("")
+		args.SetValue("")
+		c.Sync()
+	})
+
+	c.View(func() h.H {
+		schedules, err := app.schedules.Store.List(app.config.WorkDir)
+
+		var content h.H
+		switch {
+		case err != nil:
+			content = h.P(h.Style("color: var(--pico-del-color);"), h.Text(err.Error()))
+		case len(schedules) == 0:
+			content = h.P(h.Text("No schedules yet."))
+		default:
+			rows := []h.H{h.THead(h.Tr(
+				h.Th(h.Text("Command")), h.Th(h.Text("Schedule")), h.Th(h.Text("Next run")),
+				h.Th(h.Text("Last run")), h.Th(),
+			))}
+			var body []h.H
+			for _, s := range schedules {
+				next := "—"
+				switch {
+				case s.Paused:
+					next = "paused"
+				case app.config.ReadOnly:
+					next = "not run (read-only)"
+				case !app.schedules.Next(s.ID).IsZero():
+					next = app.schedules.Next(s.ID).Local().Format("2006-01-02 15:04")
+				}
+				body = append(body, h.Tr(
+					h.Td(h.Code(h.Text(s.Command()))),
+					h.Td(h.Text(s.When())),
+					h.Td(h.Text(next)),
+					h.Td(lastScheduleRun(s)),
+					h.Td(h.If(!app.config.ReadOnly, h.Div(
+						h.Style("display: flex; gap: 0.5rem;"),
+						h.If(s.Paused, scheduleButton(s, "resume", "▶ Resume", "outline")),
+						h.If(!s.Paused, scheduleButton(s, "pause", "⏸ Pause", "outline secondary")),
+						scheduleButton(s, "delete", "✕ Delete", "outline contrast"),
+					))),
+				))
+			}
+			rows = append(rows, h.TBody(body...))
+			content = h.Table(rows...)
+		}
+
+		taskOpts := []h.H{task.Bind(), h.Option(h.Attr("value", ""), h.Text("Task..."))}
+		names := make([]string, 0, len(app.tasks))
+		for _, t := range app.tasks {
+			names = append(names, t.Name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			opt := []h.H{h.Attr("value", name), h.Text(name)}
+			if name == task.String() {
+				opt = append(opt, h.Attr("selected", "selected"))
+			}
+			taskOpts = append(taskOpts, h.Option(opt...))
+		}
+
+		statusColor := "var(--pico-ins-color)"
+		if !statusOK.Bool() {
+			statusColor = "var(--pico-del-color)"
+		}
+
+		return h.Div(
+			app.renderNav(TabSchedules),
+			h.Main(
+				h.Class("container"),
+				h.Article(
+					h.H3(h.Text("Schedules")),
+					h.P(
+						h.Style("color: var(--pico-muted-color);"),
+						h.Text(filepath.ToSlash(app.schedules.Store.Path)+" · runs are saved to "),
+						h.A(h.Href("/history"), h.Text("History")),
+					),
+					content,
+					h.If(!app.config.ReadOnly, h.Div(
+						h.H4(h.Text("Add schedule")),
+						h.Div(
+							h.Attr("role", "group"),
+							h.Select(taskOpts...),
+							h.Input(h.Type("text"), h.Placeholder("args (optional)"), h.Value(args.String()), args.Bind()),
+						),
+						h.Div(
+							h.Attr("role", "group"),
+							h.Input(h.Type("number"), h.Attr("min", "1"), h.Placeholder("every N minutes"), h.Value(every.String()), every.Bind()),
+							h.Input(h.Type("text"), h.Placeholder("or cron, e.g. 0 2 * * *"), h.Value(cronExpr.String()), cronExpr.Bind()),
+						),
+						h.Button(h.Text("Add"), addAction.OnClick()),
+						h.If(status.String() != "", h.P(
+							h.Style("color: "+statusColor+";"),
+							h.Text(status.String()),
+						)),
+					)),
+					h.If(app.config.ReadOnly, h.P(
+						h.Style("color: var(--pico-muted-color);"),
+						h.Text("Read-only: schedules are not run or edited by this UI"),
+					)),
+				),
+			),
+		)
+	})
+}
+
+// lastScheduleRun renders the outcome of a schedule's last run, linked to
+// its History entry.
+func lastScheduleRun(s Schedule) h.H {
+	if s.LastRun.IsZero() {
+		return h.Text("never")
+	}
+	text := s.LastRun.Local().Format("2006-01-02 15:04") + " ✓"
+	style := "color: var(--pico-ins-color);"
+	if s.Failed() {
+		text = fmt.Sprintf("%s ✗ exit %d", s.LastRun.Local().Format("2006-01-02 15:04"), s.LastExit)
+		style = "color: var(--pico-del-color);"
+	}
+	return h.A(
+		h.Href(HistoryRun{ID: s.LastRunID}.URL()),
+		h.Style(style),
+		h.Attr("title", s.LastError),
+		h.Text(text),
+	)
+}
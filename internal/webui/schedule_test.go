@@ -0,0 +1,158 @@
+package web
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduleStore(t *testing.T) {
+	store := &ScheduleStore{Path: filepath.Join(t.TempDir(), "schedules.yaml")}
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name string
+		s    Schedule
+	}{
+		{"no task", Schedule{Every: 5}},
+		{"no timing", Schedule{Task: "build"}},
+		{"both", Schedule{Task: "build", Every: 5, Cron: "0 2 * * *"}},
+		{"bad cron", Schedule{Task: "build", Cron: "every day"}},
+	} {
+		if _, err := store.Add(tt.s); err == nil {
+			t.Errorf("Add(%s) succeeded", tt.name)
+		}
+	}
+
+	build, err := store.Add(Schedule{Task: "build", Dir: "/proj", Every: 5, Created: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(Schedule{Task: " backup ", Args: []string{"FULL=1"}, Dir: "/proj", Cron: " 0 2 * * * ", Created: created.Add(time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Add(Schedule{Task: "build", Dir: "/other", Every: 1, Created: created.Add(2 * time.Second)}); err != nil {
+		t.Fatal(err)
+	}
+
+	schedules, err := store.List("/proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 2 || schedules[0].When() != "every 5 minutes" || schedules[1].Command() != "task backup FULL=1" || schedules[1].When() != "0 2 * * *" {
+		t.Fatalf("List() = %+v", schedules)
+	}
+
+	if err := store.Update(build.ID, func(s *Schedule) { s.LastRun, s.LastExit = created, 1 }); err != nil {
+		t.Fatal(err)
+	}
+	if schedules, _ := store.List("/proj"); !schedules[0].Failed() || schedules[1].Failed() {
+		t.Errorf("Failed() = %v, %v, want true, false", schedules[0].Failed(), schedules[1].Failed())
+	}
+
+	if err := store.Remove(build.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Remove(build.ID); err == nil {
+		t.Error("Remove() of a removed schedule succeeded")
+	}
+	if schedules, _ := store.List("/proj"); len(schedules) != 1 || schedules[0].Task != "backup" {
+		t.Errorf("List() after Remove = %+v", schedules)
+	}
+}
+
+func TestSchedulerTick(t *testing.T) {
+	store := &ScheduleStore{Path: filepath.Join(t.TempDir(), "schedules.yaml")}
+	start := time.Date(2026, 1, 2, 3, 0, 30, 0, time.UTC)
+	every, err := store.Add(Schedule{Task: "build", Dir: "/proj", Every: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nightly, err := store.Add(Schedule{Task: "backup", Dir: "/proj", Cron: "0 4 * * *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var ran []string
+	sch := &Scheduler{Store: store, Dir: "/proj", Run: func(s Schedule) (time.Time, error) {
+		mu.Lock()
+		ran = append(ran, s.Task)
+		mu.Unlock()
+		if s.Task == "backup" {
+			return start, errors.New("exit status 2")
+		}
+		return start, nil
+	}}
+
+	if got := sch.Tick(start); len(got) != 0 {
+		t.Errorf("first Tick started %v, want nothing", got)
+	}
+	if want := start.Add(5 * time.Minute); !sch.Next(every.ID).Equal(want) {
+		t.Errorf("Next(every) = %v, want %v", sch.Next(every.ID), want)
+	}
+	if want := time.Date(2026, 1, 2, 4, 0, 0, 0, time.UTC); !sch.Next(nightly.ID).Equal(want) {
+		t.Errorf("Next(nightly) = %v, want %v", sch.Next(nightly.ID), want)
+	}
+
+	if got := sch.Tick(start.Add(4 * time.Minute)); len(got) != 0 {
+		t.Errorf("Tick before due started %v", got)
+	}
+	if got := sch.Tick(start.Add(time.Hour)); len(got) != 2 {
+		t.Errorf("Tick after both due started %v, want both", got)
+	}
+	sch.wg.Wait()
+	if len(ran) != 2 {
+		t.Errorf("ran %v, want build and backup", ran)
+	}
+
+	schedules, _ := store.List("/proj")
+	for _, s := range schedules {
+		if !s.LastRun.Equal(start) || s.LastRunID != historyID(start) {
+			t.Errorf("%s: last run %v (%s), want %v", s.Task, s.LastRun, s.LastRunID, start)
+		}
+		if s.Failed() != (s.Task == "backup") {
+			t.Errorf("%s: Failed() = %v", s.Task, s.Failed())
+		}
+	}
+
+	// Paused schedules are not run, and resume from their next occurrence
+	if err := store.Update(every.ID, func(s *Schedule) { s.Paused = true }); err != nil {
+		t.Fatal(err)
+	}
+	if got := sch.Tick(start.Add(2 * time.Hour)); len(got) != 0 {
+		t.Errorf("Tick started %v while paused", got)
+	}
+	if !sch.Next(every.ID).IsZero() {
+		t.Errorf("Next(paused) = %v, want zero", sch.Next(every.ID))
+	}
+}
+
+func TestSchedulerSkipsRunning(t *testing.T) {
+	store := &ScheduleStore{Path: filepath.Join(t.TempDir(), "schedules.yaml")}
+	if _, err := store.Add(Schedule{Task: "slow", Dir: "/proj", Every: 1}); err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	sch := &Scheduler{Store: store, Dir: "/proj", Run: func(s Schedule) (time.Time, error) {
+		<-release
+		return time.Now(), nil
+	}}
+
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	sch.Tick(now)
+	if got := sch.Tick(now.Add(time.Minute)); len(got) != 1 {
+		t.Fatalf("Tick started %v, want the schedule", got)
+	}
+	if got := sch.Tick(now.Add(2 * time.Minute)); len(got) != 0 {
+		t.Errorf("Tick started %v while the previous run is going", got)
+	}
+	close(release)
+	sch.wg.Wait()
+	if got := sch.Tick(now.Add(3 * time.Minute)); len(got) != 1 {
+		t.Errorf("Tick after the run ended started %v", got)
+	}
+	sch.wg.Wait()
+}
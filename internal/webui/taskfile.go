@@ -52,6 +52,9 @@ type Task struct {
 	Deps        []any  `yaml:"deps"`
 	Internal    bool   `yaml:"internal"`
 	Interactive bool   `yaml:"interactive"`
+
+	Vars     taskVarDecls `yaml:"vars"`
+	Requires taskRequires `yaml:"requires"`
 }
 
 // remoteTaskfileCache caches fetched remote taskfiles.
@@ -0,0 +1,175 @@
+package web
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-via/via/h"
+	"gopkg.in/yaml.v3"
+)
+
+// TaskVar is a variable the execution page asks for before running a task:
+// one listed under the task's requires.vars, or one of its vars that can be
+// overridden from the command line ({{.NAME | default "x"}}).
+type TaskVar struct {
+	Name     string
+	Default  string   // Value used when left empty, if known
+	Required bool     // Listed under requires.vars
+	Enum     []string // Allowed values (requires.vars enum)
+}
+
+// taskVarDecl is one entry of a task's vars, in file order.
+type taskVarDecl struct {
+	Name  string
+	Value string // Static or templated value; empty for sh/ref/map vars
+}
+
+// taskVarDecls keeps a task's vars in file order.
+type taskVarDecls []taskVarDecl
+
+// UnmarshalYAML decodes the vars mapping, keeping the order of the keys.
+func (d *taskVarDecls) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return nil // Not for the form; 'xplat task' reports invalid vars
+	}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		decl := taskVarDecl{Name: value.Content[i].Value}
+		if v := value.Content[i+1]; v.Kind == yaml.ScalarNode {
+			decl.Value = v.Value
+		}
+		*d = append(*d, decl)
+	}
+	return nil
+}
+
+// taskRequires is a task's requires section.
+type taskRequires struct {
+	Vars []taskRequiredVar `yaml:"vars"`
+}
+
+// taskRequiredVar is an entry of requires.vars: a name, or a name with the
+// allowed values.
+type taskRequiredVar struct {
+	Name string   `yaml:"name"`
+	Enum []string `yaml:"enum"`
+}
+
+// UnmarshalYAML handles both the string and object forms.
+func (r *taskRequiredVar) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		r.Name = value.Value
+		return nil
+	}
+	type requiredAlias taskRequiredVar
+	var alias requiredAlias
+	if err := value.Decode(&alias); err != nil {
+		return err
+	}
+	*r = taskRequiredVar(alias)
+	return nil
+}
+
+// defaultPattern matches the default of a templated var: {{.NAME | default "x"}}.
+var defaultPattern = regexp.MustCompile(`default\s+"([^"]*)"`)
+
+// formVars returns the variables to ask for before running t: the required
+// ones first, then the vars that read their own name from the command line.
+// Vars with a fixed value are left out, since CLI vars cannot override them.
+func (t Task) formVars() []TaskVar {
+	var vars []TaskVar
+	index := make(map[string]int)
+	for _, r := range t.Requires.Vars {
+		if r.Name == "" {
+			continue
+		}
+		index[r.Name] = len(vars)
+		vars = append(vars, TaskVar{Name: r.Name, Required: true, Enum: r.Enum})
+	}
+
+	for _, decl := range t.Vars {
+		if !strings.Contains(decl.Value, "."+decl.Name) {
+			continue
+		}
+		var def string
+		if m := defaultPattern.FindStringSubmatch(decl.Value); m != nil {
+			def = m[1]
+		}
+		if i, ok := index[decl.Name]; ok {
+			vars[i].Default = def
+			continue
+		}
+		index[decl.Name] = len(vars)
+		vars = append(vars, TaskVar{Name: decl.Name, Default: def})
+	}
+	return vars
+}
+
+// taskArgs returns the arguments for 'xplat task <name>': NAME=value for
+// each var given a value, then the extra args.
+func taskArgs(vars []TaskVar, values map[string]string, extra string) []string {
+	var args []string
+	for _, v := range vars {
+		if value := strings.TrimSpace(values[v.Name]); value != "" {
+			args = append(args, v.Name+"="+value)
+		}
+	}
+	return append(args, strings.Fields(extra)...)
+}
+
+// missingVars returns the required vars without a value.
+func missingVars(vars []TaskVar, values map[string]string) []string {
+	var missing []string
+	for _, v := range vars {
+		if v.Required && strings.TrimSpace(values[v.Name]) == "" {
+			missing = append(missing, v.Name)
+		}
+	}
+	return missing
+}
+
+// boundSignal is a Via signal bound to a form field.
+type boundSignal interface {
+	String() string
+	Bind() h.H
+}
+
+// taskVarField renders the input for a variable: a select for enums, else
+// a text input with the default as placeholder.
+func taskVarField(v TaskVar, sig boundSignal) h.H {
+	label := v.Name
+	if v.Required {
+		label += " *"
+	}
+
+	var input h.H
+	if len(v.Enum) > 0 {
+		opts := []h.H{h.ID("var-" + v.Name), sig.Bind(), h.Option(h.Attr("value", ""), h.Text("Select..."))}
+		for _, e := range v.Enum {
+			opt := []h.H{h.Attr("value", e), h.Text(e)}
+			if e == sig.String() {
+				opt = append(opt, h.Attr("selected", "selected"))
+			}
+			opts = append(opts, h.Option(opt...))
+		}
+		input = h.Select(opts...)
+	} else {
+		placeholder := v.Default
+		if placeholder == "" && v.Required {
+			placeholder = "required"
+		}
+		input = h.Input(
+			h.Type("text"),
+			h.ID("var-"+v.Name),
+			h.Placeholder(placeholder),
+			h.Value(sig.String()),
+			sig.Bind(),
+		)
+	}
+
+	return h.Label(
+		h.Attr("for", "var-"+v.Name),
+		h.Style("margin: 0;"),
+		h.Code(h.Text(label)),
+		input,
+	)
+}
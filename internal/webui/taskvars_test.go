@@ -0,0 +1,53 @@
+package web
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFormVars(t *testing.T) {
+	var tf Taskfile
+	err := yaml.Unmarshal([]byte(`
+version: '3'
+tasks:
+  deploy:
+    requires:
+      vars:
+        - VERSION
+        - name: ENV
+          enum: [dev, prod]
+    vars:
+      REGION: '{{.REGION | default "eu-west-1"}}'
+      ENV: '{{.ENV | default "dev"}}'
+      OUT: build/app
+      SHA:
+        sh: git rev-parse HEAD
+    cmds:
+      - echo {{.VERSION}} {{.ENV}} {{.REGION}}
+`), &tf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := tf.Tasks["deploy"].formVars()
+	want := []TaskVar{
+		{Name: "VERSION", Required: true},
+		{Name: "ENV", Default: "dev", Required: true, Enum: []string{"dev", "prod"}},
+		{Name: "REGION", Default: "eu-west-1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("formVars() = %+v, want %+v", got, want)
+	}
+
+	values := map[string]string{"ENV": "prod", "REGION": " "}
+	if missing := missingVars(got, values); !reflect.DeepEqual(missing, []string{"VERSION"}) {
+		t.Errorf("missingVars() = %v", missing)
+	}
+	values["VERSION"] = "1.2"
+	args := taskArgs(got, values, "-- --verbose")
+	if want := []string{"VERSION=1.2", "ENV=prod", "--", "--verbose"}; !reflect.DeepEqual(args, want) {
+		t.Errorf("taskArgs() = %q, want %q", args, want)
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+// Theme: Pico follows the OS light/dark preference until the ☾/☀ toggle in
+// the nav picks one, which is kept in a cookie for a year. themeHeadScript
+// applies it before the page renders, so there is no flash of the other theme.
+
+// themeCookie holds the picked theme: "light" or "dark".
+const themeCookie = "xplat_ui_theme"
+
+// themeHeadScript sets the picked theme on <html> (Pico's data-theme).
+const themeHeadScript = `
+(function () {
+  var m = document.cookie.match(/(?:^|; )` + themeCookie + `=(light|dark)/);
+  if (m) document.documentElement.setAttribute('data-theme', m[1]);
+})();
+`
+
+// themeScript drives the theme toggle in the nav.
+const themeScript = `
+(function () {
+  const btn = document.getElementById('xplat-theme-toggle');
+  if (!btn) return;
+  const root = document.documentElement;
+
+  function current() {
+    return root.getAttribute('data-theme') ||
+      (window.matchMedia('(prefers-color-scheme: dark)').matches ? 'dark' : 'light');
+  }
+  function render() {
+    const dark = current() === 'dark';
+    btn.textContent = dark ? '☀' : '☾';
+    btn.title = dark ? 'Switch to light mode' : 'Switch to dark mode';
+    btn.style.display = '';
+  }
+
+  btn.addEventListener('click', (e) => {
+    e.preventDefault();
+    const next = current() === 'dark' ? 'light' : 'dark';
+    root.setAttribute('data-theme', next);
+    document.cookie = '` + themeCookie + `=' + next + '; path=/; max-age=31536000; SameSite=Lax';
+    render();
+  });
+  render();
+})();
+`
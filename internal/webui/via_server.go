@@ -13,6 +13,8 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +22,7 @@ import (
 	"github.com/go-via/via/h"
 
 	"github.com/joeblew999/xplat/internal/config"
+	"github.com/joeblew999/xplat/internal/sitecheck"
 )
 
 // TaskInfo holds information about a task.
@@ -27,6 +30,7 @@ type TaskInfo struct {
 	Name        string
 	Description string
 	Summary     string
+	Vars        []TaskVar // Asked for on the execution page
 }
 
 // ViaConfig holds the Via server configuration.
@@ -36,6 +40,18 @@ type ViaConfig struct {
 	WorkDir            string // Working directory for task execution
 	OpenBrowser        bool   // Open browser on start
 	ProcessComposePort int    // Port for process-compose API (default 8080)
+
+	// Audit, if set, is called after each task run with its outcome
+	Audit func(action, target string, err error)
+
+	// Runs, if set, records each task run so notifications can link to it
+	Runs *RunStore
+
+	// EnvReload, if set, returns the latest .env reload for the processes page
+	EnvReload func() *EnvReload
+
+	// ReadOnly hides the task run and process actions
+	ReadOnly bool
 }
 
 // DefaultViaConfig returns sensible defaults.
@@ -69,6 +85,7 @@ func listTasksFromFile(filename, workDir string) ([]TaskInfo, error) {
 			Name:        name,
 			Description: task.Desc,
 			Summary:     task.Summary,
+			Vars:        task.formVars(),
 		})
 	}
 
@@ -176,15 +193,39 @@ func getNamespaceOrder(groups map[string][]TaskInfo) []string {
 }
 
 // viaTaskExecutionPage renders the task execution page with terminal output
-func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []TaskInfo, cfg ViaConfig) {
+func viaTaskExecutionPage(c *via.Context, task TaskInfo, tasks []TaskInfo, cfg ViaConfig) {
+	taskName, taskDesc := task.Name, task.Description
+
 	// Signals for state management
 	output := c.Signal("")
 	status := c.Signal("ready") // ready, running, finished, error
 	running := c.Signal(false)
+	runID := c.Signal(0)
+	argsInput := c.Signal("") // Other CLI vars and args, e.g. "VERSION=1.2 -- --verbose"
+
+	// One field per variable the task requires or reads from the command line
+	varInputs := make(map[string]boundSignal, len(task.Vars))
+	for _, v := range task.Vars {
+		varInputs[v.Name] = c.Signal("")
+	}
+	varValues := func() map[string]string {
+		values := make(map[string]string, len(varInputs))
+		for name, sig := range varInputs {
+			values[name] = sig.String()
+		}
+		return values
+	}
 
 	// Run task action
 	runAction := c.Action(func() {
-		if running.String() == "true" {
+		if running.String() == "true" || cfg.ReadOnly {
+			return
+		}
+		values := varValues()
+		if missing := missingVars(task.Vars, values); len(missing) > 0 {
+			status.SetValue("missing")
+			output.SetValue("Missing required variables: " + strings.Join(missing, ", "))
+			c.Sync()
 			return
 		}
 
@@ -193,18 +234,35 @@ func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []Tas
 		output.SetValue("")
 		c.Sync()
 
+		args := taskArgs(task.Vars, values, argsInput.String())
+		var id int
+		if cfg.Runs != nil {
+			id = cfg.Runs.Start(taskName, args...)
+			runID.SetValue(id)
+		}
+
 		// Run the task and stream output
 		go func() {
-			err := runTaskWithCallback(taskName, cfg.WorkDir, func(line string) {
+			err := runTaskWithCallback(taskName, args, cfg.WorkDir, func(line string) {
 				// Append output line
 				current := output.String()
 				if current != "" {
 					current += "\n"
 				}
 				output.SetValue(current + line)
+				if cfg.Runs != nil {
+					cfg.Runs.AppendLine(id, line)
+				}
 				c.Sync()
 			})
 
+			if cfg.Audit != nil {
+				cfg.Audit("task.run", taskName, err)
+			}
+			if cfg.Runs != nil {
+				cfg.Runs.Finish(id, err)
+			}
+
 			running.SetValue(false)
 			if err != nil {
 				status.SetValue("error")
@@ -217,6 +275,9 @@ func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []Tas
 
 	c.View(func() h.H {
 		statusText := "Ready to run"
+		if cfg.ReadOnly {
+			statusText = "Read-only: tasks cannot be run from this UI"
+		}
 		switch status.String() {
 		case "running":
 			statusText = "Running..."
@@ -224,6 +285,13 @@ func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []Tas
 			statusText = "Finished"
 		case "error":
 			statusText = "Error"
+		case "missing":
+			statusText = "Fill in the required variables"
+		}
+
+		var varFields []h.H
+		for _, v := range task.Vars {
+			varFields = append(varFields, taskVarField(v, varInputs[v.Name]))
 		}
 
 		// Get current task's namespace
@@ -329,11 +397,13 @@ func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []Tas
 								),
 								h.Div(
 									h.Style("display: flex; gap: 0.5rem;"),
-									h.Button(
-										h.Text("▶ Run"),
-										h.If(running.String() == "true", h.Attr("aria-busy", "true")),
-										h.If(running.String() == "true", h.Attr("disabled", "disabled")),
-										runAction.OnClick(),
+									h.If(!cfg.ReadOnly,
+										h.Button(
+											h.Text("▶ Run"),
+											h.If(running.String() == "true", h.Attr("aria-busy", "true")),
+											h.If(running.String() == "true", h.Attr("disabled", "disabled")),
+											runAction.OnClick(),
+										),
 									),
 									h.A(
 										h.Href("/"),
@@ -344,24 +414,46 @@ func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []Tas
 								),
 							),
 
+							// Task variables, passed as NAME=value
+							h.If(len(varFields) > 0,
+								h.Div(append([]h.H{
+									h.Style("display: grid; grid-template-columns: repeat(auto-fill, minmax(220px, 1fr)); gap: 0.5rem 1rem; margin-bottom: 1rem;"),
+								}, varFields...)...),
+							),
+
+							// Other vars and args, saved with the run in the history
+							h.Input(
+								h.Type("text"),
+								h.Placeholder("Args (optional), e.g. VERSION=1.2 -- --verbose"),
+								h.Value(argsInput.String()),
+								argsInput.Bind(),
+								runAction.OnKeyDown("Enter"),
+							),
+
 							// Terminal output area
 							h.Div(
 								h.Style("background-color: #1e1e1e; color: #d4d4d4; padding: 1rem; border-radius: 0.5rem; min-height: 300px; font-family: 'Menlo', 'Monaco', 'Courier New', monospace; font-size: 14px; white-space: pre-wrap; overflow-y: auto; max-height: 500px;"),
-								h.If(output.String() == "" && status.String() == "ready",
+								h.If(output.String() == "" && status.String() == "ready" && !cfg.ReadOnly,
 									h.Span(
 										h.Style("color: #6c757d;"),
 										h.Text("Click \"Run\" to execute: task "+taskName),
 									),
 								),
 								h.If(output.String() != "",
-									h.Text(output.String()),
+									ansiOutput(output.String()),
 								),
 							),
 
-							// Status footer
+							// Status footer, with a link to the run for sharing or later
 							h.Div(
 								h.Style("margin-top: 0.5rem; color: var(--pico-muted-color);"),
 								h.Small(h.Text(statusText)),
+								h.If(runID.String() != "0" && status.String() != "running",
+									h.Small(
+										h.Text(" · "),
+										h.A(h.Href("/runs/"+runID.String()), h.Text("Run #"+runID.String())),
+									),
+								),
 							),
 						),
 					),
@@ -371,14 +463,14 @@ func viaTaskExecutionPage(c *via.Context, taskName, taskDesc string, tasks []Tas
 	})
 }
 
-// runTaskWithCallback runs a task and calls the callback for each line of output
-func runTaskWithCallback(taskName, workDir string, callback func(string)) error {
+// runTaskWithCallback runs a task with args and calls the callback for each line of output
+func runTaskWithCallback(taskName string, args []string, workDir string, callback func(string)) error {
 	xplatBin, err := os.Executable()
 	if err != nil {
 		xplatBin = "xplat"
 	}
 
-	cmd := exec.Command(xplatBin, "task", taskName)
+	cmd := exec.Command(xplatBin, append([]string{"task", taskName}, args...)...)
 	cmd.Dir = workDir
 	cmd.Env = append(os.Environ(), "FORCE_COLOR=1")
 
@@ -424,6 +516,12 @@ func readLines(r io.Reader, callback func(string)) {
 // RenderNav is the single source of truth for navigation.
 // Used by both app.go and the page functions in this file.
 func RenderNav(activeTab string, workDir string) h.H {
+	return renderNav(activeTab, workDir, "", nil)
+}
+
+// renderNav renders the navigation with a red count badge on the tabs in
+// badges with a non-zero count. basePath prefixes the Setup link (see App.link).
+func renderNav(activeTab string, workDir string, basePath string, badges map[string]int) h.H {
 	tabStyle := func(tab string) string {
 		base := "color: white; text-decoration: none; padding: 0.5rem 1rem; border-radius: 0.25rem 0.25rem 0 0;"
 		if tab == activeTab {
@@ -431,6 +529,17 @@ func RenderNav(activeTab string, workDir string) h.H {
 		}
 		return base
 	}
+	badge := func(tab string) h.H {
+		n := badges[tab]
+		if n == 0 {
+			return nil
+		}
+		return h.Span(
+			h.Style("background-color: #dc3545; color: white; border-radius: 1rem; padding: 0 0.4rem; margin-left: 0.35rem; font-size: 0.75rem;"),
+			h.Attr("title", fmt.Sprintf("%d failing", n)),
+			h.Text(strconv.Itoa(n)),
+		)
+	}
 
 	return h.Nav(
 		h.Style("background-color: #343a40; padding: 1rem; margin-bottom: 1rem;"),
@@ -455,26 +564,79 @@ func RenderNav(activeTab string, workDir string) h.H {
 						h.Style(tabStyle("tasks")),
 						h.Text("Tasks"),
 					),
+					h.A(
+						h.Href("/history"),
+						h.Style(tabStyle("history")),
+						h.Text("History"),
+					),
+					h.A(
+						h.Href("/schedules"),
+						h.Style(tabStyle("schedules")),
+						h.Text("Schedules"),
+						badge("schedules"),
+					),
+					h.A(
+						h.Href("/includes"),
+						h.Style(tabStyle("includes")),
+						h.Text("Includes"),
+					),
 					h.A(
 						h.Href("/processes"),
 						h.Style(tabStyle("processes")),
 						h.Text("Processes"),
 					),
 					h.A(
-						h.Href("/setup"),
+						h.Href("/env"),
+						h.Style(tabStyle("env")),
+						h.Text("Env"),
+					),
+					h.A(
+						h.Href(basePath+"/setup"),
 						h.Style(tabStyle("setup")),
 						h.Text("Setup"),
 					),
+					h.A(
+						h.Href("/audit"),
+						h.Style(tabStyle("audit")),
+						h.Text("Audit"),
+					),
 				),
 			),
-			h.Span(
-				h.Style("color: #6c757d;"),
-				h.Text(workDir),
+			h.Div(
+				h.Style("display: flex; align-items: center; gap: 1rem;"),
+				// Light/dark toggle, shown by themeScript
+				h.A(
+					h.ID("xplat-theme-toggle"),
+					h.Href("#"),
+					h.Style("display: none; color: white; text-decoration: none;"),
+				),
+				// Desktop notification toggle, shown by notifyScript when the app serves the stream
+				h.A(
+					h.ID("xplat-notify-toggle"),
+					h.Href("#"),
+					h.Style("display: none; color: white; text-decoration: none;"),
+				),
+				h.Span(
+					h.Style("color: #6c757d;"),
+					h.Text(workDir),
+				),
 			),
 		),
 	)
 }
 
+// namespaceOptions builds the namespace select for the Stop Namespace action.
+func namespaceOptions(namespaces []string) []h.H {
+	opts := []h.H{
+		h.Attr("id", "group-namespace"),
+		h.Style("margin: 0; padding: 0.25rem 0.5rem;"),
+	}
+	for _, ns := range namespaces {
+		opts = append(opts, h.Option(h.Attr("value", ns), h.Text(ns)))
+	}
+	return opts
+}
+
 // viaProcessListPage renders the process-compose status page with tabs.
 func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaConfig) {
 	// Signals for state management
@@ -525,9 +687,40 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 		}
 		lastRefresh := fmt.Sprintf("Last updated: %s", time.Now().Format("15:04:05"))
 
+		var envReload *EnvReload
+		var envReloadText string
+		if cfg.EnvReload != nil {
+			if envReload = cfg.EnvReload(); envReload != nil {
+				envReloadText = envReload.Time.Format("15:04:05") + " " + envReload.Summary()
+			}
+		}
+
 		// Check if process-compose is running
 		isRunning := client.IsRunning()
 
+		// Dependencies and namespaces for the group actions
+		var deps map[string][]string
+		if isRunning {
+			deps, _ = client.GetDependencies()
+		}
+		var namespaces []string
+		seenNamespace := make(map[string]bool)
+		for _, p := range processes {
+			if p.Namespace != "" && !seenNamespace[p.Namespace] {
+				seenNamespace[p.Namespace] = true
+				namespaces = append(namespaces, p.Namespace)
+			}
+		}
+		sort.Strings(namespaces)
+
+		// SLO compliance for processes with an SLO in xplat.yaml
+		sloByProcess := make(map[string]sitecheck.SLOStatus)
+		for _, s := range loadSLOStatus(cfg.WorkDir) {
+			if s.Process != "" {
+				sloByProcess[s.Process] = s
+			}
+		}
+
 		// Tab button style helper
 		tabStyle := func(tab string) string {
 			base := "padding: 0.5rem 1rem; border: none; background: none; cursor: pointer; border-bottom: 2px solid transparent; margin-right: 0.5rem;"
@@ -547,6 +740,11 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 		var processCards []h.H
 		for _, p := range processes {
 			statusColor := getStatusColor(p.Status)
+			slo, hasSLO := sloByProcess[p.Name]
+			sloColor := "inherit"
+			if slo.Checks > 0 && !slo.Met {
+				sloColor = "#dc3545"
+			}
 			processCards = append(processCards,
 				h.Div(
 					h.Style("border-bottom: 1px solid var(--pico-muted-border-color);"),
@@ -567,10 +765,14 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 									h.Text(fmt.Sprintf("Status: %s", p.Status)),
 									h.If(p.PID > 0, h.Text(fmt.Sprintf(" | PID: %d", p.PID))),
 									h.If(p.Restarts > 0, h.Text(fmt.Sprintf(" | Restarts: %d", p.Restarts))),
+									h.If(hasSLO, h.Span(
+										h.Style("color: "+sloColor+";"),
+										h.Text(" | SLO: "+slo.Summary()),
+									)),
 								),
 							),
 						),
-						h.Div(
+						h.If(!cfg.ReadOnly, h.Div(
 							h.Style("display: flex; gap: 0.25rem;"),
 							h.If(p.IsRunning,
 								h.Button(
@@ -596,7 +798,17 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 								h.Attr("data-process", p.Name),
 								h.Attr("onclick", fmt.Sprintf("fetch('/api/process/restart/%s', {method: 'POST'}).then(() => location.reload())", p.Name)),
 							),
-						),
+							h.If(len(dependentsOf(p.Name, deps)) > 0,
+								h.Button(
+									h.Class("contrast outline"),
+									h.Style("padding: 0.25rem 0.5rem; font-size: 0.8rem;"),
+									h.Attr("title", "Restart this process and everything that depends on it"),
+									h.Text("Restart + dependents"),
+									h.Attr("data-process", p.Name),
+									h.Attr("onclick", fmt.Sprintf("runGroupAction('%s', '%s')", GroupRestartDependents, p.Name)),
+								),
+							),
+						)),
 					),
 					// Expandable logs panel
 					h.Details(
@@ -713,6 +925,14 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 					),
 
 					// Error message
+					// Latest .env reload (xplat up --env-reload)
+					h.If(envReload != nil,
+						h.Div(
+							h.Style("background-color: #e7f1ff; border: 1px solid #0d6efd; border-radius: 0.5rem; padding: 0.75rem 1rem; margin-bottom: 1rem;"),
+							h.Small(h.Text(envReloadText)),
+						),
+					),
+
 					h.If(errorMsg != "" && isRunning,
 						h.Div(
 							h.Style("background-color: #f8d7da; border: 1px solid #dc3545; border-radius: 0.5rem; padding: 1rem; margin-bottom: 1rem;"),
@@ -723,6 +943,34 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 					// STATUS TAB
 					h.If(activeTab.String() == "status",
 						h.Div(
+							// Group actions
+							h.If(len(processCards) > 0 && !cfg.ReadOnly,
+								h.Div(
+									h.Style("display: flex; gap: 0.5rem; align-items: center; margin-bottom: 1rem;"),
+									h.Button(
+										h.Class("contrast outline"),
+										h.Style("padding: 0.25rem 0.75rem; margin: 0;"),
+										h.Text("Restart All"),
+										h.Attr("onclick", fmt.Sprintf("runGroupAction('%s', '')", GroupRestartAll)),
+									),
+									h.If(len(namespaces) > 0,
+										h.Div(
+											h.Style("display: flex; gap: 0.5rem; align-items: center;"),
+											h.Select(namespaceOptions(namespaces)...),
+											h.Button(
+												h.Class("secondary outline"),
+												h.Style("padding: 0.25rem 0.75rem; margin: 0; white-space: nowrap;"),
+												h.Text("Stop Namespace"),
+												h.Attr("onclick", fmt.Sprintf("runGroupAction('%s', document.getElementById('group-namespace').value)", GroupStopNamespace)),
+											),
+										),
+									),
+									h.Small(
+										h.Attr("id", "group-progress"),
+										h.Style("color: var(--pico-muted-color); margin-left: auto;"),
+									),
+								),
+							),
 							h.If(len(processCards) > 0,
 								h.Div(processCards...),
 							),
@@ -749,7 +997,7 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 								h.Button(
 									h.Class("outline"),
 									h.Style("padding: 0.25rem 0.75rem;"),
-									h.Text("Refresh Logs"),
+									h.Text("Reconnect"),
 									h.Attr("onclick", "refreshAllLogs()"),
 								),
 								h.Label(
@@ -821,7 +1069,9 @@ func viaProcessListPage(c *via.Context, client *ProcessComposeClient, cfg ViaCon
 var processNames = [];
 var allLogs = {};
 var currentFilter = 'all';
-var logsLoading = false;  // Prevent concurrent loads
+var logStream = null;     // EventSource for the Logs tab
+var panelStreams = {};    // EventSources for the Status tab log panels
+var maxLogLines = 1000;   // Per process, older lines are dropped
 var graphLoading = false; // Prevent concurrent loads
 
 // Colors for different processes
@@ -847,22 +1097,70 @@ function getProcessColor(name) {
 	return 'hsl(' + h + ', 70%, 60%)';
 }
 
-// Load logs for a single process (for status tab expandable panels)
+// Tail logs for a single process (for status tab expandable panels)
 function loadLogs(processName) {
 	const logEl = document.getElementById('logs-' + processName);
 	if (!logEl) return;
 
+	if (panelStreams[processName]) panelStreams[processName].close();
 	logEl.textContent = 'Loading...';
 
-	fetch('/api/process/logs/' + processName)
-		.then(r => r.json())
-		.then(data => {
-			logEl.textContent = data.logs || '(no logs available)';
-			logEl.scrollTop = logEl.scrollHeight;
+	var es = new EventSource('/api/process/logs/stream/' + encodeURIComponent(processName) + '?tail=500');
+	panelStreams[processName] = es;
+	es.onopen = function() {
+		var el = document.getElementById('logs-' + processName);
+		if (el) el.textContent = '';
+	};
+	es.addEventListener('log', function(e) {
+		var el = document.getElementById('logs-' + processName);
+		if (!el) {
+			// Panel was re-rendered away
+			es.close();
+			delete panelStreams[processName];
+			return;
+		}
+		var m = JSON.parse(e.data);
+		el.appendChild(document.createTextNode(m.line + '\n'));
+		while (el.childNodes.length > maxLogLines) el.removeChild(el.firstChild);
+		el.scrollTop = el.scrollHeight;
+	});
+	es.onerror = function() {
+		var el = document.getElementById('logs-' + processName);
+		if (el && el.textContent === 'Loading...') el.textContent = '(no logs available, retrying...)';
+	};
+}
+
+// Group actions: fetch the plan, confirm it, then run the steps one by one
+function runGroupAction(action, target) {
+	fetch('/api/process/group/plan?action=' + encodeURIComponent(action) + '&target=' + encodeURIComponent(target || ''))
+		.then(r => r.ok ? r.json() : r.text().then(t => { throw new Error(t.trim()); }))
+		.then(plan => {
+			var steps = plan.steps || [];
+			if (steps.length === 0) {
+				alert(plan.title + ': nothing to do');
+				return;
+			}
+			var summary = steps.map(function(s, i) { return (i + 1) + '. ' + s.op + ' ' + s.process; }).join('\n');
+			if (!confirm(plan.title + '?\n\n' + summary)) return;
+
+			var progressEl = document.getElementById('group-progress');
+			var i = 0;
+			function next() {
+				if (i >= steps.length) {
+					progressEl.textContent = plan.title + ': done';
+					setTimeout(function() { location.reload(); }, 1000);
+					return;
+				}
+				var step = steps[i];
+				progressEl.textContent = '[' + (i + 1) + '/' + steps.length + '] ' + step.op + ' ' + step.process + '...';
+				fetch('/api/process/' + step.op + '/' + step.process, {method: 'POST'})
+					.then(r => r.ok ? null : r.text().then(t => { throw new Error(t.trim()); }))
+					.then(() => { i++; next(); })
+					.catch(err => { progressEl.textContent = 'Failed to ' + step.op + ' ' + step.process + ': ' + err.message; });
+			}
+			next();
 		})
-		.catch(err => {
-			logEl.textContent = 'Error fetching logs: ' + err.message;
-		});
+		.catch(err => alert('Error: ' + err.message));
 }
 
 // Get process names from data attribute (always available regardless of tab)
@@ -891,15 +1189,13 @@ function getProcessNames() {
 	return [];
 }
 
-// Fetch logs for all processes
+// Stream logs for all processes into the Logs tab
 function refreshAllLogs() {
-	if (logsLoading) return; // Prevent concurrent loads
-
 	const logEl = document.getElementById('combined-logs');
 	if (!logEl) return;
 
-	logsLoading = true;
-	logEl.textContent = 'Loading logs...';
+	stopLogStream();
+	logEl.textContent = 'Connecting...';
 
 	// Get process names from data attribute (always available)
 	processNames = getProcessNames();
@@ -907,31 +1203,65 @@ function refreshAllLogs() {
 
 	if (processNames.length === 0) {
 		logEl.textContent = '(no processes - is process-compose running?)';
-		logsLoading = false;
 		return;
 	}
 
-	var pending = processNames.length;
-	processNames.forEach(function(name) {
-		fetch('/api/process/logs/' + name)
-			.then(r => r.json())
-			.then(data => {
-				allLogs[name] = (data.logs || '').split('\n').filter(l => l.trim());
-				pending--;
-				if (pending === 0) {
-					renderCombinedLogs();
-					logsLoading = false;
-				}
-			})
-			.catch(err => {
-				allLogs[name] = ['Error: ' + err.message];
-				pending--;
-				if (pending === 0) {
-					renderCombinedLogs();
-					logsLoading = false;
-				}
-			});
+	logStream = new EventSource('/api/process/logs/stream?name=' + encodeURIComponent(processNames.join(',')));
+	logStream.onopen = function() {
+		// Each (re)connection replays the tail, so start over
+		allLogs = {};
+		renderCombinedLogs();
+	};
+	logStream.addEventListener('log', function(e) {
+		var m = JSON.parse(e.data);
+		appendLogLine(m.process, m.line);
 	});
+	logStream.onerror = function() {
+		var el = document.getElementById('combined-logs');
+		if (!el) {
+			stopLogStream();
+		} else if (el.textContent === 'Connecting...') {
+			el.textContent = '(log stream unavailable, retrying...)';
+		}
+	};
+}
+
+function stopLogStream() {
+	if (logStream) {
+		logStream.close();
+		logStream = null;
+	}
+}
+
+// Append one streamed line, without re-rendering the whole view
+function appendLogLine(name, text) {
+	var lines = allLogs[name] || (allLogs[name] = []);
+	lines.push(text);
+	if (lines.length > maxLogLines) lines.shift();
+
+	const logEl = document.getElementById('combined-logs');
+	if (!logEl) {
+		stopLogStream();
+		return;
+	}
+	if (currentFilter !== 'all' && currentFilter !== name) return;
+
+	var span = document.createElement('span');
+	if (currentFilter === 'all') {
+		var prefix = document.createElement('span');
+		prefix.style.color = getProcessColor(name);
+		prefix.style.fontWeight = 'bold';
+		prefix.textContent = '[' + name + '] ';
+		span.appendChild(prefix);
+	}
+	span.appendChild(document.createTextNode(text + '\n'));
+	logEl.appendChild(span);
+	while (logEl.childNodes.length > maxLogLines * 2) logEl.removeChild(logEl.firstChild);
+
+	var autoScroll = document.getElementById('logs-auto-scroll');
+	if (autoScroll && autoScroll.checked) {
+		logEl.scrollTop = logEl.scrollHeight;
+	}
 }
 
 // Render combined logs with color coding
@@ -1165,8 +1495,10 @@ var observer = new MutationObserver(function(mutations) {
 	observerDebounce = setTimeout(function() {
 		observerDebounce = null;
 		var logsEl = document.getElementById('combined-logs');
-		if (logsEl && logsEl.textContent === 'Loading logs...' && !logsLoading) {
+		if (logsEl && logsEl.textContent === 'Loading logs...') {
 			refreshAllLogs();
+		} else if (!logsEl) {
+			stopLogStream();
 		}
 		var graphEl = document.getElementById('graph-display');
 		if (graphEl && graphEl.textContent === 'Loading dependency graph...' && !graphLoading) {
@@ -104,6 +104,21 @@ KEY COMMANDS:
 	// P16 (Documentation server - preview docs locally matching GitHub Pages)
 	rootCmd.AddCommand(cmd.DocsServeCmd)
 
+	// P17 (Encrypted .env - age/sops)
+	rootCmd.AddCommand(cmd.EnvFileCmd)
+
+	// P18 (Site reachability checks - check-host.net, direct, agents)
+	rootCmd.AddCommand(cmd.SiteCmd)
+
+	// P19 (Health checks for background services)
+	rootCmd.AddCommand(cmd.DoctorCmd)
+
+	// P20 (Development workflows - per-branch previews)
+	rootCmd.AddCommand(cmd.DevCmd)
+
+	// P21 (Per-project tool state under ~/.xplat/state)
+	rootCmd.AddCommand(cmd.StateCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
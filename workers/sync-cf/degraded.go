@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/kv"
+)
+
+// budgetKVBinding is the optional KV namespace 'xplat sync-cf budget' writes
+// the degraded-mode flag to when a monthly budget is exhausted.
+const budgetKVBinding = "BUDGET_KV"
+
+// degradedCacheTTL bounds how often the flag is read, as KV reads are billed too.
+const degradedCacheTTL = 30 * time.Second
+
+var degraded struct {
+	mu      sync.Mutex
+	on      bool
+	checked time.Time
+}
+
+// isDegraded reports whether the degraded-mode flag is set. In degraded mode
+// noncritical traffic (Logpush, R2 event notifications) is accepted and
+// dropped; Pages deploys and alerts are still forwarded. Without the
+// BUDGET_KV binding the Worker never degrades.
+func isDegraded() bool {
+	degraded.mu.Lock()
+	defer degraded.mu.Unlock()
+
+	if time.Since(degraded.checked) < degradedCacheTTL {
+		return degraded.on
+	}
+	degraded.checked = time.Now()
+
+	ns, err := kv.NewNamespace(budgetKVBinding)
+	if err != nil {
+		degraded.on = false
+		return false
+	}
+	key := os.Getenv("DEGRADED_KEY")
+	if key == "" {
+		key = "degraded"
+	}
+	value, err := ns.GetString(key, nil)
+	if err != nil {
+		log.Printf("degraded: failed to read %s: %v", key, err)
+		return degraded.on
+	}
+	// A missing key comes back as JS null, which syscall/js renders as "<null>"
+	degraded.on = value != "" && value != "<null>"
+	return degraded.on
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/cloudflare/r2"
+)
+
+// rawBucketBinding is the optional R2 binding for oversized raw payloads
+const rawBucketBinding = "RAW_BUCKET"
+
+// forwardEvent limits the event's payload size and sends it to the sync
+// service. It returns nil only once the service has accepted the event.
+func forwardEvent(ctx context.Context, event Event) error {
+	return forwardEvents(ctx, []Event{event}, nil)
+}
+
+// forwardEvents sends events in batches of up to BATCH_MAX_EVENTS. Every
+// batch is sent before it returns: nothing is held across invocations, as an
+// isolate can be evicted at any time and its memory with it. sent, if set, is
+// called for each batch with its events' index range and the send error, so a
+// queue consumer can ack or retry exactly those messages. It returns the
+// first send error.
+func forwardEvents(ctx context.Context, events []Event, sent func(from, to int, err error)) error {
+	if syncEndpoint == "" {
+		for _, event := range events {
+			log.Printf("SYNC_ENDPOINT not configured, event: %s/%s", event.Type, event.Action)
+		}
+		if sent != nil {
+			sent(0, len(events), nil)
+		}
+		return nil
+	}
+
+	for i := range events {
+		limitPayload(&events[i])
+	}
+
+	size := batchMax
+	if size < 1 {
+		size = 1
+	}
+	var firstErr error
+	for from := 0; from < len(events); from += size {
+		to := from + size
+		if to > len(events) {
+			to = len(events)
+		}
+		err := sendEvents(ctx, events[from:to])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if sent != nil {
+			sent(from, to, err)
+		}
+	}
+	return firstErr
+}
+
+// limitPayload drops Raw when it exceeds maxPayload, keeping the full body in
+// R2 (if bound) so the receiver can fetch it by RawRef.
+func limitPayload(event *Event) {
+	if len(event.Raw) <= maxPayload {
+		return
+	}
+
+	usage.incTruncated()
+	event.RawSize = len(event.Raw)
+	event.RawTruncated = true
+
+	key := fmt.Sprintf("sync-cf/%s/%s-%d.json", event.Type, event.Timestamp.UTC().Format("2006-01-02"), event.Timestamp.UnixNano())
+	if ref, err := storeRaw(key, event.Raw); err != nil {
+		log.Printf("raw payload (%d bytes) dropped: %v", event.RawSize, err)
+	} else {
+		event.RawRef = ref
+	}
+	event.Raw = nil
+}
+
+// storeRaw writes a raw payload to the RAW_BUCKET R2 binding
+func storeRaw(key string, raw []byte) (string, error) {
+	bucket, err := r2.NewBucket(rawBucketBinding)
+	if err != nil {
+		return "", fmt.Errorf("%s not bound: %w", rawBucketBinding, err)
+	}
+
+	_, err = bucket.Put(key, io.NopCloser(bytes.NewReader(raw)), &r2.PutOptions{
+		HTTPMetadata: r2.HTTPMetadata{ContentType: "application/json"},
+	})
+	if err != nil {
+		return "", fmt.Errorf("r2 put: %w", err)
+	}
+	return "r2://" + rawBucketName() + "/" + key, nil
+}
+
+// rawBucketName returns the bucket name for RawRef pointers
+func rawBucketName() string {
+	if name := os.Getenv("RAW_BUCKET_NAME"); name != "" {
+		return name
+	}
+	return rawBucketBinding
+}
+
+// sendEvents posts events to the sync service.
+// A single event is sent as an object, several as a JSON array.
+func sendEvents(ctx context.Context, events []Event) error {
+	var payload interface{} = events
+	if len(events) == 1 {
+		payload = events[0]
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		usage.incForwardFailure()
+		return fmt.Errorf("marshal events: %w", err)
+	}
+
+	encoding := ""
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			usage.incForwardFailure()
+			return fmt.Errorf("gzip events: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			usage.incForwardFailure()
+			return fmt.Errorf("gzip events: %w", err)
+		}
+		body = buf.Bytes()
+		encoding = "gzip"
+	}
+
+	cli := fetch.NewClient()
+	req, err := fetch.NewRequest(ctx, http.MethodPost, syncEndpoint, bytes.NewReader(body))
+	if err != nil {
+		usage.incForwardFailure()
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	req.Header.Set("X-Sync-Batch-Size", strconv.Itoa(len(events)))
+	if syncToken != "" {
+		req.Header.Set("Authorization", "Bearer "+syncToken)
+	}
+
+	resp, err := cli.Do(req, nil)
+	if err != nil {
+		usage.incForwardFailure()
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		usage.incForwardFailure()
+		return fmt.Errorf("sync service returned %d", resp.StatusCode)
+	}
+
+	usage.incForwardSuccess()
+	if len(events) > 1 {
+		usage.incBatches()
+	}
+	log.Printf("forwarded %d event(s), %d bytes", len(events), len(body))
+	return nil
+}
@@ -7,19 +7,19 @@
 package main
 
 import (
-	"bytes"
-	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/syumai/workers"
-	"github.com/syumai/workers/cloudflare/fetch"
+	"github.com/syumai/workers/cloudflare/queues"
 )
 
 // Event represents a normalized Cloudflare event
@@ -33,6 +33,11 @@ type Event struct {
 	Source    string                 `json:"source"` // Which CF service sent this
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	Raw       json.RawMessage        `json:"raw,omitempty"`
+
+	// Set when Raw exceeded MAX_PAYLOAD_BYTES and was dropped from the event
+	RawTruncated bool   `json:"raw_truncated,omitempty"`
+	RawSize      int    `json:"raw_size,omitempty"`
+	RawRef       string `json:"raw_ref,omitempty"` // r2://<bucket>/<key> when RAW_BUCKET is bound
 }
 
 // Usage tracks request counts for billing visibility
@@ -42,16 +47,24 @@ type Usage struct {
 	WebhookPages    int64
 	WebhookAlert    int64
 	Logpush         int64
+	R2Events        int64
 	ForwardSuccess  int64
 	ForwardFailures int64
+	Batches         int64
+	Truncated       int64
+	Shed            int64 // Dropped in degraded mode
 }
 
 func (u *Usage) incTotal()          { u.mu.Lock(); u.TotalRequests++; u.mu.Unlock() }
 func (u *Usage) incPages()          { u.mu.Lock(); u.WebhookPages++; u.mu.Unlock() }
 func (u *Usage) incAlert()          { u.mu.Lock(); u.WebhookAlert++; u.mu.Unlock() }
 func (u *Usage) incLogpush()        { u.mu.Lock(); u.Logpush++; u.mu.Unlock() }
+func (u *Usage) incR2Events()       { u.mu.Lock(); u.R2Events++; u.mu.Unlock() }
 func (u *Usage) incForwardSuccess() { u.mu.Lock(); u.ForwardSuccess++; u.mu.Unlock() }
 func (u *Usage) incForwardFailure() { u.mu.Lock(); u.ForwardFailures++; u.mu.Unlock() }
+func (u *Usage) incBatches()        { u.mu.Lock(); u.Batches++; u.mu.Unlock() }
+func (u *Usage) incTruncated()      { u.mu.Lock(); u.Truncated++; u.mu.Unlock() }
+func (u *Usage) incShed()           { u.mu.Lock(); u.Shed++; u.mu.Unlock() }
 
 func (u *Usage) snapshot() map[string]int64 {
 	u.mu.Lock()
@@ -61,8 +74,12 @@ func (u *Usage) snapshot() map[string]int64 {
 		"webhook_pages":    u.WebhookPages,
 		"webhook_alert":    u.WebhookAlert,
 		"logpush":          u.Logpush,
+		"r2_events":        u.R2Events,
 		"forward_success":  u.ForwardSuccess,
 		"forward_failures": u.ForwardFailures,
+		"batches":          u.Batches,
+		"truncated":        u.Truncated,
+		"shed":             u.Shed,
 	}
 }
 
@@ -77,6 +94,10 @@ var (
 	syncEndpoint string // Where to forward events (e.g., your tunnel URL)
 	syncToken    string // Auth token for your sync service
 	workerName   string // Worker name for identification
+
+	maxPayload int  // Max Raw bytes per event before offloading to R2
+	batchMax   int  // Max queue messages per forward (1 = no batching)
+	compress   bool // gzip forwarded bodies (receivers must accept Content-Encoding: gzip)
 )
 
 func init() {
@@ -86,6 +107,18 @@ func init() {
 	if workerName == "" {
 		workerName = "xplat-sync-cf"
 	}
+
+	maxPayload = envInt("MAX_PAYLOAD_BYTES", 256*1024)
+	batchMax = envInt("BATCH_MAX_EVENTS", 1)
+	compress = os.Getenv("FORWARD_GZIP") == "true"
+}
+
+// envInt reads a positive integer env var, falling back to def
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return def
 }
 
 func main() {
@@ -96,6 +129,9 @@ func main() {
 	http.HandleFunc("/webhook/alert", handleAlertWebhook)
 	http.HandleFunc("/logpush", handleLogpush)
 
+	// R2 event notifications arrive through a Queue bound as a consumer
+	queues.ConsumeNonBlock(handleQueueBatch)
+
 	workers.Serve(nil)
 }
 
@@ -129,6 +165,10 @@ func handleMetrics(w http.ResponseWriter, r *http.Request) {
 		"usage":   usage.snapshot(),
 		"config": map[string]interface{}{
 			"sync_endpoint_configured": syncEndpoint != "",
+			"max_payload_bytes":        maxPayload,
+			"batch_max_events":         batchMax,
+			"forward_gzip":             compress,
+			"degraded":                 isDegraded(),
 		},
 		"billing_note": "Cloudflare Workers: Free tier 100k req/day, Paid $5/mo + $0.50/million after 10M.",
 	}
@@ -224,6 +264,16 @@ func handleAlertWebhook(w http.ResponseWriter, r *http.Request) {
 		Raw: body,
 	}
 
+	// Error rate and CPU exceeded alerts carry a /metrics snapshot so the
+	// receiver can file an issue with the Worker's state at alert time
+	if payload.AlertType == "workers_event" {
+		if kind := workersAlertKind(payload.AlertType, payload.Name); kind != "" {
+			event.Type = "workers_error"
+			event.Metadata["alert_kind"] = kind
+			event.Metadata["metrics"] = usage.snapshot()
+		}
+	}
+
 	if err := forwardEvent(r.Context(), event); err != nil {
 		log.Printf("forward error: %v", err)
 	}
@@ -242,6 +292,13 @@ func handleLogpush(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Over budget: accept so Logpush doesn't retry, but don't forward
+	if isDegraded() {
+		usage.incShed()
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
 	dataset := r.URL.Query().Get("dataset")
 	if dataset == "" {
 		dataset = "unknown"
@@ -294,44 +351,18 @@ func mapAlertType(alertType string) string {
 	}
 }
 
-// forwardEvent sends the event to the sync service
-func forwardEvent(ctx context.Context, event Event) error {
-	if syncEndpoint == "" {
-		log.Printf("SYNC_ENDPOINT not configured, event: %s/%s", event.Type, event.Action)
-		return nil
-	}
-
-	body, err := json.Marshal(event)
-	if err != nil {
-		usage.incForwardFailure()
-		return fmt.Errorf("marshal event: %w", err)
-	}
-
-	cli := fetch.NewClient()
-	req, err := fetch.NewRequest(ctx, http.MethodPost, syncEndpoint, bytes.NewReader(body))
-	if err != nil {
-		usage.incForwardFailure()
-		return fmt.Errorf("create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if syncToken != "" {
-		req.Header.Set("Authorization", "Bearer "+syncToken)
+// workersAlertKind classifies a workers_event notification as "error_rate",
+// "cpu_exceeded" or "" (deploys and other events) by its alert_type and name.
+// Keep in sync with synccf.WorkerAlertKind.
+func workersAlertKind(alertType, name string) string {
+	for _, field := range []string{alertType, name} {
+		s := strings.ToLower(strings.ReplaceAll(field, "_", " "))
+		switch {
+		case strings.Contains(s, "cpu") && (strings.Contains(s, "exceed") || strings.Contains(s, "limit")):
+			return "cpu_exceeded"
+		case strings.Contains(s, "error rate"), strings.Contains(s, "errors"):
+			return "error_rate"
+		}
 	}
-
-	resp, err := cli.Do(req, nil)
-	if err != nil {
-		usage.incForwardFailure()
-		return fmt.Errorf("do request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		usage.incForwardFailure()
-		return fmt.Errorf("sync service returned %d", resp.StatusCode)
-	}
-
-	usage.incForwardSuccess()
-	log.Printf("forwarded event: %s/%s", event.Type, event.Action)
-	return nil
+	return ""
 }
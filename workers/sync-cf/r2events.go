@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"syscall/js"
+	"time"
+
+	"github.com/syumai/workers/cloudflare/queues"
+)
+
+// r2Notification is an R2 event notification queue message.
+// See synccf.R2ObjectEvent for the receiving side.
+type r2Notification struct {
+	Account string `json:"account"`
+	Action  string `json:"action"`
+	Bucket  string `json:"bucket"`
+	Object  struct {
+		Key  string `json:"key"`
+		Size int64  `json:"size"`
+		ETag string `json:"eTag"`
+	} `json:"object"`
+	EventTime time.Time `json:"eventTime"`
+}
+
+// handleQueueBatch forwards R2 event notifications from the consumer queue,
+// up to BATCH_MAX_EVENTS per request. A message is acked only once its
+// forward succeeded; failed ones are retried by the queue, malformed ones are
+// acked and dropped.
+func handleQueueBatch(batch *queues.MessageBatch) error {
+	var events []Event
+	var msgs []*queues.Message
+	for _, msg := range batch.Messages {
+		usage.incTotal()
+		usage.incR2Events()
+
+		// Over budget: ack without forwarding
+		if isDegraded() {
+			usage.incShed()
+			msg.Ack()
+			continue
+		}
+
+		body, err := messageJSON(msg)
+		if err != nil {
+			log.Printf("queue %s: message %s: %v", batch.Queue, msg.ID, err)
+			msg.Ack()
+			continue
+		}
+
+		var n r2Notification
+		if err := json.Unmarshal(body, &n); err != nil || n.Bucket == "" {
+			log.Printf("queue %s: message %s is not an R2 notification", batch.Queue, msg.ID)
+			msg.Ack()
+			continue
+		}
+		eventType := r2EventType(n.Action)
+		if eventType == "" {
+			log.Printf("queue %s: unknown R2 action %q", batch.Queue, n.Action)
+			msg.Ack()
+			continue
+		}
+
+		timestamp := n.EventTime
+		if timestamp.IsZero() {
+			timestamp = msg.Timestamp
+		}
+		events = append(events, Event{
+			Type:      eventType,
+			Timestamp: timestamp,
+			AccountID: n.Account,
+			Action:    n.Action,
+			Resource:  n.Bucket + "/" + n.Object.Key,
+			Source:    "r2_event_notification",
+			Metadata: map[string]interface{}{
+				"bucket":     n.Bucket,
+				"key":        n.Object.Key,
+				"size":       n.Object.Size,
+				"etag":       n.Object.ETag,
+				"queue":      batch.Queue,
+				"message_id": msg.ID,
+			},
+			Raw: body,
+		})
+		msgs = append(msgs, msg)
+	}
+
+	err := forwardEvents(context.Background(), events, func(from, to int, err error) {
+		for _, msg := range msgs[from:to] {
+			if err != nil {
+				msg.Retry()
+			} else {
+				msg.Ack()
+			}
+		}
+	})
+	if err != nil {
+		log.Printf("forward error: %v", err)
+	}
+	return nil
+}
+
+// messageJSON returns a queue message body as JSON. R2 notifications arrive
+// as structured-clone objects; string and byte bodies are passed through.
+func messageJSON(msg *queues.Message) ([]byte, error) {
+	if s, err := msg.StringBody(); err == nil {
+		return []byte(s), nil
+	}
+	if b, err := msg.BytesBody(); err == nil {
+		return b, nil
+	}
+	if msg.Body.Type() != js.TypeObject {
+		return nil, fmt.Errorf("unsupported message body type %s", msg.Body.Type())
+	}
+	return []byte(js.Global().Get("JSON").Call("stringify", msg.Body).String()), nil
+}
+
+// r2EventType maps an R2 notification action to an event type.
+// Keep in sync with synccf.R2EventType.
+func r2EventType(action string) string {
+	switch action {
+	case "PutObject", "CopyObject", "CompleteMultipartUpload":
+		return "r2_object_created"
+	case "DeleteObject", "LifecycleDeletion":
+		return "r2_object_deleted"
+	}
+	return ""
+}